@@ -5,6 +5,8 @@ import (
 
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/api"
+	"github.com/spiffe/spire/cmd/spire-agent/cli/config"
+	"github.com/spiffe/spire/cmd/spire-agent/cli/debug"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/healthcheck"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/run"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/validate"
@@ -36,6 +38,18 @@ func (cc *CLI) Run(args []string) int {
 		"api watch": func() (cli.Command, error) {
 			return &api.WatchCLI{}, nil
 		},
+		"config show": func() (cli.Command, error) {
+			return config.NewShowCommand(), nil
+		},
+		"debug dump-attestation": func() (cli.Command, error) {
+			return debug.NewDumpAttestationCommand(), nil
+		},
+		"debug reattest": func() (cli.Command, error) {
+			return debug.NewReattestCommand(), nil
+		},
+		"debug simulate-attestation": func() (cli.Command, error) {
+			return debug.NewSimulateAttestationCommand(), nil
+		},
 		"run": func() (cli.Command, error) {
 			return run.NewRunCommand(cc.LogOptions, cc.AllowUnknownConfig), nil
 		},
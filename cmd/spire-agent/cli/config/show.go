@@ -0,0 +1,62 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-agent/cli/run"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/log"
+)
+
+const showCommandName = "config show"
+
+func NewShowCommand() cli.Command {
+	return newShowCommand(common_cli.DefaultEnv, os.LookupEnv)
+}
+
+func newShowCommand(env *common_cli.Env, lookupEnv func(string) (string, bool)) *showCommand {
+	return &showCommand{
+		env:       env,
+		lookupEnv: lookupEnv,
+	}
+}
+
+type showCommand struct {
+	env       *common_cli.Env
+	lookupEnv func(string) (string, bool)
+}
+
+// Help prints the agent cmd usage
+func (c *showCommand) Help() string {
+	return run.Help(showCommandName, c.env.Stderr)
+}
+
+func (c *showCommand) Synopsis() string {
+	return "Prints the effective SPIRE agent configuration, with secrets redacted"
+}
+
+func (c *showCommand) Run(args []string) int {
+	// The configuration is printed to stdout, so the logs are discarded
+	discardLogs := func(logger *log.Logger) error {
+		logger.SetOutput(ioutil.Discard)
+		return nil
+	}
+
+	config, err := run.LoadEffectiveConfig(showCommandName, args, []log.Option{discardLogs}, c.env.Stderr, false, c.lookupEnv)
+	if err != nil {
+		// Ignore error since a failure to write to stderr cannot very well be reported
+		_ = c.env.ErrPrintf("Failed to resolve the SPIRE agent configuration: %v\n", err)
+		return 1
+	}
+
+	out, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		_ = c.env.ErrPrintf("Failed to print the SPIRE agent configuration: %v\n", err)
+		return 1
+	}
+	_ = c.env.Println(string(out))
+	return 0
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const configTemplate = `
+agent {
+	data_dir = %q
+	server_address = "127.0.0.1"
+	server_port = 8081
+	trust_domain = "example.org"
+	insecure_bootstrap = true
+	join_token = "d3a1b0e2-7a6c-4a0e-9a4e-0a8c1b2c3d4e"
+}
+
+plugins {
+	KeyManager "memory" {
+		plugin_data {}
+	}
+
+	NodeAttestor "join_token" {
+		plugin_data {}
+	}
+
+	WorkloadAttestor "k8s" {
+		plugin_data {
+			node_name_env = "NODE"
+			token_path = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+		}
+	}
+}
+`
+
+func TestShow(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "agent.conf")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(fmt.Sprintf(configTemplate, dir)), 0600))
+
+	env := map[string]string{
+		"NODE":         "node-1",
+		"MY_NODE_NAME": "ignored",
+	}
+	stdout, stderr, code := runShow(env, "-config", configPath)
+	require.Equal(t, 0, code, "stderr: %s", stderr)
+
+	var config map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &config))
+
+	agent := config["agent"].(map[string]interface{})
+	// Defaults
+	assert.Equal(t, "/tmp/spire-agent/public/api.sock", agent["socket_path"])
+	assert.Equal(t, "1m0s", agent["jwt_svid_validation_leeway"])
+	assert.Equal(t, "INFO", agent["log_level"])
+	assert.Equal(t, map[string]interface{}{
+		"default_svid_name":   "default",
+		"default_bundle_name": "ROOTCA",
+	}, agent["sds"])
+	// Secrets
+	assert.Equal(t, common_cli.Redacted, agent["join_token"])
+	assert.NotContains(t, stdout, "d3a1b0e2")
+
+	plugins := config["plugins"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"enabled": true,
+		"plugin_data": map[string]interface{}{
+			"node_name_env": "NODE",
+			"node_name":     "node-1",
+			"token_path":    "/var/run/secrets/kubernetes.io/serviceaccount/token",
+		},
+	}, plugins["WorkloadAttestor"].(map[string]interface{})["k8s"])
+}
+
+func TestShowInvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "agent.conf")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(`agent { server_address = "127.0.0.1" }`), 0600))
+
+	stdout, stderr, code := runShow(nil, "-config", configPath)
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "Failed to resolve the SPIRE agent configuration: server_port must be configured\n", stderr)
+}
+
+func runShow(env map[string]string, args ...string) (string, string, int) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newShowCommand(&common_cli.Env{
+		Stdout: stdout,
+		Stderr: stderr,
+	}, func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+	code := cmd.Run(args)
+	return stdout.String(), stderr.String(), code
+}
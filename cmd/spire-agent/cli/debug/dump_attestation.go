@@ -0,0 +1,294 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/mitchellh/cli"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/cmd/spire-agent/cli/run"
+	"github.com/spiffe/spire/pkg/agent"
+	"github.com/spiffe/spire/pkg/agent/catalog"
+	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+const dumpAttestationCommandName = "debug dump-attestation"
+
+// AttestationDump is the document produced by the dump-attestation command.
+type AttestationDump struct {
+	NodeAttestation NodeAttestationDump `json:"node_attestation"`
+	Workloads       []WorkloadDump      `json:"workloads"`
+}
+
+// NodeAttestationDump holds the attestation data the agent would send to the
+// server. Secrets in the payload are redacted.
+type NodeAttestationDump struct {
+	Type        string      `json:"type,omitempty"`
+	Payload     interface{} `json:"payload,omitempty"`
+	PayloadSize int         `json:"payload_size"`
+	Error       string      `json:"error,omitempty"`
+}
+
+// WorkloadDump holds the outputs of every workload attestor for a process.
+type WorkloadDump struct {
+	PID       int                    `json:"pid"`
+	Attestors []WorkloadAttestorDump `json:"attestors"`
+}
+
+// WorkloadAttestorDump holds the selectors produced by a workload attestor
+// for a process, or the error it failed with.
+type WorkloadAttestorDump struct {
+	Name      string         `json:"name"`
+	Selectors []SelectorDump `json:"selectors,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+type SelectorDump struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func NewDumpAttestationCommand() cli.Command {
+	return newDumpAttestationCommand(common_cli.DefaultEnv)
+}
+
+func newDumpAttestationCommand(env *common_cli.Env) *dumpAttestationCommand {
+	return &dumpAttestationCommand{
+		env:         env,
+		loadCatalog: loadCatalog,
+		listPIDs:    listPIDs,
+	}
+}
+
+type dumpAttestationCommand struct {
+	env *common_cli.Env
+
+	// loadCatalog and listPIDs can be overridden in tests
+	loadCatalog func(ctx context.Context, config *agent.Config) (catalog.Catalog, io.Closer, error)
+	listPIDs    func() ([]int, error)
+
+	configPath string
+	expandEnv  bool
+	outputPath string
+	pids       common_cli.StringsFlag
+}
+
+func (c *dumpAttestationCommand) Help() string {
+	// ignoring parsing errors since "-h" is always supported by the flags package
+	_ = c.parseFlags([]string{"-h"})
+	return ""
+}
+
+func (c *dumpAttestationCommand) Synopsis() string {
+	return "Dumps the node attestation data and workload selectors produced by the agent plugins"
+}
+
+func (c *dumpAttestationCommand) Run(args []string) int {
+	if err := c.parseFlags(args); err != nil {
+		return 1
+	}
+	if err := c.run(context.Background()); err != nil {
+		// Ignore error since a failure to write to stderr cannot very well be
+		// reported
+		_ = c.env.ErrPrintf("Failed to dump attestation: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (c *dumpAttestationCommand) parseFlags(args []string) error {
+	fs := flag.NewFlagSet(dumpAttestationCommandName, flag.ContinueOnError)
+	fs.SetOutput(c.env.Stderr)
+	fs.StringVar(&c.configPath, "config", "", "Path to a SPIRE agent configuration file (defaults to the one used by run)")
+	fs.BoolVar(&c.expandEnv, "expandEnv", false, "Expand environment variables in SPIRE config file")
+	fs.StringVar(&c.outputPath, "output", "", "File to write the dump to (defaults to stdout)")
+	fs.Var(&c.pids, "pid", "PID of a workload to attest. Can be used more than once. Defaults to all running processes")
+	return fs.Parse(args)
+}
+
+func (c *dumpAttestationCommand) run(ctx context.Context) error {
+	pids, err := c.workloadPIDs()
+	if err != nil {
+		return err
+	}
+
+	var runArgs []string
+	if c.configPath != "" {
+		runArgs = append(runArgs, "-config", c.configPath)
+	}
+	if c.expandEnv {
+		runArgs = append(runArgs, "-expandEnv")
+	}
+
+	// Logs go to stderr so they don't get mixed with a dump written to stdout
+	logToStderr := func(logger *log.Logger) error {
+		logger.SetOutput(c.env.Stderr)
+		return nil
+	}
+
+	config, err := run.LoadConfig(dumpAttestationCommandName, runArgs, []log.Option{logToStderr}, c.env.Stderr, false)
+	if err != nil {
+		return err
+	}
+
+	cat, closer, err := c.loadCatalog(ctx, config)
+	if err != nil {
+		return fmt.Errorf("unable to load plugins: %w", err)
+	}
+	defer closer.Close()
+
+	dump := dumpAttestation(ctx, cat, config.Log, config.JoinToken, pids)
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if c.outputPath == "" {
+		_, err = c.env.Stdout.Write(data)
+		return err
+	}
+
+	// The dump can reveal details about the host, so it is only readable by
+	// the owner
+	if err := ioutil.WriteFile(c.outputPath, data, 0600); err != nil {
+		return err
+	}
+	return c.env.Printf("Attestation dump written to %s\n", c.outputPath)
+}
+
+func (c *dumpAttestationCommand) workloadPIDs() ([]int, error) {
+	if len(c.pids) == 0 {
+		return c.listPIDs()
+	}
+
+	pids := make([]int, 0, len(c.pids))
+	for _, value := range c.pids {
+		pid, err := strconv.Atoi(value)
+		if err != nil || pid <= 0 {
+			return nil, fmt.Errorf("invalid pid %q", value)
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+func dumpAttestation(ctx context.Context, cat catalog.Catalog, log logrus.FieldLogger, joinToken string, pids []int) *AttestationDump {
+	attestor := nodeattestor.JoinToken(log, joinToken)
+	if joinToken == "" {
+		attestor = cat.GetNodeAttestor()
+	}
+
+	dump := &AttestationDump{
+		NodeAttestation: dumpNodeAttestation(ctx, attestor),
+		Workloads:       []WorkloadDump{},
+	}
+	for _, pid := range pids {
+		dump.Workloads = append(dump.Workloads, dumpWorkload(ctx, cat, pid))
+	}
+	return dump
+}
+
+func dumpNodeAttestation(ctx context.Context, attestor nodeattestor.NodeAttestor) NodeAttestationDump {
+	stream := new(captureStream)
+	err := attestor.Attest(ctx, stream)
+	if stream.attestationData == nil {
+		if err == nil {
+			err = errors.New("plugin did not produce attestation data")
+		}
+		return NodeAttestationDump{
+			Type:  attestor.Name(),
+			Error: err.Error(),
+		}
+	}
+
+	return NodeAttestationDump{
+		Type:        stream.attestationData.Type,
+		Payload:     redactPayload(stream.attestationData.Type, stream.attestationData.Payload),
+		PayloadSize: len(stream.attestationData.Payload),
+	}
+}
+
+func dumpWorkload(ctx context.Context, cat catalog.Catalog, pid int) WorkloadDump {
+	dump := WorkloadDump{
+		PID:       pid,
+		Attestors: []WorkloadAttestorDump{},
+	}
+	for _, attestor := range cat.GetWorkloadAttestors() {
+		attestorDump := WorkloadAttestorDump{
+			Name: attestor.Name(),
+		}
+		selectors, err := attestor.Attest(ctx, pid)
+		if err != nil {
+			attestorDump.Error = err.Error()
+		}
+		for _, selector := range selectors {
+			attestorDump.Selectors = append(attestorDump.Selectors, SelectorDump{
+				Type:  selector.Type,
+				Value: selector.Value,
+			})
+		}
+		dump.Attestors = append(dump.Attestors, attestorDump)
+	}
+	return dump
+}
+
+// captureStream captures the attestation data sent by a node attestor
+// without contacting the server. Since no challenge is ever issued, the
+// attestation ends right after the attestation data is sent.
+type captureStream struct {
+	attestationData *nodeattestor.AttestationData
+}
+
+func (s *captureStream) SendAttestationData(ctx context.Context, attestationData nodeattestor.AttestationData) ([]byte, error) {
+	s.attestationData = &attestationData
+	return nil, nil
+}
+
+func (s *captureStream) SendChallengeResponse(ctx context.Context, response []byte) ([]byte, error) {
+	return nil, errors.New("unexpected challenge response")
+}
+
+func loadCatalog(ctx context.Context, config *agent.Config) (catalog.Catalog, io.Closer, error) {
+	repo, err := catalog.Load(ctx, catalog.Config{
+		Log:          config.Log.WithField(telemetry.SubsystemName, telemetry.Catalog),
+		Metrics:      telemetry.Blackhole{},
+		TrustDomain:  config.TrustDomain,
+		PluginConfig: config.PluginConfigs,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return repo, repo, nil
+}
+
+// listPIDs returns the PIDs of the running processes, other than this one.
+func listPIDs() ([]int, error) {
+	entries, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list running processes, use -pid to select the workloads: %w", err)
+	}
+
+	self := os.Getpid()
+	var pids []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() || pid == self {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	return pids, nil
+}
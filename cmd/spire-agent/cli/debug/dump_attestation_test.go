@@ -0,0 +1,358 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/agent"
+	"github.com/spiffe/spire/pkg/agent/catalog"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakeagentcatalog"
+	"github.com/spiffe/spire/test/fakes/fakeagentnodeattestor"
+	"github.com/spiffe/spire/test/fakes/fakeworkloadattestor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const configTemplate = `
+agent {
+	data_dir = %q
+	server_address = "127.0.0.1"
+	server_port = 8081
+	trust_domain = "example.org"
+	insecure_bootstrap = true
+	%s
+}
+
+plugins {}
+`
+
+func TestDumpAttestation(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		extraConfig  string
+		args         []string
+		nodeConfig   fakeagentnodeattestor.Config
+		expectedDump string
+		expectErr    string
+	}{
+		{
+			name: "secret fields of the payload are redacted",
+			nodeConfig: fakeagentnodeattestor.Config{
+				Type:    "k8s_psat",
+				Payload: []byte(`{"cluster":"production","token":"SECRET","nested":{"count":3,"clientSecret":"SECRET"}}`),
+			},
+			expectedDump: `{
+  "node_attestation": {
+    "type": "k8s_psat",
+    "payload": {
+      "cluster": "production",
+      "nested": {
+        "clientSecret": "[REDACTED]",
+        "count": 3
+      },
+      "token": "[REDACTED]"
+    },
+    "payload_size": 86
+  },
+  "workloads": [
+    {
+      "pid": 1,
+      "attestors": [
+        {
+          "name": "fake1",
+          "selectors": [
+            {
+              "type": "unix",
+              "value": "uid:0"
+            }
+          ]
+        },
+        {
+          "name": "fake2",
+          "error": "rpc error: code = Unknown desc = workloadattestor(fake2): cannot attest pid 1"
+        }
+      ]
+    },
+    {
+      "pid": 2,
+      "attestors": [
+        {
+          "name": "fake1",
+          "selectors": [
+            {
+              "type": "unix",
+              "value": "uid:1000"
+            }
+          ]
+        },
+        {
+          "name": "fake2",
+          "selectors": [
+            {
+              "type": "k8s",
+              "value": "ns:default"
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`,
+		},
+		{
+			name: "opaque payload is redacted",
+			args: []string{"-pid", "2"},
+			nodeConfig: fakeagentnodeattestor.Config{
+				Type:    "gcp_iit",
+				Payload: []byte("header.claims.signature"),
+			},
+			expectedDump: `{
+  "node_attestation": {
+    "type": "gcp_iit",
+    "payload": "[REDACTED]",
+    "payload_size": 23
+  },
+  "workloads": [
+    {
+      "pid": 2,
+      "attestors": [
+        {
+          "name": "fake1",
+          "selectors": [
+            {
+              "type": "unix",
+              "value": "uid:1000"
+            }
+          ]
+        },
+        {
+          "name": "fake2",
+          "selectors": [
+            {
+              "type": "k8s",
+              "value": "ns:default"
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`,
+		},
+		{
+			name:        "join token is redacted",
+			extraConfig: `join_token = "SECRET"`,
+			args:        []string{"-pid", "3"},
+			expectedDump: `{
+  "node_attestation": {
+    "type": "join_token",
+    "payload": "[REDACTED]",
+    "payload_size": 6
+  },
+  "workloads": [
+    {
+      "pid": 3,
+      "attestors": [
+        {
+          "name": "fake1",
+          "error": "rpc error: code = Unknown desc = workloadattestor(fake1): cannot attest pid 3"
+        },
+        {
+          "name": "fake2",
+          "error": "rpc error: code = Unknown desc = workloadattestor(fake2): cannot attest pid 3"
+        }
+      ]
+    }
+  ]
+}
+`,
+		},
+		{
+			name: "node attestor failure is reported",
+			args: []string{"-pid", "3"},
+			nodeConfig: fakeagentnodeattestor.Config{
+				Fail: true,
+			},
+			expectedDump: `{
+  "node_attestation": {
+    "type": "fake",
+    "payload_size": 0,
+    "error": "rpc error: code = Unknown desc = nodeattestor(fake): fetching attestation data failed by test"
+  },
+  "workloads": [
+    {
+      "pid": 3,
+      "attestors": [
+        {
+          "name": "fake1",
+          "error": "rpc error: code = Unknown desc = workloadattestor(fake1): cannot attest pid 3"
+        },
+        {
+          "name": "fake2",
+          "error": "rpc error: code = Unknown desc = workloadattestor(fake2): cannot attest pid 3"
+        }
+      ]
+    }
+  ]
+}
+`,
+		},
+		{
+			name:      "invalid pid",
+			args:      []string{"-pid", "foo"},
+			expectErr: "Failed to dump attestation: invalid pid \"foo\"\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, stdout, stderr := setupDumpAttestationCommand(t, tt.nodeConfig)
+			configPath := writeConfig(t, tt.extraConfig)
+
+			code := cmd.Run(append([]string{"-config", configPath}, tt.args...))
+			if tt.expectErr != "" {
+				assert.Equal(t, 1, code)
+				assert.Equal(t, tt.expectErr, stderr.String())
+				return
+			}
+			require.Equal(t, 0, code, "stderr: %s", stderr.String())
+			assert.Equal(t, tt.expectedDump, stdout.String())
+		})
+	}
+}
+
+func TestDumpAttestationToFile(t *testing.T) {
+	cmd, stdout, stderr := setupDumpAttestationCommand(t, fakeagentnodeattestor.Config{})
+	configPath := writeConfig(t, "")
+	outputPath := filepath.Join(t.TempDir(), "dump.json")
+
+	code := cmd.Run([]string{"-config", configPath, "-output", outputPath, "-pid", "1"})
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Equal(t, fmt.Sprintf("Attestation dump written to %s\n", outputPath), stdout.String())
+
+	info, err := os.Stat(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	data, err := ioutil.ReadFile(outputPath)
+	require.NoError(t, err)
+	dump := new(AttestationDump)
+	require.NoError(t, json.Unmarshal(data, dump))
+	assert.Equal(t, "test", dump.NodeAttestation.Type)
+	assert.Equal(t, "[REDACTED]", dump.NodeAttestation.Payload)
+	require.Len(t, dump.Workloads, 1)
+	assert.Equal(t, 1, dump.Workloads[0].PID)
+}
+
+func TestDumpAttestationFailsToListPIDs(t *testing.T) {
+	cmd, _, stderr := setupDumpAttestationCommand(t, fakeagentnodeattestor.Config{})
+	cmd.listPIDs = func() ([]int, error) {
+		return nil, errors.New("oh no")
+	}
+
+	code := cmd.Run([]string{"-config", writeConfig(t, "")})
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "Failed to dump attestation: oh no\n", stderr.String())
+}
+
+func TestRedactPayload(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		attestationType string
+		payload         string
+		expected        interface{}
+	}{
+		{
+			name:            "join token",
+			attestationType: "join_token",
+			payload:         `{"cluster":"foo"}`,
+			expected:        "[REDACTED]",
+		},
+		{
+			name:            "object",
+			attestationType: "aws_iid",
+			payload:         `{"document":"DOC","signature":"SIG"}`,
+			expected:        map[string]interface{}{"document": "DOC", "signature": "[REDACTED]"},
+		},
+		{
+			name:            "array of objects",
+			attestationType: "x",
+			payload:         `[{"Password":"P","user":"U"}]`,
+			expected:        []interface{}{map[string]interface{}{"Password": "[REDACTED]", "user": "U"}},
+		},
+		{
+			name:            "JSON string",
+			attestationType: "x",
+			payload:         `"token"`,
+			expected:        "[REDACTED]",
+		},
+		{
+			name:            "trailing data",
+			attestationType: "x",
+			payload:         `{"a":"b"} {"c":"d"}`,
+			expected:        "[REDACTED]",
+		},
+		{
+			name:            "not JSON",
+			attestationType: "x",
+			payload:         "TOKEN",
+			expected:        "[REDACTED]",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, redactPayload(tt.attestationType, []byte(tt.payload)))
+		})
+	}
+}
+
+func setupDumpAttestationCommand(t *testing.T, nodeConfig fakeagentnodeattestor.Config) (*dumpAttestationCommand, *bytes.Buffer, *bytes.Buffer) {
+	cat := fakeagentcatalog.New()
+	cat.SetNodeAttestor(fakeagentnodeattestor.New(t, nodeConfig))
+	cat.SetWorkloadAttestors(
+		fakeworkloadattestor.New(t, "fake1", map[int32][]*common.Selector{
+			1: {{Type: "unix", Value: "uid:0"}},
+			2: {{Type: "unix", Value: "uid:1000"}},
+		}),
+		fakeworkloadattestor.New(t, "fake2", map[int32][]*common.Selector{
+			2: {{Type: "k8s", Value: "ns:default"}},
+		}),
+	)
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newDumpAttestationCommand(&common_cli.Env{
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	cmd.loadCatalog = func(context.Context, *agent.Config) (catalog.Catalog, io.Closer, error) {
+		return cat, nopCloser{}, nil
+	}
+	cmd.listPIDs = func() ([]int, error) {
+		return []int{1, 2}, nil
+	}
+	return cmd, stdout, stderr
+}
+
+func writeConfig(t *testing.T, extraConfig string) string {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "agent.conf")
+	config := fmt.Sprintf(configTemplate, filepath.Join(dir, "data"), extraConfig)
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(config), 0600))
+	return configPath
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
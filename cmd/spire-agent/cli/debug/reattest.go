@@ -0,0 +1,91 @@
+package debug
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/api/workload/dial"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/agent/attestation"
+)
+
+const reattestCommandName = "debug reattest"
+
+func NewReattestCommand() cli.Command {
+	return newReattestCommand(common_cli.DefaultEnv)
+}
+
+func newReattestCommand(env *common_cli.Env) *reattestCommand {
+	return &reattestCommand{
+		env:     env,
+		timeout: common_cli.DurationFlag(30 * time.Second),
+	}
+}
+
+type reattestCommand struct {
+	env *common_cli.Env
+
+	adminSocketPath string
+	timeout         common_cli.DurationFlag
+}
+
+func (c *reattestCommand) Help() string {
+	// ignoring parsing errors since "-h" is always supported by the flags package
+	_ = c.parseFlags([]string{"-h"})
+	return ""
+}
+
+func (c *reattestCommand) Synopsis() string {
+	return "Makes the running agent attest its node again, refreshing its node selectors"
+}
+
+func (c *reattestCommand) Run(args []string) int {
+	if err := c.parseFlags(args); err != nil {
+		return 1
+	}
+	if err := c.run(context.Background()); err != nil {
+		// Ignore error since a failure to write to stderr cannot very well be
+		// reported
+		_ = c.env.ErrPrintf("Failed to reattest: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (c *reattestCommand) parseFlags(args []string) error {
+	fs := flag.NewFlagSet(reattestCommandName, flag.ContinueOnError)
+	fs.SetOutput(c.env.Stderr)
+	fs.StringVar(&c.adminSocketPath, "adminSocketPath", "", "Path to the SPIRE Agent admin API socket (see admin_socket_path)")
+	fs.Var(&c.timeout, "timeout", "Time to wait for the reattestation to finish")
+	return fs.Parse(args)
+}
+
+func (c *reattestCommand) run(ctx context.Context) error {
+	if c.adminSocketPath == "" {
+		return errors.New("adminSocketPath must be set")
+	}
+
+	conn, err := dial.Dial(ctx, &net.UnixAddr{
+		Name: c.adminSocketPath,
+		Net:  "unix",
+	})
+	if err != nil {
+		return fmt.Errorf("unable to connect to the agent admin API: %w", err)
+	}
+	defer conn.Close()
+	client := attestation.NewAttestationClient(conn)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.timeout))
+	defer cancel()
+	resp, err := client.Reattest(ctx, &attestation.ReattestRequest{})
+	if err != nil {
+		return err
+	}
+
+	return c.env.Printf("Agent reattested\nSPIFFE ID : %s\nExpires at: %s\n", resp.SpiffeId, time.Unix(resp.ExpiresAt, 0).UTC())
+}
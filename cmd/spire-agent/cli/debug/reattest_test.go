@@ -0,0 +1,99 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/agent/attestation"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReattest(t *testing.T) {
+	expiresAt := time.Date(2030, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name         string
+		serverErr    error
+		expectStdout string
+		expectStderr string
+	}{
+		{
+			name: "success",
+			expectStdout: `Agent reattested
+SPIFFE ID : spiffe://example.org/spire/agent/aws_iid/123/us-east-1/i-1234
+Expires at: 2030-01-02 03:04:05 +0000 UTC
+`,
+		},
+		{
+			name:         "server failure",
+			serverErr:    status.Error(codes.Internal, "failed to reattest: oh no"),
+			expectStderr: "Failed to reattest: rpc error: code = Internal desc = failed to reattest: oh no\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeAttestationServer{
+				resp: &attestation.ReattestResponse{
+					SpiffeId:  "spiffe://example.org/spire/agent/aws_iid/123/us-east-1/i-1234",
+					ExpiresAt: expiresAt.Unix(),
+				},
+				err: tt.serverErr,
+			}
+			socketPath := spiretest.StartGRPCSocketServerOnTempSocket(t, func(s *grpc.Server) {
+				attestation.RegisterAttestationServer(s, server)
+			})
+
+			stdout := new(bytes.Buffer)
+			stderr := new(bytes.Buffer)
+			cmd := newReattestCommand(&common_cli.Env{
+				Stdout: stdout,
+				Stderr: stderr,
+			})
+
+			rc := cmd.Run([]string{"-adminSocketPath", socketPath})
+			assert.Equal(t, 1, server.calls)
+			if tt.expectStderr != "" {
+				assert.Equal(t, 1, rc)
+				assert.Equal(t, tt.expectStderr, stderr.String())
+				return
+			}
+			require.Equal(t, 0, rc, "stderr: %s", stderr.String())
+			assert.Equal(t, tt.expectStdout, stdout.String())
+		})
+	}
+}
+
+func TestReattestRequiresAdminSocketPath(t *testing.T) {
+	stderr := new(bytes.Buffer)
+	cmd := newReattestCommand(&common_cli.Env{
+		Stdout: new(bytes.Buffer),
+		Stderr: stderr,
+	})
+
+	assert.Equal(t, 1, cmd.Run(nil))
+	assert.Equal(t, "Failed to reattest: adminSocketPath must be set\n", stderr.String())
+}
+
+type fakeAttestationServer struct {
+	attestation.UnimplementedAttestationServer
+
+	resp  *attestation.ReattestResponse
+	err   error
+	calls int
+}
+
+func (s *fakeAttestationServer) Reattest(ctx context.Context, req *attestation.ReattestRequest) (*attestation.ReattestResponse, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.resp, nil
+}
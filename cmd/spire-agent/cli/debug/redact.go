@@ -0,0 +1,65 @@
+package debug
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// secretFieldMarkers are the substrings of the payload field names (in lower
+// case) whose values are redacted from the dump.
+var secretFieldMarkers = []string{"token", "secret", "password", "signature", "private"}
+
+// redactPayload returns a representation of the attestation payload that is
+// safe to share. JSON payloads are kept with the values of secret looking
+// fields redacted. Any other payload is opaque and might be the credential
+// itself (e.g. a join token or a signed JWT), so it is redacted entirely.
+func redactPayload(attestationType string, payload []byte) interface{} {
+	if attestationType == "join_token" {
+		return redacted
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return redacted
+	}
+
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return redactValue(value)
+	default:
+		return redacted
+	}
+}
+
+func redactValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, fieldValue := range value {
+			if isSecretField(key) {
+				value[key] = redacted
+			} else {
+				value[key] = redactValue(fieldValue)
+			}
+		}
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = redactValue(elem)
+		}
+	}
+	return value
+}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, marker := range secretFieldMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
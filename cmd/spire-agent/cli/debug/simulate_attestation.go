@@ -0,0 +1,169 @@
+package debug
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/api/workload/dial"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/agent/simulation"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+const simulateAttestationCommandName = "debug simulate-attestation"
+
+func NewSimulateAttestationCommand() cli.Command {
+	return newSimulateAttestationCommand(common_cli.DefaultEnv)
+}
+
+func newSimulateAttestationCommand(env *common_cli.Env) *simulateAttestationCommand {
+	return &simulateAttestationCommand{
+		env:     env,
+		timeout: common_cli.DurationFlag(5 * time.Second),
+	}
+}
+
+type simulateAttestationCommand struct {
+	env *common_cli.Env
+
+	adminSocketPath string
+	selectors       common_cli.StringsFlag
+	timeout         common_cli.DurationFlag
+}
+
+func (c *simulateAttestationCommand) Help() string {
+	// ignoring parsing errors since "-h" is always supported by the flags package
+	_ = c.parseFlags([]string{"-h"})
+	return ""
+}
+
+func (c *simulateAttestationCommand) Synopsis() string {
+	return "Shows the entries and X509-SVIDs a workload attested with the given selectors would get from the running agent"
+}
+
+func (c *simulateAttestationCommand) Run(args []string) int {
+	if err := c.parseFlags(args); err != nil {
+		return 1
+	}
+	if err := c.run(context.Background()); err != nil {
+		// Ignore error since a failure to write to stderr cannot very well be
+		// reported
+		_ = c.env.ErrPrintf("Failed to simulate attestation: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (c *simulateAttestationCommand) parseFlags(args []string) error {
+	fs := flag.NewFlagSet(simulateAttestationCommandName, flag.ContinueOnError)
+	fs.SetOutput(c.env.Stderr)
+	fs.StringVar(&c.adminSocketPath, "adminSocketPath", "", "Path to the SPIRE Agent admin API socket (see admin_socket_path)")
+	fs.Var(&c.selectors, "selector", "A colon-delimited type:value selector the workload would be attested with. Can be used more than once")
+	fs.Var(&c.timeout, "timeout", "Time to wait for a response")
+	return fs.Parse(args)
+}
+
+func (c *simulateAttestationCommand) run(ctx context.Context) error {
+	if c.adminSocketPath == "" {
+		return errors.New("adminSocketPath must be set")
+	}
+	if len(c.selectors) == 0 {
+		return errors.New("at least one selector is required")
+	}
+
+	selectors := make([]*common.Selector, 0, len(c.selectors))
+	for _, value := range c.selectors {
+		selector, err := parseSelector(value)
+		if err != nil {
+			return err
+		}
+		selectors = append(selectors, selector)
+	}
+
+	conn, err := dial.Dial(ctx, &net.UnixAddr{
+		Name: c.adminSocketPath,
+		Net:  "unix",
+	})
+	if err != nil {
+		return fmt.Errorf("unable to connect to the agent admin API: %w", err)
+	}
+	defer conn.Close()
+	client := simulation.NewSimulationClient(conn)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.timeout))
+	defer cancel()
+	resp, err := client.SimulateAttestation(ctx, &simulation.SimulateAttestationRequest{
+		Selectors: selectors,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.printEntries(resp.Entries)
+}
+
+func (c *simulateAttestationCommand) printEntries(entries []*simulation.MatchedEntry) error {
+	if len(entries) == 1 {
+		if err := c.env.Println("Found 1 matching entry"); err != nil {
+			return err
+		}
+	} else if err := c.env.Printf("Found %d matching entries\n", len(entries)); err != nil {
+		return err
+	}
+
+	for _, matched := range entries {
+		if err := c.printEntry(matched); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *simulateAttestationCommand) printEntry(matched *simulation.MatchedEntry) error {
+	var lines []string
+	addLine := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	entry := matched.Entry
+	addLine("Entry ID         : %s", entry.EntryId)
+	addLine("SPIFFE ID        : %s", entry.SpiffeId)
+	addLine("Parent ID        : %s", entry.ParentId)
+	for _, s := range entry.Selectors {
+		addLine("Selector         : %s:%s", s.Type, s.Value)
+	}
+	for _, id := range entry.FederatesWith {
+		addLine("FederatesWith    : %s", id)
+	}
+
+	if svid := matched.Svid; svid != nil && len(svid.CertChain) > 0 {
+		leaf, err := x509.ParseCertificate(svid.CertChain[0])
+		if err != nil {
+			return fmt.Errorf("unable to parse X509-SVID for entry %q: %w", entry.EntryId, err)
+		}
+		addLine("X509-SVID expiry : %s", time.Unix(svid.ExpiresAt, 0).UTC())
+		for _, dnsName := range leaf.DNSNames {
+			addLine("X509-SVID DNS    : %s", dnsName)
+		}
+	}
+
+	return c.env.Printf("\n%s\n", strings.Join(lines, "\n"))
+}
+
+func parseSelector(value string) (*common.Selector, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("selector %q must be formatted as type:value", value)
+	}
+	return &common.Selector{
+		Type:  parts[0],
+		Value: parts[1],
+	}, nil
+}
@@ -0,0 +1,183 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/agent/simulation"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSimulateAttestation(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	notAfter := time.Date(2030, time.January, 2, 3, 4, 5, 0, time.UTC)
+	svid := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/web"),
+		testca.WithLifetime(notAfter.Add(-time.Hour), notAfter),
+		testca.WithDNSNames("web.example.org"))
+
+	webEntry := &simulation.MatchedEntry{
+		Entry: &common.RegistrationEntry{
+			EntryId:  "web",
+			SpiffeId: "spiffe://example.org/web",
+			ParentId: "spiffe://example.org/agent",
+			Selectors: []*common.Selector{
+				{Type: "unix", Value: "uid:1000"},
+				{Type: "unix", Value: "gid:1000"},
+			},
+			FederatesWith: []string{"spiffe://domain.test"},
+		},
+		Svid: &simulation.X509SVID{
+			CertChain: [][]byte{svid.Certificates[0].Raw},
+			ExpiresAt: notAfter.Unix(),
+		},
+	}
+	dbEntry := &simulation.MatchedEntry{
+		Entry: &common.RegistrationEntry{
+			EntryId:   "db",
+			SpiffeId:  "spiffe://example.org/db",
+			ParentId:  "spiffe://example.org/agent",
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		},
+	}
+
+	for _, tt := range []struct {
+		name            string
+		args            []string
+		entries         []*simulation.MatchedEntry
+		serverErr       error
+		expectSelectors []*common.Selector
+		expectStdout    string
+		expectStderr    string
+	}{
+		{
+			name:    "multiple matching entries",
+			args:    []string{"-selector", "unix:uid:1000", "-selector", "unix:gid:1000"},
+			entries: []*simulation.MatchedEntry{webEntry, dbEntry},
+			expectSelectors: []*common.Selector{
+				{Type: "unix", Value: "uid:1000"},
+				{Type: "unix", Value: "gid:1000"},
+			},
+			expectStdout: `Found 2 matching entries
+
+Entry ID         : web
+SPIFFE ID        : spiffe://example.org/web
+Parent ID        : spiffe://example.org/agent
+Selector         : unix:uid:1000
+Selector         : unix:gid:1000
+FederatesWith    : spiffe://domain.test
+X509-SVID expiry : 2030-01-02 03:04:05 +0000 UTC
+X509-SVID DNS    : web.example.org
+
+Entry ID         : db
+SPIFFE ID        : spiffe://example.org/db
+Parent ID        : spiffe://example.org/agent
+Selector         : unix:uid:1000
+`,
+		},
+		{
+			name:            "single matching entry",
+			args:            []string{"-selector", "unix:uid:1000"},
+			entries:         []*simulation.MatchedEntry{dbEntry},
+			expectSelectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			expectStdout: `Found 1 matching entry
+
+Entry ID         : db
+SPIFFE ID        : spiffe://example.org/db
+Parent ID        : spiffe://example.org/agent
+Selector         : unix:uid:1000
+`,
+		},
+		{
+			name:            "no matching entries",
+			args:            []string{"-selector", "k8s:ns:default"},
+			expectSelectors: []*common.Selector{{Type: "k8s", Value: "ns:default"}},
+			expectStdout:    "Found 0 matching entries\n",
+		},
+		{
+			name:         "no selectors",
+			expectStderr: "Failed to simulate attestation: at least one selector is required\n",
+		},
+		{
+			name:         "malformed selector",
+			args:         []string{"-selector", "unix"},
+			expectStderr: "Failed to simulate attestation: selector \"unix\" must be formatted as type:value\n",
+		},
+		{
+			name:            "server failure",
+			args:            []string{"-selector", "unix:uid:1000"},
+			serverErr:       status.Error(codes.Internal, "oh no"),
+			expectSelectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			expectStderr:    "Failed to simulate attestation: rpc error: code = Internal desc = oh no\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeSimulationServer{
+				entries: tt.entries,
+				err:     tt.serverErr,
+			}
+			socketPath := spiretest.StartGRPCSocketServerOnTempSocket(t, func(s *grpc.Server) {
+				simulation.RegisterSimulationServer(s, server)
+			})
+
+			stdout := new(bytes.Buffer)
+			stderr := new(bytes.Buffer)
+			cmd := newSimulateAttestationCommand(&common_cli.Env{
+				Stdout: stdout,
+				Stderr: stderr,
+			})
+
+			args := append([]string{"-adminSocketPath", socketPath}, tt.args...)
+			rc := cmd.Run(args)
+			if tt.expectStderr != "" {
+				assert.Equal(t, 1, rc)
+				assert.Equal(t, tt.expectStderr, stderr.String())
+				return
+			}
+			require.Equal(t, 0, rc, "stderr: %s", stderr.String())
+			assert.Equal(t, tt.expectStdout, stdout.String())
+			spiretest.AssertProtoListEqual(t, tt.expectSelectors, server.selectors)
+		})
+	}
+}
+
+func TestSimulateAttestationRequiresAdminSocketPath(t *testing.T) {
+	stderr := new(bytes.Buffer)
+	cmd := newSimulateAttestationCommand(&common_cli.Env{
+		Stdout: new(bytes.Buffer),
+		Stderr: stderr,
+	})
+
+	assert.Equal(t, 1, cmd.Run([]string{"-selector", "unix:uid:1000"}))
+	assert.Equal(t, "Failed to simulate attestation: adminSocketPath must be set\n", stderr.String())
+}
+
+type fakeSimulationServer struct {
+	simulation.UnimplementedSimulationServer
+
+	entries   []*simulation.MatchedEntry
+	err       error
+	selectors []*common.Selector
+}
+
+func (s *fakeSimulationServer) SimulateAttestation(ctx context.Context, req *simulation.SimulateAttestationRequest) (*simulation.SimulateAttestationResponse, error) {
+	s.selectors = req.Selectors
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &simulation.SimulateAttestationResponse{
+		Entries: s.entries,
+	}, nil
+}
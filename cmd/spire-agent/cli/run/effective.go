@@ -0,0 +1,49 @@
+package run
+
+import (
+	"io"
+
+	"github.com/spiffe/spire/pkg/common/catalog"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/jwtsvid"
+	"github.com/spiffe/spire/pkg/common/log"
+)
+
+// pluginEnvFallbacks are the configurables the built-in agent plugins read
+// from the environment when they are not set.
+var pluginEnvFallbacks = []catalog.EnvFallback{
+	{PluginType: "WorkloadAttestor", PluginName: "k8s", Key: "node_name", Env: "MY_NODE_NAME", EnvKey: "node_name_env"},
+}
+
+// LoadEffectiveConfig loads the configuration like LoadConfig and returns it
+// as the agent resolves it, with the defaults and the values the built-in
+// plugins read from the environment filled in, and the secrets redacted.
+func LoadEffectiveConfig(name string, args []string, logOptions []log.Option, output io.Writer, allowUnknownConfig bool, lookupEnv func(string) (string, bool)) (map[string]interface{}, error) {
+	input, err := loadInput(name, args, output)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := NewAgentConfig(input, logOptions, allowUnknownConfig); err != nil {
+		return nil, err
+	}
+
+	// Fill in the defaults applied past the configuration parsing
+	if input.Agent.JWTSVIDValidationLeeway == "" {
+		input.Agent.JWTSVIDValidationLeeway = jwtsvid.DefaultLeeway.String()
+	}
+
+	var plugins catalog.HCLPluginConfigMap
+	if input.Plugins != nil {
+		plugins = *input.Plugins
+		input.Plugins = nil
+	}
+
+	effective := common_cli.EffectiveConfig(input)
+	effective["plugins"], err = catalog.EffectivePluginConfigs(plugins, pluginEnvFallbacks, lookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	common_cli.RedactSecrets(effective)
+	return effective, nil
+}
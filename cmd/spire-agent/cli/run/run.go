@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"os/user"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -23,9 +24,11 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/common"
 	"github.com/spiffe/spire/pkg/agent"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/health"
+	"github.com/spiffe/spire/pkg/common/jwtsvid"
 	"github.com/spiffe/spire/pkg/common/log"
 	"github.com/spiffe/spire/pkg/common/pemutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
@@ -58,12 +61,17 @@ type agentConfig struct {
 	AdminSocketPath               string    `hcl:"admin_socket_path"`
 	InsecureBootstrap             bool      `hcl:"insecure_bootstrap"`
 	JoinToken                     string    `hcl:"join_token"`
+	JWTSVIDValidationLeeway       string    `hcl:"jwt_svid_validation_leeway"`
 	LogFile                       string    `hcl:"log_file"`
 	LogFormat                     string    `hcl:"log_format"`
 	LogLevel                      string    `hcl:"log_level"`
+	ReattestInterval              string    `hcl:"reattest_interval"`
 	SDS                           sdsConfig `hcl:"sds"`
 	ServerAddress                 string    `hcl:"server_address"`
 	ServerPort                    int       `hcl:"server_port"`
+	SocketGroup                   string    `hcl:"socket_group"`
+	SocketMode                    string    `hcl:"socket_mode"`
+	SocketOwner                   string    `hcl:"socket_owner"`
 	SocketPath                    string    `hcl:"socket_path"`
 	TrustBundlePath               string    `hcl:"trust_bundle_path"`
 	TrustBundleURL                string    `hcl:"trust_bundle_url"`
@@ -89,7 +97,13 @@ type sdsConfig struct {
 }
 
 type experimentalConfig struct {
-	SyncInterval string `hcl:"sync_interval"`
+	SyncInterval        string `hcl:"sync_interval"`
+	JWTSVIDRefreshAhead string `hcl:"jwt_svid_refresh_ahead"`
+
+	WorkloadAttestationCacheTTL string `hcl:"workload_attestation_cache_ttl"`
+
+	EntryMatching       string         `hcl:"entry_matching"`
+	SelectorTypeWeights map[string]int `hcl:"selector_type_weights"`
 
 	UnusedKeys []string `hcl:",unusedKeys"`
 }
@@ -126,6 +140,17 @@ func Help(name string, writer io.Writer) string {
 }
 
 func LoadConfig(name string, args []string, logOptions []log.Option, output io.Writer, allowUnknownConfig bool) (*agent.Config, error) {
+	input, err := loadInput(name, args, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAgentConfig(input, logOptions, allowUnknownConfig)
+}
+
+// loadInput loads the configuration from the CLI flags and the config file,
+// with the defaults applied.
+func loadInput(name string, args []string, output io.Writer) (*Config, error) {
 	// First parse the CLI flags so we can get the config
 	// file path, if set
 	cliInput, err := parseFlags(name, args, output)
@@ -140,12 +165,7 @@ func LoadConfig(name string, args []string, logOptions []log.Option, output io.W
 		return nil, err
 	}
 
-	input, err := mergeInput(fileInput, cliInput)
-	if err != nil {
-		return nil, err
-	}
-
-	return NewAgentConfig(input, logOptions, allowUnknownConfig)
+	return mergeInput(fileInput, cliInput)
 }
 
 func (cmd *Command) Run(args []string) int {
@@ -352,6 +372,51 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 		}
 	}
 
+	if c.Agent.Experimental.JWTSVIDRefreshAhead != "" {
+		var err error
+		ac.JWTSVIDRefreshAhead, err = time.ParseDuration(c.Agent.Experimental.JWTSVIDRefreshAhead)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse JWT-SVID refresh ahead: %v", err)
+		}
+		if ac.JWTSVIDRefreshAhead < 0 {
+			return nil, fmt.Errorf("jwt_svid_refresh_ahead must not be negative: %s", c.Agent.Experimental.JWTSVIDRefreshAhead)
+		}
+	}
+
+	if c.Agent.Experimental.WorkloadAttestationCacheTTL != "" {
+		var err error
+		ac.WorkloadAttestationCacheTTL, err = time.ParseDuration(c.Agent.Experimental.WorkloadAttestationCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse workload attestation cache TTL: %v", err)
+		}
+	}
+
+	if c.Agent.ReattestInterval != "" {
+		var err error
+		ac.ReattestInterval, err = time.ParseDuration(c.Agent.ReattestInterval)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse reattest interval: %v", err)
+		}
+		if ac.ReattestInterval <= 0 {
+			return nil, fmt.Errorf("reattest_interval must be a positive duration: %s", c.Agent.ReattestInterval)
+		}
+		if c.Agent.JoinToken != "" {
+			return nil, errors.New("reattest_interval cannot be used with join_token since join tokens can only be used once")
+		}
+	}
+
+	ac.JWTSVIDValidationLeeway = jwtsvid.DefaultLeeway
+	if c.Agent.JWTSVIDValidationLeeway != "" {
+		var err error
+		ac.JWTSVIDValidationLeeway, err = time.ParseDuration(c.Agent.JWTSVIDValidationLeeway)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse JWT-SVID validation leeway: %v", err)
+		}
+		if ac.JWTSVIDValidationLeeway < 0 || ac.JWTSVIDValidationLeeway > jwtsvid.MaxLeeway {
+			return nil, fmt.Errorf("JWT-SVID validation leeway must be between 0 and %s", jwtsvid.MaxLeeway)
+		}
+	}
+
 	serverHostPort := net.JoinHostPort(c.Agent.ServerAddress, strconv.Itoa(c.Agent.ServerPort))
 	ac.ServerAddress = fmt.Sprintf("dns:///%s", serverHostPort)
 
@@ -372,11 +437,63 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 	}
 	ac.TrustDomain = td
 
+	switch c.Agent.Experimental.EntryMatching {
+	case "", "all":
+		if len(c.Agent.Experimental.SelectorTypeWeights) > 0 {
+			ac.Log.Warn("The selector_type_weights configurable is ignored unless entry_matching is set to most_specific")
+		}
+	case "most_specific":
+		for selectorType, weight := range c.Agent.Experimental.SelectorTypeWeights {
+			if weight < 0 {
+				return nil, fmt.Errorf("invalid weight %d for selector type %q: must be non-negative", weight, selectorType)
+			}
+		}
+		ac.EntryMatching = cache.EntryMatching{
+			MostSpecific:        true,
+			SelectorTypeWeights: c.Agent.Experimental.SelectorTypeWeights,
+		}
+	default:
+		return nil, fmt.Errorf("unknown entry_matching %q: must be all or most_specific", c.Agent.Experimental.EntryMatching)
+	}
+
 	ac.BindAddress = &net.UnixAddr{
 		Name: c.Agent.SocketPath,
 		Net:  "unix",
 	}
 
+	if c.Agent.SocketMode != "" {
+		ac.SocketMode, err = parseSocketMode(c.Agent.SocketMode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid socket_mode %q: %v", c.Agent.SocketMode, err)
+		}
+	}
+	if c.Agent.SocketOwner != "" {
+		uid, err := lookupSocketID(c.Agent.SocketOwner, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid socket_owner %q: %v", c.Agent.SocketOwner, err)
+		}
+		ac.SocketUID = &uid
+	}
+	if c.Agent.SocketGroup != "" {
+		gid, err := lookupSocketID(c.Agent.SocketGroup, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid socket_group %q: %v", c.Agent.SocketGroup, err)
+		}
+		ac.SocketGID = &gid
+	}
+
 	if c.Agent.AdminSocketPath != "" {
 		socketPathAbs, err := filepath.Abs(c.Agent.SocketPath)
 		if err != nil {
@@ -544,6 +661,36 @@ func defaultConfig() *Config {
 	}
 }
 
+// parseSocketMode parses an octal file mode, e.g. "0660", with no bits set
+// other than the permission bits.
+func parseSocketMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, errors.New("must be an octal file mode")
+	}
+	if mode == 0 || mode > uint64(os.ModePerm) {
+		return 0, errors.New("must be an octal file mode between 0001 and 0777")
+	}
+	return os.FileMode(mode), nil
+}
+
+// lookupSocketID returns the numeric user or group ID for the given value,
+// which is either the ID itself or a name resolved with lookup.
+func lookupSocketID(value string, lookup func(name string) (string, error)) (int, error) {
+	id := value
+	if _, err := strconv.Atoi(value); err != nil {
+		id, err = lookup(value)
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a valid ID", id)
+	}
+	return n, nil
+}
+
 func parseTrustBundle(path string) ([]*x509.Certificate, error) {
 	bundle, err := pemutil.LoadCertificates(path)
 	if err != nil {
@@ -11,12 +11,15 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/hcl/hcl/printer"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/spire/pkg/agent"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
 	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/jwtsvid"
 	"github.com/spiffe/spire/pkg/common/log"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/util"
@@ -662,6 +665,91 @@ func TestNewAgentConfig(t *testing.T) {
 				require.Equal(t, "unix", c.BindAddress.Net)
 			},
 		},
+		{
+			msg: "socket permissions should default to unset",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, os.FileMode(0), c.SocketMode)
+				require.Nil(t, c.SocketUID)
+				require.Nil(t, c.SocketGID)
+			},
+		},
+		{
+			msg: "socket_mode should be parsed as octal",
+			input: func(c *Config) {
+				c.Agent.SocketMode = "0660"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, os.FileMode(0660), c.SocketMode)
+			},
+		},
+		{
+			msg:         "invalid socket_mode should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.SocketMode = "rw-rw----"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "socket_mode with non permission bits should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.SocketMode = "1777"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "numeric socket_owner and socket_group should be used as IDs",
+			input: func(c *Config) {
+				c.Agent.SocketOwner = "1234"
+				c.Agent.SocketGroup = "5678"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.NotNil(t, c.SocketUID)
+				require.Equal(t, 1234, *c.SocketUID)
+				require.NotNil(t, c.SocketGID)
+				require.Equal(t, 5678, *c.SocketGID)
+			},
+		},
+		{
+			msg: "socket_owner and socket_group should be resolved by name",
+			input: func(c *Config) {
+				c.Agent.SocketOwner = "root"
+				c.Agent.SocketGroup = "root"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.NotNil(t, c.SocketUID)
+				require.Equal(t, 0, *c.SocketUID)
+				require.NotNil(t, c.SocketGID)
+				require.Equal(t, 0, *c.SocketGID)
+			},
+		},
+		{
+			msg:         "unknown socket_owner should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.SocketOwner = "no-such-spire-user"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "unknown socket_group should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.SocketGroup = "no-such-spire-group"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "insecure_bootsrap should be correctly set to false",
 			input: func(c *Config) {
@@ -787,6 +875,190 @@ func TestNewAgentConfig(t *testing.T) {
 				require.Nil(t, c)
 			},
 		},
+		{
+			msg: "jwt_svid_refresh_ahead parses a duration",
+			input: func(c *Config) {
+				c.Agent.Experimental.JWTSVIDRefreshAhead = "30s"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.EqualValues(t, 30000000000, c.JWTSVIDRefreshAhead)
+			},
+		},
+		{
+			msg:         "invalid jwt_svid_refresh_ahead returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.Experimental.JWTSVIDRefreshAhead = "moo"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "negative jwt_svid_refresh_ahead returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.Experimental.JWTSVIDRefreshAhead = "-1m"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "workload_attestation_cache_ttl parses a duration",
+			input: func(c *Config) {
+				c.Agent.Experimental.WorkloadAttestationCacheTTL = "5s"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.EqualValues(t, 5000000000, c.WorkloadAttestationCacheTTL)
+			},
+		},
+		{
+			msg:         "invalid workload_attestation_cache_ttl returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.Experimental.WorkloadAttestationCacheTTL = "moo"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "reattest_interval is not set by default",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Zero(t, c.ReattestInterval)
+			},
+		},
+		{
+			msg: "reattest_interval parses a duration",
+			input: func(c *Config) {
+				c.Agent.ReattestInterval = "6h"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, 6*time.Hour, c.ReattestInterval)
+			},
+		},
+		{
+			msg:         "invalid reattest_interval returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.ReattestInterval = "moo"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "zero reattest_interval returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.ReattestInterval = "0s"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "reattest_interval with join_token returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.ReattestInterval = "6h"
+				c.Agent.JoinToken = "foo"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "jwt_svid_validation_leeway defaults to the default leeway",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, jwtsvid.DefaultLeeway, c.JWTSVIDValidationLeeway)
+			},
+		},
+		{
+			msg: "jwt_svid_validation_leeway parses a duration",
+			input: func(c *Config) {
+				c.Agent.JWTSVIDValidationLeeway = "2m"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, 2*time.Minute, c.JWTSVIDValidationLeeway)
+			},
+		},
+		{
+			msg:         "invalid jwt_svid_validation_leeway returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.JWTSVIDValidationLeeway = "moo"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "jwt_svid_validation_leeway above the maximum returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.JWTSVIDValidationLeeway = "6m"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "negative jwt_svid_validation_leeway returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.JWTSVIDValidationLeeway = "-1m"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "entry_matching defaults to all",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, cache.EntryMatching{}, c.EntryMatching)
+			},
+		},
+		{
+			msg: "entry_matching most_specific is correctly configured",
+			input: func(c *Config) {
+				c.Agent.Experimental.EntryMatching = "most_specific"
+				c.Agent.Experimental.SelectorTypeWeights = map[string]int{"k8s": 2}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, cache.EntryMatching{
+					MostSpecific:        true,
+					SelectorTypeWeights: map[string]int{"k8s": 2},
+				}, c.EntryMatching)
+			},
+		},
+		{
+			msg:         "unknown entry_matching returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.Experimental.EntryMatching = "best"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "negative selector_type_weights returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.Experimental.EntryMatching = "most_specific"
+				c.Agent.Experimental.SelectorTypeWeights = map[string]int{"k8s": -1}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "admin_socket_path should be correctly configured",
 			input: func(c *Config) {
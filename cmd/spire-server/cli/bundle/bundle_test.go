@@ -1,12 +1,16 @@
 package bundle
 
 import (
+	"crypto"
 	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"path/filepath"
 	"testing"
 
+	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/pemutil"
@@ -14,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"gopkg.in/square/go-jose.v2"
 )
 
 func TestShowHelp(t *testing.T) {
@@ -22,7 +27,7 @@ func TestShowHelp(t *testing.T) {
 
 	require.Equal(t, `Usage of bundle show:
   -format string
-    	The format to show the bundle. Either "pem" or "spiffe". (default "pem")
+    	The format to show the bundle. Either "pem", "der", "spiffe" or "jwks". (default "pem")
   -registrationUDSPath string
     	Path to the SPIRE Server API socket (deprecated; use -socketPath)
   -socketPath string
@@ -30,6 +35,55 @@ func TestShowHelp(t *testing.T) {
 `, test.stderr.String())
 }
 
+func TestShowFormats(t *testing.T) {
+	test := setupTest(t, newShowCommand)
+	test.server.bundles = []*types.Bundle{{
+		TrustDomain: "spiffe://example.test",
+		X509Authorities: []*types.X509Certificate{
+			{Asn1: test.cert1.Raw},
+			{Asn1: test.cert2.Raw},
+		},
+		JwtAuthorities: []*types.JWTKey{
+			{KeyId: "KID", PublicKey: test.key1Pkix},
+		},
+	}}
+
+	show := func(format string) []byte {
+		test.stdout.Reset()
+		require.Equal(t, 0, test.client.Run(append(test.args, "-format", format)))
+		return test.stdout.Bytes()
+	}
+
+	t.Run("pem", func(t *testing.T) {
+		certs, err := pemutil.ParseCertificates(show(formatPEM))
+		require.NoError(t, err)
+		require.Equal(t, []*x509.Certificate{test.cert1, test.cert2}, certs)
+	})
+
+	t.Run("der", func(t *testing.T) {
+		certs, err := x509.ParseCertificates(show(formatDER))
+		require.NoError(t, err)
+		require.Equal(t, []*x509.Certificate{test.cert1, test.cert2}, certs)
+	})
+
+	t.Run("spiffe", func(t *testing.T) {
+		bundle, err := spiffebundle.Parse(spiffeid.RequireTrustDomainFromString("example.test"), show(formatSPIFFE))
+		require.NoError(t, err)
+		require.Equal(t, []*x509.Certificate{test.cert1, test.cert2}, bundle.X509Authorities())
+		require.Equal(t, map[string]crypto.PublicKey{"KID": test.cert1.PublicKey}, bundle.JWTAuthorities())
+	})
+
+	t.Run("jwks", func(t *testing.T) {
+		var jwks jose.JSONWebKeySet
+		require.NoError(t, json.Unmarshal(show(formatJWKS), &jwks))
+		require.Len(t, jwks.Keys, 1)
+		require.Equal(t, "KID", jwks.Keys[0].KeyID)
+		require.Equal(t, "sig", jwks.Keys[0].Use)
+		require.Equal(t, test.cert1.PublicKey, jwks.Keys[0].Key)
+		require.Empty(t, jwks.Keys[0].Certificates)
+	})
+}
+
 func TestShowSynopsis(t *testing.T) {
 	test := setupTest(t, newShowCommand)
 	require.Equal(t, "Prints server CA bundle to stdout", test.client.Synopsis())
@@ -18,6 +18,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/zeebo/errs"
+	"gopkg.in/square/go-jose.v2"
 )
 
 const (
@@ -27,6 +28,8 @@ const (
 `
 	formatPEM    = "pem"
 	formatSPIFFE = "spiffe"
+	formatDER    = "der"
+	formatJWKS   = "jwks"
 )
 
 // loadParamData loads the data from a parameter. If the parameter is empty then
@@ -71,6 +74,50 @@ func printCACertsPEM(out io.Writer, caCerts []byte) error {
 	return nil
 }
 
+// printX509AuthoritiesDER prints the ASN.1 DER data of the provided
+// certificates, concatenated, into writer
+func printX509AuthoritiesDER(out io.Writer, certs []*types.X509Certificate) error {
+	for _, cert := range certs {
+		if _, err := x509.ParseCertificates(cert.Asn1); err != nil {
+			return fmt.Errorf("unable to parse certificates ASN.1 DER data: %v", err)
+		}
+		if _, err := out.Write(cert.Asn1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printJWTAuthoritiesJWKS prints the provided JWT keys into writer as a
+// JSON Web Key Set, without the SPIFFE bundle extensions
+func printJWTAuthoritiesJWKS(out io.Writer, keys []*types.JWTKey) error {
+	jwks := jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{},
+	}
+	for i, key := range keys {
+		publicKey, err := x509.ParsePKIXPublicKey(key.PublicKey)
+		if err != nil {
+			return fmt.Errorf("unable to parse JWT signing key %d: %v", i, err)
+		}
+		jwks.Keys = append(jwks.Keys, jose.JSONWebKey{
+			Key:   publicKey,
+			KeyID: key.KeyId,
+			Use:   "sig",
+		})
+	}
+
+	docBytes, err := json.MarshalIndent(jwks, "", "    ")
+	if err != nil {
+		return errs.Wrap(err)
+	}
+
+	if _, err := fmt.Fprintln(out, string(docBytes)); err != nil {
+		return errs.Wrap(err)
+	}
+
+	return nil
+}
+
 // printBundle marshals and prints the bundle using the provided writer
 func printBundle(out io.Writer, bundle *types.Bundle) error {
 	b, err := bundleFromProto(bundle)
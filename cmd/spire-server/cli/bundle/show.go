@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"strings"
 
 	"github.com/mitchellh/cli"
 	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
@@ -33,7 +34,7 @@ func (c *showCommand) Synopsis() string {
 }
 
 func (c *showCommand) AppendFlags(fs *flag.FlagSet) {
-	fs.StringVar(&c.format, "format", formatPEM, fmt.Sprintf("The format to show the bundle. Either %q or %q.", formatPEM, formatSPIFFE))
+	fs.StringVar(&c.format, "format", formatPEM, fmt.Sprintf("The format to show the bundle. Either %q, %q, %q or %q.", formatPEM, formatDER, formatSPIFFE, formatJWKS))
 }
 
 func (c *showCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
@@ -43,5 +44,13 @@ func (c *showCommand) Run(ctx context.Context, env *common_cli.Env, serverClient
 		return err
 	}
 
+	// The DER and JWKS formats only hold part of the bundle, so they are
+	// only offered to show the bundle of the server
+	switch strings.ToLower(c.format) {
+	case formatDER:
+		return printX509AuthoritiesDER(env.Stdout, resp.X509Authorities)
+	case formatJWKS:
+		return printJWTAuthoritiesJWKS(env.Stdout, resp.JwtAuthorities)
+	}
 	return printBundleWithFormat(env.Stdout, resp, c.format, false)
 }
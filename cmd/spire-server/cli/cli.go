@@ -6,10 +6,13 @@ import (
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/spire/cmd/spire-server/cli/agent"
 	"github.com/spiffe/spire/cmd/spire-server/cli/bundle"
+	"github.com/spiffe/spire/cmd/spire-server/cli/config"
+	"github.com/spiffe/spire/cmd/spire-server/cli/datastore"
 	"github.com/spiffe/spire/cmd/spire-server/cli/entry"
 	"github.com/spiffe/spire/cmd/spire-server/cli/healthcheck"
 	"github.com/spiffe/spire/cmd/spire-server/cli/jwt"
 	"github.com/spiffe/spire/cmd/spire-server/cli/run"
+	"github.com/spiffe/spire/cmd/spire-server/cli/selectors"
 	"github.com/spiffe/spire/cmd/spire-server/cli/token"
 	"github.com/spiffe/spire/cmd/spire-server/cli/validate"
 	"github.com/spiffe/spire/cmd/spire-server/cli/x509"
@@ -55,6 +58,15 @@ func (cc *CLI) Run(args []string) int {
 		"bundle delete": func() (cli.Command, error) {
 			return bundle.NewDeleteCommand(), nil
 		},
+		"config show": func() (cli.Command, error) {
+			return config.NewShowCommand(), nil
+		},
+		"datastore explain": func() (cli.Command, error) {
+			return datastore.NewExplainCommand(), nil
+		},
+		"datastore migrate": func() (cli.Command, error) {
+			return datastore.NewMigrateCommand(), nil
+		},
 		"entry count": func() (cli.Command, error) {
 			return entry.NewCountCommand(), nil
 		},
@@ -70,6 +82,12 @@ func (cc *CLI) Run(args []string) int {
 		"entry show": func() (cli.Command, error) {
 			return entry.NewShowCommand(), nil
 		},
+		"entry restore": func() (cli.Command, error) {
+			return entry.NewRestoreCommand(), nil
+		},
+		"entry federate": func() (cli.Command, error) {
+			return entry.NewFederateCommand(), nil
+		},
 		"run": func() (cli.Command, error) {
 			return run.NewRunCommand(cc.LogOptions, cc.AllowUnknownConfig), nil
 		},
@@ -79,6 +97,9 @@ func (cc *CLI) Run(args []string) int {
 		"healthcheck": func() (cli.Command, error) {
 			return healthcheck.NewHealthCheckCommand(), nil
 		},
+		"selectors schema": func() (cli.Command, error) {
+			return selectors.NewSchemaCommand(), nil
+		},
 		"x509 mint": func() (cli.Command, error) {
 			return x509.NewMintCommand(), nil
 		},
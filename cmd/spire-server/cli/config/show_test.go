@@ -0,0 +1,197 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const configTemplate = `
+server {
+	bind_address = "127.0.0.1"
+	trust_domain = "example.org"
+	data_dir = %q
+	default_svid_ttl = "2h"
+	ca_key_type = "rsa-2048"
+}
+
+plugins {
+	DataStore "sql" {
+		plugin_data {
+			database_type = "sqlite3"
+			connection_string = %q
+		}
+	}
+
+	KeyManager "memory" {
+		plugin_data {}
+	}
+
+	NodeAttestor "aws_iid" {
+		plugin_data {
+			skip_block_device = true
+		}
+	}
+
+	NodeAttestor "join_token" {
+		enabled = false
+	}
+
+	UpstreamAuthority "awssecret" {
+		plugin_cmd = "/usr/bin/awssecret"
+		plugin_data {}
+	}
+}
+`
+
+func TestShow(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "server.conf")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(fmt.Sprintf(configTemplate, dir, filepath.Join(dir, "datastore.sqlite3"))), 0600))
+
+	env := map[string]string{
+		"AWS_ACCESS_KEY_ID":     "AKIDEXAMPLE",
+		"AWS_SECRET_ACCESS_KEY": "secret-access-key",
+		"AWS_SESSION_TOKEN":     "session-token",
+	}
+	stdout, stderr, code := runShow(env, "-config", configPath, "-logLevel", "DEBUG")
+	require.Equal(t, 0, code, "stderr: %s", stderr)
+
+	var config map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &config))
+
+	server := config["server"].(map[string]interface{})
+	// Defaults
+	assert.Equal(t, float64(8081), server["bind_port"])
+	assert.Equal(t, "/tmp/spire-server/private/api.sock", server["socket_path"])
+	assert.Equal(t, "24h0m0s", server["ca_ttl"])
+	assert.Equal(t, "5m0s", server["default_jwt_svid_ttl"])
+	assert.Equal(t, "rsa-2048", server["jwt_key_type"])
+	assert.Equal(t, map[string]interface{}{"attestation": true, "signing": true}, server["ratelimit"])
+	// Configured values, from the file and the flags
+	assert.Equal(t, "127.0.0.1", server["bind_address"])
+	assert.Equal(t, "2h", server["default_svid_ttl"])
+	assert.Equal(t, "DEBUG", server["log_level"])
+	assert.NotContains(t, server, "ConfigPath")
+
+	plugins := config["plugins"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"enabled": true,
+		"plugin_data": map[string]interface{}{
+			"database_type":     "sqlite3",
+			"connection_string": common_cli.Redacted,
+		},
+	}, plugins["DataStore"].(map[string]interface{})["sql"])
+	assert.Equal(t, map[string]interface{}{
+		"aws_iid": map[string]interface{}{
+			"enabled": true,
+			"plugin_data": map[string]interface{}{
+				"skip_block_device": true,
+				"access_key_id":     "AKIDEXAMPLE",
+				"secret_access_key": common_cli.Redacted,
+			},
+		},
+		"join_token": map[string]interface{}{
+			"enabled":     false,
+			"plugin_data": map[string]interface{}{},
+		},
+	}, plugins["NodeAttestor"])
+	// External plugins don't read the environment like the built-in ones
+	assert.Equal(t, map[string]interface{}{
+		"enabled":         true,
+		"plugin_cmd":      "/usr/bin/awssecret",
+		"plugin_args":     nil,
+		"plugin_checksum": "",
+		"plugin_data":     map[string]interface{}{},
+	}, plugins["UpstreamAuthority"].(map[string]interface{})["awssecret"])
+
+	assert.NotContains(t, stdout, "secret-access-key")
+}
+
+func TestShowVaultEnvOverrides(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "server.conf")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(fmt.Sprintf(`
+server {
+	trust_domain = "example.org"
+	data_dir = %q
+}
+
+plugins {
+	UpstreamAuthority "vault" {
+		plugin_data {
+			vault_addr = "https://vault.example.org"
+			namespace = "configured"
+			approle_auth {
+				approle_id = "configured-role"
+				approle_secret_id = "configured-secret"
+			}
+		}
+	}
+}
+`, dir)), 0600))
+
+	env := map[string]string{
+		"VAULT_ADDR":              "https://other-vault.example.org",
+		"VAULT_NAMESPACE":         "",
+		"VAULT_APPROLE_ID":        "env-role",
+		"VAULT_APPROLE_SECRET_ID": "env-secret",
+		"VAULT_TOKEN":             "env-token",
+	}
+	stdout, stderr, code := runShow(env, "-config", configPath)
+	require.Equal(t, 0, code, "stderr: %s", stderr)
+
+	var config map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &config))
+
+	// The environment takes precedence over the plugin data, even when
+	// empty. VAULT_TOKEN is not used since token_auth is not configured.
+	plugins := config["plugins"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"enabled": true,
+		"plugin_data": map[string]interface{}{
+			"vault_addr": "https://other-vault.example.org",
+			"namespace":  "",
+			"approle_auth": []interface{}{
+				map[string]interface{}{
+					"approle_id":        "env-role",
+					"approle_secret_id": common_cli.Redacted,
+				},
+			},
+		},
+	}, plugins["UpstreamAuthority"].(map[string]interface{})["vault"])
+
+	assert.NotContains(t, stdout, "env-secret")
+}
+
+func TestShowInvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "server.conf")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(`server { trust_domain = "example.org" }`), 0600))
+
+	stdout, stderr, code := runShow(nil, "-config", configPath)
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "Failed to resolve the SPIRE server configuration: data_dir must be configured\n", stderr)
+}
+
+func runShow(env map[string]string, args ...string) (string, string, int) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newShowCommand(&common_cli.Env{
+		Stdout: stdout,
+		Stderr: stderr,
+	}, func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+	code := cmd.Run(args)
+	return stdout.String(), stderr.String(), code
+}
@@ -0,0 +1,178 @@
+package datastore
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/cli/run"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/catalog"
+	"github.com/spiffe/spire/pkg/server/plugin/datastore"
+	ds_sql "github.com/spiffe/spire/pkg/server/plugin/datastore/sql"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const explainCommandName = "datastore explain"
+
+func NewExplainCommand() cli.Command {
+	return newExplainCommand(common_cli.DefaultEnv)
+}
+
+func newExplainCommand(env *common_cli.Env) *explainCommand {
+	return &explainCommand{
+		env: env,
+	}
+}
+
+type explainCommand struct {
+	env *common_cli.Env
+
+	configPath       string
+	expandEnv        bool
+	parentID         string
+	spiffeID         string
+	selectors        common_cli.StringsFlag
+	matchSelectorsOn string
+	pageSize         int
+}
+
+func (c *explainCommand) Help() string {
+	// ignoring parsing errors since "-h" is always supported by the flags package
+	_ = c.parseFlags([]string{"-h"})
+	return ""
+}
+
+func (c *explainCommand) Synopsis() string {
+	return "Reports the plan the datastore chooses to list registration entries"
+}
+
+func (c *explainCommand) Run(args []string) int {
+	if err := c.parseFlags(args); err != nil {
+		return 1
+	}
+	if err := c.run(); err != nil {
+		// Ignore error since a failure to write to stderr cannot very well be
+		// reported
+		_ = c.env.ErrPrintf("Failed to explain the entry listing: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (c *explainCommand) parseFlags(args []string) error {
+	fs := flag.NewFlagSet(explainCommandName, flag.ContinueOnError)
+	fs.SetOutput(c.env.Stderr)
+	fs.StringVar(&c.configPath, "config", "", "Path to a SPIRE server configuration file (defaults to the one used by run)")
+	fs.BoolVar(&c.expandEnv, "expandEnv", false, "Expand environment variables in SPIRE config file")
+	fs.StringVar(&c.parentID, "parentID", "", "List the entries with this parent ID")
+	fs.StringVar(&c.spiffeID, "spiffeID", "", "List the entries with this SPIFFE ID")
+	fs.Var(&c.selectors, "selector", "List the entries matching this colon-delimited type:value selector. Can be used more than once")
+	fs.StringVar(&c.matchSelectorsOn, "matchSelectorsOn", "exact", "The match mode used when listing by selectors [exact | subset]")
+	fs.IntVar(&c.pageSize, "pageSize", 0, "List the entries in pages of this size. Defaults to no paging")
+	return fs.Parse(args)
+}
+
+func (c *explainCommand) run() error {
+	req, err := c.listRequest()
+	if err != nil {
+		return err
+	}
+
+	var runArgs []string
+	if c.configPath != "" {
+		runArgs = append(runArgs, "-config", c.configPath)
+	}
+	if c.expandEnv {
+		runArgs = append(runArgs, "-expandEnv")
+	}
+
+	// Logs go to stderr so they don't get mixed with the report
+	logToStderr := func(logger *log.Logger) error {
+		logger.SetOutput(c.env.Stderr)
+		return nil
+	}
+
+	config, err := run.LoadConfig(explainCommandName, runArgs, []log.Option{logToStderr}, c.env.Stderr, false)
+	if err != nil {
+		return err
+	}
+
+	dataStoreConfig, err := catalog.DataStoreConfigData(config.PluginConfigs)
+	if err != nil {
+		return err
+	}
+
+	dsLog := config.Log.WithField(telemetry.SubsystemName, ds_sql.PluginName)
+	plan, err := ds_sql.ExplainListRegistrationEntries(dataStoreConfig, req, dsLog)
+	if err != nil {
+		return err
+	}
+	return c.printPlan(plan)
+}
+
+func (c *explainCommand) listRequest() (*datastore.ListRegistrationEntriesRequest, error) {
+	if c.pageSize < 0 {
+		return nil, errors.New("page size cannot be negative")
+	}
+
+	req := new(datastore.ListRegistrationEntriesRequest)
+	if c.parentID != "" {
+		req.ByParentId = wrapperspb.String(c.parentID)
+	}
+	if c.spiffeID != "" {
+		req.BySpiffeId = wrapperspb.String(c.spiffeID)
+	}
+	if c.pageSize > 0 {
+		req.Pagination = &datastore.Pagination{PageSize: int32(c.pageSize)}
+	}
+
+	if len(c.selectors) > 0 {
+		req.BySelectors = new(datastore.BySelectors)
+		switch c.matchSelectorsOn {
+		case "exact":
+			req.BySelectors.Match = datastore.Exact
+		case "subset":
+			req.BySelectors.Match = datastore.Subset
+		default:
+			return nil, fmt.Errorf("match behavior %q unknown", c.matchSelectorsOn)
+		}
+		for _, selector := range c.selectors {
+			parts := strings.SplitN(selector, ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("selector %q must be formatted as type:value", selector)
+			}
+			req.BySelectors.Selectors = append(req.BySelectors.Selectors, &common.Selector{
+				Type:  parts[0],
+				Value: parts[1],
+			})
+		}
+	}
+	return req, nil
+}
+
+func (c *explainCommand) printPlan(plan *ds_sql.EntryListingPlan) error {
+	if err := c.env.Printf("Query:\n%s\n\nPlan:\n", strings.TrimSpace(plan.Query)); err != nil {
+		return err
+	}
+	for _, step := range plan.Plan {
+		if err := c.env.Printf("  %s\n", step); err != nil {
+			return err
+		}
+	}
+
+	indexes := "none"
+	if len(plan.Indexes) > 0 {
+		indexes = strings.Join(plan.Indexes, ", ")
+	}
+	fullScans := "none"
+	if len(plan.FullScans) > 0 {
+		fullScans = strings.Join(plan.FullScans, ", ")
+	}
+	return c.env.Printf("\nIndexes used: %s\nTables scanned in full: %s\n", indexes, fullScans)
+}
@@ -0,0 +1,97 @@
+package datastore
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NOTE: The plans chosen for seeded datastores are tested in the sql
+// datastore package.
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	configPath := writeConfig(t, dir, fmt.Sprintf(`
+		database_type = "sqlite3"
+		connection_string = %q
+	`, filepath.Join(dir, "datastore.sqlite3")))
+
+	// Create the schema
+	_, stderr, code := runMigrate("-config", configPath)
+	require.Equal(t, 0, code, "stderr: %s", stderr)
+
+	stdout, stderr, code := runExplain("-config", configPath, "-selector", "unix:uid:1000", "-selector", "unix:gid:1000", "-matchSelectorsOn", "subset")
+	require.Equal(t, 0, code, "stderr: %s", stderr)
+	assert.Contains(t, stdout, "SELECT registered_entry_id AS id FROM selectors WHERE type = 'unix' AND value = 'uid:1000'")
+	assert.Contains(t, stdout, "SELECT registered_entry_id AS id FROM selectors WHERE type = 'unix' AND value = 'gid:1000'")
+	assert.Contains(t, stdout, "\nPlan:\n")
+	assert.Regexp(t, `\nIndexes used: .*idx_selectors_type_value_entry.*\nTables scanned in full: none\n$`, stdout)
+
+	stdout, stderr, code = runExplain("-config", configPath, "-parentID", "spiffe://example.org/agent", "-pageSize", "10")
+	require.Equal(t, 0, code, "stderr: %s", stderr)
+	assert.Contains(t, stdout, "SELECT id FROM registered_entries WHERE parent_id = 'spiffe://example.org/agent' ORDER BY id ASC LIMIT 10")
+	assert.Contains(t, stdout, "\nTables scanned in full: none\n")
+
+	stdout, stderr, code = runExplain("-config", configPath)
+	require.Equal(t, 0, code, "stderr: %s", stderr)
+	assert.Contains(t, stdout, "\nTables scanned in full: registered_entries\n")
+}
+
+func TestExplainErrors(t *testing.T) {
+	dir := t.TempDir()
+	sqliteConfig := fmt.Sprintf(`
+		database_type = "sqlite3"
+		connection_string = %q
+	`, filepath.Join(dir, "datastore.sqlite3"))
+
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		expectErr string
+	}{
+		{
+			name:      "negative page size",
+			args:      []string{"-config", writeConfig(t, dir, sqliteConfig), "-pageSize", "-1"},
+			expectErr: "Failed to explain the entry listing: page size cannot be negative\n",
+		},
+		{
+			name:      "unknown match behavior",
+			args:      []string{"-config", writeConfig(t, dir, sqliteConfig), "-selector", "unix:uid:1000", "-matchSelectorsOn", "superset"},
+			expectErr: "Failed to explain the entry listing: match behavior \"superset\" unknown\n",
+		},
+		{
+			name:      "malformed selector",
+			args:      []string{"-config", writeConfig(t, dir, sqliteConfig), "-selector", "unix"},
+			expectErr: "Failed to explain the entry listing: selector \"unix\" must be formatted as type:value\n",
+		},
+		{
+			name:      "invalid datastore configuration",
+			args:      []string{"-config", writeConfig(t, dir, `database_type = "sqlite3"`)},
+			expectErr: "Failed to explain the entry listing: datastore-sql: connection_string must be set\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runExplain(tt.args...)
+			assert.Equal(t, 1, code)
+			assert.Empty(t, stdout)
+			assert.Equal(t, tt.expectErr, stderr)
+		})
+	}
+}
+
+func runExplain(args ...string) (string, string, int) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newExplainCommand(&common_cli.Env{
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	code := cmd.Run(args)
+	return stdout.String(), stderr.String(), code
+}
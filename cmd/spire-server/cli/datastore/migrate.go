@@ -0,0 +1,152 @@
+package datastore
+
+import (
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/cli/run"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/catalog"
+	ds_sql "github.com/spiffe/spire/pkg/server/plugin/datastore/sql"
+)
+
+const migrateCommandName = "datastore migrate"
+
+func NewMigrateCommand() cli.Command {
+	return newMigrateCommand(common_cli.DefaultEnv)
+}
+
+func newMigrateCommand(env *common_cli.Env) *migrateCommand {
+	return &migrateCommand{
+		env: env,
+	}
+}
+
+type migrateCommand struct {
+	env *common_cli.Env
+
+	configPath       string
+	expandEnv        bool
+	dryRun           bool
+	statementTimeout time.Duration
+}
+
+func (c *migrateCommand) Help() string {
+	// ignoring parsing errors since "-h" is always supported by the flags package
+	_ = c.parseFlags([]string{"-h"})
+	return ""
+}
+
+func (c *migrateCommand) Synopsis() string {
+	return "Reports the datastore schema version and applies the pending migrations"
+}
+
+func (c *migrateCommand) Run(args []string) int {
+	if err := c.parseFlags(args); err != nil {
+		return 1
+	}
+	if err := c.run(); err != nil {
+		// Ignore error since a failure to write to stderr cannot very well be
+		// reported
+		_ = c.env.ErrPrintf("Failed to migrate the datastore: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (c *migrateCommand) parseFlags(args []string) error {
+	fs := flag.NewFlagSet(migrateCommandName, flag.ContinueOnError)
+	fs.SetOutput(c.env.Stderr)
+	fs.StringVar(&c.configPath, "config", "", "Path to a SPIRE server configuration file (defaults to the one used by run)")
+	fs.BoolVar(&c.expandEnv, "expandEnv", false, "Expand environment variables in SPIRE config file")
+	fs.BoolVar(&c.dryRun, "dryRun", false, "Report the schema version and the pending migrations without applying them")
+	fs.DurationVar(&c.statementTimeout, "statementTimeout", 0, "Maximum time each migration statement can take (e.g. 30s). Defaults to no timeout")
+	return fs.Parse(args)
+}
+
+func (c *migrateCommand) run() error {
+	if c.statementTimeout < 0 {
+		return errors.New("statement timeout cannot be negative")
+	}
+
+	var runArgs []string
+	if c.configPath != "" {
+		runArgs = append(runArgs, "-config", c.configPath)
+	}
+	if c.expandEnv {
+		runArgs = append(runArgs, "-expandEnv")
+	}
+
+	// Logs go to stderr so they don't get mixed with the report
+	logToStderr := func(logger *log.Logger) error {
+		logger.SetOutput(c.env.Stderr)
+		return nil
+	}
+
+	config, err := run.LoadConfig(migrateCommandName, runArgs, []log.Option{logToStderr}, c.env.Stderr, false)
+	if err != nil {
+		return err
+	}
+
+	dataStoreConfig, err := catalog.DataStoreConfigData(config.PluginConfigs)
+	if err != nil {
+		return err
+	}
+
+	dsLog := config.Log.WithField(telemetry.SubsystemName, ds_sql.PluginName)
+	status, err := ds_sql.InspectSchema(dataStoreConfig, dsLog)
+	if err != nil {
+		return err
+	}
+	if err := c.printStatus(status); err != nil {
+		return err
+	}
+
+	if c.dryRun || (status.Initialized && len(status.Pending) == 0) {
+		return nil
+	}
+
+	status, err = ds_sql.MigrateSchema(dataStoreConfig, c.statementTimeout, dsLog)
+	if err != nil {
+		return err
+	}
+	return c.env.Printf("Schema migrated to version %d\n", status.Version)
+}
+
+func (c *migrateCommand) printStatus(status *ds_sql.SchemaStatus) error {
+	if !status.Initialized {
+		return c.env.Printf("The database is not initialized. It will be created at schema version %d\n", status.LatestVersion)
+	}
+
+	if status.CodeVersion != "" {
+		if err := c.env.Printf("Schema version: %d (last updated by SPIRE Server %s)\n", status.Version, status.CodeVersion); err != nil {
+			return err
+		}
+	} else if err := c.env.Printf("Schema version: %d\n", status.Version); err != nil {
+		return err
+	}
+	if err := c.env.Printf("Latest schema version: %d\n", status.LatestVersion); err != nil {
+		return err
+	}
+
+	switch {
+	case status.Version > status.LatestVersion:
+		return c.env.Println("The schema is ahead of this SPIRE Server. There are no migrations to apply.")
+	case len(status.Pending) == 0:
+		return c.env.Println("The schema is up to date.")
+	}
+
+	if err := c.env.Println("Pending migrations:"); err != nil {
+		return err
+	}
+	for _, pending := range status.Pending {
+		if err := c.env.Printf("  - v%d: %s\n", pending.Version, pending.Description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
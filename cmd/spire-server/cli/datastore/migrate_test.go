@@ -0,0 +1,120 @@
+package datastore
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NOTE: The reporting of pending migrations for every schema version is
+// tested in the sql datastore package.
+
+const configTemplate = `
+server {
+	bind_address = "127.0.0.1"
+	bind_port = "8081"
+	trust_domain = "example.org"
+	data_dir = %q
+	log_level = "ERROR"
+}
+
+plugins {
+	DataStore "sql" {
+		plugin_data {
+			%s
+		}
+	}
+}
+`
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+	configPath := writeConfig(t, dir, fmt.Sprintf(`
+		database_type = "sqlite3"
+		connection_string = %q
+	`, filepath.Join(dir, "datastore.sqlite3")))
+
+	// A dry run reports the database would be initialized, without doing it,
+	// so a second dry run reports the same
+	stdout, stderr, code := runMigrate("-config", configPath, "-dryRun")
+	require.Equal(t, 0, code, "stderr: %s", stderr)
+	assert.Equal(t, "The database is not initialized. It will be created at schema version 20\n", stdout)
+
+	stdout, stderr, code = runMigrate("-config", configPath, "-dryRun")
+	require.Equal(t, 0, code, "stderr: %s", stderr)
+	assert.Equal(t, "The database is not initialized. It will be created at schema version 20\n", stdout)
+
+	stdout, stderr, code = runMigrate("-config", configPath, "-statementTimeout", "10s")
+	require.Equal(t, 0, code, "stderr: %s", stderr)
+	assert.Equal(t, "The database is not initialized. It will be created at schema version 20\nSchema migrated to version 20\n", stdout)
+
+	stdout, stderr, code = runMigrate("-config", configPath)
+	require.Equal(t, 0, code, "stderr: %s", stderr)
+	assert.Regexp(t, `^Schema version: 20 \(last updated by SPIRE Server .+\)
+Latest schema version: 20
+The schema is up to date.
+$`, stdout)
+}
+
+func TestMigrateErrors(t *testing.T) {
+	dir := t.TempDir()
+	sqliteConfig := fmt.Sprintf(`
+		database_type = "sqlite3"
+		connection_string = %q
+	`, filepath.Join(dir, "datastore.sqlite3"))
+
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		expectErr string
+	}{
+		{
+			name:      "negative statement timeout",
+			args:      []string{"-config", writeConfig(t, dir, sqliteConfig), "-statementTimeout", "-1s"},
+			expectErr: "Failed to migrate the datastore: statement timeout cannot be negative\n",
+		},
+		{
+			name:      "invalid datastore configuration",
+			args:      []string{"-config", writeConfig(t, dir, `database_type = "sqlite3"`)},
+			expectErr: "Failed to migrate the datastore: datastore-sql: connection_string must be set\n",
+		},
+		{
+			name: "unsupported database type",
+			args: []string{"-config", writeConfig(t, dir, `database_type = "oracle"
+			connection_string = "foo"`)},
+			expectErr: "Failed to migrate the datastore: datastore-sql: unsupported database_type: oracle\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runMigrate(tt.args...)
+			assert.Equal(t, 1, code)
+			assert.Empty(t, stdout)
+			assert.Equal(t, tt.expectErr, stderr)
+		})
+	}
+}
+
+func runMigrate(args ...string) (string, string, int) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newMigrateCommand(&common_cli.Env{
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	code := cmd.Run(args)
+	return stdout.String(), stderr.String(), code
+}
+
+func writeConfig(t *testing.T, dir, dataStoreConfig string) string {
+	configPath := filepath.Join(t.TempDir(), "server.conf")
+	config := fmt.Sprintf(configTemplate, dir, dataStoreConfig)
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(config), 0600))
+	return configPath
+}
@@ -0,0 +1,114 @@
+package entry
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/proto/private/server/entryfederation"
+	"github.com/spiffe/spire/proto/spire/common"
+
+	"golang.org/x/net/context"
+)
+
+// NewFederateCommand creates a new "federate" subcommand for "entry" command.
+func NewFederateCommand() cli.Command {
+	return newFederateCommand(common_cli.DefaultEnv)
+}
+
+func newFederateCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(federateCommand))
+}
+
+type federateCommand struct {
+	// Type and value are delimited by a colon (:)
+	// ex. "unix:uid:1000" or "spiffe_id:spiffe://example.org/foo"
+	selectors StringsFlag
+
+	// Whether entries with a subset of the selectors are updated too
+	matchSubset bool
+
+	// Trust domain to add to or remove from the entries
+	federatesWith string
+
+	// Remove the trust domain instead of adding it
+	remove bool
+}
+
+func (*federateCommand) Name() string {
+	return "entry federate"
+}
+
+func (*federateCommand) Synopsis() string {
+	return "Adds or removes a federated trust domain on the registration entries matching some selectors"
+}
+
+func (c *federateCommand) AppendFlags(f *flag.FlagSet) {
+	f.Var(&c.selectors, "selector", "A colon-delimited type:value selector of the entries to update. Can be used more than once")
+	f.BoolVar(&c.matchSubset, "matchSubset", false, "Also update the entries whose selectors are a subset of the given selectors, instead of only those with exactly the given selectors")
+	f.StringVar(&c.federatesWith, "federatesWith", "", "SPIFFE ID of the trust domain to add to the entries")
+	f.BoolVar(&c.remove, "remove", false, "Remove the trust domain from the entries instead")
+}
+
+func (c *federateCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	req := &entryfederation.UpdateFederatesWithRequest{
+		MatchSubset: c.matchSubset,
+		TrustDomain: c.federatesWith,
+	}
+	for _, s := range c.selectors {
+		selector, err := parseSelector(s)
+		if err != nil {
+			return fmt.Errorf("error parsing selectors: %w", err)
+		}
+		req.Selectors = append(req.Selectors, &common.Selector{
+			Type:  selector.Type,
+			Value: selector.Value,
+		})
+	}
+	if c.remove {
+		req.Operation = entryfederation.UpdateFederatesWithRequest_REMOVE
+	}
+
+	resp, err := serverClient.NewEntryFederationClient().UpdateFederatesWith(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Added %s to %d of %d matching ", c.federatesWith, len(resp.Entries), resp.Matched)
+	if c.remove {
+		msg = fmt.Sprintf("Removed %s from %d of %d matching ", c.federatesWith, len(resp.Entries), resp.Matched)
+	}
+	msg = util.Pluralizer(msg, "entry", "entries", int(resp.Matched))
+	if len(resp.Entries) == 0 {
+		return env.Println(msg)
+	}
+	env.Printf(msg + ":\n\n")
+
+	for _, e := range resp.Entries {
+		entry, err := api.RegistrationEntryToProto(e)
+		if err != nil {
+			return err
+		}
+		printEntry(entry, env.Printf)
+	}
+	return nil
+}
+
+// Perform basic validation.
+func (c *federateCommand) validate() error {
+	if len(c.selectors) == 0 {
+		return errors.New("at least one selector is required")
+	}
+	if c.federatesWith == "" {
+		return errors.New("a trust domain to federate with is required")
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package entry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spiffe/spire/proto/private/server/entryfederation"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFederateHelp(t *testing.T) {
+	test := setupTest(t, newFederateCommand)
+	test.client.Help()
+
+	require.Equal(t, `Usage of entry federate:
+  -federatesWith string
+    	SPIFFE ID of the trust domain to add to the entries
+  -matchSubset
+    	Also update the entries whose selectors are a subset of the given selectors, instead of only those with exactly the given selectors
+  -registrationUDSPath string
+    	Path to the SPIRE Server API socket (deprecated; use -socketPath)
+  -remove
+    	Remove the trust domain from the entries instead
+  -selector value
+    	A colon-delimited type:value selector of the entries to update. Can be used more than once
+  -socketPath string
+    	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
+`, test.stderr.String())
+}
+
+func TestFederateSynopsis(t *testing.T) {
+	test := setupTest(t, newFederateCommand)
+	require.Equal(t, "Adds or removes a federated trust domain on the registration entries matching some selectors", test.client.Synopsis())
+}
+
+func TestFederate(t *testing.T) {
+	entry := &common.RegistrationEntry{
+		EntryId:        "entry-id",
+		SpiffeId:       "spiffe://example.org/workload",
+		ParentId:       "spiffe://example.org/node",
+		Selectors:      []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		FederatesWith:  []string{"spiffe://domain1.org"},
+		RevisionNumber: 1,
+	}
+
+	for _, tt := range []struct {
+		name string
+		args []string
+
+		expReq    *entryfederation.UpdateFederatesWithRequest
+		resp      *entryfederation.UpdateFederatesWithResponse
+		serverErr error
+
+		expOut string
+		expErr string
+	}{
+		{
+			name:   "Missing selectors",
+			args:   []string{"-federatesWith", "spiffe://domain1.org"},
+			expErr: "Error: at least one selector is required\n",
+		},
+		{
+			name:   "Missing trust domain",
+			args:   []string{"-selector", "unix:uid:1000"},
+			expErr: "Error: a trust domain to federate with is required\n",
+		},
+		{
+			name:   "Malformed selector",
+			args:   []string{"-selector", "unix", "-federatesWith", "spiffe://domain1.org"},
+			expErr: "Error: error parsing selectors: selector \"unix\" must be formatted as type:value\n",
+		},
+		{
+			name: "Add succeeds",
+			args: []string{"-selector", "unix:uid:1000", "-selector", "unix:gid:1000", "-matchSubset", "-federatesWith", "spiffe://domain1.org"},
+			expReq: &entryfederation.UpdateFederatesWithRequest{
+				Selectors: []*common.Selector{
+					{Type: "unix", Value: "uid:1000"},
+					{Type: "unix", Value: "gid:1000"},
+				},
+				MatchSubset: true,
+				TrustDomain: "spiffe://domain1.org",
+			},
+			resp: &entryfederation.UpdateFederatesWithResponse{
+				Entries: []*common.RegistrationEntry{entry},
+				Matched: 2,
+			},
+			expOut: `Added spiffe://domain1.org to 1 of 2 matching entries:
+
+Entry ID         : entry-id
+SPIFFE ID        : spiffe://example.org/workload
+Parent ID        : spiffe://example.org/node
+Revision         : 1
+TTL              : default
+Selector         : unix:uid:1000
+FederatesWith    : domain1.org
+
+`,
+		},
+		{
+			name: "Remove succeeds",
+			args: []string{"-selector", "unix:uid:1000", "-federatesWith", "spiffe://domain1.org", "-remove"},
+			expReq: &entryfederation.UpdateFederatesWithRequest{
+				Selectors:   []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+				TrustDomain: "spiffe://domain1.org",
+				Operation:   entryfederation.UpdateFederatesWithRequest_REMOVE,
+			},
+			resp:   &entryfederation.UpdateFederatesWithResponse{Matched: 1},
+			expOut: "Removed spiffe://domain1.org from 0 of 1 matching entry\n",
+		},
+		{
+			name:      "Server fails",
+			args:      []string{"-selector", "unix:uid:1000", "-federatesWith", "spiffe://domain1.org"},
+			serverErr: errors.New("server-error"),
+			expErr:    "Error: rpc error: code = Unknown desc = server-error\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newFederateCommand)
+			test.server.err = tt.serverErr
+			test.server.expUpdateFederatesWithReq = tt.expReq
+			test.server.updateFederatesWithResp = tt.resp
+
+			args := append(test.args, tt.args...)
+			rc := test.client.Run(args)
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc)
+			require.Equal(t, tt.expOut, test.stdout.String())
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package entry
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/proto/private/server/deletedentry"
+
+	"golang.org/x/net/context"
+)
+
+// NewRestoreCommand creates a new "restore" subcommand for "entry" command.
+func NewRestoreCommand() cli.Command {
+	return newRestoreCommand(common_cli.DefaultEnv)
+}
+
+func newRestoreCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(restoreCommand))
+}
+
+type restoreCommand struct {
+	// ID of the deleted record to restore
+	entryID string
+
+	// List the deleted records instead
+	list bool
+}
+
+func (*restoreCommand) Name() string {
+	return "entry restore"
+}
+
+func (*restoreCommand) Synopsis() string {
+	return "Restores deleted registration entries"
+}
+
+func (c *restoreCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.entryID, "entryID", "", "The Registration Entry ID of the deleted record to restore")
+	f.BoolVar(&c.list, "list", false, "List the deleted records that can be restored")
+}
+
+func (c *restoreCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	client := serverClient.NewDeletedEntryClient()
+	if c.list {
+		return listDeletedEntries(ctx, env, client)
+	}
+
+	resp, err := client.RestoreEntry(ctx, &deletedentry.RestoreEntryRequest{Id: c.entryID})
+	if err != nil {
+		return err
+	}
+
+	entry, err := api.RegistrationEntryToProto(resp.Entry)
+	if err != nil {
+		return err
+	}
+	env.Printf("Restored entry with ID: %s\n\n", c.entryID)
+	printEntry(entry, env.Printf)
+	return nil
+}
+
+func listDeletedEntries(ctx context.Context, env *common_cli.Env, client deletedentry.DeletedEntryClient) error {
+	resp, err := client.ListDeletedEntries(ctx, &deletedentry.ListDeletedEntriesRequest{})
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Entries) == 0 {
+		return env.Printf("No deleted entries found\n")
+	}
+
+	msg := fmt.Sprintf("Found %d deleted ", len(resp.Entries))
+	msg = util.Pluralizer(msg, "entry", "entries", len(resp.Entries))
+	env.Printf(msg + ":\n\n")
+
+	for _, deleted := range resp.Entries {
+		entry, err := api.RegistrationEntryToProto(deleted.Entry)
+		if err != nil {
+			return err
+		}
+		env.Printf("Deleted at       : %s\n", time.Unix(deleted.DeletedAt, 0).UTC())
+		env.Printf("Purged at        : %s\n", time.Unix(deleted.PurgedAt, 0).UTC())
+		printEntry(entry, env.Printf)
+	}
+	return nil
+}
+
+// Perform basic validation.
+func (c *restoreCommand) validate() error {
+	switch {
+	case c.list && c.entryID != "":
+		return errors.New("-list cannot be used with -entryID")
+	case !c.list && c.entryID == "":
+		return errors.New("an entry ID is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package entry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/proto/private/server/deletedentry"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRestoreHelp(t *testing.T) {
+	test := setupTest(t, newRestoreCommand)
+	test.client.Help()
+
+	require.Equal(t, `Usage of entry restore:
+  -entryID string
+    	The Registration Entry ID of the deleted record to restore
+  -list
+    	List the deleted records that can be restored
+  -registrationUDSPath string
+    	Path to the SPIRE Server API socket (deprecated; use -socketPath)
+  -socketPath string
+    	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
+`, test.stderr.String())
+}
+
+func TestRestoreSynopsis(t *testing.T) {
+	test := setupTest(t, newRestoreCommand)
+	require.Equal(t, "Restores deleted registration entries", test.client.Synopsis())
+}
+
+func TestRestore(t *testing.T) {
+	entry := &common.RegistrationEntry{
+		EntryId:   "entry-id",
+		SpiffeId:  "spiffe://example.org/workload",
+		ParentId:  "spiffe://example.org/node",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	}
+	deletedAt := time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name string
+		args []string
+
+		expReq      *deletedentry.RestoreEntryRequest
+		restoreResp *deletedentry.RestoreEntryResponse
+		listResp    *deletedentry.ListDeletedEntriesResponse
+		serverErr   error
+
+		expOut string
+		expErr string
+	}{
+		{
+			name:   "Empty entry ID",
+			expErr: "Error: an entry ID is required\n",
+		},
+		{
+			name:   "Both entry ID and list",
+			args:   []string{"-entryID", "entry-id", "-list"},
+			expErr: "Error: -list cannot be used with -entryID\n",
+		},
+		{
+			name:      "Entry not found",
+			args:      []string{"-entryID", "entry-id"},
+			serverErr: status.Error(codes.NotFound, "deleted entry not found"),
+			expErr:    "Error: rpc error: code = NotFound desc = deleted entry not found\n",
+		},
+		{
+			name:        "Restore succeeds",
+			args:        []string{"-entryID", "entry-id"},
+			expReq:      &deletedentry.RestoreEntryRequest{Id: "entry-id"},
+			restoreResp: &deletedentry.RestoreEntryResponse{Entry: entry},
+			expOut: `Restored entry with ID: entry-id
+
+Entry ID         : entry-id
+SPIFFE ID        : spiffe://example.org/workload
+Parent ID        : spiffe://example.org/node
+Revision         : 0
+TTL              : default
+Selector         : unix:uid:1000
+
+`,
+		},
+		{
+			name:     "List without deleted entries",
+			args:     []string{"-list"},
+			listResp: &deletedentry.ListDeletedEntriesResponse{},
+			expOut:   "No deleted entries found\n",
+		},
+		{
+			name: "List succeeds",
+			args: []string{"-list"},
+			listResp: &deletedentry.ListDeletedEntriesResponse{
+				Entries: []*deletedentry.DeletedRegistrationEntry{
+					{
+						Entry:     entry,
+						DeletedAt: deletedAt.Unix(),
+						PurgedAt:  deletedAt.Add(24 * time.Hour).Unix(),
+					},
+				},
+			},
+			expOut: `Found 1 deleted entry:
+
+Deleted at       : 2021-03-01 10:00:00 +0000 UTC
+Purged at        : 2021-03-02 10:00:00 +0000 UTC
+Entry ID         : entry-id
+SPIFFE ID        : spiffe://example.org/workload
+Parent ID        : spiffe://example.org/node
+Revision         : 0
+TTL              : default
+Selector         : unix:uid:1000
+
+`,
+		},
+		{
+			name:      "List fails",
+			args:      []string{"-list"},
+			serverErr: errors.New("server-error"),
+			expErr:    "Error: rpc error: code = Unknown desc = server-error\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newRestoreCommand)
+			test.server.err = tt.serverErr
+			test.server.expRestoreEntryReq = tt.expReq
+			test.server.restoreEntryResp = tt.restoreResp
+			test.server.listDeletedResp = tt.listResp
+
+			args := append(test.args, tt.args...)
+			rc := test.client.Run(args)
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc)
+			require.Equal(t, tt.expOut, test.stdout.String())
+		})
+	}
+}
@@ -10,6 +10,8 @@ import (
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/deletedentry"
+	"github.com/spiffe/spire/proto/private/server/entryfederation"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/util"
 	"github.com/stretchr/testify/assert"
@@ -132,22 +134,29 @@ func (e *entryTest) afterTest(t *testing.T) {
 
 type fakeEntryServer struct {
 	*entryv1.UnimplementedEntryServer
+	*deletedentry.UnimplementedDeletedEntryServer
+	*entryfederation.UnimplementedEntryFederationServer
 
 	t   *testing.T
 	err error
 
-	expGetEntryReq         *entryv1.GetEntryRequest
-	expListEntriesReq      *entryv1.ListEntriesRequest
-	expBatchDeleteEntryReq *entryv1.BatchDeleteEntryRequest
-	expBatchCreateEntryReq *entryv1.BatchCreateEntryRequest
-	expBatchUpdateEntryReq *entryv1.BatchUpdateEntryRequest
-
-	getEntryResp         *types.Entry
-	countEntriesResp     *entryv1.CountEntriesResponse
-	listEntriesResp      *entryv1.ListEntriesResponse
-	batchDeleteEntryResp *entryv1.BatchDeleteEntryResponse
-	batchCreateEntryResp *entryv1.BatchCreateEntryResponse
-	batchUpdateEntryResp *entryv1.BatchUpdateEntryResponse
+	expGetEntryReq            *entryv1.GetEntryRequest
+	expListEntriesReq         *entryv1.ListEntriesRequest
+	expBatchDeleteEntryReq    *entryv1.BatchDeleteEntryRequest
+	expBatchCreateEntryReq    *entryv1.BatchCreateEntryRequest
+	expBatchUpdateEntryReq    *entryv1.BatchUpdateEntryRequest
+	expRestoreEntryReq        *deletedentry.RestoreEntryRequest
+	expUpdateFederatesWithReq *entryfederation.UpdateFederatesWithRequest
+
+	getEntryResp            *types.Entry
+	countEntriesResp        *entryv1.CountEntriesResponse
+	listEntriesResp         *entryv1.ListEntriesResponse
+	batchDeleteEntryResp    *entryv1.BatchDeleteEntryResponse
+	batchCreateEntryResp    *entryv1.BatchCreateEntryResponse
+	batchUpdateEntryResp    *entryv1.BatchUpdateEntryResponse
+	listDeletedResp         *deletedentry.ListDeletedEntriesResponse
+	restoreEntryResp        *deletedentry.RestoreEntryResponse
+	updateFederatesWithResp *entryfederation.UpdateFederatesWithResponse
 }
 
 func (f fakeEntryServer) CountEntries(ctx context.Context, req *entryv1.CountEntriesRequest) (*entryv1.CountEntriesResponse, error) {
@@ -197,6 +206,29 @@ func (f fakeEntryServer) BatchUpdateEntry(ctx context.Context, req *entryv1.Batc
 	return f.batchUpdateEntryResp, nil
 }
 
+func (f fakeEntryServer) ListDeletedEntries(ctx context.Context, req *deletedentry.ListDeletedEntriesRequest) (*deletedentry.ListDeletedEntriesResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.listDeletedResp, nil
+}
+
+func (f fakeEntryServer) RestoreEntry(ctx context.Context, req *deletedentry.RestoreEntryRequest) (*deletedentry.RestoreEntryResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	spiretest.RequireProtoEqual(f.t, f.expRestoreEntryReq, req)
+	return f.restoreEntryResp, nil
+}
+
+func (f fakeEntryServer) UpdateFederatesWith(ctx context.Context, req *entryfederation.UpdateFederatesWithRequest) (*entryfederation.UpdateFederatesWithResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	spiretest.RequireProtoEqual(f.t, f.expUpdateFederatesWithReq, req)
+	return f.updateFederatesWithResp, nil
+}
+
 func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *entryTest {
 	stdin := new(bytes.Buffer)
 	stdout := new(bytes.Buffer)
@@ -211,6 +243,8 @@ func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *entry
 	server := &fakeEntryServer{t: t}
 	socketPath := spiretest.StartGRPCSocketServerOnTempSocket(t, func(s *grpc.Server) {
 		entryv1.RegisterEntryServer(s, server)
+		deletedentry.RegisterDeletedEntryServer(s, server)
+		entryfederation.RegisterEntryFederationServer(s, server)
 	})
 
 	test := &entryTest{
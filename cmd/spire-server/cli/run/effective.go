@@ -0,0 +1,75 @@
+package run
+
+import (
+	"io"
+
+	"github.com/spiffe/spire/pkg/common/catalog"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/pkg/server/ca"
+)
+
+// pluginEnvFallbacks are the configurables the built-in server plugins read
+// from the environment when they are not set, or that the environment
+// overrides.
+var pluginEnvFallbacks = []catalog.EnvFallback{
+	{PluginType: "NodeAttestor", PluginName: "aws_iid", Key: "access_key_id", Env: "AWS_ACCESS_KEY_ID"},
+	{PluginType: "NodeAttestor", PluginName: "aws_iid", Key: "secret_access_key", Env: "AWS_SECRET_ACCESS_KEY"},
+	{PluginType: "UpstreamAuthority", PluginName: "awssecret", Key: "secret_token", Env: "AWS_SESSION_TOKEN"},
+	{PluginType: "UpstreamAuthority", PluginName: "vault", Key: "vault_addr", Env: "VAULT_ADDR", Overrides: true},
+	{PluginType: "UpstreamAuthority", PluginName: "vault", Key: "ca_cert_path", Env: "VAULT_CACERT", Overrides: true},
+	{PluginType: "UpstreamAuthority", PluginName: "vault", Key: "namespace", Env: "VAULT_NAMESPACE", Overrides: true},
+	{PluginType: "UpstreamAuthority", PluginName: "vault", Block: "token_auth", Key: "token", Env: "VAULT_TOKEN", Overrides: true},
+	{PluginType: "UpstreamAuthority", PluginName: "vault", Block: "cert_auth", Key: "client_cert_path", Env: "VAULT_CLIENT_CERT", Overrides: true},
+	{PluginType: "UpstreamAuthority", PluginName: "vault", Block: "cert_auth", Key: "client_key_path", Env: "VAULT_CLIENT_KEY", Overrides: true},
+	{PluginType: "UpstreamAuthority", PluginName: "vault", Block: "approle_auth", Key: "approle_id", Env: "VAULT_APPROLE_ID", Overrides: true},
+	{PluginType: "UpstreamAuthority", PluginName: "vault", Block: "approle_auth", Key: "approle_secret_id", Env: "VAULT_APPROLE_SECRET_ID", Overrides: true},
+}
+
+// LoadEffectiveConfig loads the configuration like LoadConfig and returns it
+// as the server resolves it, with the defaults and the values the built-in
+// plugins read from the environment filled in, and the secrets redacted.
+func LoadEffectiveConfig(name string, args []string, logOptions []log.Option, output io.Writer, allowUnknownConfig bool, lookupEnv func(string) (string, bool)) (map[string]interface{}, error) {
+	input, err := loadInput(name, args, output)
+	if err != nil {
+		return nil, err
+	}
+
+	sc, err := NewServerConfig(input, logOptions, allowUnknownConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fill in the defaults applied past the configuration parsing
+	input.Server.SocketPath = sc.BindUDSAddress.Name
+	input.Server.DeprecatedRegistrationUDSPath = ""
+	if input.Server.CATTL == "" {
+		input.Server.CATTL = ca.DefaultCATTL.String()
+	}
+	if input.Server.DefaultSVIDTTL == "" {
+		input.Server.DefaultSVIDTTL = ca.DefaultX509SVIDTTL.String()
+	}
+	if input.Server.DefaultJWTSVIDTTL == "" {
+		input.Server.DefaultJWTSVIDTTL = ca.DefaultJWTSVIDTTL.String()
+	}
+	if input.Server.CAKeyType == "" {
+		input.Server.CAKeyType = "ec-p256"
+	}
+	if input.Server.JWTKeyType == "" {
+		input.Server.JWTKeyType = input.Server.CAKeyType
+	}
+
+	var plugins catalog.HCLPluginConfigMap
+	if input.Plugins != nil {
+		plugins = *input.Plugins
+		input.Plugins = nil
+	}
+
+	effective := common_cli.EffectiveConfig(input)
+	effective["plugins"], err = catalog.EffectivePluginConfigs(plugins, pluginEnvFallbacks, lookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	common_cli.RedactSecrets(effective)
+	return effective, nil
+}
@@ -2,6 +2,7 @@ package run
 
 import (
 	"context"
+	"crypto/tls"
 	"crypto/x509/pkix"
 	"errors"
 	"flag"
@@ -9,9 +10,12 @@ import (
 	"io"
 	"io/ioutil"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,10 +30,15 @@ import (
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/log"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/tracing"
 	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/issuancehook"
 	bundleClient "github.com/spiffe/spire/pkg/server/bundle/client"
 	"github.com/spiffe/spire/pkg/server/ca"
+	"github.com/spiffe/spire/pkg/server/endpoints"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
 )
@@ -41,6 +50,8 @@ const (
 	defaultSocketPath         = "/tmp/spire-server/private/api.sock"
 	defaultLogLevel           = "INFO"
 	defaultBundleEndpointPort = 443
+
+	upstreamAuthorityType = "UpstreamAuthority"
 )
 
 var (
@@ -62,23 +73,51 @@ type Config struct {
 }
 
 type serverConfig struct {
-	BindAddress    string             `hcl:"bind_address"`
-	BindPort       int                `hcl:"bind_port"`
-	CAKeyType      string             `hcl:"ca_key_type"`
-	CASubject      *caSubjectConfig   `hcl:"ca_subject"`
-	CATTL          string             `hcl:"ca_ttl"`
-	DataDir        string             `hcl:"data_dir"`
-	DefaultSVIDTTL string             `hcl:"default_svid_ttl"`
-	Experimental   experimentalConfig `hcl:"experimental"`
-	Federation     *federationConfig  `hcl:"federation"`
-	JWTIssuer      string             `hcl:"jwt_issuer"`
-	JWTKeyType     string             `hcl:"jwt_key_type"`
-	LogFile        string             `hcl:"log_file"`
-	LogLevel       string             `hcl:"log_level"`
-	LogFormat      string             `hcl:"log_format"`
-	RateLimit      rateLimitConfig    `hcl:"ratelimit"`
-	SocketPath     string             `hcl:"socket_path"`
-	TrustDomain    string             `hcl:"trust_domain"`
+	AuditLogEnabled             bool                       `hcl:"audit_log_enabled"`
+	AdminScopes                 map[string][]string        `hcl:"admin_scopes"`
+	AuditLogFile                string                     `hcl:"audit_log_file"`
+	BindAddress                 string                     `hcl:"bind_address"`
+	BindPort                    int                        `hcl:"bind_port"`
+	CAKeyType                   string                     `hcl:"ca_key_type"`
+	CASubject                   *caSubjectConfig           `hcl:"ca_subject"`
+	CATTL                       string                     `hcl:"ca_ttl"`
+	DataDir                     string                     `hcl:"data_dir"`
+	DefaultJWTSVIDTTL           string                     `hcl:"default_jwt_svid_ttl"`
+	DefaultSVIDTTL              string                     `hcl:"default_svid_ttl"`
+	DeletedEntryGracePeriod     string                     `hcl:"deleted_entry_grace_period"`
+	DuplicateSelectorPolicy     string                     `hcl:"duplicate_selector_policy"`
+	EntryPruneInterval          string                     `hcl:"entry_prune_interval"`
+	Experimental                experimentalConfig         `hcl:"experimental"`
+	Federation                  *federationConfig          `hcl:"federation"`
+	FIPSMode                    bool                       `hcl:"fips_mode"`
+	GRPCMaxRecvMessageSize      int                        `hcl:"grpc_max_recv_message_size"`
+	GRPCMaxSendMessageSize      int                        `hcl:"grpc_max_send_message_size"`
+	JWTIssuer                   string                     `hcl:"jwt_issuer"`
+	JWTKeyIDThumbprint          bool                       `hcl:"jwt_key_id_thumbprint"`
+	JWTKeyNoticePeriod          string                     `hcl:"jwt_key_notice_period"`
+	JWTKeyTrustDomains          []string                   `hcl:"jwt_key_trust_domains"`
+	JWTKeyType                  string                     `hcl:"jwt_key_type"`
+	LogFile                     string                     `hcl:"log_file"`
+	LogLevel                    string                     `hcl:"log_level"`
+	LogFormat                   string                     `hcl:"log_format"`
+	MaxAttestationPayloadSize   int                        `hcl:"max_attestation_payload_size"`
+	MaxConcurrentAttestations   map[string]int             `hcl:"max_concurrent_attestations"`
+	MaxNodeSelectors            int                        `hcl:"max_node_selectors"`
+	MinNodeSelectors            int                        `hcl:"min_node_selectors"`
+	NodeSelectorMergePolicy     string                     `hcl:"node_selector_merge_policy"`
+	NotifierTimeout             string                     `hcl:"notifier_timeout"`
+	RateLimit                   rateLimitConfig            `hcl:"ratelimit"`
+	RejectBelowMinNodeSelectors bool                       `hcl:"reject_below_min_node_selectors"`
+	SerialNumberStrategy        string                     `hcl:"serial_number_strategy"`
+	SocketPath                  string                     `hcl:"socket_path"`
+	SPIFFEIDCollisionPolicy     string                     `hcl:"spiffe_id_collision_policy"`
+	SVIDIssuanceWebhook         *svidIssuanceWebhookConfig `hcl:"svid_issuance_webhook"`
+	TLSCipherSuites             []string                   `hcl:"tls_cipher_suites"`
+	TLSMinVersion               string                     `hcl:"tls_min_version"`
+	TrustDomain                 string                     `hcl:"trust_domain"`
+	UnknownSelectorTypePolicy   string                     `hcl:"unknown_selector_type_policy"`
+	UpstreamAuthorityOrder      []string                   `hcl:"upstream_authority_order"`
+	UpstreamAuthorityValidation string                     `hcl:"upstream_authority_validation"`
 
 	ConfigPath string
 	ExpandEnv  bool
@@ -98,7 +137,11 @@ type serverConfig struct {
 }
 
 type experimentalConfig struct {
-	CacheReloadInterval string `hcl:"cache_reload_interval"`
+	CacheReloadInterval  string `hcl:"cache_reload_interval"`
+	TrustDomainMigration bool   `hcl:"trust_domain_migration"`
+	SecondaryTrustDomain string `hcl:"secondary_trust_domain"`
+	TracingOTLPEndpoint  string `hcl:"tracing_otlp_endpoint"`
+	TracingOTLPInsecure  bool   `hcl:"tracing_otlp_insecure"`
 
 	UnusedKeys []string `hcl:",unusedKeys"`
 }
@@ -137,10 +180,21 @@ type federatesWithConfig struct {
 }
 
 type federatesWithBundleEndpointConfig struct {
-	Address    string   `hcl:"address"`
-	Port       int      `hcl:"port"`
-	SpiffeID   string   `hcl:"spiffe_id"`
-	UseWebPKI  bool     `hcl:"use_web_pki"`
+	Address            string   `hcl:"address"`
+	AlternateAddresses []string `hcl:"alternate_addresses"`
+	ClientCertFilePath string   `hcl:"client_cert_file_path"`
+	ClientKeyFilePath  string   `hcl:"client_key_file_path"`
+	Port               int      `hcl:"port"`
+	SpiffeID           string   `hcl:"spiffe_id"`
+	UseServerSVID      bool     `hcl:"use_server_svid"`
+	UseWebPKI          bool     `hcl:"use_web_pki"`
+	UnusedKeys         []string `hcl:",unusedKeys"`
+}
+
+type svidIssuanceWebhookConfig struct {
+	URL        string   `hcl:"url"`
+	Timeout    string   `hcl:"timeout"`
+	FailOpen   bool     `hcl:"fail_open"`
 	UnusedKeys []string `hcl:",unusedKeys"`
 }
 
@@ -183,6 +237,17 @@ func Help(name string, writer io.Writer) string {
 }
 
 func LoadConfig(name string, args []string, logOptions []log.Option, output io.Writer, allowUnknownConfig bool) (*server.Config, error) {
+	input, err := loadInput(name, args, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewServerConfig(input, logOptions, allowUnknownConfig)
+}
+
+// loadInput loads the configuration from the CLI flags and the config file,
+// with the defaults applied.
+func loadInput(name string, args []string, output io.Writer) (*Config, error) {
 	// First parse the CLI flags so we can get the config
 	// file path, if set
 	cliInput, err := parseFlags(name, args, output)
@@ -197,12 +262,7 @@ func LoadConfig(name string, args []string, logOptions []log.Option, output io.W
 		return nil, err
 	}
 
-	input, err := mergeInput(fileInput, cliInput)
-	if err != nil {
-		return nil, err
-	}
-
-	return NewServerConfig(input, logOptions, allowUnknownConfig)
+	return mergeInput(fileInput, cliInput)
 }
 
 // Run the SPIFFE Server
@@ -342,6 +402,24 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 	}
 	sc.Log = logger
 
+	switch {
+	case c.Server.AuditLogEnabled && c.Server.AuditLogFile != "":
+		auditLogger, err := log.NewLogger(
+			log.WithFormat(log.JSONFormat),
+			log.WithOutputFile(c.Server.AuditLogFile))
+		if err != nil {
+			return nil, fmt.Errorf("could not start audit logger: %s", err)
+		}
+		sc.AuditLog = auditLogger
+	case c.Server.AuditLogEnabled:
+		if !logger.IsLevelEnabled(logrus.InfoLevel) {
+			logger.Warn("Audit records are logged at the INFO level and will be dropped with the configured log_level; set audit_log_file to write them to a separate file")
+		}
+		sc.AuditLog = logger.WithField(telemetry.SubsystemName, "audit")
+	case c.Server.AuditLogFile != "":
+		return nil, errors.New("audit_log_file requires audit_log_enabled to be true")
+	}
+
 	ip := net.ParseIP(c.Server.BindAddress)
 	if ip == nil {
 		return nil, fmt.Errorf("could not parse bind_address %q", c.Server.BindAddress)
@@ -385,6 +463,31 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 	}
 	sc.RateLimit.Signing = *c.Server.RateLimit.Signing
 
+	if c.Server.TLSMinVersion != "" {
+		sc.TLSPolicy.MinVersion, err = tlsVersionFromString(c.Server.TLSMinVersion)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing tls_min_version: %w", err)
+		}
+	}
+	if len(c.Server.TLSCipherSuites) > 0 {
+		sc.TLSPolicy.CipherSuites, err = tlsCipherSuitesFromNames(c.Server.TLSCipherSuites)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing tls_cipher_suites: %w", err)
+		}
+		if sc.TLSPolicy.MinVersion == tls.VersionTLS13 {
+			sc.Log.Warn("tls_cipher_suites has no effect when tls_min_version is 1.3")
+		}
+	}
+
+	sc.GRPCMessageSizeLimits.MaxRecv, err = parseGRPCMessageSize(c.Server.GRPCMaxRecvMessageSize)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing grpc_max_recv_message_size: %v", err)
+	}
+	sc.GRPCMessageSizeLimits.MaxSend, err = parseGRPCMessageSize(c.Server.GRPCMaxSendMessageSize)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing grpc_max_send_message_size: %v", err)
+	}
+
 	if c.Server.Federation != nil {
 		if c.Server.Federation.BundleEndpoint != nil {
 			sc.Federation.BundleEndpoint = &bundle.EndpointConfig{
@@ -428,10 +531,43 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 				return nil, err
 			}
 
+			// Alternate addresses that do not have a port use the port of the
+			// bundle endpoint
+			var alternateAddresses []string
+			for _, address := range config.BundleEndpoint.AlternateAddresses {
+				if _, _, err := net.SplitHostPort(address); err != nil {
+					address = net.JoinHostPort(address, strconv.Itoa(port))
+				}
+				alternateAddresses = append(alternateAddresses, address)
+			}
+
+			// The client certificate is loaded up front so that a bad
+			// certificate or key is reported at startup instead of on every
+			// bundle refresh
+			var clientCertificate *tls.Certificate
+			certPath := config.BundleEndpoint.ClientCertFilePath
+			keyPath := config.BundleEndpoint.ClientKeyFilePath
+			switch {
+			case certPath == "" && keyPath == "":
+			case certPath == "" || keyPath == "":
+				return nil, errors.New("`bundle_endpoint.client_cert_file_path` and `bundle_endpoint.client_key_file_path` must be configured together")
+			case config.BundleEndpoint.UseServerSVID:
+				return nil, errors.New("usage of `bundle_endpoint.client_cert_file_path` is not allowed when presenting the server SVID")
+			default:
+				cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+				if err != nil {
+					return nil, fmt.Errorf("unable to load bundle endpoint client certificate for %q: %v", trustDomain, err)
+				}
+				clientCertificate = &cert
+			}
+
 			federatesWith[td] = bundleClient.TrustDomainConfig{
-				EndpointAddress:  fmt.Sprintf("%s:%d", config.BundleEndpoint.Address, port),
-				EndpointSpiffeID: spiffeID,
-				UseWebPKI:        config.BundleEndpoint.UseWebPKI,
+				EndpointAddress:            fmt.Sprintf("%s:%d", config.BundleEndpoint.Address, port),
+				AlternateEndpointAddresses: alternateAddresses,
+				EndpointSpiffeID:           spiffeID,
+				UseWebPKI:                  config.BundleEndpoint.UseWebPKI,
+				ClientCertificate:          clientCertificate,
+				UseServerSVID:              config.BundleEndpoint.UseServerSVID,
 			}
 		}
 		sc.Federation.FederatesWith = federatesWith
@@ -450,6 +586,14 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.SVIDTTL = ttl
 	}
 
+	if c.Server.DefaultJWTSVIDTTL != "" {
+		ttl, err := time.ParseDuration(c.Server.DefaultJWTSVIDTTL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse default JWT-SVID ttl %q: %v", c.Server.DefaultJWTSVIDTTL, err)
+		}
+		sc.JWTSVIDTTL = ttl
+	}
+
 	if c.Server.CATTL != "" {
 		ttl, err := time.ParseDuration(c.Server.CATTL)
 		if err != nil {
@@ -462,6 +606,12 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.Log.Warnf("The configured SVID TTL cannot be guaranteed in all cases - SVIDs with shorter TTLs may be issued if the signing key is expiring soon. Set a CA TTL of at least 6x or reduce SVID TTL below 6x to avoid issuing SVIDs with a smaller TTL than specified")
 	}
 
+	// JWT-SVIDs are capped to the expiration of the JWT signing keys, which
+	// follow the CA TTL too
+	if sc.JWTSVIDTTL > 0 && !hasExpectedTTLs(sc.CATTL, sc.JWTSVIDTTL) {
+		return nil, fmt.Errorf("default_jwt_svid_ttl %q cannot be guaranteed by the JWT signing keys: set a CA TTL of at least 6x the JWT-SVID TTL", c.Server.DefaultJWTSVIDTTL)
+	}
+
 	if c.Server.CAKeyType != "" {
 		keyType, err := keyTypeFromString(c.Server.CAKeyType)
 		if err != nil {
@@ -479,6 +629,143 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 	}
 
 	sc.JWTIssuer = c.Server.JWTIssuer
+	sc.JWTKeyIDThumbprint = c.Server.JWTKeyIDThumbprint
+
+	if c.Server.JWTKeyNoticePeriod != "" {
+		sc.JWTKeyNoticePeriod, err = time.ParseDuration(c.Server.JWTKeyNoticePeriod)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse jwt_key_notice_period %q: %v", c.Server.JWTKeyNoticePeriod, err)
+		}
+		caTTL := sc.CATTL
+		if caTTL == 0 {
+			caTTL = ca.DefaultCATTL
+		}
+		if sc.JWTKeyNoticePeriod < 0 || sc.JWTKeyNoticePeriod >= caTTL {
+			return nil, fmt.Errorf("jwt_key_notice_period must be a non-negative duration shorter than ca_ttl: %s", c.Server.JWTKeyNoticePeriod)
+		}
+	}
+	sc.FIPSMode = c.Server.FIPSMode
+
+	for _, trustDomain := range c.Server.JWTKeyTrustDomains {
+		td, err := spiffeid.TrustDomainFromString(trustDomain)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse jwt_key_trust_domains entry %q: %v", trustDomain, err)
+		}
+		if td == sc.TrustDomain {
+			return nil, fmt.Errorf("jwt_key_trust_domains entry %q must be different from trust_domain", trustDomain)
+		}
+		// the bundle of a federated trust domain is refreshed from its
+		// bundle endpoint, which would drop the published JWT keys
+		if _, ok := sc.Federation.FederatesWith[td]; ok {
+			return nil, fmt.Errorf("jwt_key_trust_domains entry %q must not be a federated trust domain", trustDomain)
+		}
+		for _, existing := range sc.JWTKeyTrustDomains {
+			if existing == td {
+				return nil, fmt.Errorf("jwt_key_trust_domains entry %q is duplicated", trustDomain)
+			}
+		}
+		sc.JWTKeyTrustDomains = append(sc.JWTKeyTrustDomains, td)
+	}
+
+	if c.Server.MinNodeSelectors < 0 {
+		return nil, fmt.Errorf("min_node_selectors must be a non-negative number: %d", c.Server.MinNodeSelectors)
+	}
+	if c.Server.RejectBelowMinNodeSelectors && c.Server.MinNodeSelectors == 0 {
+		sc.Log.Warn("reject_below_min_node_selectors has no effect unless min_node_selectors is configured")
+	}
+	sc.MinNodeSelectors = c.Server.MinNodeSelectors
+	sc.RejectBelowMinNodeSelectors = c.Server.RejectBelowMinNodeSelectors
+
+	if c.Server.MaxNodeSelectors < 0 {
+		return nil, fmt.Errorf("max_node_selectors must be a non-negative number: %d", c.Server.MaxNodeSelectors)
+	}
+	if c.Server.MaxNodeSelectors > 0 && c.Server.MaxNodeSelectors < c.Server.MinNodeSelectors {
+		return nil, fmt.Errorf("max_node_selectors (%d) must not be lower than min_node_selectors (%d)", c.Server.MaxNodeSelectors, c.Server.MinNodeSelectors)
+	}
+	sc.MaxNodeSelectors = c.Server.MaxNodeSelectors
+
+	if c.Server.MaxAttestationPayloadSize < 0 {
+		return nil, fmt.Errorf("max_attestation_payload_size must be a non-negative number: %d", c.Server.MaxAttestationPayloadSize)
+	}
+	sc.MaxAttestationPayloadSize = c.Server.MaxAttestationPayloadSize
+
+	for attestorType, limit := range c.Server.MaxConcurrentAttestations {
+		if limit <= 0 {
+			return nil, fmt.Errorf("max_concurrent_attestations for %q must be a positive number: %d", attestorType, limit)
+		}
+	}
+	sc.MaxConcurrentAttestations = c.Server.MaxConcurrentAttestations
+
+	sc.NodeSelectorMergePolicy, err = api.ParseNodeSelectorMergePolicy(c.Server.NodeSelectorMergePolicy)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing node_selector_merge_policy: %v", err)
+	}
+
+	sc.SPIFFEIDCollisionPolicy, err = api.ParseSPIFFEIDCollisionPolicy(c.Server.SPIFFEIDCollisionPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing spiffe_id_collision_policy: %v", err)
+	}
+
+	sc.DuplicateSelectorPolicy, err = api.ParseDuplicateSelectorPolicy(c.Server.DuplicateSelectorPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing duplicate_selector_policy: %v", err)
+	}
+
+	sc.UnknownSelectorTypePolicy, err = api.ParseUnknownSelectorTypePolicy(c.Server.UnknownSelectorTypePolicy)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing unknown_selector_type_policy: %v", err)
+	}
+
+	if webhook := c.Server.SVIDIssuanceWebhook; webhook != nil {
+		sc.SVIDIssuanceHook, err = parseSVIDIssuanceWebhookConfig(webhook)
+		if err != nil {
+			return nil, fmt.Errorf("invalid svid_issuance_webhook: %v", err)
+		}
+	}
+
+	sc.AdminScopes, err = parseAdminScopes(c.Server.AdminScopes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid admin_scopes: %v", err)
+	}
+
+	if c.Server.DeletedEntryGracePeriod != "" {
+		gracePeriod, err := time.ParseDuration(c.Server.DeletedEntryGracePeriod)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse deleted entry grace period %q: %v", c.Server.DeletedEntryGracePeriod, err)
+		}
+		if gracePeriod < 0 {
+			return nil, fmt.Errorf("deleted_entry_grace_period must be a non-negative duration: %s", c.Server.DeletedEntryGracePeriod)
+		}
+		sc.DeletedEntryGracePeriod = gracePeriod
+	}
+
+	if c.Server.EntryPruneInterval != "" {
+		pruneInterval, err := time.ParseDuration(c.Server.EntryPruneInterval)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse entry prune interval %q: %v", c.Server.EntryPruneInterval, err)
+		}
+		if pruneInterval <= 0 {
+			return nil, fmt.Errorf("entry_prune_interval must be a positive duration: %s", c.Server.EntryPruneInterval)
+		}
+		sc.EntryPruneInterval = pruneInterval
+	}
+
+	if c.Server.NotifierTimeout != "" {
+		notifierTimeout, err := time.ParseDuration(c.Server.NotifierTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse notifier timeout %q: %v", c.Server.NotifierTimeout, err)
+		}
+		if notifierTimeout <= 0 {
+			return nil, fmt.Errorf("notifier_timeout must be a positive duration: %s", c.Server.NotifierTimeout)
+		}
+		sc.NotifierTimeout = notifierTimeout
+	}
+
+	serialNumberGenerator, err := x509util.NewSerialNumberGenerator(c.Server.SerialNumberStrategy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid serial_number_strategy: %v", err)
+	}
+	sc.SerialNumberGenerator = serialNumberGenerator
 
 	if subject := c.Server.CASubject; subject != nil {
 		sc.CASubject = pkix.Name{
@@ -496,6 +783,16 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 	}
 
 	sc.PluginConfigs = *c.Plugins
+
+	if err := validateUpstreamAuthorityOrder(c.Server.UpstreamAuthorityOrder, sc.PluginConfigs[upstreamAuthorityType]); err != nil {
+		return nil, err
+	}
+	sc.UpstreamAuthorityOrder = c.Server.UpstreamAuthorityOrder
+
+	sc.UpstreamAuthorityValidation, err = ca.ParseUpstreamAuthorityValidation(c.Server.UpstreamAuthorityValidation)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing upstream_authority_validation: %v", err)
+	}
 	sc.Telemetry = c.Telemetry
 	sc.HealthChecks = c.HealthChecks
 
@@ -519,6 +816,35 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.CacheReloadInterval = interval
 	}
 
+	if c.Server.Experimental.TrustDomainMigration {
+		if c.Server.Experimental.SecondaryTrustDomain == "" {
+			return nil, errors.New("secondary_trust_domain must be configured when trust_domain_migration is enabled")
+		}
+		secondaryTD, err := spiffeid.TrustDomainFromString(c.Server.Experimental.SecondaryTrustDomain)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse secondary_trust_domain %q: %v", c.Server.Experimental.SecondaryTrustDomain, err)
+		}
+		if secondaryTD == sc.TrustDomain {
+			return nil, errors.New("secondary_trust_domain must be different from trust_domain")
+		}
+		sc.SecondaryTrustDomain = secondaryTD
+	} else if c.Server.Experimental.SecondaryTrustDomain != "" {
+		sc.Log.Warn("The secondary_trust_domain configurable is ignored unless trust_domain_migration is enabled")
+	}
+
+	if c.Server.Experimental.TracingOTLPEndpoint != "" {
+		if _, _, err := net.SplitHostPort(c.Server.Experimental.TracingOTLPEndpoint); err != nil {
+			return nil, fmt.Errorf("could not parse tracing_otlp_endpoint %q: %v", c.Server.Experimental.TracingOTLPEndpoint, err)
+		}
+		sc.Tracing = &tracing.Config{
+			OTLPEndpoint: c.Server.Experimental.TracingOTLPEndpoint,
+			Insecure:     c.Server.Experimental.TracingOTLPInsecure,
+			ServiceName:  telemetry.SpireServer,
+		}
+	} else if c.Server.Experimental.TracingOTLPInsecure {
+		sc.Log.Warn("The tracing_otlp_insecure configurable is ignored unless tracing_otlp_endpoint is set")
+	}
+
 	return sc, nil
 }
 
@@ -597,6 +923,10 @@ func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
 			detectedUnknown("ratelimit", rl.UnusedKeys)
 		}
 
+		if webhook := c.Server.SVIDIssuanceWebhook; webhook != nil && len(webhook.UnusedKeys) != 0 {
+			detectedUnknown("svid_issuance_webhook", webhook.UnusedKeys)
+		}
+
 		// TODO: Re-enable unused key detection for experimental config. See
 		// https://github.com/spiffe/spire/issues/1101 for more information
 		//
@@ -697,6 +1027,173 @@ func keyTypeFromString(s string) (keymanager.KeyType, error) {
 	}
 }
 
+func tlsVersionFromString(s string) (uint16, error) {
+	switch s {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("TLS version %q is unsupported; must be one of [1.2, 1.3]", s)
+	}
+}
+
+// tlsCipherSuitesFromNames returns the IDs of the named cipher suites. Only
+// the secure cipher suites that can be used with TLS 1.2 are accepted, since
+// TLS 1.3 cipher suites are not configurable.
+func tlsCipherSuitesFromNames(names []string) ([]uint16, error) {
+	suites := make(map[string]*tls.CipherSuite)
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite
+	}
+	insecureSuites := make(map[string]bool)
+	for _, suite := range tls.InsecureCipherSuites() {
+		insecureSuites[suite.Name] = true
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		suite, ok := suites[name]
+		switch {
+		case insecureSuites[name]:
+			return nil, fmt.Errorf("cipher suite %q is insecure", name)
+		case !ok:
+			return nil, fmt.Errorf("cipher suite %q is unknown", name)
+		case !supportsTLS12(suite):
+			return nil, fmt.Errorf("cipher suite %q is only used by TLS 1.3, whose cipher suites are not configurable", name)
+		}
+		ids = append(ids, suite.ID)
+	}
+	return ids, nil
+}
+
+func supportsTLS12(suite *tls.CipherSuite) bool {
+	for _, version := range suite.SupportedVersions {
+		if version == tls.VersionTLS12 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateUpstreamAuthorityOrder checks that the upstream authority order
+// lists each enabled UpstreamAuthority plugin exactly once. The order is
+// required when more than one UpstreamAuthority plugin is enabled, since the
+// plugin configuration does not preserve the order plugins are declared in.
+func validateUpstreamAuthorityOrder(order []string, upstreamAuthorities map[string]catalog.HCLPluginConfig) error {
+	enabled := make(map[string]bool)
+	for name, config := range upstreamAuthorities {
+		if config.IsEnabled() {
+			enabled[name] = true
+		}
+	}
+
+	if len(order) == 0 {
+		if len(enabled) > 1 {
+			return errors.New("upstream_authority_order must be configured when more than one UpstreamAuthority plugin is enabled")
+		}
+		return nil
+	}
+
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		switch {
+		case seen[name]:
+			return fmt.Errorf("upstream_authority_order lists %q more than once", name)
+		case !enabled[name]:
+			return fmt.Errorf("upstream_authority_order lists %q, which is not an enabled UpstreamAuthority plugin", name)
+		}
+		seen[name] = true
+	}
+	if len(seen) != len(enabled) {
+		var missing []string
+		for name := range enabled {
+			if !seen[name] {
+				missing = append(missing, name)
+			}
+		}
+		sort.Strings(missing)
+		return fmt.Errorf("upstream_authority_order is missing UpstreamAuthority plugins: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// parseGRPCMessageSize checks that a gRPC message size limit, in bytes, is
+// either zero, which keeps the gRPC default, or within the accepted bounds.
+func parseGRPCMessageSize(size int) (int, error) {
+	if size == 0 {
+		return 0, nil
+	}
+	if size < endpoints.MinGRPCMessageSize || size > endpoints.MaxGRPCMessageSize {
+		return 0, fmt.Errorf("%d is out of range: expected a size between %d and %d bytes", size, endpoints.MinGRPCMessageSize, endpoints.MaxGRPCMessageSize)
+	}
+	return size, nil
+}
+
+// parseAdminScopes parses the admin scopes keyed by the SPIFFE IDs of the
+// admin workloads they restrict. Nil is returned if none are configured.
+func parseAdminScopes(adminScopes map[string][]string) (api.AdminScopes, error) {
+	if len(adminScopes) == 0 {
+		return nil, nil
+	}
+
+	parsed := make(api.AdminScopes, len(adminScopes))
+	for rawID, names := range adminScopes {
+		id, err := spiffeid.FromString(rawID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPIFFE ID %q: %v", rawID, err)
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no scopes for %q", rawID)
+		}
+		for _, name := range names {
+			scope, err := api.ParseAdminScope(name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid scope for %q: %v", rawID, err)
+			}
+			parsed[id] = append(parsed[id], scope)
+		}
+	}
+	return parsed, nil
+}
+
+// parseSVIDIssuanceWebhookConfig validates the SVID issuance webhook
+// configuration. The URL must be an absolute http or https URL, and is never
+// included in errors since it may contain credentials.
+func parseSVIDIssuanceWebhookConfig(c *svidIssuanceWebhookConfig) (*issuancehook.Config, error) {
+	if c.URL == "" {
+		return nil, errors.New("url is required")
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		// The error would otherwise include the URL, and any credentials in it
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("could not parse url: %v", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("url %q must be an absolute http or https URL", u.Redacted())
+	}
+
+	config := &issuancehook.Config{
+		URL:      u,
+		Timeout:  issuancehook.DefaultTimeout,
+		FailOpen: c.FailOpen,
+	}
+	if c.Timeout != "" {
+		config.Timeout, err = time.ParseDuration(c.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse timeout %q: %v", c.Timeout, err)
+		}
+		if config.Timeout <= 0 {
+			return nil, errors.New("timeout must be positive")
+		}
+	}
+	return config, nil
+}
+
 // hasExpectedTTLs is a function that checks if ca_ttl is less than default_svid_ttl * 6. SPIRE Server prepares a new CA certificate when 1/2 of the CA lifetime has elapsed in order to give ample time for the new trust bundle to propagate. However, it does not start using it until 5/6th of the CA lifetime. So its normal for an SVID TTL to be capped to 1/6th of the CA TTL. In order to get the expected lifetime on SVID TTLs, the CA TTL should be 6x.
 func hasExpectedTTLs(caTTL, svidTTL time.Duration) bool {
 	if caTTL == 0 {
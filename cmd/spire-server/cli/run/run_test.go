@@ -2,6 +2,7 @@ package run
 
 import (
 	"bytes"
+	"crypto/tls"
 	"crypto/x509/pkix"
 	"io/ioutil"
 	"os"
@@ -16,9 +17,15 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/pkg/common/tracing"
+	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server"
+	"github.com/spiffe/spire/pkg/server/api"
 	bundleClient "github.com/spiffe/spire/pkg/server/bundle/client"
+	"github.com/spiffe/spire/pkg/server/ca"
+	"github.com/spiffe/spire/pkg/server/endpoints"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
+	"github.com/spiffe/spire/test/fixture"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -518,6 +525,8 @@ func TestMergeInput(t *testing.T) {
 }
 
 func TestNewServerConfig(t *testing.T) {
+	auditLogFile := filepath.Join(t.TempDir(), "audit.log")
+
 	cases := []struct {
 		msg         string
 		expectError bool
@@ -526,93 +535,840 @@ func TestNewServerConfig(t *testing.T) {
 		test        func(*testing.T, *server.Config)
 	}{
 		{
-			msg: "bind_address and bind_port should be correctly parsed",
+			msg: "bind_address and bind_port should be correctly parsed",
+			input: func(c *Config) {
+				c.Server.BindAddress = "192.168.1.1"
+				c.Server.BindPort = 1337
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, "192.168.1.1", c.BindAddress.IP.String())
+				require.Equal(t, 1337, c.BindAddress.Port)
+			},
+		},
+		{
+			msg:         "invalid bind_address should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.BindAddress = "this-is-not-an-ip-address"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "deprecated registration_uds_path should be correctly configured",
+			input: func(c *Config) {
+				c.Server.DeprecatedRegistrationUDSPath = "foo"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, "foo", c.BindUDSAddress.Name)
+				require.Equal(t, "unix", c.BindUDSAddress.Net)
+			},
+		},
+		{
+			msg: "socket_path should be correctly configured",
+			input: func(c *Config) {
+				c.Server.SocketPath = "foo"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, "foo", c.BindUDSAddress.Name)
+				require.Equal(t, "unix", c.BindUDSAddress.Net)
+			},
+		},
+		{
+			msg: "default socket_path should be used if neither socket_path or the deprecated registration_uds_path is set",
+			input: func(c *Config) {
+				c.Server.DeprecatedRegistrationUDSPath = ""
+				c.Server.SocketPath = ""
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, defaultSocketPath, c.BindUDSAddress.Name)
+				require.Equal(t, "unix", c.BindUDSAddress.Net)
+			},
+		},
+
+		{
+			msg: "data_dir should be correctly configured",
+			input: func(c *Config) {
+				c.Server.DataDir = "foo"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, "foo", c.DataDir)
+			},
+		},
+		{
+			msg: "trust_domain should be correctly parsed",
+			input: func(c *Config) {
+				c.Server.TrustDomain = "foo"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, "spiffe://foo", c.TrustDomain.IDString())
+			},
+		},
+		{
+			msg:         "invalid trust_domain should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.TrustDomain = "i'm invalid"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "jwt_issuer is correctly configured",
+			input: func(c *Config) {
+				c.Server.JWTIssuer = "ISSUER"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, "ISSUER", c.JWTIssuer)
+			},
+		},
+		{
+			msg: "tracing is disabled by default",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c.Tracing)
+			},
+		},
+		{
+			msg: "tracing_otlp_endpoint enables tracing",
+			input: func(c *Config) {
+				c.Server.Experimental.TracingOTLPEndpoint = "localhost:4317"
+				c.Server.Experimental.TracingOTLPInsecure = true
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, &tracing.Config{
+					OTLPEndpoint: "localhost:4317",
+					Insecure:     true,
+					ServiceName:  "spire_server",
+				}, c.Tracing)
+			},
+		},
+		{
+			msg:         "invalid tracing_otlp_endpoint should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.TracingOTLPEndpoint = "localhost"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "min_node_selectors is correctly configured",
+			input: func(c *Config) {
+				c.Server.MinNodeSelectors = 3
+				c.Server.RejectBelowMinNodeSelectors = true
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 3, c.MinNodeSelectors)
+				require.True(t, c.RejectBelowMinNodeSelectors)
+			},
+		},
+		{
+			msg:         "negative min_node_selectors should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.MinNodeSelectors = -1
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "max_node_selectors is correctly configured",
+			input: func(c *Config) {
+				c.Server.MaxNodeSelectors = 100
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 100, c.MaxNodeSelectors)
+			},
+		},
+		{
+			msg:         "negative max_node_selectors should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.MaxNodeSelectors = -1
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "max_node_selectors lower than min_node_selectors should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.MinNodeSelectors = 3
+				c.Server.MaxNodeSelectors = 2
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "max_attestation_payload_size is correctly configured",
+			input: func(c *Config) {
+				c.Server.MaxAttestationPayloadSize = 65536
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 65536, c.MaxAttestationPayloadSize)
+			},
+		},
+		{
+			msg:         "negative max_attestation_payload_size should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.MaxAttestationPayloadSize = -1
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "grpc message size limits default to the gRPC defaults",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, endpoints.GRPCMessageSizeLimits{}, c.GRPCMessageSizeLimits)
+			},
+		},
+		{
+			msg: "grpc message size limits are correctly configured",
+			input: func(c *Config) {
+				c.Server.GRPCMaxRecvMessageSize = 16 << 20
+				c.Server.GRPCMaxSendMessageSize = 32 << 20
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, endpoints.GRPCMessageSizeLimits{MaxRecv: 16 << 20, MaxSend: 32 << 20}, c.GRPCMessageSizeLimits)
+			},
+		},
+		{
+			msg:         "grpc_max_recv_message_size below the minimum should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.GRPCMaxRecvMessageSize = 1024
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "grpc_max_send_message_size above the maximum should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.GRPCMaxSendMessageSize = 1 << 30
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "negative grpc_max_recv_message_size should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.GRPCMaxRecvMessageSize = -1
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "max_concurrent_attestations is correctly configured",
+			input: func(c *Config) {
+				c.Server.MaxConcurrentAttestations = map[string]int{"k8s_psat": 10}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, map[string]int{"k8s_psat": 10}, c.MaxConcurrentAttestations)
+			},
+		},
+		{
+			msg:         "non-positive max_concurrent_attestations should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.MaxConcurrentAttestations = map[string]int{"k8s_psat": 0}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "spiffe_id_collision_policy defaults to allow",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, api.AllowSPIFFEIDCollisions, c.SPIFFEIDCollisionPolicy)
+			},
+		},
+		{
+			msg: "spiffe_id_collision_policy is correctly configured",
+			input: func(c *Config) {
+				c.Server.SPIFFEIDCollisionPolicy = "reject"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, api.RejectSPIFFEIDCollisions, c.SPIFFEIDCollisionPolicy)
+			},
+		},
+		{
+			msg:         "unknown spiffe_id_collision_policy should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.SPIFFEIDCollisionPolicy = "ignore"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "admin_scopes are not configured by default",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c.AdminScopes)
+			},
+		},
+		{
+			msg: "admin_scopes are correctly configured",
+			input: func(c *Config) {
+				c.Server.AdminScopes = map[string][]string{
+					"spiffe://example.org/ci":     {"read-only"},
+					"spiffe://example.org/deploy": {"entry-management", "bundle-management"},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, api.AdminScopes{
+					spiffeid.Must("example.org", "ci"):     {api.ReadOnlyAdminScope},
+					spiffeid.Must("example.org", "deploy"): {api.EntryManagementAdminScope, api.BundleManagementAdminScope},
+				}, c.AdminScopes)
+			},
+		},
+		{
+			msg:         "admin_scopes with an invalid SPIFFE ID should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.AdminScopes = map[string][]string{"example.org/ci": {"read-only"}}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "admin_scopes with an unknown scope should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.AdminScopes = map[string][]string{"spiffe://example.org/ci": {"write"}}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "admin_scopes without scopes should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.AdminScopes = map[string][]string{"spiffe://example.org/ci": {}}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "node_selector_merge_policy defaults to union",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, api.UnionNodeSelectors, c.NodeSelectorMergePolicy)
+			},
+		},
+		{
+			msg: "node_selector_merge_policy is correctly configured",
+			input: func(c *Config) {
+				c.Server.NodeSelectorMergePolicy = "resolver-wins"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, api.ResolverWinsNodeSelectors, c.NodeSelectorMergePolicy)
+			},
+		},
+		{
+			msg:         "unknown node_selector_merge_policy should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.NodeSelectorMergePolicy = "attestor"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "duplicate_selector_policy defaults to dedupe",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, api.DedupeDuplicateSelectors, c.DuplicateSelectorPolicy)
+			},
+		},
+		{
+			msg: "duplicate_selector_policy is correctly configured",
+			input: func(c *Config) {
+				c.Server.DuplicateSelectorPolicy = "reject"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, api.RejectDuplicateSelectors, c.DuplicateSelectorPolicy)
+			},
+		},
+		{
+			msg:         "unknown duplicate_selector_policy should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.DuplicateSelectorPolicy = "allow"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "unknown_selector_type_policy defaults to ignore",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, api.IgnoreUnknownSelectorTypes, c.UnknownSelectorTypePolicy)
+			},
+		},
+		{
+			msg: "unknown_selector_type_policy is correctly configured",
+			input: func(c *Config) {
+				c.Server.UnknownSelectorTypePolicy = "reject"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, api.RejectUnknownSelectorTypes, c.UnknownSelectorTypePolicy)
+			},
+		},
+		{
+			msg:         "invalid unknown_selector_type_policy should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.UnknownSelectorTypePolicy = "drop"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "svid_issuance_webhook is disabled by default",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c.SVIDIssuanceHook)
+			},
+		},
+		{
+			msg: "svid_issuance_webhook is correctly configured",
+			input: func(c *Config) {
+				c.Server.SVIDIssuanceWebhook = &svidIssuanceWebhookConfig{
+					URL:      "https://opa.example.org/v1/data/spire/allow",
+					Timeout:  "500ms",
+					FailOpen: true,
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.NotNil(t, c.SVIDIssuanceHook)
+				require.Equal(t, "https://opa.example.org/v1/data/spire/allow", c.SVIDIssuanceHook.URL.String())
+				require.Equal(t, 500*time.Millisecond, c.SVIDIssuanceHook.Timeout)
+				require.True(t, c.SVIDIssuanceHook.FailOpen)
+			},
+		},
+		{
+			msg: "svid_issuance_webhook timeout defaults to one second",
+			input: func(c *Config) {
+				c.Server.SVIDIssuanceWebhook = &svidIssuanceWebhookConfig{
+					URL: "http://localhost:8181/v1/data/spire/allow",
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.NotNil(t, c.SVIDIssuanceHook)
+				require.Equal(t, time.Second, c.SVIDIssuanceHook.Timeout)
+				require.False(t, c.SVIDIssuanceHook.FailOpen)
+			},
+		},
+		{
+			msg:         "svid_issuance_webhook without url should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.SVIDIssuanceWebhook = &svidIssuanceWebhookConfig{}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "svid_issuance_webhook with non-http url should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.SVIDIssuanceWebhook = &svidIssuanceWebhookConfig{
+					URL: "unix:///tmp/opa.sock",
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "svid_issuance_webhook with invalid timeout should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.SVIDIssuanceWebhook = &svidIssuanceWebhookConfig{
+					URL:     "http://localhost:8181/v1/data/spire/allow",
+					Timeout: "-1s",
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "deleted_entry_grace_period defaults to deleting entries right away",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Zero(t, c.DeletedEntryGracePeriod)
+			},
+		},
+		{
+			msg: "deleted_entry_grace_period is correctly configured",
+			input: func(c *Config) {
+				c.Server.DeletedEntryGracePeriod = "24h"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 24*time.Hour, c.DeletedEntryGracePeriod)
+			},
+		},
+		{
+			msg:         "invalid deleted_entry_grace_period should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.DeletedEntryGracePeriod = "forever"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "negative deleted_entry_grace_period should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.DeletedEntryGracePeriod = "-1h"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "entry_prune_interval defaults to the registration manager default",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Zero(t, c.EntryPruneInterval)
+			},
+		},
+		{
+			msg: "entry_prune_interval is correctly configured",
+			input: func(c *Config) {
+				c.Server.EntryPruneInterval = "30s"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 30*time.Second, c.EntryPruneInterval)
+			},
+		},
+		{
+			msg:         "invalid entry_prune_interval should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.EntryPruneInterval = "often"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "zero entry_prune_interval should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.EntryPruneInterval = "0s"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "notifier_timeout is not set by default",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Zero(t, c.NotifierTimeout)
+			},
+		},
+		{
+			msg: "notifier_timeout is correctly configured",
+			input: func(c *Config) {
+				c.Server.NotifierTimeout = "30s"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 30*time.Second, c.NotifierTimeout)
+			},
+		},
+		{
+			msg:         "invalid notifier_timeout should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.NotifierTimeout = "soon"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "zero notifier_timeout should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.NotifierTimeout = "0s"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "serial_number_strategy defaults to random",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				expected, err := x509util.NewSerialNumberGenerator(x509util.SerialNumberRandom)
+				require.NoError(t, err)
+				require.IsType(t, expected, c.SerialNumberGenerator)
+			},
+		},
+		{
+			msg: "serial_number_strategy is correctly configured",
+			input: func(c *Config) {
+				c.Server.SerialNumberStrategy = "monotonic"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				expected, err := x509util.NewSerialNumberGenerator(x509util.SerialNumberMonotonic)
+				require.NoError(t, err)
+				require.IsType(t, expected, c.SerialNumberGenerator)
+			},
+		},
+		{
+			msg:         "unknown serial_number_strategy should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.SerialNumberStrategy = "sequential"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "audit logging is disabled by default",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c.AuditLog)
+			},
+		},
+		{
+			msg: "audit_log_enabled emits the audit records to the server log",
+			input: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.NotNil(t, c.AuditLog)
+			},
+		},
+		{
+			msg: "audit_log_file routes the audit records to a separate file",
+			input: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogFile = auditLogFile
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.NotNil(t, c.AuditLog)
+				require.NotSame(t, c.Log, c.AuditLog)
+
+				c.AuditLog.Info("test")
+				require.NoError(t, c.AuditLog.(*log.Logger).Close())
+				data, err := ioutil.ReadFile(auditLogFile)
+				require.NoError(t, err)
+				require.Contains(t, string(data), `"msg":"test"`)
+			},
+		},
+		{
+			msg:         "audit_log_file without audit_log_enabled should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.AuditLogFile = auditLogFile
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "jwt_key_id_thumbprint is correctly configured",
+			input: func(c *Config) {
+				c.Server.JWTKeyIDThumbprint = true
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.JWTKeyIDThumbprint)
+			},
+		},
+		{
+			msg: "jwt_key_notice_period is correctly configured",
+			input: func(c *Config) {
+				c.Server.JWTKeyNoticePeriod = "1h"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, time.Hour, c.JWTKeyNoticePeriod)
+			},
+		},
+		{
+			msg:         "invalid jwt_key_notice_period returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.JWTKeyNoticePeriod = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "jwt_key_notice_period must be shorter than ca_ttl",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.CATTL = "1h"
+				c.Server.JWTKeyNoticePeriod = "1h"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "jwt_key_trust_domains is correctly configured",
 			input: func(c *Config) {
-				c.Server.BindAddress = "192.168.1.1"
-				c.Server.BindPort = 1337
+				c.Server.JWTKeyTrustDomains = []string{"nested1.example.org", "nested2.example.org"}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, "192.168.1.1", c.BindAddress.IP.String())
-				require.Equal(t, 1337, c.BindAddress.Port)
+				require.Equal(t, []spiffeid.TrustDomain{
+					spiffeid.RequireTrustDomainFromString("nested1.example.org"),
+					spiffeid.RequireTrustDomainFromString("nested2.example.org"),
+				}, c.JWTKeyTrustDomains)
 			},
 		},
 		{
-			msg:         "invalid bind_address should return an error",
+			msg:         "jwt_key_trust_domains must not include the server trust domain",
 			expectError: true,
 			input: func(c *Config) {
-				c.Server.BindAddress = "this-is-not-an-ip-address"
+				c.Server.JWTKeyTrustDomains = []string{"example.org"}
 			},
 			test: func(t *testing.T, c *server.Config) {
 				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "deprecated registration_uds_path should be correctly configured",
+			msg:         "jwt_key_trust_domains must be valid trust domains",
+			expectError: true,
 			input: func(c *Config) {
-				c.Server.DeprecatedRegistrationUDSPath = "foo"
+				c.Server.JWTKeyTrustDomains = []string{"Invalid Trust Domain"}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, "foo", c.BindUDSAddress.Name)
-				require.Equal(t, "unix", c.BindUDSAddress.Net)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "socket_path should be correctly configured",
+			msg: "fips_mode is correctly configured",
 			input: func(c *Config) {
-				c.Server.SocketPath = "foo"
+				c.Server.FIPSMode = true
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, "foo", c.BindUDSAddress.Name)
-				require.Equal(t, "unix", c.BindUDSAddress.Net)
+				require.True(t, c.FIPSMode)
 			},
 		},
 		{
-			msg: "default socket_path should be used if neither socket_path or the deprecated registration_uds_path is set",
+			msg: "upstream_authority_order is correctly configured",
 			input: func(c *Config) {
-				c.Server.DeprecatedRegistrationUDSPath = ""
-				c.Server.SocketPath = ""
+				(*c.Plugins)["UpstreamAuthority"] = map[string]catalog.HCLPluginConfig{
+					"vault": {},
+					"disk":  {},
+				}
+				c.Server.UpstreamAuthorityOrder = []string{"vault", "disk"}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, defaultSocketPath, c.BindUDSAddress.Name)
-				require.Equal(t, "unix", c.BindUDSAddress.Net)
+				require.Equal(t, []string{"vault", "disk"}, c.UpstreamAuthorityOrder)
 			},
 		},
-
 		{
-			msg: "data_dir should be correctly configured",
+			msg: "upstream_authority_order is not required for a single upstream authority",
 			input: func(c *Config) {
-				c.Server.DataDir = "foo"
+				(*c.Plugins)["UpstreamAuthority"] = map[string]catalog.HCLPluginConfig{
+					"disk": {},
+				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, "foo", c.DataDir)
+				require.NotNil(t, c)
+				require.Empty(t, c.UpstreamAuthorityOrder)
 			},
 		},
 		{
-			msg: "trust_domain should be correctly parsed",
+			msg:         "upstream_authority_order is required for multiple upstream authorities",
+			expectError: true,
 			input: func(c *Config) {
-				c.Server.TrustDomain = "foo"
+				(*c.Plugins)["UpstreamAuthority"] = map[string]catalog.HCLPluginConfig{
+					"vault": {},
+					"disk":  {},
+				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, "spiffe://foo", c.TrustDomain.IDString())
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg:         "invalid trust_domain should return an error",
+			msg:         "upstream_authority_order must list every enabled upstream authority",
 			expectError: true,
 			input: func(c *Config) {
-				c.Server.TrustDomain = "i'm invalid"
+				(*c.Plugins)["UpstreamAuthority"] = map[string]catalog.HCLPluginConfig{
+					"vault": {},
+					"disk":  {},
+				}
+				c.Server.UpstreamAuthorityOrder = []string{"vault"}
 			},
 			test: func(t *testing.T, c *server.Config) {
 				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "jwt_issuer is correctly configured",
+			msg:         "upstream_authority_order cannot list unknown upstream authorities",
+			expectError: true,
 			input: func(c *Config) {
-				c.Server.JWTIssuer = "ISSUER"
+				(*c.Plugins)["UpstreamAuthority"] = map[string]catalog.HCLPluginConfig{
+					"disk": {},
+				}
+				c.Server.UpstreamAuthorityOrder = []string{"disk", "vault"}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, "ISSUER", c.JWTIssuer)
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:   "upstream_authority_validation defaults to off",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, ca.SkipUpstreamAuthorityValidation, c.UpstreamAuthorityValidation)
+			},
+		},
+		{
+			msg: "upstream_authority_validation is correctly configured",
+			input: func(c *Config) {
+				c.Server.UpstreamAuthorityValidation = "fail"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, ca.FailUpstreamAuthorityValidation, c.UpstreamAuthorityValidation)
+			},
+		},
+		{
+			msg:         "invalid upstream_authority_validation should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.UpstreamAuthorityValidation = "strict"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
 			},
 		},
 		{
@@ -685,10 +1441,11 @@ func TestNewServerConfig(t *testing.T) {
 					FederatesWith: map[string]federatesWithConfig{
 						"domain1.test": {
 							BundleEndpoint: federatesWithBundleEndpointConfig{
-								Address:   "192.168.1.1",
-								Port:      1337,
-								SpiffeID:  "spiffe://domain1.test/bundle/endpoint",
-								UseWebPKI: false,
+								Address:            "192.168.1.1",
+								AlternateAddresses: []string{"192.168.1.2", "192.168.1.3:8443"},
+								Port:               1337,
+								SpiffeID:           "spiffe://domain1.test/bundle/endpoint",
+								UseWebPKI:          false,
 							},
 						},
 						"domain2.test": {
@@ -704,9 +1461,10 @@ func TestNewServerConfig(t *testing.T) {
 			test: func(t *testing.T, c *server.Config) {
 				require.Equal(t, map[spiffeid.TrustDomain]bundleClient.TrustDomainConfig{
 					spiffeid.RequireTrustDomainFromString("domain1.test"): {
-						EndpointAddress:  "192.168.1.1:1337",
-						EndpointSpiffeID: spiffeid.RequireFromString("spiffe://domain1.test/bundle/endpoint"),
-						UseWebPKI:        false,
+						EndpointAddress:            "192.168.1.1:1337",
+						AlternateEndpointAddresses: []string{"192.168.1.2:1337", "192.168.1.3:8443"},
+						EndpointSpiffeID:           spiffeid.RequireFromString("spiffe://domain1.test/bundle/endpoint"),
+						UseWebPKI:                  false,
 					},
 					spiffeid.RequireTrustDomainFromString("domain2.test"): {
 						EndpointAddress: "192.168.1.1:1337",
@@ -736,6 +1494,109 @@ func TestNewServerConfig(t *testing.T) {
 				require.Nil(t, c)
 			},
 		},
+		{
+			msg: "bundle federates with section loads the client certificate",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					FederatesWith: map[string]federatesWithConfig{
+						"domain1.test": {
+							BundleEndpoint: federatesWithBundleEndpointConfig{
+								Address:            "192.168.1.1",
+								ClientCertFilePath: fixture.Join("certs", "svid.pem"),
+								ClientKeyFilePath:  fixture.Join("certs", "svid_key.pem"),
+							},
+						},
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				tdConfig := c.Federation.FederatesWith[spiffeid.RequireTrustDomainFromString("domain1.test")]
+				require.NotNil(t, tdConfig.ClientCertificate)
+				require.Len(t, tdConfig.ClientCertificate.Certificate, 1)
+				require.NotNil(t, tdConfig.ClientCertificate.PrivateKey)
+				require.False(t, tdConfig.UseServerSVID)
+			},
+		},
+		{
+			msg: "bundle federates with section presents the server SVID",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					FederatesWith: map[string]federatesWithConfig{
+						"domain1.test": {
+							BundleEndpoint: federatesWithBundleEndpointConfig{
+								Address:       "192.168.1.1",
+								UseServerSVID: true,
+							},
+						},
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				tdConfig := c.Federation.FederatesWith[spiffeid.RequireTrustDomainFromString("domain1.test")]
+				require.Nil(t, tdConfig.ClientCertificate)
+				require.True(t, tdConfig.UseServerSVID)
+			},
+		},
+		{
+			msg:         "bundle federates with section requires both the client certificate and key",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					FederatesWith: map[string]federatesWithConfig{
+						"domain1.test": {
+							BundleEndpoint: federatesWithBundleEndpointConfig{
+								Address:            "192.168.1.1",
+								ClientCertFilePath: fixture.Join("certs", "svid.pem"),
+							},
+						},
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "bundle federates with section uses the client certificate and the server SVID",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					FederatesWith: map[string]federatesWithConfig{
+						"domain1.test": {
+							BundleEndpoint: federatesWithBundleEndpointConfig{
+								Address:            "192.168.1.1",
+								ClientCertFilePath: fixture.Join("certs", "svid.pem"),
+								ClientKeyFilePath:  fixture.Join("certs", "svid_key.pem"),
+								UseServerSVID:      true,
+							},
+						},
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "bundle federates with section client certificate does not match the key",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					FederatesWith: map[string]federatesWithConfig{
+						"domain1.test": {
+							BundleEndpoint: federatesWithBundleEndpointConfig{
+								Address:            "192.168.1.1",
+								ClientCertFilePath: fixture.Join("certs", "svid.pem"),
+								ClientKeyFilePath:  fixture.Join("certs", "base_key.pem"),
+							},
+						},
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "default_svid_ttl is correctly parsed",
 			input: func(c *Config) {
@@ -755,6 +1616,45 @@ func TestNewServerConfig(t *testing.T) {
 				require.Nil(t, c)
 			},
 		},
+		{
+			msg: "default_jwt_svid_ttl is correctly parsed, distinct from default_svid_ttl",
+			input: func(c *Config) {
+				c.Server.DefaultSVIDTTL = "1h"
+				c.Server.DefaultJWTSVIDTTL = "2m"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, time.Hour, c.SVIDTTL)
+				require.Equal(t, 2*time.Minute, c.JWTSVIDTTL)
+			},
+		},
+		{
+			msg:   "default_jwt_svid_ttl is not set by default",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.Zero(t, c.JWTSVIDTTL)
+			},
+		},
+		{
+			msg:         "invalid default_jwt_svid_ttl returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.DefaultJWTSVIDTTL = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "default_jwt_svid_ttl above a sixth of ca_ttl returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.CATTL = "1h"
+				c.Server.DefaultJWTSVIDTTL = "20m"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "rsa-2048 ca_key_type is correctly parsed and is set as default for jwt key",
 			input: func(c *Config) {
@@ -976,6 +1876,64 @@ func TestNewServerConfig(t *testing.T) {
 				require.True(t, c.RateLimit.Signing)
 			},
 		},
+		{
+			msg: "tls_min_version is correctly parsed",
+			input: func(c *Config) {
+				c.Server.TLSMinVersion = "1.3"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.EqualValues(t, tls.VersionTLS13, c.TLSPolicy.MinVersion)
+			},
+		},
+		{
+			msg:         "unsupported tls_min_version returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.TLSMinVersion = "1.1"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "tls_cipher_suites are correctly parsed",
+			input: func(c *Config) {
+				c.Server.TLSCipherSuites = []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256}, c.TLSPolicy.CipherSuites)
+			},
+		},
+		{
+			msg:         "insecure tls_cipher_suites returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.TLSCipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "unknown tls_cipher_suites returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.TLSCipherSuites = []string{"TLS_FOO"}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "TLS 1.3 tls_cipher_suites returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.TLSCipherSuites = []string{"TLS_AES_128_GCM_SHA256"}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "warn_on_long_trust_domain",
 			input: func(c *Config) {
@@ -1023,6 +1981,64 @@ func TestNewServerConfig(t *testing.T) {
 				require.Nil(t, c)
 			},
 		},
+		{
+			msg:   "secondary trust domain is disabled by default",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.SecondaryTrustDomain.IsZero())
+			},
+		},
+		{
+			msg: "secondary trust domain is ignored unless trust_domain_migration is enabled",
+			input: func(c *Config) {
+				c.Server.Experimental.SecondaryTrustDomain = "new.example.org"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.SecondaryTrustDomain.IsZero())
+			},
+		},
+		{
+			msg: "secondary trust domain is parsed when trust_domain_migration is enabled",
+			input: func(c *Config) {
+				c.Server.Experimental.TrustDomainMigration = true
+				c.Server.Experimental.SecondaryTrustDomain = "new.example.org"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, "new.example.org", c.SecondaryTrustDomain.String())
+			},
+		},
+		{
+			msg:         "trust_domain_migration requires secondary_trust_domain",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.TrustDomainMigration = true
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "invalid secondary_trust_domain returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.TrustDomainMigration = true
+				c.Server.Experimental.SecondaryTrustDomain = "https://new.example.org"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "secondary_trust_domain must differ from trust_domain",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.TrustDomainMigration = true
+				c.Server.Experimental.SecondaryTrustDomain = "example.org"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
 	}
 
 	for _, testCase := range cases {
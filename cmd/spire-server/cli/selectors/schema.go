@@ -0,0 +1,87 @@
+package selectors
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	caws "github.com/spiffe/spire/pkg/common/plugin/aws"
+	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor/aws"
+)
+
+const schemaCommandName = "selectors schema"
+
+// selectorSchemas are the selector schemas of the plugins that describe the
+// selectors they generate, keyed by plugin name
+var selectorSchemas = map[string]func() []aws.SelectorSchema{
+	caws.PluginName: aws.SelectorSchemas,
+}
+
+// schemaOutput is the machine-readable description of the selectors of a
+// plugin printed by the command
+type schemaOutput struct {
+	Type      string               `json:"type"`
+	Selectors []aws.SelectorSchema `json:"selectors"`
+}
+
+func NewSchemaCommand() cli.Command {
+	return newSchemaCommand(common_cli.DefaultEnv)
+}
+
+func newSchemaCommand(env *common_cli.Env) *schemaCommand {
+	return &schemaCommand{
+		env: env,
+	}
+}
+
+type schemaCommand struct {
+	env *common_cli.Env
+
+	plugin string
+}
+
+func (c *schemaCommand) Help() string {
+	// ignoring parsing errors since "-h" is always supported by the flags package
+	_ = c.parseFlags([]string{"-h"})
+	return ""
+}
+
+func (c *schemaCommand) Synopsis() string {
+	return "Prints the selectors a plugin generates and the format of their values, as JSON"
+}
+
+func (c *schemaCommand) Run(args []string) int {
+	if err := c.parseFlags(args); err != nil {
+		return 1
+	}
+
+	schemas, ok := selectorSchemas[c.plugin]
+	if !ok {
+		// Ignore error since a failure to write to stderr cannot very well be
+		// reported
+		_ = c.env.ErrPrintf("Failed to print the selector schema: plugin %q does not describe its selectors\n", c.plugin)
+		return 1
+	}
+
+	// The formats are easier to read with their placeholders unescaped
+	encoder := json.NewEncoder(c.env.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(schemaOutput{
+		Type:      c.plugin,
+		Selectors: schemas(),
+	}); err != nil {
+		_ = c.env.ErrPrintf("Failed to print the selector schema: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (c *schemaCommand) parseFlags(args []string) error {
+	fs := flag.NewFlagSet(schemaCommandName, flag.ContinueOnError)
+	fs.SetOutput(c.env.Stderr)
+	fs.StringVar(&c.plugin, "plugin", caws.PluginName, fmt.Sprintf("Name of the plugin to print the selectors of. Only %q describes its selectors", caws.PluginName))
+	return fs.Parse(args)
+}
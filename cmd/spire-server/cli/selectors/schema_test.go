@@ -0,0 +1,56 @@
+package selectors
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	stdout, stderr, code := runSchema("-plugin", "aws_iid")
+	require.Equal(t, 0, code, "stderr: %s", stderr)
+
+	var schema struct {
+		Type      string `json:"type"`
+		Selectors []struct {
+			Name      string `json:"name"`
+			Category  string `json:"category"`
+			Format    string `json:"format"`
+			EnabledBy string `json:"enabled_by"`
+		} `json:"selectors"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &schema))
+	assert.Equal(t, "aws_iid", schema.Type)
+	require.NotEmpty(t, schema.Selectors)
+	assert.Equal(t, "Instance Tag", schema.Selectors[0].Name)
+	assert.Equal(t, "tag", schema.Selectors[0].Category)
+	assert.Equal(t, "tag:<key>:<value>", schema.Selectors[0].Format)
+	assert.Empty(t, schema.Selectors[0].EnabledBy)
+
+	// aws_iid is the default plugin
+	defaultStdout, _, code := runSchema()
+	require.Equal(t, 0, code)
+	assert.Equal(t, stdout, defaultStdout)
+}
+
+func TestSchemaUnknownPlugin(t *testing.T) {
+	stdout, stderr, code := runSchema("-plugin", "k8s_psat")
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "Failed to print the selector schema: plugin \"k8s_psat\" does not describe its selectors\n", stderr)
+}
+
+func runSchema(args ...string) (string, string, int) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newSchemaCommand(&common_cli.Env{
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	code := cmd.Run(args)
+	return stdout.String(), stderr.String(), code
+}
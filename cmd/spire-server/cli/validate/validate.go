@@ -1,9 +1,14 @@
 package validate
 
 import (
+	"context"
+	"io/ioutil"
+
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/spire/cmd/spire-server/cli/run"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/pkg/server/catalog"
 )
 
 const commandName = "validate"
@@ -32,11 +37,32 @@ func (c *validateCommand) Synopsis() string {
 }
 
 func (c *validateCommand) Run(args []string) int {
-	if _, err := run.LoadConfig(commandName, args, nil, c.env.Stderr, false); err != nil {
+	// Plugins log their failures, which are reported below anyway
+	discardLogs := func(logger *log.Logger) error {
+		logger.SetOutput(ioutil.Discard)
+		return nil
+	}
+
+	config, err := run.LoadConfig(commandName, args, []log.Option{discardLogs}, c.env.Stderr, false)
+	if err != nil {
 		// Ignore error since a failure to write to stderr cannot very well be reported
 		_ = c.env.ErrPrintf("SPIRE server configuration file is invalid: %v\n", err)
 		return 1
 	}
+
+	errs := catalog.Validate(context.Background(), catalog.Config{
+		Log:          config.Log,
+		TrustDomain:  config.TrustDomain,
+		PluginConfig: config.PluginConfigs,
+	})
+	if len(errs) > 0 {
+		_ = c.env.ErrPrintln("SPIRE server plugin configuration is invalid:")
+		for _, err := range errs {
+			_ = c.env.ErrPrintf("  - %v\n", err)
+		}
+		return 1
+	}
+
 	_ = c.env.Println("SPIRE server configuration file is valid.")
 	return 0
 }
@@ -2,6 +2,9 @@ package validate
 
 import (
 	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"github.com/mitchellh/cli"
@@ -9,8 +12,31 @@ import (
 	"github.com/stretchr/testify/suite"
 )
 
-// NOTE: Since Run() in this package is a wrapper
-// using some functions in run package, Do not test here.
+// NOTE: The parsing of the configuration file is done by the run package and
+// tested there. The tests here cover the validation of plugin configurations.
+
+const configTemplate = `
+server {
+	bind_address = "127.0.0.1"
+	bind_port = "8081"
+	trust_domain = "example.org"
+	data_dir = %q
+}
+
+plugins {
+	DataStore "sql" {
+		plugin_data {
+			%s
+		}
+	}
+
+	KeyManager "memory" {
+		plugin_data {}
+	}
+
+	%s
+}
+`
 
 func TestValidate(t *testing.T) {
 	suite.Run(t, new(ValidateSuite))
@@ -53,3 +79,64 @@ func (s *ValidateSuite) TestBadFlags() {
 	s.Equal("", s.stdout.String(), "stdout")
 	s.Contains(s.stderr.String(), "flag provided but not defined: -badflag")
 }
+
+func (s *ValidateSuite) TestValidConfig() {
+	configPath := s.writeConfig(`
+		database_type = "sqlite3"
+		connection_string = "datastore.sqlite3"
+	`, `
+	NodeAttestor "join_token" {
+		plugin_data {}
+	}
+	`)
+
+	code := s.cmd.Run([]string{"-config", configPath})
+	s.Equal(0, code, "exit code")
+	s.Equal("SPIRE server configuration file is valid.\n", s.stdout.String())
+	s.Equal("", s.stderr.String())
+}
+
+func (s *ValidateSuite) TestInvalidPluginConfigs() {
+	configPath := s.writeConfig(`
+		database_type = "sqlite3"
+	`, `
+	NodeAttestor "aws_iid" {
+		plugin_data {
+			max_results = 1
+		}
+	}
+
+	NodeAttestor "join_token" {
+		plugin_data {}
+	}
+
+	KeyManager "disk" {
+		plugin_data {}
+	}
+	`)
+
+	code := s.cmd.Run([]string{"-config", configPath})
+	s.Equal(1, code, "exit code")
+	s.Equal("", s.stdout.String())
+	s.Equal(`SPIRE server plugin configuration is invalid:
+  - invalid DataStore configuration: datastore-sql: connection_string must be set
+  - failed to configure plugin "disk": rpc error: code = InvalidArgument desc = keys_path is required
+  - failed to configure plugin "aws_iid": rpc error: code = Unknown desc = aws-iid: max_results must be between 5 and 1000
+  - plugin type "KeyManager" constraint not satisfied: expected exactly 1 but got 2
+`, s.stderr.String())
+}
+
+func (s *ValidateSuite) TestInvalidConfigFile() {
+	code := s.cmd.Run([]string{"-config", filepath.Join(s.T().TempDir(), "missing.conf")})
+	s.Equal(1, code, "exit code")
+	s.Equal("", s.stdout.String())
+	s.Contains(s.stderr.String(), "SPIRE server configuration file is invalid: ")
+}
+
+func (s *ValidateSuite) writeConfig(dataStoreConfig, pluginsConfig string) string {
+	dir := s.T().TempDir()
+	configPath := filepath.Join(dir, "server.conf")
+	config := fmt.Sprintf(configTemplate, dir, dataStoreConfig, pluginsConfig)
+	s.Require().NoError(ioutil.WriteFile(configPath, []byte(config), 0600))
+	return configPath
+}
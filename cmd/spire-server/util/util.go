@@ -11,6 +11,8 @@ import (
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/deletedentry"
+	"github.com/spiffe/spire/proto/private/server/entryfederation"
 	"github.com/spiffe/spire/proto/spire/api/registration"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -51,6 +53,8 @@ type ServerClient interface {
 	NewEntryClient() entryv1.EntryClient
 	NewSVIDClient() svidv1.SVIDClient
 	NewHealthClient() grpc_health_v1.HealthClient
+	NewDeletedEntryClient() deletedentry.DeletedEntryClient
+	NewEntryFederationClient() entryfederation.EntryFederationClient
 }
 
 func NewServerClient(socketPath string) (ServerClient, error) {
@@ -89,6 +93,14 @@ func (c *serverClient) NewHealthClient() grpc_health_v1.HealthClient {
 	return grpc_health_v1.NewHealthClient(c.conn)
 }
 
+func (c *serverClient) NewDeletedEntryClient() deletedentry.DeletedEntryClient {
+	return deletedentry.NewDeletedEntryClient(c.conn)
+}
+
+func (c *serverClient) NewEntryFederationClient() entryfederation.EntryFederationClient {
+	return entryfederation.NewEntryFederationClient(c.conn)
+}
+
 // Pluralizer concatenates `singular` to `msg` when `val` is one, and
 // `plural` on all other occasions. It is meant to facilitate friendlier
 // CLI output.
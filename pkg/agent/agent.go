@@ -74,12 +74,13 @@ func (a *Agent) Run(ctx context.Context) error {
 
 	healthChecker := health.NewChecker(a.c.HealthChecks, a.c.Log)
 
-	as, err := a.attest(ctx, cat, metrics)
+	nodeAttestor := a.newNodeAttestor(cat, metrics)
+	as, err := nodeAttestor.Attest(ctx)
 	if err != nil {
 		return err
 	}
 
-	manager, err := a.newManager(ctx, cat, metrics, as)
+	manager, err := a.newManager(ctx, cat, metrics, as, nodeAttestor)
 	if err != nil {
 		return err
 	}
@@ -165,7 +166,7 @@ func (a *Agent) setupProfiling(ctx context.Context) (stop func()) {
 	}
 }
 
-func (a *Agent) attest(ctx context.Context, cat catalog.Catalog, metrics telemetry.Metrics) (*node_attestor.AttestationResult, error) {
+func (a *Agent) newNodeAttestor(cat catalog.Catalog, metrics telemetry.Metrics) node_attestor.Attestor {
 	config := node_attestor.Config{
 		Catalog:           cat,
 		Metrics:           metrics,
@@ -178,10 +179,10 @@ func (a *Agent) attest(ctx context.Context, cat catalog.Catalog, metrics telemet
 		Log:               a.c.Log.WithField(telemetry.SubsystemName, telemetry.Attestor),
 		ServerAddress:     a.c.ServerAddress,
 	}
-	return node_attestor.New(&config).Attest(ctx)
+	return node_attestor.New(&config)
 }
 
-func (a *Agent) newManager(ctx context.Context, cat catalog.Catalog, metrics telemetry.Metrics, as *node_attestor.AttestationResult) (manager.Manager, error) {
+func (a *Agent) newManager(ctx context.Context, cat catalog.Catalog, metrics telemetry.Metrics, as *node_attestor.AttestationResult, reattestor node_attestor.Attestor) (manager.Manager, error) {
 	config := &manager.Config{
 		SVID:            as.SVID,
 		SVIDKey:         as.Key,
@@ -194,6 +195,11 @@ func (a *Agent) newManager(ctx context.Context, cat catalog.Catalog, metrics tel
 		BundleCachePath: a.bundleCachePath(),
 		SVIDCachePath:   a.agentSVIDPath(),
 		SyncInterval:    a.c.SyncInterval,
+
+		JWTSVIDRefreshAhead: a.c.JWTSVIDRefreshAhead,
+		EntryMatching:       a.c.EntryMatching,
+		Reattestor:          reattestor,
+		ReattestInterval:    a.c.ReattestInterval,
 	}
 
 	mgr := manager.New(config)
@@ -206,7 +212,10 @@ func (a *Agent) newManager(ctx context.Context, cat catalog.Catalog, metrics tel
 
 func (a *Agent) newEndpoints(cat catalog.Catalog, metrics telemetry.Metrics, mgr manager.Manager) endpoints.Server {
 	return endpoints.New(endpoints.Config{
-		BindAddr: a.c.BindAddress,
+		BindAddr:   a.c.BindAddress,
+		SocketMode: a.c.SocketMode,
+		SocketUID:  a.c.SocketUID,
+		SocketGID:  a.c.SocketGID,
 		Attestor: workload_attestor.New(&workload_attestor.Config{
 			Catalog: cat,
 			Log:     a.c.Log.WithField(telemetry.SubsystemName, telemetry.WorkloadAttestor),
@@ -218,6 +227,8 @@ func (a *Agent) newEndpoints(cat catalog.Catalog, metrics telemetry.Metrics, mgr
 		DefaultSVIDName:               a.c.DefaultSVIDName,
 		DefaultBundleName:             a.c.DefaultBundleName,
 		AllowUnauthenticatedVerifiers: a.c.AllowUnauthenticatedVerifiers,
+		AttestationCacheTTL:           a.c.WorkloadAttestationCacheTTL,
+		JWTSVIDValidationLeeway:       a.c.JWTSVIDValidationLeeway,
 	})
 }
 
@@ -0,0 +1,60 @@
+package attestation
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/agent/manager"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/proto/private/agent/attestation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterService registers attestation service on provided server
+func RegisterService(s *grpc.Server, service *Service) {
+	attestation.RegisterAttestationServer(s, service)
+}
+
+// Config configurations for attestation service
+type Config struct {
+	Log     logrus.FieldLogger
+	Manager manager.Manager
+}
+
+// New creates a new attestation service
+func New(config Config) *Service {
+	return &Service{
+		log: config.Log,
+		m:   config.Manager,
+	}
+}
+
+// Service implements attestation server
+type Service struct {
+	attestation.UnsafeAttestationServer
+
+	log logrus.FieldLogger
+	m   manager.Manager
+}
+
+// Reattest performs node attestation again and returns the new agent SVID
+// details
+func (s *Service) Reattest(ctx context.Context, req *attestation.ReattestRequest) (*attestation.ReattestResponse, error) {
+	s.log.Info("Reattestation requested")
+	if err := s.m.Reattest(ctx); err != nil {
+		s.log.WithError(err).Error("Failed to reattest")
+		return nil, status.Errorf(codes.Internal, "failed to reattest: %v", err)
+	}
+
+	resp := new(attestation.ReattestResponse)
+	if svid := s.m.GetCurrentCredentials().SVID; len(svid) > 0 {
+		if len(svid[0].URIs) > 0 {
+			resp.SpiffeId = svid[0].URIs[0].String()
+		}
+		resp.ExpiresAt = svid[0].NotAfter.Unix()
+	}
+	s.log.WithField(telemetry.SPIFFEID, resp.SpiffeId).Debug("Reattestation completed")
+	return resp, nil
+}
@@ -0,0 +1,90 @@
+package attestation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/api/attestation/v1"
+	"github.com/spiffe/spire/pkg/agent/manager"
+	"github.com/spiffe/spire/pkg/agent/svid"
+	attestationpb "github.com/spiffe/spire/proto/private/agent/attestation"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	ctx = context.Background()
+	td  = spiffeid.RequireTrustDomainFromString("example.org")
+)
+
+func TestReattest(t *testing.T) {
+	ca := testca.New(t, td)
+	agentID := spiffeid.RequireFromString("spiffe://example.org/spire/agent/test/node")
+	notAfter := time.Now().Add(time.Hour).Truncate(time.Second)
+	agentSVID := ca.CreateX509SVID(agentID, testca.WithLifetime(time.Now(), notAfter))
+
+	t.Run("success", func(t *testing.T) {
+		m := &fakeManager{state: svid.State{SVID: agentSVID.Certificates}}
+		client := setupServiceTest(t, m)
+
+		resp, err := client.Reattest(ctx, &attestationpb.ReattestRequest{})
+		require.NoError(t, err)
+		require.Equal(t, 1, m.reattestCalls)
+		spiretest.RequireProtoEqual(t, &attestationpb.ReattestResponse{
+			SpiffeId:  agentID.String(),
+			ExpiresAt: notAfter.Unix(),
+		}, resp)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		m := &fakeManager{err: errors.New("oh no")}
+		client := setupServiceTest(t, m)
+
+		resp, err := client.Reattest(ctx, &attestationpb.ReattestRequest{})
+		spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to reattest: oh no")
+		require.Nil(t, resp)
+		require.Equal(t, 1, m.reattestCalls)
+	})
+}
+
+func setupServiceTest(t *testing.T, m manager.Manager) attestationpb.AttestationClient {
+	log, _ := test.NewNullLogger()
+	service := attestation.New(attestation.Config{
+		Log:     log,
+		Manager: m,
+	})
+
+	registerFn := func(s *grpc.Server) {
+		attestation.RegisterService(s, service)
+	}
+	contextFn := func(ctx context.Context) context.Context {
+		return ctx
+	}
+	conn, done := spiretest.NewAPIServer(t, registerFn, contextFn)
+	t.Cleanup(done)
+	return attestationpb.NewAttestationClient(conn)
+}
+
+type fakeManager struct {
+	manager.Manager
+
+	state         svid.State
+	err           error
+	reattestCalls int
+}
+
+func (m *fakeManager) Reattest(ctx context.Context) error {
+	m.reattestCalls++
+	return m.err
+}
+
+func (m *fakeManager) GetCurrentCredentials() svid.State {
+	return m.state
+}
@@ -7,7 +7,9 @@ import (
 	"os"
 
 	"github.com/andres-erbsen/clock"
+	"github.com/spiffe/spire/pkg/agent/api/attestation/v1"
 	"github.com/spiffe/spire/pkg/agent/api/debug/v1"
+	"github.com/spiffe/spire/pkg/agent/api/simulation/v1"
 	"github.com/spiffe/spire/pkg/common/peertracker"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 
@@ -29,6 +31,8 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	)
 
 	e.registerDebugAPI(server)
+	e.registerSimulationAPI(server)
+	e.registerAttestationAPI(server)
 
 	l, err := e.createUDSListener()
 	if err != nil {
@@ -63,6 +67,24 @@ func (e *Endpoints) registerDebugAPI(server *grpc.Server) {
 	debug.RegisterService(server, service)
 }
 
+func (e *Endpoints) registerSimulationAPI(server *grpc.Server) {
+	service := simulation.New(simulation.Config{
+		Log:     e.c.Log.WithField(telemetry.SubsystemName, telemetry.SimulationAPI),
+		Manager: e.c.Manager,
+	})
+
+	simulation.RegisterService(server, service)
+}
+
+func (e *Endpoints) registerAttestationAPI(server *grpc.Server) {
+	service := attestation.New(attestation.Config{
+		Log:     e.c.Log.WithField(telemetry.SubsystemName, telemetry.AttestationAPI),
+		Manager: e.c.Manager,
+	})
+
+	attestation.RegisterService(server, service)
+}
+
 func (e *Endpoints) createUDSListener() (net.Listener, error) {
 	// Remove uds if already exists
 	os.Remove(e.c.BindAddr.String())
@@ -0,0 +1,87 @@
+package simulation
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/agent/manager"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/proto/private/agent/simulation"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterService registers simulation service on provided server
+func RegisterService(s *grpc.Server, service *Service) {
+	simulation.RegisterSimulationServer(s, service)
+}
+
+// Config configurations for simulation service
+type Config struct {
+	Log     logrus.FieldLogger
+	Manager manager.Manager
+}
+
+// New creates a new simulation service
+func New(config Config) *Service {
+	return &Service{
+		log: config.Log,
+		m:   config.Manager,
+	}
+}
+
+// Service implements simulation server
+type Service struct {
+	simulation.UnsafeSimulationServer
+
+	log logrus.FieldLogger
+	m   manager.Manager
+}
+
+// SimulateAttestation returns the entries, and their X509-SVIDs, that a
+// workload attested with the given selectors would get
+func (s *Service) SimulateAttestation(ctx context.Context, req *simulation.SimulateAttestationRequest) (*simulation.SimulateAttestationResponse, error) {
+	if len(req.Selectors) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one selector is required")
+	}
+	for _, selector := range req.Selectors {
+		if selector.Type == "" || selector.Value == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid selector %q: type and value are required", selector.Type+":"+selector.Value)
+		}
+	}
+
+	identities := s.m.MatchingIdentities(req.Selectors)
+	s.log.WithFields(logrus.Fields{
+		telemetry.Selectors: selectorsString(req.Selectors),
+		telemetry.Count:     len(identities),
+	}).Debug("Simulated workload attestation")
+
+	resp := &simulation.SimulateAttestationResponse{
+		Entries: make([]*simulation.MatchedEntry, 0, len(identities)),
+	}
+	for _, identity := range identities {
+		// The private key is purposefully left out
+		svid := new(simulation.X509SVID)
+		for _, cert := range identity.SVID {
+			svid.CertChain = append(svid.CertChain, cert.Raw)
+		}
+		if len(identity.SVID) > 0 {
+			svid.ExpiresAt = identity.SVID[0].NotAfter.Unix()
+		}
+		resp.Entries = append(resp.Entries, &simulation.MatchedEntry{
+			Entry: identity.Entry,
+			Svid:  svid,
+		})
+	}
+	return resp, nil
+}
+
+func selectorsString(selectors []*common.Selector) []string {
+	out := make([]string, 0, len(selectors))
+	for _, selector := range selectors {
+		out = append(out, selector.Type+":"+selector.Value)
+	}
+	return out
+}
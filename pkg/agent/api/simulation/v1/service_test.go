@@ -0,0 +1,175 @@
+package simulation_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/api/simulation/v1"
+	"github.com/spiffe/spire/pkg/agent/manager"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	simulationpb "github.com/spiffe/spire/proto/private/agent/simulation"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	ctx = context.Background()
+	td  = spiffeid.RequireTrustDomainFromString("example.org")
+)
+
+func TestSimulateAttestation(t *testing.T) {
+	ca := testca.New(t, td)
+	entries := map[string]*common.RegistrationEntry{
+		"uid": {
+			EntryId:   "uid",
+			SpiffeId:  "spiffe://example.org/uid",
+			ParentId:  "spiffe://example.org/agent",
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		},
+		"uid-and-gid": {
+			EntryId:  "uid-and-gid",
+			SpiffeId: "spiffe://example.org/uid-and-gid",
+			ParentId: "spiffe://example.org/agent",
+			Selectors: []*common.Selector{
+				{Type: "unix", Value: "uid:1000"},
+				{Type: "unix", Value: "gid:1000"},
+			},
+		},
+		"k8s": {
+			EntryId:   "k8s",
+			SpiffeId:  "spiffe://example.org/k8s",
+			ParentId:  "spiffe://example.org/agent",
+			Selectors: []*common.Selector{{Type: "k8s", Value: "ns:default"}},
+		},
+		"no-svid": {
+			EntryId:   "no-svid",
+			SpiffeId:  "spiffe://example.org/no-svid",
+			ParentId:  "spiffe://example.org/agent",
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		},
+	}
+	svids := make(map[string]*cache.X509SVID)
+	for id, entry := range entries {
+		if id == "no-svid" {
+			continue
+		}
+		svid := ca.CreateX509SVID(spiffeid.RequireFromString(entry.SpiffeId))
+		svids[id] = &cache.X509SVID{
+			Chain:      svid.Certificates,
+			PrivateKey: svid.PrivateKey,
+		}
+	}
+
+	log, _ := test.NewNullLogger()
+	c := cache.New(log, td, bundleutil.BundleFromRootCA(td, ca.X509Authorities()[0]), telemetry.Blackhole{})
+	c.UpdateEntries(&cache.UpdateEntries{
+		Bundles:             map[spiffeid.TrustDomain]*bundleutil.Bundle{td: bundleutil.BundleFromRootCA(td, ca.X509Authorities()[0])},
+		RegistrationEntries: entries,
+	}, nil)
+	c.UpdateSVIDs(&cache.UpdateSVIDs{X509SVIDs: svids})
+
+	client := setupServiceTest(t, &fakeManager{cache: c})
+
+	for _, tt := range []struct {
+		name          string
+		selectors     []*common.Selector
+		expectEntries []string
+		expectCode    codes.Code
+		expectMsg     string
+	}{
+		{
+			name:          "single selector",
+			selectors:     []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			expectEntries: []string{"uid"},
+		},
+		{
+			name: "superset of the selectors of several entries",
+			selectors: []*common.Selector{
+				{Type: "unix", Value: "uid:1000"},
+				{Type: "unix", Value: "gid:1000"},
+				{Type: "unix", Value: "user:bob"},
+			},
+			expectEntries: []string{"uid", "uid-and-gid"},
+		},
+		{
+			name:          "no match",
+			selectors:     []*common.Selector{{Type: "unix", Value: "uid:2000"}},
+			expectEntries: []string{},
+		},
+		{
+			name:       "no selectors",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "at least one selector is required",
+		},
+		{
+			name:       "selector without value",
+			selectors:  []*common.Selector{{Type: "unix"}},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `invalid selector "unix:": type and value are required`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.SimulateAttestation(ctx, &simulationpb.SimulateAttestationRequest{
+				Selectors: tt.selectors,
+			})
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			gotEntries := []string{}
+			for _, matched := range resp.Entries {
+				gotEntries = append(gotEntries, matched.Entry.EntryId)
+				spiretest.AssertProtoEqual(t, entries[matched.Entry.EntryId], matched.Entry)
+
+				svid := svids[matched.Entry.EntryId]
+				require.NotNil(t, matched.Svid)
+				require.Len(t, matched.Svid.CertChain, len(svid.Chain))
+				for i, cert := range svid.Chain {
+					assert.Equal(t, cert.Raw, matched.Svid.CertChain[i])
+				}
+				assert.Equal(t, svid.Chain[0].NotAfter.Unix(), matched.Svid.ExpiresAt)
+			}
+			assert.Equal(t, tt.expectEntries, gotEntries)
+		})
+	}
+}
+
+func setupServiceTest(t *testing.T, m manager.Manager) simulationpb.SimulationClient {
+	log, _ := test.NewNullLogger()
+	service := simulation.New(simulation.Config{
+		Log:     log,
+		Manager: m,
+	})
+
+	registerFn := func(s *grpc.Server) {
+		simulation.RegisterService(s, service)
+	}
+	contextFn := func(ctx context.Context) context.Context {
+		return ctx
+	}
+	conn, done := spiretest.NewAPIServer(t, registerFn, contextFn)
+	t.Cleanup(done)
+	return simulationpb.NewSimulationClient(conn)
+}
+
+type fakeManager struct {
+	manager.Manager
+
+	cache *cache.Cache
+}
+
+func (m *fakeManager) MatchingIdentities(selectors []*common.Selector) []cache.Identity {
+	return m.cache.MatchingIdentities(selectors)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/spiffe/spire/pkg/agent/manager"
 	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/cryptoutil"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	telemetry_agent "github.com/spiffe/spire/pkg/common/telemetry/agent"
@@ -24,9 +25,7 @@ import (
 	"github.com/zeebo/errs"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/balancer/roundrobin"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/status"
 )
 
 type AttestationResult struct {
@@ -37,6 +36,12 @@ type AttestationResult struct {
 
 type Attestor interface {
 	Attest(ctx context.Context) (*AttestationResult, error)
+
+	// Reattest performs node attestation again for the given private key and
+	// returns the new agent SVID. The current agent SVID and its key
+	// authenticate the agent to the server, and the bundle is used to
+	// authenticate the server.
+	Reattest(ctx context.Context, svid []*x509.Certificate, svidKey crypto.Signer, key crypto.Signer, bundle *bundleutil.Bundle) ([]*x509.Certificate, error)
 }
 
 type Config struct {
@@ -82,7 +87,7 @@ func (a *attestor) Attest(ctx context.Context) (res *AttestationResult, err erro
 	switch {
 	case svid == nil:
 		log.Info("SVID is not found. Starting node attestation")
-		svid, bundle, err = a.newSVID(ctx, key, bundle)
+		svid, bundle, err = a.newSVID(ctx, nil, key, bundle)
 		if err != nil {
 			return nil, err
 		}
@@ -99,18 +104,42 @@ func (a *attestor) Attest(ctx context.Context) (res *AttestationResult, err erro
 	return &AttestationResult{Bundle: bundle, SVID: svid, Key: key}, nil
 }
 
+func (a *attestor) Reattest(ctx context.Context, svid []*x509.Certificate, svidKey crypto.Signer, key crypto.Signer, bundle *bundleutil.Bundle) ([]*x509.Certificate, error) {
+	if a.c.JoinToken != "" {
+		return nil, errors.New("agents attested with a join token cannot reattest since join tokens can only be used once")
+	}
+	if bundle == nil {
+		// Never fall back to an insecure bootstrap once attested
+		return nil, errors.New("no bundle available to authenticate the server")
+	}
+
+	// Presenting the current SVID lets the server know the node has already
+	// been attested by this agent, so node attestors that only allow a node
+	// to attest once still accept it
+	agentCert := &tls.Certificate{
+		PrivateKey: svidKey,
+	}
+	for _, cert := range svid {
+		agentCert.Certificate = append(agentCert.Certificate, cert.Raw)
+	}
+
+	newSVID, _, err := a.newSVID(ctx, agentCert, key, bundle)
+	if err != nil {
+		return nil, err
+	}
+	return newSVID, nil
+}
+
 // Load the current SVID and key. The returned SVID is nil to indicate a new SVID should be created.
 func (a *attestor) loadSVID(ctx context.Context) ([]*x509.Certificate, crypto.Signer, error) {
 	km := a.c.Catalog.GetKeyManager()
-	key, err := km.GetKey(ctx)
-	switch status.Code(err) {
-	case codes.OK:
-	case codes.NotFound:
-	default:
+	keys, err := km.GetKeys(ctx)
+	if err != nil {
 		return nil, nil, fmt.Errorf("unable to load private key: %v", err)
 	}
 
 	svid := a.readSVIDFromDisk()
+	key := findSVIDKey(svid, keys)
 
 	privateKeyExists := key != nil
 	svidExists := svid != nil
@@ -121,7 +150,7 @@ func (a *attestor) loadSVID(ctx context.Context) ([]*x509.Certificate, crypto.Si
 		return svid, key, nil
 	case privateKeyExists && svidExists && svidIsExpired:
 		a.c.Log.WithField("expiry", svid[0].NotAfter).Warn("Private key recovered, but SVID is expired. Generating new keypair")
-	case privateKeyExists && !svidExists:
+	case len(keys) > 0 && !svidExists:
 		a.c.Log.Warn("Private key recovered, but no SVID found. Generating new keypair")
 	case !privateKeyExists && svidExists:
 		a.c.Log.Warn("SVID recovered, but no private key found. Generating new keypair")
@@ -129,13 +158,27 @@ func (a *attestor) loadSVID(ctx context.Context) ([]*x509.Certificate, crypto.Si
 		// Neither private key nor SVID were found.
 	}
 
-	key, err = km.GenerateKey(ctx)
+	key, err = km.GenerateKey(ctx, nil)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to generate private key: %v", err)
 	}
 	return nil, key, nil
 }
 
+// findSVIDKey returns the key whose public key matches the one in the SVID,
+// or nil if there is none.
+func findSVIDKey(svid []*x509.Certificate, keys []crypto.Signer) crypto.Signer {
+	if len(svid) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		if matches, err := cryptoutil.PublicKeyEqual(svid[0].PublicKey, key.Public()); err == nil && matches {
+			return key
+		}
+	}
+	return nil
+}
+
 // IsSVIDExpired returns true if the X.509 SVID provided is expired
 func IsSVIDExpired(svid []*x509.Certificate, timeNow func() time.Time) bool {
 	if len(svid) == 0 {
@@ -187,8 +230,9 @@ func (a *attestor) readSVIDFromDisk() []*x509.Certificate {
 }
 
 // newSVID obtains an agent svid for the given private key by performing node attesatation. The bundle is
-// necessary in order to validate the SPIRE server we are attesting to. Returns the SVID and an updated bundle.
-func (a *attestor) newSVID(ctx context.Context, key crypto.Signer, bundle *bundleutil.Bundle) (_ []*x509.Certificate, _ *bundleutil.Bundle, err error) {
+// necessary in order to validate the SPIRE server we are attesting to. The agent certificate, if any, is
+// presented to the server. Returns the SVID and an updated bundle.
+func (a *attestor) newSVID(ctx context.Context, agentCert *tls.Certificate, key crypto.Signer, bundle *bundleutil.Bundle) (_ []*x509.Certificate, _ *bundleutil.Bundle, err error) {
 	counter := telemetry_agent.StartNodeAttestorNewSVIDCall(a.c.Metrics)
 	defer counter.Done(&err)
 
@@ -198,7 +242,7 @@ func (a *attestor) newSVID(ctx context.Context, key crypto.Signer, bundle *bundl
 	}
 	telemetry_common.AddAttestorType(counter, attestor.Name())
 
-	conn, err := a.serverConn(ctx, bundle)
+	conn, err := a.serverConn(ctx, agentCert, bundle)
 	if err != nil {
 		return nil, nil, fmt.Errorf("create attestation client: %v", err)
 	}
@@ -220,13 +264,19 @@ func (a *attestor) newSVID(ctx context.Context, key crypto.Signer, bundle *bundl
 	return newSVID, newBundle, nil
 }
 
-func (a *attestor) serverConn(ctx context.Context, bundle *bundleutil.Bundle) (*grpc.ClientConn, error) {
+func (a *attestor) serverConn(ctx context.Context, agentCert *tls.Certificate, bundle *bundleutil.Bundle) (*grpc.ClientConn, error) {
 	if bundle != nil {
-		return client.DialServer(ctx, client.DialServerConfig{
+		config := client.DialServerConfig{
 			Address:     a.c.ServerAddress,
 			TrustDomain: a.c.TrustDomain,
 			GetBundle:   bundle.RootCAs,
-		})
+		}
+		if agentCert != nil {
+			config.GetAgentCertificate = func() *tls.Certificate {
+				return agentCert
+			}
+		}
+		return client.DialServer(ctx, config)
 	}
 
 	if !a.c.InsecureBootstrap {
@@ -24,6 +24,7 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	attestor "github.com/spiffe/spire/pkg/agent/attestor/node"
 	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/pemutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
@@ -31,9 +32,11 @@ import (
 	"github.com/spiffe/spire/test/fakes/fakeagentkeymanager"
 	"github.com/spiffe/spire/test/fakes/fakeagentnodeattestor"
 	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
 )
 
 var (
@@ -79,6 +82,7 @@ func TestAttestor(t *testing.T) {
 		cachedSVID                  []byte
 		err                         string
 		storeKey                    crypto.Signer
+		otherKeys                   []crypto.Signer
 		failFetchingAttestationData bool
 		agentService                *fakeAgentService
 		bundleService               *fakeBundleService
@@ -249,6 +253,20 @@ func TestAttestor(t *testing.T) {
 				bundle: bundle,
 			},
 		},
+		{
+			name:         "success with cached svid and one of several keys",
+			cachedBundle: caCert.Raw,
+			cachedSVID:   agentCert.Raw,
+			storeKey:     testKey,
+			otherKeys:    []crypto.Signer{testkey.MustEC256()},
+			agentService: &fakeAgentService{
+				svid:            svid,
+				failAttestAgent: true,
+			},
+			bundleService: &fakeBundleService{
+				bundle: bundle,
+			},
+		},
 		{
 			name:            "malformed cached svid ignored",
 			bootstrapBundle: caCert,
@@ -276,6 +294,20 @@ func TestAttestor(t *testing.T) {
 			},
 			err: "attestation failed by test",
 		},
+		{
+			name:            "mismatched key in keymanager ignored",
+			bootstrapBundle: caCert,
+			cachedSVID:      agentCert.Raw,
+			storeKey:        testkey.MustEC256(),
+			agentService: &fakeAgentService{
+				svid:            svid,
+				failAttestAgent: true,
+			},
+			bundleService: &fakeBundleService{
+				bundle: bundle,
+			},
+			err: "attestation failed by test",
+		},
 		{
 			name:            "get bundle error",
 			bootstrapBundle: caCert,
@@ -305,7 +337,7 @@ func TestAttestor(t *testing.T) {
 			})
 
 			// load up an in-memory key manager
-			km := prepareKeyManager(t, testCase.storeKey)
+			km := prepareKeyManager(t, testCase.storeKey, testCase.otherKeys...)
 
 			// initialize the catalog
 			catalog := fakeagentcatalog.New()
@@ -368,6 +400,9 @@ type fakeAgentService struct {
 	joinToken          string
 	svid               *types.X509SVID
 
+	// peerCerts holds the certificates presented by the agent
+	peerCerts []*x509.Certificate
+
 	agentv1.AgentServer
 }
 
@@ -377,6 +412,12 @@ func (s *fakeAgentService) AttestAgent(stream agentv1.Agent_AttestAgentServer) e
 		return err
 	}
 
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			s.peerCerts = tlsInfo.State.PeerCertificates
+		}
+	}
+
 	if s.failAttestAgent {
 		return errors.New("attestation failed by test")
 	}
@@ -443,14 +484,32 @@ func prepareTestDir(t *testing.T, cachedSVID, cachedBundle []byte) (string, stri
 	return svidCachePath, bundleCachePath
 }
 
-func prepareKeyManager(t *testing.T, key crypto.Signer) keymanager.KeyManager {
+func prepareKeyManager(t *testing.T, key crypto.Signer, otherKeys ...crypto.Signer) keymanager.KeyManager {
 	km := fakeagentkeymanager.New(t, "")
 	if key != nil {
 		storePrivateKey(t, km, key)
 	}
+	if len(otherKeys) > 0 {
+		return &multiKeyManager{KeyManager: km, otherKeys: otherKeys}
+	}
 	return km
 }
 
+// multiKeyManager returns other keys ahead of the stored key, like a key
+// manager that holds the key from an interrupted rotation.
+type multiKeyManager struct {
+	keymanager.KeyManager
+	otherKeys []crypto.Signer
+}
+
+func (km *multiKeyManager) GetKeys(ctx context.Context) ([]crypto.Signer, error) {
+	keys, err := km.KeyManager.GetKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]crypto.Signer(nil), km.otherKeys...), keys...), nil
+}
+
 func writeFile(t *testing.T, path string, data []byte, mode os.FileMode) {
 	require.NoError(t, ioutil.WriteFile(path, data, mode))
 }
@@ -553,3 +612,87 @@ func TestIsSVIDExpired(t *testing.T) {
 		})
 	}
 }
+
+func TestReattestRejectsInsecureOrSingleUseAttestation(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	key := testKey
+	bundle := bundleutil.BundleFromRootCAs(trustDomain, []*x509.Certificate{createCACertificate(t)})
+
+	// Join tokens can only be used once
+	a := attestor.New(&attestor.Config{
+		JoinToken:   "token",
+		TrustDomain: trustDomain,
+		Log:         log,
+	})
+	_, err := a.Reattest(context.Background(), nil, key, key, bundle)
+	require.EqualError(t, err, "agents attested with a join token cannot reattest since join tokens can only be used once")
+
+	// The server is always authenticated, even with insecure bootstrap
+	a = attestor.New(&attestor.Config{
+		InsecureBootstrap: true,
+		TrustDomain:       trustDomain,
+		Log:               log,
+	})
+	_, err = a.Reattest(context.Background(), nil, key, key, nil)
+	require.EqualError(t, err, "no bundle available to authenticate the server")
+}
+
+func TestReattestPresentsAgentSVID(t *testing.T) {
+	caCert := createCACertificate(t)
+	serverCert := createServerCertificate(t, caCert)
+	agentCert := createAgentCertificate(t, caCert, "/test/foo")
+
+	agentService := &fakeAgentService{
+		svid: &types.X509SVID{
+			Id:        &types.SPIFFEID{TrustDomain: trustDomain.String(), Path: "/spire/agent/test/foo"},
+			CertChain: [][]byte{agentCert.Raw},
+		},
+	}
+	bundleService := &fakeBundleService{
+		bundle: &types.Bundle{
+			TrustDomain:     trustDomain.String(),
+			X509Authorities: []*types.X509Certificate{{Asn1: caCert.Raw}},
+		},
+	}
+
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{
+			{
+				Certificate: [][]byte{serverCert.Raw},
+				PrivateKey:  testKey,
+			},
+		},
+		ClientAuth: tls.RequestClientCert,
+		MinVersion: tls.VersionTLS12,
+	})))
+	agentv1.RegisterAgentServer(server, agentService)
+	bundlev1.RegisterBundleServer(server, bundleService)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	spiretest.ServeGRPCServerOnListener(t, server, listener)
+
+	catalog := fakeagentcatalog.New()
+	catalog.SetNodeAttestor(fakeagentnodeattestor.New(t, fakeagentnodeattestor.Config{}))
+
+	log, _ := test.NewNullLogger()
+	a := attestor.New(&attestor.Config{
+		Catalog:       catalog,
+		Metrics:       telemetry.Blackhole{},
+		Log:           log,
+		TrustDomain:   trustDomain,
+		ServerAddress: listener.Addr().String(),
+	})
+
+	currentSVID := []*x509.Certificate{createAgentCertificate(t, caCert, "/test/foo")}
+	bundle := bundleutil.BundleFromRootCAs(trustDomain, []*x509.Certificate{caCert})
+	svid, err := a.Reattest(context.Background(), currentSVID, testKey, testKey, bundle)
+	require.NoError(t, err)
+	require.Equal(t, []*x509.Certificate{agentCert}, svid)
+
+	// The current SVID authenticates the agent to the server
+	require.Len(t, agentService.peerCerts, 1)
+	require.Equal(t, currentSVID[0].Raw, agentService.peerCerts[0].Raw)
+}
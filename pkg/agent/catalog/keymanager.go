@@ -6,6 +6,7 @@ import (
 	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
 	"github.com/spiffe/spire/pkg/agent/plugin/keymanager/disk"
 	"github.com/spiffe/spire/pkg/agent/plugin/keymanager/memory"
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager/tpm"
 )
 
 type keyManagerRepository struct {
@@ -21,7 +22,7 @@ func (repo *keyManagerRepository) Constraints() catalog.Constraints {
 }
 
 func (repo *keyManagerRepository) Versions() []catalog.Version {
-	return []catalog.Version{keyManagerV0{}}
+	return []catalog.Version{keyManagerV1{}, keyManagerV0{}}
 }
 
 func (repo *keyManagerRepository) LegacyVersion() (catalog.Version, bool) {
@@ -32,9 +33,15 @@ func (repo *keyManagerRepository) BuiltIns() []catalog.BuiltIn {
 	return []catalog.BuiltIn{
 		disk.BuiltIn(),
 		memory.BuiltIn(),
+		tpm.BuiltIn(),
 	}
 }
 
+type keyManagerV1 struct{}
+
+func (keyManagerV1) New() catalog.Facade { return new(keymanager.V1) }
+func (keyManagerV1) Deprecated() bool    { return false }
+
 type keyManagerV0 struct{}
 
 func (keyManagerV0) New() catalog.Facade { return new(keymanager.V0) }
@@ -2,6 +2,7 @@ package catalog
 
 import (
 	"github.com/spiffe/spire/pkg/agent/plugin/workloadattestor"
+	"github.com/spiffe/spire/pkg/agent/plugin/workloadattestor/awsecs"
 	"github.com/spiffe/spire/pkg/agent/plugin/workloadattestor/docker"
 	"github.com/spiffe/spire/pkg/agent/plugin/workloadattestor/k8s"
 	"github.com/spiffe/spire/pkg/agent/plugin/workloadattestor/unix"
@@ -30,6 +31,7 @@ func (repo *workloadAttestorRepository) LegacyVersion() (catalog.Version, bool)
 
 func (repo *workloadAttestorRepository) BuiltIns() []catalog.BuiltIn {
 	return []catalog.BuiltIn{
+		awsecs.BuiltIn(),
 		docker.BuiltIn(),
 		k8s.BuiltIn(),
 		unix.BuiltIn(),
@@ -3,10 +3,12 @@ package agent
 import (
 	"crypto/x509"
 	"net"
+	"os"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/health"
 	"github.com/spiffe/spire/pkg/common/telemetry"
@@ -16,6 +18,15 @@ type Config struct {
 	// Address to bind the workload api to
 	BindAddress *net.UnixAddr
 
+	// SocketMode is the file mode of the workload api socket. If zero,
+	// anyone can connect to it.
+	SocketMode os.FileMode
+
+	// SocketUID and SocketGID, if set, are the IDs of the user and group
+	// that own the workload api socket
+	SocketUID *int
+	SocketGID *int
+
 	// Directory to store runtime data
 	DataDir string
 
@@ -45,6 +56,27 @@ type Config struct {
 	// SyncInterval controls how often the agent sync synchronizer waits
 	SyncInterval time.Duration
 
+	// JWTSVIDRefreshAhead controls how long before expiration cached
+	// JWT-SVIDs are refreshed. Cached JWT-SVIDs are refreshed on the next
+	// fetch once inside that window, not in the background.
+	JWTSVIDRefreshAhead time.Duration
+
+	// ReattestInterval, if greater than zero, controls how often the agent
+	// reattests so the server resolves its node selectors again
+	ReattestInterval time.Duration
+
+	// JWTSVIDValidationLeeway is the clock skew tolerated when validating the
+	// time based claims of JWT-SVIDs through the Workload API
+	JWTSVIDValidationLeeway time.Duration
+
+	// WorkloadAttestationCacheTTL controls how long workload attestation
+	// results are reused for connections from the same process
+	WorkloadAttestationCacheTTL time.Duration
+
+	// EntryMatching controls how registration entries are matched against
+	// workload selectors
+	EntryMatching cache.EntryMatching
+
 	// Trust domain and associated CA bundle
 	TrustDomain spiffeid.TrustDomain
 	TrustBundle []*x509.Certificate
@@ -0,0 +1,74 @@
+package endpoints
+
+import (
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+// attestationCache holds workload attestation results for a short period of
+// time so that rapid reconnects from the same process do not have to re-run
+// the workload attestors. Results are keyed by PID and process start time so
+// that a reused PID never gets the selectors of the process that held it
+// before.
+type attestationCache struct {
+	clock clock.Clock
+	ttl   time.Duration
+
+	mtx     sync.Mutex
+	entries map[int32]attestationCacheEntry
+}
+
+type attestationCacheEntry struct {
+	startTime string
+	selectors []*common.Selector
+	expiresAt time.Time
+}
+
+func newAttestationCache(clk clock.Clock, ttl time.Duration) *attestationCache {
+	return &attestationCache{
+		clock:   clk,
+		ttl:     ttl,
+		entries: make(map[int32]attestationCacheEntry),
+	}
+}
+
+// Get returns the cached selectors for the process, if any.
+func (c *attestationCache) Get(pid int32, startTime string) ([]*common.Selector, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	entry, ok := c.entries[pid]
+	if !ok {
+		return nil, false
+	}
+	if entry.startTime != startTime || !c.clock.Now().Before(entry.expiresAt) {
+		// Either the PID has been reused by a new process or the entry has
+		// expired. In both cases the entry is no longer useful.
+		delete(c.entries, pid)
+		return nil, false
+	}
+	return entry.selectors, true
+}
+
+// Set caches the selectors for the process, replacing any previous entry
+// for the same PID.
+func (c *attestationCache) Set(pid int32, startTime string, selectors []*common.Selector) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := c.clock.Now()
+	for p, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, p)
+		}
+	}
+
+	c.entries[pid] = attestationCacheEntry{
+		startTime: startTime,
+		selectors: selectors,
+		expiresAt: now.Add(c.ttl),
+	}
+}
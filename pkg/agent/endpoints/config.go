@@ -2,6 +2,8 @@ package endpoints
 
 import (
 	"net"
+	"os"
+	"time"
 
 	discovery_v2 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
 	secret_v3 "github.com/envoyproxy/go-control-plane/envoy/service/secret/v3"
@@ -20,6 +22,15 @@ import (
 type Config struct {
 	BindAddr *net.UnixAddr
 
+	// SocketMode is the file mode of the socket. If zero, anyone can connect
+	// to the socket (i.e. 0777).
+	SocketMode os.FileMode
+
+	// SocketUID and SocketGID, if set, are the IDs of the user and group
+	// that own the socket.
+	SocketUID *int
+	SocketGID *int
+
 	Attestor attestor.Attestor
 
 	Manager manager.Manager
@@ -36,6 +47,14 @@ type Config struct {
 
 	AllowUnauthenticatedVerifiers bool
 
+	// AttestationCacheTTL is how long workload attestation results are
+	// reused for connections from the same process. Zero disables caching.
+	AttestationCacheTTL time.Duration
+
+	// JWTSVIDValidationLeeway is the clock skew tolerated when validating
+	// JWT-SVIDs
+	JWTSVIDValidationLeeway time.Duration
+
 	// Hooks used by the unit tests to assert that the configuration provided
 	// to each handler is correct and return fake handlers.
 	newWorkloadAPIServer func(workload.Config) workload_pb.SpiffeWorkloadAPIServer
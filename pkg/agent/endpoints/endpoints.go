@@ -6,6 +6,7 @@ import (
 	"net"
 	"os"
 
+	"github.com/andres-erbsen/clock"
 	discovery_v2 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
 	secret_v3 "github.com/envoyproxy/go-control-plane/envoy/service/secret/v3"
 	"github.com/sirupsen/logrus"
@@ -27,6 +28,9 @@ type Server interface {
 
 type Endpoints struct {
 	addr              *net.UnixAddr
+	socketMode        os.FileMode
+	socketUID         *int
+	socketGID         *int
 	log               logrus.FieldLogger
 	metrics           telemetry.Metrics
 	workloadAPIServer workload_pb.SpiffeWorkloadAPIServer
@@ -37,6 +41,9 @@ type Endpoints struct {
 
 func New(c Config) *Endpoints {
 	attestor := peerTrackerAttestor{Attestor: c.Attestor}
+	if c.AttestationCacheTTL > 0 {
+		attestor.Cache = newAttestationCache(clock.New(), c.AttestationCacheTTL)
+	}
 
 	if c.newWorkloadAPIServer == nil {
 		c.newWorkloadAPIServer = func(c workload.Config) workload_pb.SpiffeWorkloadAPIServer {
@@ -63,6 +70,7 @@ func New(c Config) *Endpoints {
 		Manager:                       c.Manager,
 		Attestor:                      attestor,
 		AllowUnauthenticatedVerifiers: c.AllowUnauthenticatedVerifiers,
+		JWTSVIDValidationLeeway:       c.JWTSVIDValidationLeeway,
 	})
 
 	sdsv2Server := c.newSDSv2Server(sdsv2.Config{
@@ -83,8 +91,16 @@ func New(c Config) *Endpoints {
 		SocketPath: c.BindAddr.String(),
 	})
 
+	socketMode := c.SocketMode
+	if socketMode == 0 {
+		socketMode = os.ModePerm
+	}
+
 	return &Endpoints{
 		addr:              c.BindAddr,
+		socketMode:        socketMode,
+		socketUID:         c.SocketUID,
+		socketGID:         c.SocketGID,
 		log:               c.Log,
 		metrics:           c.Metrics,
 		workloadAPIServer: workloadAPIServer,
@@ -114,6 +130,7 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	defer os.Remove(e.addr.String())
 	defer l.Close()
 
 	e.log.Info("Starting Workload and SDS APIs")
@@ -138,16 +155,60 @@ func (e *Endpoints) createUDSListener() (net.Listener, error) {
 	os.Remove(e.addr.String())
 
 	unixListener := &peertracker.ListenerFactory{
-		Log: e.log,
+		Log:             e.log,
+		NewUnixListener: e.listenUnix,
 	}
 
 	l, err := unixListener.ListenUnix(e.addr.Network(), e.addr)
 	if err != nil {
 		return nil, fmt.Errorf("create UDS listener: %s", err)
 	}
+	return l, nil
+}
+
+// listenUnix binds the socket to a temporary path next to the configured one
+// and only moves it into place once its mode and ownership are set, so that
+// workloads never connect to it while it has the wrong ones.
+func (e *Endpoints) listenUnix(network string, laddr *net.UnixAddr) (*net.UnixListener, error) {
+	tmpPath := laddr.Name + ".tmp"
+	os.Remove(tmpPath)
+
+	l, err := net.ListenUnix(network, &net.UnixAddr{Net: laddr.Net, Name: tmpPath})
+	if err != nil {
+		return nil, err
+	}
+	// The socket is moved, so it is removed by ListenAndServe instead
+	l.SetUnlinkOnClose(false)
 
-	if err := os.Chmod(e.addr.String(), os.ModePerm); err != nil {
-		return nil, fmt.Errorf("unable to change UDS permissions: %v", err)
+	if err := e.setSocketPermissions(tmpPath); err != nil {
+		l.Close()
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, laddr.Name); err != nil {
+		l.Close()
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("unable to move UDS into place: %v", err)
 	}
 	return l, nil
 }
+
+func (e *Endpoints) setSocketPermissions(path string) error {
+	if err := os.Chmod(path, e.socketMode); err != nil {
+		return fmt.Errorf("unable to change UDS permissions: %v", err)
+	}
+
+	uid, gid := -1, -1
+	if e.socketUID != nil {
+		uid = *e.socketUID
+	}
+	if e.socketGID != nil {
+		gid = *e.socketGID
+	}
+	if uid != -1 || gid != -1 {
+		if err := os.Chown(path, uid, gid); err != nil {
+			return fmt.Errorf("unable to change UDS ownership: %v", err)
+		}
+	}
+	return nil
+}
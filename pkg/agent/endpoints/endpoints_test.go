@@ -6,6 +6,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"syscall"
 	"testing"
 	"time"
 
@@ -169,11 +170,14 @@ func TestEndpoints(t *testing.T) {
 				DefaultSVIDName:   "DefaultSVIDName",
 				DefaultBundleName: "DefaultBundleName",
 
+				JWTSVIDValidationLeeway: 2 * time.Minute,
+
 				// Assert the provided config and return a fake Workload API server
 				newWorkloadAPIServer: func(c workload.Config) workload_pb.SpiffeWorkloadAPIServer {
 					attestor, ok := c.Attestor.(peerTrackerAttestor)
 					require.True(t, ok, "attestor was not a peerTrackerAttestor wrapper")
 					assert.Equal(t, FakeManager{}, c.Manager)
+					assert.Equal(t, 2*time.Minute, c.JWTSVIDValidationLeeway)
 					return FakeWorkloadAPIServer{Attestor: attestor}
 				},
 
@@ -240,6 +244,87 @@ func TestEndpoints(t *testing.T) {
 	}
 }
 
+func TestEndpointsSocketPermissions(t *testing.T) {
+	udsPath := filepath.Join(spiretest.TempDir(t), "agent.sock")
+	uid, gid := os.Getuid(), os.Getgid()
+
+	log, _ := test.NewNullLogger()
+	endpoints := New(Config{
+		BindAddr: &net.UnixAddr{
+			Net:  "unix",
+			Name: udsPath,
+		},
+		SocketMode: 0660,
+		SocketUID:  &uid,
+		SocketGID:  &gid,
+		Log:        log,
+		Metrics:    fakemetrics.New(),
+		Attestor:   FakeAttestor{},
+		Manager:    FakeManager{},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- endpoints.ListenAndServe(ctx)
+	}()
+
+	// The socket only shows up once its mode and ownership are set
+	var info os.FileInfo
+	require.Eventually(t, func() bool {
+		var err error
+		info, err = os.Stat(udsPath)
+		return err == nil
+	}, time.Minute, 5*time.Millisecond)
+	require.Equal(t, os.ModeSocket, info.Mode().Type())
+	require.Equal(t, os.FileMode(0660), info.Mode().Perm())
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	require.True(t, ok)
+	require.Equal(t, uint32(uid), stat.Uid)
+	require.Equal(t, uint32(gid), stat.Gid)
+
+	_, err := os.Stat(udsPath + ".tmp")
+	require.True(t, os.IsNotExist(err), "temporary socket was left behind")
+
+	cancel()
+	require.NoError(t, <-errCh)
+	_, err = os.Stat(udsPath)
+	require.True(t, os.IsNotExist(err), "socket was not removed")
+}
+
+func TestEndpointsDefaultSocketPermissions(t *testing.T) {
+	udsPath := filepath.Join(spiretest.TempDir(t), "agent.sock")
+
+	log, _ := test.NewNullLogger()
+	endpoints := New(Config{
+		BindAddr: &net.UnixAddr{
+			Net:  "unix",
+			Name: udsPath,
+		},
+		Log:      log,
+		Metrics:  fakemetrics.New(),
+		Attestor: FakeAttestor{},
+		Manager:  FakeManager{},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- endpoints.ListenAndServe(ctx)
+	}()
+
+	var info os.FileInfo
+	require.Eventually(t, func() bool {
+		var err error
+		info, err = os.Stat(udsPath)
+		return err == nil
+	}, time.Minute, 5*time.Millisecond)
+	require.Equal(t, os.ModePerm, info.Mode().Perm())
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
+
 type FakeManager struct {
 	manager.Manager
 }
@@ -12,6 +12,17 @@ import (
 
 type peerTrackerAttestor struct {
 	Attestor attestor.Attestor
+
+	// Cache, if set, is used to reuse attestation results across
+	// connections from the same process.
+	Cache *attestationCache
+}
+
+// startTimeWatcher is implemented by watchers that can report the start time
+// of the watched process, which is needed to safely cache attestation
+// results.
+type startTimeWatcher interface {
+	StartTime() string
 }
 
 func (a peerTrackerAttestor) Attest(ctx context.Context) ([]*common.Selector, error) {
@@ -20,7 +31,15 @@ func (a peerTrackerAttestor) Attest(ctx context.Context) ([]*common.Selector, er
 		return nil, status.Error(codes.Internal, "peer tracker watcher missing from context")
 	}
 
-	selectors := a.Attestor.Attest(ctx, int(watcher.PID()))
+	var startTime string
+	if w, ok := watcher.(startTimeWatcher); ok && a.Cache != nil {
+		startTime = w.StartTime()
+	}
+
+	selectors, cached := a.cachedSelectors(watcher.PID(), startTime)
+	if !cached {
+		selectors = a.Attestor.Attest(ctx, int(watcher.PID()))
+	}
 
 	// Ensure that the original caller is still alive so that we know we didn't
 	// attest some other process that happened to be assigned the original PID
@@ -28,5 +47,16 @@ func (a peerTrackerAttestor) Attest(ctx context.Context) ([]*common.Selector, er
 		return nil, status.Errorf(codes.Unauthenticated, "could not verify existence of the original caller: %v", err)
 	}
 
+	if !cached && startTime != "" {
+		a.Cache.Set(watcher.PID(), startTime, selectors)
+	}
+
 	return selectors, nil
 }
+
+func (a peerTrackerAttestor) cachedSelectors(pid int32, startTime string) ([]*common.Selector, bool) {
+	if startTime == "" {
+		return nil, false
+	}
+	return a.Cache.Get(pid, startTime)
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"os"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/spiffe/spire/pkg/common/peertracker"
 	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
 )
@@ -35,6 +39,65 @@ func TestPeerTrackerAttestor(t *testing.T) {
 	})
 }
 
+func TestPeerTrackerAttestorCache(t *testing.T) {
+	clk := clock.NewMock(t)
+	counting := &countingAttestor{}
+	attestor := peerTrackerAttestor{
+		Attestor: counting,
+		Cache:    newAttestationCache(clk, time.Second),
+	}
+
+	attest := func(pid int32, startTime string, alive bool) ([]*common.Selector, error) {
+		return attestor.Attest(withFakeStartTimeWatcher(fakeStartTimeWatcher{
+			pid:       pid,
+			startTime: startTime,
+			alive:     alive,
+		}))
+	}
+
+	// Rapid reconnects from the same process only attest once
+	for i := 0; i < 3; i++ {
+		selectors, err := attest(1, "100", true)
+		require.NoError(t, err)
+		require.Equal(t, []*common.Selector{{Type: "pid", Value: "1"}}, selectors)
+	}
+	require.Equal(t, 1, counting.calls)
+
+	// A reused PID (different start time) is attested again
+	selectors, err := attest(1, "200", true)
+	require.NoError(t, err)
+	require.Equal(t, []*common.Selector{{Type: "pid", Value: "1"}}, selectors)
+	require.Equal(t, 2, counting.calls)
+
+	// ...and the previous process' entry is gone
+	_, err = attest(1, "100", true)
+	require.NoError(t, err)
+	require.Equal(t, 3, counting.calls)
+
+	// Cached results still require the caller to be alive
+	_, err = attest(1, "100", false)
+	spiretest.RequireGRPCStatus(t, err, codes.Unauthenticated, "could not verify existence of the original caller: dead")
+	require.Equal(t, 3, counting.calls)
+
+	// Entries expire after the TTL
+	clk.Add(time.Second)
+	_, err = attest(1, "100", true)
+	require.NoError(t, err)
+	require.Equal(t, 4, counting.calls)
+
+	// Other processes are cached independently
+	_, err = attest(2, "100", true)
+	require.NoError(t, err)
+	require.Equal(t, 5, counting.calls)
+
+	// Watchers that can't report the start time are never cached
+	attestor.Attestor = FakeAttestor{}
+	selectors, err = attestor.Attest(WithFakeWatcher(true))
+	require.NoError(t, err)
+	require.Equal(t, []*common.Selector{{Type: "Type", Value: "Value"}}, selectors)
+	require.Empty(t, attestor.Cache.entries[int32(os.Getpid())])
+}
+
 type FakeAttestor struct{}
 
 func (a FakeAttestor) Attest(ctx context.Context, pid int) []*common.Selector {
@@ -44,6 +107,15 @@ func (a FakeAttestor) Attest(ctx context.Context, pid int) []*common.Selector {
 	return nil
 }
 
+type countingAttestor struct {
+	calls int
+}
+
+func (a *countingAttestor) Attest(ctx context.Context, pid int) []*common.Selector {
+	a.calls++
+	return []*common.Selector{{Type: "pid", Value: strconv.Itoa(pid)}}
+}
+
 func WithFakeWatcher(alive bool) context.Context {
 	return peer.NewContext(context.Background(), &peer.Peer{
 		AuthInfo: peertracker.AuthInfo{
@@ -64,3 +136,30 @@ func (w FakeWatcher) IsAlive() error {
 }
 
 func (w FakeWatcher) PID() int32 { return int32(os.Getpid()) }
+
+type fakeStartTimeWatcher struct {
+	pid       int32
+	startTime string
+	alive     bool
+}
+
+func withFakeStartTimeWatcher(w fakeStartTimeWatcher) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: peertracker.AuthInfo{
+			Watcher: w,
+		},
+	})
+}
+
+func (w fakeStartTimeWatcher) Close() {}
+
+func (w fakeStartTimeWatcher) IsAlive() error {
+	if !w.alive {
+		return errors.New("dead")
+	}
+	return nil
+}
+
+func (w fakeStartTimeWatcher) PID() int32 { return w.pid }
+
+func (w fakeStartTimeWatcher) StartTime() string { return w.startTime }
@@ -44,6 +44,10 @@ type Config struct {
 	Manager                       Manager
 	Attestor                      Attestor
 	AllowUnauthenticatedVerifiers bool
+
+	// JWTSVIDValidationLeeway is the clock skew tolerated when validating
+	// the time based claims of JWT-SVIDs
+	JWTSVIDValidationLeeway time.Duration
 }
 
 type Handler struct {
@@ -164,7 +168,7 @@ func (h *Handler) ValidateJWTSVID(ctx context.Context, req *workload.ValidateJWT
 
 	keyStore := keyStoreFromBundles(h.getWorkloadBundles(selectors))
 
-	spiffeID, claims, err := jwtsvid.ValidateToken(ctx, req.Svid, keyStore, []string{req.Audience})
+	spiffeID, claims, err := jwtsvid.ValidateTokenWithLeeway(ctx, req.Svid, keyStore, []string{req.Audience}, h.c.JWTSVIDValidationLeeway)
 	if err != nil {
 		log.WithError(err).Warn("Failed to validate JWT")
 		return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -118,6 +118,23 @@ type Cache struct {
 
 	// bundles holds the trust bundles, keyed by trust domain id (i.e. "spiffe://domain.test")
 	bundles map[spiffeid.TrustDomain]*bundleutil.Bundle
+
+	// entryMatching controls how entries are matched against workloads
+	entryMatching EntryMatching
+}
+
+// EntryMatching controls how registration entries are matched against the
+// selectors of a workload.
+type EntryMatching struct {
+	// MostSpecific, if set, only matches the single most specific entry whose
+	// selectors are a subset of the workload selectors, instead of all of
+	// them. Entries are ranked by the sum of the weights of their selectors,
+	// then by their number of selectors and finally by ascending entry ID.
+	MostSpecific bool
+
+	// SelectorTypeWeights holds the weight of each selector type when ranking
+	// entries. Selector types without a weight count as 1.
+	SelectorTypeWeights map[string]int
 }
 
 // StaleEntry holds stale entries with SVIDs expiration time
@@ -181,6 +198,14 @@ func (c *Cache) CountSVIDs() int {
 	return records
 }
 
+// SetEntryMatching changes how registration entries are matched against
+// workload selectors. It is meant to be called before the cache is used.
+func (c *Cache) SetEntryMatching(entryMatching EntryMatching) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entryMatching = entryMatching
+}
+
 func (c *Cache) MatchingIdentities(selectors []*common.Selector) []Identity {
 	set, setDone := allocSelectorSet(selectors...)
 	defer setDone()
@@ -287,6 +312,8 @@ func (c *Cache) UpdateEntries(update *UpdateEntries, checkSVID func(*common.Regi
 			delete(c.records, id)
 			// Remove stale entry since, registration entry is no longer on cache.
 			delete(c.staleEntries, id)
+			// Drop the JWT-SVIDs minted for the entry
+			c.RemoveJWTSVIDs(id)
 		}
 	}
 
@@ -299,6 +326,12 @@ func (c *Cache) UpdateEntries(update *UpdateEntries, checkSVID func(*common.Regi
 
 		record, existingEntry := c.updateOrCreateRecord(newEntry)
 
+		// JWT-SVIDs minted for a previous revision of the entry are no
+		// longer valid
+		if existingEntry != nil && (existingEntry.RevisionNumber != newEntry.RevisionNumber || existingEntry.SpiffeId != newEntry.SpiffeId) {
+			c.RemoveJWTSVIDs(newEntry.EntryId)
+		}
+
 		// Calculate the difference in selectors, add/remove the record
 		// from impacted selector indices, and add the selector diff to the
 		// notify set.
@@ -598,6 +631,10 @@ func (c *Cache) matchingIdentities(set selectorSet) []Identity {
 		return nil
 	}
 
+	if c.entryMatching.MostSpecific {
+		return []Identity{makeIdentity(c.mostSpecificRecord(records))}
+	}
+
 	// Return identities in ascending "entry id" order to maintain a consistent
 	// ordering.
 	// TODO: figure out how to determine the "default" identity
@@ -670,6 +707,36 @@ func (c *Cache) getRecordsForSelectors(set selectorSet) (recordSet, func()) {
 	return records, recordsDone
 }
 
+// mostSpecificRecord returns the record with the highest selector weight,
+// breaking ties by number of selectors and then by lowest entry ID.
+func (c *Cache) mostSpecificRecord(records recordSet) *cacheRecord {
+	var best *cacheRecord
+	var bestWeight int
+	for record := range records {
+		weight := c.selectorsWeight(record.entry.Selectors)
+		switch {
+		case best == nil,
+			weight > bestWeight,
+			weight == bestWeight && len(record.entry.Selectors) > len(best.entry.Selectors),
+			weight == bestWeight && len(record.entry.Selectors) == len(best.entry.Selectors) && record.entry.EntryId < best.entry.EntryId:
+			best, bestWeight = record, weight
+		}
+	}
+	return best
+}
+
+func (c *Cache) selectorsWeight(selectors []*common.Selector) int {
+	var weight int
+	for _, s := range selectors {
+		if w, ok := c.entryMatching.SelectorTypeWeights[s.Type]; ok {
+			weight += w
+		} else {
+			weight++
+		}
+	}
+	return weight
+}
+
 // getSelectorIndex gets the selector index for the selector. If one doesn't
 // exist, it is created.
 func (c *Cache) getSelectorIndex(s selector) *selectorIndex {
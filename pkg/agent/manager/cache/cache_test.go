@@ -9,6 +9,7 @@ import (
 
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/client"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/proto/spire/common"
@@ -85,6 +86,98 @@ func TestMatchingIdentities(t *testing.T) {
 	}, identities)
 }
 
+func TestMatchingIdentitiesMostSpecific(t *testing.T) {
+	typed := func(selectorType, value string) *common.Selector {
+		return &common.Selector{Type: selectorType, Value: value}
+	}
+	workload := []*common.Selector{
+		typed("unix", "uid:1000"),
+		typed("unix", "gid:1000"),
+		typed("k8s", "ns:foo"),
+		typed("k8s", "sa:bar"),
+	}
+
+	uid := &common.RegistrationEntry{EntryId: "UID", Selectors: workload[:1]}
+	uidGID := &common.RegistrationEntry{EntryId: "UIDGID", Selectors: workload[:2]}
+	ns := &common.RegistrationEntry{EntryId: "NS", Selectors: workload[2:3]}
+	nsSA := &common.RegistrationEntry{EntryId: "NSSA", Selectors: workload[2:4]}
+	nsSA2 := &common.RegistrationEntry{EntryId: "NSSA2", Selectors: workload[2:4]}
+	other := &common.RegistrationEntry{EntryId: "OTHER", Selectors: []*common.Selector{typed("k8s", "ns:other")}}
+	entries := []*common.RegistrationEntry{uid, uidGID, ns, nsSA, nsSA2, other}
+
+	for _, tt := range []struct {
+		name     string
+		matching EntryMatching
+		expected []Identity
+	}{
+		{
+			name:     "all matching entries by default",
+			expected: []Identity{{Entry: ns}, {Entry: nsSA}, {Entry: nsSA2}, {Entry: uid}, {Entry: uidGID}},
+		},
+		{
+			name:     "most selectors wins, ties broken by entry ID",
+			matching: EntryMatching{MostSpecific: true},
+			expected: []Identity{{Entry: nsSA}},
+		},
+		{
+			name: "selector type weights take precedence",
+			matching: EntryMatching{
+				MostSpecific:        true,
+				SelectorTypeWeights: map[string]int{"unix": 3},
+			},
+			expected: []Identity{{Entry: uidGID}},
+		},
+		{
+			name: "zero weights fall back to the number of selectors",
+			matching: EntryMatching{
+				MostSpecific:        true,
+				SelectorTypeWeights: map[string]int{"unix": 0, "k8s": 0},
+			},
+			expected: []Identity{{Entry: nsSA}},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newTestCache()
+			cache.SetEntryMatching(tt.matching)
+			cache.UpdateEntries(&UpdateEntries{
+				Bundles:             makeBundles(bundleV1),
+				RegistrationEntries: makeRegistrationEntries(entries...),
+			}, nil)
+			cache.UpdateSVIDs(&UpdateSVIDs{
+				X509SVIDs: makeX509SVIDs(entries...),
+			})
+
+			assert.Equal(t, tt.expected, cache.MatchingIdentities(workload))
+			assert.Equal(t, tt.expected, cache.FetchWorkloadUpdate(workload).Identities)
+		})
+	}
+
+	t.Run("weight ties broken by number of selectors", func(t *testing.T) {
+		cache := newTestCache()
+		cache.SetEntryMatching(EntryMatching{
+			MostSpecific:        true,
+			SelectorTypeWeights: map[string]int{"k8s": 2},
+		})
+		// Both entries weigh 2
+		entries := []*common.RegistrationEntry{ns, uidGID}
+		cache.UpdateEntries(&UpdateEntries{
+			Bundles:             makeBundles(bundleV1),
+			RegistrationEntries: makeRegistrationEntries(entries...),
+		}, nil)
+		cache.UpdateSVIDs(&UpdateSVIDs{
+			X509SVIDs: makeX509SVIDs(entries...),
+		})
+		assert.Equal(t, []Identity{{Entry: uidGID}}, cache.MatchingIdentities(workload))
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		cache := newTestCache()
+		cache.SetEntryMatching(EntryMatching{MostSpecific: true})
+		assert.Empty(t, cache.MatchingIdentities(workload))
+	})
+}
+
 func TestCountSVIDs(t *testing.T) {
 	cache := newTestCache()
 
@@ -594,6 +687,46 @@ func TestGetStaleEntries(t *testing.T) {
 	assert.Empty(t, cache.GetStaleEntries())
 }
 
+func TestJWTSVIDsRemovedOnEntryChanges(t *testing.T) {
+	cache := newTestCache()
+
+	foo := makeRegistrationEntry("FOO", "A")
+	bar := makeRegistrationEntry("BAR", "B")
+	updateEntries := &UpdateEntries{
+		Bundles:             makeBundles(bundleV1),
+		RegistrationEntries: makeRegistrationEntries(foo, bar),
+	}
+	cache.UpdateEntries(updateEntries, nil)
+
+	now := time.Now()
+	jwtSVID := &client.JWTSVID{Token: "X", IssuedAt: now, ExpiresAt: now.Add(time.Minute)}
+	cache.SetJWTSVID("FOO", []string{"audience"}, jwtSVID)
+	cache.SetJWTSVID("BAR", []string{"audience"}, jwtSVID)
+
+	// JWT-SVIDs are kept if the entries did not change
+	cache.UpdateEntries(updateEntries, nil)
+	_, ok := cache.GetJWTSVID("FOO", []string{"audience"})
+	assert.True(t, ok)
+	_, ok = cache.GetJWTSVID("BAR", []string{"audience"})
+	assert.True(t, ok)
+
+	// JWT-SVIDs are dropped when the entry is updated
+	foo = makeRegistrationEntry("FOO", "A")
+	foo.RevisionNumber++
+	updateEntries.RegistrationEntries = makeRegistrationEntries(foo, bar)
+	cache.UpdateEntries(updateEntries, nil)
+	_, ok = cache.GetJWTSVID("FOO", []string{"audience"})
+	assert.False(t, ok)
+	_, ok = cache.GetJWTSVID("BAR", []string{"audience"})
+	assert.True(t, ok)
+
+	// JWT-SVIDs are dropped when the entry is removed
+	updateEntries.RegistrationEntries = makeRegistrationEntries(foo)
+	cache.UpdateEntries(updateEntries, nil)
+	_, ok = cache.GetJWTSVID("BAR", []string{"audience"})
+	assert.False(t, ok)
+}
+
 func BenchmarkCacheGlobalNotification(b *testing.B) {
 	cache := newTestCache()
 
@@ -7,23 +7,27 @@ import (
 	"sort"
 	"sync"
 
-	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/agent/client"
 )
 
+// JWTSVIDCache caches JWT-SVIDs by registration entry and audience.
 type JWTSVIDCache struct {
 	mu    sync.Mutex
 	svids map[string]*client.JWTSVID
+	// keys holds the cache keys in use for each registration entry, so the
+	// JWT-SVIDs of an entry can be dropped when it changes
+	keys map[string]map[string]struct{}
 }
 
 func NewJWTSVIDCache() *JWTSVIDCache {
 	return &JWTSVIDCache{
 		svids: make(map[string]*client.JWTSVID),
+		keys:  make(map[string]map[string]struct{}),
 	}
 }
 
-func (c *JWTSVIDCache) GetJWTSVID(spiffeID spiffeid.ID, audience []string) (*client.JWTSVID, bool) {
-	key := jwtSVIDKey(spiffeID, audience)
+func (c *JWTSVIDCache) GetJWTSVID(entryID string, audience []string) (*client.JWTSVID, bool) {
+	key := jwtSVIDKey(entryID, audience)
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -31,22 +35,40 @@ func (c *JWTSVIDCache) GetJWTSVID(spiffeID spiffeid.ID, audience []string) (*cli
 	return svid, ok
 }
 
-func (c *JWTSVIDCache) SetJWTSVID(spiffeID spiffeid.ID, audience []string, svid *client.JWTSVID) {
-	key := jwtSVIDKey(spiffeID, audience)
+func (c *JWTSVIDCache) SetJWTSVID(entryID string, audience []string, svid *client.JWTSVID) {
+	key := jwtSVIDKey(entryID, audience)
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.svids[key] = svid
+
+	keys, ok := c.keys[entryID]
+	if !ok {
+		keys = make(map[string]struct{})
+		c.keys[entryID] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+// RemoveJWTSVIDs drops all the cached JWT-SVIDs for the given entry.
+func (c *JWTSVIDCache) RemoveJWTSVIDs(entryID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.keys[entryID] {
+		delete(c.svids, key)
+	}
+	delete(c.keys, entryID)
 }
 
-func jwtSVIDKey(spiffeID spiffeid.ID, audience []string) string {
+func jwtSVIDKey(entryID string, audience []string) string {
 	h := sha256.New()
 
 	// duplicate and sort the audience slice
 	audience = append([]string(nil), audience...)
 	sort.Strings(audience)
 
-	_, _ = io.WriteString(h, spiffeID.String())
+	_, _ = io.WriteString(h, entryID)
 	for _, a := range audience {
 		_, _ = io.WriteString(h, a)
 	}
@@ -4,7 +4,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/agent/client"
 	"github.com/stretchr/testify/assert"
 )
@@ -15,16 +14,43 @@ func TestJWTSVIDCache(t *testing.T) {
 
 	cache := NewJWTSVIDCache()
 
-	spiffeID := spiffeid.RequireFromString("spiffe://example.org/blog")
-
 	// JWT is not cached
-	actual, ok := cache.GetJWTSVID(spiffeID, []string{"bar"})
+	actual, ok := cache.GetJWTSVID("FOO", []string{"bar"})
 	assert.False(t, ok)
 	assert.Nil(t, actual)
 
 	// JWT is cached
-	cache.SetJWTSVID(spiffeID, []string{"bar"}, expected)
-	actual, ok = cache.GetJWTSVID(spiffeID, []string{"bar"})
+	cache.SetJWTSVID("FOO", []string{"bar"}, expected)
+	actual, ok = cache.GetJWTSVID("FOO", []string{"bar"})
 	assert.True(t, ok)
 	assert.Equal(t, expected, actual)
+
+	// JWT is not cached for a different entry
+	actual, ok = cache.GetJWTSVID("BAR", []string{"bar"})
+	assert.False(t, ok)
+	assert.Nil(t, actual)
+}
+
+func TestJWTSVIDCacheRemoveJWTSVIDs(t *testing.T) {
+	now := time.Now()
+	fooBar := &client.JWTSVID{Token: "X", IssuedAt: now, ExpiresAt: now.Add(time.Second)}
+	fooBaz := &client.JWTSVID{Token: "Y", IssuedAt: now, ExpiresAt: now.Add(time.Second)}
+	barBar := &client.JWTSVID{Token: "Z", IssuedAt: now, ExpiresAt: now.Add(time.Second)}
+
+	cache := NewJWTSVIDCache()
+	cache.SetJWTSVID("FOO", []string{"bar"}, fooBar)
+	cache.SetJWTSVID("FOO", []string{"baz"}, fooBaz)
+	cache.SetJWTSVID("BAR", []string{"bar"}, barBar)
+
+	cache.RemoveJWTSVIDs("FOO")
+
+	_, ok := cache.GetJWTSVID("FOO", []string{"bar"})
+	assert.False(t, ok)
+	_, ok = cache.GetJWTSVID("FOO", []string{"baz"})
+	assert.False(t, ok)
+
+	// JWTs for other entries are kept
+	actual, ok := cache.GetJWTSVID("BAR", []string{"bar"})
+	assert.True(t, ok)
+	assert.Equal(t, barBar, actual)
 }
@@ -31,6 +31,20 @@ type Config struct {
 	SyncInterval     time.Duration
 	RotationInterval time.Duration
 
+	// JWTSVIDRefreshAhead, if set, is how long before expiration a cached
+	// JWT-SVID is refreshed. Defaults to half of the JWT-SVID lifetime. The
+	// refresh is lazy: it happens on the first fetch inside that window.
+	JWTSVIDRefreshAhead time.Duration
+
+	// EntryMatching controls how registration entries are matched against
+	// workload selectors. By default, all matching entries are returned.
+	EntryMatching cache.EntryMatching
+
+	// Reattestor, if set, allows the agent to reattest, on demand or every
+	// ReattestInterval if greater than zero.
+	Reattestor       svid.Reattestor
+	ReattestInterval time.Duration
+
 	// Clk is the clock the manager will use to get time
 	Clk clock.Clock
 }
@@ -54,6 +68,7 @@ func newManager(c *Config) *manager {
 	}
 
 	cache := cache.New(c.Log.WithField(telemetry.SubsystemName, telemetry.CacheManager), c.TrustDomain, c.Bundle, c.Metrics)
+	cache.SetEntryMatching(c.EntryMatching)
 
 	rotCfg := &svid.RotatorConfig{
 		Catalog:      c.Catalog,
@@ -66,6 +81,9 @@ func newManager(c *Config) *manager {
 		TrustDomain:  c.TrustDomain,
 		Interval:     c.RotationInterval,
 		Clk:          c.Clk,
+
+		Reattestor:       c.Reattestor,
+		ReattestInterval: c.ReattestInterval,
 	}
 	svidRotator, client := svid.NewRotator(rotCfg)
 
@@ -78,6 +78,10 @@ type Manager interface {
 
 	// GetBundle get latest cached bundle
 	GetBundle() *cache.Bundle
+
+	// Reattest performs node attestation again to get a new agent SVID, so
+	// the node selectors are resolved again by the server
+	Reattest(ctx context.Context) error
 }
 
 type manager struct {
@@ -174,6 +178,10 @@ func (m *manager) MatchingIdentities(selectors []*common.Selector) []cache.Ident
 	return m.cache.MatchingIdentities(selectors)
 }
 
+func (m *manager) Reattest(ctx context.Context) error {
+	return m.svid.Reattest(ctx)
+}
+
 func (m *manager) CountSVIDs() int {
 	return m.cache.CountSVIDs()
 }
@@ -186,16 +194,16 @@ func (m *manager) FetchWorkloadUpdate(selectors []*common.Selector) *cache.Workl
 func (m *manager) FetchJWTSVID(ctx context.Context, spiffeID spiffeid.ID, audience []string) (*client.JWTSVID, error) {
 	now := m.clk.Now()
 
-	cachedSVID, ok := m.cache.GetJWTSVID(spiffeID, audience)
-	if ok && !rotationutil.JWTSVIDExpiresSoon(cachedSVID, now) {
-		return cachedSVID, nil
-	}
-
 	entryID := m.getEntryID(spiffeID.String())
 	if entryID == "" {
 		return nil, errors.New("no entry found")
 	}
 
+	cachedSVID, ok := m.cache.GetJWTSVID(entryID, audience)
+	if ok && !m.jwtSVIDExpiresSoon(cachedSVID, now) {
+		return cachedSVID, nil
+	}
+
 	newSVID, err := m.client.NewJWTSVID(ctx, entryID, audience)
 	switch {
 	case err == nil:
@@ -208,10 +216,21 @@ func (m *manager) FetchJWTSVID(ctx context.Context, spiffeID spiffeid.ID, audien
 		return cachedSVID, nil
 	}
 
-	m.cache.SetJWTSVID(spiffeID, audience, newSVID)
+	m.cache.SetJWTSVID(entryID, audience, newSVID)
 	return newSVID, nil
 }
 
+// jwtSVIDExpiresSoon determines if a cached JWT-SVID needs to be refreshed.
+// When a refresh-ahead period is configured, the JWT-SVID is refreshed once
+// it is within that period of its expiration; otherwise it is refreshed
+// after half of its lifetime.
+func (m *manager) jwtSVIDExpiresSoon(svid *client.JWTSVID, now time.Time) bool {
+	if m.c.JWTSVIDRefreshAhead > 0 {
+		return rotationutil.JWTSVIDExpired(svid, now) || svid.ExpiresAt.Sub(now) <= m.c.JWTSVIDRefreshAhead
+	}
+	return rotationutil.JWTSVIDExpiresSoon(svid, now)
+}
+
 func (m *manager) getEntryID(spiffeID string) string {
 	for _, identity := range m.cache.Identities() {
 		if identity.Entry.SpiffeId == spiffeID {
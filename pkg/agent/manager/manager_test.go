@@ -193,16 +193,18 @@ func TestStoreKeyOnStartup(t *testing.T) {
 		Catalog:         cat,
 	}
 
-	_, err := km.GetKey(context.Background())
-	spiretest.RequireGRPCStatus(t, err, codes.NotFound, "keymanager(disk): private key not found")
+	keys, err := km.GetKeys(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, keys)
 
 	m := newManager(c)
 	require.Error(t, m.Initialize(context.Background()))
 
-	key, err := km.GetKey(context.Background())
+	keys, err = km.GetKeys(context.Background())
 	require.NoError(t, err)
+	require.Len(t, keys, 1)
 
-	if !reflect.DeepEqual(key, baseSVIDKey) {
+	if !reflect.DeepEqual(keys[0], baseSVIDKey) {
 		t.Fatal("stored key is different than provided")
 	}
 }
@@ -908,6 +910,92 @@ func TestFetchJWTSVID(t *testing.T) {
 	require.Nil(t, svid)
 }
 
+func TestFetchJWTSVIDWithRefreshAhead(t *testing.T) {
+	dir := spiretest.TempDir(t)
+
+	clk := clock.NewMock(t)
+	fetchCount := 0
+	fetchResp := &svidv1.NewJWTSVIDResponse{}
+	api := newMockAPI(t, &mockAPIConfig{
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		newJWTSVID: func(*mockAPI, *svidv1.NewJWTSVIDRequest) (*svidv1.NewJWTSVIDResponse, error) {
+			fetchCount++
+			return fetchResp, nil
+		},
+		clk:     clk,
+		svidTTL: 200,
+	})
+
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(fakeagentkeymanager.New(t, dir))
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+
+	c := &Config{
+		ServerAddr:          api.addr,
+		SVID:                baseSVID,
+		SVIDKey:             baseSVIDKey,
+		Log:                 testLogger,
+		TrustDomain:         trustDomain,
+		SVIDCachePath:       path.Join(dir, "svid.der"),
+		BundleCachePath:     path.Join(dir, "bundle.der"),
+		Bundle:              api.bundle,
+		Metrics:             &telemetry.Blackhole{},
+		Catalog:             cat,
+		Clk:                 clk,
+		JWTSVIDRefreshAhead: 10 * time.Second,
+	}
+
+	m := newManager(c)
+	require.NoError(t, m.Initialize(context.Background()))
+
+	spiffeID := spiffeid.RequireFromString("spiffe://example.org/blog")
+	audience := []string{"foo"}
+
+	now := clk.Now()
+	fetchResp.Svid = &types.JWTSVID{
+		Token:     "A",
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Minute).Unix(),
+	}
+	svid, err := m.FetchJWTSVID(context.Background(), spiffeID, audience)
+	require.NoError(t, err)
+	require.Equal(t, "A", svid.Token)
+	require.Equal(t, 1, fetchCount)
+
+	// past half of the lifetime but outside of the refresh-ahead period, the
+	// cached JWT is returned without calling the server
+	clk.Add(40 * time.Second)
+	svid, err = m.FetchJWTSVID(context.Background(), spiffeID, audience)
+	require.NoError(t, err)
+	require.Equal(t, "A", svid.Token)
+	require.Equal(t, 1, fetchCount)
+
+	// within the refresh-ahead period the JWT is refreshed
+	clk.Add(10 * time.Second)
+	now = clk.Now()
+	fetchResp.Svid = &types.JWTSVID{
+		Token:     "B",
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Minute).Unix(),
+	}
+	svid, err = m.FetchJWTSVID(context.Background(), spiffeID, audience)
+	require.NoError(t, err)
+	require.Equal(t, "B", svid.Token)
+	require.Equal(t, 2, fetchCount)
+
+	// a different audience is not served from the cache
+	svid, err = m.FetchJWTSVID(context.Background(), spiffeID, []string{"bar"})
+	require.NoError(t, err)
+	require.Equal(t, "B", svid.Token)
+	require.Equal(t, 3, fetchCount)
+}
+
 func makeGetAuthorizedEntriesResponse(t *testing.T, respKeys ...string) *entryv1.GetAuthorizedEntriesResponse {
 	var entries []*types.Entry
 	for _, respKey := range respKeys {
@@ -7,13 +7,14 @@ import (
 
 // KeyManager provides a signing key for the agent
 type KeyManager interface {
-	// GenerateKey generates a temporary key. It will not be the key returned
-	// by GetKey until after SetKey has been called.
-	GenerateKey(ctx context.Context) (crypto.Signer, error)
+	// GenerateKey generates a new key. The current key, if any, is left
+	// usable until the new key has been set with SetKey.
+	GenerateKey(ctx context.Context, currentKey crypto.Signer) (crypto.Signer, error)
 
-	// GetKey returns a Key previously set with SetKey.
-	GetKey(ctx context.Context) (crypto.Signer, error)
+	// GetKeys returns the keys that may be backing the agent SVID. The agent
+	// uses the one that matches the SVID it has persisted.
+	GetKeys(ctx context.Context) ([]crypto.Signer, error)
 
-	// SetKey sets the key that is returned by GetKey.
+	// SetKey sets the key backing the agent SVID.
 	SetKey(ctx context.Context, key crypto.Signer) error
 }
@@ -0,0 +1,477 @@
+package tpm
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/google/go-tpm-tools/tpm2tools"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	keymanagerv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/keymanager/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/diskutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pluginName = "tpm"
+
+	keysFileName = "keys.tpm.json"
+
+	// maxPCR is the highest PCR index guaranteed to be present by the PC
+	// client platform specification.
+	maxPCR = 23
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		keymanagerv1.KeyManagerPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+type Config struct {
+	// Directory is where the TPM-wrapped key blobs are persisted. The private
+	// blob is encrypted by the TPM and cannot be used anywhere else.
+	Directory string `hcl:"directory" json:"directory"`
+
+	// DevicePath is the path to the TPM device. Defaults to the kernel
+	// resource manager (/dev/tpmrm0). Ignored on Windows.
+	DevicePath string `hcl:"device_path" json:"device_path"`
+
+	// PCRs, if set, binds generated keys to the current values of these
+	// SHA-256 bank PCRs. A bound key cannot be used once the PCR values
+	// change.
+	PCRs []int `hcl:"pcrs" json:"pcrs"`
+}
+
+type Plugin struct {
+	keymanagerv1.UnsafeKeyManagerServer
+	configv1.UnsafeConfigServer
+
+	log hclog.Logger
+
+	mtx  sync.Mutex
+	rw   io.ReadWriteCloser
+	srk  tpmutil.Handle
+	dir  string
+	pcrs []int
+
+	// keys holds the persisted keys, indexed by key ID.
+	keys map[string]*tpmKey
+
+	hooks struct {
+		openTPM func(path string) (io.ReadWriteCloser, error)
+	}
+}
+
+func New() *Plugin {
+	p := &Plugin{}
+	p.hooks.openTPM = openTPM
+	return p
+}
+
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) GenerateKey(ctx context.Context, req *keymanagerv1.GenerateKeyRequest) (*keymanagerv1.GenerateKeyResponse, error) {
+	if req.KeyId == "" {
+		return nil, status.Error(codes.InvalidArgument, "key id is required")
+	}
+	curveID, ok := curves[req.KeyType]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported key type: %s", req.KeyType)
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.rw == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+
+	template := tpm2.Public{
+		Type:       tpm2.AlgECC,
+		NameAlg:    tpm2.AlgSHA256,
+		Attributes: tpm2.FlagSign | tpm2.FlagFixedTPM | tpm2.FlagFixedParent | tpm2.FlagSensitiveDataOrigin,
+		ECCParameters: &tpm2.ECCParams{
+			CurveID: curveID,
+		},
+	}
+	if len(p.pcrs) > 0 {
+		policy, err := p.pcrPolicyDigest(p.pcrs)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "unable to compute PCR policy: %v", err)
+		}
+		template.AuthPolicy = policy
+	} else {
+		template.Attributes |= tpm2.FlagUserWithAuth
+	}
+
+	private, public, _, _, _, err := tpm2.CreateKey(p.rw, p.srk, tpm2.PCRSelection{}, "", "", template)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to create key: %v", err)
+	}
+
+	key, err := newKey(req.KeyId, public, private, p.pcrs)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to parse created key: %v", err)
+	}
+
+	// Persist the new set of keys before using it, so that a key is never
+	// handed out unless it survives a restart.
+	keys := make(map[string]*tpmKey, len(p.keys)+1)
+	for id, existing := range p.keys {
+		keys[id] = existing
+	}
+	keys[key.id] = key
+	if err := p.storeKeys(keys); err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to persist key: %v", err)
+	}
+	p.keys = keys
+
+	return &keymanagerv1.GenerateKeyResponse{
+		PublicKey: key.publicKeyPB(),
+	}, nil
+}
+
+func (p *Plugin) GetPublicKey(ctx context.Context, req *keymanagerv1.GetPublicKeyRequest) (*keymanagerv1.GetPublicKeyResponse, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	key, ok := p.keys[req.KeyId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no such key %q", req.KeyId)
+	}
+
+	return &keymanagerv1.GetPublicKeyResponse{
+		PublicKey: key.publicKeyPB(),
+	}, nil
+}
+
+func (p *Plugin) GetPublicKeys(ctx context.Context, req *keymanagerv1.GetPublicKeysRequest) (*keymanagerv1.GetPublicKeysResponse, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	resp := &keymanagerv1.GetPublicKeysResponse{}
+	for _, key := range p.keys {
+		resp.PublicKeys = append(resp.PublicKeys, key.publicKeyPB())
+	}
+	sort.Slice(resp.PublicKeys, func(i, j int) bool {
+		return resp.PublicKeys[i].Id < resp.PublicKeys[j].Id
+	})
+	return resp, nil
+}
+
+func (p *Plugin) SignData(ctx context.Context, req *keymanagerv1.SignDataRequest) (*keymanagerv1.SignDataResponse, error) {
+	var hashAlgorithm keymanagerv1.HashAlgorithm
+	switch opts := req.SignerOpts.(type) {
+	case *keymanagerv1.SignDataRequest_HashAlgorithm:
+		hashAlgorithm = opts.HashAlgorithm
+	case *keymanagerv1.SignDataRequest_PssOptions:
+		return nil, status.Error(codes.InvalidArgument, "PSS options are not supported with ECDSA keys")
+	default:
+		return nil, status.Error(codes.InvalidArgument, "signer opts are required")
+	}
+	hashAlg, ok := hashAlgorithms[hashAlgorithm]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported hash algorithm: %s", hashAlgorithm)
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.rw == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+
+	key, ok := p.keys[req.KeyId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no such key %q", req.KeyId)
+	}
+
+	signature, err := p.sign(key, req.Data, hashAlg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to sign data: %v", err)
+	}
+
+	return &keymanagerv1.SignDataResponse{
+		Signature:      signature,
+		KeyFingerprint: key.fingerprint,
+	}, nil
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	config := new(Config)
+	if err := hcl.Decode(config, req.HclConfiguration); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to decode configuration: %v", err)
+	}
+
+	if config.Directory == "" {
+		return nil, status.Error(codes.InvalidArgument, "directory is required")
+	}
+	if config.DevicePath == "" {
+		config.DevicePath = defaultDevicePath
+	}
+	for _, pcr := range config.PCRs {
+		if pcr < 0 || pcr > maxPCR {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid PCR %d: must be between 0 and %d", pcr, maxPCR)
+		}
+	}
+
+	if err := os.MkdirAll(config.Directory, 0755); err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to create directory: %v", err)
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.closeTPM()
+
+	rw, err := p.hooks.openTPM(config.DevicePath)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to open TPM: %v", err)
+	}
+
+	// The storage root key is derived from the owner hierarchy seed, so it is
+	// the same every time it is created and can unwrap keys created before a
+	// restart.
+	srk, _, err := tpm2.CreatePrimary(rw, tpm2.HandleOwner, tpm2.PCRSelection{}, "", "", tpm2tools.SRKTemplateECC())
+	if err != nil {
+		rw.Close()
+		return nil, status.Errorf(codes.Internal, "unable to create storage root key: %v", err)
+	}
+
+	p.rw = rw
+	p.srk = srk
+	p.dir = config.Directory
+	p.pcrs = config.PCRs
+
+	keys, err := p.loadKeys()
+	if err != nil {
+		// Starting without keys makes the agent generate a new key and
+		// attest again, which is the best that can be done when the keys
+		// cannot be read.
+		p.log.Warn("Persisted keys could not be loaded; new keys will be generated", "error", err)
+		keys = make(map[string]*tpmKey)
+	}
+	p.keys = keys
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+// loadKeys loads the persisted keys, dropping the ones the TPM can no longer
+// sign with (e.g. the TPM was cleared or the bound PCRs changed).
+func (p *Plugin) loadKeys() (map[string]*tpmKey, error) {
+	keys := make(map[string]*tpmKey)
+
+	data, err := ioutil.ReadFile(filepath.Join(p.dir, keysFileName))
+	switch {
+	case os.IsNotExist(err):
+		return keys, nil
+	case err != nil:
+		return nil, err
+	}
+
+	stored := make(map[string]*tpmKey)
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	for id, s := range stored {
+		key, err := newKey(id, s.Public, s.Private, s.PCRs)
+		if err == nil {
+			digest := sha256.Sum256([]byte(key.fingerprint))
+			_, err = p.sign(key, digest[:], tpm2.AlgSHA256)
+		}
+		if err != nil {
+			p.log.Warn("Persisted key is not usable; a new key will be generated", "id", id, "error", err)
+			continue
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+func (p *Plugin) storeKeys(keys map[string]*tpmKey) error {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	return diskutil.AtomicWriteFile(filepath.Join(p.dir, keysFileName), data, 0600)
+}
+
+func (p *Plugin) sign(key *tpmKey, digest []byte, hashAlg tpm2.Algorithm) ([]byte, error) {
+	handle, _, err := tpm2.Load(p.rw, p.srk, "", key.Public, key.Private)
+	if err != nil {
+		return nil, err
+	}
+	defer p.flush(handle)
+
+	scheme := &tpm2.SigScheme{Alg: tpm2.AlgECDSA, Hash: hashAlg}
+
+	var sig *tpm2.Signature
+	if len(key.PCRs) > 0 {
+		session, err := p.startPCRSession(tpm2.SessionPolicy, key.PCRs)
+		if err != nil {
+			return nil, err
+		}
+		defer p.flush(session)
+		sig, err = tpm2.SignWithSession(p.rw, session, handle, "", digest, nil, scheme)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		sig, err = tpm2.Sign(p.rw, handle, "", digest, nil, scheme)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if sig.ECC == nil {
+		return nil, errors.New("TPM returned a non-ECDSA signature")
+	}
+	return asn1.Marshal(struct {
+		R, S *big.Int
+	}{sig.ECC.R, sig.ECC.S})
+}
+
+// pcrPolicyDigest returns the policy digest that authorizes use of a key
+// while the given PCRs hold their current values.
+func (p *Plugin) pcrPolicyDigest(pcrs []int) ([]byte, error) {
+	session, err := p.startPCRSession(tpm2.SessionTrial, pcrs)
+	if err != nil {
+		return nil, err
+	}
+	defer p.flush(session)
+	return tpm2.PolicyGetDigest(p.rw, session)
+}
+
+func (p *Plugin) startPCRSession(sessionType tpm2.SessionType, pcrs []int) (tpmutil.Handle, error) {
+	nonce := make([]byte, sha256.Size)
+	if _, err := rand.Read(nonce); err != nil {
+		return 0, err
+	}
+	session, _, err := tpm2.StartAuthSession(p.rw, tpm2.HandleNull, tpm2.HandleNull, nonce, nil, sessionType, tpm2.AlgNull, tpm2.AlgSHA256)
+	if err != nil {
+		return 0, err
+	}
+	if err := tpm2.PolicyPCR(p.rw, session, nil, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: pcrs}); err != nil {
+		p.flush(session)
+		return 0, err
+	}
+	return session, nil
+}
+
+func (p *Plugin) flush(handle tpmutil.Handle) {
+	if err := tpm2.FlushContext(p.rw, handle); err != nil {
+		p.log.Warn("Failed to flush TPM handle", "handle", handle, "error", err)
+	}
+}
+
+func (p *Plugin) closeTPM() {
+	if p.rw == nil {
+		return
+	}
+	p.flush(p.srk)
+	if err := p.rw.Close(); err != nil {
+		p.log.Warn("Failed to close TPM", "error", err)
+	}
+	p.rw = nil
+}
+
+// tpmKey is a key created by the TPM under the storage root key. The JSON
+// encoding is what gets persisted.
+type tpmKey struct {
+	Public  []byte `json:"public"`
+	Private []byte `json:"private"`
+	PCRs    []int  `json:"pcrs,omitempty"`
+
+	id          string
+	keyType     keymanagerv1.KeyType
+	fingerprint string
+	pkixData    []byte
+}
+
+func newKey(id string, public, private []byte, pcrs []int) (*tpmKey, error) {
+	pub, err := tpm2.DecodePublic(public)
+	if err != nil {
+		return nil, err
+	}
+	if pub.ECCParameters == nil {
+		return nil, errors.New("not an ECC key")
+	}
+	keyType, err := keyTypeFromCurve(pub.ECCParameters.CurveID)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := pub.Key()
+	if err != nil {
+		return nil, err
+	}
+	pkixData, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(public)
+	return &tpmKey{
+		Public:      public,
+		Private:     private,
+		PCRs:        pcrs,
+		id:          id,
+		keyType:     keyType,
+		fingerprint: hex.EncodeToString(sum[:]),
+		pkixData:    pkixData,
+	}, nil
+}
+
+func (k *tpmKey) publicKeyPB() *keymanagerv1.PublicKey {
+	return &keymanagerv1.PublicKey{
+		Id:          k.id,
+		Type:        k.keyType,
+		PkixData:    k.pkixData,
+		Fingerprint: k.fingerprint,
+	}
+}
+
+var curves = map[keymanagerv1.KeyType]tpm2.EllipticCurve{
+	keymanagerv1.KeyType_EC_P256: tpm2.CurveNISTP256,
+	keymanagerv1.KeyType_EC_P384: tpm2.CurveNISTP384,
+}
+
+func keyTypeFromCurve(curveID tpm2.EllipticCurve) (keymanagerv1.KeyType, error) {
+	for keyType, curve := range curves {
+		if curve == curveID {
+			return keyType, nil
+		}
+	}
+	return keymanagerv1.KeyType_UNSPECIFIED_KEY_TYPE, fmt.Errorf("unsupported curve %v", curveID)
+}
+
+var hashAlgorithms = map[keymanagerv1.HashAlgorithm]tpm2.Algorithm{
+	keymanagerv1.HashAlgorithm_SHA256: tpm2.AlgSHA256,
+	keymanagerv1.HashAlgorithm_SHA384: tpm2.AlgSHA384,
+	keymanagerv1.HashAlgorithm_SHA512: tpm2.AlgSHA512,
+}
@@ -0,0 +1,15 @@
+// +build !windows
+
+package tpm
+
+import (
+	"io"
+
+	"github.com/google/go-tpm/tpm2"
+)
+
+const defaultDevicePath = "/dev/tpmrm0"
+
+func openTPM(path string) (io.ReadWriteCloser, error) {
+	return tpm2.OpenTPM(path)
+}
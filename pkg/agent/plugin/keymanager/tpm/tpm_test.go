@@ -0,0 +1,303 @@
+// +build tpmsimulator
+
+// The tests run against the go-tpm-tools simulator, which links OpenSSL 1.1
+// through cgo. Run them with: go test -tags tpmsimulator
+
+package tpm
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/google/go-tpm-tools/simulator"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	keymanagerv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/keymanager/v1"
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var ctx = context.Background()
+
+// debugPCR is resettable and unused by firmware, which makes it convenient
+// for testing PCR policies.
+const debugPCR = 16
+
+func TestGenerateKeyAndSign(t *testing.T) {
+	sim := startSimulator(t)
+	km := loadPlugin(t, sim, fmt.Sprintf("directory = %q", spiretest.TempDir(t)))
+
+	key, err := km.GenerateKey(ctx, nil)
+	require.NoError(t, err)
+	publicKey, ok := key.Public().(*ecdsa.PublicKey)
+	require.True(t, ok, "expected an ECDSA public key; got %T", key.Public())
+
+	digest256 := sha256.Sum256([]byte("DATA"))
+	signature, err := key.Sign(rand.Reader, digest256[:], crypto.SHA256)
+	require.NoError(t, err)
+	assert.True(t, ecdsa.VerifyASN1(publicKey, digest256[:], signature), "signature is not valid")
+
+	digest384 := sha512.Sum384([]byte("DATA"))
+	signature, err = key.Sign(rand.Reader, digest384[:], crypto.SHA384)
+	require.NoError(t, err)
+	assert.True(t, ecdsa.VerifyASN1(publicKey, digest384[:], signature), "signature is not valid")
+
+	_, err = key.Sign(rand.Reader, digest256[:], crypto.SHA1)
+	spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, "unsupported hash algorithm")
+}
+
+func TestKeySurvivesRestart(t *testing.T) {
+	sim := startSimulator(t)
+	config := fmt.Sprintf("directory = %q", spiretest.TempDir(t))
+	km := loadPlugin(t, sim, config)
+
+	key, err := km.GenerateKey(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, km.SetKey(ctx, key))
+
+	keys, err := km.GetKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, key.Public(), keys[0].Public())
+
+	// Reboot the host and start a new agent
+	require.NoError(t, sim.Reset())
+	km = loadPlugin(t, sim, config)
+
+	keys, err = km.GetKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, key.Public(), keys[0].Public())
+	assertCanSign(t, keys[0])
+}
+
+func TestGenerateKeyKeepsCurrentKey(t *testing.T) {
+	sim := startSimulator(t)
+	km := loadPlugin(t, sim, fmt.Sprintf("directory = %q", spiretest.TempDir(t)))
+
+	oldKey, err := km.GenerateKey(ctx, nil)
+	require.NoError(t, err)
+	currentKey, err := km.GenerateKey(ctx, oldKey)
+	require.NoError(t, err)
+
+	// The current key can still sign while the rotation settles
+	assertCanSign(t, oldKey)
+	assertCanSign(t, currentKey)
+
+	// The next key replaces the one that is no longer current
+	newKey, err := km.GenerateKey(ctx, currentKey)
+	require.NoError(t, err)
+	assertCanSign(t, currentKey)
+	assertCanSign(t, newKey)
+	_, err = oldKey.Sign(rand.Reader, make([]byte, sha256.Size), crypto.SHA256)
+	spiretest.RequireGRPCStatusContains(t, err, codes.Internal, "does not match key")
+
+	keys, err := km.GetKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.ElementsMatch(t, []crypto.PublicKey{currentKey.Public(), newKey.Public()},
+		[]crypto.PublicKey{keys[0].Public(), keys[1].Public()})
+}
+
+func TestPCRPolicy(t *testing.T) {
+	sim := startSimulator(t)
+	config := fmt.Sprintf("directory = %q\npcrs = [%d]", spiretest.TempDir(t), debugPCR)
+	km := loadPlugin(t, sim, config)
+
+	key, err := km.GenerateKey(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, km.SetKey(ctx, key))
+	assertCanSign(t, key)
+
+	// The key can no longer be used once the measured state changes
+	require.NoError(t, tpm2.PCRExtend(sim, tpmutil.Handle(debugPCR), tpm2.AlgSHA256, make([]byte, sha256.Size), ""))
+	_, err = key.Sign(rand.Reader, make([]byte, sha256.Size), crypto.SHA256)
+	spiretest.RequireGRPCStatusContains(t, err, codes.Internal, "unable to sign data")
+
+	// A restarted agent discards the key so that it attests again with a
+	// new one
+	log, hook := test.NewNullLogger()
+	km = loadPlugin(t, sim, config, plugintest.Log(log))
+	keys, err := km.GetKeys(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+	assertHasWarning(t, hook, "Persisted key is not usable; a new key will be generated")
+
+	// Rebooting restores the PCR to the value the key is bound to
+	require.NoError(t, sim.Reset())
+	km = loadPlugin(t, sim, config)
+	keys, err = km.GetKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, key.Public(), keys[0].Public())
+	assertCanSign(t, keys[0])
+}
+
+func TestKeyNotUsableAfterTPMCleared(t *testing.T) {
+	sim := startSimulator(t)
+	config := fmt.Sprintf("directory = %q", spiretest.TempDir(t))
+	km := loadPlugin(t, sim, config)
+
+	key, err := km.GenerateKey(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, km.SetKey(ctx, key))
+
+	// Wiping the TPM changes the owner seed, so the SRK can no longer load
+	// the persisted key
+	require.NoError(t, sim.ManufactureReset())
+	log, hook := test.NewNullLogger()
+	km = loadPlugin(t, sim, config, plugintest.Log(log))
+	keys, err := km.GetKeys(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+	assertHasWarning(t, hook, "Persisted key is not usable; a new key will be generated")
+}
+
+func TestPluginInterface(t *testing.T) {
+	sim := startSimulator(t)
+	km := loadPlugin(t, sim, fmt.Sprintf("directory = %q", spiretest.TempDir(t)))
+	client := km.KeyManagerPluginClient
+
+	_, err := client.GetPublicKey(ctx, &keymanagerv1.GetPublicKeyRequest{KeyId: "foo"})
+	spiretest.RequireGRPCStatus(t, err, codes.NotFound, `no such key "foo"`)
+
+	_, err = client.GenerateKey(ctx, &keymanagerv1.GenerateKeyRequest{KeyType: keymanagerv1.KeyType_EC_P256})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "key id is required")
+
+	_, err = client.GenerateKey(ctx, &keymanagerv1.GenerateKeyRequest{KeyId: "foo", KeyType: keymanagerv1.KeyType_RSA_2048})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "unsupported key type: RSA_2048")
+
+	generated, err := client.GenerateKey(ctx, &keymanagerv1.GenerateKeyRequest{KeyId: "foo", KeyType: keymanagerv1.KeyType_EC_P384})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", generated.PublicKey.Id)
+	assert.Equal(t, keymanagerv1.KeyType_EC_P384, generated.PublicKey.Type)
+	assert.NotEmpty(t, generated.PublicKey.Fingerprint)
+
+	fetched, err := client.GetPublicKey(ctx, &keymanagerv1.GetPublicKeyRequest{KeyId: "foo"})
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, generated.PublicKey, fetched.PublicKey)
+
+	digest := sha256.Sum256([]byte("DATA"))
+	signed, err := client.SignData(ctx, &keymanagerv1.SignDataRequest{
+		KeyId:      "foo",
+		Data:       digest[:],
+		SignerOpts: &keymanagerv1.SignDataRequest_HashAlgorithm{HashAlgorithm: keymanagerv1.HashAlgorithm_SHA256},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, generated.PublicKey.Fingerprint, signed.KeyFingerprint)
+	publicKey, err := x509.ParsePKIXPublicKey(generated.PublicKey.PkixData)
+	require.NoError(t, err)
+	assert.True(t, ecdsa.VerifyASN1(publicKey.(*ecdsa.PublicKey), digest[:], signed.Signature), "signature is not valid")
+
+	_, err = client.SignData(ctx, &keymanagerv1.SignDataRequest{
+		KeyId: "foo",
+		Data:  digest[:],
+		SignerOpts: &keymanagerv1.SignDataRequest_PssOptions{PssOptions: &keymanagerv1.SignDataRequest_PSSOptions{
+			SaltLength:    32,
+			HashAlgorithm: keymanagerv1.HashAlgorithm_SHA256,
+		}},
+	})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "PSS options are not supported with ECDSA keys")
+
+	_, err = client.SignData(ctx, &keymanagerv1.SignDataRequest{KeyId: "foo", Data: digest[:]})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "signer opts are required")
+}
+
+func TestConfigure(t *testing.T) {
+	sim := startSimulator(t)
+
+	for _, tt := range []struct {
+		name      string
+		config    string
+		expectErr string
+	}{
+		{
+			name:      "malformed configuration",
+			config:    "{{",
+			expectErr: "unable to decode configuration",
+		},
+		{
+			name:      "missing directory",
+			config:    "",
+			expectErr: "directory is required",
+		},
+		{
+			name:      "invalid PCR",
+			config:    fmt.Sprintf("directory = %q\npcrs = [24]", spiretest.TempDir(t)),
+			expectErr: "invalid PCR 24: must be between 0 and 23",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			loadPlugin(t, sim, tt.config, plugintest.CaptureConfigureError(&err))
+			spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, tt.expectErr)
+		})
+	}
+}
+
+func TestNotConfigured(t *testing.T) {
+	km := new(keymanager.V1)
+	plugintest.Load(t, BuiltIn(), km)
+
+	_, err := km.GenerateKey(ctx, nil)
+	spiretest.RequireGRPCStatusContains(t, err, codes.FailedPrecondition, "not configured")
+}
+
+func startSimulator(t *testing.T) *simulator.Simulator {
+	sim, err := simulator.Get()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, sim.Close())
+	})
+	return sim
+}
+
+func loadPlugin(t *testing.T, sim *simulator.Simulator, config string, options ...plugintest.Option) *keymanager.V1 {
+	p := New()
+	p.hooks.openTPM = func(path string) (io.ReadWriteCloser, error) {
+		assert.Equal(t, defaultDevicePath, path)
+		// The simulator outlives plugin instances to simulate restarts
+		return nopCloser{ReadWriter: sim}, nil
+	}
+
+	km := new(keymanager.V1)
+	plugintest.Load(t, builtin(p), km, append([]plugintest.Option{plugintest.Configure(config)}, options...)...)
+	return km
+}
+
+func assertCanSign(t *testing.T, key crypto.Signer) {
+	digest := sha256.Sum256([]byte("DATA"))
+	signature, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+	assert.True(t, ecdsa.VerifyASN1(key.Public().(*ecdsa.PublicKey), digest[:], signature), "signature is not valid")
+}
+
+func assertHasWarning(t *testing.T, hook *test.Hook, message string) {
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == logrus.WarnLevel && entry.Message == message {
+			return
+		}
+	}
+	assert.Fail(t, "missing warning", "expected warning %q", message)
+}
+
+type nopCloser struct {
+	io.ReadWriter
+}
+
+func (nopCloser) Close() error { return nil }
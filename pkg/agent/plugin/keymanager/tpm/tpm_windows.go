@@ -0,0 +1,15 @@
+package tpm
+
+import (
+	"io"
+
+	"github.com/google/go-tpm/tpm2"
+)
+
+// The TPM is reached through the TPM Base Services on Windows, so there is
+// no device path.
+const defaultDevicePath = ""
+
+func openTPM(string) (io.ReadWriteCloser, error) {
+	return tpm2.OpenTPM()
+}
@@ -16,7 +16,7 @@ type V0 struct {
 	keymanagerv0.KeyManagerPluginClient
 }
 
-func (v0 *V0) GenerateKey(ctx context.Context) (crypto.Signer, error) {
+func (v0 *V0) GenerateKey(ctx context.Context, _ crypto.Signer) (crypto.Signer, error) {
 	resp, err := v0.KeyManagerPluginClient.GenerateKeyPair(ctx, &keymanagerv0.GenerateKeyPairRequest{})
 	if err != nil {
 		return nil, v0.WrapErr(err)
@@ -34,14 +34,14 @@ func (v0 *V0) GenerateKey(ctx context.Context) (crypto.Signer, error) {
 	return ecKey, nil
 }
 
-func (v0 *V0) GetKey(ctx context.Context) (crypto.Signer, error) {
+func (v0 *V0) GetKeys(ctx context.Context) ([]crypto.Signer, error) {
 	resp, err := v0.KeyManagerPluginClient.FetchPrivateKey(ctx, &keymanagerv0.FetchPrivateKeyRequest{})
 	if err != nil {
 		return nil, v0.WrapErr(err)
 	}
 
 	if resp.PrivateKey == nil {
-		return nil, v0.Error(codes.NotFound, "private key not found")
+		return nil, nil
 	}
 
 	ecKey, err := x509.ParseECPrivateKey(resp.PrivateKey)
@@ -49,7 +49,7 @@ func (v0 *V0) GetKey(ctx context.Context) (crypto.Signer, error) {
 		return nil, v0.WrapErr(err)
 	}
 
-	return ecKey, nil
+	return []crypto.Signer{ecKey}, nil
 }
 
 func (v0 *V0) SetKey(ctx context.Context, key crypto.Signer) error {
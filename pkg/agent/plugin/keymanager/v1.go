@@ -0,0 +1,138 @@
+package keymanager
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"io"
+	"time"
+
+	keymanagerv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/keymanager/v1"
+	"github.com/spiffe/spire/pkg/common/plugin"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// The agent SVID key alternates between these two IDs so that generating
+	// a new key never overwrites the key currently in use.
+	svidKeyA = "agent-svid-A"
+	svidKeyB = "agent-svid-B"
+
+	// signTimeout bounds how long signing with a plugin-managed key can
+	// take, since crypto.Signer does not carry a context.
+	signTimeout = 30 * time.Second
+)
+
+type V1 struct {
+	plugin.Facade
+	keymanagerv1.KeyManagerPluginClient
+}
+
+func (v1 *V1) GenerateKey(ctx context.Context, currentKey crypto.Signer) (crypto.Signer, error) {
+	keyID := svidKeyA
+	if current, ok := currentKey.(*v1Key); ok && current.id == svidKeyA {
+		keyID = svidKeyB
+	}
+
+	resp, err := v1.KeyManagerPluginClient.GenerateKey(ctx, &keymanagerv1.GenerateKeyRequest{
+		KeyId:   keyID,
+		KeyType: keymanagerv1.KeyType_EC_P256,
+	})
+	if err != nil {
+		return nil, v1.WrapErr(err)
+	}
+
+	if resp.PublicKey == nil {
+		return nil, v1.Error(codes.Internal, "plugin response missing public key")
+	}
+
+	return v1.makeKey(keyID, resp.PublicKey)
+}
+
+func (v1 *V1) GetKeys(ctx context.Context) ([]crypto.Signer, error) {
+	resp, err := v1.KeyManagerPluginClient.GetPublicKeys(ctx, &keymanagerv1.GetPublicKeysRequest{})
+	if err != nil {
+		return nil, v1.WrapErr(err)
+	}
+
+	var keys []crypto.Signer
+	for _, pb := range resp.PublicKeys {
+		if pb.Id != svidKeyA && pb.Id != svidKeyB {
+			continue
+		}
+		key, err := v1.makeKey(pb.Id, pb)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (v1 *V1) SetKey(ctx context.Context, key crypto.Signer) error {
+	// The plugin persists keys when they are generated, so there is nothing
+	// to store. The key must still be one the plugin manages.
+	if v1Key, ok := key.(*v1Key); !ok || v1Key.v1 != v1 {
+		return v1.Error(codes.Internal, "v1 key manager only supports keys it generated")
+	}
+	return nil
+}
+
+func (v1 *V1) makeKey(keyID string, pb *keymanagerv1.PublicKey) (crypto.Signer, error) {
+	switch {
+	case pb.Id != keyID:
+		return nil, v1.Errorf(codes.Internal, "plugin response has wrong key id %q; expected %q", pb.Id, keyID)
+	case len(pb.PkixData) == 0:
+		return nil, v1.Errorf(codes.Internal, "plugin response missing public key PKIX data for key %q", pb.Id)
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(pb.PkixData)
+	if err != nil {
+		return nil, v1.Errorf(codes.Internal, "unable to parse public key PKIX data for key %q: %v", pb.Id, err)
+	}
+
+	return &v1Key{
+		v1:          v1,
+		id:          pb.Id,
+		fingerprint: pb.Fingerprint,
+		publicKey:   publicKey,
+	}, nil
+}
+
+type v1Key struct {
+	v1          *V1
+	id          string
+	fingerprint string
+	publicKey   crypto.PublicKey
+}
+
+func (k *v1Key) Public() crypto.PublicKey {
+	return k.publicKey
+}
+
+func (k *v1Key) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), signTimeout)
+	defer cancel()
+
+	// rand is purposefully ignored since it can't be communicated between
+	// the plugin boundary. The crypto.Signer interface implies this is ok
+	// when it says "possibly using entropy from rand".
+	resp, err := k.v1.KeyManagerPluginClient.SignData(ctx, &keymanagerv1.SignDataRequest{
+		KeyId: k.id,
+		Data:  digest,
+		SignerOpts: &keymanagerv1.SignDataRequest_HashAlgorithm{
+			// Hash algorithm constants are aligned.
+			HashAlgorithm: keymanagerv1.HashAlgorithm(opts.HashFunc()),
+		},
+	})
+	if err != nil {
+		return nil, k.v1.WrapErr(err)
+	}
+	if len(resp.Signature) == 0 {
+		return nil, k.v1.Error(codes.Internal, "key manager returned empty signature data")
+	}
+	if resp.KeyFingerprint != k.fingerprint {
+		return nil, k.v1.Errorf(codes.Internal, "fingerprint %q on signature does not match key %q fingerprint %q", resp.KeyFingerprint, k.id, k.fingerprint)
+	}
+	return resp.Signature, nil
+}
@@ -0,0 +1,190 @@
+package keymanager_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"testing"
+
+	keymanagerv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/keymanager/v1"
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestV1GenerateKeyAlternatesKeyIDs(t *testing.T) {
+	fake := newFakePluginV1()
+	km := loadV1Plugin(t, fake)
+
+	first, err := km.GenerateKey(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"agent-svid-A"}, fake.generated)
+
+	second, err := km.GenerateKey(context.Background(), first)
+	require.NoError(t, err)
+	require.Equal(t, []string{"agent-svid-A", "agent-svid-B"}, fake.generated)
+
+	// Rotating again reuses the slot of the key that is no longer current.
+	_, err = km.GenerateKey(context.Background(), second)
+	require.NoError(t, err)
+	require.Equal(t, []string{"agent-svid-A", "agent-svid-B", "agent-svid-A"}, fake.generated)
+
+	// A key that did not come from the plugin is treated as no current key.
+	_, err = km.GenerateKey(context.Background(), testkey.MustEC256())
+	require.NoError(t, err)
+	require.Equal(t, "agent-svid-A", fake.generated[3])
+}
+
+func TestV1GenerateKeyFails(t *testing.T) {
+	fake := newFakePluginV1()
+	fake.err = status.Error(codes.Internal, "ohno")
+	km := loadV1Plugin(t, fake)
+
+	_, err := km.GenerateKey(context.Background(), nil)
+	spiretest.RequireGRPCStatus(t, err, codes.Internal, "keymanager(test): ohno")
+}
+
+func TestV1GetKeys(t *testing.T) {
+	fake := newFakePluginV1()
+	km := loadV1Plugin(t, fake)
+
+	keys, err := km.GetKeys(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	keyA, err := km.GenerateKey(context.Background(), nil)
+	require.NoError(t, err)
+	keyB, err := km.GenerateKey(context.Background(), keyA)
+	require.NoError(t, err)
+
+	// Keys the plugin holds for other purposes are not SVID keys.
+	fake.keys["other"] = testkey.MustEC256()
+
+	keys, err = km.GetKeys(context.Background())
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.ElementsMatch(t, []crypto.PublicKey{keyA.Public(), keyB.Public()}, []crypto.PublicKey{keys[0].Public(), keys[1].Public()})
+}
+
+func TestV1SetKey(t *testing.T) {
+	km := loadV1Plugin(t, newFakePluginV1())
+
+	key, err := km.GenerateKey(context.Background(), nil)
+	require.NoError(t, err)
+	require.NoError(t, km.SetKey(context.Background(), key))
+
+	err = km.SetKey(context.Background(), testkey.MustEC256())
+	spiretest.RequireGRPCStatus(t, err, codes.Internal, "keymanager(test): v1 key manager only supports keys it generated")
+}
+
+func TestV1Sign(t *testing.T) {
+	fake := newFakePluginV1()
+	km := loadV1Plugin(t, fake)
+
+	key, err := km.GenerateKey(context.Background(), nil)
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("DATA"))
+	signature, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+	require.True(t, ecdsa.VerifyASN1(key.Public().(*ecdsa.PublicKey), digest[:], signature))
+	require.Equal(t, keymanagerv1.HashAlgorithm_SHA256, fake.signedWith)
+
+	// The key was replaced after the public key was fetched.
+	fake.keys["agent-svid-A"] = testkey.MustEC256()
+	_, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	spiretest.RequireGRPCStatusContains(t, err, codes.Internal, `does not match key "agent-svid-A" fingerprint`)
+}
+
+func loadV1Plugin(t *testing.T, fake *fakePluginV1) keymanager.KeyManager {
+	km := new(keymanager.V1)
+	plugintest.Load(t, catalog.MakeBuiltIn("test", keymanagerv1.KeyManagerPluginServer(fake)), km)
+	return km
+}
+
+type fakePluginV1 struct {
+	keymanagerv1.UnimplementedKeyManagerServer
+
+	err        error
+	keys       map[string]*ecdsa.PrivateKey
+	generated  []string
+	signedWith keymanagerv1.HashAlgorithm
+}
+
+func newFakePluginV1() *fakePluginV1 {
+	return &fakePluginV1{
+		keys: make(map[string]*ecdsa.PrivateKey),
+	}
+}
+
+func (p *fakePluginV1) GenerateKey(ctx context.Context, req *keymanagerv1.GenerateKeyRequest) (*keymanagerv1.GenerateKeyResponse, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.keys[req.KeyId] = testkey.MustEC256()
+	p.generated = append(p.generated, req.KeyId)
+
+	publicKey, err := p.publicKey(req.KeyId)
+	if err != nil {
+		return nil, err
+	}
+	return &keymanagerv1.GenerateKeyResponse{PublicKey: publicKey}, nil
+}
+
+func (p *fakePluginV1) GetPublicKeys(ctx context.Context, req *keymanagerv1.GetPublicKeysRequest) (*keymanagerv1.GetPublicKeysResponse, error) {
+	resp := new(keymanagerv1.GetPublicKeysResponse)
+	for id := range p.keys {
+		publicKey, err := p.publicKey(id)
+		if err != nil {
+			return nil, err
+		}
+		resp.PublicKeys = append(resp.PublicKeys, publicKey)
+	}
+	return resp, nil
+}
+
+func (p *fakePluginV1) SignData(ctx context.Context, req *keymanagerv1.SignDataRequest) (*keymanagerv1.SignDataResponse, error) {
+	key, ok := p.keys[req.KeyId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no such key %q", req.KeyId)
+	}
+	p.signedWith = req.GetHashAlgorithm()
+
+	signature, err := key.Sign(rand.Reader, req.Data, crypto.Hash(req.GetHashAlgorithm()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &keymanagerv1.SignDataResponse{
+		Signature:      signature,
+		KeyFingerprint: fingerprint(key),
+	}, nil
+}
+
+func (p *fakePluginV1) publicKey(id string) (*keymanagerv1.PublicKey, error) {
+	key := p.keys[id]
+	pkixData, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &keymanagerv1.PublicKey{
+		Id:          id,
+		Type:        keymanagerv1.KeyType_EC_P256,
+		PkixData:    pkixData,
+		Fingerprint: fingerprint(key),
+	}, nil
+}
+
+func fingerprint(key *ecdsa.PrivateKey) string {
+	pkixData, _ := x509.MarshalPKIXPublicKey(key.Public())
+	sum := sha256.Sum256(pkixData)
+	return hex.EncodeToString(sum[:])
+}
@@ -3,11 +3,16 @@ package aws
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/hcl"
 	"github.com/spiffe/spire/pkg/common/catalog"
@@ -67,7 +72,12 @@ func (p *IIDAttestorPlugin) FetchAttestationData(stream nodeattestorv0.NodeAttes
 		return err
 	}
 
-	attestationData, err := fetchMetadata(c.EC2MetadataEndpoint)
+	client, err := newMetadataClient(c.EC2MetadataEndpoint)
+	if err != nil {
+		return err
+	}
+
+	attestationData, err := fetchMetadata(client)
 	if err != nil {
 		return err
 	}
@@ -77,15 +87,45 @@ func (p *IIDAttestorPlugin) FetchAttestationData(stream nodeattestorv0.NodeAttes
 		return caws.AttestationStepError("marshaling the attested data", err)
 	}
 
-	return stream.Send(&nodeattestorv0.FetchAttestationDataResponse{
+	if err := stream.Send(&nodeattestorv0.FetchAttestationDataResponse{
 		AttestationData: &common.AttestationData{
 			Type: caws.PluginName,
 			Data: respData,
 		},
+	}); err != nil {
+		return err
+	}
+
+	// The server only issues a challenge when it requires a nonce
+	req, err := stream.Recv()
+	switch {
+	case err == io.EOF, status.Code(err) == codes.Canceled:
+		return nil
+	case err != nil:
+		return err
+	}
+
+	challenge := new(caws.NonceChallenge)
+	if err := json.Unmarshal(req.Challenge, challenge); err != nil {
+		return caws.AttestationStepError("unmarshaling the nonce challenge", err)
+	}
+
+	response, err := signNonce(stream.Context(), client, challenge.Nonce)
+	if err != nil {
+		return caws.AttestationStepError("signing the nonce", err)
+	}
+
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		return caws.AttestationStepError("marshaling the nonce response", err)
+	}
+
+	return stream.Send(&nodeattestorv0.FetchAttestationDataResponse{
+		Response: responseBytes,
 	})
 }
 
-func fetchMetadata(endpoint string) (*caws.IIDAttestationData, error) {
+func newMetadataClient(endpoint string) (*ec2metadata.EC2Metadata, error) {
 	awsCfg := aws.NewConfig()
 	if endpoint != "" {
 		awsCfg.WithEndpoint(endpoint)
@@ -95,8 +135,29 @@ func fetchMetadata(endpoint string) (*caws.IIDAttestationData, error) {
 		return nil, err
 	}
 
-	client := ec2metadata.New(newSession)
+	return ec2metadata.New(newSession), nil
+}
+
+// signNonce signs an sts:GetCallerIdentity request carrying the nonce with
+// the credentials of the instance profile role, so the server can tell the
+// instance signed it.
+func signNonce(ctx context.Context, client *ec2metadata.EC2Metadata, nonce string) (*caws.NonceResponse, error) {
+	req, err := caws.NewGetCallerIdentityRequest(ctx, caws.STSEndpoint, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	signer := v4.NewSigner(ec2rolecreds.NewCredentialsWithClient(client))
+	if _, err := signer.Sign(req, strings.NewReader(caws.GetCallerIdentityBody), "sts", caws.STSRegion, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &caws.NonceResponse{
+		Headers: req.Header,
+	}, nil
+}
 
+func fetchMetadata(client *ec2metadata.EC2Metadata) (*caws.IIDAttestationData, error) {
 	doc, err := client.GetDynamicData(docPath)
 	if err != nil {
 		return nil, err
@@ -27,6 +27,8 @@ const (
 	staticToken                  = "It's just some data" //nolint: gosec // false positive
 	defaultIdentityDocumentPath  = "/latest/dynamic/instance-identity/document"
 	defaultIdentitySignaturePath = "/latest/dynamic/instance-identity/signature"
+	securityCredentialsPath      = "/latest/meta-data/iam/security-credentials/"
+	testRoleName                 = "test-role"
 )
 
 var (
@@ -73,6 +75,18 @@ func (s *Suite) SetupTest() {
 			// write sig resp
 			w.WriteHeader(s.status)
 			_, _ = w.Write([]byte(s.sigBody))
+		case securityCredentialsPath:
+			// instance profile role of the instance
+			_, _ = w.Write([]byte(testRoleName))
+		case securityCredentialsPath + testRoleName:
+			// credentials of the instance profile role
+			_, _ = w.Write([]byte(`{
+				"Code": "Success",
+				"AccessKeyId": "ASIAEXAMPLE",
+				"SecretAccessKey": "secret",
+				"Token": "token",
+				"Expiration": "2100-01-01T00:00:00Z"
+			}`))
 		default:
 			// unexpected path
 			w.WriteHeader(http.StatusForbidden)
@@ -133,6 +147,40 @@ func (s *Suite) TestSuccessfulIdentityProcessing() {
 	require.Equal(string(expectedBytes), string(resp.AttestationData.Data))
 }
 
+func (s *Suite) TestNonceChallenge() {
+	doc, sig := s.buildDefaultIIDDocAndSig()
+	s.docBody = string(doc)
+	s.sigBody = string(sig)
+	require := s.Require()
+
+	stream, err := s.p.FetchAttestationData(context.Background())
+	require.NoError(err)
+
+	resp, err := stream.Recv()
+	require.NoError(err)
+	require.NotNil(resp.AttestationData)
+
+	challenge, err := json.Marshal(aws.NonceChallenge{Nonce: "nonce"})
+	require.NoError(err)
+	require.NoError(stream.Send(&nodeattestorv0.FetchAttestationDataRequest{
+		Challenge: challenge,
+	}))
+
+	resp, err = stream.Recv()
+	require.NoError(err)
+	response := new(aws.NonceResponse)
+	require.NoError(json.Unmarshal(resp.Response, response))
+
+	// The nonce is signed with the credentials of the instance profile role
+	require.Equal("nonce", response.Headers.Get(aws.NonceHeader))
+	require.Equal("token", response.Headers.Get("X-Amz-Security-Token"))
+	authorization := response.Headers.Get("Authorization")
+	require.Contains(authorization, "Credential=ASIAEXAMPLE/")
+	require.Contains(authorization, "/us-east-1/sts/aws4_request")
+	require.Regexp(`SignedHeaders=[^,]*x-spire-aws-iid-nonce`, authorization)
+	require.NoError(stream.CloseSend())
+}
+
 func (s *Suite) TestConfigure() {
 	require := s.Require()
 
@@ -0,0 +1,317 @@
+package awsecs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	"github.com/spiffe/spire/pkg/agent/common/cgroups"
+	"github.com/spiffe/spire/pkg/agent/plugin/workloadattestor/docker/cgroup"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/proto/spire/common"
+	spi "github.com/spiffe/spire/proto/spire/common/plugin"
+	workloadattestorv0 "github.com/spiffe/spire/proto/spire/plugin/agent/workloadattestor/v0"
+	"github.com/zeebo/errs"
+)
+
+const (
+	pluginName            = "aws_ecs"
+	subselectorCluster    = "cluster"
+	subselectorService    = "service"
+	subselectorTaskDef    = "taskdef"
+	defaultECSAgentURI    = "http://localhost:51678"
+	serviceGroupPrefix    = "service:"
+	clusterResourcePrefix = "cluster/"
+	taskDefResourcePrefix = "task-definition/"
+)
+
+var ecsErr = errs.Class(pluginName)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName, workloadattestorv0.WorkloadAttestorPluginServer(p))
+}
+
+// Config configures the plugin.
+type Config struct {
+	AccessKeyID     string `hcl:"access_key_id"`
+	SecretAccessKey string `hcl:"secret_access_key"`
+	// ECSAgentURI is the base URI of the introspection API of the ECS
+	// container agent (default: "http://localhost:51678").
+	ECSAgentURI string `hcl:"ecs_agent_uri"`
+	// ContainerIDCGroupMatchers is a list of patterns used to discover container IDs from cgroup entries.
+	// See the documentation for cgroup.NewContainerIDFinder in the docker cgroup subpackage for more information.
+	ContainerIDCGroupMatchers []string `hcl:"container_id_cgroup_matchers"`
+}
+
+// Plugin is a workload attestor that generates selectors from the ECS task
+// that runs the workload container. The task is looked up through the ECS
+// container agent and then described with the ECS API.
+type Plugin struct {
+	workloadattestorv0.UnsafeWorkloadAttestorServer
+
+	log       hclog.Logger
+	fs        cgroups.FileSystem
+	newClient newClientCallback
+
+	mtx               sync.RWMutex
+	config            *Config
+	containerIDFinder cgroup.ContainerIDFinder
+	agent             ECSAgent
+	clients           map[string]ECSClient
+}
+
+func New() *Plugin {
+	return &Plugin{
+		fs:        cgroups.OSFileSystem{},
+		newClient: newClient,
+	}
+}
+
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) Attest(ctx context.Context, req *workloadattestorv0.AttestRequest) (*workloadattestorv0.AttestResponse, error) {
+	p.mtx.RLock()
+	finder, agent := p.containerIDFinder, p.agent
+	p.mtx.RUnlock()
+	if agent == nil {
+		return nil, ecsErr.New("not configured")
+	}
+
+	cgroupList, err := cgroups.GetCgroups(req.Pid, p.fs)
+	if err != nil {
+		return nil, ecsErr.Wrap(err)
+	}
+
+	containerID, err := getContainerIDFromCGroups(finder, cgroupList)
+	switch {
+	case err != nil:
+		return nil, err
+	case containerID == "":
+		// Not an ECS workload. Nothing more to do.
+		return &workloadattestorv0.AttestResponse{}, nil
+	}
+
+	taskARN, err := agent.TaskARN(ctx, containerID)
+	if err != nil {
+		return nil, ecsErr.Wrap(err)
+	}
+
+	task, err := p.describeTask(ctx, taskARN)
+	if err != nil {
+		return nil, err
+	}
+
+	selectors, err := getSelectorsFromTask(task)
+	if err != nil {
+		return nil, err
+	}
+	return &workloadattestorv0.AttestResponse{
+		Selectors: selectors,
+	}, nil
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *spi.ConfigureRequest) (*spi.ConfigureResponse, error) {
+	config := new(Config)
+	if err := hcl.Decode(config, req.Configuration); err != nil {
+		return nil, ecsErr.New("unable to decode configuration: %v", err)
+	}
+
+	switch {
+	case config.AccessKeyID != "" && config.SecretAccessKey == "":
+		return nil, ecsErr.New("configuration missing secret access key, but has access key id")
+	case config.AccessKeyID == "" && config.SecretAccessKey != "":
+		return nil, ecsErr.New("configuration missing access key id, but has secret access key")
+	}
+
+	if config.ECSAgentURI == "" {
+		config.ECSAgentURI = defaultECSAgentURI
+	}
+
+	var containerIDFinder cgroup.ContainerIDFinder = &defaultContainerIDFinder{}
+	if len(config.ContainerIDCGroupMatchers) > 0 {
+		var err error
+		containerIDFinder, err = cgroup.NewContainerIDFinder(config.ContainerIDCGroupMatchers)
+		if err != nil {
+			return nil, ecsErr.Wrap(err)
+		}
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.config = config
+	p.containerIDFinder = containerIDFinder
+	p.agent = newIntrospectionClient(config.ECSAgentURI)
+	p.clients = make(map[string]ECSClient)
+	return &spi.ConfigureResponse{}, nil
+}
+
+func (*Plugin) GetPluginInfo(context.Context, *spi.GetPluginInfoRequest) (*spi.GetPluginInfoResponse, error) {
+	return &spi.GetPluginInfoResponse{}, nil
+}
+
+// describeTask describes the task with the ECS API of the region and cluster
+// in the task ARN.
+func (p *Plugin) describeTask(ctx context.Context, taskARN string) (*ecs.Task, error) {
+	region, cluster, err := parseTaskARN(taskARN)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := p.getClient(region)
+	if err != nil {
+		return nil, ecsErr.New("unable to create an ECS client: %v", err)
+	}
+
+	resp, err := client.DescribeTasksWithContext(ctx, &ecs.DescribeTasksInput{
+		Cluster: aws.String(cluster),
+		Tasks:   []*string{aws.String(taskARN)},
+	})
+	if err != nil {
+		return nil, ecsErr.New("unable to describe task %q: %v", taskARN, err)
+	}
+	for _, task := range resp.Tasks {
+		if aws.StringValue(task.TaskArn) == taskARN {
+			return task, nil
+		}
+	}
+	for _, failure := range resp.Failures {
+		if aws.StringValue(failure.Arn) == taskARN {
+			return nil, ecsErr.New("unable to describe task %q: %s", taskARN, aws.StringValue(failure.Reason))
+		}
+	}
+	return nil, ecsErr.New("unable to describe task %q: task not found", taskARN)
+}
+
+func (p *Plugin) getClient(region string) (ECSClient, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if client, ok := p.clients[region]; ok {
+		return client, nil
+	}
+	if p.config == nil {
+		return nil, errors.New("not configured")
+	}
+
+	client, err := p.newClient(p.config, region)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[region] = client
+	return client, nil
+}
+
+// parseTaskARN returns the region and cluster name from a task ARN in the
+// long format, i.e. "arn:aws:ecs:<region>:<account>:task/<cluster>/<id>".
+func parseTaskARN(taskARN string) (region, cluster string, err error) {
+	parsed, err := arn.Parse(taskARN)
+	if err != nil {
+		return "", "", ecsErr.New("malformed task ARN %q: %v", taskARN, err)
+	}
+	parts := strings.Split(parsed.Resource, "/")
+	switch {
+	case parsed.Service != "ecs" || parts[0] != "task":
+		return "", "", ecsErr.New("malformed task ARN %q: not an ECS task", taskARN)
+	case len(parts) != 3:
+		return "", "", ecsErr.New("task ARN %q does not include the cluster; the long ARN format is required", taskARN)
+	}
+	return parsed.Region, parts[1], nil
+}
+
+func getSelectorsFromTask(task *ecs.Task) ([]*common.Selector, error) {
+	cluster, err := resourceName(aws.StringValue(task.ClusterArn), clusterResourcePrefix)
+	if err != nil {
+		return nil, err
+	}
+	taskDef, err := resourceName(aws.StringValue(task.TaskDefinitionArn), taskDefResourcePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	selectors := []*common.Selector{
+		makeSelector(subselectorCluster, cluster),
+		makeSelector(subselectorTaskDef, taskDef),
+	}
+	// Tasks started by a service are in the "service:<name>" group
+	if group := aws.StringValue(task.Group); strings.HasPrefix(group, serviceGroupPrefix) {
+		selectors = append(selectors, makeSelector(subselectorService, strings.TrimPrefix(group, serviceGroupPrefix)))
+	}
+	return selectors, nil
+}
+
+// resourceName returns the name of the resource in an ARN, e.g. "default" for
+// "arn:aws:ecs:us-east-1:123456789012:cluster/default".
+func resourceName(s, prefix string) (string, error) {
+	parsed, err := arn.Parse(s)
+	if err != nil {
+		return "", ecsErr.New("malformed ARN %q: %v", s, err)
+	}
+	if !strings.HasPrefix(parsed.Resource, prefix) {
+		return "", ecsErr.New("malformed ARN %q: expected a %q resource", s, strings.TrimSuffix(prefix, "/"))
+	}
+	return strings.TrimPrefix(parsed.Resource, prefix), nil
+}
+
+func makeSelector(kind, value string) *common.Selector {
+	return &common.Selector{
+		Type:  pluginName,
+		Value: fmt.Sprintf("%s:%s", kind, value),
+	}
+}
+
+// getContainerIDFromCGroups returns the container ID from a set of cgroups
+// using the given finder. All of the cgroups that yield a container ID must
+// agree on it. An empty string is returned if no container ID is found, i.e.
+// the workload is not running in a container.
+func getContainerIDFromCGroups(finder cgroup.ContainerIDFinder, cgroups []cgroups.Cgroup) (string, error) {
+	var found bool
+	var containerID string
+	for _, cgroup := range cgroups {
+		candidate, ok := finder.FindContainerID(cgroup.GroupPath)
+		if !ok {
+			continue
+		}
+		found = true
+
+		switch {
+		case containerID == "":
+			containerID = candidate
+		case containerID != candidate:
+			return "", ecsErr.New("multiple container IDs found in cgroups (%s, %s)", containerID, candidate)
+		}
+	}
+
+	if found && containerID == "" {
+		return "", ecsErr.New("a pattern matched, but no container id was found")
+	}
+	return containerID, nil
+}
+
+// ecsCGroupRE matches the cgroup paths of the containers run by the ECS
+// container agent, i.e. "/ecs/<task id>/<container id>", capturing the 64
+// hex-character docker container ID.
+var ecsCGroupRE = regexp.MustCompile(`\becs\b.+\b([[:xdigit:]]{64})\b`)
+
+type defaultContainerIDFinder struct{}
+
+func (f *defaultContainerIDFinder) FindContainerID(cgroupPath string) (string, bool) {
+	m := ecsCGroupRE.FindStringSubmatch(cgroupPath)
+	if m != nil {
+		return m[1], true
+	}
+	return "", false
+}
@@ -0,0 +1,237 @@
+package awsecs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/spiffe/spire/pkg/agent/plugin/workloadattestor"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testContainerID   = "6469646e742065787065637420616e796f6e6520746f20726561642074686973"
+	testCgroupEntries = "10:devices:/ecs/5a0d5ce2d10c4e8bb2e4a2b8e0c4b7b4/" + testContainerID
+	testTaskARN       = "arn:aws:ecs:us-east-1:123456789012:task/prod/5a0d5ce2d10c4e8bb2e4a2b8e0c4b7b4"
+	testClusterARN    = "arn:aws:ecs:us-east-1:123456789012:cluster/prod"
+	testTaskDefARN    = "arn:aws:ecs:us-east-1:123456789012:task-definition/web:7"
+)
+
+func TestAttest(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		cgroups         string
+		taskARN         string
+		task            *ecs.Task
+		describeErr     error
+		expectSelectors []string
+		expectErr       string
+	}{
+		{
+			name:    "task started by a service",
+			cgroups: testCgroupEntries,
+			taskARN: testTaskARN,
+			task: &ecs.Task{
+				TaskArn:           aws.String(testTaskARN),
+				ClusterArn:        aws.String(testClusterARN),
+				TaskDefinitionArn: aws.String(testTaskDefARN),
+				Group:             aws.String("service:frontend"),
+			},
+			expectSelectors: []string{
+				"aws_ecs:cluster:prod",
+				"aws_ecs:taskdef:web:7",
+				"aws_ecs:service:frontend",
+			},
+		},
+		{
+			name:    "standalone task",
+			cgroups: testCgroupEntries,
+			taskARN: testTaskARN,
+			task: &ecs.Task{
+				TaskArn:           aws.String(testTaskARN),
+				ClusterArn:        aws.String(testClusterARN),
+				TaskDefinitionArn: aws.String(testTaskDefARN),
+				Group:             aws.String("family:web"),
+			},
+			expectSelectors: []string{
+				"aws_ecs:cluster:prod",
+				"aws_ecs:taskdef:web:7",
+			},
+		},
+		{
+			name:    "not a container",
+			cgroups: "10:devices:/user.slice",
+		},
+		{
+			name:      "more than one container ID",
+			cgroups:   testCgroupEntries + "\n4:cpu:/ecs/5a0d5ce2d10c4e8bb2e4a2b8e0c4b7b4/41e4ab61d2860b0e1467de0da0a9c6068012761febec402dc04a5a94f32ea867",
+			expectErr: "multiple container IDs found in cgroups",
+		},
+		{
+			name:      "container unknown to the ECS agent",
+			cgroups:   testCgroupEntries,
+			expectErr: "unexpected status code from the ECS agent: 404",
+		},
+		{
+			name:      "task ARN without the cluster",
+			cgroups:   testCgroupEntries,
+			taskARN:   "arn:aws:ecs:us-east-1:123456789012:task/5a0d5ce2d10c4e8bb2e4a2b8e0c4b7b4",
+			expectErr: "does not include the cluster; the long ARN format is required",
+		},
+		{
+			name:        "describe tasks fails",
+			cgroups:     testCgroupEntries,
+			taskARN:     testTaskARN,
+			describeErr: errors.New("oh no"),
+			expectErr:   fmt.Sprintf("unable to describe task %q: oh no", testTaskARN),
+		},
+		{
+			name:      "task not found",
+			cgroups:   testCgroupEntries,
+			taskARN:   testTaskARN,
+			expectErr: fmt.Sprintf("unable to describe task %q: MISSING", testTaskARN),
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/tasks" || r.URL.Query().Get("dockerid") != testContainerID || tt.taskARN == "" {
+					http.NotFound(w, r)
+					return
+				}
+				fmt.Fprintf(w, `{"Arn": %q, "DesiredStatus": "RUNNING"}`, tt.taskARN)
+			}))
+			defer agent.Close()
+
+			client := &fakeECSClient{
+				t:       t,
+				cluster: "prod",
+				taskARN: tt.taskARN,
+				task:    tt.task,
+				err:     tt.describeErr,
+			}
+			p := New()
+			p.fs = fakeFileSystem{"/proc/123/cgroup": tt.cgroups}
+			p.newClient = func(config *Config, region string) (ECSClient, error) {
+				require.Equal(t, "us-east-1", region)
+				return client, nil
+			}
+			attestor := loadPlugin(t, p, fmt.Sprintf("ecs_agent_uri = %q", agent.URL))
+
+			selectors, err := attestor.Attest(context.Background(), 123)
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectSelectors, selectorStrings(selectors))
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		config    string
+		expectErr string
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:      "malformed",
+			config:    "ecs_agent_uri = [",
+			expectErr: "unable to decode configuration",
+		},
+		{
+			name:      "access key id without secret",
+			config:    `access_key_id = "id"`,
+			expectErr: "configuration missing secret access key, but has access key id",
+		},
+		{
+			name:      "secret without access key id",
+			config:    `secret_access_key = "secret"`,
+			expectErr: "configuration missing access key id, but has secret access key",
+		},
+		{
+			name:      "ambiguous container ID matchers",
+			config:    `container_id_cgroup_matchers = ["/a/b/<id>", "/*/b/<id>"]`,
+			expectErr: "ambiguous",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			var err error
+			plugintest.Load(t, builtin(p), new(workloadattestor.V0),
+				plugintest.Configure(tt.config),
+				plugintest.CaptureConfigureError(&err))
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, defaultECSAgentURI, p.config.ECSAgentURI)
+		})
+	}
+}
+
+func loadPlugin(t *testing.T, p *Plugin, config string) *workloadattestor.V0 {
+	attestor := new(workloadattestor.V0)
+	plugintest.Load(t, builtin(p), attestor, plugintest.Configure(config))
+	return attestor
+}
+
+func selectorStrings(selectors []*common.Selector) []string {
+	var out []string
+	for _, selector := range selectors {
+		out = append(out, selector.Type+":"+selector.Value)
+	}
+	return out
+}
+
+type fakeECSClient struct {
+	t       *testing.T
+	cluster string
+	taskARN string
+	task    *ecs.Task
+	err     error
+}
+
+func (c *fakeECSClient) DescribeTasksWithContext(ctx aws.Context, input *ecs.DescribeTasksInput, opts ...request.Option) (*ecs.DescribeTasksOutput, error) {
+	require.Equal(c.t, c.cluster, aws.StringValue(input.Cluster))
+	require.Equal(c.t, []*string{aws.String(c.taskARN)}, input.Tasks)
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.task == nil {
+		return &ecs.DescribeTasksOutput{
+			Failures: []*ecs.Failure{{Arn: aws.String(c.taskARN), Reason: aws.String("MISSING")}},
+		}, nil
+	}
+	return &ecs.DescribeTasksOutput{Tasks: []*ecs.Task{c.task}}, nil
+}
+
+type fakeFileSystem map[string]string
+
+func (fs fakeFileSystem) Open(path string) (io.ReadCloser, error) {
+	data, ok := fs[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return ioutil.NopCloser(strings.NewReader(data)), nil
+}
@@ -0,0 +1,86 @@
+package awsecs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+// ECSClient interface describing used aws ecs client functions, useful for mocking
+type ECSClient interface {
+	DescribeTasksWithContext(ctx aws.Context, input *ecs.DescribeTasksInput, opts ...request.Option) (*ecs.DescribeTasksOutput, error)
+}
+
+// ECSAgent looks up the tasks run by the ECS container agent on this host,
+// useful for mocking
+type ECSAgent interface {
+	// TaskARN returns the ARN of the task the container belongs to
+	TaskARN(ctx context.Context, containerID string) (string, error)
+}
+
+type newClientCallback func(config *Config, region string) (ECSClient, error)
+
+func newClient(config *Config, region string) (ECSClient, error) {
+	awsConf := &aws.Config{Region: aws.String(region)}
+	if config.AccessKeyID != "" && config.SecretAccessKey != "" {
+		awsConf.Credentials = credentials.NewStaticCredentials(config.AccessKeyID, config.SecretAccessKey, "")
+	}
+	sess, err := session.NewSession(awsConf)
+	if err != nil {
+		return nil, err
+	}
+	return ecs.New(sess), nil
+}
+
+// introspectionClient queries the introspection API of the ECS container
+// agent, which is only reachable from the host.
+type introspectionClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+func newIntrospectionClient(baseURL string) *introspectionClient {
+	return &introspectionClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  http.DefaultClient,
+	}
+}
+
+func (c *introspectionClient) TaskARN(ctx context.Context, containerID string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v1/tasks?dockerid="+url.QueryEscape(containerID), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to query the ECS agent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("unexpected status code from the ECS agent: %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var task struct {
+		Arn string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&task); err != nil {
+		return "", fmt.Errorf("unable to decode the ECS agent response: %w", err)
+	}
+	if task.Arn == "" {
+		return "", fmt.Errorf("the ECS agent returned no task ARN for container %q", containerID)
+	}
+	return task.Arn, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -11,8 +12,10 @@ import (
 	observer "github.com/imkira/go-observer"
 	"github.com/spiffe/spire/pkg/agent/client"
 	"github.com/spiffe/spire/pkg/agent/common/backoff"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/nodeutil"
 	"github.com/spiffe/spire/pkg/common/rotationutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
 	telemetry_agent "github.com/spiffe/spire/pkg/common/telemetry/agent"
 	"github.com/spiffe/spire/pkg/common/util"
 )
@@ -24,6 +27,16 @@ type Rotator interface {
 	Subscribe() observer.Stream
 	GetRotationMtx() *sync.RWMutex
 	SetRotationFinishedHook(func())
+
+	// Reattest performs node attestation again to get a new SVID.
+	Reattest(ctx context.Context) error
+}
+
+// Reattestor performs node attestation for the given private key, using the
+// current agent SVID and its key to authenticate the agent and the bundle to
+// authenticate the server, and returns the new agent SVID.
+type Reattestor interface {
+	Reattest(ctx context.Context, svid []*x509.Certificate, svidKey crypto.Signer, key crypto.Signer, bundle *bundleutil.Bundle) ([]*x509.Certificate, error)
 }
 
 type rotator struct {
@@ -55,7 +68,11 @@ type State struct {
 // Run runs the rotator. It monitors the server SVID for expiration and rotates
 // as necessary. It also watches for changes to the trust bundle.
 func (r *rotator) Run(ctx context.Context) error {
-	err := util.RunTasks(ctx, r.runRotation, r.processBundleUpdates)
+	tasks := []func(context.Context) error{r.runRotation, r.processBundleUpdates}
+	if r.c.ReattestInterval > 0 {
+		tasks = append(tasks, r.runReattestation)
+	}
+	err := util.RunTasks(ctx, tasks...)
 	r.c.Log.Debug("Stopping SVID rotator")
 	r.client.Release()
 	return err
@@ -88,6 +105,25 @@ func (r *rotator) runRotation(ctx context.Context) error {
 	}
 }
 
+// runReattestation periodically reattests the agent, so changes to the node
+// selectors (e.g. to the tags of a cloud instance) are picked up without
+// waiting for the agent to be attested again from scratch.
+func (r *rotator) runReattestation(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-r.clk.After(r.c.ReattestInterval):
+		}
+
+		if err := r.Reattest(ctx); err != nil {
+			// The SVID keeps being rotated as usual, so just log the error
+			// and wait for the next reattestation
+			r.c.Log.WithError(err).Error("Could not reattest agent")
+		}
+	}
+}
+
 func (r *rotator) processBundleUpdates(ctx context.Context) error {
 	for {
 		select {
@@ -127,6 +163,48 @@ func (r *rotator) rotateSVIDIfNeeded(ctx context.Context) (err error) {
 	return err
 }
 
+// Reattest performs node attestation again to get a new agent SVID.
+func (r *rotator) Reattest(ctx context.Context) (err error) {
+	if r.c.Reattestor == nil {
+		return errors.New("reattestation is not enabled")
+	}
+
+	counter := telemetry_agent.StartReattestAgentSVIDCall(r.c.Metrics)
+	defer counter.Done(&err)
+
+	// Like rotations, reattestations must not happen while new connections
+	// are being created
+	r.rotMtx.Lock()
+	defer r.rotMtx.Unlock()
+	r.c.Log.Debug("Reattesting agent")
+
+	r.bsm.RLock()
+	bundle := r.c.BundleStream.Value()[r.c.TrustDomain]
+	r.bsm.RUnlock()
+
+	state := r.state.Value().(State)
+	key, err := r.c.Catalog.GetKeyManager().GenerateKey(ctx, state.Key)
+	if err != nil {
+		return err
+	}
+
+	svid, err := r.c.Reattestor.Reattest(ctx, state.SVID, state.Key, key, bundle)
+	if err != nil {
+		return err
+	}
+
+	r.state.Update(State{
+		SVID: svid,
+		Key:  key,
+	})
+
+	// The client connection is tied to the previous SVID
+	r.client.Release()
+
+	r.c.Log.WithField(telemetry.SPIFFEID, svid[0].URIs[0].String()).Info("Agent reattestation was successful")
+	return nil
+}
+
 // rotateSVID asks SPIRE's server for a new agent's SVID.
 func (r *rotator) rotateSVID(ctx context.Context) (err error) {
 	counter := telemetry_agent.StartRotateAgentSVIDCall(r.c.Metrics)
@@ -138,7 +216,7 @@ func (r *rotator) rotateSVID(ctx context.Context) (err error) {
 	defer r.rotMtx.Unlock()
 	r.c.Log.Debug("Rotating agent SVID")
 
-	key, err := r.c.Catalog.GetKeyManager().GenerateKey(ctx)
+	key, err := r.c.Catalog.GetKeyManager().GenerateKey(ctx, r.state.Value().(State).Key)
 	if err != nil {
 		return err
 	}
@@ -34,6 +34,14 @@ type RotatorConfig struct {
 	// How long to wait between expiry checks
 	Interval time.Duration
 
+	// Reattestor, if set, lets the agent reattest to get a new SVID, so the
+	// server attests the node and resolves its selectors again.
+	Reattestor Reattestor
+
+	// ReattestInterval, if greater than zero, is how often the agent
+	// reattests. It requires a Reattestor.
+	ReattestInterval time.Duration
+
 	// Clk is the clock that the rotator will use to create a ticker
 	Clk clock.Clock
 }
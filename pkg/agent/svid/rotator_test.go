@@ -2,6 +2,7 @@ package svid
 
 import (
 	"context"
+	"crypto"
 	"crypto/x509"
 	"errors"
 	"testing"
@@ -14,6 +15,7 @@ import (
 	"github.com/spiffe/spire/pkg/agent/client"
 	"github.com/spiffe/spire/pkg/agent/manager/cache"
 	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakeagentcatalog"
@@ -78,7 +80,7 @@ func (s *RotatorTestSuite) TearDownTest() {
 }
 
 func (s *RotatorTestSuite) TestRunWithGoodExistingSVID() {
-	key, err := s.km.GenerateKey(context.Background())
+	key, err := s.km.GenerateKey(context.Background(), nil)
 	s.Require().NoError(err)
 
 	// Cert that's valid for 1hr
@@ -230,3 +232,152 @@ func (s *RotatorTestSuite) expectSVIDRotation(cert *x509.Certificate) {
 		}, nil)
 	s.client.EXPECT().Release().MaxTimes(2)
 }
+
+func (s *RotatorTestSuite) TestReattest() {
+	bundle := s.setBundle()
+	oldKey, err := s.km.GenerateKey(context.Background(), nil)
+	s.Require().NoError(err)
+	oldCert := s.newCert("spiffe://example.org/spire/agent/test/node")
+	s.r.state = observer.NewProperty(State{SVID: []*x509.Certificate{oldCert}, Key: oldKey})
+
+	newCert := s.newCert("spiffe://example.org/spire/agent/test/node")
+	reattestor := &fakeReattestor{svid: []*x509.Certificate{newCert}}
+	s.r.c.Reattestor = reattestor
+
+	// The connection tied to the previous SVID is released
+	s.client.EXPECT().Release()
+
+	stream := s.r.Subscribe()
+	s.Require().NoError(s.r.Reattest(context.Background()))
+
+	s.Require().True(stream.HasNext())
+	state := stream.Next().(State)
+	s.Require().Equal([]*x509.Certificate{newCert}, state.SVID)
+	s.Require().NotNil(state.Key)
+
+	// The reattestation was done with the new key and the current bundle,
+	// authenticating the agent with the current SVID
+	s.Require().Equal(1, reattestor.calls)
+	s.Require().Equal([]*x509.Certificate{oldCert}, reattestor.currentSVID)
+	s.Require().Equal(oldKey, reattestor.currentKey)
+	s.Require().Equal(state.Key, reattestor.key)
+	s.Require().NotEqual(oldKey, state.Key)
+	s.Require().Equal(bundle, reattestor.bundle)
+}
+
+func (s *RotatorTestSuite) TestReattestFailureKeepsSVID() {
+	s.setBundle()
+	oldCert := s.newCert("spiffe://example.org/spire/agent/test/node")
+	s.r.state = observer.NewProperty(State{SVID: []*x509.Certificate{oldCert}})
+	s.r.c.Reattestor = &fakeReattestor{err: errors.New("oh no")}
+
+	stream := s.r.Subscribe()
+	s.Require().EqualError(s.r.Reattest(context.Background()), "oh no")
+	s.Require().False(stream.HasNext())
+	s.Require().Equal([]*x509.Certificate{oldCert}, s.r.State().SVID)
+}
+
+func (s *RotatorTestSuite) TestReattestNotEnabled() {
+	s.Require().EqualError(s.r.Reattest(context.Background()), "reattestation is not enabled")
+}
+
+func (s *RotatorTestSuite) TestRunReattestsPeriodically() {
+	s.setBundle()
+	key, err := s.km.GenerateKey(context.Background(), nil)
+	s.Require().NoError(err)
+	s.r.state = observer.NewProperty(State{
+		SVID: []*x509.Certificate{s.newCert("spiffe://example.org/spire/agent/test/node")},
+		Key:  key,
+	})
+
+	// The node selectors changed, so reattestation gets an SVID for the same
+	// agent, with the selectors resolved again by the server
+	newCert := s.newCert("spiffe://example.org/spire/agent/test/node")
+	reattestor := &fakeReattestor{
+		svid:   []*x509.Certificate{newCert},
+		called: make(chan struct{}, 1),
+	}
+	s.r.c.Reattestor = reattestor
+	s.r.c.ReattestInterval = time.Minute
+	s.client.EXPECT().Release().AnyTimes()
+
+	stream := s.r.Subscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := new(tomb.Tomb)
+	t.Go(func() error {
+		return s.r.Run(ctx)
+	})
+
+	// Move the clock forward until the reattestation happens, since the
+	// rotation checks also wait on the clock
+	timeout := time.After(time.Minute)
+waitForReattestation:
+	for {
+		select {
+		case <-reattestor.called:
+			break waitForReattestation
+		case <-timeout:
+			s.FailNow("timed out waiting for reattestation")
+		case <-time.After(10 * time.Millisecond):
+			s.mockClock.Add(s.r.c.ReattestInterval)
+		}
+	}
+
+	select {
+	case <-time.After(time.Minute):
+		s.FailNow("timed out waiting for SVID update")
+	case <-stream.Changes():
+		state := stream.Next().(State)
+		s.Require().Equal([]*x509.Certificate{newCert}, state.SVID)
+	}
+
+	cancel()
+	err = t.Wait()
+	s.Require().True(errors.Is(err, context.Canceled))
+}
+
+func (s *RotatorTestSuite) setBundle() *bundleutil.Bundle {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	bundle := bundleutil.BundleFromRootCAs(td, s.bundle.Value().([]*x509.Certificate))
+	s.r.c.BundleStream = cache.NewBundleStream(observer.NewProperty(map[spiffeid.TrustDomain]*cache.Bundle{
+		td: bundle,
+	}).Observe())
+	return bundle
+}
+
+func (s *RotatorTestSuite) newCert(spiffeID string) *x509.Certificate {
+	// Cert that's valid for 1hr
+	temp, err := util.NewSVIDTemplate(s.mockClock, spiffeID)
+	s.Require().NoError(err)
+	cert, _, err := util.SelfSign(temp)
+	s.Require().NoError(err)
+	return cert
+}
+
+type fakeReattestor struct {
+	svid   []*x509.Certificate
+	err    error
+	called chan struct{}
+
+	calls       int
+	currentSVID []*x509.Certificate
+	currentKey  crypto.Signer
+	key         crypto.Signer
+	bundle      *bundleutil.Bundle
+}
+
+func (r *fakeReattestor) Reattest(ctx context.Context, svid []*x509.Certificate, svidKey crypto.Signer, key crypto.Signer, bundle *bundleutil.Bundle) ([]*x509.Certificate, error) {
+	r.calls++
+	r.currentSVID = svid
+	r.currentKey = svidKey
+	r.key = key
+	r.bundle = bundle
+	if r.called != nil {
+		select {
+		case r.called <- struct{}{}:
+		default:
+		}
+	}
+	return r.svid, r.err
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/spiffe/spire/pkg/common/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// WithTracing starts a span for each RPC call, continuing the trace
+// propagated by the caller in the request metadata, if any. The span is
+// available to the handler through the context so that internal operations
+// are recorded as its children.
+func WithTracing() Middleware {
+	return tracingMiddleware{}
+}
+
+type tracingMiddleware struct{}
+
+func (tracingMiddleware) Preprocess(ctx context.Context, fullMethod string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	ctx, names := withNames(ctx, fullMethod)
+	ctx, _ = tracing.StartSpan(ctx, names.RawService+"/"+names.Method,
+		semconv.RPCSystemKey.String("grpc"),
+		semconv.RPCServiceKey.String(names.RawService),
+		semconv.RPCMethodKey.String(names.Method),
+	)
+	return ctx, nil
+}
+
+func (tracingMiddleware) Postprocess(ctx context.Context, fullMethod string, handlerInvoked bool, rpcErr error) {
+	span := trace.SpanFromContext(ctx)
+	code := status.Code(rpcErr)
+	span.SetAttributes(attribute.Int64("rpc.grpc.status_code", int64(code)))
+	if rpcErr != nil {
+		span.RecordError(rpcErr)
+		span.SetStatus(codes.Error, code.String())
+	}
+	span.End()
+}
+
+// metadataCarrier adapts gRPC metadata to the OpenTelemetry propagation
+// interfaces.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,99 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/common/api/middleware"
+	"github.com/spiffe/spire/pkg/common/tracing"
+	"github.com/spiffe/spire/test/fakes/faketracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithTracing(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		exporter := faketracing.New(t)
+
+		m := middleware.WithTracing()
+		ctx, err := m.Preprocess(context.Background(), fakeFullMethod)
+		require.NoError(t, err)
+
+		// Spans started by the handler are children of the RPC span
+		_, child := tracing.StartSpan(ctx, "child")
+		child.End()
+
+		m.Postprocess(ctx, fakeFullMethod, true, nil)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "child", spans[0].Name)
+		assert.Equal(t, "spire.api.server.foo.v1.Foo/SomeMethod", spans[1].Name)
+		assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].ParentSpanID)
+		assert.Contains(t, spans[1].Attributes, attribute.String("rpc.system", "grpc"))
+		assert.Contains(t, spans[1].Attributes, attribute.String("rpc.service", "spire.api.server.foo.v1.Foo"))
+		assert.Contains(t, spans[1].Attributes, attribute.String("rpc.method", "SomeMethod"))
+		assert.Contains(t, spans[1].Attributes, attribute.Int64("rpc.grpc.status_code", int64(codes.OK)))
+		assert.Equal(t, otelcodes.Unset, spans[1].StatusCode)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		exporter := faketracing.New(t)
+
+		m := middleware.WithTracing()
+		ctx, err := m.Preprocess(context.Background(), fakeFullMethod)
+		require.NoError(t, err)
+		m.Postprocess(ctx, fakeFullMethod, true, status.Error(codes.PermissionDenied, "ohno"))
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Contains(t, spans[0].Attributes, attribute.Int64("rpc.grpc.status_code", int64(codes.PermissionDenied)))
+		assert.Equal(t, otelcodes.Error, spans[0].StatusCode)
+		assert.Equal(t, "PermissionDenied", spans[0].StatusMessage)
+		require.Len(t, spans[0].MessageEvents, 1)
+		assert.Equal(t, "error", spans[0].MessageEvents[0].Name)
+	})
+
+	t.Run("continues propagated trace", func(t *testing.T) {
+		exporter := faketracing.New(t)
+
+		// Start a span on the "client" side and inject it into the metadata
+		clientCtx, clientSpan := tracing.StartSpan(context.Background(), "client")
+		header := http.Header{}
+		otel.GetTextMapPropagator().Inject(clientCtx, propagation.HeaderCarrier(header))
+		clientSpan.End()
+		md := metadata.MD{}
+		for key, values := range header {
+			md.Set(key, values...)
+		}
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+
+		m := middleware.WithTracing()
+		ctx, err := m.Preprocess(ctx, fakeFullMethod)
+		require.NoError(t, err)
+		m.Postprocess(ctx, fakeFullMethod, true, nil)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, clientSpan.SpanContext().TraceID(), spans[1].SpanContext.TraceID())
+		assert.Equal(t, clientSpan.SpanContext().SpanID(), spans[1].ParentSpanID)
+		assert.True(t, spans[1].HasRemoteParent)
+	})
+
+	t.Run("no-op when tracing is not set up", func(t *testing.T) {
+		m := middleware.WithTracing()
+		ctx, err := m.Preprocess(context.Background(), fakeFullMethod)
+		require.NoError(t, err)
+		assert.False(t, trace.SpanFromContext(ctx).SpanContext().IsValid())
+		m.Postprocess(ctx, fakeFullMethod, true, nil)
+	})
+}
@@ -302,7 +302,9 @@ pruneRootCA:
 		newBundle.JwtSigningKeys = append(newBundle.JwtSigningKeys, jwtSigningKey)
 	}
 
-	if len(newBundle.RootCas) == 0 {
+	// Bundles holding only JWT signing keys, like those of the trust domains
+	// the server only signs JWT-SVIDs for, have no certificates to keep.
+	if len(bundle.RootCas) > 0 && len(newBundle.RootCas) == 0 {
 		log.Warn("Pruning halted; all known CA certificates have expired")
 		return nil, false, errors.New("would prune all certificates")
 	}
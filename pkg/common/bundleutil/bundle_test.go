@@ -70,6 +70,19 @@ func TestPruneBundle(t *testing.T) {
 			expiration:  test.currentTime,
 			expectedErr: "would prune all JWT signing keys",
 		},
+		{
+			name: "succeeds without X509 certs",
+			bundle: createBundle(
+				nil,
+				[]*common.PublicKey{test.jwtKeyNotExpired, test.jwtKeyExpired},
+			),
+			newBundle: createBundle(
+				nil,
+				[]*common.PublicKey{test.jwtKeyNotExpired},
+			),
+			expiration: test.currentTime,
+			changed:    true,
+		},
 		{
 			name: "succeeds",
 			bundle: createBundle(
@@ -177,33 +177,16 @@ func Load(ctx context.Context, config Config, cat Catalog) (_ io.Closer, err err
 			continue
 		}
 
-		plugin, err := loadPlugin(ctx, pluginRepo.BuiltIns(), pluginConfig, pluginLog, config.HostServices)
-		if err != nil {
-			pluginLog.WithError(err).Error("Failed to load plugin")
-			return nil, fmt.Errorf("failed to load plugin %q: %w", pluginConfig.Name, err)
+		plugin, err := loadAndConfigurePlugin(ctx, config, pluginRepo, serviceRepos, pluginConfig, pluginLog)
+		if plugin != nil {
+			// Add the plugin to the closers even though it might not have
+			// been completely configured. If anything goes wrong (i.e. failure
+			// to configure, panic, etc.) we want the defer above to close the
+			// plugin. Failure to do so can orphan external plugin processes.
+			closers = append(closers, plugin)
 		}
-
-		// Add the plugin to the closers even though it has not been completely
-		// configured. If anything goes wrong (i.e. failure to configure,
-		// panic, etc.) we want the defer above to close the plugin. Failure to
-		// do so can orphan external plugin processes.
-		closers = append(closers, plugin)
-
-		configurer, err := plugin.bindRepos(pluginRepo, serviceRepos)
 		if err != nil {
-			pluginLog.WithError(err).Error("Failed to bind plugin")
-			return nil, fmt.Errorf("failed to bind plugin %q: %w", pluginConfig.Name, err)
-		}
-
-		switch {
-		case configurer != nil:
-			if err := configurer.Configure(ctx, config.CoreConfig, pluginConfig.Data); err != nil {
-				pluginLog.WithError(err).Error("Failed to configure plugin")
-				return nil, fmt.Errorf("failed to configure plugin %q: %w", pluginConfig.Name, err)
-			}
-		case pluginConfig.Data != "":
-			pluginLog.WithField(telemetry.Reason, "no supported configuration interface").Error("Failed to configure plugin")
-			return nil, fmt.Errorf("failed to configure plugin %q: no supported configuration interface found", pluginConfig.Name)
+			return nil, err
 		}
 
 		pluginLog.Info("Plugin loaded")
@@ -220,6 +203,107 @@ func Load(ctx context.Context, config Config, cat Catalog) (_ io.Closer, err err
 	return closers, nil
 }
 
+// Validate loads and configures the plugins defined in the configuration like
+// Load, but instead of stopping at the first failure, it carries on with the
+// remaining plugins and returns every error found. All plugins are unloaded
+// and the catalog is cleared before returning, so the configuration does not
+// take effect.
+func Validate(ctx context.Context, config Config, cat Catalog) []error {
+	closers := make(closerGroup, 0)
+	defer func() {
+		for _, pluginRepo := range cat.Plugins() {
+			pluginRepo.Clear()
+		}
+		for _, serviceRepo := range cat.Services() {
+			serviceRepo.Clear()
+		}
+		closers.Close()
+	}()
+
+	pluginRepos, err := makeBindablePluginRepos(cat.Plugins())
+	if err != nil {
+		return []error{err}
+	}
+	serviceRepos, err := makeBindableServiceRepos(cat.Services())
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	pluginCounts := make(map[string]int)
+	for _, pluginConfig := range config.PluginConfigs {
+		pluginLog := makePluginLog(config.Log, pluginConfig)
+
+		pluginRepo, ok := pluginRepos[pluginConfig.Type]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unsupported plugin type %q", pluginConfig.Type))
+			continue
+		}
+
+		if pluginConfig.Disabled {
+			continue
+		}
+
+		// The plugin still counts towards the constraints when it fails, to
+		// avoid reporting an unsatisfied constraint on top of its error.
+		pluginCounts[pluginConfig.Type]++
+
+		plugin, err := loadAndConfigurePlugin(ctx, config, pluginRepo, serviceRepos, pluginConfig, pluginLog)
+		if plugin != nil {
+			closers = append(closers, plugin)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, pluginType := range sortedPluginTypes(pluginRepos) {
+		if err := pluginRepos[pluginType].Constraints().Check(pluginCounts[pluginType]); err != nil {
+			errs = append(errs, fmt.Errorf("plugin type %q constraint not satisfied: %v", pluginType, err))
+		}
+	}
+
+	return errs
+}
+
+// loadAndConfigurePlugin loads the plugin, binds it to the repositories and
+// configures it. The plugin is returned, so it can be closed, even when it
+// fails to bind or configure.
+func loadAndConfigurePlugin(ctx context.Context, config Config, pluginRepo bindablePluginRepo, serviceRepos []bindableServiceRepo, pluginConfig PluginConfig, pluginLog logrus.FieldLogger) (*pluginImpl, error) {
+	plugin, err := loadPlugin(ctx, pluginRepo.BuiltIns(), pluginConfig, pluginLog, config.HostServices)
+	if err != nil {
+		pluginLog.WithError(err).Error("Failed to load plugin")
+		return nil, fmt.Errorf("failed to load plugin %q: %w", pluginConfig.Name, err)
+	}
+
+	configurer, err := plugin.bindRepos(pluginRepo, serviceRepos)
+	if err != nil {
+		pluginLog.WithError(err).Error("Failed to bind plugin")
+		return plugin, fmt.Errorf("failed to bind plugin %q: %w", pluginConfig.Name, err)
+	}
+
+	switch {
+	case configurer != nil:
+		if err := configurer.Configure(ctx, config.CoreConfig, pluginConfig.Data); err != nil {
+			pluginLog.WithError(err).Error("Failed to configure plugin")
+			return plugin, fmt.Errorf("failed to configure plugin %q: %w", pluginConfig.Name, err)
+		}
+	case pluginConfig.Data != "":
+		pluginLog.WithField(telemetry.Reason, "no supported configuration interface").Error("Failed to configure plugin")
+		return plugin, fmt.Errorf("failed to configure plugin %q: no supported configuration interface found", pluginConfig.Name)
+	}
+	return plugin, nil
+}
+
+func sortedPluginTypes(pluginRepos map[string]bindablePluginRepo) []string {
+	pluginTypes := make([]string, 0, len(pluginRepos))
+	for pluginType := range pluginRepos {
+		pluginTypes = append(pluginTypes, pluginType)
+	}
+	sort.Strings(pluginTypes)
+	return pluginTypes
+}
+
 func makePluginLog(log logrus.FieldLogger, pluginConfig PluginConfig) logrus.FieldLogger {
 	return log.WithFields(logrus.Fields{
 		telemetry.PluginName: pluginConfig.Name,
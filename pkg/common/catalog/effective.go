@@ -0,0 +1,109 @@
+package catalog
+
+import (
+	"github.com/hashicorp/hcl"
+	"github.com/zeebo/errs"
+)
+
+// EnvFallback is a configurable of a built-in plugin that the plugin reads
+// from the environment when it is not set in the plugin data.
+type EnvFallback struct {
+	PluginType string
+	PluginName string
+
+	// Key is the name of the configurable in the plugin data.
+	Key string
+
+	// Env is the environment variable the value is read from.
+	Env string
+
+	// EnvKey, if set, is the name of a configurable in the plugin data that
+	// overrides the environment variable the value is read from.
+	EnvKey string
+
+	// Block, if set, is the name of the block of the plugin data holding the
+	// configurable. The environment is only read when the block is present.
+	Block string
+
+	// Overrides is set when the environment variable, if set, takes
+	// precedence over the plugin data, even when it is empty.
+	Overrides bool
+}
+
+// EffectivePluginConfigs returns the plugin configurations, keyed by plugin
+// type and name, with the plugin data decoded and the values the built-in
+// plugins read from the environment filled in.
+func EffectivePluginConfigs(hclPlugins HCLPluginConfigMap, fallbacks []EnvFallback, lookupEnv func(string) (string, bool)) (map[string]interface{}, error) {
+	effective := make(map[string]interface{}, len(hclPlugins))
+	for pluginType, pluginsForType := range hclPlugins {
+		effectiveForType := make(map[string]interface{}, len(pluginsForType))
+		for pluginName, hclPluginConfig := range pluginsForType {
+			data := make(map[string]interface{})
+			if hclPluginConfig.PluginData != nil {
+				if err := hcl.DecodeObject(&data, hclPluginConfig.PluginData); err != nil {
+					return nil, errs.New("unable to decode plugin data of %s %q: %v", pluginType, pluginName, err)
+				}
+			}
+
+			if hclPluginConfig.PluginCmd == "" {
+				applyEnvFallbacks(pluginType, pluginName, data, fallbacks, lookupEnv)
+			}
+
+			config := map[string]interface{}{
+				"plugin_data": data,
+				"enabled":     hclPluginConfig.IsEnabled(),
+			}
+			if hclPluginConfig.PluginCmd != "" {
+				config["plugin_cmd"] = hclPluginConfig.PluginCmd
+				config["plugin_args"] = hclPluginConfig.PluginArgs
+				config["plugin_checksum"] = hclPluginConfig.PluginChecksum
+			}
+			effectiveForType[pluginName] = config
+		}
+		effective[pluginType] = effectiveForType
+	}
+	return effective, nil
+}
+
+func applyEnvFallbacks(pluginType, pluginName string, data map[string]interface{}, fallbacks []EnvFallback, lookupEnv func(string) (string, bool)) {
+	for _, fallback := range fallbacks {
+		if fallback.PluginType != pluginType || fallback.PluginName != pluginName {
+			continue
+		}
+
+		target := data
+		if fallback.Block != "" {
+			target = dataBlock(data, fallback.Block)
+			if target == nil {
+				continue
+			}
+		}
+		if value, ok := target[fallback.Key].(string); ok && value != "" && !fallback.Overrides {
+			continue
+		}
+
+		env := fallback.Env
+		if fallback.EnvKey != "" {
+			if value, ok := data[fallback.EnvKey].(string); ok && value != "" {
+				env = value
+			}
+		}
+		if value, ok := lookupEnv(env); ok && (value != "" || fallback.Overrides) {
+			target[fallback.Key] = value
+		}
+	}
+}
+
+// dataBlock returns the block of the decoded plugin data with the given name,
+// or nil if there is none.
+func dataBlock(data map[string]interface{}, name string) map[string]interface{} {
+	switch block := data[name].(type) {
+	case map[string]interface{}:
+		return block
+	case []map[string]interface{}:
+		if len(block) > 0 {
+			return block[0]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the values of secrets in effective configurations.
+const Redacted = "[REDACTED]"
+
+var (
+	// reSecretKey matches the names of the configurables holding secrets
+	reSecretKey = regexp.MustCompile(`(?i)(secret|password|passwd|token|credential|private_key|connection_string)`)
+
+	// reNonSecretKey matches the names of the configurables referring to a
+	// secret instead of holding it, like token_path
+	reNonSecretKey = regexp.MustCompile(`(?i)_(path|file|dir|env|ttl)$`)
+)
+
+// EffectiveConfig returns the configuration decoded from HCL as a tree of
+// maps keyed by the HCL names of the configurables. Configurables without an
+// HCL name are left out, as are nil pointers, maps and slices.
+func EffectiveConfig(config interface{}) map[string]interface{} {
+	effective, _ := effectiveValue(reflect.ValueOf(config)).(map[string]interface{})
+	return effective
+}
+
+// RedactSecrets replaces, in place, the values of the configurables in the
+// effective configuration that look like secrets. Blocks are not redacted as
+// a whole, since they can be keyed by names (e.g. of plugins) rather than
+// configurables, but their contents are. Unset values are kept so they can be
+// told apart from the redacted ones. URLs are printed without their user
+// information and query values, which can hold credentials.
+func RedactSecrets(effective map[string]interface{}) {
+	for key, value := range effective {
+		if isSecretKey(key) && !isUnset(value) && !isBlock(value) {
+			effective[key] = Redacted
+			continue
+		}
+		effective[key] = redactValue(value)
+	}
+}
+
+func redactValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case string:
+		return redactURL(value)
+	case map[string]interface{}:
+		RedactSecrets(value)
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = redactValue(elem)
+		}
+	case []map[string]interface{}:
+		for _, elem := range value {
+			RedactSecrets(elem)
+		}
+	}
+	return value
+}
+
+// redactURL redacts the user information and the query values of an absolute
+// URL. Other strings are returned as is.
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.User == nil && u.RawQuery == "") {
+		return value
+	}
+
+	if u.RawQuery != "" {
+		var keys []string
+		for key := range u.Query() {
+			keys = append(keys, url.QueryEscape(key)+"="+Redacted)
+		}
+		sort.Strings(keys)
+		u.RawQuery = strings.Join(keys, "&")
+	}
+
+	if u.User == nil {
+		return u.String()
+	}
+	u.User = nil
+	return strings.Replace(u.String(), "://", "://"+Redacted+"@", 1)
+}
+
+func isSecretKey(key string) bool {
+	return reSecretKey.MatchString(key) && !reNonSecretKey.MatchString(key)
+}
+
+func isBlock(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []map[string]interface{}:
+		return true
+	}
+	return false
+}
+
+func isUnset(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	}
+	return false
+}
+
+func effectiveValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return effectiveValue(v.Elem())
+	case reflect.Struct:
+		effective := make(map[string]interface{})
+		addStructFields(effective, v)
+		return effective
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		effective := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			if value := effectiveValue(iter.Value()); value != nil {
+				effective[fmt.Sprint(iter.Key().Interface())] = value
+			}
+		}
+		return effective
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		effective := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			effective = append(effective, effectiveValue(v.Index(i)))
+		}
+		return effective
+	default:
+		return v.Interface()
+	}
+}
+
+func addStructFields(effective map[string]interface{}, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("hcl"), ",")
+		name := tag[0]
+		if len(tag) > 1 && tag[1] == "squash" {
+			if squashed := reflect.Indirect(v.Field(i)); squashed.Kind() == reflect.Struct {
+				addStructFields(effective, squashed)
+			}
+			continue
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+
+		if value := effectiveValue(v.Field(i)); value != nil {
+			effective[name] = value
+		}
+	}
+}
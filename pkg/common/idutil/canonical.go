@@ -0,0 +1,98 @@
+package idutil
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+// CanonicalizeSpiffeID returns the canonical form of the SPIFFE ID string, so
+// that IDs that are equivalent but spelled differently (e.g. with a trailing
+// slash or percent-encoded letters) compare equal. The scheme and trust
+// domain are lowercased and the path is canonicalized with CanonicalizePath.
+// The ID is not otherwise validated.
+func CanonicalizeSpiffeID(id string) (string, error) {
+	const scheme = "spiffe://"
+	if len(id) < len(scheme) || !strings.EqualFold(id[:len(scheme)], scheme) {
+		return "", errors.New(`scheme must be "spiffe://"`)
+	}
+	protoID, err := IDProtoFromString(scheme + id[len(scheme):])
+	if err != nil {
+		return "", err
+	}
+	return IDProtoString(&types.SPIFFEID{
+		TrustDomain: strings.ToLower(protoID.TrustDomain),
+		Path:        CanonicalizePath(protoID.Path),
+	})
+}
+
+// CanonicalizePath returns the canonical form of an escaped SPIFFE ID path.
+// Only normalizations that do not change which resource the path names are
+// applied:
+//   - percent-encoded unreserved characters (ALPHA, DIGIT, "-", ".", "_" and
+//     "~") are decoded, as in RFC 3986 section 6.2.2.2
+//   - the hex digits of the remaining percent-encodings are uppercased
+//   - runs of slashes are collapsed into one, i.e. empty segments are removed
+//   - the trailing slash is stripped
+//
+// Percent-encoded reserved characters, notably "%2F", are left encoded since
+// decoding them changes how the path splits into segments. Dot segments are
+// not resolved and the case of the path is preserved, so IDs with those are
+// still rejected, rather than silently rewritten, by the normalization checks.
+func CanonicalizePath(p string) string {
+	if !strings.Contains(p, "%") && !strings.Contains(p, "//") && !strings.HasSuffix(p, "/") {
+		return p
+	}
+
+	var b strings.Builder
+	b.Grow(len(p))
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case c == '%' && i+2 < len(p) && isHex(p[i+1]) && isHex(p[i+2]):
+			decoded := unhex(p[i+1])<<4 | unhex(p[i+2])
+			if isUnreserved(decoded) {
+				b.WriteByte(decoded)
+			} else {
+				b.WriteByte('%')
+				b.WriteString(strings.ToUpper(p[i+1 : i+3]))
+			}
+			i += 2
+		case c == '/' && strings.HasSuffix(b.String(), "/"):
+			// Collapse runs of slashes
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "/")
+}
+
+func isUnreserved(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	case c == '-', c == '.', c == '_', c == '~':
+		return true
+	}
+	return false
+}
+
+func isHex(c byte) bool {
+	switch {
+	case '0' <= c && c <= '9', 'a' <= c && c <= 'f', 'A' <= c && c <= 'F':
+		return true
+	}
+	return false
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
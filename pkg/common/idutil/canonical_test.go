@@ -0,0 +1,61 @@
+package idutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalizePath(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{name: "empty", in: "", out: ""},
+		{name: "already canonical", in: "/foo/bar", out: "/foo/bar"},
+		{name: "root", in: "/", out: ""},
+		{name: "trailing slash", in: "/foo/bar/", out: "/foo/bar"},
+		{name: "duplicate slashes", in: "//foo///bar", out: "/foo/bar"},
+		{name: "duplicate trailing slashes", in: "/foo//", out: "/foo"},
+		{name: "encoded letters and digits", in: "/%66%6F%6f/%30", out: "/foo/0"},
+		{name: "encoded unreserved marks", in: "/a%2Db%2ec%5Fd%7e", out: "/a-b.c_d~"},
+		{name: "encoded slash is kept", in: "/foo%2fbar", out: "/foo%2Fbar"},
+		{name: "encoded non-ASCII is kept", in: "/%e4%b8%96", out: "/%E4%B8%96"},
+		{name: "malformed encoding is kept", in: "/foo%zz/%4", out: "/foo%zz/%4"},
+		{name: "dot segments are kept", in: "/foo/../bar", out: "/foo/../bar"},
+		{name: "case is preserved", in: "/FoO", out: "/FoO"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.out, CanonicalizePath(tt.in))
+		})
+	}
+}
+
+func TestCanonicalizeSpiffeID(t *testing.T) {
+	// Equivalent, but differently spelled, IDs resolve to the same form
+	for _, id := range []string{
+		"spiffe://example.org/spire/agent/x509pop/abc",
+		"SPIFFE://Example.ORG/spire/agent/x509pop/abc",
+		"spiffe://example.org/spire/agent/x509pop/abc/",
+		"spiffe://example.org//spire//agent/x509pop/abc",
+		"spiffe://example.org/spire/agent/x509pop/%61%62%63",
+		"spiffe://example.org/%73pire/agent/x509pop///%61bc//",
+	} {
+		canonical, err := CanonicalizeSpiffeID(id)
+		require.NoError(t, err, id)
+		assert.Equal(t, "spiffe://example.org/spire/agent/x509pop/abc", canonical, id)
+	}
+
+	canonical, err := CanonicalizeSpiffeID("spiffe://example.org/")
+	require.NoError(t, err)
+	assert.Equal(t, "spiffe://example.org", canonical)
+
+	_, err = CanonicalizeSpiffeID("http://example.org/foo")
+	assert.EqualError(t, err, `scheme must be "spiffe://"`)
+
+	_, err = CanonicalizeSpiffeID("spiffe:///foo")
+	assert.EqualError(t, err, "trust domain is empty")
+}
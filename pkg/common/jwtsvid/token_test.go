@@ -333,6 +333,98 @@ func (s *TokenSuite) TestValidateKeyNotFound() {
 	s.Require().Nil(claims)
 }
 
+func (s *TokenSuite) TestValidateWithLeeway() {
+	now := time.Now()
+	// skewedClaims returns the claims of a token minted by a server whose
+	// clock is off by the given skew, valid for the given lifetime
+	skewedClaims := func(skew, lifetime time.Duration) jwt.Claims {
+		issuedAt := now.Add(skew)
+		return jwt.Claims{
+			Subject:   fakeSpiffeID,
+			Audience:  fakeAudience,
+			NotBefore: jwt.NewNumericDate(issuedAt),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			Expiry:    jwt.NewNumericDate(issuedAt.Add(lifetime)),
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		claims jwt.Claims
+		leeway time.Duration
+		err    string
+	}{
+		{
+			name:   "minted ahead within default leeway",
+			claims: skewedClaims(30*time.Second, time.Hour),
+			leeway: DefaultLeeway,
+		},
+		{
+			name:   "minted ahead beyond default leeway",
+			claims: skewedClaims(90*time.Second, time.Hour),
+			leeway: DefaultLeeway,
+			err:    "token is not valid yet",
+		},
+		{
+			name:   "minted ahead within configured leeway",
+			claims: skewedClaims(90*time.Second, time.Hour),
+			leeway: 2 * time.Minute,
+		},
+		{
+			name:   "minted ahead beyond configured leeway",
+			claims: skewedClaims(3*time.Minute, time.Hour),
+			leeway: 2 * time.Minute,
+			err:    "token is not valid yet",
+		},
+		{
+			name:   "expired within configured leeway",
+			claims: skewedClaims(-time.Hour-90*time.Second, time.Hour),
+			leeway: 2 * time.Minute,
+		},
+		{
+			name:   "expired beyond configured leeway",
+			claims: skewedClaims(-time.Hour-3*time.Minute, time.Hour),
+			leeway: 2 * time.Minute,
+			err:    "token has expired",
+		},
+		{
+			name:   "expired within leeway above the cap",
+			claims: skewedClaims(-time.Hour-4*time.Minute, time.Hour),
+			leeway: time.Hour,
+		},
+		{
+			name:   "expired beyond the cap",
+			claims: skewedClaims(-time.Hour-MaxLeeway-time.Minute, time.Hour),
+			leeway: time.Hour,
+			err:    "token has expired",
+		},
+		{
+			name:   "negative leeway is ignored",
+			claims: skewedClaims(time.Second, time.Hour),
+			leeway: -time.Hour,
+			err:    "token is not valid yet",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase // alias loop variable as it is used in the closure
+		s.T().Run(testCase.name, func(t *testing.T) {
+			token := s.signToken(jose.ES256, jose.JSONWebKey{Key: ec256Key, KeyID: "ec256Key"}, testCase.claims)
+
+			spiffeID, claims, err := ValidateTokenWithLeeway(ctx, token, s.bundle, fakeAudience, testCase.leeway)
+			if testCase.err != "" {
+				require.EqualError(t, err, testCase.err)
+				require.Empty(t, spiffeID)
+				require.Nil(t, claims)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, fakeSpiffeID, spiffeID)
+			require.NotEmpty(t, claims)
+		})
+	}
+}
+
 func (s *TokenSuite) signToken(alg jose.SignatureAlgorithm, key interface{}, claims jwt.Claims) string {
 	signer, err := jose.NewSigner(
 		jose.SigningKey{
@@ -12,6 +12,16 @@ import (
 	"gopkg.in/square/go-jose.v2/jwt"
 )
 
+const (
+	// DefaultLeeway is the tolerance for clock skew applied when validating
+	// the time based claims of a token.
+	DefaultLeeway = jwt.DefaultLeeway
+
+	// MaxLeeway caps the clock skew tolerance so that expired tokens are never
+	// accepted for long, regardless of configuration.
+	MaxLeeway = 5 * time.Minute
+)
+
 type KeyStore interface {
 	FindPublicKey(ctx context.Context, trustDomainID, kid string) (crypto.PublicKey, error)
 }
@@ -39,6 +49,20 @@ func (t *keyStore) FindPublicKey(ctx context.Context, trustDomainID, keyID strin
 }
 
 func ValidateToken(ctx context.Context, token string, keyStore KeyStore, audience []string) (string, map[string]interface{}, error) {
+	return ValidateTokenWithLeeway(ctx, token, keyStore, audience, DefaultLeeway)
+}
+
+// ValidateTokenWithLeeway validates the token like ValidateToken, tolerating
+// the given clock skew when validating the "nbf", "exp" and "iat" claims. The
+// leeway is capped at MaxLeeway.
+func ValidateTokenWithLeeway(ctx context.Context, token string, keyStore KeyStore, audience []string, leeway time.Duration) (string, map[string]interface{}, error) {
+	switch {
+	case leeway < 0:
+		leeway = 0
+	case leeway > MaxLeeway:
+		leeway = MaxLeeway
+	}
+
 	tok, err := jwt.ParseSigned(token)
 	if err != nil {
 		return "", nil, errs.New("unable to parse JWT token")
@@ -95,14 +119,18 @@ func ValidateToken(ctx context.Context, token string, keyStore KeyStore, audienc
 
 	// Now that the signature over the claims has been verified, validate the
 	// standard claims.
-	if err := claims.Validate(jwt.Expected{
+	if err := claims.ValidateWithLeeway(jwt.Expected{
 		Audience: audience,
 		Time:     time.Now(),
-	}); err != nil {
+	}, leeway); err != nil {
 		// Convert expected validation errors for pretty errors
 		switch err {
 		case jwt.ErrExpired:
 			err = errs.New("token has expired")
+		case jwt.ErrNotValidYet:
+			err = errs.New("token is not valid yet")
+		case jwt.ErrIssuedInTheFuture:
+			err = errs.New("token issued in the future")
 		case jwt.ErrInvalidAudience:
 			err = errs.New("expected audience in %q (audience=%q)", audience, claims.Audience)
 		default:
@@ -134,6 +134,12 @@ func (l *linuxWatcher) PID() int32 {
 	return l.pid
 }
 
+// StartTime returns the start time of the watched process, as reported by
+// proc. Together with the PID it uniquely identifies the process.
+func (l *linuxWatcher) StartTime() string {
+	return l.starttime
+}
+
 func parseTaskStat(stat string) ([]string, error) {
 	b := strings.IndexByte(stat, '(')
 	e := strings.LastIndexByte(stat, ')')
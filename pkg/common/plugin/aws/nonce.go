@@ -0,0 +1,51 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const (
+	// NonceHeader is the header of the sts:GetCallerIdentity request that
+	// carries the nonce issued by the server
+	NonceHeader = "X-Spire-Aws-Iid-Nonce"
+
+	// STSEndpoint is the endpoint the sts:GetCallerIdentity request is
+	// signed for and sent to
+	STSEndpoint = "https://sts.amazonaws.com/"
+
+	// STSRegion is the region the sts:GetCallerIdentity request is signed
+	// for, i.e. the region of the global STS endpoint
+	STSRegion = "us-east-1"
+
+	// GetCallerIdentityBody is the body of the sts:GetCallerIdentity request
+	GetCallerIdentityBody = "Action=GetCallerIdentity&Version=2011-06-15"
+)
+
+// NonceChallenge is the challenge issued by the server when it requires a
+// nonce to attest the agent.
+type NonceChallenge struct {
+	Nonce string `json:"nonce"`
+}
+
+// NonceResponse is the response of the agent to a NonceChallenge, i.e. the
+// headers of an sts:GetCallerIdentity request carrying the nonce, signed
+// with the credentials of the instance profile role of the instance. The
+// server sends the request to STS to learn the role session that signed
+// it, which EC2 names after the instance ID.
+type NonceResponse struct {
+	Headers http.Header `json:"headers"`
+}
+
+// NewGetCallerIdentityRequest returns an unsigned sts:GetCallerIdentity
+// request to the given endpoint, carrying the given nonce.
+func NewGetCallerIdentityRequest(ctx context.Context, endpoint, nonce string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(GetCallerIdentityBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	req.Header.Set(NonceHeader, nonce)
+	return req, nil
+}
@@ -4,6 +4,7 @@ package selector
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/spiffe/spire/proto/spire/common"
@@ -43,3 +44,55 @@ func Validate(s *common.Selector) error {
 	}
 	return nil
 }
+
+// NewInt returns a selector with the value "<name>:<v>" that also carries v
+// as its typed value.
+func NewInt(selectorType, name string, v int64) *common.Selector {
+	return &common.Selector{
+		Type:       selectorType,
+		Value:      name + Delimiter + strconv.FormatInt(v, 10),
+		TypedValue: &common.Selector_IntValue{IntValue: v},
+	}
+}
+
+// NewBool returns a selector with the value "<name>:<v>" that also carries v
+// as its typed value.
+func NewBool(selectorType, name string, v bool) *common.Selector {
+	return &common.Selector{
+		Type:       selectorType,
+		Value:      name + Delimiter + strconv.FormatBool(v),
+		TypedValue: &common.Selector_BoolValue{BoolValue: v},
+	}
+}
+
+// IntValue returns the integer value of the selector. The typed value is used
+// if set. Otherwise, for selectors that do not carry one (e.g. those read
+// back from the datastore or produced by older plugins), the last
+// colon-delimited part of the string value is parsed.
+func IntValue(s *common.Selector) (int64, bool) {
+	if typed, ok := s.TypedValue.(*common.Selector_IntValue); ok {
+		return typed.IntValue, true
+	}
+	v, err := strconv.ParseInt(lastPart(s.Value), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// BoolValue returns the boolean value of the selector. Like IntValue, it
+// falls back to parsing the string value when no typed value is set.
+func BoolValue(s *common.Selector) (bool, bool) {
+	if typed, ok := s.TypedValue.(*common.Selector_BoolValue); ok {
+		return typed.BoolValue, true
+	}
+	v, err := strconv.ParseBool(lastPart(s.Value))
+	if err != nil {
+		return false, false
+	}
+	return v, true
+}
+
+func lastPart(value string) string {
+	return value[strings.LastIndex(value, Delimiter)+1:]
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestValiate(t *testing.T) {
@@ -39,3 +41,62 @@ func TestValiate(t *testing.T) {
 		})
 	}
 }
+
+func TestTypedSelectorRoundTrip(t *testing.T) {
+	for _, s := range []*common.Selector{
+		NewInt("aws_iid", "cpucount", 4),
+		NewBool("type", "enabled", true),
+	} {
+		data, err := proto.Marshal(s)
+		require.NoError(t, err)
+
+		decoded := new(common.Selector)
+		require.NoError(t, proto.Unmarshal(data, decoded))
+		assert.True(t, proto.Equal(s, decoded))
+	}
+
+	s := NewInt("aws_iid", "cpucount", 4)
+	assert.Equal(t, "cpucount:4", s.Value)
+	v, ok := IntValue(s)
+	assert.True(t, ok)
+	assert.Equal(t, int64(4), v)
+
+	s = NewBool("type", "enabled", true)
+	assert.Equal(t, "enabled:true", s.Value)
+	b, ok := BoolValue(s)
+	assert.True(t, ok)
+	assert.True(t, b)
+}
+
+func TestTypedSelectorBackwardCompatibility(t *testing.T) {
+	// The string form is unchanged by the typed value
+	typed := NewInt("aws_iid", "cpucount", 4)
+	assert.Equal(t, &Selector{Type: "aws_iid", Value: "cpucount:4"}, New(typed))
+
+	// Selectors without a typed value, e.g. serialized before typed values
+	// existed, decode without one and fall back to the string value
+	legacy := &common.Selector{Type: "aws_iid", Value: "cpucount:8"}
+	data, err := proto.Marshal(legacy)
+	require.NoError(t, err)
+	decoded := new(common.Selector)
+	require.NoError(t, proto.Unmarshal(data, decoded))
+	assert.Nil(t, decoded.TypedValue)
+
+	v, ok := IntValue(decoded)
+	assert.True(t, ok)
+	assert.Equal(t, int64(8), v)
+
+	_, ok = IntValue(&common.Selector{Type: "aws_iid", Value: "tag:name:web"})
+	assert.False(t, ok)
+	_, ok = BoolValue(&common.Selector{Type: "aws_iid", Value: "cpucount:8"})
+	assert.False(t, ok)
+
+	// The typed value takes precedence over the string value
+	v, ok = IntValue(&common.Selector{
+		Type:       "aws_iid",
+		Value:      "cpucount:8",
+		TypedValue: &common.Selector_IntValue{IntValue: 2},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), v)
+}
@@ -20,14 +20,14 @@ func WithMetrics(km keymanager.KeyManager, metrics telemetry.Metrics) keymanager
 	}
 }
 
-func (w keyManagerWrapper) GenerateKey(ctx context.Context) (_ crypto.Signer, err error) {
+func (w keyManagerWrapper) GenerateKey(ctx context.Context, currentKey crypto.Signer) (_ crypto.Signer, err error) {
 	defer StartGenerateKeyPairCall(w.m).Done(&err)
-	return w.KeyManager.GenerateKey(ctx)
+	return w.KeyManager.GenerateKey(ctx, currentKey)
 }
 
-func (w keyManagerWrapper) GetKey(ctx context.Context) (_ crypto.Signer, err error) {
+func (w keyManagerWrapper) GetKeys(ctx context.Context) (_ []crypto.Signer, err error) {
 	defer StartFetchPrivateKeyCall(w.m).Done(&err)
-	return w.KeyManager.GetKey(ctx)
+	return w.KeyManager.GetKeys(ctx)
 }
 
 func (w keyManagerWrapper) SetKey(ctx context.Context, key crypto.Signer) (err error) {
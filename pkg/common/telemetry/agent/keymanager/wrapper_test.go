@@ -14,11 +14,11 @@ import (
 
 type fakeKeyManager struct{}
 
-func (fakeKeyManager) GenerateKey(ctx context.Context) (crypto.Signer, error) {
+func (fakeKeyManager) GenerateKey(ctx context.Context, currentKey crypto.Signer) (crypto.Signer, error) {
 	return nil, nil
 }
 
-func (fakeKeyManager) GetKey(ctx context.Context) (crypto.Signer, error) {
+func (fakeKeyManager) GetKeys(ctx context.Context) ([]crypto.Signer, error) {
 	return nil, nil
 }
 
@@ -36,14 +36,14 @@ func TestWithMetrics(t *testing.T) {
 		{
 			key: "agent_key_manager.generate_key_pair",
 			call: func() error {
-				_, err := w.GenerateKey(context.Background())
+				_, err := w.GenerateKey(context.Background(), nil)
 				return err
 			},
 		},
 		{
 			key: "agent_key_manager.fetch_private_key",
 			call: func() error {
-				_, err := w.GetKey(context.Background())
+				_, err := w.GetKeys(context.Background())
 				return err
 			},
 		},
@@ -13,4 +13,10 @@ func StartRotateAgentSVIDCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.AgentSVID, telemetry.Rotate)
 }
 
+// StartReattestAgentSVIDCall return metric for Agent's SVID
+// reattestation.
+func StartReattestAgentSVIDCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.AgentSVID, telemetry.Reattest)
+}
+
 // End Call Counters
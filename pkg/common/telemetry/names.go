@@ -22,6 +22,10 @@ const (
 	// to add clarity
 	Attest = "attest"
 
+	// BatchSet functionality related to setting many entities at once; should
+	// be used with other tags to add clarity
+	BatchSet = "batch_set"
+
 	// Create functionality related to creating some entity; should be used with other tags
 	// to add clarity
 	Create = "create"
@@ -75,9 +79,21 @@ const (
 	// to add clarity
 	Push = "push"
 
+	// Refresh functionality related to refreshing some entity; should be used
+	// with other tags to add clarity
+	Refresh = "refresh"
+
 	// Reload functionality related to reloading of a cache
 	Reload = "reload"
 
+	// Reattest functionality related to attesting again an already attested
+	// node; should be used with other tags to add clarity
+	Reattest = "reattest"
+
+	// Restore functionality related to restoring some deleted entity; should be used
+	// with other tags to add clarity
+	Restore = "restore"
+
 	// Rotate functionality related to rotation of SVID; should be used with other tags
 	// to add clarity
 	Rotate = "rotate"
@@ -94,6 +110,10 @@ const (
 	// (server)
 	SignData = "sign_data"
 
+	// SoftDelete functionality related to deleting some entity that can be restored
+	// later; should be used with other tags to add clarity
+	SoftDelete = "soft_delete"
+
 	// StorePrivateKey related to storing a private key in the KeyManager plugin interface
 	// (agent or server)
 	StorePrivateKey = "store_private_key"
@@ -132,6 +152,10 @@ const (
 	// CGroupPath tags a linux CGroup path, most likely for use in attestation
 	CGroupPath = "cgroup_path"
 
+	// CollidingSPIFFEID tags the SPIFFE ID of an entry that collides with
+	// another entry
+	CollidingSPIFFEID = "colliding_spiffe_id"
+
 	// Connection functionality related to some connection; should be used with other tags
 	// to add clarity
 	Connection = "connection"
@@ -152,6 +176,9 @@ const (
 	// DatabaseType labels a database type (MySQL, postgres...)
 	DatabaseType = "db_type"
 
+	// DeletedRegistrationEntry tags a soft-deleted registration entry
+	DeletedRegistrationEntry = "deleted_registration_entry"
+
 	// DeprecatedServiceName tags the deprecated service name
 	DeprecatedServiceName = "deprecated_service_name"
 
@@ -281,6 +308,9 @@ const (
 	// Selectors tags some group of registration selector
 	Selectors = "selectors"
 
+	// SelectorTypes tags some group of selector types
+	SelectorTypes = "selector_types"
+
 	// SelectorsAdded labels some count of selectors that have been added to an entity
 	SelectorsAdded = "selectors_added"
 
@@ -494,6 +524,9 @@ const (
 	// AgentKeyManager attached to all operations related to the Agent KeyManger interface
 	AgentKeyManager = "agent_key_manager"
 
+	// AttestationAPI functionality related to the agent attestation endpoints
+	AttestationAPI = "attestation_api"
+
 	// AuthorizeCall functionality related to authorizing an incoming call
 	AuthorizeCall = "authorize_call"
 
@@ -612,6 +645,9 @@ const (
 	// ServerKeyManager attached to all operations related to the server KeyManager interface
 	ServerKeyManager = "server_key_manager"
 
+	// SimulationAPI functionality related to simulation endpoints
+	SimulationAPI = "simulation_api"
+
 	// StreamSecrets functionality related to streaming secrets
 	StreamSecrets = "stream_secrets"
 
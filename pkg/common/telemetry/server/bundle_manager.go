@@ -19,3 +19,21 @@ func IncrBundleManagerUpdateFederatedBundleCounter(m telemetry.Metrics, trustDom
 }
 
 // End Counters
+
+// Gauge (remember previous value set)
+
+// SetBundleManagerFederatedBundleRefreshGauge sets gauge for the number of
+// seconds until the bundle manager refreshes the bundle of a specific
+// federated Trust Domain
+func SetBundleManagerFederatedBundleRefreshGauge(m telemetry.Metrics, trustDomain string, val float32) {
+	m.SetGaugeWithLabels([]string{
+		telemetry.BundleManager,
+		telemetry.FederatedBundle,
+		telemetry.Refresh,
+		telemetry.Seconds,
+	}, val, []telemetry.Label{
+		{Name: telemetry.TrustDomainID, Value: trustDomain},
+	})
+}
+
+// End Gauge
@@ -43,6 +43,12 @@ func StartGetNodeSelectorsCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Selectors, telemetry.Fetch)
 }
 
+// StartBatchSetNodeSelectorsCall return metric
+// for server's datastore, on setting selectors for many nodes.
+func StartBatchSetNodeSelectorsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Selectors, telemetry.BatchSet)
+}
+
 // StartListNodeSelectorsCall return metric
 // for server's datastore, on getting selectors for a node.
 func StartListNodeSelectorsCall(m telemetry.Metrics) *telemetry.CallCounter {
@@ -43,6 +43,37 @@ func StartPruneRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.Prune)
 }
 
+// StartRestoreRegistrationCall return metric
+// for server's datastore, on restoring a soft-deleted registration.
+func StartRestoreRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.Restore)
+}
+
+// StartSoftDeleteRegistrationCall return metric
+// for server's datastore, on soft-deleting a registration.
+func StartSoftDeleteRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.SoftDelete)
+}
+
+// StartListDeletedRegistrationCall return metric
+// for server's datastore, on listing soft-deleted registrations.
+func StartListDeletedRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.DeletedRegistrationEntry, telemetry.List)
+}
+
+// StartPruneDeletedRegistrationCall return metric
+// for server's datastore, on pruning soft-deleted registrations.
+func StartPruneDeletedRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.DeletedRegistrationEntry, telemetry.Prune)
+}
+
+// StartUpdateEntriesFederatesWithCall return metric
+// for server's datastore, on updating the federated bundles of the
+// registrations matching some selectors.
+func StartUpdateEntriesFederatesWithCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.FederatedBundle, telemetry.Update)
+}
+
 // StartUpdateRegistrationCall return metric
 // for server's datastore, on updating a registration.
 func StartUpdateRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
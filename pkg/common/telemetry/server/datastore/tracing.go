@@ -0,0 +1,229 @@
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/tracing"
+	"github.com/spiffe/spire/pkg/server/plugin/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+// WithTracing wraps a datastore interface and records a span for each call.
+func WithTracing(ds datastore.DataStore) datastore.DataStore {
+	return tracingWrapper{ds: ds}
+}
+
+type tracingWrapper struct {
+	ds datastore.DataStore
+}
+
+func (w tracingWrapper) AppendBundle(ctx context.Context, req *datastore.AppendBundleRequest) (_ *datastore.AppendBundleResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.AppendBundle")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.AppendBundle(ctx, req)
+}
+
+func (w tracingWrapper) BatchSetNodeSelectors(ctx context.Context, req *datastore.BatchSetNodeSelectorsRequest) (_ *datastore.BatchSetNodeSelectorsResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.BatchSetNodeSelectors")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.BatchSetNodeSelectors(ctx, req)
+}
+
+func (w tracingWrapper) CreateAttestedNode(ctx context.Context, node *common.AttestedNode) (_ *common.AttestedNode, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.CreateAttestedNode")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.CreateAttestedNode(ctx, node)
+}
+
+func (w tracingWrapper) CreateBundle(ctx context.Context, bundle *common.Bundle) (_ *common.Bundle, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.CreateBundle")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.CreateBundle(ctx, bundle)
+}
+
+func (w tracingWrapper) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.CreateJoinToken")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.CreateJoinToken(ctx, token)
+}
+
+func (w tracingWrapper) CreateRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (_ *common.RegistrationEntry, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.CreateRegistrationEntry")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.CreateRegistrationEntry(ctx, entry)
+}
+
+func (w tracingWrapper) DeleteAttestedNode(ctx context.Context, spiffeID string) (_ *common.AttestedNode, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.DeleteAttestedNode")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.DeleteAttestedNode(ctx, spiffeID)
+}
+
+func (w tracingWrapper) DeleteBundle(ctx context.Context, trustDomain string, mode datastore.DeleteMode) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.DeleteBundle")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.DeleteBundle(ctx, trustDomain, mode)
+}
+
+func (w tracingWrapper) DeleteJoinToken(ctx context.Context, token string) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.DeleteJoinToken")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.DeleteJoinToken(ctx, token)
+}
+
+func (w tracingWrapper) DeleteRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.DeleteRegistrationEntry")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.DeleteRegistrationEntry(ctx, entryID)
+}
+
+func (w tracingWrapper) FetchAttestedNode(ctx context.Context, spiffeID string) (_ *common.AttestedNode, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.FetchAttestedNode")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.FetchAttestedNode(ctx, spiffeID)
+}
+
+func (w tracingWrapper) FetchBundle(ctx context.Context, trustDomain string) (_ *common.Bundle, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.FetchBundle")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.FetchBundle(ctx, trustDomain)
+}
+
+func (w tracingWrapper) FetchJoinToken(ctx context.Context, token string) (_ *datastore.JoinToken, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.FetchJoinToken")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.FetchJoinToken(ctx, token)
+}
+
+func (w tracingWrapper) FetchRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.FetchRegistrationEntry")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.FetchRegistrationEntry(ctx, entryID)
+}
+
+func (w tracingWrapper) GetNodeSelectors(ctx context.Context, req *datastore.GetNodeSelectorsRequest) (_ *datastore.GetNodeSelectorsResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.GetNodeSelectors")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.GetNodeSelectors(ctx, req)
+}
+
+func (w tracingWrapper) ListAttestedNodes(ctx context.Context, req *datastore.ListAttestedNodesRequest) (_ *datastore.ListAttestedNodesResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.ListAttestedNodes")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.ListAttestedNodes(ctx, req)
+}
+
+func (w tracingWrapper) ListBundles(ctx context.Context, req *datastore.ListBundlesRequest) (_ *datastore.ListBundlesResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.ListBundles")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.ListBundles(ctx, req)
+}
+
+func (w tracingWrapper) ListDeletedRegistrationEntries(ctx context.Context) (_ []*datastore.DeletedRegistrationEntry, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.ListDeletedRegistrationEntries")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.ListDeletedRegistrationEntries(ctx)
+}
+
+func (w tracingWrapper) ListNodeSelectors(ctx context.Context, req *datastore.ListNodeSelectorsRequest) (_ *datastore.ListNodeSelectorsResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.ListNodeSelectors")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.ListNodeSelectors(ctx, req)
+}
+
+func (w tracingWrapper) ListRegistrationEntries(ctx context.Context, req *datastore.ListRegistrationEntriesRequest) (_ *datastore.ListRegistrationEntriesResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.ListRegistrationEntries")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.ListRegistrationEntries(ctx, req)
+}
+
+func (w tracingWrapper) CountAttestedNodes(ctx context.Context) (_ int32, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.CountAttestedNodes")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.CountAttestedNodes(ctx)
+}
+
+func (w tracingWrapper) CountBundles(ctx context.Context) (_ int32, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.CountBundles")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.CountBundles(ctx)
+}
+
+func (w tracingWrapper) CountRegistrationEntries(ctx context.Context) (_ int32, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.CountRegistrationEntries")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.CountRegistrationEntries(ctx)
+}
+
+func (w tracingWrapper) PruneBundle(ctx context.Context, req *datastore.PruneBundleRequest) (_ *datastore.PruneBundleResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.PruneBundle")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.PruneBundle(ctx, req)
+}
+
+func (w tracingWrapper) PruneJoinTokens(ctx context.Context, expiresBefore time.Time) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.PruneJoinTokens")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.PruneJoinTokens(ctx, expiresBefore)
+}
+
+func (w tracingWrapper) PruneDeletedRegistrationEntries(ctx context.Context, deletedBefore time.Time) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.PruneDeletedRegistrationEntries")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.PruneDeletedRegistrationEntries(ctx, deletedBefore)
+}
+
+func (w tracingWrapper) PruneRegistrationEntries(ctx context.Context, req *datastore.PruneRegistrationEntriesRequest) (_ *datastore.PruneRegistrationEntriesResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.PruneRegistrationEntries")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.PruneRegistrationEntries(ctx, req)
+}
+
+func (w tracingWrapper) RestoreRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.RestoreRegistrationEntry")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.RestoreRegistrationEntry(ctx, entryID)
+}
+
+func (w tracingWrapper) SetBundle(ctx context.Context, req *datastore.SetBundleRequest) (_ *datastore.SetBundleResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.SetBundle")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.SetBundle(ctx, req)
+}
+
+func (w tracingWrapper) SetNodeSelectors(ctx context.Context, req *datastore.SetNodeSelectorsRequest) (_ *datastore.SetNodeSelectorsResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.SetNodeSelectors")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.SetNodeSelectors(ctx, req)
+}
+
+func (w tracingWrapper) SoftDeleteRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.SoftDeleteRegistrationEntry")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.SoftDeleteRegistrationEntry(ctx, entryID)
+}
+
+func (w tracingWrapper) UpdateAttestedNode(ctx context.Context, req *datastore.UpdateAttestedNodeRequest) (_ *datastore.UpdateAttestedNodeResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.UpdateAttestedNode")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.UpdateAttestedNode(ctx, req)
+}
+
+func (w tracingWrapper) UpdateBundle(ctx context.Context, req *datastore.UpdateBundleRequest) (_ *datastore.UpdateBundleResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.UpdateBundle")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.UpdateBundle(ctx, req)
+}
+
+func (w tracingWrapper) UpdateEntriesFederatesWith(ctx context.Context, req *datastore.UpdateEntriesFederatesWithRequest) (_ *datastore.UpdateEntriesFederatesWithResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.UpdateEntriesFederatesWith")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.UpdateEntriesFederatesWith(ctx, req)
+}
+
+func (w tracingWrapper) UpdateRegistrationEntry(ctx context.Context, req *datastore.UpdateRegistrationEntryRequest) (_ *datastore.UpdateRegistrationEntryResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "datastore.UpdateRegistrationEntry")
+	defer tracing.EndSpan(span, &err)
+	return w.ds.UpdateRegistrationEntry(ctx, req)
+}
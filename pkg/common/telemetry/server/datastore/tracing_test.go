@@ -0,0 +1,53 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/spiffe/spire/test/fakes/faketracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestWithTracing(t *testing.T) {
+	ds := &fakeDataStore{}
+	w := WithTracing(ds)
+
+	wv := reflect.ValueOf(w)
+	wt := reflect.TypeOf(w)
+	for i := 0; i < wt.NumMethod(); i++ {
+		method := wt.Method(i)
+		methodValue := wv.Method(i)
+
+		doCall := func(err error) {
+			ds.SetError(err)
+			args := []reflect.Value{reflect.ValueOf(context.Background())}
+			for i := 1; i < methodValue.Type().NumIn(); i++ {
+				args = append(args, reflect.New(methodValue.Type().In(i)).Elem())
+			}
+			methodValue.Call(args)
+		}
+
+		t.Run(method.Name+"(success)", func(t *testing.T) {
+			exporter := faketracing.New(t)
+			doCall(nil)
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "datastore."+method.Name, spans[0].Name)
+			assert.Equal(t, codes.Unset, spans[0].StatusCode)
+		})
+
+		t.Run(method.Name+"(failure)", func(t *testing.T) {
+			exporter := faketracing.New(t)
+			doCall(errors.New("ohno"))
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "datastore."+method.Name, spans[0].Name)
+			assert.Equal(t, codes.Error, spans[0].StatusCode)
+			assert.Equal(t, "ohno", spans[0].StatusMessage)
+		})
+	}
+}
@@ -27,6 +27,12 @@ func (w metricsWrapper) AppendBundle(ctx context.Context, req *datastore.AppendB
 	return w.ds.AppendBundle(ctx, req)
 }
 
+func (w metricsWrapper) BatchSetNodeSelectors(ctx context.Context, req *datastore.BatchSetNodeSelectorsRequest) (_ *datastore.BatchSetNodeSelectorsResponse, err error) {
+	callCounter := StartBatchSetNodeSelectorsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.BatchSetNodeSelectors(ctx, req)
+}
+
 func (w metricsWrapper) CreateAttestedNode(ctx context.Context, node *common.AttestedNode) (_ *common.AttestedNode, err error) {
 	callCounter := StartCreateNodeCall(w.m)
 	defer callCounter.Done(&err)
@@ -117,6 +123,12 @@ func (w metricsWrapper) ListBundles(ctx context.Context, req *datastore.ListBund
 	return w.ds.ListBundles(ctx, req)
 }
 
+func (w metricsWrapper) ListDeletedRegistrationEntries(ctx context.Context) (_ []*datastore.DeletedRegistrationEntry, err error) {
+	callCounter := StartListDeletedRegistrationCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListDeletedRegistrationEntries(ctx)
+}
+
 func (w metricsWrapper) ListNodeSelectors(ctx context.Context, req *datastore.ListNodeSelectorsRequest) (_ *datastore.ListNodeSelectorsResponse, err error) {
 	callCounter := StartListNodeSelectorsCall(w.m)
 	defer callCounter.Done(&err)
@@ -159,12 +171,24 @@ func (w metricsWrapper) PruneJoinTokens(ctx context.Context, expiresBefore time.
 	return w.ds.PruneJoinTokens(ctx, expiresBefore)
 }
 
+func (w metricsWrapper) PruneDeletedRegistrationEntries(ctx context.Context, deletedBefore time.Time) (err error) {
+	callCounter := StartPruneDeletedRegistrationCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.PruneDeletedRegistrationEntries(ctx, deletedBefore)
+}
+
 func (w metricsWrapper) PruneRegistrationEntries(ctx context.Context, req *datastore.PruneRegistrationEntriesRequest) (_ *datastore.PruneRegistrationEntriesResponse, err error) {
 	callCounter := StartPruneRegistrationCall(w.m)
 	defer callCounter.Done(&err)
 	return w.ds.PruneRegistrationEntries(ctx, req)
 }
 
+func (w metricsWrapper) RestoreRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
+	callCounter := StartRestoreRegistrationCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.RestoreRegistrationEntry(ctx, entryID)
+}
+
 func (w metricsWrapper) SetBundle(ctx context.Context, req *datastore.SetBundleRequest) (_ *datastore.SetBundleResponse, err error) {
 	callCounter := StartSetBundleCall(w.m)
 	defer callCounter.Done(&err)
@@ -177,6 +201,12 @@ func (w metricsWrapper) SetNodeSelectors(ctx context.Context, req *datastore.Set
 	return w.ds.SetNodeSelectors(ctx, req)
 }
 
+func (w metricsWrapper) SoftDeleteRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
+	callCounter := StartSoftDeleteRegistrationCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.SoftDeleteRegistrationEntry(ctx, entryID)
+}
+
 func (w metricsWrapper) UpdateAttestedNode(ctx context.Context, req *datastore.UpdateAttestedNodeRequest) (_ *datastore.UpdateAttestedNodeResponse, err error) {
 	callCounter := StartUpdateNodeCall(w.m)
 	defer callCounter.Done(&err)
@@ -189,6 +219,12 @@ func (w metricsWrapper) UpdateBundle(ctx context.Context, req *datastore.UpdateB
 	return w.ds.UpdateBundle(ctx, req)
 }
 
+func (w metricsWrapper) UpdateEntriesFederatesWith(ctx context.Context, req *datastore.UpdateEntriesFederatesWithRequest) (_ *datastore.UpdateEntriesFederatesWithResponse, err error) {
+	callCounter := StartUpdateEntriesFederatesWithCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.UpdateEntriesFederatesWith(ctx, req)
+}
+
 func (w metricsWrapper) UpdateRegistrationEntry(ctx context.Context, req *datastore.UpdateRegistrationEntryRequest) (_ *datastore.UpdateRegistrationEntryResponse, err error) {
 	callCounter := StartUpdateRegistrationCall(w.m)
 	defer callCounter.Done(&err)
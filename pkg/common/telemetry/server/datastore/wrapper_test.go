@@ -39,6 +39,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.bundle.append",
 			methodName: "AppendBundle",
 		},
+		{
+			key:        "datastore.node.selectors.batch_set",
+			methodName: "BatchSetNodeSelectors",
+		},
 		{
 			key:        "datastore.node.count",
 			methodName: "CountAttestedNodes",
@@ -111,6 +115,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.bundle.list",
 			methodName: "ListBundles",
 		},
+		{
+			key:        "datastore.deleted_registration_entry.list",
+			methodName: "ListDeletedRegistrationEntries",
+		},
 		{
 			key:        "datastore.node.selectors.list",
 			methodName: "ListNodeSelectors",
@@ -127,10 +135,18 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.join_token.prune",
 			methodName: "PruneJoinTokens",
 		},
+		{
+			key:        "datastore.deleted_registration_entry.prune",
+			methodName: "PruneDeletedRegistrationEntries",
+		},
 		{
 			key:        "datastore.registration_entry.prune",
 			methodName: "PruneRegistrationEntries",
 		},
+		{
+			key:        "datastore.registration_entry.restore",
+			methodName: "RestoreRegistrationEntry",
+		},
 		{
 			key:        "datastore.bundle.set",
 			methodName: "SetBundle",
@@ -139,6 +155,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.node.selectors.set",
 			methodName: "SetNodeSelectors",
 		},
+		{
+			key:        "datastore.registration_entry.soft_delete",
+			methodName: "SoftDeleteRegistrationEntry",
+		},
 		{
 			key:        "datastore.node.update",
 			methodName: "UpdateAttestedNode",
@@ -147,6 +167,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.bundle.update",
 			methodName: "UpdateBundle",
 		},
+		{
+			key:        "datastore.registration_entry.federated_bundle.update",
+			methodName: "UpdateEntriesFederatesWith",
+		},
 		{
 			key:        "datastore.registration_entry.update",
 			methodName: "UpdateRegistrationEntry",
@@ -235,6 +259,10 @@ func (ds *fakeDataStore) AppendBundle(context.Context, *datastore.AppendBundleRe
 	return &datastore.AppendBundleResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) BatchSetNodeSelectors(context.Context, *datastore.BatchSetNodeSelectorsRequest) (*datastore.BatchSetNodeSelectorsResponse, error) {
+	return &datastore.BatchSetNodeSelectorsResponse{}, ds.err
+}
+
 func (ds *fakeDataStore) CountAttestedNodes(context.Context) (int32, error) {
 	return 0, ds.err
 }
@@ -307,6 +335,10 @@ func (ds *fakeDataStore) ListBundles(context.Context, *datastore.ListBundlesRequ
 	return &datastore.ListBundlesResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) ListDeletedRegistrationEntries(context.Context) ([]*datastore.DeletedRegistrationEntry, error) {
+	return []*datastore.DeletedRegistrationEntry{}, ds.err
+}
+
 func (ds *fakeDataStore) ListNodeSelectors(context.Context, *datastore.ListNodeSelectorsRequest) (*datastore.ListNodeSelectorsResponse, error) {
 	return &datastore.ListNodeSelectorsResponse{}, ds.err
 }
@@ -323,10 +355,18 @@ func (ds *fakeDataStore) PruneJoinTokens(context.Context, time.Time) error {
 	return ds.err
 }
 
+func (ds *fakeDataStore) PruneDeletedRegistrationEntries(context.Context, time.Time) error {
+	return ds.err
+}
+
 func (ds *fakeDataStore) PruneRegistrationEntries(context.Context, *datastore.PruneRegistrationEntriesRequest) (*datastore.PruneRegistrationEntriesResponse, error) {
 	return &datastore.PruneRegistrationEntriesResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) RestoreRegistrationEntry(context.Context, string) (*common.RegistrationEntry, error) {
+	return &common.RegistrationEntry{}, ds.err
+}
+
 func (ds *fakeDataStore) SetBundle(context.Context, *datastore.SetBundleRequest) (*datastore.SetBundleResponse, error) {
 	return &datastore.SetBundleResponse{}, ds.err
 }
@@ -335,6 +375,10 @@ func (ds *fakeDataStore) SetNodeSelectors(context.Context, *datastore.SetNodeSel
 	return &datastore.SetNodeSelectorsResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) SoftDeleteRegistrationEntry(context.Context, string) (*common.RegistrationEntry, error) {
+	return &common.RegistrationEntry{}, ds.err
+}
+
 func (ds *fakeDataStore) UpdateAttestedNode(context.Context, *datastore.UpdateAttestedNodeRequest) (*datastore.UpdateAttestedNodeResponse, error) {
 	return &datastore.UpdateAttestedNodeResponse{}, ds.err
 }
@@ -343,6 +387,10 @@ func (ds *fakeDataStore) UpdateBundle(context.Context, *datastore.UpdateBundleRe
 	return &datastore.UpdateBundleResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) UpdateEntriesFederatesWith(context.Context, *datastore.UpdateEntriesFederatesWithRequest) (*datastore.UpdateEntriesFederatesWithResponse, error) {
+	return &datastore.UpdateEntriesFederatesWithResponse{}, ds.err
+}
+
 func (ds *fakeDataStore) UpdateRegistrationEntry(context.Context, *datastore.UpdateRegistrationEntryRequest) (*datastore.UpdateRegistrationEntryResponse, error) {
 	return &datastore.UpdateRegistrationEntryResponse{}, ds.err
 }
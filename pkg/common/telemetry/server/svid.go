@@ -0,0 +1,19 @@
+package server
+
+import (
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// AddSVIDTTLSample records the TTL of an SVID minted by the server, in
+// seconds, labeled by SVID type (i.e. x509 or jwt) and trust domain
+func AddSVIDTTLSample(m telemetry.Metrics, svidType, trustDomain string, ttl time.Duration) {
+	m.AddSampleWithLabels([]string{
+		telemetry.SVID,
+		telemetry.TTL,
+	}, float32(ttl.Seconds()), []telemetry.Label{
+		{Name: telemetry.SVIDType, Value: svidType},
+		{Name: telemetry.TrustDomainID, Value: trustDomain},
+	})
+}
@@ -0,0 +1,92 @@
+// Package tracing provides the OpenTelemetry tracing integration. Spans are
+// recorded through the globally registered tracer provider, which is a no-op
+// until Setup is called, so instrumented code pays next to nothing when
+// tracing is disabled.
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/spiffe/spire"
+
+// Config configures span export.
+type Config struct {
+	// OTLPEndpoint is the host:port of the OTLP/gRPC collector.
+	OTLPEndpoint string
+
+	// Insecure disables TLS on the connection to the collector.
+	Insecure bool
+
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+}
+
+// Setup installs a tracer provider that exports spans over OTLP/gRPC. The
+// returned function flushes pending spans and shuts the provider down.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	if config.OTLPEndpoint == "" {
+		return nil, errors.New("OTLP endpoint is required")
+	}
+
+	opts := []otlpgrpc.Option{
+		otlpgrpc.WithEndpoint(config.OTLPEndpoint),
+	}
+	if config.Insecure {
+		opts = append(opts, otlpgrpc.WithInsecure())
+	}
+	exporter, err := otlp.NewExporter(ctx, otlpgrpc.NewDriver(opts...))
+	if err != nil {
+		return nil, err
+	}
+
+	tp := NewTracerProvider(config.ServiceName, sdktrace.WithBatcher(exporter))
+	SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// NewTracerProvider creates a tracer provider tagged with the given service
+// name.
+func NewTracerProvider(serviceName string, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	opts = append(opts, sdktrace.WithResource(resource.NewWithAttributes(
+		semconv.ServiceNameKey.String(serviceName),
+	)))
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// SetTracerProvider registers the tracer provider used by StartSpan along
+// with the W3C trace context propagator.
+func SetTracerProvider(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+// StartSpan starts a span that is a child of the span in the context, if any.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records the error pointed to by errp, if any, and ends the span. It
+// is meant to be deferred with a pointer to a named error return value.
+func EndSpan(span trace.Span, errp *error) {
+	if errp != nil && *errp != nil {
+		span.RecordError(*errp)
+		span.SetStatus(codes.Error, (*errp).Error())
+	}
+	span.End()
+}
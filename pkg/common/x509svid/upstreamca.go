@@ -3,6 +3,7 @@ package x509svid
 import (
 	"context"
 	"crypto/x509"
+	"encoding/asn1"
 	"time"
 
 	"github.com/andres-erbsen/clock"
@@ -19,6 +20,10 @@ const (
 type UpstreamCAOptions struct {
 	Backdate time.Duration
 	Clock    clock.Clock
+
+	// PolicyIdentifiers, if set, are added to the certificate policies
+	// extension of the signed CA certificates.
+	PolicyIdentifiers []asn1.ObjectIdentifier
 }
 
 type UpstreamCA struct {
@@ -88,6 +93,7 @@ func (ca *UpstreamCA) SignCSR(ctx context.Context, csrDER []byte, preferredTTL t
 			x509.KeyUsageCRLSign,
 		BasicConstraintsValid: true,
 		IsCA:                  true,
+		PolicyIdentifiers:     ca.options.PolicyIdentifiers,
 	}
 
 	certDER, err := ca.keypair.CreateCertificate(ctx, template, csr.PublicKey)
@@ -3,6 +3,7 @@ package x509svid
 import (
 	"context"
 	"crypto/x509"
+	"encoding/asn1"
 	"net/url"
 	"testing"
 	"time"
@@ -110,3 +111,17 @@ func (s *UpstreamCASuite) TestSignCSRUsesDefaultTTLIfPreferredTTLUnset() {
 
 	s.Require().Equal(s.clock.Now().Add(DefaultUpstreamCATTL).UTC(), cert.NotAfter)
 }
+
+func (s *UpstreamCASuite) TestSignCSRWithPolicyIdentifiers() {
+	policies := []asn1.ObjectIdentifier{{2, 23, 140, 1, 2, 1}}
+	s.upstreamCA = NewUpstreamCA(s.keypair, spiffeid.RequireTrustDomainFromString("example.org"), UpstreamCAOptions{
+		Clock:             s.clock,
+		PolicyIdentifiers: policies,
+	})
+
+	csr := s.makeCSR("spiffe://example.org")
+	cert, err := s.upstreamCA.SignCSR(context.Background(), csr, 0)
+	s.Require().NoError(err)
+
+	s.Require().Equal(policies, cert.PolicyIdentifiers)
+}
@@ -4,6 +4,10 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
 )
 
 var (
@@ -33,3 +37,89 @@ func getMaxUint128() *big.Int {
 	}
 	return max
 }
+
+// Serial number strategies supported by NewSerialNumberGenerator.
+const (
+	// SerialNumberRandom generates random 128-bit serial numbers.
+	SerialNumberRandom = "random"
+
+	// SerialNumberMonotonic generates increasing serial numbers, made of a
+	// nanosecond timestamp followed by 64 random bits.
+	SerialNumberMonotonic = "monotonic"
+
+	// SerialNumberUUID generates serial numbers that are random (version 4)
+	// UUIDs.
+	SerialNumberUUID = "uuid"
+)
+
+// SerialNumberGenerator generates certificate serial numbers.
+type SerialNumberGenerator interface {
+	NewSerialNumber() (*big.Int, error)
+}
+
+// NewSerialNumberGenerator returns the generator for the given strategy. The
+// random strategy is used if the strategy is empty. All of the strategies
+// include at least 64 bits of output from a CSPRNG, as required by the
+// CA/Browser forum, so the serial numbers are unique and unpredictable.
+func NewSerialNumberGenerator(strategy string) (SerialNumberGenerator, error) {
+	switch strategy {
+	case "", SerialNumberRandom:
+		return randomSerialNumbers{}, nil
+	case SerialNumberMonotonic:
+		return &monotonicSerialNumbers{now: time.Now}, nil
+	case SerialNumberUUID:
+		return uuidSerialNumbers{}, nil
+	default:
+		return nil, fmt.Errorf("unknown serial number strategy %q", strategy)
+	}
+}
+
+type randomSerialNumbers struct{}
+
+func (randomSerialNumbers) NewSerialNumber() (*big.Int, error) {
+	return NewSerialNumber()
+}
+
+// monotonicSerialNumbers generates serial numbers that increase with each
+// call. The upper 64 bits are a nanosecond timestamp, bumped when needed so
+// it is always greater than that of the previous serial number, and the lower
+// 64 bits are random. Serial numbers generated by other processes, e.g. after
+// a restart or by other servers, are ordered by their generation time as long
+// as the clocks agree, and remain unique thanks to the random bits.
+type monotonicSerialNumbers struct {
+	now func() time.Time
+
+	mtx  sync.Mutex
+	last int64
+}
+
+func (g *monotonicSerialNumbers) NewSerialNumber() (*big.Int, error) {
+	var random [8]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return nil, fmt.Errorf("cannot create random number: %v", err)
+	}
+
+	g.mtx.Lock()
+	timestamp := g.now().UnixNano()
+	if timestamp <= g.last {
+		timestamp = g.last + 1
+	}
+	g.last = timestamp
+	g.mtx.Unlock()
+
+	s := new(big.Int).Lsh(big.NewInt(timestamp), 64)
+	return s.Or(s, new(big.Int).SetBytes(random[:])), nil
+}
+
+// uuidSerialNumbers generates serial numbers that are the 128-bit value of a
+// random UUID, i.e. with 122 random bits. They are positive since the version
+// bits of the UUID are never zero.
+type uuidSerialNumbers struct{}
+
+func (uuidSerialNumbers) NewSerialNumber() (*big.Int, error) {
+	u, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create UUID: %v", err)
+	}
+	return new(big.Int).SetBytes(u.Bytes()), nil
+}
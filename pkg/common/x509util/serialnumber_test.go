@@ -3,7 +3,9 @@ package x509util
 import (
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -23,3 +25,71 @@ func TestMaxUint128IsMaxValueRepresentableWith128bits(t *testing.T) {
 	assert.Equal(t, 128, maxUint128.BitLen())
 	assert.Equal(t, 129, maxUint128.Add(maxUint128, one).BitLen())
 }
+
+func TestSerialNumberGenerators(t *testing.T) {
+	for _, strategy := range []string{"", SerialNumberRandom, SerialNumberMonotonic, SerialNumberUUID} {
+		strategy := strategy
+		t.Run(strategy, func(t *testing.T) {
+			g, err := NewSerialNumberGenerator(strategy)
+			require.NoError(t, err)
+
+			seen := make(map[string]struct{})
+			for i := 0; i < 1000; i++ {
+				s, err := g.NewSerialNumber()
+				require.NoError(t, err)
+				require.Equal(t, 1, s.Sign(), "Serial numbers must be positive")
+				// RFC 5280 limits serial numbers to 20 octets
+				require.LessOrEqual(t, s.BitLen(), 159)
+
+				_, ok := seen[s.String()]
+				require.False(t, ok, "Serial numbers must be unique")
+				seen[s.String()] = struct{}{}
+			}
+		})
+	}
+}
+
+func TestMonotonicSerialNumbers(t *testing.T) {
+	// The clock does not move, and even goes back, but the serial numbers
+	// still increase
+	now := time.Unix(1600000000, 0)
+	g := &monotonicSerialNumbers{now: func() time.Time { return now }}
+
+	var last *big.Int
+	for i := 0; i < 100; i++ {
+		if i == 50 {
+			now = now.Add(-time.Hour)
+		}
+		s, err := g.NewSerialNumber()
+		require.NoError(t, err)
+		if last != nil {
+			require.Equal(t, 1, s.Cmp(last), "Serial numbers must increase")
+		}
+		last = s
+	}
+
+	// The upper 64 bits are the timestamp
+	now = time.Unix(1700000000, 0)
+	s, err := g.NewSerialNumber()
+	require.NoError(t, err)
+	assert.Equal(t, now.UnixNano(), new(big.Int).Rsh(s, 64).Int64())
+}
+
+func TestUUIDSerialNumbers(t *testing.T) {
+	g, err := NewSerialNumberGenerator(SerialNumberUUID)
+	require.NoError(t, err)
+
+	s, err := g.NewSerialNumber()
+	require.NoError(t, err)
+	require.LessOrEqual(t, s.BitLen(), 128)
+
+	u, err := uuid.FromBytes(s.FillBytes(make([]byte, 16)))
+	require.NoError(t, err)
+	assert.Equal(t, byte(uuid.V4), u.Version())
+	assert.Equal(t, byte(uuid.VariantRFC4122), u.Variant())
+}
+
+func TestNewSerialNumberGeneratorUnknownStrategy(t *testing.T) {
+	_, err := NewSerialNumberGenerator("sequential")
+	require.EqualError(t, err, `unknown serial number strategy "sequential"`)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+)
+
+// AdminScope is a subset of the server APIs an admin workload can be
+// restricted to.
+type AdminScope int
+
+const (
+	// ReadOnlyAdminScope allows the methods reading entries, agents, bundles
+	// and the CA status.
+	ReadOnlyAdminScope AdminScope = iota + 1
+
+	// EntryManagementAdminScope allows the methods of the entry APIs,
+	// including the deleted entry and entry federation APIs.
+	EntryManagementAdminScope
+
+	// BundleManagementAdminScope allows the methods of the bundle API.
+	BundleManagementAdminScope
+)
+
+// String returns the name of the scope.
+func (s AdminScope) String() string {
+	switch s {
+	case ReadOnlyAdminScope:
+		return "read-only"
+	case EntryManagementAdminScope:
+		return "entry-management"
+	case BundleManagementAdminScope:
+		return "bundle-management"
+	default:
+		return fmt.Sprintf("AdminScope(%d)", int(s))
+	}
+}
+
+// ParseAdminScope parses a scope name, one of "read-only",
+// "entry-management" or "bundle-management".
+func ParseAdminScope(name string) (AdminScope, error) {
+	switch strings.ToLower(name) {
+	case "read-only":
+		return ReadOnlyAdminScope, nil
+	case "entry-management":
+		return EntryManagementAdminScope, nil
+	case "bundle-management":
+		return BundleManagementAdminScope, nil
+	default:
+		return 0, fmt.Errorf("unknown admin scope %q: expected read-only, entry-management or bundle-management", name)
+	}
+}
+
+// AdminScopes maps the SPIFFE IDs of admin workloads to the scopes they are
+// restricted to. Admin workloads not in the map are not restricted.
+type AdminScopes map[spiffeid.ID][]AdminScope
+
+// IsRestricted returns true if the admin workload with the given SPIFFE ID
+// is restricted to some scopes.
+func (s AdminScopes) IsRestricted(id spiffeid.ID) bool {
+	_, ok := s[id]
+	return ok
+}
+
+// Allows returns true if the admin workload with the given SPIFFE ID is not
+// restricted, or is restricted to any of the given scopes.
+func (s AdminScopes) Allows(id spiffeid.ID, allowed ...AdminScope) bool {
+	scopes, ok := s[id]
+	if !ok {
+		return true
+	}
+	for _, scope := range scopes {
+		for _, a := range allowed {
+			if scope == a {
+				return true
+			}
+		}
+	}
+	return false
+}
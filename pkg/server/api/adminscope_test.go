@@ -0,0 +1,67 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAdminScope(t *testing.T) {
+	for name, expected := range map[string]api.AdminScope{
+		"read-only":         api.ReadOnlyAdminScope,
+		"Entry-Management":  api.EntryManagementAdminScope,
+		"bundle-management": api.BundleManagementAdminScope,
+	} {
+		scope, err := api.ParseAdminScope(name)
+		require.NoError(t, err, name)
+		require.Equal(t, expected, scope, name)
+	}
+
+	for _, name := range []string{"", "admin"} {
+		_, err := api.ParseAdminScope(name)
+		require.EqualError(t, err, `unknown admin scope "`+name+`": expected read-only, entry-management or bundle-management`)
+	}
+}
+
+func TestAdminScopeString(t *testing.T) {
+	for _, scope := range []api.AdminScope{api.ReadOnlyAdminScope, api.EntryManagementAdminScope, api.BundleManagementAdminScope} {
+		parsed, err := api.ParseAdminScope(scope.String())
+		require.NoError(t, err)
+		require.Equal(t, scope, parsed)
+	}
+	require.Equal(t, "AdminScope(0)", api.AdminScope(0).String())
+}
+
+func TestAdminScopes(t *testing.T) {
+	unrestrictedID := spiffeid.Must("example.org", "admin")
+	readerID := spiffeid.Must("example.org", "reader")
+	managerID := spiffeid.Must("example.org", "manager")
+
+	adminScopes := api.AdminScopes{
+		readerID:  {api.ReadOnlyAdminScope},
+		managerID: {api.EntryManagementAdminScope, api.BundleManagementAdminScope},
+	}
+
+	require.False(t, adminScopes.IsRestricted(unrestrictedID))
+	require.True(t, adminScopes.IsRestricted(readerID))
+	require.True(t, adminScopes.IsRestricted(managerID))
+
+	// Admin workloads that are not restricted are allowed everything
+	require.True(t, adminScopes.Allows(unrestrictedID))
+	require.True(t, adminScopes.Allows(unrestrictedID, api.ReadOnlyAdminScope))
+
+	require.True(t, adminScopes.Allows(readerID, api.ReadOnlyAdminScope))
+	require.True(t, adminScopes.Allows(readerID, api.ReadOnlyAdminScope, api.EntryManagementAdminScope))
+	require.False(t, adminScopes.Allows(readerID, api.EntryManagementAdminScope))
+	require.False(t, adminScopes.Allows(readerID))
+
+	require.True(t, adminScopes.Allows(managerID, api.EntryManagementAdminScope))
+	require.True(t, adminScopes.Allows(managerID, api.BundleManagementAdminScope))
+	require.False(t, adminScopes.Allows(managerID, api.ReadOnlyAdminScope))
+
+	// Nothing is restricted without admin scopes
+	require.True(t, api.AdminScopes(nil).Allows(readerID))
+	require.False(t, api.AdminScopes(nil).IsRestricted(readerID))
+}
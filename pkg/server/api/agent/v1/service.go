@@ -17,14 +17,19 @@ import (
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/nodeutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/tracing"
+	"github.com/spiffe/spire/pkg/common/util"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/catalog"
+	"github.com/spiffe/spire/pkg/server/hostservice/agentstore"
 	"github.com/spiffe/spire/pkg/server/plugin/datastore"
 	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor"
+	"github.com/spiffe/spire/pkg/server/plugin/noderesolver"
 	"github.com/spiffe/spire/proto/spire/common"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
@@ -39,6 +44,35 @@ type Config struct {
 	DataStore   datastore.DataStore
 	ServerCA    ca.ServerCA
 	TrustDomain spiffeid.TrustDomain
+
+	// MinNodeSelectors is the minimum number of selectors an agent must end
+	// up with after attestation and resolution. Zero disables the check.
+	MinNodeSelectors int
+
+	// RejectBelowMinNodeSelectors, when set, fails attestation for agents
+	// below MinNodeSelectors instead of attaching no selectors to them.
+	RejectBelowMinNodeSelectors bool
+
+	// MaxNodeSelectors is the maximum number of selectors stored for an
+	// agent. Agents with more selectors have them sorted and truncated to
+	// this number. Zero disables the check.
+	MaxNodeSelectors int
+
+	// MaxAttestationPayloadSize is the maximum size in bytes of the
+	// attestation payload and of each challenge response sent by an agent.
+	// Larger ones are rejected before they reach the node attestor. Zero
+	// disables the check.
+	MaxAttestationPayloadSize int
+
+	// MaxConcurrentAttestations is the maximum number of attestations in
+	// progress at a time, keyed by node attestor type. Attestations beyond
+	// the limit are rejected with ResourceExhausted. Types without a limit
+	// are not restricted.
+	MaxConcurrentAttestations map[string]int
+
+	// NodeSelectorMergePolicy determines which selectors are kept when the
+	// node attestor and node resolver produce selectors for the same key.
+	NodeSelectorMergePolicy api.NodeSelectorMergePolicy
 }
 
 // Service implements the v1 agent service
@@ -50,16 +84,41 @@ type Service struct {
 	ds  datastore.DataStore
 	ca  ca.ServerCA
 	td  spiffeid.TrustDomain
+
+	minNodeSelectors            int
+	rejectBelowMinNodeSelectors bool
+	maxNodeSelectors            int
+	maxAttestationPayloadSize   int
+	nodeSelectorMergePolicy     api.NodeSelectorMergePolicy
+
+	// attestationSlots holds a channel for each node attestor type with a
+	// limit on concurrent attestations. Each attestation in progress holds
+	// one element of the buffer.
+	attestationSlots map[string]chan struct{}
 }
 
 // New creates a new agent service
 func New(config Config) *Service {
+	attestationSlots := make(map[string]chan struct{}, len(config.MaxConcurrentAttestations))
+	for attestorType, limit := range config.MaxConcurrentAttestations {
+		if limit > 0 {
+			attestationSlots[attestorType] = make(chan struct{}, limit)
+		}
+	}
+
 	return &Service{
 		cat: config.Catalog,
 		clk: config.Clock,
 		ds:  config.DataStore,
 		ca:  config.ServerCA,
 		td:  config.TrustDomain,
+
+		minNodeSelectors:            config.MinNodeSelectors,
+		rejectBelowMinNodeSelectors: config.RejectBelowMinNodeSelectors,
+		maxNodeSelectors:            config.MaxNodeSelectors,
+		maxAttestationPayloadSize:   config.MaxAttestationPayloadSize,
+		attestationSlots:            attestationSlots,
+		nodeSelectorMergePolicy:     config.NodeSelectorMergePolicy,
 	}
 }
 
@@ -250,6 +309,16 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 
 	log = log.WithField(telemetry.NodeAttestorType, params.Data.Type)
 
+	if err := s.checkAttestationPayloadSize(params.Data.Payload); err != nil {
+		return api.MakeErr(log, codes.InvalidArgument, "attestation data payload is too large", err)
+	}
+
+	release, ok := s.acquireAttestationSlot(params.Data.Type)
+	if !ok {
+		return api.MakeErr(log, codes.ResourceExhausted, "rejecting request due to too many concurrent attestations for the node attestor type", nil)
+	}
+	defer release()
+
 	// attest
 	var attestResult *nodeattestor.AttestResult
 	if params.Data.Type == "join_token" {
@@ -258,23 +327,27 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 			return err
 		}
 	} else {
-		attestResult, err = s.attestChallengeResponse(ctx, stream, params)
+		attestCtx := ctx
+		if rpccontext.CallerIsAgent(ctx) {
+			// The agent authenticated with its current SVID, so it is
+			// attesting its node again and the node attestor must not
+			// reject it for having already attested
+			callerID, _ := rpccontext.CallerID(ctx)
+			attestCtx = agentstore.WithReattestingAgent(ctx, callerID.String())
+		}
+		attestResult, err = s.attestChallengeResponse(attestCtx, stream, params)
 		if err != nil {
 			return err
 		}
 	}
 
-	agentID := attestResult.AgentID
-	log = log.WithField(telemetry.AgentID, agentID)
+	log = log.WithField(telemetry.AgentID, attestResult.AgentID)
 
-	if err := idutil.CheckAgentIDStringNormalization(agentID); err != nil {
-		return api.MakeErr(log, codes.Internal, "agent ID is malformed", err)
-	}
-
-	agentSpiffeID, err := spiffeid.FromString(agentID)
+	agentSpiffeID, err := parseAgentID(attestResult.AgentID)
 	if err != nil {
-		return api.MakeErr(log, codes.Internal, "invalid agent id", err)
+		return api.MakeErr(log, codes.Internal, "agent ID is malformed", err)
 	}
+	agentID := agentSpiffeID.String()
 
 	// fetch the agent/node to check if it was already attested or banned
 	attestedNode, err := s.ds.FetchAttestedNode(ctx, agentID)
@@ -286,22 +359,48 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 		return api.MakeErr(log, codes.PermissionDenied, "failed to attest: agent is banned", nil)
 	}
 
+	// augment selectors with resolver
+	resolvedSelectors, err := s.resolveSelectors(ctx, agentID, params.Data.Type)
+	if err != nil {
+		return api.MakeErr(log, codes.Internal, "failed to resolve selectors", err)
+	}
+	selectors := api.MergeNodeSelectors(s.nodeSelectorMergePolicy, attestResult.Selectors, resolvedSelectors)
+
+	// fail closed when the agent ends up with too few selectors, which
+	// usually means that the node could not be properly resolved
+	if len(selectors) < s.minNodeSelectors {
+		if s.rejectBelowMinNodeSelectors {
+			return api.MakeErr(log, codes.PermissionDenied, "failed to attest: agent has fewer selectors than the configured minimum", nil)
+		}
+		log.WithFields(logrus.Fields{
+			telemetry.Count:  len(selectors),
+			telemetry.Expect: s.minNodeSelectors,
+		}).Warn("Agent has fewer selectors than the configured minimum; no selectors will be attached")
+		selectors = nil
+	}
+
+	// cap the number of stored selectors, keeping the first ones in sorted
+	// order so the same selectors are kept on every attestation
+	if s.maxNodeSelectors > 0 && len(selectors) > s.maxNodeSelectors {
+		log.WithFields(logrus.Fields{
+			telemetry.Count: len(selectors),
+			telemetry.Limit: s.maxNodeSelectors,
+		}).Warn("Agent has more selectors than the configured maximum; selectors will be truncated")
+		util.SortSelectors(selectors)
+		selectors = selectors[:s.maxNodeSelectors]
+	}
+
 	// parse and sign CSR
 	svid, err := s.signSvid(ctx, agentSpiffeID, params.Params.Csr, log)
 	if err != nil {
 		return err
 	}
 
-	// augment selectors with resolver
-	resolvedSelectors, err := s.resolveSelectors(ctx, agentID, params.Data.Type)
-	if err != nil {
-		return api.MakeErr(log, codes.Internal, "failed to resolve selectors", err)
-	}
 	// store augmented selectors
 	_, err = s.ds.SetNodeSelectors(ctx, &datastore.SetNodeSelectorsRequest{
 		Selectors: &datastore.NodeSelectors{
 			SpiffeId:  agentID,
-			Selectors: append(attestResult.Selectors, resolvedSelectors...),
+			Selectors: selectors,
 		},
 	})
 	if err != nil {
@@ -551,7 +650,12 @@ func (s *Service) attestChallengeResponse(ctx context.Context, agentStream agent
 			return nil, api.MakeErr(log, codes.Internal, "failed to receive challenge from agent", err)
 		}
 
-		return req.GetChallengeResponse(), nil
+		challengeResponse := req.GetChallengeResponse()
+		if err := s.checkAttestationPayloadSize(challengeResponse); err != nil {
+			// Logged when the node attestor returns the error
+			return nil, status.Errorf(codes.InvalidArgument, "challenge response is too large: %v", err)
+		}
+		return challengeResponse, nil
 	})
 	if err != nil {
 		st := status.Convert(err)
@@ -560,9 +664,40 @@ func (s *Service) attestChallengeResponse(ctx context.Context, agentStream agent
 	return result, nil
 }
 
-func (s *Service) resolveSelectors(ctx context.Context, agentID string, attestationType string) ([]*common.Selector, error) {
+// checkAttestationPayloadSize fails if the payload sent by an agent exceeds
+// the configured maximum size.
+func (s *Service) checkAttestationPayloadSize(payload []byte) error {
+	if s.maxAttestationPayloadSize > 0 && len(payload) > s.maxAttestationPayloadSize {
+		return fmt.Errorf("%d bytes exceeds the maximum of %d bytes", len(payload), s.maxAttestationPayloadSize)
+	}
+	return nil
+}
+
+// acquireAttestationSlot reserves one of the concurrent attestations allowed
+// for the node attestor type. It returns false if the limit is reached. The
+// returned function releases the slot once the attestation completes.
+func (s *Service) acquireAttestationSlot(attestorType string) (func(), bool) {
+	slots, ok := s.attestationSlots[attestorType]
+	if !ok {
+		return func() {}, true
+	}
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, true
+	default:
+		return nil, false
+	}
+}
+
+func (s *Service) resolveSelectors(ctx context.Context, agentID string, attestationType string) (_ []*common.Selector, err error) {
 	if nodeResolver, ok := s.cat.GetNodeResolverNamed(attestationType); ok {
-		return nodeResolver.Resolve(ctx, agentID)
+		ctx, span := tracing.StartSpan(ctx, "noderesolver.Resolve", attribute.String("noderesolver.name", attestationType))
+		defer tracing.EndSpan(span, &err)
+		selectors, err := noderesolver.ResolveBatch(ctx, nodeResolver, []string{agentID})
+		if err != nil {
+			return nil, err
+		}
+		return selectors[agentID], nil
 	}
 	return nil, nil
 }
@@ -611,6 +746,22 @@ func validateAttestAgentParams(params *agentv1.AttestAgentRequest_Params) error
 	}
 }
 
+// parseAgentID parses the agent ID returned by a node attestor. Attestors may
+// build the ID from attested data that is not normalized, e.g. with trailing
+// slashes or percent-encoded letters, so the ID is canonicalized to match the
+// entries parented to it and the attested node stored for it, and is then
+// checked for normalization.
+func parseAgentID(rawID string) (spiffeid.ID, error) {
+	agentID, err := idutil.CanonicalizeSpiffeID(rawID)
+	if err != nil {
+		return spiffeid.ID{}, err
+	}
+	if err := idutil.CheckAgentIDStringNormalization(agentID); err != nil {
+		return spiffeid.ID{}, err
+	}
+	return spiffeid.FromString(agentID)
+}
+
 func getAttestAgentResponse(spiffeID spiffeid.ID, certificates []*x509.Certificate) *agentv1.AttestAgentResponse {
 	svid := &types.X509SVID{
 		Id:        api.ProtoFromID(spiffeID),
@@ -1333,6 +1333,10 @@ func TestAttestAgent(t *testing.T) {
 		expectLogs        []spiretest.LogEntry
 		rateLimiterErr    error
 		dsError           []error
+		minNodeSelectors  int
+		rejectBelowMin    bool
+		maxNodeSelectors  int
+		maxPayloadSize    int
 	}{
 
 		{
@@ -1544,6 +1548,122 @@ func TestAttestAgent(t *testing.T) {
 			},
 		},
 
+		{
+			name:             "attest with result meeting min node selectors",
+			request:          getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr),
+			minNodeSelectors: 2,
+			expectedID:       td.NewID("/spire/agent/test_type/id_with_result"),
+			expectedSelectors: []*common.Selector{
+				{Type: "test_type", Value: "resolved"},
+				{Type: "test_type", Value: "result"},
+			},
+		},
+
+		{
+			name:             "attest with result below min node selectors",
+			request:          getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr),
+			minNodeSelectors: 3,
+			expectedID:       td.NewID("/spire/agent/test_type/id_with_result"),
+		},
+
+		{
+			name:             "attest with result below min node selectors rejected",
+			request:          getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr),
+			minNodeSelectors: 3,
+			rejectBelowMin:   true,
+			expectCode:       codes.PermissionDenied,
+			expectMsg:        "failed to attest: agent has fewer selectors than the configured minimum",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to attest: agent has fewer selectors than the configured minimum",
+					Data: logrus.Fields{
+						telemetry.NodeAttestorType: "test_type",
+						telemetry.AgentID:          td.NewID("/spire/agent/test_type/id_with_result").String(),
+					},
+				},
+			},
+		},
+
+		{
+			name:             "attest with result at max node selectors",
+			request:          getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr),
+			maxNodeSelectors: 2,
+			expectedID:       td.NewID("/spire/agent/test_type/id_with_result"),
+			expectedSelectors: []*common.Selector{
+				{Type: "test_type", Value: "resolved"},
+				{Type: "test_type", Value: "result"},
+			},
+		},
+
+		{
+			name:             "attest with result above max node selectors",
+			request:          getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr),
+			maxNodeSelectors: 1,
+			expectedID:       td.NewID("/spire/agent/test_type/id_with_result"),
+			expectedSelectors: []*common.Selector{
+				{Type: "test_type", Value: "resolved"},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Agent has more selectors than the configured maximum; selectors will be truncated",
+					Data: logrus.Fields{
+						telemetry.NodeAttestorType: "test_type",
+						telemetry.AgentID:          td.NewID("/spire/agent/test_type/id_with_result").String(),
+						telemetry.Count:            "2",
+						telemetry.Limit:            "1",
+					},
+				},
+			},
+		},
+
+		{
+			name:           "attest with payload above max payload size",
+			request:        getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr),
+			maxPayloadSize: 18,
+			expectCode:     codes.InvalidArgument,
+			expectMsg:      "attestation data payload is too large: 19 bytes exceeds the maximum of 18 bytes",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: attestation data payload is too large",
+					Data: logrus.Fields{
+						telemetry.NodeAttestorType: "test_type",
+						logrus.ErrorKey:            "19 bytes exceeds the maximum of 18 bytes",
+					},
+				},
+			},
+		},
+
+		{
+			name:           "attest with payload at max payload size",
+			request:        getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr),
+			maxPayloadSize: 19,
+			expectedID:     td.NewID("/spire/agent/test_type/id_with_result"),
+			expectedSelectors: []*common.Selector{
+				{Type: "test_type", Value: "resolved"},
+				{Type: "test_type", Value: "result"},
+			},
+		},
+
+		{
+			name:           "attest with challenge response above max payload size",
+			request:        getAttestAgentRequest("test_type", []byte("payload_with_long_challenge"), testCsr),
+			maxPayloadSize: len("payload_with_long_challenge"),
+			expectCode:     codes.InvalidArgument,
+			expectMsg:      "challenge response is too large: 36 bytes exceeds the maximum of 27 bytes",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: challenge response is too large: 36 bytes exceeds the maximum of 27 bytes",
+					Data: logrus.Fields{
+						telemetry.NodeAttestorType: "test_type",
+					},
+				},
+			},
+		},
+
 		{
 			name:       "attest with result twice",
 			retry:      true,
@@ -1555,6 +1675,15 @@ func TestAttestAgent(t *testing.T) {
 			},
 		},
 
+		{
+			name:       "attest with non-canonical agent ID",
+			request:    getAttestAgentRequest("test_type", []byte("payload_not_canonical"), testCsr),
+			expectedID: td.NewID("/spire/agent/test_type/id_not/canonical"),
+			expectedSelectors: []*common.Selector{
+				{Type: "test_type", Value: "not_canonical"},
+			},
+		},
+
 		{
 			name:       "attest with challenge",
 			request:    getAttestAgentRequest("test_type", []byte("payload_with_challenge"), testCsr),
@@ -1766,7 +1895,12 @@ func TestAttestAgent(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			// setup
-			test := setupServiceTest(t)
+			test := setupServiceTestWithConfig(t, func(c *agent.Config) {
+				c.MinNodeSelectors = tt.minNodeSelectors
+				c.RejectBelowMinNodeSelectors = tt.rejectBelowMin
+				c.MaxNodeSelectors = tt.maxNodeSelectors
+				c.MaxAttestationPayloadSize = tt.maxPayloadSize
+			})
 			defer test.Cleanup()
 
 			ctx, cancel := context.WithCancel(context.Background())
@@ -1814,11 +1948,190 @@ func TestAttestAgent(t *testing.T) {
 				require.NotNil(t, result)
 				test.assertAttestAgentResult(t, tt.expectedID, result)
 				test.assertAgentWasStored(t, tt.expectedID.String(), tt.expectedSelectors)
+				if tt.expectLogs != nil {
+					// the logs are followed by the request completion log
+					logs := test.logHook.AllEntries()
+					require.Greater(t, len(logs), len(tt.expectLogs))
+					spiretest.AssertLogs(t, logs[:len(tt.expectLogs)], tt.expectLogs)
+				}
 			}
 		})
 	}
 }
 
+func TestAttestAgentNodeSelectorMergePolicy(t *testing.T) {
+	testCsr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, testkey.MustEC256())
+	require.NoError(t, err)
+
+	agentID := td.NewID("/spire/agent/test_type/id_with_result")
+
+	for _, tt := range []struct {
+		name              string
+		policy            api.NodeSelectorMergePolicy
+		expectedSelectors []*common.Selector
+	}{
+		{
+			name:   "union",
+			policy: api.UnionNodeSelectors,
+			expectedSelectors: []*common.Selector{
+				{Type: "test_type", Value: "region:us-east-1"},
+				{Type: "test_type", Value: "result"},
+				{Type: "test_type", Value: "tag:Name:attested"},
+				{Type: "test_type", Value: "tag:Name:resolved"},
+				{Type: "test_type", Value: "tag:Team:ops"},
+			},
+		},
+		{
+			name:   "attestor wins",
+			policy: api.AttestorWinsNodeSelectors,
+			expectedSelectors: []*common.Selector{
+				{Type: "test_type", Value: "region:us-east-1"},
+				{Type: "test_type", Value: "result"},
+				{Type: "test_type", Value: "tag:Name:attested"},
+				{Type: "test_type", Value: "tag:Team:ops"},
+			},
+		},
+		{
+			name:   "resolver wins",
+			policy: api.ResolverWinsNodeSelectors,
+			expectedSelectors: []*common.Selector{
+				{Type: "test_type", Value: "region:us-east-1"},
+				{Type: "test_type", Value: "result"},
+				{Type: "test_type", Value: "tag:Name:resolved"},
+				{Type: "test_type", Value: "tag:Team:ops"},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTestWithConfig(t, func(c *agent.Config) {
+				c.NodeSelectorMergePolicy = tt.policy
+			})
+			defer test.Cleanup()
+
+			test.cat.SetNodeAttestor(fakeservernodeattestor.New(t, "test_type", fakeservernodeattestor.Config{
+				Data: map[string]string{
+					"payload_with_result": "id_with_result",
+				},
+				Selectors: map[string][]string{
+					"id_with_result": {"result", "tag:Name:attested", "region:us-east-1"},
+				},
+			}))
+			test.cat.SetNodeResolver(fakenoderesolver.New(t, "test_type", map[string][]string{
+				agentID.String(): {"tag:Name:resolved", "tag:Team:ops"},
+			}))
+			test.rateLimiter.count = 1
+
+			stream, err := test.client.AttestAgent(ctx)
+			require.NoError(t, err)
+			result, err := attest(t, stream, getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr))
+			require.NoError(t, err)
+			require.NoError(t, stream.CloseSend())
+			test.assertAttestAgentResult(t, agentID, result)
+			test.assertAgentWasStored(t, agentID.String(), tt.expectedSelectors)
+		})
+	}
+}
+
+func TestAttestAgentRefreshesNodeSelectors(t *testing.T) {
+	testCsr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, testkey.MustEC256())
+	require.NoError(t, err)
+
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+	test.setupAttestor(t)
+
+	agentID := td.NewID("/spire/agent/test_type/id_with_result")
+	attestWithResolvedSelectors := func(resolved ...string) {
+		test.cat.SetNodeResolver(fakenoderesolver.New(t, "test_type", map[string][]string{
+			agentID.String(): resolved,
+		}))
+		test.rateLimiter.count = 1
+
+		stream, err := test.client.AttestAgent(ctx)
+		require.NoError(t, err)
+		result, err := attest(t, stream, getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr))
+		require.NoError(t, err)
+		require.NoError(t, stream.CloseSend())
+		test.assertAttestAgentResult(t, agentID, result)
+	}
+
+	attestWithResolvedSelectors("tag:v1")
+	test.assertAgentWasStored(t, agentID.String(), []*common.Selector{
+		{Type: "test_type", Value: "result"},
+		{Type: "test_type", Value: "tag:v1"},
+	})
+
+	// The node, e.g. its instance tags, changed since the agent attested, so
+	// reattesting replaces the stale selectors
+	attestWithResolvedSelectors("tag:v2", "tag:new")
+	test.assertAgentWasStored(t, agentID.String(), []*common.Selector{
+		{Type: "test_type", Value: "result"},
+		{Type: "test_type", Value: "tag:new"},
+		{Type: "test_type", Value: "tag:v2"},
+	})
+}
+
+func TestAttestAgentConcurrencyLimit(t *testing.T) {
+	testCsr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, testkey.MustEC256())
+	require.NoError(t, err)
+
+	test := setupServiceTestWithConfig(t, func(c *agent.Config) {
+		c.MaxConcurrentAttestations = map[string]int{"test_type": 1}
+	})
+	defer test.Cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	test.setupAttestor(t)
+	test.setupJoinTokens(ctx, t)
+	test.rateLimiter.count = 1
+
+	// Start an attestation that holds the only test_type slot while the
+	// challenge is pending
+	pending, err := test.client.AttestAgent(ctx)
+	require.NoError(t, err)
+	require.NoError(t, pending.Send(getAttestAgentRequest("test_type", []byte("payload_with_challenge"), testCsr)))
+	resp, err := pending.Recv()
+	require.NoError(t, err)
+	challenge := resp.GetChallenge()
+	require.NotNil(t, challenge)
+
+	// Attestations of the same type are throttled
+	stream, err := test.client.AttestAgent(ctx)
+	require.NoError(t, err)
+	result, err := attest(t, stream, getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr))
+	require.NoError(t, stream.CloseSend())
+	spiretest.RequireGRPCStatus(t, err, codes.ResourceExhausted, "rejecting request due to too many concurrent attestations for the node attestor type")
+	require.Nil(t, result)
+
+	// Attestations of other types are unaffected
+	stream, err = test.client.AttestAgent(ctx)
+	require.NoError(t, err)
+	result, err = attest(t, stream, getAttestAgentRequest("join_token", []byte("test_token"), testCsr))
+	require.NoError(t, stream.CloseSend())
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	// Completing the pending attestation releases the slot
+	result, err = attest(t, pending, &agentv1.AttestAgentRequest{
+		Step: &agentv1.AttestAgentRequest_ChallengeResponse{
+			ChallengeResponse: challenge,
+		},
+	})
+	require.NoError(t, pending.CloseSend())
+	require.NoError(t, err)
+	test.assertAttestAgentResult(t, td.NewID("/spire/agent/test_type/id_with_challenge"), result)
+
+	stream, err = test.client.AttestAgent(ctx)
+	require.NoError(t, err)
+	result, err = attest(t, stream, getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr))
+	require.NoError(t, stream.CloseSend())
+	require.NoError(t, err)
+	test.assertAttestAgentResult(t, td.NewID("/spire/agent/test_type/id_with_result"), result)
+}
+
 type serviceTest struct {
 	client       agentv1.AgentClient
 	done         func()
@@ -1840,18 +2153,24 @@ func (s *serviceTest) Cleanup() {
 }
 
 func setupServiceTest(t *testing.T) *serviceTest {
+	return setupServiceTestWithConfig(t, func(*agent.Config) {})
+}
+
+func setupServiceTestWithConfig(t *testing.T, configure func(*agent.Config)) *serviceTest {
 	ca := fakeserverca.New(t, td, &fakeserverca.Options{})
 	ds := fakedatastore.New(t)
 	cat := fakeservercatalog.New()
 	clk := clock.NewMock(t)
 
-	service := agent.New(agent.Config{
+	config := agent.Config{
 		ServerCA:    ca,
 		DataStore:   ds,
 		TrustDomain: td,
 		Clock:       clk,
 		Catalog:     cat,
-	})
+	}
+	configure(&config)
+	service := agent.New(config)
 
 	log, logHook := test.NewNullLogger()
 	log.Level = logrus.DebugLevel
@@ -1889,20 +2208,27 @@ func setupServiceTest(t *testing.T) *serviceTest {
 func (s *serviceTest) setupAttestor(t *testing.T) {
 	attestorConfig := fakeservernodeattestor.Config{
 		Data: map[string]string{
-			"payload_attested_before": "id_attested_before",
-			"payload_with_challenge":  "id_with_challenge",
-			"payload_with_result":     "id_with_result",
-			"payload_banned":          "id_banned",
+			"payload_attested_before":     "id_attested_before",
+			"payload_with_challenge":      "id_with_challenge",
+			"payload_with_long_challenge": "id_with_long_challenge",
+			"payload_with_result":         "id_with_result",
+			"payload_banned":              "id_banned",
+			"payload_not_canonical":       "id%5Fnot//canonical/",
 		},
 		Selectors: map[string][]string{
 			"id_with_result":     {"result"},
 			"id_attested_before": {"attested_before"},
 			"id_with_challenge":  {"challenge"},
 			"id_banned":          {"banned"},
+
+			"id%5Fnot//canonical/": {"not_canonical"},
 		},
 	}
 
-	attestorConfig.Challenges = map[string][]string{"id_with_challenge": {"challenge_response"}}
+	attestorConfig.Challenges = map[string][]string{
+		"id_with_challenge":      {"challenge_response"},
+		"id_with_long_challenge": {"challenge_response_that_is_too_large"},
+	}
 
 	fakeNodeAttestor := fakeservernodeattestor.New(t, "test_type", attestorConfig)
 	s.cat.SetNodeAttestor(fakeNodeAttestor)
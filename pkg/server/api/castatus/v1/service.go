@@ -0,0 +1,83 @@
+package castatus
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	"github.com/spiffe/spire/pkg/server/ca"
+	"github.com/spiffe/spire/proto/private/server/castatus"
+	"google.golang.org/grpc"
+)
+
+// RegisterService registers the CA status service on the gRPC server.
+func RegisterService(s *grpc.Server, service *Service) {
+	castatus.RegisterCAStatusServer(s, service)
+}
+
+// Manager is the interface used by the service to get the status of the
+// server CA.
+type Manager interface {
+	X509CAStatus() ca.X509CAStatus
+}
+
+// Config defines the service configuration.
+type Config struct {
+	Manager Manager
+}
+
+// Service defines the CA status service.
+type Service struct {
+	castatus.UnsafeCAStatusServer
+
+	manager Manager
+}
+
+// New creates a new CA status service.
+func New(config Config) *Service {
+	return &Service{
+		manager: config.Manager,
+	}
+}
+
+// GetX509CAStatus returns the active and next X509 CAs and when the next
+// one is prepared and activated.
+func (s *Service) GetX509CAStatus(ctx context.Context, req *castatus.GetX509CAStatusRequest) (*castatus.GetX509CAStatusResponse, error) {
+	status := s.manager.X509CAStatus()
+	return &castatus.GetX509CAStatusResponse{
+		Active:        x509CAToProto(status.Active),
+		Next:          x509CAToProto(status.Next),
+		PrepareNextAt: unixOrZero(status.PrepareNextAt),
+		RotateAt:      unixOrZero(status.ActivateNextAt),
+	}, nil
+}
+
+func x509CAToProto(slot *ca.X509CASlotStatus) *castatus.X509CA {
+	if slot == nil || slot.X509CA == nil {
+		return nil
+	}
+
+	chain := slot.X509CA.UpstreamChain
+	if len(chain) == 0 {
+		chain = []*x509.Certificate{slot.X509CA.Certificate}
+	}
+	var caCertChain [][]byte
+	for _, cert := range chain {
+		caCertChain = append(caCertChain, cert.Raw)
+	}
+
+	return &castatus.X509CA{
+		SlotId:      slot.SlotID,
+		CaCertChain: caCertChain,
+		IssuedAt:    unixOrZero(slot.IssuedAt),
+		NotBefore:   slot.X509CA.Certificate.NotBefore.Unix(),
+		NotAfter:    slot.X509CA.Certificate.NotAfter.Unix(),
+	}
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,131 @@
+package castatus_test
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/pkg/server/api/castatus/v1"
+	"github.com/spiffe/spire/pkg/server/ca"
+	castatuspb "github.com/spiffe/spire/proto/private/server/castatus"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestGetX509CAStatus(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	root, rootKey := testca.CreateCACertificate(t, nil, nil)
+	active, _ := testca.CreateCACertificate(t, root, rootKey, testca.WithLifetime(now, now.Add(time.Hour)))
+	next, _ := testca.CreateCACertificate(t, root, rootKey, testca.WithLifetime(now.Add(time.Minute), now.Add(2*time.Hour)))
+
+	for _, tt := range []struct {
+		name       string
+		status     ca.X509CAStatus
+		expectResp *castatuspb.GetX509CAStatusResponse
+	}{
+		{
+			name:       "no X509 CA",
+			expectResp: &castatuspb.GetX509CAStatusResponse{},
+		},
+		{
+			name: "active X509 CA",
+			status: ca.X509CAStatus{
+				Active: &ca.X509CASlotStatus{
+					SlotID:   "A",
+					IssuedAt: now,
+					X509CA:   &ca.X509CA{Certificate: active},
+				},
+				PrepareNextAt:  now.Add(30 * time.Minute),
+				ActivateNextAt: now.Add(50 * time.Minute),
+			},
+			expectResp: &castatuspb.GetX509CAStatusResponse{
+				Active: &castatuspb.X509CA{
+					SlotId:      "A",
+					CaCertChain: [][]byte{active.Raw},
+					IssuedAt:    now.Unix(),
+					NotBefore:   now.Unix(),
+					NotAfter:    now.Add(time.Hour).Unix(),
+				},
+				PrepareNextAt: now.Add(30 * time.Minute).Unix(),
+				RotateAt:      now.Add(50 * time.Minute).Unix(),
+			},
+		},
+		{
+			name: "active and next upstream signed X509 CAs",
+			status: ca.X509CAStatus{
+				Active: &ca.X509CASlotStatus{
+					SlotID:   "A",
+					IssuedAt: now,
+					X509CA: &ca.X509CA{
+						Certificate:   active,
+						UpstreamChain: []*x509.Certificate{active, root},
+					},
+				},
+				Next: &ca.X509CASlotStatus{
+					SlotID:   "B",
+					IssuedAt: now.Add(time.Minute),
+					X509CA: &ca.X509CA{
+						Certificate:   next,
+						UpstreamChain: []*x509.Certificate{next, root},
+					},
+				},
+				ActivateNextAt: now.Add(50 * time.Minute),
+			},
+			expectResp: &castatuspb.GetX509CAStatusResponse{
+				Active: &castatuspb.X509CA{
+					SlotId:      "A",
+					CaCertChain: [][]byte{active.Raw, root.Raw},
+					IssuedAt:    now.Unix(),
+					NotBefore:   now.Unix(),
+					NotAfter:    now.Add(time.Hour).Unix(),
+				},
+				Next: &castatuspb.X509CA{
+					SlotId:      "B",
+					CaCertChain: [][]byte{next.Raw, root.Raw},
+					IssuedAt:    now.Add(time.Minute).Unix(),
+					NotBefore:   now.Add(time.Minute).Unix(),
+					NotAfter:    now.Add(2 * time.Hour).Unix(),
+				},
+				RotateAt: now.Add(50 * time.Minute).Unix(),
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := setupServiceTest(t, fakeManager{status: tt.status})
+
+			resp, err := client.GetX509CAStatus(context.Background(), &castatuspb.GetX509CAStatusRequest{})
+			require.NoError(t, err)
+			spiretest.RequireProtoEqual(t, tt.expectResp, resp)
+		})
+	}
+}
+
+func setupServiceTest(t *testing.T, manager castatus.Manager) castatuspb.CAStatusClient {
+	service := castatus.New(castatus.Config{
+		Manager: manager,
+	})
+
+	conn, done := spiretest.NewAPIServer(t,
+		func(s *grpc.Server) {
+			castatus.RegisterService(s, service)
+		},
+		func(ctx context.Context) context.Context {
+			return ctx
+		},
+	)
+	t.Cleanup(done)
+
+	return castatuspb.NewCAStatusClient(conn)
+}
+
+type fakeManager struct {
+	status ca.X509CAStatus
+}
+
+func (m fakeManager) X509CAStatus() ca.X509CAStatus {
+	return m.status
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/plugin/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ErrSPIFFEIDCollision is returned by CheckSPIFFEIDCollisions when an entry
+// collides with an existing one and collisions are rejected.
+var ErrSPIFFEIDCollision = errors.New("an entry with the same parent ID and selectors but a different SPIFFE ID already exists")
+
+// SPIFFEIDCollisionPolicy determines what happens when an entry is created
+// or updated with the same parent ID and selectors as an existing entry but
+// a different SPIFFE ID. Workloads matching such entries would be issued
+// more than one identity.
+type SPIFFEIDCollisionPolicy int
+
+const (
+	// AllowSPIFFEIDCollisions allows colliding entries. This is the default.
+	AllowSPIFFEIDCollisions SPIFFEIDCollisionPolicy = iota
+
+	// WarnOnSPIFFEIDCollisions allows colliding entries but logs a warning.
+	WarnOnSPIFFEIDCollisions
+
+	// RejectSPIFFEIDCollisions rejects colliding entries.
+	RejectSPIFFEIDCollisions
+)
+
+// ParseSPIFFEIDCollisionPolicy parses a policy name, one of "allow", "warn"
+// or "reject". An empty name is the default policy.
+func ParseSPIFFEIDCollisionPolicy(name string) (SPIFFEIDCollisionPolicy, error) {
+	switch strings.ToLower(name) {
+	case "", "allow":
+		return AllowSPIFFEIDCollisions, nil
+	case "warn":
+		return WarnOnSPIFFEIDCollisions, nil
+	case "reject":
+		return RejectSPIFFEIDCollisions, nil
+	default:
+		return 0, fmt.Errorf("unknown SPIFFE ID collision policy %q: expected allow, warn or reject", name)
+	}
+}
+
+// CheckSPIFFEIDCollisions applies the policy to the given entry, which is
+// about to be created, or is the result of an update. When the entry
+// collides with existing entries, either a warning is logged or an error
+// wrapping ErrSPIFFEIDCollision is returned, depending on the policy.
+func CheckSPIFFEIDCollisions(ctx context.Context, log logrus.FieldLogger, ds datastore.DataStore, policy SPIFFEIDCollisionPolicy, entry *common.RegistrationEntry) error {
+	if policy == AllowSPIFFEIDCollisions {
+		return nil
+	}
+
+	collisions, err := FindSPIFFEIDCollisions(ctx, ds, entry)
+	if err != nil {
+		return err
+	}
+	if len(collisions) == 0 {
+		return nil
+	}
+
+	if policy == RejectSPIFFEIDCollisions {
+		return fmt.Errorf("%w (entry %q, SPIFFE ID %q)", ErrSPIFFEIDCollision, collisions[0].EntryId, collisions[0].SpiffeId)
+	}
+	for _, collision := range collisions {
+		log.WithFields(logrus.Fields{
+			telemetry.ParentID:          entry.ParentId,
+			telemetry.SPIFFEID:          entry.SpiffeId,
+			telemetry.RegistrationID:    collision.EntryId,
+			telemetry.CollidingSPIFFEID: collision.SpiffeId,
+		}).Warn("Entry has the same parent ID and selectors as an existing entry with a different SPIFFE ID")
+	}
+	return nil
+}
+
+// FindSPIFFEIDCollisions returns the entries, other than the given one, with
+// the same parent ID and selectors as the given entry but a different SPIFFE
+// ID.
+func FindSPIFFEIDCollisions(ctx context.Context, ds datastore.DataStore, entry *common.RegistrationEntry) ([]*common.RegistrationEntry, error) {
+	resp, err := ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		ByParentId: &wrapperspb.StringValue{
+			Value: entry.ParentId,
+		},
+		BySelectors: &datastore.BySelectors{
+			Match:     datastore.Exact,
+			Selectors: entry.Selectors,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var collisions []*common.RegistrationEntry
+	for _, existing := range resp.Entries {
+		if existing.EntryId != entry.EntryId && existing.SpiffeId != entry.SpiffeId {
+			collisions = append(collisions, existing)
+		}
+	}
+	return collisions, nil
+}
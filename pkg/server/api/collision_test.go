@@ -0,0 +1,121 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseSPIFFEIDCollisionPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		expect    api.SPIFFEIDCollisionPolicy
+		expectErr string
+	}{
+		{name: "", expect: api.AllowSPIFFEIDCollisions},
+		{name: "allow", expect: api.AllowSPIFFEIDCollisions},
+		{name: "Warn", expect: api.WarnOnSPIFFEIDCollisions},
+		{name: "REJECT", expect: api.RejectSPIFFEIDCollisions},
+		{name: "ignore", expectErr: `unknown SPIFFE ID collision policy "ignore": expected allow, warn or reject`},
+	} {
+		policy, err := api.ParseSPIFFEIDCollisionPolicy(tt.name)
+		if tt.expectErr != "" {
+			require.EqualError(t, err, tt.expectErr)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tt.expect, policy)
+	}
+}
+
+func TestCheckSPIFFEIDCollisions(t *testing.T) {
+	ctx := context.Background()
+	ds := fakedatastore.New(t)
+	existing, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		ParentId: "spiffe://example.org/parent",
+		SpiffeId: "spiffe://example.org/existing",
+		Selectors: []*common.Selector{
+			{Type: "unix", Value: "uid:1000"},
+			{Type: "unix", Value: "gid:1000"},
+		},
+	})
+	require.NoError(t, err)
+
+	colliding := &common.RegistrationEntry{
+		ParentId: "spiffe://example.org/parent",
+		SpiffeId: "spiffe://example.org/colliding",
+		Selectors: []*common.Selector{
+			{Type: "unix", Value: "gid:1000"},
+			{Type: "unix", Value: "uid:1000"},
+		},
+	}
+	subset := &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/parent",
+		SpiffeId:  "spiffe://example.org/subset",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	}
+
+	t.Run("collisions are found", func(t *testing.T) {
+		collisions, err := api.FindSPIFFEIDCollisions(ctx, ds, colliding)
+		require.NoError(t, err)
+		require.Len(t, collisions, 1)
+		require.Equal(t, existing.EntryId, collisions[0].EntryId)
+	})
+
+	t.Run("entries do not collide with themselves or entries with the same SPIFFE ID", func(t *testing.T) {
+		collisions, err := api.FindSPIFFEIDCollisions(ctx, ds, existing)
+		require.NoError(t, err)
+		require.Empty(t, collisions)
+
+		sameID := proto.Clone(colliding).(*common.RegistrationEntry)
+		sameID.SpiffeId = existing.SpiffeId
+		collisions, err = api.FindSPIFFEIDCollisions(ctx, ds, sameID)
+		require.NoError(t, err)
+		require.Empty(t, collisions)
+	})
+
+	t.Run("selectors must match exactly", func(t *testing.T) {
+		collisions, err := api.FindSPIFFEIDCollisions(ctx, ds, subset)
+		require.NoError(t, err)
+		require.Empty(t, collisions)
+	})
+
+	t.Run("allow", func(t *testing.T) {
+		log, hook := test.NewNullLogger()
+		require.NoError(t, api.CheckSPIFFEIDCollisions(ctx, log, ds, api.AllowSPIFFEIDCollisions, colliding))
+		require.Empty(t, hook.AllEntries())
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		log, hook := test.NewNullLogger()
+		require.NoError(t, api.CheckSPIFFEIDCollisions(ctx, log, ds, api.WarnOnSPIFFEIDCollisions, colliding))
+		require.Len(t, hook.AllEntries(), 1)
+		entry := hook.LastEntry()
+		require.Equal(t, logrus.WarnLevel, entry.Level)
+		require.Equal(t, "Entry has the same parent ID and selectors as an existing entry with a different SPIFFE ID", entry.Message)
+		require.Equal(t, logrus.Fields{
+			"parent_id":           colliding.ParentId,
+			"spiffe_id":           colliding.SpiffeId,
+			"entry_id":            existing.EntryId,
+			"colliding_spiffe_id": existing.SpiffeId,
+		}, entry.Data)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		log, hook := test.NewNullLogger()
+		err := api.CheckSPIFFEIDCollisions(ctx, log, ds, api.RejectSPIFFEIDCollisions, colliding)
+		require.True(t, errors.Is(err, api.ErrSPIFFEIDCollision))
+		require.Contains(t, err.Error(), existing.EntryId)
+		require.Empty(t, hook.AllEntries())
+
+		require.NoError(t, api.CheckSPIFFEIDCollisions(ctx, log, ds, api.RejectSPIFFEIDCollisions, subset))
+	})
+}
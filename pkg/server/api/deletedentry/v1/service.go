@@ -0,0 +1,94 @@
+package deletedentry
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/plugin/datastore"
+	"github.com/spiffe/spire/proto/private/server/deletedentry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterService registers the deleted entry service on the gRPC server.
+func RegisterService(s *grpc.Server, service *Service) {
+	deletedentry.RegisterDeletedEntryServer(s, service)
+}
+
+// Config defines the service configuration.
+type Config struct {
+	DataStore datastore.DataStore
+
+	// GracePeriod is how long deleted entries are kept before they are
+	// purged
+	GracePeriod time.Duration
+}
+
+// Service defines the deleted entry service.
+type Service struct {
+	deletedentry.UnsafeDeletedEntryServer
+
+	ds          datastore.DataStore
+	gracePeriod time.Duration
+}
+
+// New creates a new deleted entry service.
+func New(config Config) *Service {
+	return &Service{
+		ds:          config.DataStore,
+		gracePeriod: config.GracePeriod,
+	}
+}
+
+// ListDeletedEntries lists the deleted entries that have not been purged yet.
+func (s *Service) ListDeletedEntries(ctx context.Context, req *deletedentry.ListDeletedEntriesRequest) (*deletedentry.ListDeletedEntriesResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	deleted, err := s.ds.ListDeletedRegistrationEntries(ctx)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list deleted entries", err)
+	}
+
+	resp := &deletedentry.ListDeletedEntriesResponse{}
+	for _, d := range deleted {
+		resp.Entries = append(resp.Entries, &deletedentry.DeletedRegistrationEntry{
+			Entry:     d.Entry,
+			DeletedAt: d.DeletedAt.Unix(),
+			PurgedAt:  d.DeletedAt.Add(s.gracePeriod).Unix(),
+		})
+	}
+	return resp, nil
+}
+
+// RestoreEntry restores a deleted entry that has not been purged yet.
+func (s *Service) RestoreEntry(ctx context.Context, req *deletedentry.RestoreEntryRequest) (*deletedentry.RestoreEntryResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if req.Id == "" {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing ID", nil)
+	}
+	log = log.WithField(telemetry.RegistrationID, req.Id)
+
+	entry, err := s.ds.RestoreRegistrationEntry(ctx, req.Id)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		return nil, api.MakeErr(log, codes.NotFound, "deleted entry not found", err)
+	case codes.AlreadyExists:
+		return nil, api.MakeErr(log, codes.AlreadyExists, "failed to restore entry", err)
+	default:
+		return nil, api.MakeErr(log, codes.Internal, "failed to restore entry", err)
+	}
+
+	log.WithFields(logrus.Fields{
+		telemetry.SPIFFEID: entry.SpiffeId,
+	}).Info("Restored deleted entry")
+	return &deletedentry.RestoreEntryResponse{
+		Entry: entry,
+	}, nil
+}
@@ -0,0 +1,188 @@
+package deletedentry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/server/api/deletedentry/v1"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	deletedentrypb "github.com/spiffe/spire/proto/private/server/deletedentry"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const gracePeriod = time.Hour
+
+func TestListDeletedEntries(t *testing.T) {
+	test := setupServiceTest(t)
+	entry := test.createDeletedEntry(t)
+
+	resp, err := test.client.ListDeletedEntries(context.Background(), &deletedentrypb.ListDeletedEntriesRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 1)
+	spiretest.RequireProtoEqual(t, entry, resp.Entries[0].Entry)
+	require.NotZero(t, resp.Entries[0].DeletedAt)
+	require.Equal(t, resp.Entries[0].DeletedAt+int64(gracePeriod/time.Second), resp.Entries[0].PurgedAt)
+}
+
+func TestListDeletedEntriesFails(t *testing.T) {
+	test := setupServiceTest(t)
+	test.ds.SetNextError(errors.New("oh no"))
+
+	resp, err := test.client.ListDeletedEntries(context.Background(), &deletedentrypb.ListDeletedEntriesRequest{})
+	spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to list deleted entries: oh no")
+	require.Nil(t, resp)
+	spiretest.AssertLogs(t, test.logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.ErrorLevel,
+			Message: "Failed to list deleted entries",
+			Data: logrus.Fields{
+				logrus.ErrorKey: "oh no",
+			},
+		},
+	})
+}
+
+func TestRestoreEntry(t *testing.T) {
+	test := setupServiceTest(t)
+	entry := test.createDeletedEntry(t)
+
+	resp, err := test.client.RestoreEntry(context.Background(), &deletedentrypb.RestoreEntryRequest{Id: entry.EntryId})
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, entry, resp.Entry)
+
+	fetched, err := test.ds.FetchRegistrationEntry(context.Background(), entry.EntryId)
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, entry, fetched)
+
+	spiretest.AssertLogs(t, test.logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "Restored deleted entry",
+			Data: logrus.Fields{
+				"entry_id":  entry.EntryId,
+				"spiffe_id": entry.SpiffeId,
+			},
+		},
+	})
+
+	// The entry can only be restored once
+	_, err = test.client.RestoreEntry(context.Background(), &deletedentrypb.RestoreEntryRequest{Id: entry.EntryId})
+	spiretest.RequireGRPCStatus(t, err, codes.NotFound, "deleted entry not found")
+}
+
+func TestRestoreEntrySimilarEntryExists(t *testing.T) {
+	test := setupServiceTest(t)
+	entry := test.createDeletedEntry(t)
+
+	_, err := test.ds.CreateRegistrationEntry(context.Background(), &common.RegistrationEntry{
+		ParentId:  entry.ParentId,
+		SpiffeId:  entry.SpiffeId,
+		Selectors: entry.Selectors,
+	})
+	require.NoError(t, err)
+
+	resp, err := test.client.RestoreEntry(context.Background(), &deletedentrypb.RestoreEntryRequest{Id: entry.EntryId})
+	spiretest.RequireGRPCStatus(t, err, codes.AlreadyExists, fmt.Sprintf("failed to restore entry: similar entry already exists for deleted entry %q", entry.EntryId))
+	require.Nil(t, resp)
+
+	// The deleted entry is kept so that it can be restored later
+	deleted, err := test.ds.ListDeletedRegistrationEntries(context.Background())
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+}
+
+func TestRestoreEntryFails(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		id         string
+		dsErr      error
+		expectCode codes.Code
+		expectMsg  string
+	}{
+		{
+			name:       "missing ID",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "missing ID",
+		},
+		{
+			name:       "not found",
+			id:         "not-deleted",
+			expectCode: codes.NotFound,
+			expectMsg:  "deleted entry not found",
+		},
+		{
+			name:       "datastore fails",
+			id:         "not-deleted",
+			dsErr:      errors.New("oh no"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to restore entry: oh no",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			if tt.dsErr != nil {
+				test.ds.SetNextError(tt.dsErr)
+			}
+
+			resp, err := test.client.RestoreEntry(context.Background(), &deletedentrypb.RestoreEntryRequest{Id: tt.id})
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			require.Nil(t, resp)
+		})
+	}
+}
+
+type serviceTest struct {
+	ds      *fakedatastore.DataStore
+	client  deletedentrypb.DeletedEntryClient
+	logHook *test.Hook
+}
+
+func setupServiceTest(t *testing.T) *serviceTest {
+	ds := fakedatastore.New(t)
+	log, logHook := test.NewNullLogger()
+
+	service := deletedentry.New(deletedentry.Config{
+		DataStore:   ds,
+		GracePeriod: gracePeriod,
+	})
+
+	conn, done := spiretest.NewAPIServer(t,
+		func(s *grpc.Server) {
+			deletedentry.RegisterService(s, service)
+		},
+		func(ctx context.Context) context.Context {
+			return rpccontext.WithLogger(ctx, log)
+		},
+	)
+	t.Cleanup(done)
+
+	return &serviceTest{
+		ds:      ds,
+		client:  deletedentrypb.NewDeletedEntryClient(conn),
+		logHook: logHook,
+	}
+}
+
+func (s *serviceTest) createDeletedEntry(t *testing.T) *common.RegistrationEntry {
+	entry, err := s.ds.CreateRegistrationEntry(context.Background(), &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/node",
+		SpiffeId:  "spiffe://example.org/workload",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	})
+	require.NoError(t, err)
+
+	_, err = s.ds.SoftDeleteRegistrationEntry(context.Background(), entry.EntryId)
+	require.NoError(t, err)
+	return entry
+}
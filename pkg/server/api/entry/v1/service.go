@@ -3,6 +3,7 @@ package entry
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -24,23 +25,41 @@ type Config struct {
 	TrustDomain  spiffeid.TrustDomain
 	EntryFetcher api.AuthorizedEntryFetcher
 	DataStore    datastore.DataStore
+
+	// SPIFFEIDCollisionPolicy determines how entries that collide with
+	// existing entries are handled on create and update
+	SPIFFEIDCollisionPolicy api.SPIFFEIDCollisionPolicy
+
+	// DuplicateSelectorPolicy determines how entries with duplicate
+	// selectors are handled on create and update
+	DuplicateSelectorPolicy api.DuplicateSelectorPolicy
+
+	// DeletedEntryGracePeriod, if greater than zero, is how long deleted
+	// entries are kept, so they can be restored, before they are purged
+	DeletedEntryGracePeriod time.Duration
 }
 
 // Service defines the v1 entry service.
 type Service struct {
 	entryv1.UnsafeEntryServer
 
-	td spiffeid.TrustDomain
-	ds datastore.DataStore
-	ef api.AuthorizedEntryFetcher
+	td              spiffeid.TrustDomain
+	ds              datastore.DataStore
+	ef              api.AuthorizedEntryFetcher
+	collisionPolicy api.SPIFFEIDCollisionPolicy
+	selectorPolicy  api.DuplicateSelectorPolicy
+	softDelete      bool
 }
 
 // New creates a new v1 entry service.
 func New(config Config) *Service {
 	return &Service{
-		td: config.TrustDomain,
-		ds: config.DataStore,
-		ef: config.EntryFetcher,
+		td:              config.TrustDomain,
+		ds:              config.DataStore,
+		ef:              config.EntryFetcher,
+		collisionPolicy: config.SPIFFEIDCollisionPolicy,
+		selectorPolicy:  config.DuplicateSelectorPolicy,
+		softDelete:      config.DeletedEntryGracePeriod > 0,
 	}
 }
 
@@ -198,6 +217,13 @@ func (s *Service) createEntry(ctx context.Context, e *types.Entry, outputMask *t
 		}
 	}
 
+	cEntry.Selectors, err = api.ApplyDuplicateSelectorPolicy(s.selectorPolicy, cEntry.Selectors)
+	if err != nil {
+		return &entryv1.BatchCreateEntryResponse_Result{
+			Status: api.MakeStatus(log, codes.InvalidArgument, "invalid entry selectors", err),
+		}
+	}
+
 	log = log.WithField(telemetry.SPIFFEID, cEntry.SpiffeId)
 
 	existingEntry, err := s.getExistingEntry(ctx, cEntry)
@@ -211,6 +237,12 @@ func (s *Service) createEntry(ctx context.Context, e *types.Entry, outputMask *t
 	regEntry := existingEntry
 
 	if existingEntry == nil {
+		if err := api.CheckSPIFFEIDCollisions(ctx, log, s.ds, s.collisionPolicy, cEntry); err != nil {
+			return &entryv1.BatchCreateEntryResponse_Result{
+				Status: collisionStatus(log, "failed to create entry", err),
+			}
+		}
+
 		// Create entry
 		regEntry, err = s.ds.CreateRegistrationEntry(ctx, cEntry)
 		if err != nil {
@@ -275,7 +307,12 @@ func (s *Service) deleteEntry(ctx context.Context, id string) *entryv1.BatchDele
 
 	log = log.WithField(telemetry.RegistrationID, id)
 
-	_, err := s.ds.DeleteRegistrationEntry(ctx, id)
+	var err error
+	if s.softDelete {
+		_, err = s.ds.SoftDeleteRegistrationEntry(ctx, id)
+	} else {
+		_, err = s.ds.DeleteRegistrationEntry(ctx, id)
+	}
 	switch status.Code(err) {
 	case codes.OK:
 		return &entryv1.BatchDeleteEntryResponse_Result{
@@ -411,6 +448,19 @@ func (s *Service) updateEntry(ctx context.Context, e *types.Entry, inputMask *ty
 		}
 	}
 
+	convEntry.Selectors, err = api.ApplyDuplicateSelectorPolicy(s.selectorPolicy, convEntry.Selectors)
+	if err != nil {
+		return &entryv1.BatchUpdateEntryResponse_Result{
+			Status: api.MakeStatus(log, codes.InvalidArgument, "invalid entry selectors", err),
+		}
+	}
+
+	if err := s.checkUpdateCollisions(ctx, log, convEntry, inputMask); err != nil {
+		return &entryv1.BatchUpdateEntryResponse_Result{
+			Status: collisionStatus(log, "failed to update entry", err),
+		}
+	}
+
 	var resp *datastore.UpdateRegistrationEntryResponse
 	if inputMask != nil {
 		resp, err = s.ds.UpdateRegistrationEntry(ctx, &datastore.UpdateRegistrationEntryRequest{
@@ -450,3 +500,51 @@ func (s *Service) updateEntry(ctx context.Context, e *types.Entry, inputMask *ty
 		Entry:  tEntry,
 	}
 }
+
+// checkUpdateCollisions checks the entry resulting from the update for SPIFFE
+// ID collisions. Updates that change neither the parent ID, the SPIFFE ID nor
+// the selectors cannot introduce a collision.
+func (s *Service) checkUpdateCollisions(ctx context.Context, log logrus.FieldLogger, e *common.RegistrationEntry, inputMask *types.EntryMask) error {
+	if s.collisionPolicy == api.AllowSPIFFEIDCollisions {
+		return nil
+	}
+	if inputMask == nil {
+		return api.CheckSPIFFEIDCollisions(ctx, log, s.ds, s.collisionPolicy, e)
+	}
+	if !inputMask.ParentId && !inputMask.SpiffeId && !inputMask.Selectors {
+		return nil
+	}
+
+	existing, err := s.ds.FetchRegistrationEntry(ctx, e.EntryId)
+	switch {
+	case err != nil:
+		return err
+	case existing == nil:
+		// Let the update report that the entry does not exist
+		return nil
+	}
+
+	updated := &common.RegistrationEntry{
+		EntryId:   existing.EntryId,
+		ParentId:  existing.ParentId,
+		SpiffeId:  existing.SpiffeId,
+		Selectors: existing.Selectors,
+	}
+	if inputMask.ParentId {
+		updated.ParentId = e.ParentId
+	}
+	if inputMask.SpiffeId {
+		updated.SpiffeId = e.SpiffeId
+	}
+	if inputMask.Selectors {
+		updated.Selectors = e.Selectors
+	}
+	return api.CheckSPIFFEIDCollisions(ctx, log, s.ds, s.collisionPolicy, updated)
+}
+
+func collisionStatus(log logrus.FieldLogger, msg string, err error) *types.Status {
+	if errors.Is(err, api.ErrSPIFFEIDCollision) {
+		return api.MakeStatus(log, codes.FailedPrecondition, msg, err)
+	}
+	return api.MakeStatus(log, codes.Internal, "failed to check for SPIFFE ID collisions", err)
+}
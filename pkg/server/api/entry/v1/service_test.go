@@ -1481,12 +1481,21 @@ func (s *serviceTest) Cleanup() {
 }
 
 func setupServiceTest(t *testing.T, ds datastore.DataStore) *serviceTest {
-	ef := &entryFetcher{}
-	service := entry.New(entry.Config{
-		TrustDomain:  td,
-		DataStore:    ds,
-		EntryFetcher: ef,
+	return setupServiceTestWithCollisionPolicy(t, ds, api.AllowSPIFFEIDCollisions)
+}
+
+func setupServiceTestWithCollisionPolicy(t *testing.T, ds datastore.DataStore, policy api.SPIFFEIDCollisionPolicy) *serviceTest {
+	return setupServiceTestWithConfig(t, ds, entry.Config{
+		SPIFFEIDCollisionPolicy: policy,
 	})
+}
+
+func setupServiceTestWithConfig(t *testing.T, ds datastore.DataStore, config entry.Config) *serviceTest {
+	ef := &entryFetcher{}
+	config.TrustDomain = td
+	config.DataStore = ds
+	config.EntryFetcher = ef
+	service := entry.New(config)
 
 	log, logHook := test.NewNullLogger()
 	registerFn := func(s *grpc.Server) {
@@ -1514,6 +1523,316 @@ func setupServiceTest(t *testing.T, ds datastore.DataStore) *serviceTest {
 	return test
 }
 
+func TestSPIFFEIDCollisions(t *testing.T) {
+	parentID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"}
+	selectors := []*types.Selector{
+		{Type: "unix", Value: "uid:1000"},
+		{Type: "unix", Value: "gid:1000"},
+	}
+	warning := "Entry has the same parent ID and selectors as an existing entry with a different SPIFFE ID"
+
+	for _, tt := range []struct {
+		name         string
+		policy       api.SPIFFEIDCollisionPolicy
+		expectStatus *types.Status
+		expectWarn   bool
+	}{
+		{
+			name:         "allowed",
+			policy:       api.AllowSPIFFEIDCollisions,
+			expectStatus: api.OK(),
+		},
+		{
+			name:         "warned",
+			policy:       api.WarnOnSPIFFEIDCollisions,
+			expectStatus: api.OK(),
+			expectWarn:   true,
+		},
+		{
+			name:         "rejected on create",
+			policy:       api.RejectSPIFFEIDCollisions,
+			expectStatus: api.CreateStatus(codes.FailedPrecondition, "failed to create entry: an entry with the same parent ID and selectors but a different SPIFFE ID already exists"),
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ds := fakedatastore.New(t)
+			test := setupServiceTestWithCollisionPolicy(t, ds, tt.policy)
+			defer test.Cleanup()
+
+			existing, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+				ParentId: "spiffe://example.org/parent",
+				SpiffeId: "spiffe://example.org/existing",
+				Selectors: []*common.Selector{
+					{Type: "unix", Value: "gid:1000"},
+					{Type: "unix", Value: "uid:1000"},
+				},
+			})
+			require.NoError(t, err)
+			expectStatus := tt.expectStatus
+			if expectStatus.Code != int32(codes.OK) {
+				expectStatus.Message += fmt.Sprintf(" (entry %q, SPIFFE ID %q)", existing.EntryId, existing.SpiffeId)
+			}
+
+			resp, err := test.client.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{
+				Entries: []*types.Entry{
+					{
+						ParentId:  parentID,
+						SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/colliding"},
+						Selectors: selectors,
+					},
+					{
+						// Same SPIFFE ID, so it is not a collision
+						ParentId:  parentID,
+						SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/existing"},
+						Selectors: selectors[:1],
+					},
+				},
+			})
+			require.NoError(t, err)
+			require.Len(t, resp.Results, 2)
+			spiretest.AssertProtoEqual(t, expectStatus, resp.Results[0].Status)
+			spiretest.AssertProtoEqual(t, api.OK(), resp.Results[1].Status)
+			assert.Equal(t, tt.expectWarn, hasLogEntry(test.logHook, logrus.WarnLevel, warning))
+		})
+	}
+}
+
+func TestBatchDeleteEntryWithGracePeriod(t *testing.T) {
+	ds := fakedatastore.New(t)
+	test := setupServiceTestWithConfig(t, ds, entry.Config{
+		DeletedEntryGracePeriod: time.Hour,
+	})
+	defer test.Cleanup()
+
+	created, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/parent",
+		SpiffeId:  "spiffe://example.org/workload",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	})
+	require.NoError(t, err)
+
+	resp, err := test.client.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{
+		Ids: []string{created.EntryId},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	spiretest.RequireProtoEqual(t, api.OK(), resp.Results[0].Status)
+
+	// The entry no longer matches, but can be restored
+	fetched, err := ds.FetchRegistrationEntry(ctx, created.EntryId)
+	require.NoError(t, err)
+	require.Nil(t, fetched)
+
+	deleted, err := ds.ListDeletedRegistrationEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	spiretest.RequireProtoEqual(t, created, deleted[0].Entry)
+}
+
+func TestSPIFFEIDCollisionsOnUpdate(t *testing.T) {
+	ds := fakedatastore.New(t)
+	test := setupServiceTestWithCollisionPolicy(t, ds, api.RejectSPIFFEIDCollisions)
+	defer test.Cleanup()
+
+	existing, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/parent",
+		SpiffeId:  "spiffe://example.org/existing",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	})
+	require.NoError(t, err)
+	other, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/parent",
+		SpiffeId:  "spiffe://example.org/other",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:2000"}},
+	})
+	require.NoError(t, err)
+
+	update := func(e *types.Entry, mask *types.EntryMask) *types.Status {
+		resp, err := test.client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+			Entries:   []*types.Entry{e},
+			InputMask: mask,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 1)
+		return resp.Results[0].Status
+	}
+
+	// Updating the entry so it has the selectors of the existing entry is
+	// rejected
+	status := update(&types.Entry{
+		Id:        other.EntryId,
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+	}, &types.EntryMask{Selectors: true})
+	spiretest.AssertProtoEqual(t, api.CreateStatus(codes.FailedPrecondition,
+		"failed to update entry: an entry with the same parent ID and selectors but a different SPIFFE ID already exists (entry %q, SPIFFE ID %q)",
+		existing.EntryId, existing.SpiffeId), status)
+
+	// Updates that do not make the entries collide are fine, including the
+	// ones that only touch unrelated fields
+	status = update(&types.Entry{
+		Id:       other.EntryId,
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/renamed"},
+	}, &types.EntryMask{SpiffeId: true})
+	spiretest.AssertProtoEqual(t, api.OK(), status)
+	status = update(&types.Entry{
+		Id:  existing.EntryId,
+		Ttl: 60,
+	}, &types.EntryMask{Ttl: true})
+	spiretest.AssertProtoEqual(t, api.OK(), status)
+}
+
+func TestDuplicateSelectors(t *testing.T) {
+	duplicated := []*types.Selector{
+		{Type: "unix", Value: "uid:1000"},
+		{Type: "unix", Value: "gid:1000"},
+		{Type: "unix", Value: "uid:1000"},
+	}
+	deduped := []*common.Selector{
+		{Type: "unix", Value: "uid:1000"},
+		{Type: "unix", Value: "gid:1000"},
+	}
+
+	t.Run("deduped", func(t *testing.T) {
+		ds := fakedatastore.New(t)
+		test := setupServiceTest(t, ds)
+		defer test.Cleanup()
+
+		resp, err := test.client.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+					SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+					Selectors: duplicated,
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 1)
+		spiretest.RequireProtoEqual(t, api.OK(), resp.Results[0].Status)
+
+		created, err := ds.FetchRegistrationEntry(ctx, resp.Results[0].Entry.Id)
+		require.NoError(t, err)
+		spiretest.RequireProtoListEqual(t, deduped, created.Selectors)
+
+		updateResp, err := test.client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					Id:        created.EntryId,
+					Selectors: append(duplicated, &types.Selector{Type: "unix", Value: "gid:1000"}),
+				},
+			},
+			InputMask: &types.EntryMask{Selectors: true},
+		})
+		require.NoError(t, err)
+		require.Len(t, updateResp.Results, 1)
+		spiretest.RequireProtoEqual(t, api.OK(), updateResp.Results[0].Status)
+
+		updated, err := ds.FetchRegistrationEntry(ctx, created.EntryId)
+		require.NoError(t, err)
+		spiretest.RequireProtoListEqual(t, deduped, updated.Selectors)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		ds := fakedatastore.New(t)
+		test := setupServiceTestWithConfig(t, ds, entry.Config{
+			DuplicateSelectorPolicy: api.RejectDuplicateSelectors,
+		})
+		defer test.Cleanup()
+
+		resp, err := test.client.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+					SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+					Selectors: duplicated,
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 1)
+		spiretest.RequireProtoEqual(t, api.CreateStatus(codes.InvalidArgument,
+			`invalid entry selectors: duplicate selector "unix:uid:1000"`), resp.Results[0].Status)
+
+		existing, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			ParentId:  "spiffe://example.org/parent",
+			SpiffeId:  "spiffe://example.org/workload",
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		})
+		require.NoError(t, err)
+
+		updateResp, err := test.client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					Id:        existing.EntryId,
+					Selectors: duplicated,
+				},
+			},
+			InputMask: &types.EntryMask{Selectors: true},
+		})
+		require.NoError(t, err)
+		require.Len(t, updateResp.Results, 1)
+		spiretest.RequireProtoEqual(t, api.CreateStatus(codes.InvalidArgument,
+			`invalid entry selectors: duplicate selector "unix:uid:1000"`), updateResp.Results[0].Status)
+	})
+}
+
+func TestBatchUpdateEntryPreservesMetadata(t *testing.T) {
+	ds := fakedatastore.New(t)
+	test := setupServiceTest(t, ds)
+	defer test.Cleanup()
+
+	metadata := map[string]string{"owner": "team-a"}
+	existing, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/parent",
+		SpiffeId:  "spiffe://example.org/workload",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		Metadata:  metadata,
+	})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name string
+		mask *types.EntryMask
+	}{
+		{name: "with mask", mask: &types.EntryMask{Ttl: true}},
+		{name: "without mask"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := test.client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+				Entries: []*types.Entry{
+					{
+						Id:        existing.EntryId,
+						ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+						SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+						Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+						Ttl:       60,
+					},
+				},
+				InputMask: tt.mask,
+			})
+			require.NoError(t, err)
+			require.Len(t, resp.Results, 1)
+			spiretest.RequireProtoEqual(t, api.OK(), resp.Results[0].Status)
+
+			updated, err := ds.FetchRegistrationEntry(ctx, existing.EntryId)
+			require.NoError(t, err)
+			require.Equal(t, int32(60), updated.Ttl)
+			require.Equal(t, metadata, updated.Metadata)
+		})
+	}
+}
+
+func hasLogEntry(hook *test.Hook, level logrus.Level, message string) bool {
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == level && entry.Message == message {
+			return true
+		}
+	}
+	return false
+}
+
 func TestBatchUpdateEntry(t *testing.T) {
 	parent := &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"}
 	entry1SpiffeID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"}
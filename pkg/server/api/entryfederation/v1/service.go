@@ -0,0 +1,118 @@
+package entryfederation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/plugin/datastore"
+	"github.com/spiffe/spire/proto/private/server/entryfederation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// RegisterService registers the entry federation service on the gRPC server.
+func RegisterService(s *grpc.Server, service *Service) {
+	entryfederation.RegisterEntryFederationServer(s, service)
+}
+
+// Config defines the service configuration.
+type Config struct {
+	TrustDomain spiffeid.TrustDomain
+	DataStore   datastore.DataStore
+}
+
+// Service defines the entry federation service.
+type Service struct {
+	entryfederation.UnsafeEntryFederationServer
+
+	td spiffeid.TrustDomain
+	ds datastore.DataStore
+}
+
+// New creates a new entry federation service.
+func New(config Config) *Service {
+	return &Service{
+		td: config.TrustDomain,
+		ds: config.DataStore,
+	}
+}
+
+// UpdateFederatesWith adds a federated trust domain to, or removes it from,
+// the entries matching the selectors.
+func (s *Service) UpdateFederatesWith(ctx context.Context, req *entryfederation.UpdateFederatesWithRequest) (*entryfederation.UpdateFederatesWithResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if len(req.Selectors) == 0 {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing selectors", nil)
+	}
+	// Validate the selectors the same way the entry API does
+	selectors, err := api.SelectorsFromProto(api.ProtoFromSelectors(req.Selectors))
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid selectors", err)
+	}
+
+	td, err := spiffeid.TrustDomainFromString(req.TrustDomain)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid trust domain", err)
+	}
+	log = log.WithField(telemetry.TrustDomainID, td.IDString())
+	if td == s.td {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid trust domain", fmt.Errorf("%q is the server trust domain", td))
+	}
+
+	var remove bool
+	switch req.Operation {
+	case entryfederation.UpdateFederatesWithRequest_ADD:
+	case entryfederation.UpdateFederatesWithRequest_REMOVE:
+		remove = true
+	default:
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid operation", fmt.Errorf("unknown operation %d", req.Operation))
+	}
+
+	// The trust domain can only be added if it is federated with, i.e. its
+	// bundle is known. It is not checked on removal, so the entries can be
+	// cleaned up regardless.
+	if !remove {
+		bundle, err := s.ds.FetchBundle(ctx, td.IDString())
+		if err != nil {
+			return nil, api.MakeErr(log, codes.Internal, "failed to fetch bundle", err)
+		}
+		if bundle == nil {
+			return nil, api.MakeErr(log, codes.FailedPrecondition, "trust domain is not federated with", fmt.Errorf("no bundle found for %q", td))
+		}
+	}
+
+	match := datastore.Exact
+	if req.MatchSubset {
+		match = datastore.Subset
+	}
+	resp, err := s.ds.UpdateEntriesFederatesWith(ctx, &datastore.UpdateEntriesFederatesWithRequest{
+		BySelectors: &datastore.BySelectors{
+			Selectors: selectors,
+			Match:     match,
+		},
+		TrustDomainID: td.IDString(),
+		Remove:        remove,
+	})
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to update entries", err)
+	}
+
+	msg := "Added federated trust domain to entries"
+	if remove {
+		msg = "Removed federated trust domain from entries"
+	}
+	log.WithFields(logrus.Fields{
+		telemetry.Count: len(resp.Entries),
+	}).Info(msg)
+
+	return &entryfederation.UpdateFederatesWithResponse{
+		Entries: resp.Entries,
+		Matched: resp.Matched,
+	}, nil
+}
@@ -0,0 +1,223 @@
+package entryfederation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/server/api/entryfederation/v1"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	entryfederationpb "github.com/spiffe/spire/proto/private/server/entryfederation"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var td = spiffeid.RequireTrustDomainFromString("example.org")
+
+func TestUpdateFederatesWith(t *testing.T) {
+	test := setupServiceTest(t)
+	test.createBundle(t, "spiffe://domain1.org")
+
+	ns := &common.Selector{Type: "k8s", Value: "ns:prod"}
+	sa := &common.Selector{Type: "k8s", Value: "sa:foo"}
+	entry1 := test.createEntry(t, "spiffe://example.org/workload1", ns)
+	entry2 := test.createEntry(t, "spiffe://example.org/workload2", ns, sa)
+	entry3 := test.createEntry(t, "spiffe://example.org/workload3", sa)
+
+	// Add the trust domain to the entries with a subset of the selectors
+	resp, err := test.client.UpdateFederatesWith(context.Background(), &entryfederationpb.UpdateFederatesWithRequest{
+		Selectors:   []*common.Selector{ns, sa},
+		MatchSubset: true,
+		TrustDomain: "domain1.org",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(3), resp.Matched)
+	for _, entry := range []*common.RegistrationEntry{entry1, entry2, entry3} {
+		entry.FederatesWith = []string{"spiffe://domain1.org"}
+		entry.RevisionNumber++
+	}
+	spiretest.RequireProtoListEqual(t, []*common.RegistrationEntry{entry1, entry2, entry3}, resp.Entries)
+	spiretest.AssertLogs(t, test.logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "Added federated trust domain to entries",
+			Data: logrus.Fields{
+				"trust_domain_id": "spiffe://domain1.org",
+				"count":           "3",
+			},
+		},
+	})
+	test.logHook.Reset()
+
+	// Remove it from the entries with exactly the selector
+	resp, err = test.client.UpdateFederatesWith(context.Background(), &entryfederationpb.UpdateFederatesWithRequest{
+		Selectors:   []*common.Selector{ns},
+		TrustDomain: "spiffe://domain1.org",
+		Operation:   entryfederationpb.UpdateFederatesWithRequest_REMOVE,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), resp.Matched)
+	entry1.FederatesWith = nil
+	entry1.RevisionNumber++
+	spiretest.RequireProtoListEqual(t, []*common.RegistrationEntry{entry1}, resp.Entries)
+	spiretest.AssertLogs(t, test.logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "Removed federated trust domain from entries",
+			Data: logrus.Fields{
+				"trust_domain_id": "spiffe://domain1.org",
+				"count":           "1",
+			},
+		},
+	})
+
+	for _, entry := range []*common.RegistrationEntry{entry1, entry2, entry3} {
+		fetched, err := test.ds.FetchRegistrationEntry(context.Background(), entry.EntryId)
+		require.NoError(t, err)
+		spiretest.RequireProtoEqual(t, entry, fetched)
+	}
+}
+
+func TestUpdateFederatesWithFails(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		req        *entryfederationpb.UpdateFederatesWithRequest
+		dsErr      error
+		expectCode codes.Code
+		expectMsg  string
+	}{
+		{
+			name: "missing selectors",
+			req: &entryfederationpb.UpdateFederatesWithRequest{
+				TrustDomain: "domain1.org",
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "missing selectors",
+		},
+		{
+			name: "invalid selector",
+			req: &entryfederationpb.UpdateFederatesWithRequest{
+				Selectors:   []*common.Selector{{Type: "k8s"}},
+				TrustDomain: "domain1.org",
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid selectors: missing selector value",
+		},
+		{
+			name: "invalid trust domain",
+			req: &entryfederationpb.UpdateFederatesWithRequest{
+				Selectors:   []*common.Selector{{Type: "k8s", Value: "ns:prod"}},
+				TrustDomain: "",
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid trust domain",
+		},
+		{
+			name: "server trust domain",
+			req: &entryfederationpb.UpdateFederatesWithRequest{
+				Selectors:   []*common.Selector{{Type: "k8s", Value: "ns:prod"}},
+				TrustDomain: "example.org",
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `invalid trust domain: "example.org" is the server trust domain`,
+		},
+		{
+			name: "invalid operation",
+			req: &entryfederationpb.UpdateFederatesWithRequest{
+				Selectors:   []*common.Selector{{Type: "k8s", Value: "ns:prod"}},
+				TrustDomain: "domain1.org",
+				Operation:   3,
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid operation: unknown operation 3",
+		},
+		{
+			name: "trust domain not federated with",
+			req: &entryfederationpb.UpdateFederatesWithRequest{
+				Selectors:   []*common.Selector{{Type: "k8s", Value: "ns:prod"}},
+				TrustDomain: "domain2.org",
+			},
+			expectCode: codes.FailedPrecondition,
+			expectMsg:  `trust domain is not federated with: no bundle found for "domain2.org"`,
+		},
+		{
+			name: "datastore fails",
+			req: &entryfederationpb.UpdateFederatesWithRequest{
+				Selectors:   []*common.Selector{{Type: "k8s", Value: "ns:prod"}},
+				TrustDomain: "domain1.org",
+				Operation:   entryfederationpb.UpdateFederatesWithRequest_REMOVE,
+			},
+			dsErr:      errors.New("oh no"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to update entries: oh no",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			test.createBundle(t, "spiffe://domain1.org")
+			if tt.dsErr != nil {
+				test.ds.SetNextError(tt.dsErr)
+			}
+
+			resp, err := test.client.UpdateFederatesWith(context.Background(), tt.req)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsg)
+			require.Nil(t, resp)
+		})
+	}
+}
+
+type serviceTest struct {
+	ds      *fakedatastore.DataStore
+	client  entryfederationpb.EntryFederationClient
+	logHook *test.Hook
+}
+
+func setupServiceTest(t *testing.T) *serviceTest {
+	ds := fakedatastore.New(t)
+	log, logHook := test.NewNullLogger()
+
+	service := entryfederation.New(entryfederation.Config{
+		TrustDomain: td,
+		DataStore:   ds,
+	})
+
+	conn, done := spiretest.NewAPIServer(t,
+		func(s *grpc.Server) {
+			entryfederation.RegisterService(s, service)
+		},
+		func(ctx context.Context) context.Context {
+			return rpccontext.WithLogger(ctx, log)
+		},
+	)
+	t.Cleanup(done)
+
+	return &serviceTest{
+		ds:      ds,
+		client:  entryfederationpb.NewEntryFederationClient(conn),
+		logHook: logHook,
+	}
+}
+
+func (s *serviceTest) createBundle(t *testing.T, trustDomainID string) {
+	_, err := s.ds.CreateBundle(context.Background(), bundleutil.BundleProtoFromRootCAs(trustDomainID, nil))
+	require.NoError(t, err)
+}
+
+func (s *serviceTest) createEntry(t *testing.T, spiffeID string, selectors ...*common.Selector) *common.RegistrationEntry {
+	entry, err := s.ds.CreateRegistrationEntry(context.Background(), &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/node",
+		SpiffeId:  spiffeID,
+		Selectors: selectors,
+	})
+	require.NoError(t, err)
+	return entry
+}
@@ -0,0 +1,197 @@
+package issuancehook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultTimeout is how long the webhook is waited for by default
+	DefaultTimeout = time.Second
+
+	// maxResponseSize bounds the size of the webhook responses read
+	maxResponseSize = 64 * 1024
+)
+
+// SVID types of the issuance requests
+const (
+	X509SVID = "x509"
+	JWTSVID  = "jwt"
+)
+
+// ErrDenied is returned when the webhook denies the issuance of an SVID.
+var ErrDenied = errors.New("denied by SVID issuance webhook")
+
+// Config is the configuration of the webhook.
+type Config struct {
+	// URL is the URL the issuance requests are posted to.
+	URL *url.URL
+
+	// Timeout is how long the webhook is waited for. Defaults to
+	// DefaultTimeout.
+	Timeout time.Duration
+
+	// FailOpen allows the issuance when the webhook cannot be reached or
+	// fails to respond with a decision. Otherwise, the issuance is refused.
+	FailOpen bool
+
+	// HTTPClient is the client used to call the webhook. Defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Selector is a selector of the registration entry an SVID is issued for.
+type Selector struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Request is the context of an SVID issuance posted to the webhook.
+type Request struct {
+	// SVIDType is the type of the SVID, X509SVID or JWTSVID.
+	SVIDType string `json:"svid_type"`
+
+	// SPIFFEID is the requested identity.
+	SPIFFEID string `json:"spiffe_id"`
+
+	// CallerID is the SPIFFE ID of the caller, if it has one.
+	CallerID string `json:"caller_id,omitempty"`
+
+	// EntryID is the ID of the registration entry the SVID is issued for,
+	// if it is issued for one.
+	EntryID string `json:"entry_id,omitempty"`
+
+	// Selectors are the selectors of the registration entry.
+	Selectors []Selector `json:"selectors,omitempty"`
+
+	// DNSNames are the DNS names requested for an X509-SVID.
+	DNSNames []string `json:"dns_names,omitempty"`
+
+	// Audience is the audience requested for a JWT-SVID.
+	Audience []string `json:"audience,omitempty"`
+
+	// TTL is the requested TTL, in seconds. Zero is the default TTL.
+	TTL int32 `json:"ttl,omitempty"`
+}
+
+// Decision is the decision of the webhook.
+type Decision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Hook consults an external policy service over HTTP before SVIDs are
+// issued. It speaks the OPA data API: the request is posted as the "input"
+// document, and the "result" of the response is either a boolean or a
+// Decision. An undefined result denies the issuance.
+type Hook struct {
+	url      string
+	timeout  time.Duration
+	failOpen bool
+	client   *http.Client
+}
+
+// New creates a new hook.
+func New(config Config) *Hook {
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return &Hook{
+		url:      config.URL.String(),
+		timeout:  config.Timeout,
+		failOpen: config.FailOpen,
+		client:   config.HTTPClient,
+	}
+}
+
+// Check consults the webhook about the issuance. It returns nil if the
+// issuance is allowed, an error wrapping ErrDenied if it is denied, or
+// the error calling the webhook if it failed and the hook fails closed.
+func (h *Hook) Check(ctx context.Context, log logrus.FieldLogger, req Request) error {
+	decision, err := h.decide(ctx, req)
+	switch {
+	case err != nil && h.failOpen:
+		log.WithError(err).Warn("SVID issuance webhook failed; allowing issuance")
+		return nil
+	case err != nil:
+		return fmt.Errorf("SVID issuance webhook failed: %w", err)
+	case !decision.Allow:
+		if decision.Reason != "" {
+			return fmt.Errorf("%w: %s", ErrDenied, decision.Reason)
+		}
+		return ErrDenied
+	default:
+		return nil
+	}
+}
+
+func (h *Hook) decide(ctx context.Context, req Request) (*Decision, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	body, err := json.Marshal(struct {
+		Input Request `json:"input"`
+	}{Input: req})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	// Drain the response, so the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	return parseResult(result.Result)
+}
+
+// parseResult parses the result of the webhook, a boolean or a Decision.
+// An undefined (i.e. missing or null) result is a deny.
+func parseResult(result json.RawMessage) (*Decision, error) {
+	if len(result) == 0 || string(result) == "null" {
+		return &Decision{Reason: "policy decision is undefined"}, nil
+	}
+
+	var allow bool
+	if err := json.Unmarshal(result, &allow); err == nil {
+		return &Decision{Allow: allow}, nil
+	}
+
+	decision := new(Decision)
+	if err := json.Unmarshal(result, decision); err != nil {
+		return nil, fmt.Errorf("result is neither a boolean nor a decision: %w", err)
+	}
+	return decision, nil
+}
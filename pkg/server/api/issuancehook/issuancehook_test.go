@@ -0,0 +1,168 @@
+package issuancehook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/server/api/issuancehook"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+)
+
+var request = issuancehook.Request{
+	SVIDType:  issuancehook.X509SVID,
+	SPIFFEID:  "spiffe://example.org/workload",
+	CallerID:  "spiffe://example.org/spire/agent/test/agent",
+	EntryID:   "entry-1",
+	Selectors: []issuancehook.Selector{{Type: "unix", Value: "uid:1000"}},
+	DNSNames:  []string{"workload.example.org"},
+	TTL:       60,
+}
+
+func TestCheck(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		response   string
+		statusCode int
+		delay      time.Duration
+		failOpen   bool
+		expectErr  string
+		expectDeny bool
+		// expectWarning expects the webhook failure to be logged
+		expectWarning bool
+	}{
+		{
+			name:     "allowed by boolean result",
+			response: `{"result": true}`,
+		},
+		{
+			name:     "allowed by decision",
+			response: `{"result": {"allow": true}}`,
+		},
+		{
+			name:       "denied by boolean result",
+			response:   `{"result": false}`,
+			expectErr:  "denied by SVID issuance webhook",
+			expectDeny: true,
+		},
+		{
+			name:       "denied by decision",
+			response:   `{"result": {"allow": false, "reason": "workload is quarantined"}}`,
+			expectErr:  "denied by SVID issuance webhook: workload is quarantined",
+			expectDeny: true,
+		},
+		{
+			name:       "denied by undefined result",
+			response:   `{}`,
+			expectErr:  "denied by SVID issuance webhook: policy decision is undefined",
+			expectDeny: true,
+		},
+		{
+			name:       "undefined result is denied even if failing open",
+			response:   `{}`,
+			failOpen:   true,
+			expectErr:  "denied by SVID issuance webhook: policy decision is undefined",
+			expectDeny: true,
+		},
+		{
+			name:      "malformed result fails closed",
+			response:  `{"result": "yes"}`,
+			expectErr: "SVID issuance webhook failed: result is neither a boolean nor a decision: json: cannot unmarshal string into Go value of type issuancehook.Decision",
+		},
+		{
+			name:       "unexpected status code fails closed",
+			response:   `{"result": true}`,
+			statusCode: http.StatusInternalServerError,
+			expectErr:  "SVID issuance webhook failed: unexpected status code 500",
+		},
+		{
+			name:      "timeout fails closed",
+			response:  `{"result": true}`,
+			delay:     time.Second,
+			expectErr: "context deadline exceeded",
+		},
+		{
+			name:          "timeout fails open",
+			response:      `{"result": false}`,
+			delay:         time.Second,
+			failOpen:      true,
+			expectWarning: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				var body struct {
+					Input issuancehook.Request `json:"input"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !requestEqual(body.Input, request) {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				if tt.delay > 0 {
+					select {
+					case <-time.After(tt.delay):
+					case <-done:
+						return
+					}
+				}
+				if tt.statusCode != 0 {
+					w.WriteHeader(tt.statusCode)
+				}
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+			// Release the delayed handlers before the server is closed
+			defer close(done)
+
+			u, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			hook := issuancehook.New(issuancehook.Config{
+				URL:      u,
+				Timeout:  100 * time.Millisecond,
+				FailOpen: tt.failOpen,
+			})
+
+			log, logHook := test.NewNullLogger()
+			err = hook.Check(context.Background(), log, request)
+			var expectLogs []spiretest.LogEntry
+			if tt.expectWarning {
+				expectLogs = append(expectLogs, spiretest.LogEntry{
+					Level:   logrus.WarnLevel,
+					Message: "SVID issuance webhook failed; allowing issuance",
+					Data: logrus.Fields{
+						logrus.ErrorKey: fmt.Sprintf("Post %q: context deadline exceeded", server.URL),
+					},
+				})
+			}
+			spiretest.AssertLogs(t, logHook.AllEntries(), expectLogs)
+			if tt.expectErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expectErr)
+			require.Equal(t, tt.expectDeny, errors.Is(err, issuancehook.ErrDenied))
+		})
+	}
+}
+
+func requestEqual(a, b issuancehook.Request) bool {
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	return string(aJSON) == string(bJSON)
+}
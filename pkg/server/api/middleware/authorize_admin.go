@@ -4,17 +4,30 @@ import (
 	"context"
 
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 func AuthorizeAdmin(entryFetcher EntryFetcher) Authorizer {
-	return adminAuthorizer{entryFetcher: entryFetcher}
+	return AuthorizeScopedAdmin(entryFetcher, nil)
+}
+
+// AuthorizeScopedAdmin authorizes admin workloads that are not restricted
+// to admin scopes, or that are restricted to any of the allowed scopes.
+func AuthorizeScopedAdmin(entryFetcher EntryFetcher, adminScopes api.AdminScopes, allowed ...api.AdminScope) Authorizer {
+	return adminAuthorizer{
+		entryFetcher: entryFetcher,
+		adminScopes:  adminScopes,
+		allowed:      allowed,
+	}
 }
 
 type adminAuthorizer struct {
 	entryFetcher EntryFetcher
+	adminScopes  api.AdminScopes
+	allowed      []api.AdminScope
 }
 
 func (a adminAuthorizer) Name() string {
@@ -37,5 +50,10 @@ func (a adminAuthorizer) AuthorizeCaller(ctx context.Context) (context.Context,
 		return nil, status.Error(codes.PermissionDenied, "caller is not an admin workload")
 	}
 
+	// The caller entries are the entries of the caller SPIFFE ID
+	if id, ok := rpccontext.CallerID(ctx); ok && !a.adminScopes.Allows(id, a.allowed...) {
+		return nil, status.Error(codes.PermissionDenied, "caller admin scopes do not allow this call")
+	}
+
 	return rpccontext.WithCallerAdminEntries(ctx, adminEntries), nil
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/test/spiretest"
@@ -105,3 +106,75 @@ func TestAdminAuthorizer(t *testing.T) {
 		})
 	}
 }
+
+func TestScopedAdminAuthorizer(t *testing.T) {
+	adminID := spiffeid.Must("example.org", "admin")
+	readerID := spiffeid.Must("example.org", "reader")
+	entryManagerID := spiffeid.Must("example.org", "entry-manager")
+	bundleManagerID := spiffeid.Must("example.org", "bundle-manager")
+
+	entryFetcher := middleware.EntryFetcherFunc(
+		func(ctx context.Context, id spiffeid.ID) ([]*types.Entry, error) {
+			return []*types.Entry{{Id: id.Path(), Admin: true}}, nil
+		},
+	)
+	adminScopes := api.AdminScopes{
+		readerID:        {api.ReadOnlyAdminScope},
+		entryManagerID:  {api.EntryManagementAdminScope},
+		bundleManagerID: {api.BundleManagementAdminScope},
+	}
+
+	unrestricted := middleware.AuthorizeScopedAdmin(entryFetcher, adminScopes)
+	read := middleware.AuthorizeScopedAdmin(entryFetcher, adminScopes, api.ReadOnlyAdminScope)
+	readEntries := middleware.AuthorizeScopedAdmin(entryFetcher, adminScopes, api.ReadOnlyAdminScope, api.EntryManagementAdminScope)
+	manageEntries := middleware.AuthorizeScopedAdmin(entryFetcher, adminScopes, api.EntryManagementAdminScope)
+	manageBundles := middleware.AuthorizeScopedAdmin(entryFetcher, adminScopes, api.BundleManagementAdminScope)
+
+	for _, tt := range []struct {
+		name       string
+		id         spiffeid.ID
+		authorizer middleware.Authorizer
+		allowed    bool
+	}{
+		{name: "unrestricted admin calling an admin-only method", id: adminID, authorizer: unrestricted, allowed: true},
+		{name: "unrestricted admin reading", id: adminID, authorizer: read, allowed: true},
+		{name: "unrestricted admin managing entries", id: adminID, authorizer: manageEntries, allowed: true},
+		{name: "unrestricted admin managing bundles", id: adminID, authorizer: manageBundles, allowed: true},
+
+		{name: "read-only admin calling an admin-only method", id: readerID, authorizer: unrestricted},
+		{name: "read-only admin reading", id: readerID, authorizer: read, allowed: true},
+		{name: "read-only admin reading entries", id: readerID, authorizer: readEntries, allowed: true},
+		{name: "read-only admin managing entries", id: readerID, authorizer: manageEntries},
+		{name: "read-only admin managing bundles", id: readerID, authorizer: manageBundles},
+
+		{name: "entry admin calling an admin-only method", id: entryManagerID, authorizer: unrestricted},
+		{name: "entry admin reading", id: entryManagerID, authorizer: read},
+		{name: "entry admin reading entries", id: entryManagerID, authorizer: readEntries, allowed: true},
+		{name: "entry admin managing entries", id: entryManagerID, authorizer: manageEntries, allowed: true},
+		{name: "entry admin managing bundles", id: entryManagerID, authorizer: manageBundles},
+
+		{name: "bundle admin calling an admin-only method", id: bundleManagerID, authorizer: unrestricted},
+		{name: "bundle admin reading", id: bundleManagerID, authorizer: read},
+		{name: "bundle admin reading entries", id: bundleManagerID, authorizer: readEntries},
+		{name: "bundle admin managing entries", id: bundleManagerID, authorizer: manageEntries},
+		{name: "bundle admin managing bundles", id: bundleManagerID, authorizer: manageBundles, allowed: true},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			log, _ := test.NewNullLogger()
+			ctx := rpccontext.WithLogger(context.Background(), log)
+			ctx = rpccontext.WithCallerID(ctx, tt.id)
+
+			ctx, err := tt.authorizer.AuthorizeCaller(ctx)
+			if !tt.allowed {
+				spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, "caller admin scopes do not allow this call")
+				assert.Nil(t, ctx)
+				return
+			}
+			spiretest.RequireGRPCStatus(t, err, codes.OK, "")
+			entries, ok := rpccontext.CallerAdminEntries(ctx)
+			assert.True(t, ok, "context should have admin entries")
+			assert.Equal(t, []*types.Entry{{Id: tt.id.Path(), Admin: true}}, entries)
+		})
+	}
+}
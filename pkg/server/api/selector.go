@@ -2,6 +2,7 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
@@ -41,3 +42,169 @@ func ProtoFromSelectors(in []*common.Selector) []*types.Selector {
 	}
 	return out
 }
+
+// DuplicateSelectorPolicy determines what happens when an entry is created
+// or updated with the same selector more than once.
+type DuplicateSelectorPolicy int
+
+const (
+	// DedupeDuplicateSelectors collapses duplicate selectors into one. This
+	// is the default.
+	DedupeDuplicateSelectors DuplicateSelectorPolicy = iota
+
+	// RejectDuplicateSelectors rejects entries with duplicate selectors.
+	RejectDuplicateSelectors
+)
+
+// ParseDuplicateSelectorPolicy parses a policy name, one of "dedupe" or
+// "reject". An empty name is the default policy.
+func ParseDuplicateSelectorPolicy(name string) (DuplicateSelectorPolicy, error) {
+	switch strings.ToLower(name) {
+	case "", "dedupe":
+		return DedupeDuplicateSelectors, nil
+	case "reject":
+		return RejectDuplicateSelectors, nil
+	default:
+		return 0, fmt.Errorf("unknown duplicate selector policy %q: expected dedupe or reject", name)
+	}
+}
+
+// ApplyDuplicateSelectorPolicy applies the policy to the selectors of an
+// entry. It returns the selectors with duplicates removed, keeping the first
+// occurrence of each, or an error naming the first duplicate if duplicates
+// are rejected. The given slice is not modified.
+func ApplyDuplicateSelectorPolicy(policy DuplicateSelectorPolicy, selectors []*common.Selector) ([]*common.Selector, error) {
+	type key struct{ Type, Value string }
+	seen := make(map[key]bool, len(selectors))
+	deduped := make([]*common.Selector, 0, len(selectors))
+	for _, s := range selectors {
+		k := key{Type: s.Type, Value: s.Value}
+		if seen[k] {
+			if policy == RejectDuplicateSelectors {
+				return nil, fmt.Errorf("duplicate selector %q", s.Type+":"+s.Value)
+			}
+			continue
+		}
+		seen[k] = true
+		deduped = append(deduped, s)
+	}
+	if len(deduped) == len(selectors) {
+		return selectors, nil
+	}
+	return deduped, nil
+}
+
+// UnknownSelectorTypePolicy determines what happens when an agent has
+// selectors of a type the server does not expect while entries are matched
+// against its selectors.
+type UnknownSelectorTypePolicy int
+
+const (
+	// IgnoreUnknownSelectorTypes matches entries without regard to the
+	// selector types of the agent. This is the default.
+	IgnoreUnknownSelectorTypes UnknownSelectorTypePolicy = iota
+
+	// WarnUnknownSelectorTypes matches entries as usual but logs a warning
+	// for agents with unknown selector types.
+	WarnUnknownSelectorTypes
+
+	// RejectUnknownSelectorTypes does not match any entries against the
+	// selectors of agents with unknown selector types.
+	RejectUnknownSelectorTypes
+)
+
+// ParseUnknownSelectorTypePolicy parses a policy name, one of "ignore",
+// "warn" or "reject". An empty name is the default policy.
+func ParseUnknownSelectorTypePolicy(name string) (UnknownSelectorTypePolicy, error) {
+	switch strings.ToLower(name) {
+	case "", "ignore":
+		return IgnoreUnknownSelectorTypes, nil
+	case "warn":
+		return WarnUnknownSelectorTypes, nil
+	case "reject":
+		return RejectUnknownSelectorTypes, nil
+	default:
+		return 0, fmt.Errorf("invalid unknown selector type policy %q: expected ignore, warn or reject", name)
+	}
+}
+
+// NodeSelectorMergePolicy determines how the selectors produced by the node
+// attestor and by the node resolver of an agent are combined when both
+// produce a selector for the same key, i.e. with the same type and the same
+// value up to its last colon (e.g. "tag:Name" for "tag:Name:web").
+type NodeSelectorMergePolicy int
+
+const (
+	// UnionNodeSelectors keeps the selectors of both. This is the default.
+	UnionNodeSelectors NodeSelectorMergePolicy = iota
+
+	// AttestorWinsNodeSelectors drops the resolver selectors with a key the
+	// attestor produced a selector for.
+	AttestorWinsNodeSelectors
+
+	// ResolverWinsNodeSelectors drops the attestor selectors with a key the
+	// resolver produced a selector for.
+	ResolverWinsNodeSelectors
+)
+
+// ParseNodeSelectorMergePolicy parses a policy name, one of "union",
+// "attestor-wins" or "resolver-wins". An empty name is the default policy.
+func ParseNodeSelectorMergePolicy(name string) (NodeSelectorMergePolicy, error) {
+	switch strings.ToLower(name) {
+	case "", "union":
+		return UnionNodeSelectors, nil
+	case "attestor-wins":
+		return AttestorWinsNodeSelectors, nil
+	case "resolver-wins":
+		return ResolverWinsNodeSelectors, nil
+	default:
+		return 0, fmt.Errorf("unknown node selector merge policy %q: expected union, attestor-wins or resolver-wins", name)
+	}
+}
+
+// MergeNodeSelectors combines the selectors produced by the node attestor
+// and the node resolver of an agent according to the policy. The attested
+// selectors come first. Selectors whose value has no colon have no key and
+// never conflict. The given slices are not modified.
+func MergeNodeSelectors(policy NodeSelectorMergePolicy, attested, resolved []*common.Selector) []*common.Selector {
+	switch policy {
+	case AttestorWinsNodeSelectors:
+		resolved = dropConflictingSelectors(resolved, attested)
+	case ResolverWinsNodeSelectors:
+		attested = dropConflictingSelectors(attested, resolved)
+	}
+
+	merged := make([]*common.Selector, 0, len(attested)+len(resolved))
+	merged = append(merged, attested...)
+	return append(merged, resolved...)
+}
+
+// dropConflictingSelectors returns the selectors with the ones sharing a key
+// with any of the winning selectors removed.
+func dropConflictingSelectors(selectors, winners []*common.Selector) []*common.Selector {
+	type key struct{ Type, Key string }
+	winnerKeys := make(map[key]bool, len(winners))
+	for _, s := range winners {
+		if k, ok := selectorKey(s.Value); ok {
+			winnerKeys[key{Type: s.Type, Key: k}] = true
+		}
+	}
+
+	kept := make([]*common.Selector, 0, len(selectors))
+	for _, s := range selectors {
+		if k, ok := selectorKey(s.Value); ok && winnerKeys[key{Type: s.Type, Key: k}] {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	return kept
+}
+
+// selectorKey returns the value of a selector up to its last colon.
+func selectorKey(value string) (string, bool) {
+	i := strings.LastIndex(value, ":")
+	if i < 0 {
+		return "", false
+	}
+	return value[:i], true
+}
@@ -89,3 +89,151 @@ func TestSelectorsFromProto(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDuplicateSelectorPolicy(t *testing.T) {
+	for name, expected := range map[string]api.DuplicateSelectorPolicy{
+		"":       api.DedupeDuplicateSelectors,
+		"dedupe": api.DedupeDuplicateSelectors,
+		"REJECT": api.RejectDuplicateSelectors,
+	} {
+		policy, err := api.ParseDuplicateSelectorPolicy(name)
+		require.NoError(t, err, name)
+		require.Equal(t, expected, policy, name)
+	}
+
+	_, err := api.ParseDuplicateSelectorPolicy("allow")
+	require.EqualError(t, err, `unknown duplicate selector policy "allow": expected dedupe or reject`)
+}
+
+func TestApplyDuplicateSelectorPolicy(t *testing.T) {
+	unique := []*common.Selector{
+		{Type: "unix", Value: "uid:1000"},
+		{Type: "unix", Value: "gid:1000"},
+	}
+	duplicated := []*common.Selector{
+		{Type: "unix", Value: "uid:1000"},
+		{Type: "unix", Value: "gid:1000"},
+		{Type: "unix", Value: "uid:1000"},
+	}
+
+	selectors, err := api.ApplyDuplicateSelectorPolicy(api.DedupeDuplicateSelectors, duplicated)
+	require.NoError(t, err)
+	require.Equal(t, unique, selectors)
+	require.Len(t, duplicated, 3)
+
+	selectors, err = api.ApplyDuplicateSelectorPolicy(api.RejectDuplicateSelectors, unique)
+	require.NoError(t, err)
+	require.Equal(t, unique, selectors)
+
+	selectors, err = api.ApplyDuplicateSelectorPolicy(api.RejectDuplicateSelectors, duplicated)
+	require.EqualError(t, err, `duplicate selector "unix:uid:1000"`)
+	require.Nil(t, selectors)
+
+	// Selectors differing only in type are not duplicates
+	distinct := []*common.Selector{
+		{Type: "unix", Value: "uid:1000"},
+		{Type: "docker", Value: "uid:1000"},
+	}
+	selectors, err = api.ApplyDuplicateSelectorPolicy(api.RejectDuplicateSelectors, distinct)
+	require.NoError(t, err)
+	require.Equal(t, distinct, selectors)
+}
+
+func TestParseUnknownSelectorTypePolicy(t *testing.T) {
+	for name, expected := range map[string]api.UnknownSelectorTypePolicy{
+		"":       api.IgnoreUnknownSelectorTypes,
+		"ignore": api.IgnoreUnknownSelectorTypes,
+		"Warn":   api.WarnUnknownSelectorTypes,
+		"REJECT": api.RejectUnknownSelectorTypes,
+	} {
+		policy, err := api.ParseUnknownSelectorTypePolicy(name)
+		require.NoError(t, err, name)
+		require.Equal(t, expected, policy, name)
+	}
+
+	_, err := api.ParseUnknownSelectorTypePolicy("allow")
+	require.EqualError(t, err, `invalid unknown selector type policy "allow": expected ignore, warn or reject`)
+}
+
+func TestParseNodeSelectorMergePolicy(t *testing.T) {
+	for name, expected := range map[string]api.NodeSelectorMergePolicy{
+		"":              api.UnionNodeSelectors,
+		"union":         api.UnionNodeSelectors,
+		"attestor-wins": api.AttestorWinsNodeSelectors,
+		"Resolver-Wins": api.ResolverWinsNodeSelectors,
+	} {
+		policy, err := api.ParseNodeSelectorMergePolicy(name)
+		require.NoError(t, err, name)
+		require.Equal(t, expected, policy, name)
+	}
+
+	_, err := api.ParseNodeSelectorMergePolicy("attestor")
+	require.EqualError(t, err, `unknown node selector merge policy "attestor": expected union, attestor-wins or resolver-wins`)
+}
+
+func TestMergeNodeSelectors(t *testing.T) {
+	attested := []*common.Selector{
+		{Type: "aws_iid", Value: "tag:Name:web"},
+		{Type: "aws_iid", Value: "sg:id:sg-1"},
+		{Type: "aws_iid", Value: "sg:id:sg-2"},
+		{Type: "aws_iid", Value: "noconflict"},
+	}
+	resolved := []*common.Selector{
+		{Type: "aws_iid", Value: "tag:Name:api"},
+		{Type: "aws_iid", Value: "sg:id:sg-3"},
+		{Type: "aws_iid", Value: "iamrole:arn:aws:iam::123456789012:role/web"},
+		{Type: "aws_iid", Value: "noconflict"},
+		{Type: "other", Value: "tag:Name:db"},
+	}
+
+	for _, tt := range []struct {
+		name   string
+		policy api.NodeSelectorMergePolicy
+		expect []*common.Selector
+	}{
+		{
+			name:   "union",
+			policy: api.UnionNodeSelectors,
+			expect: append(append([]*common.Selector{}, attested...), resolved...),
+		},
+		{
+			name:   "attestor wins",
+			policy: api.AttestorWinsNodeSelectors,
+			expect: []*common.Selector{
+				{Type: "aws_iid", Value: "tag:Name:web"},
+				{Type: "aws_iid", Value: "sg:id:sg-1"},
+				{Type: "aws_iid", Value: "sg:id:sg-2"},
+				{Type: "aws_iid", Value: "noconflict"},
+				{Type: "aws_iid", Value: "iamrole:arn:aws:iam::123456789012:role/web"},
+				{Type: "aws_iid", Value: "noconflict"},
+				{Type: "other", Value: "tag:Name:db"},
+			},
+		},
+		{
+			name:   "resolver wins",
+			policy: api.ResolverWinsNodeSelectors,
+			expect: []*common.Selector{
+				{Type: "aws_iid", Value: "noconflict"},
+				{Type: "aws_iid", Value: "tag:Name:api"},
+				{Type: "aws_iid", Value: "sg:id:sg-3"},
+				{Type: "aws_iid", Value: "iamrole:arn:aws:iam::123456789012:role/web"},
+				{Type: "aws_iid", Value: "noconflict"},
+				{Type: "other", Value: "tag:Name:db"},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			merged := api.MergeNodeSelectors(tt.policy, attested, resolved)
+			require.Equal(t, tt.expect, merged)
+			require.Len(t, attested, 4)
+			require.Len(t, resolved, 5)
+		})
+	}
+
+	// Without conflicting selectors, every policy keeps all of them
+	for _, policy := range []api.NodeSelectorMergePolicy{api.UnionNodeSelectors, api.AttestorWinsNodeSelectors, api.ResolverWinsNodeSelectors} {
+		merged := api.MergeNodeSelectors(policy, attested[:1], resolved[2:3])
+		require.Equal(t, []*common.Selector{attested[0], resolved[2]}, merged)
+	}
+}
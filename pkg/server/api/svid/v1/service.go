@@ -3,6 +3,7 @@ package svid
 import (
 	"context"
 	"crypto/x509"
+	"errors"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -12,14 +13,17 @@ import (
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/jwtsvid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	telemetry_server "github.com/spiffe/spire/pkg/common/telemetry/server"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/issuancehook"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/plugin/datastore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 // RegisterService registers the service on the gRPC server.
@@ -33,15 +37,34 @@ type Config struct {
 	ServerCA     ca.ServerCA
 	TrustDomain  spiffeid.TrustDomain
 	DataStore    datastore.DataStore
+
+	// Metrics receives the TTL samples of the minted SVIDs. Defaults to
+	// discarding them.
+	Metrics telemetry.Metrics
+
+	// JWTKeyTrustDomains are additional trust domains JWT-SVIDs can be
+	// minted for. The CA signs them with the key of their trust domain.
+	JWTKeyTrustDomains []spiffeid.TrustDomain
+
+	// IssuanceHook, if set, is consulted before each SVID is signed and
+	// can deny its issuance.
+	IssuanceHook *issuancehook.Hook
 }
 
 // New creates a new SVID service
 func New(config Config) *Service {
+	if config.Metrics == nil {
+		config.Metrics = telemetry.Blackhole{}
+	}
 	return &Service{
-		ca: config.ServerCA,
-		ef: config.EntryFetcher,
-		td: config.TrustDomain,
-		ds: config.DataStore,
+		ca:      config.ServerCA,
+		ef:      config.EntryFetcher,
+		td:      config.TrustDomain,
+		ds:      config.DataStore,
+		metrics: config.Metrics,
+
+		jwtKeyTrustDomains: config.JWTKeyTrustDomains,
+		issuanceHook:       config.IssuanceHook,
 	}
 }
 
@@ -49,10 +72,14 @@ func New(config Config) *Service {
 type Service struct {
 	svidv1.UnsafeSVIDServer
 
-	ca ca.ServerCA
-	ef api.AuthorizedEntryFetcher
-	td spiffeid.TrustDomain
-	ds datastore.DataStore
+	ca      ca.ServerCA
+	ef      api.AuthorizedEntryFetcher
+	td      spiffeid.TrustDomain
+	ds      datastore.DataStore
+	metrics telemetry.Metrics
+
+	jwtKeyTrustDomains []spiffeid.TrustDomain
+	issuanceHook       *issuancehook.Hook
 }
 
 func (s *Service) MintX509SVID(ctx context.Context, req *svidv1.MintX509SVIDRequest) (*svidv1.MintX509SVIDResponse, error) {
@@ -98,6 +125,19 @@ func (s *Service) MintX509SVID(ctx context.Context, req *svidv1.MintX509SVIDRequ
 		}
 	}
 
+	if err := s.authorizeDelegatedMint(ctx, log, id); err != nil {
+		return nil, err
+	}
+
+	if code, msg, err := s.checkIssuanceHook(ctx, log, issuancehook.Request{
+		SVIDType: issuancehook.X509SVID,
+		SPIFFEID: id.String(),
+		DNSNames: csr.DNSNames,
+		TTL:      req.Ttl,
+	}); err != nil {
+		return nil, api.MakeErr(log, code, msg, err)
+	}
+
 	x509SVID, err := s.ca.SignX509SVID(ctx, ca.X509SVIDParams{
 		SpiffeID:  id,
 		PublicKey: csr.PublicKey,
@@ -108,6 +148,7 @@ func (s *Service) MintX509SVID(ctx context.Context, req *svidv1.MintX509SVIDRequ
 	if err != nil {
 		return nil, api.MakeErr(log, codes.Internal, "failed to sign X509-SVID", err)
 	}
+	s.addX509SVIDTTLSample(id, x509SVID[0])
 
 	return &svidv1.MintX509SVIDResponse{
 		Svid: &types.X509SVID{
@@ -119,7 +160,18 @@ func (s *Service) MintX509SVID(ctx context.Context, req *svidv1.MintX509SVIDRequ
 }
 
 func (s *Service) MintJWTSVID(ctx context.Context, req *svidv1.MintJWTSVIDRequest) (*svidv1.MintJWTSVIDResponse, error) {
-	jwtsvid, err := s.mintJWTSVID(ctx, req.Id, req.Audience, req.Ttl)
+	if rpccontext.CallerIsDownstream(ctx) {
+		log := rpccontext.Logger(ctx)
+		id, err := api.TrustDomainWorkloadIDFromProto(s.td, req.Id)
+		if err != nil {
+			return nil, api.MakeErr(log, codes.InvalidArgument, "invalid SPIFFE ID", err)
+		}
+		if err := s.authorizeDelegatedMint(ctx, log, id); err != nil {
+			return nil, err
+		}
+	}
+
+	jwtsvid, err := s.mintJWTSVID(ctx, req.Id, req.Audience, req.Ttl, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -155,6 +207,39 @@ func (s *Service) BatchNewX509SVID(ctx context.Context, req *svidv1.BatchNewX509
 	return &svidv1.BatchNewX509SVIDResponse{Results: results}, nil
 }
 
+// authorizeDelegatedMint restricts the SVIDs that downstream workloads can
+// mint on behalf of other identities (i.e. delegated issuance) to the SPIFFE
+// IDs of the entries registered as children of their downstream entries.
+// Which identities a downstream workload can mint for is therefore scoped by
+// the selectors of its downstream entries, and by the entries parented to
+// them. Local and admin callers are not restricted.
+func (s *Service) authorizeDelegatedMint(ctx context.Context, log logrus.FieldLogger, id spiffeid.ID) error {
+	downstreamEntries, isDownstream := rpccontext.CallerDownstreamEntries(ctx)
+	if !isDownstream || rpccontext.CallerIsLocal(ctx) || rpccontext.CallerIsAdmin(ctx) {
+		return nil
+	}
+
+	log = log.WithField(telemetry.SPIFFEID, id.String())
+	for _, downstreamEntry := range downstreamEntries {
+		parentID, err := api.TrustDomainWorkloadIDFromProto(s.td, downstreamEntry.SpiffeId)
+		if err != nil {
+			return api.MakeErr(log, codes.Internal, "downstream entry has an invalid SPIFFE ID", err)
+		}
+		resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			ByParentId: &wrapperspb.StringValue{Value: parentID.String()},
+			BySpiffeId: &wrapperspb.StringValue{Value: id.String()},
+		})
+		if err != nil {
+			return api.MakeErr(log, codes.Internal, "failed to list child entries", err)
+		}
+		if len(resp.Entries) > 0 {
+			return nil
+		}
+	}
+
+	return api.MakeErr(log, codes.PermissionDenied, "SPIFFE ID is not registered as a child of the caller's downstream entries", nil)
+}
+
 // fetchEntries fetches authorized entries using caller ID from context
 func (s *Service) fetchEntries(ctx context.Context, log logrus.FieldLogger) (map[string]*types.Entry, error) {
 	callerID, ok := rpccontext.CallerID(ctx)
@@ -221,6 +306,19 @@ func (s *Service) newX509SVID(ctx context.Context, param *svidv1.NewX509SVIDPara
 	}
 	log = log.WithField(telemetry.SPIFFEID, spiffeID.String())
 
+	if code, msg, err := s.checkIssuanceHook(ctx, log, issuancehook.Request{
+		SVIDType:  issuancehook.X509SVID,
+		SPIFFEID:  spiffeID.String(),
+		EntryID:   entry.Id,
+		Selectors: issuanceHookSelectors(entry.Selectors),
+		DNSNames:  entry.DnsNames,
+		TTL:       entry.Ttl,
+	}); err != nil {
+		return &svidv1.BatchNewX509SVIDResponse_Result{
+			Status: api.MakeStatus(log, code, msg, err),
+		}
+	}
+
 	x509Svid, err := s.ca.SignX509SVID(ctx, ca.X509SVIDParams{
 		SpiffeID:  spiffeID,
 		PublicKey: csr.PublicKey,
@@ -232,6 +330,7 @@ func (s *Service) newX509SVID(ctx context.Context, param *svidv1.NewX509SVIDPara
 			Status: api.MakeStatus(log, codes.Internal, "failed to sign X509-SVID", err),
 		}
 	}
+	s.addX509SVIDTTLSample(spiffeID, x509Svid[0])
 
 	return &svidv1.BatchNewX509SVIDResponse_Result{
 		Svid: &types.X509SVID{
@@ -243,10 +342,12 @@ func (s *Service) newX509SVID(ctx context.Context, param *svidv1.NewX509SVIDPara
 	}
 }
 
-func (s *Service) mintJWTSVID(ctx context.Context, protoID *types.SPIFFEID, audience []string, ttl int32) (*types.JWTSVID, error) {
+// mintJWTSVID mints a JWT-SVID for the given ID. The entry the JWT-SVID is
+// minted for, if any, is passed to the issuance hook.
+func (s *Service) mintJWTSVID(ctx context.Context, protoID *types.SPIFFEID, audience []string, ttl int32, entry *types.Entry) (*types.JWTSVID, error) {
 	log := rpccontext.Logger(ctx)
 
-	id, err := api.TrustDomainWorkloadIDFromProto(s.td, protoID)
+	id, err := api.TrustDomainWorkloadIDFromProto(s.jwtSVIDTrustDomain(protoID), protoID)
 	if err != nil {
 		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid SPIFFE ID", err)
 	}
@@ -261,6 +362,17 @@ func (s *Service) mintJWTSVID(ctx context.Context, protoID *types.SPIFFEID, audi
 		return nil, api.MakeErr(log, codes.InvalidArgument, "at least one audience is required", nil)
 	}
 
+	if code, msg, err := s.checkIssuanceHook(ctx, log, issuancehook.Request{
+		SVIDType:  issuancehook.JWTSVID,
+		SPIFFEID:  id.String(),
+		EntryID:   entry.GetId(),
+		Selectors: issuanceHookSelectors(entry.GetSelectors()),
+		Audience:  audience,
+		TTL:       ttl,
+	}); err != nil {
+		return nil, api.MakeErr(log, code, msg, err)
+	}
+
 	token, err := s.ca.SignJWTSVID(ctx, ca.JWTSVIDParams{
 		SpiffeID: id,
 		TTL:      time.Duration(ttl) * time.Second,
@@ -274,6 +386,7 @@ func (s *Service) mintJWTSVID(ctx context.Context, protoID *types.SPIFFEID, audi
 	if err != nil {
 		return nil, api.MakeErr(log, codes.Internal, "failed to get JWT-SVID expiry", err)
 	}
+	telemetry_server.AddSVIDTTLSample(s.metrics, telemetry.JWT, id.TrustDomain().IDString(), expiresAt.Sub(issuedAt))
 
 	return &types.JWTSVID{
 		Token:     token,
@@ -283,6 +396,57 @@ func (s *Service) mintJWTSVID(ctx context.Context, protoID *types.SPIFFEID, audi
 	}, nil
 }
 
+// checkIssuanceHook consults the issuance hook, if configured, about the
+// issuance of an SVID. If the issuance is refused, it returns the code and
+// message of the error to return to the caller.
+func (s *Service) checkIssuanceHook(ctx context.Context, log logrus.FieldLogger, req issuancehook.Request) (codes.Code, string, error) {
+	if s.issuanceHook == nil {
+		return codes.OK, "", nil
+	}
+	if callerID, ok := rpccontext.CallerID(ctx); ok {
+		req.CallerID = callerID.String()
+	}
+
+	err := s.issuanceHook.Check(ctx, log, req)
+	switch {
+	case err == nil:
+		return codes.OK, "", nil
+	case errors.Is(err, issuancehook.ErrDenied):
+		return codes.PermissionDenied, "SVID issuance refused", err
+	default:
+		return codes.Unavailable, "SVID issuance refused", err
+	}
+}
+
+func issuanceHookSelectors(selectors []*types.Selector) []issuancehook.Selector {
+	if len(selectors) == 0 {
+		return nil
+	}
+	out := make([]issuancehook.Selector, 0, len(selectors))
+	for _, selector := range selectors {
+		out = append(out, issuancehook.Selector{Type: selector.Type, Value: selector.Value})
+	}
+	return out
+}
+
+// addX509SVIDTTLSample records the TTL of the minted X509-SVID, i.e. its
+// whole validity period
+func (s *Service) addX509SVIDTTLSample(id spiffeid.ID, svid *x509.Certificate) {
+	telemetry_server.AddSVIDTTLSample(s.metrics, telemetry.X509, id.TrustDomain().IDString(), svid.NotAfter.Sub(svid.NotBefore))
+}
+
+// jwtSVIDTrustDomain returns the trust domain a JWT-SVID for the given ID is
+// minted in, i.e. one of the additional JWT key trust domains if the ID is a
+// member of it, or the server trust domain otherwise.
+func (s *Service) jwtSVIDTrustDomain(protoID *types.SPIFFEID) spiffeid.TrustDomain {
+	for _, td := range s.jwtKeyTrustDomains {
+		if protoID.GetTrustDomain() == td.String() {
+			return td
+		}
+	}
+	return s.td
+}
+
 func (s *Service) NewJWTSVID(ctx context.Context, req *svidv1.NewJWTSVIDRequest) (resp *svidv1.NewJWTSVIDResponse, err error) {
 	log := rpccontext.Logger(ctx)
 
@@ -301,7 +465,7 @@ func (s *Service) NewJWTSVID(ctx context.Context, req *svidv1.NewJWTSVIDRequest)
 		return nil, api.MakeErr(log, codes.NotFound, "entry not found or not authorized", nil)
 	}
 
-	jwtsvid, err := s.mintJWTSVID(ctx, entry.SpiffeId, req.Audience, entry.Ttl)
+	jwtsvid, err := s.mintJWTSVID(ctx, entry.SpiffeId, req.Audience, entry.Ttl, entry)
 	if err != nil {
 		return nil, err
 	}
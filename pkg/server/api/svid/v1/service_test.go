@@ -5,9 +5,13 @@ import (
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
@@ -18,17 +22,23 @@ import (
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	telemetry_server "github.com/spiffe/spire/pkg/common/telemetry/server"
+	"github.com/spiffe/spire/pkg/common/tracing"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/issuancehook"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/api/svid/v1"
 	"github.com/spiffe/spire/pkg/server/plugin/datastore"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
 	"github.com/spiffe/spire/test/fakes/fakeserverca"
+	"github.com/spiffe/spire/test/fakes/faketracing"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/testkey"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -41,6 +51,9 @@ var (
 	td         = spiffeid.RequireTrustDomainFromString("example.org")
 	agentID    = td.NewID("agent")
 	workloadID = td.NewID("workload1")
+
+	// otherTD has its own JWT signing key
+	otherTD = spiffeid.RequireTrustDomainFromString("other.test")
 )
 
 func TestServiceMintX509SVID(t *testing.T) {
@@ -277,6 +290,96 @@ func TestServiceMintX509SVID(t *testing.T) {
 	}
 }
 
+func TestServiceMintX509SVIDTracing(t *testing.T) {
+	exporter := faketracing.New(t)
+
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+	test.withTracing = true
+
+	resp, err := test.client.MintX509SVID(context.Background(), &svidv1.MintX509SVIDRequest{
+		Csr: createCSR(t, &x509.CertificateRequest{
+			URIs: []*url.URL{workloadID.URL()},
+		}),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	test.rpcSpan.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, "ca.SignX509SVID", spans[0].Name)
+	require.Equal(t, "rpc", spans[1].Name)
+	require.Equal(t, spans[1].SpanContext.TraceID(), spans[0].SpanContext.TraceID())
+	require.Equal(t, spans[1].SpanContext.SpanID(), spans[0].ParentSpanID)
+}
+
+func TestServiceMintDelegated(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	downstreamID := td.NewID("downstream")
+	test.downstream.entries = []*types.Entry{
+		{
+			Id:         "downstream",
+			ParentId:   api.ProtoFromID(agentID),
+			SpiffeId:   api.ProtoFromID(downstreamID),
+			Downstream: true,
+		},
+	}
+	_, err := test.ds.CreateRegistrationEntry(context.Background(), &common.RegistrationEntry{
+		ParentId:  downstreamID.String(),
+		SpiffeId:  workloadID.String(),
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	})
+	require.NoError(t, err)
+	// An entry with the same SPIFFE ID parented to someone else does not
+	// authorize the downstream workload
+	otherID := td.NewID("other")
+	_, err = test.ds.CreateRegistrationEntry(context.Background(), &common.RegistrationEntry{
+		ParentId:  agentID.String(),
+		SpiffeId:  otherID.String(),
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	})
+	require.NoError(t, err)
+
+	t.Run("authorized X509-SVID", func(t *testing.T) {
+		resp, err := test.client.MintX509SVID(context.Background(), &svidv1.MintX509SVIDRequest{
+			Csr: createCSR(t, &x509.CertificateRequest{URIs: []*url.URL{workloadID.URL()}}),
+		})
+		require.NoError(t, err)
+		id, err := api.TrustDomainWorkloadIDFromProto(td, resp.Svid.Id)
+		require.NoError(t, err)
+		require.Equal(t, workloadID, id)
+	})
+
+	t.Run("unauthorized X509-SVID", func(t *testing.T) {
+		resp, err := test.client.MintX509SVID(context.Background(), &svidv1.MintX509SVIDRequest{
+			Csr: createCSR(t, &x509.CertificateRequest{URIs: []*url.URL{otherID.URL()}}),
+		})
+		spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, "SPIFFE ID is not registered as a child of the caller's downstream entries")
+		require.Nil(t, resp)
+	})
+
+	t.Run("authorized JWT-SVID", func(t *testing.T) {
+		resp, err := test.client.MintJWTSVID(context.Background(), &svidv1.MintJWTSVIDRequest{
+			Id:       api.ProtoFromID(workloadID),
+			Audience: []string{"AUDIENCE"},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.Svid.Token)
+	})
+
+	t.Run("unauthorized JWT-SVID", func(t *testing.T) {
+		resp, err := test.client.MintJWTSVID(context.Background(), &svidv1.MintJWTSVIDRequest{
+			Id:       api.ProtoFromID(otherID),
+			Audience: []string{"AUDIENCE"},
+		})
+		spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, "SPIFFE ID is not registered as a child of the caller's downstream entries")
+		require.Nil(t, resp)
+	})
+}
+
 func TestServiceMintJWTSVID(t *testing.T) {
 	test := setupServiceTest(t)
 	defer test.Cleanup()
@@ -384,6 +487,226 @@ func TestServiceMintJWTSVID(t *testing.T) {
 	}
 }
 
+func TestServiceMintJWTSVIDForTrustDomainJWTKeys(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	mint := func(id spiffeid.ID) *jwt.JSONWebToken {
+		resp, err := test.client.MintJWTSVID(context.Background(), &svidv1.MintJWTSVIDRequest{
+			Id:       api.ProtoFromID(id),
+			Audience: []string{"AUDIENCE"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, api.ProtoFromID(id), resp.Svid.Id)
+
+		token, err := jwt.ParseSigned(resp.Svid.Token)
+		require.NoError(t, err)
+		require.Len(t, token.Headers, 1)
+		return token
+	}
+
+	// each trust domain has its own signing key
+	token := mint(workloadID)
+	require.Equal(t, "KID", token.Headers[0].KeyID)
+	require.NoError(t, token.Claims(test.ca.JWTKey().Signer.Public(), &jwt.Claims{}))
+
+	otherToken := mint(otherTD.NewID("workload1"))
+	require.Equal(t, "KID-other.test", otherToken.Headers[0].KeyID)
+	otherPublicKey := test.ca.TrustDomainJWTKey(otherTD).Signer.Public()
+	require.NoError(t, otherToken.Claims(otherPublicKey, &jwt.Claims{}))
+	require.Error(t, otherToken.Claims(test.ca.JWTKey().Signer.Public(), &jwt.Claims{}))
+
+	// the additional trust domain is still validated for workload IDs
+	_, err := test.client.MintJWTSVID(context.Background(), &svidv1.MintJWTSVIDRequest{
+		Id:       api.ProtoFromID(otherTD.ID()),
+		Audience: []string{"AUDIENCE"},
+	})
+	spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, `invalid SPIFFE ID: "spiffe://other.test" is not a workload in trust domain "other.test"; path is empty`)
+}
+
+func TestServiceSVIDTTLMetrics(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	x509Resp, err := test.client.MintX509SVID(context.Background(), &svidv1.MintX509SVIDRequest{
+		Csr: createCSR(t, &x509.CertificateRequest{
+			URIs: []*url.URL{workloadID.URL()},
+		}),
+	})
+	require.NoError(t, err)
+	x509SVID, err := x509.ParseCertificate(x509Resp.Svid.CertChain[0])
+	require.NoError(t, err)
+
+	_, err = test.client.MintJWTSVID(context.Background(), &svidv1.MintJWTSVIDRequest{
+		Id:       api.ProtoFromID(workloadID),
+		Audience: []string{"AUDIENCE"},
+		Ttl:      10,
+	})
+	require.NoError(t, err)
+
+	_, err = test.client.MintJWTSVID(context.Background(), &svidv1.MintJWTSVIDRequest{
+		Id:       api.ProtoFromID(otherTD.NewID("workload1")),
+		Audience: []string{"AUDIENCE"},
+	})
+	require.NoError(t, err)
+
+	// the TTLs of the SVIDs are observed under the trust domain of each SVID
+	expected := fakemetrics.New()
+	telemetry_server.AddSVIDTTLSample(expected, telemetry.X509, "spiffe://example.org", x509SVID.NotAfter.Sub(x509SVID.NotBefore))
+	telemetry_server.AddSVIDTTLSample(expected, telemetry.JWT, "spiffe://example.org", 10*time.Second)
+	telemetry_server.AddSVIDTTLSample(expected, telemetry.JWT, "spiffe://other.test", test.ca.JWTSVIDTTL())
+	require.Equal(t, expected.AllMetrics(), test.metrics.AllMetrics())
+}
+
+func TestServiceIssuanceHook(t *testing.T) {
+	// The stub webhook answers with the decision of the current test case
+	var mu sync.Mutex
+	var decision string
+	var inputs []issuancehook.Request
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input issuancehook.Request `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		inputs = append(inputs, body.Input)
+		currentDecision := decision
+		mu.Unlock()
+		switch currentDecision {
+		case "allow":
+			_, _ = w.Write([]byte(`{"result": true}`))
+		case "deny":
+			_, _ = w.Write([]byte(`{"result": {"allow": false, "reason": "workload is quarantined"}}`))
+		case "timeout":
+			<-done
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	test := setupServiceTestWithIssuanceHook(t, issuancehook.New(issuancehook.Config{
+		URL:     serverURL,
+		Timeout: 100 * time.Millisecond,
+	}))
+	defer test.Cleanup()
+
+	entry := &types.Entry{
+		Id:        "workload-entry-id",
+		ParentId:  api.ProtoFromID(agentID),
+		SpiffeId:  api.ProtoFromID(workloadID),
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+		DnsNames:  []string{"workload.example.org"},
+	}
+	test.ef.entries = []*types.Entry{entry}
+	test.withCallerID = true
+
+	for _, tt := range []struct {
+		name     string
+		decision string
+		code     codes.Code
+		err      string
+	}{
+		{
+			name:     "allowed",
+			decision: "allow",
+		},
+		{
+			name:     "denied",
+			decision: "deny",
+			code:     codes.PermissionDenied,
+			err:      "SVID issuance refused: denied by SVID issuance webhook: workload is quarantined",
+		},
+		{
+			name:     "timeout",
+			decision: "timeout",
+			code:     codes.Unavailable,
+			err:      "SVID issuance refused: SVID issuance webhook failed",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			decision = tt.decision
+			inputs = nil
+			mu.Unlock()
+			test.rateLimiter.count = 1
+
+			mintResp, err := test.client.MintX509SVID(context.Background(), &svidv1.MintX509SVIDRequest{
+				Csr: createCSR(t, &x509.CertificateRequest{
+					URIs:     []*url.URL{workloadID.URL()},
+					DNSNames: []string{"mint.example.org"},
+				}),
+				Ttl: 60,
+			})
+			if tt.err != "" {
+				spiretest.RequireGRPCStatusContains(t, err, tt.code, tt.err)
+				require.Nil(t, mintResp)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, mintResp.Svid)
+			}
+
+			batchResp, err := test.client.BatchNewX509SVID(context.Background(), &svidv1.BatchNewX509SVIDRequest{
+				Params: []*svidv1.NewX509SVIDParams{
+					{EntryId: entry.Id, Csr: createCSR(t, &x509.CertificateRequest{})},
+				},
+			})
+			require.NoError(t, err)
+			require.Len(t, batchResp.Results, 1)
+			require.Equal(t, int32(tt.code), batchResp.Results[0].Status.Code)
+			require.Contains(t, batchResp.Results[0].Status.Message, tt.err)
+
+			jwtResp, err := test.client.NewJWTSVID(context.Background(), &svidv1.NewJWTSVIDRequest{
+				EntryId:  entry.Id,
+				Audience: []string{"AUDIENCE"},
+			})
+			if tt.err != "" {
+				spiretest.RequireGRPCStatusContains(t, err, tt.code, tt.err)
+				require.Nil(t, jwtResp)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, jwtResp.Svid)
+			}
+
+			// The webhook is consulted with the context of each issuance
+			mu.Lock()
+			defer mu.Unlock()
+			selectors := []issuancehook.Selector{{Type: "unix", Value: "uid:1000"}}
+			require.Equal(t, []issuancehook.Request{
+				{
+					SVIDType: issuancehook.X509SVID,
+					SPIFFEID: workloadID.String(),
+					CallerID: agentID.String(),
+					DNSNames: []string{"mint.example.org"},
+					TTL:      60,
+				},
+				{
+					SVIDType:  issuancehook.X509SVID,
+					SPIFFEID:  workloadID.String(),
+					CallerID:  agentID.String(),
+					EntryID:   entry.Id,
+					Selectors: selectors,
+					DNSNames:  []string{"workload.example.org"},
+				},
+				{
+					SVIDType:  issuancehook.JWTSVID,
+					SPIFFEID:  workloadID.String(),
+					CallerID:  agentID.String(),
+					EntryID:   entry.Id,
+					Selectors: selectors,
+					Audience:  []string{"AUDIENCE"},
+				},
+			}, inputs)
+		})
+	}
+}
+
 func TestServiceNewJWTSVID(t *testing.T) {
 	test := setupServiceTest(t)
 	defer test.Cleanup()
@@ -1082,9 +1405,12 @@ type serviceTest struct {
 	downstream   *entryFetcher // Stores Downstream entries which end up in the context
 	ca           *fakeserverca.CA
 	ds           *fakedatastore.DataStore
+	metrics      *fakemetrics.FakeMetrics
 	logHook      *test.Hook
 	rateLimiter  *fakeRateLimiter
 	withCallerID bool
+	withTracing  bool
+	rpcSpan      trace.Span
 	done         func()
 }
 
@@ -1093,11 +1419,18 @@ func (c *serviceTest) Cleanup() {
 }
 
 func setupServiceTest(t *testing.T) *serviceTest {
+	return setupServiceTestWithIssuanceHook(t, nil)
+}
+
+func setupServiceTestWithIssuanceHook(t *testing.T, issuanceHook *issuancehook.Hook) *serviceTest {
 	trustDomain := spiffeid.RequireTrustDomainFromString("example.org")
-	ca := fakeserverca.New(t, trustDomain, &fakeserverca.Options{})
+	ca := fakeserverca.New(t, trustDomain, &fakeserverca.Options{
+		JWTKeyTrustDomains: []spiffeid.TrustDomain{otherTD},
+	})
 	ef := &entryFetcher{}
 	downstream := &entryFetcher{}
 	ds := fakedatastore.New(t)
+	metrics := fakemetrics.New()
 
 	rateLimiter := &fakeRateLimiter{}
 	service := svid.New(svid.Config{
@@ -1105,6 +1438,10 @@ func setupServiceTest(t *testing.T) *serviceTest {
 		ServerCA:     ca,
 		TrustDomain:  trustDomain,
 		DataStore:    ds,
+		Metrics:      metrics,
+
+		JWTKeyTrustDomains: []spiffeid.TrustDomain{otherTD},
+		IssuanceHook:       issuanceHook,
 	})
 
 	log, logHook := test.NewNullLogger()
@@ -1117,6 +1454,7 @@ func setupServiceTest(t *testing.T) *serviceTest {
 		ef:          ef,
 		downstream:  downstream,
 		ds:          ds,
+		metrics:     metrics,
 		logHook:     logHook,
 		rateLimiter: rateLimiter,
 	}
@@ -1130,6 +1468,10 @@ func setupServiceTest(t *testing.T) *serviceTest {
 		if test.downstream.entries != nil {
 			ctx = rpccontext.WithCallerDownstreamEntries(ctx, downstream.entries)
 		}
+		if test.withTracing {
+			// Stand in for the tracing middleware
+			ctx, test.rpcSpan = tracing.StartSpan(ctx, "rpc")
+		}
 		return ctx
 	}
 
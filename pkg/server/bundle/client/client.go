@@ -2,10 +2,13 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -15,6 +18,16 @@ import (
 	"github.com/zeebo/errs"
 )
 
+const (
+	// defaultEndpointPort is the port assumed for endpoint addresses that
+	// do not have one, matching the port implied by the https URL.
+	defaultEndpointPort = "443"
+
+	// dialTimeout bounds each connection attempt to an endpoint address so
+	// that an unreachable address does not hold up the alternates.
+	dialTimeout = 10 * time.Second
+)
+
 type SPIFFEAuthConfig struct {
 	// EndpointSpiffeID is the expected SPIFFE ID of the endpoint server. If unset, it
 	// defaults to the SPIRE server ID within the trust domain.
@@ -32,10 +45,21 @@ type ClientConfig struct { //nolint: golint // name stutter is intentional
 	// EndpointAddress is the bundle endpoint for the trust domain.
 	EndpointAddress string
 
+	// AlternateEndpointAddresses are tried, in order, when a connection to
+	// EndpointAddress cannot be established. The endpoint is authenticated
+	// the same way no matter which address serves it (i.e. Web PKI still
+	// verifies the host name in EndpointAddress).
+	AlternateEndpointAddresses []string
+
 	// SPIFFEAuth contains required configuration to authenticate the endpoint
 	// using SPIFFE authentication. If unset, it is assumed that the endpoint
 	// is authenticated via Web PKI.
 	SPIFFEAuth *SPIFFEAuthConfig
+
+	// ClientCertificate, if set, returns the certificate presented to the
+	// endpoint when it requests one (i.e. mutual TLS). It is called on each
+	// handshake so that rotated certificates are picked up.
+	ClientCertificate func() (*tls.Certificate, error)
 }
 
 // Client is used to fetch a bundle and metadata from a bundle endpoint
@@ -49,7 +73,7 @@ type client struct {
 }
 
 func NewClient(config ClientConfig) (Client, error) {
-	httpClient := &http.Client{}
+	var transport *http.Transport
 	if config.SPIFFEAuth != nil {
 		endpointID := config.SPIFFEAuth.EndpointSpiffeID
 		if endpointID.IsZero() {
@@ -60,10 +84,35 @@ func NewClient(config ClientConfig) (Client, error) {
 
 		authorizer := tlsconfig.AuthorizeID(endpointID)
 
-		httpClient.Transport = &http.Transport{
+		transport = &http.Transport{
 			TLSClientConfig: tlsconfig.TLSClientConfig(bundle, authorizer),
 		}
 	}
+
+	if config.ClientCertificate != nil {
+		if transport == nil {
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = &tls.Config{
+				MinVersion: tls.VersionTLS12,
+			}
+		}
+		getClientCertificate := config.ClientCertificate
+		transport.TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return getClientCertificate()
+		}
+	}
+
+	if len(config.AlternateEndpointAddresses) > 0 {
+		if transport == nil {
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		}
+		transport.DialContext = newFailoverDialer(config.EndpointAddress, config.AlternateEndpointAddresses)
+	}
+
+	httpClient := &http.Client{}
+	if transport != nil {
+		httpClient.Transport = transport
+	}
 	return &client{
 		c:      config,
 		client: httpClient,
@@ -71,7 +120,12 @@ func NewClient(config ClientConfig) (Client, error) {
 }
 
 func (c *client) FetchBundle(ctx context.Context) (*bundleutil.Bundle, error) {
-	resp, err := c.client.Get(fmt.Sprintf("https://%s", c.c.EndpointAddress))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s", c.c.EndpointAddress), nil)
+	if err != nil {
+		return nil, errs.New("failed to create bundle request: %v", err)
+	}
+
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, errs.New("failed to fetch bundle: %v", err)
 	}
@@ -89,6 +143,47 @@ func (c *client) FetchBundle(ctx context.Context) (*bundleutil.Bundle, error) {
 	return b, nil
 }
 
+// newFailoverDialer returns a dial function that connects to the first
+// reachable address out of the endpoint address and its alternates. Only
+// connections to the endpoint address fail over; anything else (e.g. a proxy)
+// is dialed as is.
+func newFailoverDialer(endpointAddress string, alternateAddresses []string) func(context.Context, string, string) (net.Conn, error) {
+	endpointAddress = withDefaultPort(endpointAddress)
+	addresses := []string{endpointAddress}
+	for _, address := range alternateAddresses {
+		addresses = append(addresses, withDefaultPort(address))
+	}
+
+	dialer := &net.Dialer{
+		Timeout: dialTimeout,
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if addr != endpointAddress {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		var dialErrs []error
+		for _, address := range addresses {
+			conn, err := dialer.DialContext(ctx, network, address)
+			if err == nil {
+				return conn, nil
+			}
+			dialErrs = append(dialErrs, err)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, errs.Combine(dialErrs...)
+	}
+}
+
+func withDefaultPort(address string) string {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return net.JoinHostPort(address, defaultEndpointPort)
+	}
+	return address
+}
+
 func tryRead(r io.Reader) string {
 	b := make([]byte, 1024)
 	n, _ := r.Read(b)
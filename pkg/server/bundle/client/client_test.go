@@ -5,6 +5,9 @@ import (
 	"crypto"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"log"
 	"math/big"
 	"net"
 	"net/http"
@@ -101,6 +104,202 @@ func TestClient(t *testing.T) {
 	}
 }
 
+func TestClientFailsOverToAlternateAddresses(t *testing.T) {
+	serverCert, serverKey := createServerCertificate(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(`{"spiffe_refresh_hint": 10}`))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{
+			{
+				Certificate: [][]byte{serverCert.Raw},
+				PrivateKey:  serverKey,
+			},
+		},
+		MinVersion: tls.VersionTLS12,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	unreachable := unreachableAddress(t)
+
+	testCases := []struct {
+		name               string
+		spiffeID           spiffeid.ID
+		endpointAddress    string
+		alternateAddresses []string
+		errContains        string
+	}{
+		{
+			name:               "first address unreachable",
+			endpointAddress:    unreachable,
+			alternateAddresses: []string{server.Listener.Addr().String()},
+		},
+		{
+			name:               "first alternate unreachable",
+			endpointAddress:    unreachable,
+			alternateAddresses: []string{unreachable, server.Listener.Addr().String()},
+		},
+		{
+			name:               "endpoint address reachable",
+			endpointAddress:    server.Listener.Addr().String(),
+			alternateAddresses: []string{unreachable},
+		},
+		{
+			name:               "all addresses unreachable",
+			endpointAddress:    unreachable,
+			alternateAddresses: []string{unreachable},
+			errContains:        "connection refused",
+		},
+		{
+			name:               "alternate address is authenticated",
+			spiffeID:           spiffeid.RequireTrustDomainFromString("otherdomain.test").ID(),
+			endpointAddress:    unreachable,
+			alternateAddresses: []string{server.Listener.Addr().String()},
+			errContains:        `unexpected ID "spiffe://domain.test/spire/server"`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			client, err := NewClient(ClientConfig{
+				TrustDomain:                trustDomain,
+				EndpointAddress:            testCase.endpointAddress,
+				AlternateEndpointAddresses: testCase.alternateAddresses,
+				SPIFFEAuth: &SPIFFEAuthConfig{
+					EndpointSpiffeID: testCase.spiffeID,
+					RootCAs:          []*x509.Certificate{serverCert},
+				},
+			})
+			require.NoError(t, err)
+
+			bundle, err := client.FetchBundle(context.Background())
+			if testCase.errContains != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), testCase.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, trustDomain.IDString(), bundle.TrustDomainID())
+		})
+	}
+}
+
+func TestClientPresentsClientCertificate(t *testing.T) {
+	serverCert, serverKey := createServerCertificate(t)
+	clientCert, clientKey := createClientCertificate(t)
+	otherCert, otherKey := createClientCertificate(t)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(`{"spiffe_refresh_hint": 10}`))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{
+			{
+				Certificate: [][]byte{serverCert.Raw},
+				PrivateKey:  serverKey,
+			},
+		},
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+	// Silence the handshake errors logged for the rejected clients
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	testCases := []struct {
+		name              string
+		clientCertificate func() (*tls.Certificate, error)
+		errContains       string
+	}{
+		{
+			name: "trusted client certificate",
+			clientCertificate: func() (*tls.Certificate, error) {
+				return &tls.Certificate{
+					Certificate: [][]byte{clientCert.Raw},
+					PrivateKey:  clientKey,
+				}, nil
+			},
+		},
+		{
+			name: "untrusted client certificate",
+			clientCertificate: func() (*tls.Certificate, error) {
+				return &tls.Certificate{
+					Certificate: [][]byte{otherCert.Raw},
+					PrivateKey:  otherKey,
+				}, nil
+			},
+			errContains: "failed to fetch bundle",
+		},
+		{
+			name:        "no client certificate",
+			errContains: "failed to fetch bundle",
+		},
+		{
+			name: "client certificate unavailable",
+			clientCertificate: func() (*tls.Certificate, error) {
+				return nil, errors.New("ohno")
+			},
+			errContains: "ohno",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			client, err := NewClient(ClientConfig{
+				TrustDomain:     trustDomain,
+				EndpointAddress: server.Listener.Addr().String(),
+				SPIFFEAuth: &SPIFFEAuthConfig{
+					RootCAs: []*x509.Certificate{serverCert},
+				},
+				ClientCertificate: testCase.clientCertificate,
+			})
+			require.NoError(t, err)
+
+			bundle, err := client.FetchBundle(context.Background())
+			if testCase.errContains != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), testCase.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, trustDomain.IDString(), bundle.TrustDomainID())
+		})
+	}
+}
+
+// unreachableAddress returns the address of a listener that has been closed,
+// so connections to it are refused.
+func unreachableAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return address
+}
+
+func TestWithDefaultPort(t *testing.T) {
+	require.Equal(t, "domain.test:443", withDefaultPort("domain.test"))
+	require.Equal(t, "domain.test:8443", withDefaultPort("domain.test:8443"))
+	require.Equal(t, "[::1]:443", withDefaultPort("::1"))
+}
+
+func createClientCertificate(t *testing.T) (*x509.Certificate, crypto.Signer) {
+	return spiretest.SelfSignCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(0),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		URIs:         []*url.URL{idutil.ServerID(spiffeid.RequireTrustDomainFromString("example.org")).URL()},
+	})
+}
+
 func createServerCertificate(t *testing.T) (*x509.Certificate, crypto.Signer) {
 	return spiretest.SelfSignCertificate(t, &x509.Certificate{
 		SerialNumber: big.NewInt(0),
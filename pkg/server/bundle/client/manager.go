@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"time"
 
 	"github.com/andres-erbsen/clock"
@@ -20,12 +21,20 @@ const (
 	// bundle. It is important to try more than once within a refresh hint
 	// period so we can be resilient to temporary downtime or failures.
 	attemptsPerRefreshHint = 4
+
+	// minimumRefresh is the shortest time the manager waits between
+	// refreshes, no matter how close to expiring the bundle keys are.
+	minimumRefresh = bundleutil.MinimumRefreshHint / attemptsPerRefreshHint
 )
 
 type TrustDomainConfig struct {
 	// EndpointAddress is the bundle endpoint for the trust domain.
 	EndpointAddress string
 
+	// AlternateEndpointAddresses are tried, in order, when the bundle
+	// endpoint cannot be reached at EndpointAddress.
+	AlternateEndpointAddresses []string
+
 	// EndpointSpiffeID is the expected SPIFFE ID of the endpoint server. If
 	// unset, it defaults to the SPIRE server ID within the trust domain.
 	EndpointSpiffeID spiffeid.ID
@@ -33,6 +42,15 @@ type TrustDomainConfig struct {
 	// UseWebPKI is true if the endpoint should be authenticated with Web PKI.
 	// Otherwise, SPIFFE authentication is assumed.
 	UseWebPKI bool
+
+	// ClientCertificate, if set, is presented to the endpoint when it
+	// requests a client certificate.
+	ClientCertificate *tls.Certificate
+
+	// UseServerSVID is true if the server X509-SVID should be presented to
+	// the endpoint when it requests a client certificate. It cannot be used
+	// along with ClientCertificate.
+	UseServerSVID bool
 }
 
 type ManagerConfig struct {
@@ -42,6 +60,10 @@ type ManagerConfig struct {
 	Clock        clock.Clock
 	TrustDomains map[spiffeid.TrustDomain]TrustDomainConfig
 
+	// ServerSVID returns the current server X509-SVID. It is required by
+	// trust domains configured with UseServerSVID.
+	ServerSVID func() (*tls.Certificate, error)
+
 	// newBundleUpdater is a test hook to inject updater behavior
 	newBundleUpdater func(BundleUpdaterConfig) BundleUpdater
 }
@@ -49,6 +71,7 @@ type ManagerConfig struct {
 type Manager struct {
 	log      logrus.FieldLogger
 	metrics  telemetry.Metrics
+	ds       datastore.DataStore
 	clock    clock.Clock
 	updaters map[spiffeid.TrustDomain]BundleUpdater
 }
@@ -67,18 +90,22 @@ func NewManager(config ManagerConfig) *Manager {
 			TrustDomainConfig: trustDomainConfig,
 			TrustDomain:       trustDomain,
 			DataStore:         config.DataStore,
+			ServerSVID:        config.ServerSVID,
 		})
 	}
 
 	return &Manager{
 		log:      config.Log,
 		metrics:  config.Metrics,
+		ds:       config.DataStore,
 		clock:    config.Clock,
 		updaters: updaters,
 	}
 }
 
 func (m *Manager) Run(ctx context.Context) error {
+	m.loadStoredBundles(ctx)
+
 	var tasks []func(context.Context) error
 	for trustDomain, updater := range m.updaters {
 		// alias the loop variables that are used by the closure
@@ -92,6 +119,25 @@ func (m *Manager) Run(ctx context.Context) error {
 	return util.RunTasks(ctx, tasks...)
 }
 
+// loadStoredBundles loads the bundles stored by previous runs for each of the
+// trust domains. Since federated bundles are served from the datastore, the
+// stored bundles are served right away after a restart, without waiting for
+// the first refresh to complete. Every refresh that succeeds replaces them.
+func (m *Manager) loadStoredBundles(ctx context.Context) {
+	for trustDomain := range m.updaters {
+		log := m.log.WithField("trust_domain", trustDomain)
+		bundle, err := fetchBundleIfExists(ctx, m.ds, trustDomain)
+		switch {
+		case err != nil:
+			log.WithError(err).Warn("Failed to load stored bundle")
+		case bundle == nil:
+			log.Warn("No stored bundle; federation is unavailable until the first bundle refresh completes")
+		default:
+			log.Info("Serving stored bundle until the first bundle refresh completes")
+		}
+	}
+}
+
 func (m *Manager) runUpdater(ctx context.Context, trustDomain spiffeid.TrustDomain, updater BundleUpdater) error {
 	log := m.log.WithField("trust_domain", trustDomain)
 	for {
@@ -106,9 +152,9 @@ func (m *Manager) runUpdater(ctx context.Context, trustDomain spiffeid.TrustDoma
 		case endpointBundle != nil:
 			telemetry_server.IncrBundleManagerUpdateFederatedBundleCounter(m.metrics, trustDomain.String())
 			log.Info("Bundle refreshed")
-			nextRefresh = calculateNextUpdate(endpointBundle)
+			nextRefresh = calculateNextUpdate(endpointBundle, m.clock.Now())
 		case localBundle != nil:
-			nextRefresh = calculateNextUpdate(localBundle)
+			nextRefresh = calculateNextUpdate(localBundle, m.clock.Now())
 		default:
 			// We have no bundle to use to calculate the refresh hint. Since
 			// the endpoint cannot be reached without the local bundle (until
@@ -119,6 +165,7 @@ func (m *Manager) runUpdater(ctx context.Context, trustDomain spiffeid.TrustDoma
 			nextRefresh = bundleutil.MinimumRefreshHint
 		}
 
+		telemetry_server.SetBundleManagerFederatedBundleRefreshGauge(m.metrics, trustDomain.String(), float32(nextRefresh.Seconds()))
 		log.WithFields(logrus.Fields{
 			"at": m.clock.Now().Add(nextRefresh).UTC().Format(time.RFC3339),
 		}).Debug("Scheduling next bundle refresh")
@@ -133,6 +180,54 @@ func (m *Manager) runUpdater(ctx context.Context, trustDomain spiffeid.TrustDoma
 	}
 }
 
-func calculateNextUpdate(b *bundleutil.Bundle) time.Duration {
-	return bundleutil.CalculateRefreshHint(b) / attemptsPerRefreshHint
+// calculateNextUpdate returns how long to wait before refreshing the bundle.
+// The refresh hint is split into a few attempts. When the soonest bundle key
+// expires before the refresh hint elapses, the remaining time until that key
+// expires is split instead, so the bundle is refreshed more often as the
+// expiration approaches and the replacement keys are picked up in time. Keys
+// that have already expired are left out, since refreshing cannot renew them.
+func calculateNextUpdate(b *bundleutil.Bundle, now time.Time) time.Duration {
+	nextUpdate := bundleutil.CalculateRefreshHint(b) / attemptsPerRefreshHint
+
+	expiresAt, ok := soonestKeyExpiration(b, now)
+	if !ok {
+		return nextUpdate
+	}
+	untilExpiration := expiresAt.Sub(now) / attemptsPerRefreshHint
+	switch {
+	case untilExpiration >= nextUpdate:
+		return nextUpdate
+	case untilExpiration < minimumRefresh:
+		return minimumRefresh
+	default:
+		return untilExpiration
+	}
+}
+
+// soonestKeyExpiration returns when the first root CA or JWT signing key of
+// the bundle that has not expired by now expires. JWT signing keys without an
+// expiration are ignored.
+func soonestKeyExpiration(b *bundleutil.Bundle, now time.Time) (time.Time, bool) {
+	var soonest time.Time
+	for _, rootCA := range b.RootCAs() {
+		if rootCA.NotAfter.Before(now) {
+			continue
+		}
+		if soonest.IsZero() || rootCA.NotAfter.Before(soonest) {
+			soonest = rootCA.NotAfter
+		}
+	}
+	for _, jwtSigningKey := range b.Proto().JwtSigningKeys {
+		if jwtSigningKey.NotAfter == 0 {
+			continue
+		}
+		notAfter := time.Unix(jwtSigningKey.NotAfter, 0)
+		if notAfter.Before(now) {
+			continue
+		}
+		if soonest.IsZero() || notAfter.Before(soonest) {
+			soonest = notAfter
+		}
+	}
+	return soonest, !soonest.IsZero()
 }
@@ -7,12 +7,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/plugin/datastore"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/require"
 	"github.com/zeebo/errs"
 	"gotest.tools/assert"
@@ -20,10 +23,12 @@ import (
 
 func TestManager(t *testing.T) {
 	// create a pair of bundles with distinct refresh hints so we can assert
-	// that the manager selected the correct refresh hint.
-	localBundle := bundleutil.BundleFromRootCA(trustDomain, createCACertificate(t, "local"))
+	// that the manager selected the correct refresh hint. The certificates
+	// expire long after the refresh hints so they don't shorten the refresh.
+	expiresAt := time.Now().Add(48 * time.Hour)
+	localBundle := bundleutil.BundleFromRootCA(trustDomain, createCACertificateExpiringAt(t, "local", expiresAt))
 	localBundle.SetRefreshHint(time.Hour)
-	endpointBundle := bundleutil.BundleFromRootCA(trustDomain, createCACertificate(t, "endpoint"))
+	endpointBundle := bundleutil.BundleFromRootCA(trustDomain, createCACertificateExpiringAt(t, "endpoint", expiresAt))
 	endpointBundle.SetRefreshHint(time.Hour * 2)
 
 	testCases := []struct {
@@ -39,13 +44,13 @@ func TestManager(t *testing.T) {
 		{
 			name:        "update failed to obtain endpoint bundle",
 			localBundle: localBundle,
-			nextRefresh: calculateNextUpdate(localBundle),
+			nextRefresh: time.Hour / attemptsPerRefreshHint,
 		},
 		{
 			name:           "update obtained endpoint bundle",
 			localBundle:    localBundle,
 			endpointBundle: endpointBundle,
-			nextRefresh:    calculateNextUpdate(endpointBundle),
+			nextRefresh:    time.Hour * 2 / attemptsPerRefreshHint,
 		},
 	}
 
@@ -71,6 +76,170 @@ func TestManager(t *testing.T) {
 	}
 }
 
+func TestManagerRefreshesSoonerAsKeysExpire(t *testing.T) {
+	testCases := []struct {
+		name          string
+		expiresIn     time.Duration
+		expiredRoot   bool
+		firstRefresh  time.Duration
+		secondRefresh time.Duration
+	}{
+		{
+			name:          "keys expire long after the refresh hint",
+			expiresIn:     48 * time.Hour,
+			firstRefresh:  30 * time.Minute,
+			secondRefresh: 30 * time.Minute,
+		},
+		{
+			name:          "keys expire before the refresh hint",
+			expiresIn:     40 * time.Minute,
+			firstRefresh:  10 * time.Minute,
+			secondRefresh: 30 * time.Minute / attemptsPerRefreshHint,
+		},
+		{
+			name:          "keys are about to expire",
+			expiresIn:     30 * time.Second,
+			firstRefresh:  minimumRefresh,
+			secondRefresh: minimumRefresh,
+		},
+		{
+			name:          "keys expired",
+			expiresIn:     -time.Hour,
+			firstRefresh:  30 * time.Minute,
+			secondRefresh: 30 * time.Minute,
+		},
+		{
+			name:          "expired root kept next to a valid one",
+			expiresIn:     48 * time.Hour,
+			expiredRoot:   true,
+			firstRefresh:  30 * time.Minute,
+			secondRefresh: 30 * time.Minute,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			clock := clock.NewMock(t)
+
+			bundle := bundleutil.BundleFromRootCA(trustDomain, createCACertificateExpiringAt(t, "endpoint", clock.Now().Add(testCase.expiresIn)))
+			if testCase.expiredRoot {
+				bundle.AppendRootCA(createCACertificateExpiringAt(t, "expired", clock.Now().Add(-time.Hour)))
+			}
+			bundle.SetRefreshHint(2 * time.Hour)
+			updater := newFakeBundleUpdater(bundle, bundle)
+
+			done := startManager(t, clock, updater)
+			defer done()
+
+			waitForRefresh(t, clock, testCase.firstRefresh)
+			require.Equal(t, 1, updater.UpdateCount())
+
+			clock.Add(testCase.firstRefresh + time.Millisecond)
+			waitForRefresh(t, clock, testCase.secondRefresh)
+			require.Equal(t, 2, updater.UpdateCount())
+		})
+	}
+}
+
+func TestCalculateNextUpdateUsesJWTSigningKeyExpiration(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	rootCA := createCACertificateExpiringAt(t, "root", now.Add(48*time.Hour))
+	bundle := bundleutil.BundleFromRootCA(trustDomain, rootCA)
+	bundle.SetRefreshHint(2 * time.Hour)
+	require.Equal(t, 30*time.Minute, calculateNextUpdate(bundle, now))
+
+	// JWT signing keys without an expiration do not shorten the refresh
+	require.NoError(t, bundle.AppendJWTSigningKey("never-expires", rootCA.PublicKey))
+	require.Equal(t, 30*time.Minute, calculateNextUpdate(bundle, now))
+
+	require.NoError(t, bundle.AppendJWTSigningKey("expires", rootCA.PublicKey))
+	bundleProto := bundle.Proto()
+	bundleProto.JwtSigningKeys[1].NotAfter = now.Add(20 * time.Minute).Unix()
+	bundle, err := bundleutil.BundleFromProto(bundleProto)
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Minute, calculateNextUpdate(bundle, now))
+
+	// JWT signing keys that already expired are ignored
+	require.NoError(t, bundle.AppendJWTSigningKey("expired", rootCA.PublicKey))
+	bundleProto = bundle.Proto()
+	bundleProto.JwtSigningKeys[2].NotAfter = now.Add(-time.Hour).Unix()
+	bundle, err = bundleutil.BundleFromProto(bundleProto)
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Minute, calculateNextUpdate(bundle, now))
+}
+
+func TestManagerServesStoredBundlesBeforeRefresh(t *testing.T) {
+	// Simulate a restart: the bundle fetched by the previous run is in the
+	// datastore and the endpoint does not respond until the test is over.
+	storedBundle := bundleutil.BundleFromRootCA(trustDomain, createCACertificate(t, "stored"))
+	storedBundle.SetRefreshHint(time.Hour)
+
+	ds := fakedatastore.New(t)
+	_, err := ds.SetBundle(context.Background(), &datastore.SetBundleRequest{
+		Bundle: storedBundle.Proto(),
+	})
+	require.NoError(t, err)
+
+	log, hook := test.NewNullLogger()
+	fetching := make(chan struct{}, 1)
+	manager := NewManager(ManagerConfig{
+		Log:       log,
+		Metrics:   telemetry.Blackhole{},
+		DataStore: ds,
+		Clock:     clock.NewMock(t),
+		TrustDomains: map[spiffeid.TrustDomain]TrustDomainConfig{
+			trustDomain: {
+				EndpointAddress:  "ENDPOINT_ADDRESS",
+				EndpointSpiffeID: spiffeid.RequireFromString("spiffe://domain.test/spire/server"),
+			},
+		},
+		newBundleUpdater: func(config BundleUpdaterConfig) BundleUpdater {
+			config.newClient = func(ClientConfig) (Client, error) {
+				return blockingClient{fetching: fetching}, nil
+			}
+			return NewBundleUpdater(config)
+		},
+	})
+	done := runManager(t, manager)
+	defer done()
+
+	select {
+	case <-fetching:
+	case <-time.After(time.Second * 10):
+		require.Fail(t, "timed out waiting for the first refresh")
+	}
+
+	// The first refresh is still in flight but the stored bundle is served
+	bundle, err := ds.FetchBundle(context.Background(), trustDomain.IDString())
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, storedBundle.Proto(), bundle)
+	entries := hook.AllEntries()
+	require.NotEmpty(t, entries)
+	require.Equal(t, "Serving stored bundle until the first bundle refresh completes", entries[0].Message)
+}
+
+func TestManagerWarnsWithoutStoredBundles(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	manager := NewManager(ManagerConfig{
+		Log:       log,
+		Metrics:   telemetry.Blackhole{},
+		DataStore: fakedatastore.New(t),
+		Clock:     clock.NewMock(t),
+		TrustDomains: map[spiffeid.TrustDomain]TrustDomainConfig{
+			trustDomain: {},
+		},
+		newBundleUpdater: func(BundleUpdaterConfig) BundleUpdater {
+			return newFakeBundleUpdater(nil, nil)
+		},
+	})
+
+	manager.loadStoredBundles(context.Background())
+	require.Len(t, hook.Entries, 1)
+	require.Equal(t, logrus.WarnLevel, hook.Entries[0].Level)
+	require.Equal(t, "No stored bundle; federation is unavailable until the first bundle refresh completes", hook.Entries[0].Message)
+}
+
 func startManager(t *testing.T, clock clock.Clock, updater BundleUpdater) func() {
 	log, _ := test.NewNullLogger()
 	ds := fakedatastore.New(t)
@@ -90,12 +259,15 @@ func startManager(t *testing.T, clock clock.Clock, updater BundleUpdater) func()
 			trustDomain: trustDomainConfig,
 		},
 		newBundleUpdater: func(config BundleUpdaterConfig) BundleUpdater {
-			assert.Equal(t, trustDomainConfig, config.TrustDomainConfig)
+			require.Equal(t, trustDomainConfig, config.TrustDomainConfig)
 			assert.Equal(t, trustDomain, config.TrustDomain)
 			return updater
 		},
 	})
+	return runManager(t, manager)
+}
 
+func runManager(t *testing.T, manager *Manager) func() {
 	ctx, cancel := context.WithCancel(context.Background())
 	errCh := make(chan error, 1)
 	go func() {
@@ -154,3 +326,14 @@ func (u *fakeBundleUpdater) UpdateBundle(context.Context) (*bundleutil.Bundle, *
 	u.updateCount++
 	return u.localBundle, u.endpointBundle, errors.New("UNUSED")
 }
+
+// blockingClient is a client for an endpoint that does not respond
+type blockingClient struct {
+	fetching chan struct{}
+}
+
+func (c blockingClient) FetchBundle(ctx context.Context) (*bundleutil.Bundle, error) {
+	c.fetching <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
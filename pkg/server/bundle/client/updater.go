@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -16,6 +17,10 @@ type BundleUpdaterConfig struct {
 	TrustDomain spiffeid.TrustDomain
 	DataStore   datastore.DataStore
 
+	// ServerSVID returns the current server X509-SVID, presented to the
+	// endpoint when UseServerSVID is set.
+	ServerSVID func() (*tls.Certificate, error)
+
 	// newClient is a test hook for injecting client behavior
 	newClient func(ClientConfig) (Client, error)
 }
@@ -78,8 +83,9 @@ func (u *bundleUpdater) UpdateBundle(ctx context.Context) (*bundleutil.Bundle, *
 
 func (u *bundleUpdater) newClient(localBundleOrNil *bundleutil.Bundle) (Client, error) {
 	config := ClientConfig{
-		TrustDomain:     u.c.TrustDomain,
-		EndpointAddress: u.c.EndpointAddress,
+		TrustDomain:                u.c.TrustDomain,
+		EndpointAddress:            u.c.EndpointAddress,
+		AlternateEndpointAddresses: u.c.AlternateEndpointAddresses,
 	}
 	if !u.c.UseWebPKI {
 		if localBundleOrNil == nil {
@@ -90,6 +96,18 @@ func (u *bundleUpdater) newClient(localBundleOrNil *bundleutil.Bundle) (Client,
 			RootCAs:          localBundleOrNil.RootCAs(),
 		}
 	}
+	switch {
+	case u.c.ClientCertificate != nil:
+		clientCertificate := u.c.ClientCertificate
+		config.ClientCertificate = func() (*tls.Certificate, error) {
+			return clientCertificate, nil
+		}
+	case u.c.UseServerSVID:
+		if u.c.ServerSVID == nil {
+			return nil, errs.New("server SVID is not available")
+		}
+		config.ClientCertificate = u.c.ServerSVID
+	}
 	return u.c.newClient(config)
 }
 
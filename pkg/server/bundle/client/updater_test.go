@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"errors"
@@ -131,6 +132,83 @@ func TestBundleUpdater(t *testing.T) {
 	}
 }
 
+func TestBundleUpdaterClientCertificate(t *testing.T) {
+	trustDomain := spiffeid.RequireTrustDomainFromString("domain.test")
+	clientCertificate := &tls.Certificate{Certificate: [][]byte{[]byte("CLIENT")}}
+	serverSVID := &tls.Certificate{Certificate: [][]byte{[]byte("SERVER")}}
+
+	testCases := []struct {
+		name              string
+		trustDomainConfig TrustDomainConfig
+		serverSVID        func() (*tls.Certificate, error)
+		expected          *tls.Certificate
+		err               string
+	}{
+		{
+			name: "no client certificate",
+		},
+		{
+			name: "configured client certificate",
+			trustDomainConfig: TrustDomainConfig{
+				ClientCertificate: clientCertificate,
+			},
+			expected: clientCertificate,
+		},
+		{
+			name: "server SVID",
+			trustDomainConfig: TrustDomainConfig{
+				UseServerSVID: true,
+			},
+			serverSVID: func() (*tls.Certificate, error) {
+				return serverSVID, nil
+			},
+			expected: serverSVID,
+		},
+		{
+			name: "server SVID not available",
+			trustDomainConfig: TrustDomainConfig{
+				UseServerSVID: true,
+			},
+			err: "server SVID is not available",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.trustDomainConfig.UseWebPKI = true
+
+			var clientConfig ClientConfig
+			updater := NewBundleUpdater(BundleUpdaterConfig{
+				DataStore:         fakedatastore.New(t),
+				TrustDomain:       trustDomain,
+				TrustDomainConfig: testCase.trustDomainConfig,
+				ServerSVID:        testCase.serverSVID,
+				newClient: func(config ClientConfig) (Client, error) {
+					clientConfig = config
+					return fakeClient{err: errors.New("ohno")}, nil
+				},
+			})
+
+			_, _, err := updater.UpdateBundle(context.Background())
+			if testCase.err != "" {
+				spiretest.RequireErrorContains(t, err, testCase.err)
+				return
+			}
+			spiretest.RequireErrorContains(t, err, "ohno")
+
+			if testCase.expected == nil {
+				require.Nil(t, clientConfig.ClientCertificate)
+				return
+			}
+			require.NotNil(t, clientConfig.ClientCertificate)
+			cert, err := clientConfig.ClientCertificate()
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, cert)
+		})
+	}
+}
+
 type fakeClient struct {
 	bundle *bundleutil.Bundle
 	err    error
@@ -141,11 +219,14 @@ func (c fakeClient) FetchBundle(context.Context) (*bundleutil.Bundle, error) {
 }
 
 func createCACertificate(t *testing.T, cn string) *x509.Certificate {
-	now := time.Now()
+	return createCACertificateExpiringAt(t, cn, time.Now().Add(time.Hour))
+}
+
+func createCACertificateExpiringAt(t *testing.T, cn string, notAfter time.Time) *x509.Certificate {
 	cert, _ := spiretest.SelfSignCertificate(t, &x509.Certificate{
 		SerialNumber: big.NewInt(0),
-		NotBefore:    now,
-		NotAfter:     now.Add(time.Hour),
+		NotBefore:    notAfter.Add(-48 * time.Hour),
+		NotAfter:     notAfter,
 		IsCA:         true,
 		Subject:      pkix.Name{CommonName: cn},
 	})
@@ -14,9 +14,11 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/health"
+	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/jwtsvid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	telemetry_server "github.com/spiffe/spire/pkg/common/telemetry/server"
+	"github.com/spiffe/spire/pkg/common/tracing"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/zeebo/errs"
@@ -119,6 +121,25 @@ type Config struct {
 	Clock         clock.Clock
 	CASubject     pkix.Name
 	HealthChecker health.Checker
+
+	// SecondaryTrustDomain, if set, causes workload X509-SVIDs to carry an
+	// additional URI SAN with the same path in the secondary trust domain.
+	// Server and agent SVIDs are not affected. It is meant to be used
+	// temporarily while migrating to a new trust domain.
+	SecondaryTrustDomain spiffeid.TrustDomain
+
+	// SerialNumberGenerator generates the serial numbers of the X509-SVIDs
+	// and X509 CA SVIDs signed by the CA. Defaults to random serial numbers.
+	SerialNumberGenerator x509util.SerialNumberGenerator
+
+	// FIPSMode, if set, restricts the keys certified by the CA to
+	// FIPS-approved algorithms.
+	FIPSMode bool
+
+	// JWTKeyTrustDomains are additional trust domains the CA signs
+	// JWT-SVIDs for, each with its own JWT signing key. JWT-SVIDs for the
+	// server trust domain are signed with the key set by SetJWTKey.
+	JWTKeyTrustDomains []spiffeid.TrustDomain
 }
 
 type CA struct {
@@ -128,6 +149,8 @@ type CA struct {
 	x509CA *X509CA
 	jwtKey *JWTKey
 
+	trustDomainJWTKeys map[spiffeid.TrustDomain]*JWTKey
+
 	jwtSigner *jwtsvid.Signer
 }
 
@@ -141,9 +164,13 @@ func NewCA(config Config) *CA {
 	if config.Clock == nil {
 		config.Clock = clock.New()
 	}
+	if config.SerialNumberGenerator == nil {
+		config.SerialNumberGenerator, _ = x509util.NewSerialNumberGenerator(x509util.SerialNumberRandom)
+	}
 
 	ca := &CA{
-		c: config,
+		c:                  config,
+		trustDomainJWTKeys: make(map[spiffeid.TrustDomain]*JWTKey),
 		jwtSigner: jwtsvid.NewSigner(jwtsvid.SignerConfig{
 			Clock:  config.Clock,
 			Issuer: config.JWTIssuer,
@@ -182,7 +209,26 @@ func (ca *CA) SetJWTKey(jwtKey *JWTKey) {
 	ca.jwtKey = jwtKey
 }
 
-func (ca *CA) SignX509SVID(ctx context.Context, params X509SVIDParams) ([]*x509.Certificate, error) {
+// TrustDomainJWTKey returns the JWT key used to sign JWT-SVIDs for the given
+// additional trust domain, if any.
+func (ca *CA) TrustDomainJWTKey(td spiffeid.TrustDomain) *JWTKey {
+	ca.mu.RLock()
+	defer ca.mu.RUnlock()
+	return ca.trustDomainJWTKeys[td]
+}
+
+// SetTrustDomainJWTKey sets the JWT key used to sign JWT-SVIDs for the given
+// additional trust domain.
+func (ca *CA) SetTrustDomainJWTKey(td spiffeid.TrustDomain, jwtKey *JWTKey) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.trustDomainJWTKeys[td] = jwtKey
+}
+
+func (ca *CA) SignX509SVID(ctx context.Context, params X509SVIDParams) (_ []*x509.Certificate, err error) {
+	_, span := tracing.StartSpan(ctx, "ca.SignX509SVID")
+	defer tracing.EndSpan(span, &err)
+
 	x509CA := ca.X509CA()
 	if x509CA == nil {
 		return nil, errs.New("X509 CA is not available for signing")
@@ -193,11 +239,17 @@ func (ca *CA) SignX509SVID(ctx context.Context, params X509SVIDParams) ([]*x509.
 	}
 
 	notBefore, notAfter := ca.capLifetime(params.TTL, x509CA.Certificate.NotAfter)
-	serialNumber, err := x509util.NewSerialNumber()
+	serialNumber, err := ca.c.SerialNumberGenerator.NewSerialNumber()
 	if err != nil {
 		return nil, err
 	}
 
+	if ca.c.FIPSMode {
+		if err := validateFIPSPublicKey(params.PublicKey); err != nil {
+			return nil, errs.New("unable to create X509 SVID: %v", err)
+		}
+	}
+
 	template, err := CreateX509SVIDTemplate(params.SpiffeID, params.PublicKey, ca.c.TrustDomain, notBefore, notAfter, serialNumber)
 	if err != nil {
 		return nil, err
@@ -219,6 +271,14 @@ func (ca *CA) SignX509SVID(ctx context.Context, params X509SVIDParams) ([]*x509.
 		template.DNSNames = params.DNSList
 	}
 
+	// while migrating trust domains, add the secondary SPIFFE ID after the
+	// primary one so that the primary ID remains the first URI SAN. Server
+	// and agent SVIDs are left alone since they are used for mTLS between
+	// agents and servers, which requires a single URI SAN.
+	if !ca.c.SecondaryTrustDomain.IsZero() && !idutil.IsReservedPath(params.SpiffeID.Path()) {
+		template.URIs = append(template.URIs, ca.c.SecondaryTrustDomain.NewID(params.SpiffeID.Path()).URL())
+	}
+
 	cert, err := createCertificate(template, x509CA.Certificate, template.PublicKey, x509CA.Signer)
 	if err != nil {
 		return nil, errs.New("unable to create X509 SVID: %v", err)
@@ -236,7 +296,10 @@ func (ca *CA) SignX509SVID(ctx context.Context, params X509SVIDParams) ([]*x509.
 	return makeSVIDCertChain(x509CA, cert), nil
 }
 
-func (ca *CA) SignX509CASVID(ctx context.Context, params X509CASVIDParams) ([]*x509.Certificate, error) {
+func (ca *CA) SignX509CASVID(ctx context.Context, params X509CASVIDParams) (_ []*x509.Certificate, err error) {
+	_, span := tracing.StartSpan(ctx, "ca.SignX509CASVID")
+	defer tracing.EndSpan(span, &err)
+
 	x509CA := ca.X509CA()
 	if x509CA == nil {
 		return nil, errs.New("X509 CA is not available for signing")
@@ -247,7 +310,7 @@ func (ca *CA) SignX509CASVID(ctx context.Context, params X509CASVIDParams) ([]*x
 	}
 
 	notBefore, notAfter := ca.capLifetime(params.TTL, x509CA.Certificate.NotAfter)
-	serialNumber, err := x509util.NewSerialNumber()
+	serialNumber, err := ca.c.SerialNumberGenerator.NewSerialNumber()
 	if err != nil {
 		return nil, err
 	}
@@ -258,6 +321,12 @@ func (ca *CA) SignX509CASVID(ctx context.Context, params X509CASVIDParams) ([]*x
 	subject := x509CA.Certificate.Subject
 	subject.OrganizationalUnit = []string{fmt.Sprintf("DOWNSTREAM-%d", 1+len(x509CA.UpstreamChain))}
 
+	if ca.c.FIPSMode {
+		if err := validateFIPSPublicKey(params.PublicKey); err != nil {
+			return nil, errs.New("unable to create X509 CA SVID: %v", err)
+		}
+	}
+
 	template, err := CreateServerCATemplate(params.SpiffeID, params.PublicKey, ca.c.TrustDomain, notBefore, notAfter, serialNumber, subject)
 	if err != nil {
 		return nil, err
@@ -284,13 +353,16 @@ func (ca *CA) SignX509CASVID(ctx context.Context, params X509CASVIDParams) ([]*x
 	return makeSVIDCertChain(x509CA, cert), nil
 }
 
-func (ca *CA) SignJWTSVID(ctx context.Context, params JWTSVIDParams) (string, error) {
-	jwtKey := ca.JWTKey()
+func (ca *CA) SignJWTSVID(ctx context.Context, params JWTSVIDParams) (_ string, err error) {
+	_, span := tracing.StartSpan(ctx, "ca.SignJWTSVID")
+	defer tracing.EndSpan(span, &err)
+
+	trustDomain, jwtKey := ca.jwtKeyFor(params.SpiffeID)
 	if jwtKey == nil {
 		return "", errs.New("JWT key is not available for signing")
 	}
 
-	if err := api.VerifyTrustDomainWorkloadID(ca.c.TrustDomain, params.SpiffeID); err != nil {
+	if err := api.VerifyTrustDomainWorkloadID(trustDomain, params.SpiffeID); err != nil {
 		return "", err
 	}
 
@@ -315,6 +387,19 @@ func (ca *CA) SignJWTSVID(ctx context.Context, params JWTSVIDParams) (string, er
 	return token, nil
 }
 
+// jwtKeyFor returns the trust domain the JWT-SVID for the given ID is signed
+// under and the key to sign it with. IDs in the additional trust domains are
+// signed with the key of their trust domain; any other ID is signed with the
+// key of the server trust domain.
+func (ca *CA) jwtKeyFor(id spiffeid.ID) (spiffeid.TrustDomain, *JWTKey) {
+	for _, td := range ca.c.JWTKeyTrustDomains {
+		if id.MemberOf(td) {
+			return td, ca.TrustDomainJWTKey(td)
+		}
+	}
+	return ca.c.TrustDomain, ca.JWTKey()
+}
+
 func (ca *CA) capLifetime(ttl time.Duration, expirationCap time.Time) (notBefore, notAfter time.Time) {
 	now := ca.c.Clock.Now()
 	notBefore = now.Add(-backdate)
@@ -2,24 +2,36 @@ package ca
 
 import (
 	"context"
+	"crypto"
+	"crypto/ed25519"
 	"crypto/rand"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"errors"
 	"math/big"
 	"testing"
 	"time"
 
 	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	"github.com/spiffe/spire/pkg/common/health"
+	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/jwtsvid"
 	"github.com/spiffe/spire/pkg/common/pemutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakehealthchecker"
+	"github.com/spiffe/spire/test/fakes/faketracing"
+	"github.com/spiffe/spire/test/testkey"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
+	"gopkg.in/square/go-jose.v2/jwt"
 )
 
 var (
@@ -112,6 +124,85 @@ func (s *CATestSuite) TestSignX509SVID() {
 	s.Equal("O=SPIRE,C=US", svid.Subject.String())
 }
 
+func (s *CATestSuite) TestSignRecordsSpans() {
+	exporter := faketracing.New(s.T())
+
+	_, err := s.ca.SignX509SVID(ctx, s.createX509SVIDParams())
+	s.Require().NoError(err)
+
+	_, err = s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(trustDomainExample, 0))
+	s.Require().NoError(err)
+
+	s.Require().Equal([]string{"ca.SignX509SVID", "ca.SignJWTSVID"}, exporter.SpanNames())
+}
+
+func (s *CATestSuite) TestSignX509SVIDNoCASetRecordsSpanError() {
+	exporter := faketracing.New(s.T())
+
+	s.ca.SetX509CA(nil)
+	_, err := s.ca.SignX509SVID(ctx, s.createX509SVIDParams())
+	s.Require().Error(err)
+
+	spans := exporter.GetSpans()
+	s.Require().Len(spans, 1)
+	s.Equal(codes.Error, spans[0].StatusCode)
+	s.Equal("X509 CA is not available for signing", spans[0].StatusMessage)
+}
+
+func (s *CATestSuite) TestSignX509SVIDWithSecondaryTrustDomain() {
+	s.ca.c.SecondaryTrustDomain = trustDomainFoo
+
+	svidChain, err := s.ca.SignX509SVID(ctx, s.createX509SVIDParams())
+	s.Require().NoError(err)
+	s.Require().Len(svidChain, 1)
+
+	// The primary SPIFFE ID must remain the first URI SAN
+	if s.Len(svidChain[0].URIs, 2) {
+		s.Equal("spiffe://example.org/workload", svidChain[0].URIs[0].String())
+		s.Equal("spiffe://foo.com/workload", svidChain[0].URIs[1].String())
+	}
+}
+
+func (s *CATestSuite) TestSecondaryTrustDomainKeepsAgentServerMTLS() {
+	s.ca.c.SecondaryTrustDomain = trustDomainFoo
+	bundle := x509bundle.FromX509Authorities(trustDomainExample, []*x509.Certificate{s.caCert})
+
+	// The server and agent SVIDs only carry the primary SPIFFE ID, so agents
+	// can still dial the server over mTLS
+	serverID := idutil.ServerID(trustDomainExample)
+	serverSVID := s.signSVID(serverID)
+	agentSVID := s.signSVID(trustDomainExample.NewID("/spire/agent/test/foo"))
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsconfig.MTLSServerConfig(serverSVID, bundle, tlsconfig.AuthorizeMemberOf(trustDomainExample)))
+	s.Require().NoError(err)
+	defer listener.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		serverErr <- conn.(*tls.Conn).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), tlsconfig.MTLSClientConfig(agentSVID, bundle, tlsconfig.AuthorizeID(serverID)))
+	s.Require().NoError(err)
+	defer conn.Close()
+	s.Require().NoError(<-serverErr)
+}
+
+func (s *CATestSuite) TestSignX509CASVIDIgnoresSecondaryTrustDomain() {
+	s.ca.c.SecondaryTrustDomain = trustDomainFoo
+
+	svidChain, err := s.ca.SignX509CASVID(ctx, s.createX509CASVIDParams(trustDomainExample))
+	s.Require().NoError(err)
+	s.Require().Len(svidChain, 1)
+	s.Require().Len(svidChain[0].URIs, 1)
+}
+
 func (s *CATestSuite) TestSignX509SVIDCannotSignTrustDomainID() {
 	params := X509SVIDParams{
 		SpiffeID:  spiffeid.RequireFromString("spiffe://example.org"),
@@ -261,6 +352,48 @@ func (s *CATestSuite) TestSignX509SVIDChangesSerialNumber() {
 	s.Require().NotEqual(0, svid2[0].SerialNumber.Cmp(svid1[0].SerialNumber))
 }
 
+func (s *CATestSuite) TestSignUsesSerialNumberGenerator() {
+	s.ca.c.SerialNumberGenerator = fakeSerialNumberGenerator{serialNumber: big.NewInt(42)}
+
+	svid, err := s.ca.SignX509SVID(ctx, s.createX509SVIDParams())
+	s.Require().NoError(err)
+	s.Require().Equal(big.NewInt(42), svid[0].SerialNumber)
+
+	caSVID, err := s.ca.SignX509CASVID(ctx, s.createX509CASVIDParams(trustDomainExample))
+	s.Require().NoError(err)
+	s.Require().Equal(big.NewInt(42), caSVID[0].SerialNumber)
+
+	s.ca.c.SerialNumberGenerator = fakeSerialNumberGenerator{err: errors.New("oh no")}
+	_, err = s.ca.SignX509SVID(ctx, s.createX509SVIDParams())
+	s.Require().EqualError(err, "oh no")
+}
+
+func (s *CATestSuite) TestSignInFIPSMode() {
+	s.ca.c.FIPSMode = true
+
+	params := s.createX509SVIDParams()
+	params.PublicKey = testkey.NewEC384(s.T()).Public()
+	_, err := s.ca.SignX509SVID(ctx, params)
+	s.Require().NoError(err)
+
+	params.PublicKey = testkey.NewRSA1024(s.T()).Public()
+	_, err = s.ca.SignX509SVID(ctx, params)
+	s.Require().EqualError(err, "unable to create X509 SVID: RSA key size of 1024 bits is not FIPS-approved; at least 2048 bits are required")
+
+	params.PublicKey = ed25519.PublicKey(make([]byte, ed25519.PublicKeySize))
+	_, err = s.ca.SignX509SVID(ctx, params)
+	s.Require().EqualError(err, "unable to create X509 SVID: public key of type ed25519.PublicKey is not FIPS-approved")
+
+	caParams := s.createX509CASVIDParams(trustDomainExample)
+	caParams.PublicKey = testkey.NewRSA2048(s.T()).Public()
+	_, err = s.ca.SignX509CASVID(ctx, caParams)
+	s.Require().NoError(err)
+
+	caParams.PublicKey = testkey.NewRSA1024(s.T()).Public()
+	_, err = s.ca.SignX509CASVID(ctx, caParams)
+	s.Require().EqualError(err, "unable to create X509 CA SVID: RSA key size of 1024 bits is not FIPS-approved; at least 2048 bits are required")
+}
+
 func (s *CATestSuite) TestNoJWTKeySet() {
 	s.ca.SetJWTKey(nil)
 	_, err := s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(trustDomainExample, 0))
@@ -285,6 +418,21 @@ func (s *CATestSuite) TestSignJWTSVIDUsesTTLIfSpecified() {
 	s.Require().Equal(s.clock.Now().Add(time.Minute+time.Second), expiresAt)
 }
 
+func (s *CATestSuite) TestSignSVIDsUseDistinctDefaultTTLs() {
+	s.ca.c.JWTSVIDTTL = 3 * time.Minute
+
+	svidChain, err := s.ca.SignX509SVID(ctx, s.createX509SVIDParams())
+	s.Require().NoError(err)
+	s.Require().Len(svidChain, 1)
+	s.Require().Equal(s.clock.Now().Add(time.Minute), svidChain[0].NotAfter)
+
+	token, err := s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(trustDomainExample, 0))
+	s.Require().NoError(err)
+	_, expiresAt, err := jwtsvid.GetTokenExpiry(token)
+	s.Require().NoError(err)
+	s.Require().Equal(s.clock.Now().Add(3*time.Minute), expiresAt)
+}
+
 func (s *CATestSuite) TestSignJWTSVIDCapsTTLToKeyExpiry() {
 	token, err := s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(trustDomainExample, time.Hour))
 	s.Require().NoError(err)
@@ -306,6 +454,41 @@ func (s *CATestSuite) TestSignJWTSVIDValidatesJSR() {
 	s.Require().EqualError(err, "unable to sign JWT SVID: audience is required")
 }
 
+func (s *CATestSuite) TestSignJWTSVIDWithTrustDomainJWTKeys() {
+	s.ca.c.JWTKeyTrustDomains = []spiffeid.TrustDomain{trustDomainFoo}
+
+	// JWT-SVIDs for the additional trust domain need its own key
+	_, err := s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(trustDomainFoo, 0))
+	s.Require().EqualError(err, "JWT key is not available for signing")
+
+	fooSigner := testkey.NewEC256(s.T())
+	s.ca.SetTrustDomainJWTKey(trustDomainFoo, &JWTKey{
+		Signer:   fooSigner,
+		Kid:      "FOO-KID",
+		NotAfter: s.clock.Now().Add(10 * time.Minute),
+	})
+
+	token, err := s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(trustDomainExample, 0))
+	s.Require().NoError(err)
+	s.requireJWTSVIDSignedBy(token, "KID", testSigner.Public())
+
+	fooToken, err := s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(trustDomainFoo, 0))
+	s.Require().NoError(err)
+	s.requireJWTSVIDSignedBy(fooToken, "FOO-KID", fooSigner.Public())
+
+	// IDs outside of the configured trust domains are still rejected
+	_, err = s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(spiffeid.RequireTrustDomainFromString("bar.com"), 0))
+	s.Require().EqualError(err, `"spiffe://bar.com/workload" is not a member of trust domain "example.org"`)
+}
+
+func (s *CATestSuite) requireJWTSVIDSignedBy(token, kid string, publicKey crypto.PublicKey) {
+	parsed, err := jwt.ParseSigned(token)
+	s.Require().NoError(err)
+	s.Require().Len(parsed.Headers, 1)
+	s.Require().Equal(kid, parsed.Headers[0].KeyID)
+	s.Require().NoError(parsed.Claims(publicKey, &jwt.Claims{}))
+}
+
 func (s *CATestSuite) TestSignX509CASVIDNoCASet() {
 	s.ca.SetX509CA(nil)
 	_, err := s.ca.SignX509CASVID(ctx, s.createX509CASVIDParams(trustDomainExample))
@@ -408,6 +591,23 @@ func (s *CATestSuite) createX509SVIDParamsInDomain(trustDomain spiffeid.TrustDom
 	}
 }
 
+func (s *CATestSuite) signSVID(id spiffeid.ID) *x509svid.SVID {
+	key := testkey.NewEC256(s.T())
+	chain, err := s.ca.SignX509SVID(ctx, X509SVIDParams{
+		SpiffeID:  id,
+		PublicKey: key.Public(),
+	})
+	s.Require().NoError(err)
+
+	svidID, err := x509svid.IDFromCert(chain[0])
+	s.Require().NoError(err)
+	return &x509svid.SVID{
+		ID:           svidID,
+		Certificates: chain,
+		PrivateKey:   key,
+	}
+}
+
 func (s *CATestSuite) createX509CASVIDParams(trustDomain spiffeid.TrustDomain) X509CASVIDParams {
 	return X509CASVIDParams{
 		SpiffeID:  trustDomain.ID(),
@@ -446,3 +646,12 @@ func (s *CATestSuite) createCACertificate(cn string, parent *x509.Certificate) *
 	s.Require().NoError(err)
 	return cert
 }
+
+type fakeSerialNumberGenerator struct {
+	serialNumber *big.Int
+	err          error
+}
+
+func (g fakeSerialNumberGenerator) NewSerialNumber() (*big.Int, error) {
+	return g.serialNumber, g.err
+}
@@ -0,0 +1,63 @@
+package ca
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
+)
+
+const fipsMinimumRSABits = 2048
+
+// ValidateFIPSKeyType returns an error if keys of the given type are not
+// approved for signing in FIPS mode, i.e. RSA keys of at least 2048 bits or
+// ECDSA keys on the P-256 or P-384 curves.
+func ValidateFIPSKeyType(keyType keymanager.KeyType) error {
+	switch keyType {
+	case keymanager.ECP256, keymanager.ECP384, keymanager.RSA2048, keymanager.RSA4096:
+		return nil
+	default:
+		return fmt.Errorf("key type %s is not FIPS-approved", keyTypeName(keyType))
+	}
+}
+
+// validateFIPSPublicKey returns an error if the public key to be certified
+// is not of a FIPS-approved algorithm.
+func validateFIPSPublicKey(publicKey crypto.PublicKey) error {
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		if bits := publicKey.N.BitLen(); bits < fipsMinimumRSABits {
+			return fmt.Errorf("RSA key size of %d bits is not FIPS-approved; at least %d bits are required", bits, fipsMinimumRSABits)
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		switch publicKey.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+			return nil
+		default:
+			return fmt.Errorf("ECDSA curve %s is not FIPS-approved", publicKey.Curve.Params().Name)
+		}
+	default:
+		return fmt.Errorf("public key of type %T is not FIPS-approved", publicKey)
+	}
+}
+
+func keyTypeName(keyType keymanager.KeyType) string {
+	switch keyType {
+	case keymanager.ECP256:
+		return "ec-p256"
+	case keymanager.ECP384:
+		return "ec-p384"
+	case keymanager.RSA1024:
+		return "rsa-1024"
+	case keymanager.RSA2048:
+		return "rsa-2048"
+	case keymanager.RSA4096:
+		return "rsa-4096"
+	default:
+		return fmt.Sprintf("%d", int(keyType))
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/diskutil"
 	"github.com/spiffe/spire/proto/private/server/journal"
 	"github.com/spiffe/spire/proto/spire/common"
@@ -135,6 +136,53 @@ func (j *Journal) AppendJWTKey(slotID string, issuedAt time.Time, jwtKey *JWTKey
 	return nil
 }
 
+// AppendTrustDomainJWTKey appends the JWT key of an additional trust domain.
+// Up to journalCap entries are kept for each trust domain.
+func (j *Journal) AppendTrustDomainJWTKey(td spiffeid.TrustDomain, slotID string, issuedAt time.Time, jwtKey *JWTKey) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	pkixBytes, err := x509.MarshalPKIXPublicKey(jwtKey.Signer.Public())
+	if err != nil {
+		return errs.Wrap(err)
+	}
+
+	backup := j.entries.TrustDomainJwtKeys
+	jwtKeys := append(backup[:len(backup):len(backup)], &JWTKeyEntry{
+		SlotId:      slotID,
+		IssuedAt:    issuedAt.Unix(),
+		Kid:         jwtKey.Kid,
+		PublicKey:   pkixBytes,
+		NotAfter:    jwtKey.NotAfter.Unix(),
+		TrustDomain: td.String(),
+	})
+
+	exceeded := -journalCap
+	for _, entry := range jwtKeys {
+		if entry.TrustDomain == td.String() {
+			exceeded++
+		}
+	}
+
+	// drop the oldest entries of the trust domain past the cap
+	kept := make([]*JWTKeyEntry, 0, len(jwtKeys))
+	for _, entry := range jwtKeys {
+		if entry.TrustDomain == td.String() && exceeded > 0 {
+			exceeded--
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	j.entries.TrustDomainJwtKeys = kept
+
+	if err := j.save(); err != nil {
+		j.entries.TrustDomainJwtKeys = backup
+		return err
+	}
+
+	return nil
+}
+
 func (j *Journal) save() error {
 	return saveJournalEntries(j.path, j.entries)
 }
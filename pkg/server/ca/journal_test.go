@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/suite"
 	"google.golang.org/protobuf/proto"
@@ -182,6 +183,41 @@ func (s *JournalSuite) TestJWTKeyOverflow() {
 	s.Require().Equal(now, time.Unix(lastEntry.IssuedAt, 0).UTC())
 }
 
+func (s *JournalSuite) TestTrustDomainJWTKeyOverflow() {
+	now := s.now()
+	fooTrustDomain := spiffeid.RequireTrustDomainFromString("foo.test")
+	barTrustDomain := spiffeid.RequireTrustDomainFromString("bar.test")
+
+	journal := s.loadJournal()
+
+	err := journal.AppendTrustDomainJWTKey(barTrustDomain, "A", now, &JWTKey{
+		Signer:   testSigner,
+		Kid:      "BAR-KID",
+		NotAfter: now.Add(time.Hour),
+	})
+	s.Require().NoError(err)
+
+	for i := 0; i < (journalCap + 1); i++ {
+		now = now.Add(time.Minute)
+		err := journal.AppendTrustDomainJWTKey(fooTrustDomain, "B", now, &JWTKey{
+			Signer:   testSigner,
+			Kid:      "FOO-KID",
+			NotAfter: now.Add(time.Hour),
+		})
+		s.Require().NoError(err)
+	}
+
+	// the cap applies to each trust domain separately
+	entries := journal.Entries()
+	s.Require().Len(entries.TrustDomainJwtKeys, journalCap+1, "JWT key entries exceeds cap")
+	s.Require().Equal("bar.test", entries.TrustDomainJwtKeys[0].TrustDomain)
+	lastEntry := entries.TrustDomainJwtKeys[len(entries.TrustDomainJwtKeys)-1]
+	s.Require().Equal("foo.test", lastEntry.TrustDomain)
+	s.Require().Equal(now, time.Unix(lastEntry.IssuedAt, 0).UTC())
+
+	s.requireProtoEqual(entries, s.loadJournal().Entries())
+}
+
 func (s *JournalSuite) TestBadPEM() {
 	s.writeString(s.journalPath(), "NOT PEM")
 	_, err := LoadJournal(s.journalPath())
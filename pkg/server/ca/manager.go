@@ -7,11 +7,13 @@ import (
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"math/big"
 	"net/url"
 	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -32,6 +34,7 @@ import (
 	"github.com/zeebo/errs"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	jose "gopkg.in/square/go-jose.v2"
 )
 
 const (
@@ -53,6 +56,7 @@ const (
 type ManagedCA interface {
 	SetX509CA(*X509CA)
 	SetJWTKey(*JWTKey)
+	SetTrustDomainJWTKey(spiffeid.TrustDomain, *JWTKey)
 }
 
 type ManagerConfig struct {
@@ -68,12 +72,45 @@ type ManagerConfig struct {
 	Metrics       telemetry.Metrics
 	Clock         clock.Clock
 	HealthChecker health.Checker
+
+	// JWTKeyIDThumbprint, if set, uses the RFC 7638 thumbprint of each new
+	// JWT signing key as its key ID instead of a random one.
+	JWTKeyIDThumbprint bool
+
+	// JWTKeyNoticePeriod, if set, is how long each new JWT signing key is
+	// published in the bundle before it is activated, so JWT verifiers
+	// caching the bundle get the key before the first JWT-SVID signed with
+	// it. The key is activated anyway once the active one expires.
+	JWTKeyNoticePeriod time.Duration
+
+	// UpstreamAuthorityOrder is the order, by plugin name, in which the
+	// upstream authorities are failed over. Upstream authorities that are
+	// not listed come last, ordered by name.
+	UpstreamAuthorityOrder []string
+
+	// NotifierTimeout, if set, bounds how long each notifier has to handle
+	// an event. A notifier that takes longer fails to handle it.
+	NotifierTimeout time.Duration
+
+	// FIPSMode, if set, fails the initialization if the X509 CA or JWT
+	// signing key types are not FIPS-approved.
+	FIPSMode bool
+
+	// JWTKeyTrustDomains are additional trust domains that get their own
+	// JWT signing keys. The keys are rotated like the JWT signing key of the
+	// server trust domain and published in the bundle of their trust domain.
+	JWTKeyTrustDomains []spiffeid.TrustDomain
+
+	// UpstreamAuthorityValidation determines whether the upstream authority
+	// is validated by signing a test CSR on initialization, and whether a
+	// failure to sign it fails the initialization or is only logged.
+	UpstreamAuthorityValidation UpstreamAuthorityValidation
 }
 
 type Manager struct {
 	c                  ManagerConfig
 	bundleUpdatedCh    chan struct{}
-	upstreamClient     *UpstreamClient
+	upstreamClient     *upstreamClients
 	upstreamPluginName string
 
 	currentX509CA *x509CASlot
@@ -81,6 +118,9 @@ type Manager struct {
 	currentJWTKey *jwtKeySlot
 	nextJWTKey    *jwtKeySlot
 
+	// JWT key slots of the additional trust domains
+	trustDomainJWTKeys map[spiffeid.TrustDomain]*trustDomainJWTKeySlots
+
 	journal *Journal
 
 	// For keeping track of number of failed rotations.
@@ -88,6 +128,37 @@ type Manager struct {
 
 	// Used to log a warning only once when the UpstreamAuthority does not support JWT-SVIDs.
 	jwtUnimplementedWarnOnce sync.Once
+
+	// Snapshot of the X509 CA slots, updated on every rotation check, so
+	// the status can be read without racing with the rotation.
+	statusMtx    sync.RWMutex
+	x509CAStatus X509CAStatus
+}
+
+// X509CAStatus describes the active and next X509 CAs of the manager and
+// when the next one is prepared and activated. The rotations happen on the
+// first rotation check (every 10 seconds) past those times.
+type X509CAStatus struct {
+	// Active is the X509 CA the server signs with, if any.
+	Active *X509CASlotStatus
+
+	// Next is the X509 CA prepared to replace the active one, if any.
+	Next *X509CASlotStatus
+
+	// PrepareNextAt is when the next X509 CA is prepared. It is zero if the
+	// next X509 CA has been prepared already or there is no active one.
+	PrepareNextAt time.Time
+
+	// ActivateNextAt is when the next X509 CA replaces the active one. It
+	// is zero if there is no active X509 CA.
+	ActivateNextAt time.Time
+}
+
+// X509CASlotStatus describes the X509 CA kept in a slot of the manager.
+type X509CASlotStatus struct {
+	SlotID   string
+	IssuedAt time.Time
+	X509CA   *X509CA
 }
 
 func NewManager(c ManagerConfig) *Manager {
@@ -109,17 +180,24 @@ func NewManager(c ManagerConfig) *Manager {
 		bundleUpdatedCh: make(chan struct{}, 1),
 	}
 
-	if upstreamAuthority, ok := c.Catalog.GetUpstreamAuthority(); ok {
-		m.upstreamClient = NewUpstreamClient(UpstreamClientConfig{
-			UpstreamAuthority: upstreamAuthority,
-			BundleUpdater: &bundleUpdater{
-				log:           c.Log,
-				trustDomainID: c.TrustDomain.IDString(),
-				ds:            c.Catalog.GetDataStore(),
-				updated:       m.bundleUpdated,
-			},
-		})
-		m.upstreamPluginName = upstreamAuthority.Name()
+	if upstreamAuthorities := orderUpstreamAuthorities(c.Catalog.GetUpstreamAuthorities(), c.UpstreamAuthorityOrder); len(upstreamAuthorities) > 0 {
+		m.upstreamClient = &upstreamClients{log: c.Log, mintTimeout: upstreamFailoverTimeout}
+		for _, upstreamAuthority := range upstreamAuthorities {
+			m.upstreamClient.clients = append(m.upstreamClient.clients, namedUpstreamClient{
+				name:              upstreamAuthority.Name(),
+				upstreamAuthority: upstreamAuthority,
+				client: NewUpstreamClient(UpstreamClientConfig{
+					UpstreamAuthority: upstreamAuthority,
+					BundleUpdater: &bundleUpdater{
+						log:           c.Log,
+						trustDomainID: c.TrustDomain.IDString(),
+						ds:            c.Catalog.GetDataStore(),
+						updated:       m.bundleUpdated,
+					},
+				}),
+			})
+		}
+		m.upstreamPluginName = m.upstreamClient.primaryName()
 	}
 
 	_ = c.HealthChecker.AddCheck("server.ca.manager", &managerHealth{m: m})
@@ -128,6 +206,19 @@ func NewManager(c ManagerConfig) *Manager {
 }
 
 func (m *Manager) Initialize(ctx context.Context) error {
+	if m.c.FIPSMode {
+		if err := ValidateFIPSKeyType(m.c.X509CAKeyType); err != nil {
+			return fmt.Errorf("invalid X509 CA key type: %w", err)
+		}
+		if err := ValidateFIPSKeyType(m.c.JWTKeyType); err != nil {
+			return fmt.Errorf("invalid JWT signing key type: %w", err)
+		}
+	}
+	if m.upstreamClient != nil && m.c.UpstreamAuthorityValidation != SkipUpstreamAuthorityValidation {
+		if err := m.validateUpstreamAuthorities(ctx); err != nil {
+			return err
+		}
+	}
 	if err := m.loadJournal(ctx); err != nil {
 		return err
 	}
@@ -198,10 +289,29 @@ func (m *Manager) rotate(ctx context.Context) error {
 		m.c.Log.WithError(jwtKeyErr).Error("Unable to rotate JWT key")
 	}
 
-	return errs.Combine(x509CAErr, jwtKeyErr)
+	var trustDomainJWTKeyErrs []error
+	for _, td := range m.c.JWTKeyTrustDomains {
+		if err := m.rotateTrustDomainJWTKey(ctx, td); err != nil {
+			atomic.AddUint64(&m.failedRotationNum, 1)
+			m.c.Log.WithError(err).WithField(telemetry.TrustDomainID, td.IDString()).Error("Unable to rotate JWT key")
+			trustDomainJWTKeyErrs = append(trustDomainJWTKeyErrs, err)
+		}
+	}
+
+	return errs.Combine(append([]error{x509CAErr, jwtKeyErr}, trustDomainJWTKeyErrs...)...)
+}
+
+// X509CAStatus returns the status of the X509 CAs as of the last rotation
+// check.
+func (m *Manager) X509CAStatus() X509CAStatus {
+	m.statusMtx.RLock()
+	defer m.statusMtx.RUnlock()
+	return m.x509CAStatus
 }
 
 func (m *Manager) rotateX509CA(ctx context.Context) error {
+	defer m.updateX509CAStatus()
+
 	now := m.c.Clock.Now()
 
 	// if there is no current keypair set, generate one
@@ -229,6 +339,23 @@ func (m *Manager) rotateX509CA(ctx context.Context) error {
 	return nil
 }
 
+func (m *Manager) updateX509CAStatus() {
+	status := X509CAStatus{
+		Active: m.currentX509CA.Status(),
+		Next:   m.nextX509CA.Status(),
+	}
+	if current := m.currentX509CA; !current.IsEmpty() {
+		if m.nextX509CA.IsEmpty() {
+			status.PrepareNextAt = preparationThreshold(current.issuedAt, current.x509CA.Certificate.NotAfter)
+		}
+		status.ActivateNextAt = KeyActivationThreshold(current.issuedAt, current.x509CA.Certificate.NotAfter)
+	}
+
+	m.statusMtx.Lock()
+	defer m.statusMtx.Unlock()
+	m.x509CAStatus = status
+}
+
 func (m *Manager) failedRotationResult() uint64 {
 	return atomic.LoadUint64(&m.failedRotationNum)
 }
@@ -315,13 +442,13 @@ func (m *Manager) rotateJWTKey(ctx context.Context) error {
 
 	// if there is no next keypair set and the current is within the
 	// preparation threshold, generate one.
-	if m.nextJWTKey.IsEmpty() && m.currentJWTKey.ShouldPrepareNext(now) {
+	if m.nextJWTKey.IsEmpty() && m.currentJWTKey.ShouldPrepareNext(now, m.c.JWTKeyNoticePeriod) {
 		if err := m.prepareJWTKey(ctx, m.nextJWTKey); err != nil {
 			return err
 		}
 	}
 
-	if m.currentJWTKey.ShouldActivateNext(now) {
+	if m.shouldActivateNextJWTKey(now, m.currentJWTKey, m.nextJWTKey) {
 		m.currentJWTKey, m.nextJWTKey = m.nextJWTKey, m.currentJWTKey
 		m.nextJWTKey.Reset()
 		m.activateJWTKey()
@@ -330,11 +457,40 @@ func (m *Manager) rotateJWTKey(ctx context.Context) error {
 	return nil
 }
 
+func (m *Manager) rotateTrustDomainJWTKey(ctx context.Context, td spiffeid.TrustDomain) error {
+	now := m.c.Clock.Now()
+	slots := m.trustDomainJWTKeys[td]
+
+	if slots.current.IsEmpty() {
+		if err := m.prepareJWTKey(ctx, slots.current); err != nil {
+			return err
+		}
+		m.activateTrustDomainJWTKey(td)
+	}
+
+	if slots.next.IsEmpty() && slots.current.ShouldPrepareNext(now, m.c.JWTKeyNoticePeriod) {
+		if err := m.prepareJWTKey(ctx, slots.next); err != nil {
+			return err
+		}
+	}
+
+	if m.shouldActivateNextJWTKey(now, slots.current, slots.next) {
+		slots.current, slots.next = slots.next, slots.current
+		slots.next.Reset()
+		m.activateTrustDomainJWTKey(td)
+	}
+
+	return nil
+}
+
 func (m *Manager) prepareJWTKey(ctx context.Context, slot *jwtKeySlot) (err error) {
 	counter := telemetry_server.StartServerCAManagerPrepareJWTKeyCall(m.c.Metrics)
 	defer counter.Done(&err)
 
 	log := m.c.Log.WithField(telemetry.Slot, slot.id)
+	if !slot.trustDomain.IsZero() {
+		log = log.WithField(telemetry.TrustDomainID, slot.trustDomain.IDString())
+	}
 	log.Debug("Preparing JWT key")
 
 	slot.Reset()
@@ -348,7 +504,7 @@ func (m *Manager) prepareJWTKey(ctx context.Context, slot *jwtKeySlot) (err erro
 		return err
 	}
 
-	jwtKey, err := newJWTKey(signer, notAfter)
+	jwtKey, err := newJWTKey(signer, notAfter, m.c.JWTKeyIDThumbprint)
 	if err != nil {
 		return err
 	}
@@ -358,18 +514,30 @@ func (m *Manager) prepareJWTKey(ctx context.Context, slot *jwtKeySlot) (err erro
 		return err
 	}
 
-	if _, err := m.PublishJWTKey(ctx, publicKey); err != nil {
+	if slot.trustDomain.IsZero() {
+		_, err = m.PublishJWTKey(ctx, publicKey)
+	} else {
+		// keys of the additional trust domains are only published in the
+		// bundle of their trust domain, never upstream.
+		_, err = m.appendTrustDomainBundle(ctx, slot.trustDomain, nil, []*common.PublicKey{publicKey})
+	}
+	if err != nil {
 		return err
 	}
 
 	slot.issuedAt = now
 	slot.jwtKey = jwtKey
 
-	if err := m.journal.AppendJWTKey(slot.id, slot.issuedAt, slot.jwtKey); err != nil {
+	if slot.trustDomain.IsZero() {
+		err = m.journal.AppendJWTKey(slot.id, slot.issuedAt, slot.jwtKey)
+	} else {
+		err = m.journal.AppendTrustDomainJWTKey(slot.trustDomain, slot.id, slot.issuedAt, slot.jwtKey)
+	}
+	if err != nil {
 		log.WithError(err).Error("Unable to append JWT key to journal")
 	}
 
-	m.c.Log.WithFields(logrus.Fields{
+	log.WithFields(logrus.Fields{
 		telemetry.Slot:       slot.id,
 		telemetry.IssuedAt:   timeField(slot.issuedAt),
 		telemetry.Expiration: timeField(slot.jwtKey.NotAfter),
@@ -393,9 +561,8 @@ func (m *Manager) prepareJWTKey(ctx context.Context, slot *jwtKeySlot) (err erro
 // just appends the passed JWK to the bundle and returns the updated list of JWT keys.
 func (m *Manager) PublishJWTKey(ctx context.Context, jwtKey *common.PublicKey) ([]*common.PublicKey, error) {
 	if m.upstreamClient != nil {
-		publishCtx, cancel := context.WithTimeout(ctx, publishJWKTimeout)
-		defer cancel()
-		upstreamJWTKeys, err := m.upstreamClient.PublishJWTKey(publishCtx, jwtKey)
+		// Each upstream authority is given publishJWKTimeout.
+		upstreamJWTKeys, err := m.upstreamClient.PublishJWTKey(ctx, jwtKey)
 		switch {
 		case status.Code(err) == codes.Unimplemented:
 			// JWT Key publishing is not supported by the upstream plugin.
@@ -422,6 +589,20 @@ func (m *Manager) PublishJWTKey(ctx context.Context, jwtKey *common.PublicKey) (
 	return resp.Bundle.JwtSigningKeys, nil
 }
 
+// shouldActivateNextJWTKey tells whether the next JWT key replaces the
+// current one. On top of the activation threshold of the current key, the
+// next key has to have been published for the notice period, unless the
+// current key has expired.
+func (m *Manager) shouldActivateNextJWTKey(now time.Time, current, next *jwtKeySlot) bool {
+	if !current.ShouldActivateNext(now) {
+		return false
+	}
+	if current.IsEmpty() || next.IsEmpty() || m.c.JWTKeyNoticePeriod <= 0 {
+		return true
+	}
+	return !now.Before(next.issuedAt.Add(m.c.JWTKeyNoticePeriod)) || !now.Before(current.jwtKey.NotAfter)
+}
+
 func (m *Manager) activateJWTKey() {
 	m.c.Log.WithFields(logrus.Fields{
 		telemetry.Slot:       m.currentJWTKey.id,
@@ -432,6 +613,18 @@ func (m *Manager) activateJWTKey() {
 	m.c.CA.SetJWTKey(m.currentJWTKey.jwtKey)
 }
 
+func (m *Manager) activateTrustDomainJWTKey(td spiffeid.TrustDomain) {
+	current := m.trustDomainJWTKeys[td].current
+	m.c.Log.WithFields(logrus.Fields{
+		telemetry.TrustDomainID: td.IDString(),
+		telemetry.Slot:          current.id,
+		telemetry.IssuedAt:      timeField(current.issuedAt),
+		telemetry.Expiration:    timeField(current.jwtKey.NotAfter),
+	}).Info("JWT key activated")
+	telemetry_server.IncrActivateJWTKeyManagerCounter(m.c.Metrics)
+	m.c.CA.SetTrustDomainJWTKey(td, current.jwtKey)
+}
+
 func (m *Manager) pruneBundleEvery(ctx context.Context, interval time.Duration) error {
 	ticker := m.c.Clock.Ticker(interval)
 	defer ticker.Stop()
@@ -455,25 +648,45 @@ func (m *Manager) pruneBundle(ctx context.Context) (err error) {
 	ds := m.c.Catalog.GetDataStore()
 	expiresBefore := m.c.Clock.Now().Add(-safetyThreshold)
 
+	var pruneErrs []error
 	resp, err := ds.PruneBundle(ctx, &datastore.PruneBundleRequest{
 		TrustDomainId: m.c.TrustDomain.IDString(),
 		ExpiresBefore: expiresBefore.Unix(),
 	})
-
-	if err != nil {
-		return fmt.Errorf("unable to prune bundle: %v", err)
-	}
-
-	if resp.BundleChanged {
+	switch {
+	case err != nil:
+		pruneErrs = append(pruneErrs, fmt.Errorf("unable to prune bundle: %v", err))
+	case resp.BundleChanged:
 		telemetry_server.IncrManagerPrunedBundleCounter(m.c.Metrics)
 		m.c.Log.Debug("Expired certificates were successfully pruned from bundle")
 		m.bundleUpdated()
 	}
 
-	return nil
+	// The bundles of the additional trust domains get JWT signing keys
+	// appended on every rotation too.
+	for _, td := range m.c.JWTKeyTrustDomains {
+		resp, err := ds.PruneBundle(ctx, &datastore.PruneBundleRequest{
+			TrustDomainId: td.IDString(),
+			ExpiresBefore: expiresBefore.Unix(),
+		})
+		if err != nil {
+			pruneErrs = append(pruneErrs, fmt.Errorf("unable to prune bundle of %q: %v", td, err))
+			continue
+		}
+		if resp.BundleChanged {
+			telemetry_server.IncrManagerPrunedBundleCounter(m.c.Metrics)
+			m.c.Log.WithField(telemetry.TrustDomainID, td.IDString()).Debug("Expired JWT signing keys were successfully pruned from bundle")
+		}
+	}
+
+	return errs.Combine(pruneErrs...)
 }
 
 func (m *Manager) appendBundle(ctx context.Context, caChain []*x509.Certificate, jwtSigningKeys []*common.PublicKey) (*datastore.AppendBundleResponse, error) {
+	return m.appendTrustDomainBundle(ctx, m.c.TrustDomain, caChain, jwtSigningKeys)
+}
+
+func (m *Manager) appendTrustDomainBundle(ctx context.Context, td spiffeid.TrustDomain, caChain []*x509.Certificate, jwtSigningKeys []*common.PublicKey) (*datastore.AppendBundleResponse, error) {
 	var rootCAs []*common.Certificate
 	for _, caCert := range caChain {
 		rootCAs = append(rootCAs, &common.Certificate{
@@ -484,7 +697,7 @@ func (m *Manager) appendBundle(ctx context.Context, caChain []*x509.Certificate,
 	ds := m.c.Catalog.GetDataStore()
 	res, err := ds.AppendBundle(ctx, &datastore.AppendBundleRequest{
 		Bundle: &common.Bundle{
-			TrustDomainId:  m.c.TrustDomain.IDString(),
+			TrustDomainId:  td.IDString(),
 			RootCas:        rootCAs,
 			JwtSigningKeys: jwtSigningKeys,
 		},
@@ -493,7 +706,9 @@ func (m *Manager) appendBundle(ctx context.Context, caChain []*x509.Certificate,
 		return nil, err
 	}
 
-	m.bundleUpdated()
+	if td == m.c.TrustDomain {
+		m.bundleUpdated()
+	}
 	return res, nil
 }
 
@@ -580,12 +795,65 @@ func (m *Manager) loadJournal(ctx context.Context) error {
 		m.nextJWTKey = newJWTKeySlot("B")
 	}
 
-	if !m.currentJWTKey.IsEmpty() && !m.currentJWTKey.ShouldActivateNext(now) {
+	if !m.currentJWTKey.IsEmpty() && !m.shouldActivateNextJWTKey(now, m.currentJWTKey, m.nextJWTKey) {
 		// activate the JWT key immediately if it is set and not within
 		// activation time of the next JWT key.
 		m.activateJWTKey()
 	}
 
+	m.trustDomainJWTKeys = make(map[spiffeid.TrustDomain]*trustDomainJWTKeySlots)
+	for _, td := range m.c.JWTKeyTrustDomains {
+		if err := m.loadTrustDomainJWTKeySlots(ctx, td, entries.TrustDomainJwtKeys); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) loadTrustDomainJWTKeySlots(ctx context.Context, td spiffeid.TrustDomain, allEntries []*JWTKeyEntry) error {
+	var entries []*JWTKeyEntry
+	for _, entry := range allEntries {
+		if entry.TrustDomain == td.String() {
+			entries = append(entries, entry)
+		}
+	}
+
+	var current, next *jwtKeySlot
+	var err error
+	if len(entries) > 0 {
+		next, err = m.tryLoadJWTKeySlotFromEntry(ctx, entries[len(entries)-1])
+		if err != nil {
+			return err
+		}
+		// if the last entry is ok, then consider the next entry
+		if next != nil && len(entries) > 1 {
+			current, err = m.tryLoadJWTKeySlotFromEntry(ctx, entries[len(entries)-2])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	switch {
+	case current != nil:
+		// both current and next are set
+	case next != nil:
+		// next is set but not current. swap them and initialize next with an empty slot.
+		current, next = next, newTrustDomainJWTKeySlot(td, otherSlotID(next.id))
+	default:
+		// neither are set. initialize them with empty slots.
+		current = newTrustDomainJWTKeySlot(td, "A")
+		next = newTrustDomainJWTKeySlot(td, "B")
+	}
+
+	m.trustDomainJWTKeys[td] = &trustDomainJWTKeySlots{
+		current: current,
+		next:    next,
+	}
+
+	if !current.IsEmpty() && !m.shouldActivateNextJWTKey(m.c.Clock.Now(), current, next) {
+		m.activateTrustDomainJWTKey(td)
+	}
 	return nil
 }
 
@@ -674,12 +942,22 @@ func (m *Manager) loadJWTKeySlotFromEntry(ctx context.Context, entry *JWTKeyEntr
 		return nil, "no slot id", nil
 	}
 
+	var td spiffeid.TrustDomain
+	if entry.TrustDomain != "" {
+		var err error
+		td, err = spiffeid.TrustDomainFromString(entry.TrustDomain)
+		if err != nil {
+			return nil, "", errs.Wrap(err)
+		}
+	}
+
 	publicKey, err := x509.ParsePKIXPublicKey(entry.PublicKey)
 	if err != nil {
 		return nil, "", errs.Wrap(err)
 	}
 
-	signer, err := m.makeSigner(ctx, jwtKeyKmKeyID(entry.SlotId))
+	slot := newTrustDomainJWTKeySlot(td, entry.SlotId)
+	signer, err := m.makeSigner(ctx, slot.KmKeyID())
 	if err != nil {
 		return nil, "", err
 	}
@@ -691,15 +969,13 @@ func (m *Manager) loadJWTKeySlotFromEntry(ctx context.Context, entry *JWTKeyEntr
 		return nil, "public key does not match key manager key", nil
 	}
 
-	return &jwtKeySlot{
-		id:       entry.SlotId,
-		issuedAt: time.Unix(entry.IssuedAt, 0),
-		jwtKey: &JWTKey{
-			Signer:   signer,
-			NotAfter: time.Unix(entry.NotAfter, 0),
-			Kid:      entry.Kid,
-		},
-	}, "", nil
+	slot.issuedAt = time.Unix(entry.IssuedAt, 0)
+	slot.jwtKey = &JWTKey{
+		Signer:   signer,
+		NotAfter: time.Unix(entry.NotAfter, 0),
+		Kid:      entry.Kid,
+	}
+	return slot, "", nil
 }
 
 func (m *Manager) makeSigner(ctx context.Context, keyID string) (crypto.Signer, error) {
@@ -730,12 +1006,45 @@ func (m *Manager) dropBundleUpdated() {
 	}
 }
 
+// notifyOnBundleUpdate notifies the notifiers when the bundle is updated.
+// Each notifier is notified from its own goroutine, so a slow or failing
+// notifier does not delay the notifications to the others. Each notifier
+// handles the updates one at a time and in order, but a notifier that is
+// still handling an update when more come in is only notified of the latest
+// bundle once it is done.
 func (m *Manager) notifyOnBundleUpdate(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	var dispatchers []*bundleUpdateDispatcher
+	for _, n := range m.c.Catalog.GetNotifiers() {
+		d := newBundleUpdateDispatcher(n)
+		dispatchers = append(dispatchers, d)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.run(ctx, func(ctx context.Context, n notifier.Notifier, bundle *common.Bundle) {
+				_ = m.notifyOne(ctx, "bundle updated", false, n, func(ctx context.Context, n notifier.Notifier) error {
+					return n.NotifyBundleUpdated(ctx, bundle)
+				})
+			})
+		}()
+	}
+
 	for {
 		select {
 		case <-m.bundleUpdatedCh:
-			if err := m.notifyBundleUpdated(ctx); err != nil {
+			if len(dispatchers) == 0 {
+				continue
+			}
+			bundle, err := m.fetchRequiredBundle(ctx)
+			if err != nil {
 				m.c.Log.WithError(err).Warn("Failed to notify on bundle update")
+				continue
+			}
+			for _, d := range dispatchers {
+				d.dispatch(bundle)
 			}
 		case <-ctx.Done():
 			return
@@ -750,62 +1059,22 @@ func (m *Manager) notifyBundleLoaded(ctx context.Context) error {
 	// updated" event right after "bundle loaded".
 	m.dropBundleUpdated()
 
-	var bundle *common.Bundle
-	return m.notify(ctx, "bundle loaded", true,
-		func(ctx context.Context) (err error) {
-			bundle, err = m.fetchRequiredBundle(ctx)
-			return err
-		},
-		func(ctx context.Context, n notifier.Notifier) error {
-			return n.NotifyAndAdviseBundleLoaded(ctx, bundle)
-		},
-	)
-}
-
-func (m *Manager) notifyBundleUpdated(ctx context.Context) error {
-	var bundle *common.Bundle
-	return m.notify(ctx, "bundle updated", false,
-		func(ctx context.Context) (err error) {
-			bundle, err = m.fetchRequiredBundle(ctx)
-			return err
-		},
-		func(ctx context.Context, n notifier.Notifier) error {
-			return n.NotifyBundleUpdated(ctx, bundle)
-		},
-	)
-}
-
-func (m *Manager) notify(ctx context.Context, event string, advise bool, pre func(context.Context) error, do func(context.Context, notifier.Notifier) error) error {
 	notifiers := m.c.Catalog.GetNotifiers()
 	if len(notifiers) == 0 {
 		return nil
 	}
 
-	if pre != nil {
-		if err := pre(ctx); err != nil {
-			return err
-		}
+	bundle, err := m.fetchRequiredBundle(ctx)
+	if err != nil {
+		return err
 	}
 
 	errsCh := make(chan error, len(notifiers))
 	for _, n := range notifiers {
 		go func(n notifier.Notifier) {
-			err := do(ctx, n)
-			f := m.c.Log.WithFields(logrus.Fields{
-				telemetry.Notifier: n.Name(),
-				telemetry.Event:    event,
+			errsCh <- m.notifyOne(ctx, "bundle loaded", true, n, func(ctx context.Context, n notifier.Notifier) error {
+				return n.NotifyAndAdviseBundleLoaded(ctx, bundle)
 			})
-			if err == nil {
-				f.Debug("Notifier handled event")
-			} else {
-				f := f.WithError(err)
-				if advise {
-					f.Error("Notifier failed to handle event")
-				} else {
-					f.Warn("Notifier failed to handle event")
-				}
-			}
-			errsCh <- err
 		}(n)
 	}
 
@@ -823,6 +1092,75 @@ func (m *Manager) notify(ctx context.Context, event string, advise bool, pre fun
 	return nil
 }
 
+// notifyOne has the notifier handle the event, within the notifier timeout,
+// and logs the outcome.
+func (m *Manager) notifyOne(ctx context.Context, event string, advise bool, n notifier.Notifier, do func(context.Context, notifier.Notifier) error) error {
+	if m.c.NotifierTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.c.NotifierTimeout)
+		defer cancel()
+	}
+
+	err := do(ctx, n)
+	f := m.c.Log.WithFields(logrus.Fields{
+		telemetry.Notifier: n.Name(),
+		telemetry.Event:    event,
+	})
+	if err == nil {
+		f.Debug("Notifier handled event")
+	} else {
+		f := f.WithError(err)
+		if advise {
+			f.Error("Notifier failed to handle event")
+		} else {
+			f.Warn("Notifier failed to handle event")
+		}
+	}
+	return err
+}
+
+// bundleUpdateDispatcher hands the updated bundles to a notifier, keeping
+// only the latest one while the notifier is busy.
+type bundleUpdateDispatcher struct {
+	n       notifier.Notifier
+	readyCh chan struct{}
+
+	mtx    sync.Mutex
+	bundle *common.Bundle
+}
+
+func newBundleUpdateDispatcher(n notifier.Notifier) *bundleUpdateDispatcher {
+	return &bundleUpdateDispatcher{
+		n:       n,
+		readyCh: make(chan struct{}, 1),
+	}
+}
+
+func (d *bundleUpdateDispatcher) dispatch(bundle *common.Bundle) {
+	d.mtx.Lock()
+	d.bundle = bundle
+	d.mtx.Unlock()
+
+	select {
+	case d.readyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (d *bundleUpdateDispatcher) run(ctx context.Context, notify func(context.Context, notifier.Notifier, *common.Bundle)) {
+	for {
+		select {
+		case <-d.readyCh:
+			d.mtx.Lock()
+			bundle := d.bundle
+			d.mtx.Unlock()
+			notify(ctx, d.n, bundle)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 func (m *Manager) fetchRequiredBundle(ctx context.Context) (*common.Bundle, error) {
 	bundle, err := m.fetchOptionalBundle(ctx)
 	if err != nil {
@@ -901,6 +1239,11 @@ func jwtKeyKmKeyID(id string) string {
 	return fmt.Sprintf("JWT-Signer-%s", id)
 }
 
+func trustDomainJWTKeyKmKeyID(td spiffeid.TrustDomain, id string) string {
+	// key managers may not allow dots in key IDs (e.g. KMS aliases)
+	return fmt.Sprintf("JWT-Signer-%s-%s", strings.ReplaceAll(td.String(), ".", "_"), id)
+}
+
 type x509CASlot struct {
 	id       string
 	issuedAt time.Time
@@ -925,6 +1268,17 @@ func (s *x509CASlot) Reset() {
 	s.x509CA = nil
 }
 
+func (s *x509CASlot) Status() *X509CASlotStatus {
+	if s.IsEmpty() {
+		return nil
+	}
+	return &X509CASlotStatus{
+		SlotID:   s.id,
+		IssuedAt: s.issuedAt,
+		X509CA:   s.x509CA,
+	}
+}
+
 func (s *x509CASlot) ShouldPrepareNext(now time.Time) bool {
 	return s.x509CA != nil && now.After(preparationThreshold(s.issuedAt, s.x509CA.Certificate.NotAfter))
 }
@@ -937,6 +1291,10 @@ type jwtKeySlot struct {
 	id       string
 	issuedAt time.Time
 	jwtKey   *JWTKey
+
+	// trustDomain is the additional trust domain the key signs JWT-SVIDs
+	// for. It is zero for the server trust domain.
+	trustDomain spiffeid.TrustDomain
 }
 
 func newJWTKeySlot(id string) *jwtKeySlot {
@@ -945,8 +1303,18 @@ func newJWTKeySlot(id string) *jwtKeySlot {
 	}
 }
 
+func newTrustDomainJWTKeySlot(td spiffeid.TrustDomain, id string) *jwtKeySlot {
+	return &jwtKeySlot{
+		id:          id,
+		trustDomain: td,
+	}
+}
+
 func (s *jwtKeySlot) KmKeyID() string {
-	return jwtKeyKmKeyID(s.id)
+	if s.trustDomain.IsZero() {
+		return jwtKeyKmKeyID(s.id)
+	}
+	return trustDomainJWTKeyKmKeyID(s.trustDomain, s.id)
 }
 
 func (s *jwtKeySlot) IsEmpty() bool {
@@ -957,14 +1325,29 @@ func (s *jwtKeySlot) Reset() {
 	s.jwtKey = nil
 }
 
-func (s *jwtKeySlot) ShouldPrepareNext(now time.Time) bool {
-	return s.jwtKey == nil || now.After(preparationThreshold(s.issuedAt, s.jwtKey.NotAfter))
+// ShouldPrepareNext tells whether the next JWT key is prepared. It is
+// prepared early enough to be published for the notice period before the
+// activation threshold of this one.
+func (s *jwtKeySlot) ShouldPrepareNext(now time.Time, noticePeriod time.Duration) bool {
+	if s.jwtKey == nil {
+		return true
+	}
+	threshold := preparationThreshold(s.issuedAt, s.jwtKey.NotAfter)
+	if noticeThreshold := KeyActivationThreshold(s.issuedAt, s.jwtKey.NotAfter).Add(-noticePeriod); noticeThreshold.Before(threshold) {
+		threshold = noticeThreshold
+	}
+	return now.After(threshold)
 }
 
 func (s *jwtKeySlot) ShouldActivateNext(now time.Time) bool {
 	return s.jwtKey == nil || now.After(KeyActivationThreshold(s.issuedAt, s.jwtKey.NotAfter))
 }
 
+type trustDomainJWTKeySlots struct {
+	current *jwtKeySlot
+	next    *jwtKeySlot
+}
+
 func otherSlotID(id string) string {
 	if id == "A" {
 		return "B"
@@ -1020,7 +1403,7 @@ func SelfSignX509CA(ctx context.Context, signer crypto.Signer, trustDomain spiff
 	}, trustBundle, nil
 }
 
-func UpstreamSignX509CA(ctx context.Context, signer crypto.Signer, trustDomain spiffeid.TrustDomain, subject pkix.Name, upstreamClient *UpstreamClient, caTTL time.Duration) (*X509CA, error) {
+func UpstreamSignX509CA(ctx context.Context, signer crypto.Signer, trustDomain spiffeid.TrustDomain, subject pkix.Name, upstreamClient X509CAMinter, caTTL time.Duration) (*X509CA, error) {
 	csr, err := GenerateServerCACSR(signer, trustDomain, subject)
 	if err != nil {
 		return nil, err
@@ -1056,8 +1439,14 @@ func KeyActivationThreshold(issuedAt, notAfter time.Time) time.Time {
 	return notAfter.Add(-threshold)
 }
 
-func newJWTKey(signer crypto.Signer, expiresAt time.Time) (*JWTKey, error) {
-	kid, err := newKeyID()
+func newJWTKey(signer crypto.Signer, expiresAt time.Time, thumbprintKeyID bool) (*JWTKey, error) {
+	var kid string
+	var err error
+	if thumbprintKeyID {
+		kid, err = thumbprintKeyIDFromPublicKey(signer.Public())
+	} else {
+		kid, err = newKeyID()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1091,6 +1480,16 @@ func newKeyID() (string, error) {
 	return keyIDFromBytes(choices), nil
 }
 
+// thumbprintKeyIDFromPublicKey returns the base64url encoded RFC 7638
+// SHA-256 thumbprint of the public key.
+func thumbprintKeyIDFromPublicKey(publicKey crypto.PublicKey) (string, error) {
+	thumbprint, err := (&jose.JSONWebKey{Key: publicKey}).Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", errs.New("unable to compute JWK thumbprint: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
+
 func keyIDFromBytes(choices []byte) string {
 	const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	buf := new(bytes.Buffer)
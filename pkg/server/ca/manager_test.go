@@ -7,12 +7,15 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -35,6 +38,8 @@ import (
 	"github.com/spiffe/spire/test/fakes/fakeupstreamauthority"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	jose "gopkg.in/square/go-jose.v2"
 )
 
 const (
@@ -226,6 +231,250 @@ func (s *ManagerSuite) TestUpstreamAuthorityWithPublishJWTKeyImplemented() {
 	)
 }
 
+func (s *ManagerSuite) TestUpstreamAuthorityFailover() {
+	primaryUA, primaryFake := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+		TrustDomain:     testTrustDomain,
+		UseIntermediate: true,
+	})
+	secondaryUA, secondaryFake := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+		TrustDomain:     testTrustDomain,
+		UseIntermediate: true,
+	})
+	primary := &failingUpstreamAuthority{UpstreamAuthority: primaryUA, name: "primary"}
+	secondary := &failingUpstreamAuthority{UpstreamAuthority: secondaryUA, name: "secondary"}
+
+	// The catalog order is not meaningful; the configured order is
+	s.cat.SetUpstreamAuthority(secondary)
+	s.cat.AddUpstreamAuthority(primary)
+	c := s.selfSignedConfig()
+	c.UpstreamAuthorityOrder = []string{"primary", "secondary"}
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+
+	// The primary signs while it is healthy, and the secondary is not used
+	s.Equal(primaryFake.X509Intermediate(), s.currentX509CA().UpstreamChain[1])
+	s.requireBundleHasRootCAs(primaryFake.X509Root())
+	s.Equal(1, primary.Mints())
+	s.Equal(0, secondary.Mints())
+
+	// The secondary signs when the primary fails, and its roots are added to
+	// the bundle
+	primary.SetErr(errors.New("primary is down"))
+	s.addTimeAndRotate(prepareAfter + time.Minute)
+	s.Equal(secondaryFake.X509Intermediate(), s.nextX509CA().UpstreamChain[1])
+	s.requireBundleHasRootCAs(primaryFake.X509Root(), secondaryFake.X509Root())
+	s.Equal(1, s.countLogEntries(logrus.WarnLevel, "Primary upstream authority is unavailable; failed over to the next upstream authority"))
+	s.Equal(1, secondary.Mints())
+}
+
+func (s *ManagerSuite) TestUpstreamAuthorityFailoverWhenPrimaryHangs() {
+	primaryUA, _ := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+		TrustDomain: testTrustDomain,
+	})
+	secondaryUA, secondaryFake := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+		TrustDomain:     testTrustDomain,
+		UseIntermediate: true,
+	})
+	s.cat.SetUpstreamAuthority(&failingUpstreamAuthority{UpstreamAuthority: primaryUA, name: "primary", hang: true})
+	s.cat.AddUpstreamAuthority(&failingUpstreamAuthority{UpstreamAuthority: secondaryUA, name: "secondary"})
+	c := s.selfSignedConfig()
+	c.UpstreamAuthorityOrder = []string{"primary", "secondary"}
+	s.m = NewManager(c)
+	s.m.upstreamClient.mintTimeout = 10 * time.Millisecond
+
+	s.Require().NoError(s.m.Initialize(context.Background()))
+	s.Equal(secondaryFake.X509Intermediate(), s.currentX509CA().UpstreamChain[1])
+}
+
+func (s *ManagerSuite) TestUpstreamAuthorityFailoverFailsWhenAllFail() {
+	primaryUA, _ := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+		TrustDomain: testTrustDomain,
+	})
+	secondaryUA, _ := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+		TrustDomain: testTrustDomain,
+	})
+	s.cat.SetUpstreamAuthority(&failingUpstreamAuthority{UpstreamAuthority: primaryUA, name: "primary", err: errors.New("primary is down")})
+	s.cat.AddUpstreamAuthority(&failingUpstreamAuthority{UpstreamAuthority: secondaryUA, name: "secondary", err: errors.New("secondary is down")})
+	c := s.selfSignedConfig()
+	c.UpstreamAuthorityOrder = []string{"primary", "secondary"}
+	s.m = NewManager(c)
+
+	// The error of the primary is returned
+	s.EqualError(s.m.Initialize(context.Background()), "primary is down")
+}
+
+func (s *ManagerSuite) TestUpstreamAuthorityValidation() {
+	const warning = "Upstream authority failed to sign the test CSR; X509 CAs cannot be prepared until it is fixed"
+
+	testCases := []struct {
+		name       string
+		validation UpstreamAuthorityValidation
+		broken     bool
+		expectErr  string
+		expectWarn bool
+	}{
+		{
+			name:       "working upstream authority fails validation",
+			validation: FailUpstreamAuthorityValidation,
+		},
+		{
+			name:       "working upstream authority warn validation",
+			validation: WarnUpstreamAuthorityValidation,
+		},
+		{
+			name:       "broken upstream authority without validation",
+			validation: SkipUpstreamAuthorityValidation,
+			broken:     true,
+		},
+		{
+			name:       "broken upstream authority warn validation",
+			validation: WarnUpstreamAuthorityValidation,
+			broken:     true,
+			expectWarn: true,
+		},
+		{
+			name:       "broken upstream authority fail validation",
+			validation: FailUpstreamAuthorityValidation,
+			broken:     true,
+			expectErr:  "upstream authority failed to sign the test CSR: upstream is down",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		s.T().Run(testCase.name, func(t *testing.T) {
+			s.dir = s.TempDir()
+			s.logHook.Reset()
+			upstreamAuthority, _ := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+				TrustDomain: testTrustDomain,
+			})
+			ua := &failingUpstreamAuthority{UpstreamAuthority: upstreamAuthority, name: "ua"}
+			s.cat.SetUpstreamAuthority(ua)
+			c := s.selfSignedConfig()
+			c.UpstreamAuthorityValidation = testCase.validation
+
+			// The first initialization signs the X509 CA persisted in the
+			// journal, so the upstream authority is not needed again by the
+			// next one unless it is validated
+			s.m = NewManager(c)
+			require.NoError(t, s.m.Initialize(context.Background()))
+			x509CA := s.currentX509CA()
+			if testCase.broken {
+				ua.SetErr(errors.New("upstream is down"))
+			}
+
+			s.m = NewManager(c)
+			err := s.m.Initialize(context.Background())
+			if testCase.expectErr != "" {
+				assert.EqualError(t, err, testCase.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			s.requireX509CAEqual(x509CA, s.currentX509CA())
+			expectedWarnings := 0
+			if testCase.expectWarn {
+				expectedWarnings = 1
+			}
+			assert.Equal(t, expectedWarnings, s.countLogEntries(logrus.WarnLevel, warning))
+		})
+	}
+}
+
+func (s *ManagerSuite) TestUpstreamAuthorityValidationWithFailover() {
+	const warning = "Upstream authority failed to sign the test CSR; X509 CAs cannot be prepared until it is fixed"
+
+	testCases := []struct {
+		name            string
+		primaryBroken   bool
+		secondaryBroken bool
+		expectErr       string
+		expectWarnFor   string
+	}{
+		{
+			name: "both upstream authorities work",
+		},
+		{
+			name:            "broken secondary is only logged",
+			secondaryBroken: true,
+			expectWarnFor:   "secondary",
+		},
+		{
+			name:          "broken primary fails even if the secondary works",
+			primaryBroken: true,
+			expectErr:     "upstream authority failed to sign the test CSR: upstream is down",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		s.T().Run(testCase.name, func(t *testing.T) {
+			s.dir = s.TempDir()
+			s.logHook.Reset()
+			primaryUA, _ := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+				TrustDomain: testTrustDomain,
+			})
+			secondaryUA, _ := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+				TrustDomain: testTrustDomain,
+			})
+			primary := &failingUpstreamAuthority{UpstreamAuthority: primaryUA, name: "primary"}
+			secondary := &failingUpstreamAuthority{UpstreamAuthority: secondaryUA, name: "secondary"}
+			if testCase.primaryBroken {
+				primary.SetErr(errors.New("upstream is down"))
+			}
+			if testCase.secondaryBroken {
+				secondary.SetErr(errors.New("upstream is down"))
+			}
+			s.cat.SetUpstreamAuthority(primary)
+			s.cat.AddUpstreamAuthority(secondary)
+			c := s.selfSignedConfig()
+			c.UpstreamAuthorityOrder = []string{"primary", "secondary"}
+			c.UpstreamAuthorityValidation = FailUpstreamAuthorityValidation
+
+			s.m = NewManager(c)
+			err := s.m.Initialize(context.Background())
+			if testCase.expectErr != "" {
+				assert.EqualError(t, err, testCase.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var warnedFor []string
+			for _, entry := range s.logHook.AllEntries() {
+				if entry.Level == logrus.WarnLevel && entry.Message == warning {
+					warnedFor = append(warnedFor, entry.Data[telemetry.PluginName].(string))
+				}
+			}
+			if testCase.expectWarnFor != "" {
+				assert.Equal(t, []string{testCase.expectWarnFor}, warnedFor)
+			} else {
+				assert.Empty(t, warnedFor)
+			}
+		})
+	}
+}
+
+func (s *ManagerSuite) TestUpstreamAuthorityValidationKeepsMintStream() {
+	upstreamAuthority, _ := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+		TrustDomain: testTrustDomain,
+	})
+	s.cat.SetUpstreamAuthority(upstreamAuthority)
+	c := s.selfSignedConfig()
+	c.UpstreamAuthorityValidation = WarnUpstreamAuthorityValidation
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+
+	// The stream of X509 root updates opened for the X509 CA in use is not
+	// replaced by one for the test CSR
+	client := s.m.upstreamClient.clients[0].client
+	stopped := client.mintX509CAStream.stopped
+	s.Require().NoError(s.m.validateUpstreamAuthorities(context.Background()))
+	select {
+	case <-stopped:
+		s.Fail("validation stopped the MintX509CA stream")
+	default:
+	}
+}
+
 func (s *ManagerSuite) TestX509CARotation() {
 	notifier, notifyCh := fakenotifier.NotifyBundleUpdatedWaiter(s.T())
 	s.setNotifier(notifier)
@@ -324,6 +573,47 @@ func (s *ManagerSuite) TestX509CARotationMetric() {
 	s.Require().Equal(expected.AllMetrics(), metrics.AllMetrics())
 }
 
+func (s *ManagerSuite) TestX509CAStatus() {
+	s.initSelfSignedManager()
+
+	initTime := s.clock.Now()
+
+	// after initialization, the current X509CA is active with no next one
+	// and the preparation and activation are scheduled from it.
+	first := s.currentX509CA()
+	status := s.m.X509CAStatus()
+	s.Require().NotNil(status.Active)
+	s.requireX509CAEqual(first, status.Active.X509CA)
+	s.Equal(initTime.UTC(), status.Active.IssuedAt.UTC())
+	s.NotEmpty(status.Active.SlotID)
+	s.Nil(status.Next)
+	s.Equal(initTime.Add(prepareAfter).UTC(), status.PrepareNextAt.UTC())
+	s.Equal(initTime.Add(activateAfter).UTC(), status.ActivateNextAt.UTC())
+
+	// once the next X509CA is prepared, it is reported and there is nothing
+	// left to prepare.
+	s.setTimeAndRotateX509CA(initTime.Add(prepareAfter + time.Minute))
+	second := s.nextX509CA()
+	status = s.m.X509CAStatus()
+	s.Require().NotNil(status.Active)
+	s.requireX509CAEqual(first, status.Active.X509CA)
+	s.Require().NotNil(status.Next)
+	s.requireX509CAEqual(second, status.Next.X509CA)
+	s.NotEqual(status.Active.SlotID, status.Next.SlotID)
+	s.True(status.PrepareNextAt.IsZero())
+	s.Equal(initTime.Add(activateAfter).UTC(), status.ActivateNextAt.UTC())
+
+	// after the activation, the next X509CA becomes the active one.
+	activationTime := s.clock.Now().Add(activateAfter - prepareAfter)
+	s.setTimeAndRotateX509CA(activationTime)
+	status = s.m.X509CAStatus()
+	s.Require().NotNil(status.Active)
+	s.requireX509CAEqual(second, status.Active.X509CA)
+	s.Nil(status.Next)
+	s.False(status.PrepareNextAt.IsZero())
+	s.False(status.ActivateNextAt.IsZero())
+}
+
 func (s *ManagerSuite) TestJWTKeyRotation() {
 	notifier, notifyCh := fakenotifier.NotifyBundleUpdatedWaiter(s.T())
 	s.setNotifier(notifier)
@@ -397,6 +687,141 @@ func (s *ManagerSuite) TestJWTKeyRotation() {
 	s.Nil(s.nextJWTKey())
 }
 
+func (s *ManagerSuite) TestJWTKeyIDThumbprint() {
+	c := s.selfSignedConfig()
+	c.JWTKeyIDThumbprint = true
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+
+	first := s.currentJWTKey()
+	s.Equal(s.jwkThumbprint(first.Signer.Public()), first.Kid)
+	s.requireBundleJWTKeys(first)
+
+	// the key ID of the prepared key is also its thumbprint
+	s.addTimeAndRotateJWTKey(prepareAfter + time.Minute)
+	second := s.nextJWTKey()
+	s.Require().NotNil(second)
+	s.Equal(s.jwkThumbprint(second.Signer.Public()), second.Kid)
+	s.NotEqual(first.Kid, second.Kid)
+	s.requireBundleJWTKeys(first, second)
+
+	// key IDs are persisted and survive a restart
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+	s.requireJWTKeyEqual(first, s.currentJWTKey())
+	s.requireJWTKeyEqual(second, s.nextJWTKey())
+}
+
+func (s *ManagerSuite) TestJWTKeyNoticePeriod() {
+	const noticePeriod = 30 * time.Minute
+
+	c := s.selfSignedConfig()
+	c.JWTKeyNoticePeriod = noticePeriod
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+
+	// CA TTL is an hour, so the next key would be prepared after thirty
+	// minutes and activated after fifty. The notice period moves the
+	// preparation up to twenty minutes.
+	initTime := s.clock.Now()
+	first := s.currentJWTKey()
+	s.setTimeAndRotateJWTKey(initTime.Add(activateAfter - noticePeriod))
+	s.Nil(s.nextJWTKey(), "second JWTKey should not be prepared yet")
+
+	// the second key is published in the bundle while the first one still
+	// signs the JWT-SVIDs
+	s.addTimeAndRotateJWTKey(time.Minute)
+	second := s.nextJWTKey()
+	s.Require().NotNil(second, "second JWTKey should have been prepared")
+	s.requireBundleJWTKeys(first, second)
+	s.requireJWTKeyEqual(first, s.ca.JWTKey())
+	publishedAt := s.clock.Now()
+
+	// past the activation mark, the first key keeps signing until the
+	// second has been published for the notice period
+	s.setTimeAndRotateJWTKey(initTime.Add(activateAfter + time.Second))
+	s.requireJWTKeyEqual(first, s.currentJWTKey())
+	s.setTimeAndRotateJWTKey(publishedAt.Add(noticePeriod - time.Second))
+	s.requireJWTKeyEqual(first, s.currentJWTKey())
+
+	s.setTimeAndRotateJWTKey(publishedAt.Add(noticePeriod))
+	s.requireJWTKeyEqual(second, s.currentJWTKey())
+	s.Nil(s.nextJWTKey())
+}
+
+func (s *ManagerSuite) TestJWTKeyNoticePeriodWhenPreparedLate() {
+	const noticePeriod = 30 * time.Minute
+
+	c := s.selfSignedConfig()
+	c.JWTKeyNoticePeriod = noticePeriod
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+	initTime := s.clock.Now()
+	first := s.currentJWTKey()
+
+	// the rotation did not run before the activation mark (e.g. the server
+	// was down), so the second key is prepared late. It is not activated
+	// right away.
+	s.setTimeAndRotateJWTKey(initTime.Add(activateAfter + time.Minute))
+	second := s.nextJWTKey()
+	s.Require().NotNil(second, "second JWTKey should have been prepared")
+	s.requireBundleJWTKeys(first, second)
+	s.requireJWTKeyEqual(first, s.currentJWTKey())
+
+	// the notice period holds across restarts
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+	s.requireJWTKeyEqual(first, s.currentJWTKey())
+	s.requireJWTKeyEqual(second, s.nextJWTKey())
+
+	// the second key is activated anyway once the first one expires
+	s.setTimeAndRotateJWTKey(initTime.Add(testCATTL - time.Second))
+	s.requireJWTKeyEqual(first, s.currentJWTKey())
+	s.setTimeAndRotateJWTKey(initTime.Add(testCATTL))
+	s.requireJWTKeyEqual(second, s.currentJWTKey())
+}
+
+func (s *ManagerSuite) TestTrustDomainJWTKeys() {
+	otherTrustDomain := spiffeid.RequireTrustDomainFromString("other.test")
+
+	c := s.selfSignedConfig()
+	c.JWTKeyTrustDomains = []spiffeid.TrustDomain{otherTrustDomain}
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+
+	// each trust domain has its own key, published in its own bundle
+	first := s.currentJWTKey()
+	otherFirst := s.currentTrustDomainJWTKey(otherTrustDomain)
+	s.requireJWTKeyNotEqual(first, otherFirst)
+	s.NotEqual(first.Kid, otherFirst.Kid)
+	s.requireBundleJWTKeys(first)
+	s.requireTrustDomainBundleJWTKeys(otherTrustDomain, otherFirst)
+	s.Empty(s.fetchBundleForTrustDomain(otherTrustDomain).RootCas)
+
+	// the key of the other trust domain is rotated alongside
+	s.addTimeAndRotate(prepareAfter + time.Minute)
+	otherSecond := s.m.trustDomainJWTKeys[otherTrustDomain].next.jwtKey
+	s.Require().NotNil(otherSecond)
+	s.requireTrustDomainBundleJWTKeys(otherTrustDomain, otherFirst, otherSecond)
+
+	// keys are persisted and survive a restart
+	s.ca = new(fakeCA)
+	c.CA = s.ca
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+	s.requireJWTKeyEqual(otherFirst, s.currentTrustDomainJWTKey(otherTrustDomain))
+	s.requireJWTKeyEqual(otherSecond, s.m.trustDomainJWTKeys[otherTrustDomain].next.jwtKey)
+
+	s.addTimeAndRotate(activateAfter - prepareAfter)
+	s.requireJWTKeyEqual(otherSecond, s.currentTrustDomainJWTKey(otherTrustDomain))
+}
+
+func (s *ManagerSuite) jwkThumbprint(publicKey crypto.PublicKey) string {
+	thumbprint, err := (&jose.JSONWebKey{Key: publicKey}).Thumbprint(crypto.SHA256)
+	s.Require().NoError(err)
+	return base64.RawURLEncoding.EncodeToString(thumbprint)
+}
+
 func (s *ManagerSuite) TestPrune() {
 	notifier, notifyCh := fakenotifier.NotifyBundleUpdatedWaiter(s.T())
 	s.setNotifier(notifier)
@@ -446,6 +871,31 @@ func (s *ManagerSuite) TestPrune() {
 	s.requireBundleJWTKeys(secondJWTKey)
 }
 
+func (s *ManagerSuite) TestPruneTrustDomainJWTKeys() {
+	otherTrustDomain := spiffeid.RequireTrustDomainFromString("other.test")
+
+	c := s.selfSignedConfig()
+	c.JWTKeyTrustDomains = []spiffeid.TrustDomain{otherTrustDomain}
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+
+	initTime := s.clock.Now()
+	firstExpiresTime := initTime.Add(testCATTL)
+
+	// rotate so that the other trust domain has two keys in its bundle
+	s.setTimeAndRotate(initTime.Add(prepareAfter + time.Minute))
+	otherFirst := s.currentTrustDomainJWTKey(otherTrustDomain)
+	otherSecond := s.m.trustDomainJWTKeys[otherTrustDomain].next.jwtKey
+	s.Require().NotNil(otherSecond)
+	s.requireTrustDomainBundleJWTKeys(otherTrustDomain, otherFirst, otherSecond)
+
+	// advance beyond the safety threshold of the first, prune, and assert that
+	// the first key has been pruned from the bundle of the other trust domain
+	// too, even though that bundle has no CA certificates.
+	s.setTimeAndPrune(firstExpiresTime.Add(time.Minute + safetyThreshold))
+	s.requireTrustDomainBundleJWTKeys(otherTrustDomain, otherSecond)
+}
+
 func (s *ManagerSuite) TestMigration() {
 	// assert that we migrate on load by writing junk data to the old JSON file
 	// and making sure initialization fails. The journal tests exercise this
@@ -510,6 +960,88 @@ func (s *ManagerSuite) TestRunFailsIfNotifierFails() {
 	s.Equal("Notifier failed to handle event", entry.Message)
 }
 
+func (s *ManagerSuite) TestBundleUpdatedNotifiersAreIsolated() {
+	// The slow notifier blocks on the first update until the test is done
+	releaseCh := make(chan struct{})
+	defer close(releaseCh)
+	slowCh := make(chan *common.Bundle, 10)
+	s.setNotifier(fakenotifier.New(s.T(), fakenotifier.Config{
+		OnNotifyBundleUpdated: func(bundle *common.Bundle) error {
+			slowCh <- bundle
+			<-releaseCh
+			return nil
+		},
+	}))
+	s.setNotifier(fakenotifier.New(s.T(), fakenotifier.Config{
+		OnNotifyBundleUpdated: func(bundle *common.Bundle) error {
+			return errors.New("ohno")
+		},
+	}))
+	notifier, notifyCh := fakenotifier.NotifyBundleUpdatedWaiter(s.T())
+	s.setNotifier(notifier)
+	s.initSelfSignedManager()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.m.dropBundleUpdated()
+	go s.m.notifyOnBundleUpdate(ctx)
+
+	// The other notifiers keep receiving the updates while the slow one is
+	// stuck on the first and the failing one returns errors
+	s.m.bundleUpdated()
+	s.waitForBundleUpdatedNotification(slowCh)
+	s.waitForBundleUpdatedNotification(notifyCh)
+	for i := 0; i < 2; i++ {
+		s.m.bundleUpdated()
+		s.waitForBundleUpdatedNotification(notifyCh)
+	}
+	s.Require().Empty(slowCh)
+}
+
+func (s *ManagerSuite) TestBundleUpdatedNotifierTimeout() {
+	releaseCh := make(chan struct{})
+	defer close(releaseCh)
+	var calls int32
+	s.setNotifier(fakenotifier.New(s.T(), fakenotifier.Config{
+		OnNotifyBundleUpdated: func(bundle *common.Bundle) error {
+			atomic.AddInt32(&calls, 1)
+			<-releaseCh
+			return nil
+		},
+	}))
+	notifier, notifyCh := fakenotifier.NotifyBundleUpdatedWaiter(s.T())
+	s.setNotifier(notifier)
+
+	c := s.selfSignedConfig()
+	c.NotifierTimeout = 100 * time.Millisecond
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.m.dropBundleUpdated()
+	go s.m.notifyOnBundleUpdate(ctx)
+
+	s.m.bundleUpdated()
+	s.waitForBundleUpdatedNotification(notifyCh)
+
+	// The slow notifier times out, and is notified again of the next update
+	s.Require().Eventually(func() bool {
+		for _, entry := range s.logHook.AllEntries() {
+			if entry.Message == "Notifier failed to handle event" && strings.Contains(fmt.Sprint(entry.Data[logrus.ErrorKey]), "DeadlineExceeded") {
+				return true
+			}
+		}
+		return false
+	}, time.Minute, 10*time.Millisecond)
+
+	s.m.bundleUpdated()
+	s.waitForBundleUpdatedNotification(notifyCh)
+	s.Require().Eventually(func() bool {
+		return atomic.LoadInt32(&calls) == 2
+	}, time.Minute, 10*time.Millisecond)
+}
+
 func (s *ManagerSuite) TestPreparationThresholdCap() {
 	issuedAt := time.Now()
 	notAfter := issuedAt.Add(365 * 24 * time.Hour)
@@ -678,6 +1210,57 @@ func (s *ManagerSuite) TestAlternateKeyTypes() {
 	}
 }
 
+func (s *ManagerSuite) TestFIPSModeValidatesKeyTypes() {
+	testCases := []struct {
+		name          string
+		x509CAKeyType keymanager.KeyType
+		jwtKeyType    keymanager.KeyType
+		expectErr     string
+	}{
+		{
+			name: "defaults are approved",
+		},
+		{
+			name:          "approved key types",
+			x509CAKeyType: keymanager.ECP384,
+			jwtKeyType:    keymanager.RSA2048,
+		},
+		{
+			name:          "X509 CA key type is not approved",
+			x509CAKeyType: keymanager.RSA1024,
+			jwtKeyType:    keymanager.ECP256,
+			expectErr:     "invalid X509 CA key type: key type rsa-1024 is not FIPS-approved",
+		},
+		{
+			name:          "JWT signing key type is not approved",
+			x509CAKeyType: keymanager.ECP256,
+			jwtKeyType:    keymanager.RSA1024,
+			expectErr:     "invalid JWT signing key type: key type rsa-1024 is not FIPS-approved",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		s.T().Run(testCase.name, func(t *testing.T) {
+			s.ca = new(fakeCA)
+			c := s.selfSignedConfigWithKeyTypes(testCase.x509CAKeyType, testCase.jwtKeyType)
+			c.FIPSMode = true
+			s.cat.SetKeyManager(fakeserverkeymanager.New(s.T()))
+			s.cat.SetUpstreamAuthority(nil)
+
+			s.m = NewManager(c)
+			err := s.m.Initialize(context.Background())
+			if testCase.expectErr != "" {
+				assert.EqualError(t, err, testCase.expectErr)
+				assert.Nil(t, s.ca.X509CA())
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, s.currentX509CA())
+		})
+	}
+}
+
 func (s *ManagerSuite) initSelfSignedManager() {
 	s.cat.SetUpstreamAuthority(nil)
 	s.m = NewManager(s.selfSignedConfig())
@@ -780,6 +1363,16 @@ func (s *ManagerSuite) getSignerInfo(signer crypto.Signer) signerInfo {
 	}
 }
 
+func (s *ManagerSuite) requireBundleHasRootCAs(rootCAs ...*x509.Certificate) {
+	var actual [][]byte
+	for _, rootCA := range s.fetchBundle().RootCas {
+		actual = append(actual, rootCA.DerBytes)
+	}
+	for _, rootCA := range rootCAs {
+		s.Require().Contains(actual, rootCA.Raw)
+	}
+}
+
 func (s *ManagerSuite) requireBundleRootCAs(rootCAs ...*x509.Certificate) {
 	expected := &common.Bundle{}
 	for _, rootCA := range rootCAs {
@@ -795,6 +1388,10 @@ func (s *ManagerSuite) requireBundleRootCAs(rootCAs ...*x509.Certificate) {
 }
 
 func (s *ManagerSuite) requireBundleJWTKeys(jwtKeys ...*JWTKey) {
+	s.requireTrustDomainBundleJWTKeys(testTrustDomain, jwtKeys...)
+}
+
+func (s *ManagerSuite) requireTrustDomainBundleJWTKeys(td spiffeid.TrustDomain, jwtKeys ...*JWTKey) {
 	expected := &common.Bundle{}
 	for _, jwtKey := range jwtKeys {
 		publicKey, err := publicKeyFromJWTKey(jwtKey)
@@ -802,7 +1399,7 @@ func (s *ManagerSuite) requireBundleJWTKeys(jwtKeys ...*JWTKey) {
 		expected.JwtSigningKeys = append(expected.JwtSigningKeys, publicKey)
 	}
 
-	bundle := s.fetchBundle()
+	bundle := s.fetchBundleForTrustDomain(td)
 	s.RequireProtoEqual(expected, &common.Bundle{
 		JwtSigningKeys: bundle.JwtSigningKeys,
 	})
@@ -838,6 +1435,12 @@ func (s *ManagerSuite) currentJWTKey() *JWTKey {
 	return s.m.currentJWTKey.jwtKey
 }
 
+func (s *ManagerSuite) currentTrustDomainJWTKey(td spiffeid.TrustDomain) *JWTKey {
+	current := s.m.trustDomainJWTKeys[td].current.jwtKey
+	s.requireJWTKeyEqual(current, s.ca.TrustDomainJWTKey(td), "current JWTKey is not active")
+	return current
+}
+
 func (s *ManagerSuite) nextX509CA() *X509CA {
 	return s.m.nextX509CA.x509CA
 }
@@ -911,9 +1514,10 @@ func (s *ManagerSuite) countLogEntries(level logrus.Level, message string) int {
 }
 
 type fakeCA struct {
-	mu     sync.Mutex
-	x509CA *X509CA
-	jwtKey *JWTKey
+	mu                 sync.Mutex
+	x509CA             *X509CA
+	jwtKey             *JWTKey
+	trustDomainJWTKeys map[spiffeid.TrustDomain]*JWTKey
 }
 
 func (s *fakeCA) X509CA() *X509CA {
@@ -939,3 +1543,65 @@ func (s *fakeCA) SetJWTKey(jwtKey *JWTKey) {
 	defer s.mu.Unlock()
 	s.jwtKey = jwtKey
 }
+
+func (s *fakeCA) TrustDomainJWTKey(td spiffeid.TrustDomain) *JWTKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.trustDomainJWTKeys[td]
+}
+
+func (s *fakeCA) SetTrustDomainJWTKey(td spiffeid.TrustDomain, jwtKey *JWTKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.trustDomainJWTKeys == nil {
+		s.trustDomainJWTKeys = make(map[spiffeid.TrustDomain]*JWTKey)
+	}
+	s.trustDomainJWTKeys[td] = jwtKey
+}
+
+// failingUpstreamAuthority renames an upstream authority and fails to mint
+// X509 CAs while err is set, or hangs if hang is set.
+type failingUpstreamAuthority struct {
+	upstreamauthority.UpstreamAuthority
+
+	name string
+	hang bool
+
+	mu    sync.Mutex
+	err   error
+	mints int
+}
+
+func (ua *failingUpstreamAuthority) Name() string {
+	return ua.name
+}
+
+func (ua *failingUpstreamAuthority) SetErr(err error) {
+	ua.mu.Lock()
+	defer ua.mu.Unlock()
+	ua.err = err
+}
+
+// Mints returns how many times the upstream authority was asked to mint an
+// X509 CA.
+func (ua *failingUpstreamAuthority) Mints() int {
+	ua.mu.Lock()
+	defer ua.mu.Unlock()
+	return ua.mints
+}
+
+func (ua *failingUpstreamAuthority) MintX509CA(ctx context.Context, csr []byte, preferredTTL time.Duration) ([]*x509.Certificate, []*x509.Certificate, upstreamauthority.UpstreamX509AuthorityStream, error) {
+	ua.mu.Lock()
+	ua.mints++
+	err := ua.err
+	ua.mu.Unlock()
+
+	switch {
+	case ua.hang:
+		<-ctx.Done()
+		return nil, nil, nil, ctx.Err()
+	case err != nil:
+		return nil, nil, nil, err
+	}
+	return ua.UpstreamAuthority.MintX509CA(ctx, csr, preferredTTL)
+}
@@ -0,0 +1,140 @@
+package ca
+
+import (
+	"context"
+	"crypto/x509"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+// X509CAMinter mints X509 CAs signed by an upstream authority.
+type X509CAMinter interface {
+	MintX509CA(ctx context.Context, csr []byte, ttl time.Duration) ([]*x509.Certificate, error)
+}
+
+// upstreamFailoverTimeout bounds how long each upstream authority has to mint
+// the X509 CA when there are others to fail over to.
+const upstreamFailoverTimeout = time.Minute
+
+// upstreamClients fails over between an ordered list of upstream authority
+// clients. Every call is made against the primary upstream authority first,
+// and against the next one in the order only if it fails, so the server goes
+// back to the primary as soon as it recovers. The roots and JWT keys of an
+// upstream authority are added to the bundle once it is used.
+type upstreamClients struct {
+	log     logrus.FieldLogger
+	clients []namedUpstreamClient
+
+	// mintTimeout bounds each MintX509CA call when there is more than one
+	// upstream authority.
+	mintTimeout time.Duration
+}
+
+type namedUpstreamClient struct {
+	name              string
+	upstreamAuthority upstreamauthority.UpstreamAuthority
+	client            *UpstreamClient
+}
+
+// MintX509CA mints the X509 CA against the first upstream authority, in order,
+// that succeeds. If all of them fail, the error returned by the primary
+// upstream authority is returned.
+func (u *upstreamClients) MintX509CA(ctx context.Context, csr []byte, ttl time.Duration) (x509CA []*x509.Certificate, err error) {
+	var timeout time.Duration
+	if len(u.clients) > 1 {
+		timeout = u.mintTimeout
+	}
+	err = u.failover(ctx, timeout, "Upstream authority failed to mint the X509 CA", func(ctx context.Context, client *UpstreamClient) (err error) {
+		x509CA, err = client.MintX509CA(ctx, csr, ttl)
+		return err
+	})
+	return x509CA, err
+}
+
+// PublishJWTKey publishes the JWT key to the first upstream authority, in
+// order, that succeeds and returns the JWT keys it returned. If all of them
+// fail, the error returned by the primary upstream authority is returned.
+func (u *upstreamClients) PublishJWTKey(ctx context.Context, jwtKey *common.PublicKey) (jwtKeys []*common.PublicKey, err error) {
+	err = u.failover(ctx, publishJWKTimeout, "Upstream authority failed to publish the JWT key", func(ctx context.Context, client *UpstreamClient) (err error) {
+		jwtKeys, err = client.PublishJWTKey(ctx, jwtKey)
+		return err
+	})
+	return jwtKeys, err
+}
+
+// Close closes the clients of all of the upstream authorities.
+func (u *upstreamClients) Close() error {
+	for _, c := range u.clients {
+		_ = c.client.Close()
+	}
+	return nil
+}
+
+func (u *upstreamClients) primaryName() string {
+	return u.clients[0].name
+}
+
+// failover calls fn against each upstream authority in order until one
+// succeeds. Each call is bounded by the timeout, if set, so that an upstream
+// authority that hangs does not prevent failing over. The failures are only
+// logged, unless all of them failed, in which case the error of the primary
+// upstream authority is returned.
+func (u *upstreamClients) failover(ctx context.Context, timeout time.Duration, msg string, fn func(context.Context, *UpstreamClient) error) error {
+	var primaryErr error
+	for i, c := range u.clients {
+		err := callWithTimeout(ctx, timeout, c.client, fn)
+		if err == nil {
+			if i > 0 {
+				u.log.WithField(telemetry.PluginName, c.name).Warn("Primary upstream authority is unavailable; failed over to the next upstream authority")
+			}
+			return nil
+		}
+		if i == 0 {
+			primaryErr = err
+		}
+		if len(u.clients) > 1 {
+			u.log.WithError(err).WithField(telemetry.PluginName, c.name).Warn(msg)
+		}
+	}
+	return primaryErr
+}
+
+func callWithTimeout(ctx context.Context, timeout time.Duration, client *UpstreamClient, fn func(context.Context, *UpstreamClient) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return fn(ctx, client)
+}
+
+// orderUpstreamAuthorities sorts the upstream authorities by their position
+// in the given order. Upstream authorities missing from the order come last,
+// sorted by name.
+func orderUpstreamAuthorities(upstreamAuthorities []upstreamauthority.UpstreamAuthority, order []string) []upstreamauthority.UpstreamAuthority {
+	position := make(map[string]int, len(order))
+	for i, name := range order {
+		position[name] = i
+	}
+	rank := func(ua upstreamauthority.UpstreamAuthority) int {
+		if i, ok := position[ua.Name()]; ok {
+			return i
+		}
+		return len(order)
+	}
+
+	sorted := append([]upstreamauthority.UpstreamAuthority(nil), upstreamAuthorities...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ri, rj := rank(sorted[i]), rank(sorted[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return sorted[i].Name() < sorted[j].Name()
+	})
+	return sorted
+}
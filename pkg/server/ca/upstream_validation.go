@@ -0,0 +1,116 @@
+package ca
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/cryptoutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority"
+)
+
+const (
+	// upstreamValidationTTL is the TTL requested for the X509 CA minted to
+	// validate the upstream authority. The X509 CA is discarded right away.
+	upstreamValidationTTL = time.Hour
+
+	upstreamValidationTimeout = 30 * time.Second
+)
+
+// UpstreamAuthorityValidation determines whether the upstream authority is
+// validated when the manager is initialized, by having it sign a test CSR,
+// and what happens when it fails to.
+type UpstreamAuthorityValidation int
+
+const (
+	// SkipUpstreamAuthorityValidation does not validate the upstream
+	// authority. This is the default.
+	SkipUpstreamAuthorityValidation UpstreamAuthorityValidation = iota
+
+	// WarnUpstreamAuthorityValidation logs a warning when the upstream
+	// authority fails to sign the test CSR.
+	WarnUpstreamAuthorityValidation
+
+	// FailUpstreamAuthorityValidation fails the initialization when the
+	// upstream authority fails to sign the test CSR.
+	FailUpstreamAuthorityValidation
+)
+
+// ParseUpstreamAuthorityValidation parses a validation mode name, one of
+// "off", "warn" or "fail". An empty name is the default mode.
+func ParseUpstreamAuthorityValidation(name string) (UpstreamAuthorityValidation, error) {
+	switch strings.ToLower(name) {
+	case "", "off":
+		return SkipUpstreamAuthorityValidation, nil
+	case "warn":
+		return WarnUpstreamAuthorityValidation, nil
+	case "fail":
+		return FailUpstreamAuthorityValidation, nil
+	default:
+		return 0, fmt.Errorf("invalid upstream authority validation %q: expected off, warn or fail", name)
+	}
+}
+
+// validateUpstreamAuthorities has each upstream authority sign a CSR for a
+// throwaway key, as it does for the X509 CAs of the server, and checks that
+// it returned a certificate for that key. Since the X509 CAs persisted in the
+// journal are reused on startup, this is the only way to find out that an
+// upstream authority is broken before the next X509 CA has to be prepared.
+//
+// A failure of the primary upstream authority fails the initialization when
+// validation is set to fail. The failures of the others, which are only
+// used when failing over, are logged.
+func (m *Manager) validateUpstreamAuthorities(ctx context.Context) error {
+	for i, c := range m.upstreamClient.clients {
+		err := m.validateUpstreamAuthority(ctx, c.upstreamAuthority)
+		if err == nil {
+			continue
+		}
+		if i == 0 && m.c.UpstreamAuthorityValidation == FailUpstreamAuthorityValidation {
+			return fmt.Errorf("upstream authority failed to sign the test CSR: %w", err)
+		}
+		m.c.Log.WithError(err).WithField(telemetry.PluginName, c.name).Warn("Upstream authority failed to sign the test CSR; X509 CAs cannot be prepared until it is fixed")
+	}
+	return nil
+}
+
+// validateUpstreamAuthority has the upstream authority sign the test CSR. It
+// calls the plugin directly rather than through the upstream client, so that
+// the stream of X509 root updates of the X509 CA in use is left alone.
+func (m *Manager) validateUpstreamAuthority(ctx context.Context, upstreamAuthority upstreamauthority.UpstreamAuthority) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	csr, err := GenerateServerCACSR(key, m.c.TrustDomain, m.c.CASubject)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, upstreamValidationTimeout)
+	defer cancel()
+
+	caChain, _, stream, err := upstreamAuthority.MintX509CA(ctx, csr, upstreamValidationTTL)
+	if err != nil {
+		return err
+	}
+	stream.Close()
+
+	if len(caChain) == 0 {
+		return errors.New("upstream authority returned an empty chain")
+	}
+	matches, err := cryptoutil.PublicKeyEqual(caChain[0].PublicKey, key.Public())
+	if err != nil {
+		return err
+	}
+	if !matches {
+		return errors.New("upstream authority returned a certificate for another key")
+	}
+	return nil
+}
@@ -2,10 +2,17 @@ package entrycache
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"sync"
 
+	"github.com/andres-erbsen/clock"
+	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/idutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
 )
 
 var (
@@ -84,9 +91,32 @@ type Agent struct {
 	Selectors []*types.Selector
 }
 
+// BuildOptions controls how agent selectors are matched against the
+// node-aliased registration entries while building the cache.
+type BuildOptions struct {
+	// KnownSelectorTypes are the selector types the server expects agents
+	// to have. It is only consulted if UnknownSelectorTypePolicy is not
+	// api.IgnoreUnknownSelectorTypes.
+	KnownSelectorTypes []string
+
+	// UnknownSelectorTypePolicy determines what happens with agents that
+	// have selectors of a type not in KnownSelectorTypes.
+	UnknownSelectorTypePolicy api.UnknownSelectorTypePolicy
+
+	// Log is used to report agents with unknown selector types. If nil,
+	// nothing is logged.
+	Log logrus.FieldLogger
+
+	// Clock is used to skip expired entries, both when building the cache
+	// and when looking up authorized entries. If nil, the system clock is
+	// used.
+	Clock clock.Clock
+}
+
 type FullEntryCache struct {
 	aliases map[spiffeID][]aliasEntry
 	entries map[spiffeID][]*types.Entry
+	clk     clock.Clock
 }
 
 type selectorSet map[Selector]struct{}
@@ -108,15 +138,30 @@ type aliasEntry struct {
 // Build queries the data source for all registration entries and Agent selectors and builds an in-memory
 // representation of the data that can be used for efficient lookups.
 func Build(ctx context.Context, entryIter EntryIterator, agentIter AgentIterator) (*FullEntryCache, error) {
+	return BuildWithOptions(ctx, entryIter, agentIter, BuildOptions{})
+}
+
+// BuildWithOptions is like Build but matches agent selectors according to
+// the given options.
+func BuildWithOptions(ctx context.Context, entryIter EntryIterator, agentIter AgentIterator, opts BuildOptions) (*FullEntryCache, error) {
 	type aliasInfo struct {
 		aliasEntry
 		selectors selectorSet
 	}
 	bysel := make(map[Selector][]aliasInfo)
 
+	clk := opts.Clock
+	if clk == nil {
+		clk = clock.New()
+	}
+	now := clk.Now().Unix()
+
 	entries := make(map[spiffeID][]*types.Entry)
 	for entryIter.Next(ctx) {
 		entry := entryIter.Entry()
+		if isExpired(entry, now) {
+			continue
+		}
 		parentID := spiffeIDFromProto(entry.ParentId)
 		if parentID.Path == "/spire/server" {
 			alias := aliasInfo{
@@ -137,6 +182,14 @@ func Build(ctx context.Context, entryIter EntryIterator, agentIter AgentIterator
 		return nil, err
 	}
 
+	var knownTypes stringSet
+	if opts.UnknownSelectorTypePolicy != api.IgnoreUnknownSelectorTypes {
+		knownTypes = make(stringSet, len(opts.KnownSelectorTypes))
+		for _, selectorType := range opts.KnownSelectorTypes {
+			knownTypes[selectorType] = struct{}{}
+		}
+	}
+
 	aliasSeen := allocStringSet()
 	defer freeStringSet(aliasSeen)
 
@@ -144,6 +197,9 @@ func Build(ctx context.Context, entryIter EntryIterator, agentIter AgentIterator
 	for agentIter.Next(ctx) {
 		agent := agentIter.Agent()
 		agentID := spiffeIDFromID(agent.ID)
+		if knownTypes != nil && !checkSelectorTypes(agent, knownTypes, opts) {
+			continue
+		}
 		agentSelectors := selectorSetFromProto(agent.Selectors)
 		// track which aliases we've evaluated so far to make sure we don't
 		// add one twice.
@@ -167,55 +223,117 @@ func Build(ctx context.Context, entryIter EntryIterator, agentIter AgentIterator
 	return &FullEntryCache{
 		aliases: aliases,
 		entries: entries,
+		clk:     clk,
 	}, nil
 }
 
+// checkSelectorTypes applies the unknown selector type policy to the agent.
+// It returns false if no entries should be matched against the selectors of
+// the agent.
+func checkSelectorTypes(agent Agent, knownTypes stringSet, opts BuildOptions) bool {
+	var unknownTypes []string
+	for _, selector := range agent.Selectors {
+		if _, ok := knownTypes[selector.Type]; ok {
+			continue
+		}
+		if !containsString(unknownTypes, selector.Type) {
+			unknownTypes = append(unknownTypes, selector.Type)
+		}
+	}
+	if len(unknownTypes) == 0 {
+		return true
+	}
+
+	rejected := opts.UnknownSelectorTypePolicy == api.RejectUnknownSelectorTypes
+	if opts.Log != nil {
+		sort.Strings(unknownTypes)
+		log := opts.Log.WithFields(logrus.Fields{
+			telemetry.AgentID:       agent.ID.String(),
+			telemetry.SelectorTypes: strings.Join(unknownTypes, ","),
+		})
+		if rejected {
+			log.Warn("Agent has selectors of unknown types; no entries will be matched against its selectors")
+		} else {
+			log.Warn("Agent has selectors of unknown types")
+		}
+	}
+	return !rejected
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // GetAuthorizedEntries gets all authorized registration entries for a given Agent SPIFFE ID.
+// Entries that expired since the cache was built are skipped, along with the entries only
+// reachable through them.
 func (c *FullEntryCache) GetAuthorizedEntries(agentID spiffeid.ID) []*types.Entry {
 	seen := allocSeenSet()
 	defer freeSeenSet(seen)
 
-	return c.getAuthorizedEntries(spiffeIDFromID(agentID), seen)
+	return c.getAuthorizedEntries(spiffeIDFromID(agentID), seen, c.clk.Now().Unix())
 }
 
-func (c *FullEntryCache) getAuthorizedEntries(id spiffeID, seen map[spiffeID]struct{}) []*types.Entry {
-	entries := c.crawl(id, seen)
+func (c *FullEntryCache) getAuthorizedEntries(id spiffeID, seen map[spiffeID]struct{}, now int64) []*types.Entry {
+	entries := c.crawl(id, seen, now)
 	for _, descendant := range entries {
-		entries = append(entries, c.getAuthorizedEntries(spiffeIDFromProto(descendant.SpiffeId), seen)...)
+		entries = append(entries, c.getAuthorizedEntries(spiffeIDFromProto(descendant.SpiffeId), seen, now)...)
 	}
 
 	for _, alias := range c.aliases[id] {
+		if isExpired(alias.entry, now) {
+			continue
+		}
 		entries = append(entries, alias.entry)
-		entries = append(entries, c.getAuthorizedEntries(alias.id, seen)...)
+		entries = append(entries, c.getAuthorizedEntries(alias.id, seen, now)...)
 	}
 	return entries
 }
 
-func (c *FullEntryCache) crawl(parentID spiffeID, seen map[spiffeID]struct{}) []*types.Entry {
+func (c *FullEntryCache) crawl(parentID spiffeID, seen map[spiffeID]struct{}, now int64) []*types.Entry {
 	if _, ok := seen[parentID]; ok {
 		return nil
 	}
 	seen[parentID] = struct{}{}
 
 	// Make a copy so that the entries aren't aliasing the backing array
-	entries := append([]*types.Entry(nil), c.entries[parentID]...)
+	var entries []*types.Entry
+	for _, entry := range c.entries[parentID] {
+		if !isExpired(entry, now) {
+			entries = append(entries, entry)
+		}
+	}
 	for _, entry := range entries {
-		entries = append(entries, c.crawl(spiffeIDFromProto(entry.SpiffeId), seen)...)
+		entries = append(entries, c.crawl(spiffeIDFromProto(entry.SpiffeId), seen, now)...)
 	}
 	return entries
 }
 
+// isExpired returns whether the entry is past its expiry at the given Unix
+// time. Entries expiring exactly now are kept, as the datastore does when
+// pruning.
+func isExpired(entry *types.Entry, now int64) bool {
+	return entry.ExpiresAt != 0 && entry.ExpiresAt < now
+}
+
+// spiffeIDFromID and spiffeIDFromProto canonicalize the paths so that
+// equivalent IDs that are spelled differently match.
 func spiffeIDFromID(id spiffeid.ID) spiffeID {
 	return spiffeID{
 		TrustDomain: id.TrustDomain().String(),
-		Path:        id.Path(),
+		Path:        idutil.CanonicalizePath(id.Path()),
 	}
 }
 
 func spiffeIDFromProto(id *types.SPIFFEID) spiffeID {
 	return spiffeID{
-		TrustDomain: id.TrustDomain,
-		Path:        id.Path,
+		TrustDomain: strings.ToLower(id.TrustDomain),
+		Path:        idutil.CanonicalizePath(id.Path),
 	}
 }
 
@@ -20,7 +20,13 @@ var (
 
 // BuildFromDataStore builds a Cache using the provided datastore as the data source
 func BuildFromDataStore(ctx context.Context, ds datastore.DataStore) (*FullEntryCache, error) {
-	return Build(ctx, makeEntryIteratorDS(ds), makeAgentIteratorDS(ds))
+	return BuildFromDataStoreWithOptions(ctx, ds, BuildOptions{})
+}
+
+// BuildFromDataStoreWithOptions is like BuildFromDataStore but matches agent
+// selectors according to the given options.
+func BuildFromDataStoreWithOptions(ctx context.Context, ds datastore.DataStore, opts BuildOptions) (*FullEntryCache, error) {
+	return BuildWithOptions(ctx, makeEntryIteratorDS(ds), makeAgentIteratorDS(ds), opts)
 }
 
 type entryIteratorDS struct {
@@ -11,13 +11,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/plugin/datastore"
 	sqlds "github.com/spiffe/spire/pkg/server/plugin/datastore/sql"
 	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
@@ -420,6 +423,189 @@ func TestFullCacheExcludesNodeSelectorMappedEntriesForExpiredAgents(t *testing.T
 	assert.Equal(t, expectedEntry, entries[0])
 }
 
+func TestFullCacheMatchesCanonicalIDs(t *testing.T) {
+	// The entries are parented to IDs that are equivalent to the agent ID,
+	// or to the ID of the workload under it, but spelled differently
+	agentID := spiffeid.RequireFromString("spiffe://domain.test/spire/agent/x509pop/abc")
+	workload := &types.Entry{
+		Id:       "workload",
+		ParentId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/spire/agent/x509pop/abc/"},
+		SpiffeId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/workload"},
+	}
+	child := &types.Entry{
+		Id:       "child",
+		ParentId: &types.SPIFFEID{TrustDomain: "DOMAIN.test", Path: "//%77orkload"},
+		SpiffeId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/child"},
+	}
+	other := &types.Entry{
+		Id:       "other",
+		ParentId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/spire/agent/x509pop/abc%2Fdef"},
+		SpiffeId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/other"},
+	}
+
+	cache, err := Build(context.Background(), makeEntryIterator([]*types.Entry{workload, child, other}), makeAgentIterator(nil))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*types.Entry{workload, child}, cache.GetAuthorizedEntries(agentID))
+}
+
+func TestFullCacheSkipsExpiredEntries(t *testing.T) {
+	clk := clock.NewMock(t)
+	now := clk.Now().Unix()
+	agentID := spiffeid.RequireFromString("spiffe://domain.test/spire/agent/x509pop/abc")
+
+	// An expired node alias no longer passes its descendants to the agent
+	expiredAlias := &types.Entry{
+		Id:        "expired-alias",
+		ParentId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/spire/server"},
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/expired-alias"},
+		Selectors: []*types.Selector{{Type: "x509pop", Value: "subject:cn:abc"}},
+		ExpiresAt: now - 1,
+	}
+	underExpiredAlias := &types.Entry{
+		Id:       "under-expired-alias",
+		ParentId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/expired-alias"},
+		SpiffeId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/under-expired-alias"},
+	}
+	// An alias and a workload expiring after the cache is built
+	alias := &types.Entry{
+		Id:        "alias",
+		ParentId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/spire/server"},
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/alias"},
+		Selectors: []*types.Selector{{Type: "x509pop", Value: "subject:cn:abc"}},
+		ExpiresAt: now + 60,
+	}
+	underAlias := &types.Entry{
+		Id:       "under-alias",
+		ParentId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/alias"},
+		SpiffeId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/under-alias"},
+	}
+	workload := &types.Entry{
+		Id:        "workload",
+		ParentId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/spire/agent/x509pop/abc"},
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/workload"},
+		ExpiresAt: now + 60,
+	}
+	child := &types.Entry{
+		Id:       "child",
+		ParentId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/workload"},
+		SpiffeId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/child"},
+	}
+	expiresNow := &types.Entry{
+		Id:        "expires-now",
+		ParentId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/spire/agent/x509pop/abc"},
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/expires-now"},
+		ExpiresAt: now,
+	}
+	agents := []Agent{
+		{
+			ID:        agentID,
+			Selectors: []*types.Selector{{Type: "x509pop", Value: "subject:cn:abc"}},
+		},
+	}
+
+	entries := []*types.Entry{expiredAlias, underExpiredAlias, alias, underAlias, workload, child, expiresNow}
+	cache, err := BuildWithOptions(context.Background(), makeEntryIterator(entries), makeAgentIterator(agents), BuildOptions{
+		Clock: clk,
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*types.Entry{alias, underAlias, workload, child, expiresNow}, cache.GetAuthorizedEntries(agentID))
+
+	// Entries stop matching as soon as they expire, without a rebuild
+	clk.Add(time.Minute + time.Second)
+	assert.Empty(t, cache.GetAuthorizedEntries(agentID))
+}
+
+func TestFullCacheUnknownSelectorTypes(t *testing.T) {
+	agentID := spiffeid.RequireFromString("spiffe://domain.test/spire/agent/x509pop/abc")
+	alias := &types.Entry{
+		Id:        "alias",
+		ParentId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/spire/server"},
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/alias"},
+		Selectors: []*types.Selector{{Type: "x509pop", Value: "subject:cn:abc"}},
+	}
+	workload := &types.Entry{
+		Id:       "workload",
+		ParentId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/spire/agent/x509pop/abc"},
+		SpiffeId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/workload"},
+	}
+	agents := []Agent{
+		{
+			ID: agentID,
+			Selectors: []*types.Selector{
+				{Type: "x509pop", Value: "subject:cn:abc"},
+				{Type: "future", Value: "b"},
+				{Type: "future", Value: "a"},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name            string
+		policy          api.UnknownSelectorTypePolicy
+		knownTypes      []string
+		expectedEntries []*types.Entry
+		expectedLogs    []spiretest.LogEntry
+	}{
+		{
+			name:            "ignore",
+			policy:          api.IgnoreUnknownSelectorTypes,
+			knownTypes:      []string{"x509pop"},
+			expectedEntries: []*types.Entry{workload, alias},
+		},
+		{
+			name:            "warn",
+			policy:          api.WarnUnknownSelectorTypes,
+			knownTypes:      []string{"x509pop"},
+			expectedEntries: []*types.Entry{workload, alias},
+			expectedLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Agent has selectors of unknown types",
+					Data: logrus.Fields{
+						telemetry.AgentID:       agentID.String(),
+						telemetry.SelectorTypes: "future",
+					},
+				},
+			},
+		},
+		{
+			name:            "reject",
+			policy:          api.RejectUnknownSelectorTypes,
+			knownTypes:      []string{"x509pop"},
+			expectedEntries: []*types.Entry{workload},
+			expectedLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Agent has selectors of unknown types; no entries will be matched against its selectors",
+					Data: logrus.Fields{
+						telemetry.AgentID:       agentID.String(),
+						telemetry.SelectorTypes: "future",
+					},
+				},
+			},
+		},
+		{
+			name:            "reject with all types known",
+			policy:          api.RejectUnknownSelectorTypes,
+			knownTypes:      []string{"x509pop", "future"},
+			expectedEntries: []*types.Entry{workload, alias},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			log, hook := test.NewNullLogger()
+			cache, err := BuildWithOptions(context.Background(), makeEntryIterator([]*types.Entry{alias, workload}), makeAgentIterator(agents), BuildOptions{
+				KnownSelectorTypes:        tt.knownTypes,
+				UnknownSelectorTypePolicy: tt.policy,
+				Log:                       log,
+			})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.expectedEntries, cache.GetAuthorizedEntries(agentID))
+			spiretest.AssertLogs(t, hook.AllEntries(), tt.expectedLogs)
+		})
+	}
+}
+
 func TestBuildIteratorError(t *testing.T) {
 	tests := []struct {
 		desc    string
@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/health"
+	caws "github.com/spiffe/spire/pkg/common/plugin/aws"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	ds_telemetry "github.com/spiffe/spire/pkg/common/telemetry/server/datastore"
 	km_telemetry "github.com/spiffe/spire/pkg/common/telemetry/server/keymanager"
@@ -41,7 +44,7 @@ type Catalog interface {
 	GetNodeResolverNamed(name string) (noderesolver.NodeResolver, bool)
 	GetKeyManager() keymanager.KeyManager
 	GetNotifiers() []notifier.Notifier
-	GetUpstreamAuthority() (upstreamauthority.UpstreamAuthority, bool)
+	GetUpstreamAuthorities() []upstreamauthority.UpstreamAuthority
 }
 
 type HCLPluginConfigMap = catalog.HCLPluginConfigMap
@@ -52,6 +55,7 @@ type Config struct {
 	PluginConfig HCLPluginConfigMap
 
 	Metrics          telemetry.Metrics
+	HealthChecker    health.Checker
 	IdentityProvider identityproviderv0.IdentityProviderServer
 	AgentStore       agentstorev0.AgentStoreServer
 	MetricsService   metricsv0.MetricsServiceServer
@@ -67,6 +71,12 @@ type Repository struct {
 	io.Closer
 }
 
+func newRepository() *Repository {
+	return &Repository{
+		nodeAttestorRepository: newNodeAttestorRepository(),
+	}
+}
+
 func (repo *Repository) Plugins() map[string]catalog.PluginRepo {
 	return map[string]catalog.PluginRepo{
 		keyManagerType:        &repo.keyManagerRepository,
@@ -102,7 +112,7 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 		return nil, err
 	}
 
-	repo := new(Repository)
+	repo := newRepository()
 	repo.Closer, err = catalog.Load(ctx, catalog.Config{
 		Log: config.Log,
 		CoreConfig: catalog.CoreConfig{
@@ -129,40 +139,164 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 	}
 
 	dataStore = ds_telemetry.WithMetrics(dataStore, config.Metrics)
+	dataStore = ds_telemetry.WithTracing(dataStore)
 	dataStore = dscache.New(dataStore, clock.New())
 
 	repo.SetDataStore(dataStore)
 	repo.SetKeyManager(km_telemetry.WithMetrics(repo.GetKeyManager(), config.Metrics))
 
+	if err := addPluginHealthChecks(config.HealthChecker, pluginConfigs, repo); err != nil {
+		repo.Close()
+		return nil, err
+	}
+
 	return repo, nil
 }
 
+// addPluginHealthChecks registers health checks for the configured built-in
+// plugins that are able to report their own health.
+func addPluginHealthChecks(healthChecker health.Checker, pluginConfigs []catalog.PluginConfig, repo *Repository) error {
+	if healthChecker == nil {
+		return nil
+	}
+
+	for _, pluginConfig := range pluginConfigs {
+		if pluginConfig.IsExternal() || pluginConfig.Disabled {
+			continue
+		}
+		if pluginConfig.Type == nodeAttestorType && pluginConfig.Name == caws.PluginName && repo.awsIID != nil {
+			if err := healthChecker.AddCheck("server.plugin.nodeattestor."+caws.PluginName, repo.awsIID); err != nil {
+				return fmt.Errorf("failed adding healthcheck for %q: %w", caws.PluginName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate loads and configures the plugins like Load, but reports every
+// error found instead of stopping at the first one. The plugins are unloaded
+// before returning and host services refuse any call, so the configuration
+// does not take effect. The DataStore configuration is validated without
+// connecting to the database.
+func Validate(ctx context.Context, config Config) []error {
+	var errs []error
+
+	sqlConfig, err := sqlDataStoreConfig(config.PluginConfig[dataStoreType])
+	if err == nil {
+		err = ds_sql.ValidateConfiguration(sqlConfig.Data)
+	}
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid DataStore configuration: %w", err))
+	}
+
+	allPluginConfigs, err := catalog.PluginConfigsFromHCL(config.PluginConfig)
+	if err != nil {
+		return append(errs, err)
+	}
+
+	// The DataStore has been validated above and the deprecated "noop"
+	// NodeResolver is ignored, like in Load.
+	var pluginConfigs []catalog.PluginConfig
+	for _, pluginConfig := range allPluginConfigs {
+		switch {
+		case pluginConfig.Type == dataStoreType:
+		case pluginConfig.Type == nodeResolverType && pluginConfig.Name == "noop" && !pluginConfig.IsExternal():
+		default:
+			pluginConfigs = append(pluginConfigs, pluginConfig)
+		}
+	}
+
+	// Sort the plugins so errors are reported in a stable order
+	sort.Slice(pluginConfigs, func(i, j int) bool {
+		if pluginConfigs[i].Type != pluginConfigs[j].Type {
+			return pluginConfigs[i].Type < pluginConfigs[j].Type
+		}
+		return pluginConfigs[i].Name < pluginConfigs[j].Name
+	})
+
+	return append(errs, catalog.Validate(ctx, catalog.Config{
+		Log: config.Log,
+		CoreConfig: catalog.CoreConfig{
+			TrustDomain: config.TrustDomain,
+		},
+		PluginConfigs: pluginConfigs,
+		HostServices: []catalog.HostServiceServer{
+			{
+				ServiceServer: identityproviderv0.IdentityProviderServiceServer(&identityproviderv0.UnimplementedIdentityProviderServer{}),
+				LegacyType:    "IdentityProvider",
+			},
+			{
+				ServiceServer: agentstorev0.AgentStoreServiceServer(&agentstorev0.UnimplementedAgentStoreServer{}),
+				LegacyType:    "AgentStore",
+			},
+			{
+				ServiceServer: metricsv0.MetricsServiceServiceServer(&metricsv0.UnimplementedMetricsServiceServer{}),
+				LegacyType:    "MetricsService",
+			},
+		},
+	}, newRepository())...)
+}
+
 func loadSQLDataStore(log logrus.FieldLogger, datastoreConfig map[string]catalog.HCLPluginConfig) (datastore.DataStore, error) {
+	sqlConfig, err := sqlDataStoreConfig(datastoreConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := ds_sql.New(log.WithField(telemetry.SubsystemName, sqlConfig.Name))
+	if err := ds.Configure(sqlConfig.Data); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// DataStoreConfigData returns the plugin data from the configuration of the
+// built-in SQL DataStore plugin.
+func DataStoreConfigData(pluginConfig HCLPluginConfigMap) (string, error) {
+	sqlConfig, err := sqlDataStoreConfig(pluginConfig[dataStoreType])
+	if err != nil {
+		return "", err
+	}
+	return sqlConfig.Data, nil
+}
+
+// KnownSelectorTypes returns the selector types the server expects agents to
+// have, i.e. the names of the enabled NodeAttestor and NodeResolver plugins,
+// since plugins name their selectors after themselves.
+func KnownSelectorTypes(pluginConfig HCLPluginConfigMap) []string {
+	var selectorTypes []string
+	for _, pluginType := range []string{nodeAttestorType, nodeResolverType} {
+		for name, config := range pluginConfig[pluginType] {
+			if config.IsEnabled() {
+				selectorTypes = append(selectorTypes, name)
+			}
+		}
+	}
+	sort.Strings(selectorTypes)
+	return selectorTypes
+}
+
+func sqlDataStoreConfig(datastoreConfig map[string]catalog.HCLPluginConfig) (catalog.PluginConfig, error) {
 	switch {
 	case len(datastoreConfig) == 0:
-		return nil, errors.New("expecting a DataStore plugin")
+		return catalog.PluginConfig{}, errors.New("expecting a DataStore plugin")
 	case len(datastoreConfig) > 1:
-		return nil, errors.New("only one DataStore plugin is allowed")
+		return catalog.PluginConfig{}, errors.New("only one DataStore plugin is allowed")
 	}
 
 	sqlHCLConfig, ok := datastoreConfig[ds_sql.PluginName]
 	if !ok {
-		return nil, fmt.Errorf("pluggability for the DataStore is deprecated; only the built-in %q plugin is supported", ds_sql.PluginName)
+		return catalog.PluginConfig{}, fmt.Errorf("pluggability for the DataStore is deprecated; only the built-in %q plugin is supported", ds_sql.PluginName)
 	}
 
 	sqlConfig, err := catalog.PluginConfigFromHCL(dataStoreType, ds_sql.PluginName, sqlHCLConfig)
 	if err != nil {
-		return nil, err
+		return catalog.PluginConfig{}, err
 	}
 
 	// Is the plugin external?
 	if sqlConfig.Path != "" {
-		return nil, fmt.Errorf("pluggability for the DataStore is deprecated; only the built-in %q plugin is supported", ds_sql.PluginName)
-	}
-
-	ds := ds_sql.New(log.WithField(telemetry.SubsystemName, sqlConfig.Name))
-	if err := ds.Configure(sqlConfig.Data); err != nil {
-		return nil, err
+		return catalog.PluginConfig{}, fmt.Errorf("pluggability for the DataStore is deprecated; only the built-in %q plugin is supported", ds_sql.PluginName)
 	}
-	return ds, nil
+	return sqlConfig, nil
 }
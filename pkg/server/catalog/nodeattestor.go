@@ -15,6 +15,16 @@ import (
 
 type nodeAttestorRepository struct {
 	nodeattestor.Repository
+
+	// awsIID is the built-in aws_iid plugin instance, retained so that it
+	// can be registered for health checking.
+	awsIID *aws.IIDAttestorPlugin
+}
+
+func newNodeAttestorRepository() nodeAttestorRepository {
+	return nodeAttestorRepository{
+		awsIID: aws.New(),
+	}
 }
 
 func (repo *nodeAttestorRepository) Binder() interface{} {
@@ -35,7 +45,7 @@ func (repo *nodeAttestorRepository) LegacyVersion() (catalog.Version, bool) {
 
 func (repo *nodeAttestorRepository) BuiltIns() []catalog.BuiltIn {
 	return []catalog.BuiltIn{
-		aws.BuiltIn(),
+		aws.BuiltInWithPlugin(repo.awsIID),
 		azure.BuiltIn(),
 		gcp.BuiltIn(),
 		jointoken.BuiltIn(),
@@ -4,6 +4,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/server/plugin/noderesolver"
 	"github.com/spiffe/spire/pkg/server/plugin/noderesolver/azure"
+	"github.com/spiffe/spire/pkg/server/plugin/noderesolver/ldap"
 )
 
 type nodeResolverRepository struct {
@@ -29,6 +30,7 @@ func (repo *nodeResolverRepository) LegacyVersion() (catalog.Version, bool) {
 func (repo *nodeResolverRepository) BuiltIns() []catalog.BuiltIn {
 	return []catalog.BuiltIn{
 		azure.BuiltIn(),
+		ldap.BuiltIn(),
 	}
 }
 
@@ -16,11 +16,11 @@ type upstreamAuthorityRepository struct {
 }
 
 func (repo *upstreamAuthorityRepository) Binder() interface{} {
-	return repo.SetUpstreamAuthority
+	return repo.AddUpstreamAuthority
 }
 
 func (repo *upstreamAuthorityRepository) Constraints() catalog.Constraints {
-	return catalog.MaybeOne()
+	return catalog.ZeroOrMore()
 }
 
 func (repo *upstreamAuthorityRepository) Versions() []catalog.Version {
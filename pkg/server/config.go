@@ -10,7 +10,12 @@ import (
 	common "github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/health"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/tracing"
+	"github.com/spiffe/spire/pkg/common/x509util"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/issuancehook"
 	bundle_client "github.com/spiffe/spire/pkg/server/bundle/client"
+	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/endpoints"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
@@ -22,6 +27,10 @@ type Config struct {
 
 	Log logrus.FieldLogger
 
+	// AuditLog, if set, receives the audit records of the calls to the
+	// mutating registration and admin API methods
+	AuditLog logrus.FieldLogger
+
 	// Address of SPIRE server
 	BindAddress *net.TCPAddr
 
@@ -51,6 +60,10 @@ type Config struct {
 	// SVIDTTL is default time-to-live for SVIDs
 	SVIDTTL time.Duration
 
+	// JWTSVIDTTL is the default time-to-live for JWT-SVIDs. Defaults to
+	// ca.DefaultJWTSVIDTTL.
+	JWTSVIDTTL time.Duration
+
 	// CATTL is the time-to-live for the server CA. This only applies to
 	// self-signed CA certificates, otherwise it is up to the upstream CA.
 	CATTL time.Duration
@@ -74,6 +87,31 @@ type Config struct {
 	// JWTKeyType is the key type used for JWT signing keys
 	JWTKeyType keymanager.KeyType
 
+	// FIPSMode, if set, restricts the CA signing keys and the keys of the
+	// SVIDs the server signs to FIPS-approved algorithms
+	FIPSMode bool
+
+	// JWTKeyIDThumbprint, if set, uses the RFC 7638 thumbprint of JWT
+	// signing keys as their key ID
+	JWTKeyIDThumbprint bool
+
+	// JWTKeyNoticePeriod is how long new JWT signing keys are published in
+	// the bundle before they sign JWT-SVIDs
+	JWTKeyNoticePeriod time.Duration
+
+	// JWTKeyTrustDomains are additional trust domains the server mints
+	// JWT-SVIDs for, each signed with its own JWT signing key
+	JWTKeyTrustDomains []spiffeid.TrustDomain
+
+	// UpstreamAuthorityOrder is the order, by plugin name, in which the CA
+	// manager fails over between the configured UpstreamAuthority plugins
+	UpstreamAuthorityOrder []string
+
+	// UpstreamAuthorityValidation determines whether the CA manager has the
+	// upstream authority sign a test CSR on startup, and whether a failure
+	// fails the startup or is only logged
+	UpstreamAuthorityValidation ca.UpstreamAuthorityValidation
+
 	// Federation holds the configuration needed to federate with other
 	// trust domains.
 	Federation FederationConfig
@@ -81,8 +119,90 @@ type Config struct {
 	// RateLimit holds rate limiting configurations.
 	RateLimit endpoints.RateLimitConfig
 
+	// TLSPolicy holds the minimum TLS version and cipher suites accepted by
+	// the TCP listeners.
+	TLSPolicy endpoints.TLSPolicy
+
+	// GRPCMessageSizeLimits holds the maximum size of the messages received
+	// and sent by the gRPC servers.
+	GRPCMessageSizeLimits endpoints.GRPCMessageSizeLimits
+
+	// MinNodeSelectors is the minimum number of selectors an agent must have
+	// after attestation and resolution. Below it, no selectors are attached
+	// unless RejectBelowMinNodeSelectors is set, in which case attestation
+	// fails.
+	MinNodeSelectors            int
+	RejectBelowMinNodeSelectors bool
+
+	// MaxNodeSelectors is the maximum number of selectors stored for an
+	// agent. Agents with more selectors have them sorted and truncated.
+	MaxNodeSelectors int
+
+	// MaxAttestationPayloadSize is the maximum size in bytes of the
+	// attestation payloads and challenge responses sent by agents. Zero
+	// disables the check.
+	MaxAttestationPayloadSize int
+
+	// MaxConcurrentAttestations is the maximum number of attestations in
+	// progress at a time, keyed by node attestor type. Types without an
+	// entry are not limited.
+	MaxConcurrentAttestations map[string]int
+
+	// NodeSelectorMergePolicy determines whether the attested or the
+	// resolved selectors of an agent win when both produce selectors for the
+	// same key, or whether both are kept
+	NodeSelectorMergePolicy api.NodeSelectorMergePolicy
+
+	// SPIFFEIDCollisionPolicy determines how entries with the same parent ID
+	// and selectors as an existing entry, but a different SPIFFE ID, are
+	// handled on create and update
+	SPIFFEIDCollisionPolicy api.SPIFFEIDCollisionPolicy
+
+	// DuplicateSelectorPolicy determines whether duplicate selectors within
+	// a registration entry are collapsed or rejected on create and update
+	DuplicateSelectorPolicy api.DuplicateSelectorPolicy
+
+	// DeletedEntryGracePeriod, if greater than zero, is how long deleted
+	// registration entries are kept, so they can be restored, before they
+	// are purged. Zero deletes entries right away.
+	DeletedEntryGracePeriod time.Duration
+
+	// UnknownSelectorTypePolicy determines whether agents with selectors of
+	// a type that no configured NodeAttestor or NodeResolver plugin produces
+	// are ignored, warned about or kept from matching node-aliased entries
+	UnknownSelectorTypePolicy api.UnknownSelectorTypePolicy
+
+	// SVIDIssuanceHook, if set, configures the webhook consulted before
+	// SVIDs are issued. Nil issues SVIDs without consulting a webhook.
+	SVIDIssuanceHook *issuancehook.Config
+
+	// AdminScopes restricts the admin workloads with the given SPIFFE IDs to
+	// the server API methods allowed to their scopes. Other admin workloads
+	// can call every admin method.
+	AdminScopes api.AdminScopes
+
+	// EntryPruneInterval is how often expired registration entries are
+	// deleted. Expired entries stop matching right away, regardless.
+	EntryPruneInterval time.Duration
+
+	// NotifierTimeout, if greater than zero, bounds how long each notifier
+	// has to handle an event.
+	NotifierTimeout time.Duration
+
 	// CacheReloadInterval controls how often the in-memory entry cache reloads
 	CacheReloadInterval time.Duration
+
+	// SecondaryTrustDomain, if set, is used to add an additional URI SAN
+	// to minted workload X509-SVIDs while migrating to a new trust domain.
+	SecondaryTrustDomain spiffeid.TrustDomain
+
+	// SerialNumberGenerator generates the serial numbers of the X509-SVIDs
+	// minted by the server CA.
+	SerialNumberGenerator x509util.SerialNumberGenerator
+
+	// Tracing, if set, enables exporting OpenTelemetry spans for RPCs and
+	// the internal operations they perform.
+	Tracing *tracing.Config
 }
 
 type ExperimentalConfig struct {
@@ -0,0 +1,157 @@
+package endpoints
+
+import (
+	"github.com/sirupsen/logrus"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	registration_pb "github.com/spiffe/spire/proto/spire/api/registration"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	auditCallerLocal = "caller_local"
+	auditCallerAddr  = "caller_addr"
+	auditStatusCode  = "status_code"
+	auditStatusMsg   = "status_message"
+	auditRequest     = "request"
+	auditResponse    = "response"
+)
+
+// auditedMethods are the methods of the registration and admin APIs that
+// mutate the server state. Each call to them produces an audit record.
+var auditedMethods = map[string]bool{
+	"/spire.api.server.entry.v1.Entry/BatchCreateEntry":             true,
+	"/spire.api.server.entry.v1.Entry/BatchUpdateEntry":             true,
+	"/spire.api.server.entry.v1.Entry/BatchDeleteEntry":             true,
+	"/spire.api.server.agent.v1.Agent/DeleteAgent":                  true,
+	"/spire.api.server.agent.v1.Agent/BanAgent":                     true,
+	"/spire.api.server.agent.v1.Agent/CreateJoinToken":              true,
+	"/spire.api.server.bundle.v1.Bundle/AppendBundle":               true,
+	"/spire.api.server.bundle.v1.Bundle/BatchCreateFederatedBundle": true,
+	"/spire.api.server.bundle.v1.Bundle/BatchUpdateFederatedBundle": true,
+	"/spire.api.server.bundle.v1.Bundle/BatchSetFederatedBundle":    true,
+	"/spire.api.server.bundle.v1.Bundle/BatchDeleteFederatedBundle": true,
+
+	"/spire.private.server.deletedentry.DeletedEntry/RestoreEntry": true,
+
+	"/spire.api.registration.Registration/CreateEntry":            true,
+	"/spire.api.registration.Registration/CreateEntryIfNotExists": true,
+	"/spire.api.registration.Registration/UpdateEntry":            true,
+	"/spire.api.registration.Registration/DeleteEntry":            true,
+	"/spire.api.registration.Registration/CreateFederatedBundle":  true,
+	"/spire.api.registration.Registration/UpdateFederatedBundle":  true,
+	"/spire.api.registration.Registration/DeleteFederatedBundle":  true,
+	"/spire.api.registration.Registration/CreateJoinToken":        true,
+	"/spire.api.registration.Registration/EvictAgent":             true,
+}
+
+// wrapWithAuditLogging wraps the unary interceptor so the calls to the
+// audited methods are recorded in the audit log once they complete, whether
+// they succeed, fail or are denied. The records include the caller, as seen
+// on the connection, and the request and response messages, which carry the
+// changes made (e.g. the entries created, the fields updated and the IDs
+// deleted) and their per-item results. Join token values are redacted.
+func wrapWithAuditLogging(auditLog logrus.FieldLogger, unary grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	if auditLog == nil {
+		return unary
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !auditedMethods[info.FullMethod] {
+			return unary(ctx, req, info, handler)
+		}
+		resp, err := unary(ctx, req, info, handler)
+		emitAuditRecord(ctx, auditLog, info.FullMethod, req, resp, err)
+		return resp, err
+	}
+}
+
+func emitAuditRecord(ctx context.Context, auditLog logrus.FieldLogger, fullMethod string, req, resp interface{}, rpcErr error) {
+	fields := auditCallerFields(ctx)
+	fields[telemetry.Type] = "audit"
+	fields[telemetry.Method] = fullMethod
+
+	st := status.Convert(rpcErr)
+	fields[auditStatusCode] = st.Code().String()
+	if st.Message() != "" {
+		fields[auditStatusMsg] = st.Message()
+	}
+
+	if msg, ok := req.(proto.Message); ok {
+		fields[auditRequest] = marshalAuditMessage(msg)
+	}
+	if msg, ok := resp.(proto.Message); ok && rpcErr == nil {
+		fields[auditResponse] = marshalAuditMessage(msg)
+	}
+
+	auditLog.WithFields(fields).Info("API call")
+}
+
+// auditCallerFields returns the fields identifying the caller from the peer
+// of the connection: the SPIFFE ID in the client certificate of mTLS callers,
+// or whether the caller is local, i.e. connects through the UDS.
+func auditCallerFields(ctx context.Context) logrus.Fields {
+	fields := make(logrus.Fields)
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		fields[telemetry.CallerID] = telemetry.Unknown
+		return fields
+	}
+
+	switch p.Addr.Network() {
+	case "unix", "unixgram", "unixpacket":
+		fields[auditCallerLocal] = true
+		return fields
+	}
+
+	fields[auditCallerAddr] = p.Addr.String()
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+		if uris := tlsInfo.State.PeerCertificates[0].URIs; len(uris) > 0 {
+			fields[telemetry.CallerID] = uris[0].String()
+		}
+	}
+	return fields
+}
+
+// marshalAuditMessage returns the JSON representation of the message, with
+// secrets redacted.
+func marshalAuditMessage(msg proto.Message) string {
+	msg = redactAuditMessage(msg)
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		// The record is still useful without the message
+		return "<unable to marshal: " + err.Error() + ">"
+	}
+	return string(data)
+}
+
+func redactAuditMessage(msg proto.Message) proto.Message {
+	const redacted = "<redacted>"
+
+	switch m := msg.(type) {
+	case *agentv1.CreateJoinTokenRequest:
+		if m.Token != "" {
+			m = proto.Clone(m).(*agentv1.CreateJoinTokenRequest)
+			m.Token = redacted
+		}
+		return m
+	case *types.JoinToken:
+		m = proto.Clone(m).(*types.JoinToken)
+		m.Value = redacted
+		return m
+	case *registration_pb.JoinToken:
+		if m.Token != "" {
+			m = proto.Clone(m).(*registration_pb.JoinToken)
+			m.Token = redacted
+		}
+		return m
+	}
+	return msg
+}
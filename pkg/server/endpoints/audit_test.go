@@ -0,0 +1,226 @@
+package endpoints
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuditLogging(t *testing.T) {
+	adminCtx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("1.2.3.4"), Port: 5678},
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{
+					{URIs: []*url.URL{{Scheme: "spiffe", Host: "example.org", Path: "/admin"}}},
+				},
+			},
+		},
+	})
+	localCtx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.UnixAddr{Net: "unix", Name: "/tmp/spire-server/private/api.sock"},
+	})
+	adminFields := logrus.Fields{
+		"caller_id":   "spiffe://example.org/admin",
+		"caller_addr": "1.2.3.4:5678",
+	}
+
+	for _, tt := range []struct {
+		name       string
+		ctx        context.Context
+		method     string
+		req        interface{}
+		resp       interface{}
+		err        error
+		expectLogs []logrus.Fields
+	}{
+		{
+			name:   "create entry",
+			ctx:    adminCtx,
+			method: "/spire.api.server.entry.v1.Entry/BatchCreateEntry",
+			req: &entryv1.BatchCreateEntryRequest{
+				Entries: []*types.Entry{{SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"}}},
+			},
+			resp: &entryv1.BatchCreateEntryResponse{
+				Results: []*entryv1.BatchCreateEntryResponse_Result{{Entry: &types.Entry{Id: "entry-1"}}},
+			},
+			expectLogs: []logrus.Fields{
+				auditLogEntry(adminFields, logrus.Fields{
+					"method":      "/spire.api.server.entry.v1.Entry/BatchCreateEntry",
+					"status_code": "OK",
+					"request":     `{"entries":[{"spiffeId":{"trustDomain":"example.org","path":"/workload"}}]}`,
+					"response":    `{"results":[{"entry":{"id":"entry-1"}}]}`,
+				}),
+			},
+		},
+		{
+			name:   "update entry",
+			ctx:    adminCtx,
+			method: "/spire.api.server.entry.v1.Entry/BatchUpdateEntry",
+			req: &entryv1.BatchUpdateEntryRequest{
+				Entries:   []*types.Entry{{Id: "entry-1", Ttl: 60}},
+				InputMask: &types.EntryMask{Ttl: true},
+			},
+			resp: &entryv1.BatchUpdateEntryResponse{
+				Results: []*entryv1.BatchUpdateEntryResponse_Result{{Entry: &types.Entry{Id: "entry-1", Ttl: 60}}},
+			},
+			expectLogs: []logrus.Fields{
+				auditLogEntry(adminFields, logrus.Fields{
+					"method":      "/spire.api.server.entry.v1.Entry/BatchUpdateEntry",
+					"status_code": "OK",
+					"request":     `{"entries":[{"id":"entry-1","ttl":60}],"inputMask":{"ttl":true}}`,
+					"response":    `{"results":[{"entry":{"id":"entry-1","ttl":60}}]}`,
+				}),
+			},
+		},
+		{
+			name:   "delete entry from a local caller",
+			ctx:    localCtx,
+			method: "/spire.api.server.entry.v1.Entry/BatchDeleteEntry",
+			req:    &entryv1.BatchDeleteEntryRequest{Ids: []string{"entry-1"}},
+			resp: &entryv1.BatchDeleteEntryResponse{
+				Results: []*entryv1.BatchDeleteEntryResponse_Result{{Id: "entry-1"}},
+			},
+			expectLogs: []logrus.Fields{
+				auditLogEntry(logrus.Fields{"caller_local": true}, logrus.Fields{
+					"method":      "/spire.api.server.entry.v1.Entry/BatchDeleteEntry",
+					"status_code": "OK",
+					"request":     `{"ids":["entry-1"]}`,
+					"response":    `{"results":[{"id":"entry-1"}]}`,
+				}),
+			},
+		},
+		{
+			name:   "denied call",
+			ctx:    adminCtx,
+			method: "/spire.api.server.entry.v1.Entry/BatchDeleteEntry",
+			req:    &entryv1.BatchDeleteEntryRequest{Ids: []string{"entry-1"}},
+			err:    status.Error(codes.PermissionDenied, "authorization denied"),
+			expectLogs: []logrus.Fields{
+				auditLogEntry(adminFields, logrus.Fields{
+					"method":         "/spire.api.server.entry.v1.Entry/BatchDeleteEntry",
+					"status_code":    "PermissionDenied",
+					"status_message": "authorization denied",
+					"request":        `{"ids":["entry-1"]}`,
+				}),
+			},
+		},
+		{
+			name:   "join token is redacted",
+			ctx:    localCtx,
+			method: "/spire.api.server.agent.v1.Agent/CreateJoinToken",
+			req:    &agentv1.CreateJoinTokenRequest{Ttl: 60, Token: "secret"},
+			resp:   &types.JoinToken{Value: "secret", ExpiresAt: 1234},
+			expectLogs: []logrus.Fields{
+				auditLogEntry(logrus.Fields{"caller_local": true}, logrus.Fields{
+					"method":      "/spire.api.server.agent.v1.Agent/CreateJoinToken",
+					"status_code": "OK",
+					"request":     `{"ttl":60,"token":"<redacted>"}`,
+					"response":    `{"value":"<redacted>","expiresAt":"1234"}`,
+				}),
+			},
+		},
+		{
+			name:   "read only method is not audited",
+			ctx:    adminCtx,
+			method: "/spire.api.server.entry.v1.Entry/ListEntries",
+			req:    &entryv1.ListEntriesRequest{OutputMask: &types.EntryMask{}},
+			resp:   &entryv1.ListEntriesResponse{},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			log, hook := test.NewNullLogger()
+			unary := wrapWithAuditLogging(log, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				return handler(ctx, req)
+			})
+
+			resp, err := unary(tt.ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return tt.resp, nil
+			})
+			require.Equal(t, tt.err, err)
+			if tt.err == nil {
+				require.Equal(t, tt.resp, resp)
+			}
+			requireAuditLogs(t, hook.AllEntries(), tt.expectLogs)
+
+			// The messages passed along are left untouched
+			if req, ok := tt.req.(*agentv1.CreateJoinTokenRequest); ok {
+				require.Equal(t, "secret", req.Token)
+			}
+		})
+	}
+}
+
+func TestAuditLoggingDisabled(t *testing.T) {
+	var called bool
+	unary := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		called = true
+		return nil, nil
+	}
+
+	wrapped := wrapWithAuditLogging(nil, unary)
+	_, err := wrapped(context.Background(), &entryv1.BatchDeleteEntryRequest{}, &grpc.UnaryServerInfo{FullMethod: "/spire.api.server.entry.v1.Entry/BatchDeleteEntry"}, nil)
+	require.NoError(t, err)
+	require.True(t, called)
+}
+
+func auditLogEntry(callerFields, fields logrus.Fields) logrus.Fields {
+	data := logrus.Fields{"type": "audit"}
+	for k, v := range callerFields {
+		data[k] = v
+	}
+	for k, v := range fields {
+		data[k] = v
+	}
+	return data
+}
+
+// requireAuditLogs asserts the audit records have the expected fields. The
+// messages are compared as JSON since their formatting is not stable.
+func requireAuditLogs(t *testing.T, entries []*logrus.Entry, expected []logrus.Fields) {
+	require.Len(t, entries, len(expected))
+	for i, entry := range entries {
+		require.Equal(t, logrus.InfoLevel, entry.Level)
+		require.Equal(t, "API call", entry.Message)
+
+		actual := make(logrus.Fields)
+		for k, v := range entry.Data {
+			actual[k] = v
+		}
+		for _, key := range []string{"request", "response"} {
+			if expectedJSON, ok := expected[i][key]; ok {
+				require.JSONEq(t, expectedJSON.(string), actual[key].(string), key)
+			} else {
+				require.NotContains(t, actual, key)
+			}
+			delete(actual, key)
+		}
+
+		expectedFields := make(logrus.Fields)
+		for k, v := range expected[i] {
+			if k != "request" && k != "response" {
+				expectedFields[k] = v
+			}
+		}
+		require.Equal(t, expectedFields, actual)
+	}
+}
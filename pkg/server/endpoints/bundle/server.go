@@ -32,6 +32,13 @@ type ServerConfig struct {
 	Getter     Getter
 	ServerAuth ServerAuth
 
+	// TLSMinVersion is the minimum TLS version accepted. Defaults to TLS 1.2.
+	TLSMinVersion uint16
+
+	// TLSCipherSuites, if set, restricts the cipher suites accepted on
+	// connections up to TLS 1.2.
+	TLSCipherSuites []uint16
+
 	// test hooks
 	listen func(network, address string) (net.Listener, error)
 }
@@ -57,9 +64,16 @@ func (s *Server) ListenAndServe(ctx context.Context) error {
 		return errs.Wrap(err)
 	}
 
-	// Set up the TLS config, setting TLS 1.2 as the minimum.
+	// Set up the TLS config, setting TLS 1.2 as the minimum unless
+	// configured otherwise.
 	tlsConfig := s.c.ServerAuth.GetTLSConfig()
 	tlsConfig.MinVersion = tls.VersionTLS12
+	if s.c.TLSMinVersion != 0 {
+		tlsConfig.MinVersion = s.c.TLSMinVersion
+	}
+	if len(s.c.TLSCipherSuites) > 0 {
+		tlsConfig.CipherSuites = s.c.TLSCipherSuites
+	}
 
 	server := &http.Server{
 		Handler:   http.HandlerFunc(s.serveHTTP),
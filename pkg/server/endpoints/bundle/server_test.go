@@ -153,6 +153,58 @@ func TestServer(t *testing.T) {
 	}
 }
 
+func TestServerTLSPolicy(t *testing.T) {
+	serverCert, serverKey := createServerCertificate(t)
+	trustDomain := spiffeid.RequireTrustDomainFromString("domain.test")
+	bundle := bundleutil.New(trustDomain)
+	bundle.AppendRootCA(serverCert)
+
+	addr, done := newTestServer(t,
+		testGetter(bundle),
+		testSPIFFEAuth(serverCert, serverKey),
+		func(c *ServerConfig) {
+			c.TLSCipherSuites = []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}
+		},
+	)
+	defer done()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(serverCert)
+	get := func(minVersion, maxVersion uint16, cipherSuites ...uint16) error {
+		client := http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:      rootCAs,
+					MinVersion:   minVersion,
+					MaxVersion:   maxVersion,
+					CipherSuites: cipherSuites,
+				},
+			},
+		}
+		resp, err := client.Get(fmt.Sprintf("https://%s/", addr))
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	t.Run("allowed cipher suite", func(t *testing.T) {
+		require.NoError(t, get(tls.VersionTLS12, tls.VersionTLS12, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256))
+	})
+
+	t.Run("disallowed cipher suite", func(t *testing.T) {
+		err := get(tls.VersionTLS12, tls.VersionTLS12, tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "handshake failure")
+	})
+
+	t.Run("version below minimum", func(t *testing.T) {
+		err := get(tls.VersionTLS11, tls.VersionTLS11, tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "protocol version")
+	})
+}
+
 func TestACMEAuth(t *testing.T) {
 	dir := spiretest.TempDir(t)
 
@@ -276,7 +328,7 @@ func TestACMEAuth(t *testing.T) {
 	})
 }
 
-func newTestServer(t *testing.T, getter Getter, serverAuth ServerAuth) (net.Addr, func()) {
+func newTestServer(t *testing.T, getter Getter, serverAuth ServerAuth, configure ...func(*ServerConfig)) (net.Addr, func()) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	addrCh := make(chan net.Addr, 1)
@@ -290,13 +342,17 @@ func newTestServer(t *testing.T, getter Getter, serverAuth ServerAuth) (net.Addr
 	}
 
 	log, _ := test.NewNullLogger()
-	server := NewServer(ServerConfig{
+	config := ServerConfig{
 		Log:        log,
 		Address:    "localhost:0",
 		Getter:     getter,
 		ServerAuth: serverAuth,
 		listen:     listen,
-	})
+	}
+	for _, fn := range configure {
+		fn(&config)
+	}
+	server := NewServer(config)
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -15,9 +15,13 @@ import (
 	"github.com/spiffe/spire/pkg/server/api"
 	agentv1 "github.com/spiffe/spire/pkg/server/api/agent/v1"
 	bundlev1 "github.com/spiffe/spire/pkg/server/api/bundle/v1"
+	castatusv1 "github.com/spiffe/spire/pkg/server/api/castatus/v1"
 	debugv1 "github.com/spiffe/spire/pkg/server/api/debug/v1"
+	deletedentryv1 "github.com/spiffe/spire/pkg/server/api/deletedentry/v1"
 	entryv1 "github.com/spiffe/spire/pkg/server/api/entry/v1"
+	entryfederationv1 "github.com/spiffe/spire/pkg/server/api/entryfederation/v1"
 	healthv1 "github.com/spiffe/spire/pkg/server/api/health/v1"
+	"github.com/spiffe/spire/pkg/server/api/issuancehook"
 	svidv1 "github.com/spiffe/spire/pkg/server/api/svid/v1"
 	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/cache/dscache"
@@ -57,15 +61,79 @@ type Config struct {
 	Log     logrus.FieldLogger
 	Metrics telemetry.Metrics
 
+	// AuditLog, if set, receives the audit records of the calls to the
+	// mutating registration and admin API methods.
+	AuditLog logrus.FieldLogger
+
 	// RateLimit holds rate limiting configurations.
 	RateLimit RateLimitConfig
 
+	// TLSPolicy holds the TLS settings of the TCP listeners, i.e. the gRPC
+	// server and the federation bundle endpoint.
+	TLSPolicy TLSPolicy
+
+	// GRPCMessageSizeLimits holds the message size limits of the gRPC
+	// servers, which serve the registration, SVID and other APIs
+	GRPCMessageSizeLimits GRPCMessageSizeLimits
+
 	Uptime func() time.Duration
 
 	Clock clock.Clock
 
 	// CacheReloadInterval controls how often the in-memory entry cache reloads
 	CacheReloadInterval time.Duration
+
+	// JWTKeyTrustDomains are additional trust domains JWT-SVIDs can be
+	// minted for
+	JWTKeyTrustDomains []spiffeid.TrustDomain
+
+	// MinNodeSelectors and RejectBelowMinNodeSelectors control how agents
+	// resolving to too few selectors are handled during attestation
+	MinNodeSelectors            int
+	RejectBelowMinNodeSelectors bool
+
+	// MaxNodeSelectors caps the number of selectors stored for an agent
+	MaxNodeSelectors int
+
+	// MaxAttestationPayloadSize bounds the size of the attestation payloads
+	// and challenge responses sent by agents
+	MaxAttestationPayloadSize int
+
+	// MaxConcurrentAttestations limits the attestations in progress at a
+	// time, per node attestor type
+	MaxConcurrentAttestations map[string]int
+
+	// NodeSelectorMergePolicy determines how conflicting attested and
+	// resolved selectors are combined during attestation
+	NodeSelectorMergePolicy api.NodeSelectorMergePolicy
+
+	// SPIFFEIDCollisionPolicy determines how entries with the same parent ID
+	// and selectors as an existing entry, but a different SPIFFE ID, are
+	// handled on create and update
+	SPIFFEIDCollisionPolicy api.SPIFFEIDCollisionPolicy
+
+	// DuplicateSelectorPolicy determines how entries with the same selector
+	// more than once are handled on create and update
+	DuplicateSelectorPolicy api.DuplicateSelectorPolicy
+
+	// DeletedEntryGracePeriod, if greater than zero, is how long deleted
+	// entries are kept, so they can be restored, before they are purged
+	DeletedEntryGracePeriod time.Duration
+
+	// KnownSelectorTypes are the selector types agents are expected to have
+	KnownSelectorTypes []string
+
+	// UnknownSelectorTypePolicy determines how agents with selectors of a
+	// type not in KnownSelectorTypes are handled when entries are matched
+	UnknownSelectorTypePolicy api.UnknownSelectorTypePolicy
+
+	// SVIDIssuanceHook, if set, configures the webhook consulted before
+	// SVIDs are issued
+	SVIDIssuanceHook *issuancehook.Config
+
+	// AdminScopes restricts the admin workloads with the given SPIFFE IDs
+	// to the methods allowed to their scopes
+	AdminScopes api.AdminScopes
 }
 
 func (c *Config) makeOldAPIServers() OldAPIServers {
@@ -75,6 +143,11 @@ func (c *Config) makeOldAPIServers() OldAPIServers {
 		Catalog:     c.Catalog,
 		TrustDomain: c.TrustDomain,
 		ServerCA:    c.ServerCA,
+
+		SPIFFEIDCollisionPolicy: c.SPIFFEIDCollisionPolicy,
+		DuplicateSelectorPolicy: c.DuplicateSelectorPolicy,
+		DeletedEntryGracePeriod: c.DeletedEntryGracePeriod,
+		AdminScopes:             c.AdminScopes,
 	}
 
 	return OldAPIServers{
@@ -113,6 +186,9 @@ func (c *Config) maybeMakeBundleEndpointServer() Server {
 			return bundleutil.BundleFromProto(commonBundle)
 		}),
 		ServerAuth: serverAuth,
+
+		TLSMinVersion:   c.TLSPolicy.MinVersion,
+		TLSCipherSuites: c.TLSPolicy.CipherSuites,
 	})
 }
 
@@ -120,6 +196,11 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 	ds := c.Catalog.GetDataStore()
 	upstreamPublisher := UpstreamPublisher(c.Manager)
 
+	var issuanceHook *issuancehook.Hook
+	if c.SVIDIssuanceHook != nil {
+		issuanceHook = issuancehook.New(*c.SVIDIssuanceHook)
+	}
+
 	return APIServers{
 		AgentServer: agentv1.New(agentv1.Config{
 			DataStore:   ds,
@@ -127,12 +208,22 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 			TrustDomain: c.TrustDomain,
 			Catalog:     c.Catalog,
 			Clock:       c.Clock,
+
+			MinNodeSelectors:            c.MinNodeSelectors,
+			RejectBelowMinNodeSelectors: c.RejectBelowMinNodeSelectors,
+			MaxNodeSelectors:            c.MaxNodeSelectors,
+			MaxAttestationPayloadSize:   c.MaxAttestationPayloadSize,
+			MaxConcurrentAttestations:   c.MaxConcurrentAttestations,
+			NodeSelectorMergePolicy:     c.NodeSelectorMergePolicy,
 		}),
 		BundleServer: bundlev1.New(bundlev1.Config{
 			TrustDomain:       c.TrustDomain,
 			DataStore:         ds,
 			UpstreamPublisher: upstreamPublisher,
 		}),
+		CAStatusServer: castatusv1.New(castatusv1.Config{
+			Manager: c.Manager,
+		}),
 		DebugServer: debugv1.New(debugv1.Config{
 			TrustDomain:  c.TrustDomain,
 			Clock:        c.Clock,
@@ -140,10 +231,22 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 			SVIDObserver: c.SVIDObserver,
 			Uptime:       c.Uptime,
 		}),
+		DeletedEntryServer: deletedentryv1.New(deletedentryv1.Config{
+			DataStore:   ds,
+			GracePeriod: c.DeletedEntryGracePeriod,
+		}),
 		EntryServer: entryv1.New(entryv1.Config{
 			TrustDomain:  c.TrustDomain,
 			DataStore:    ds,
 			EntryFetcher: entryFetcher,
+
+			SPIFFEIDCollisionPolicy: c.SPIFFEIDCollisionPolicy,
+			DuplicateSelectorPolicy: c.DuplicateSelectorPolicy,
+			DeletedEntryGracePeriod: c.DeletedEntryGracePeriod,
+		}),
+		EntryFederationServer: entryfederationv1.New(entryfederationv1.Config{
+			TrustDomain: c.TrustDomain,
+			DataStore:   ds,
 		}),
 		HealthServer: healthv1.New(healthv1.Config{
 			TrustDomain: c.TrustDomain,
@@ -154,6 +257,10 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 			EntryFetcher: entryFetcher,
 			ServerCA:     c.ServerCA,
 			DataStore:    ds,
+			Metrics:      c.Metrics,
+
+			JWTKeyTrustDomains: c.JWTKeyTrustDomains,
+			IssuanceHook:       issuanceHook,
 		}),
 	}
 }
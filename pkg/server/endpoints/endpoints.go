@@ -28,10 +28,14 @@ import (
 	"github.com/spiffe/spire/pkg/common/auth"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/plugin/datastore"
 	"github.com/spiffe/spire/pkg/server/svid"
+	castatus_pb "github.com/spiffe/spire/proto/private/server/castatus"
+	deletedentry_pb "github.com/spiffe/spire/proto/private/server/deletedentry"
+	entryfederation_pb "github.com/spiffe/spire/proto/private/server/entryfederation"
 	registration_pb "github.com/spiffe/spire/proto/spire/api/registration"
 )
 
@@ -66,8 +70,12 @@ type Endpoints struct {
 	APIServers                   APIServers
 	BundleEndpointServer         Server
 	Log                          logrus.FieldLogger
+	AuditLog                     logrus.FieldLogger
 	Metrics                      telemetry.Metrics
 	RateLimit                    RateLimitConfig
+	TLSPolicy                    TLSPolicy
+	GRPCMessageSizeLimits        GRPCMessageSizeLimits
+	AdminScopes                  api.AdminScopes
 	EntryFetcherCacheRebuildTask func(context.Context) error
 }
 
@@ -76,12 +84,15 @@ type OldAPIServers struct {
 }
 
 type APIServers struct {
-	AgentServer  agentv1.AgentServer
-	BundleServer bundlev1.BundleServer
-	DebugServer  debugv1_pb.DebugServer
-	EntryServer  entryv1.EntryServer
-	HealthServer grpc_health_v1.HealthServer
-	SVIDServer   svidv1.SVIDServer
+	AgentServer           agentv1.AgentServer
+	BundleServer          bundlev1.BundleServer
+	CAStatusServer        castatus_pb.CAStatusServer
+	DebugServer           debugv1_pb.DebugServer
+	DeletedEntryServer    deletedentry_pb.DeletedEntryServer
+	EntryServer           entryv1.EntryServer
+	EntryFederationServer entryfederation_pb.EntryFederationServer
+	HealthServer          grpc_health_v1.HealthServer
+	SVIDServer            svidv1.SVIDServer
 }
 
 // RateLimitConfig holds rate limiting configurations.
@@ -93,6 +104,66 @@ type RateLimitConfig struct {
 	Signing bool
 }
 
+// DefaultTLSCipherSuites are the cipher suites accepted by default on TLS 1.2
+// connections. All of them provide forward secrecy and authenticated
+// encryption.
+var DefaultTLSCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// TLSPolicy holds the TLS settings of the TCP listeners.
+type TLSPolicy struct {
+	// MinVersion is the minimum TLS version accepted. Defaults to TLS 1.2.
+	MinVersion uint16
+
+	// CipherSuites are the cipher suites accepted on connections up to TLS
+	// 1.2. TLS 1.3 cipher suites are not configurable. Defaults to
+	// DefaultTLSCipherSuites.
+	CipherSuites []uint16
+}
+
+func (p TLSPolicy) withDefaults() TLSPolicy {
+	if p.MinVersion == 0 {
+		p.MinVersion = tls.VersionTLS12
+	}
+	if len(p.CipherSuites) == 0 {
+		p.CipherSuites = DefaultTLSCipherSuites
+	}
+	return p
+}
+
+const (
+	// MinGRPCMessageSize and MaxGRPCMessageSize bound the configurable
+	// message size limits of the gRPC servers
+	MinGRPCMessageSize = 1 << 20
+	MaxGRPCMessageSize = 256 << 20
+)
+
+// GRPCMessageSizeLimits holds the maximum size in bytes of the messages
+// received and sent by the gRPC servers. A zero value leaves the gRPC
+// default in place, i.e. 4 MiB for received messages and no practical limit
+// for sent ones.
+type GRPCMessageSizeLimits struct {
+	MaxRecv int
+	MaxSend int
+}
+
+func (l GRPCMessageSizeLimits) serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if l.MaxRecv > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(l.MaxRecv))
+	}
+	if l.MaxSend > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(l.MaxSend))
+	}
+	return opts
+}
+
 // New creates new endpoints struct
 func New(ctx context.Context, c Config) (*Endpoints, error) {
 	if err := os.MkdirAll(c.UDSAddr.String(), 0750); err != nil {
@@ -104,12 +175,18 @@ func New(ctx context.Context, c Config) (*Endpoints, error) {
 	buildCacheFn := func(ctx context.Context) (_ entrycache.Cache, err error) {
 		call := telemetry.StartCall(c.Metrics, telemetry.Entry, telemetry.Cache, telemetry.Reload)
 		defer call.Done(&err)
-		return entrycache.BuildFromDataStore(ctx, c.Catalog.GetDataStore())
+		return entrycache.BuildFromDataStoreWithOptions(ctx, c.Catalog.GetDataStore(), entrycache.BuildOptions{
+			KnownSelectorTypes:        c.KnownSelectorTypes,
+			UnknownSelectorTypePolicy: c.UnknownSelectorTypePolicy,
+			Log:                       c.Log.WithField(telemetry.SubsystemName, telemetry.Cache),
+			Clock:                     c.Clock,
+		})
 	}
 
 	if c.CacheReloadInterval == 0 {
 		c.CacheReloadInterval = defaultCacheReloadInterval
 	}
+	c.TLSPolicy = c.TLSPolicy.withDefaults()
 
 	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCacheFn, c.Log, c.Clock, c.CacheReloadInterval)
 	if err != nil {
@@ -126,8 +203,12 @@ func New(ctx context.Context, c Config) (*Endpoints, error) {
 		APIServers:                   c.makeAPIServers(ef),
 		BundleEndpointServer:         c.maybeMakeBundleEndpointServer(),
 		Log:                          c.Log,
+		AuditLog:                     c.AuditLog,
 		Metrics:                      c.Metrics,
 		RateLimit:                    c.RateLimit,
+		TLSPolicy:                    c.TLSPolicy,
+		GRPCMessageSizeLimits:        c.GRPCMessageSizeLimits,
+		AdminScopes:                  c.AdminScopes,
 		EntryFetcherCacheRebuildTask: ef.RunRebuildCacheTask,
 	}, nil
 }
@@ -157,6 +238,12 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	entryv1.RegisterEntryServer(udsServer, e.APIServers.EntryServer)
 	svidv1.RegisterSVIDServer(tcpServer, e.APIServers.SVIDServer)
 	svidv1.RegisterSVIDServer(udsServer, e.APIServers.SVIDServer)
+	deletedentry_pb.RegisterDeletedEntryServer(tcpServer, e.APIServers.DeletedEntryServer)
+	deletedentry_pb.RegisterDeletedEntryServer(udsServer, e.APIServers.DeletedEntryServer)
+	entryfederation_pb.RegisterEntryFederationServer(tcpServer, e.APIServers.EntryFederationServer)
+	entryfederation_pb.RegisterEntryFederationServer(udsServer, e.APIServers.EntryFederationServer)
+	castatus_pb.RegisterCAStatusServer(tcpServer, e.APIServers.CAStatusServer)
+	castatus_pb.RegisterCAStatusServer(udsServer, e.APIServers.CAStatusServer)
 
 	// Register Health and Debug only on UDS server
 	grpc_health_v1.RegisterHealthServer(udsServer, e.APIServers.HealthServer)
@@ -188,21 +275,24 @@ func (e *Endpoints) createTCPServer(ctx context.Context, unaryInterceptor grpc.U
 		GetConfigForClient: e.getTLSConfig(ctx),
 	}
 
-	return grpc.NewServer(
+	opts := []grpc.ServerOption{
 		grpc.UnaryInterceptor(unaryInterceptor),
 		grpc.StreamInterceptor(streamInterceptor),
 		grpc.Creds(credentials.NewTLS(tlsConfig)),
 		grpc.KeepaliveParams(keepalive.ServerParameters{
 			MaxConnectionAge: defaultMaxConnectionAge,
 		}),
-	)
+	}
+	return grpc.NewServer(append(opts, e.GRPCMessageSizeLimits.serverOptions()...)...)
 }
 
 func (e *Endpoints) createUDSServer(unaryInterceptor grpc.UnaryServerInterceptor, streamInterceptor grpc.StreamServerInterceptor) *grpc.Server {
-	return grpc.NewServer(
+	opts := []grpc.ServerOption{
 		grpc.UnaryInterceptor(unaryInterceptor),
 		grpc.StreamInterceptor(streamInterceptor),
-		grpc.Creds(auth.UntrackedUDSCredentials()))
+		grpc.Creds(auth.UntrackedUDSCredentials()),
+	}
+	return grpc.NewServer(append(opts, e.GRPCMessageSizeLimits.serverOptions()...)...)
 }
 
 // runTCPServer will start the server and block until it exits or we are dying.
@@ -281,7 +371,8 @@ func (e *Endpoints) getTLSConfig(ctx context.Context) func(*tls.ClientHelloInfo)
 			Certificates: certs,
 			ClientCAs:    roots,
 
-			MinVersion: tls.VersionTLS12,
+			MinVersion:   e.TLSPolicy.MinVersion,
+			CipherSuites: e.TLSPolicy.CipherSuites,
 
 			NextProtos: []string{http2.NextProtoTLS},
 		}, nil
@@ -333,7 +424,7 @@ func (e *Endpoints) makeInterceptors() (grpc.UnaryServerInterceptor, grpc.Stream
 
 	oldUnary, oldStream := wrapWithDeprecationLogging(log, auth.UnaryAuthorizeCall, auth.StreamAuthorizeCall)
 
-	newUnary, newStream := middleware.Interceptors(Middleware(log, e.Metrics, e.DataStore, clock.New(), e.RateLimit))
+	newUnary, newStream := middleware.Interceptors(Middleware(log, e.Metrics, e.DataStore, clock.New(), e.RateLimit, e.AdminScopes))
 
-	return unaryInterceptorMux(oldUnary, newUnary), streamInterceptorMux(oldStream, newStream)
+	return wrapWithAuditLogging(e.AuditLog, unaryInterceptorMux(oldUnary, newUnary)), streamInterceptorMux(oldStream, newStream)
 }
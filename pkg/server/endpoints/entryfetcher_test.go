@@ -14,6 +14,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/cache/entrycache"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -90,6 +91,51 @@ func TestFetchRegistrationEntries(t *testing.T) {
 	assert.Equal(t, expected, entries)
 }
 
+func TestFetchRegistrationEntriesFiltersExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	log, _ := test.NewNullLogger()
+	clk := clock.NewMock(t)
+	ds := fakedatastore.New(t)
+	agentID := trustDomain.NewID("/spire/agent/test/node")
+	now := clk.Now().Unix()
+
+	createEntry := func(path string, expiresAt int64) *types.Entry {
+		entry, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			ParentId:    agentID.String(),
+			SpiffeId:    trustDomain.NewID(path).String(),
+			Selectors:   []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			EntryExpiry: expiresAt,
+		})
+		require.NoError(t, err)
+		protoEntry, err := api.RegistrationEntryToProto(entry)
+		require.NoError(t, err)
+		return protoEntry
+	}
+	noExpiry := createEntry("/no-expiry", 0)
+	expiresNow := createEntry("/expires-now", now)
+	createEntry("/expired", now-1)
+	expiresLater := createEntry("/expires-later", now+60)
+
+	buildCacheFn := func(ctx context.Context) (entrycache.Cache, error) {
+		return entrycache.BuildFromDataStoreWithOptions(ctx, ds, entrycache.BuildOptions{
+			Clock: clk,
+		})
+	}
+
+	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCacheFn, log, clk, defaultCacheReloadInterval)
+	require.NoError(t, err)
+
+	// Expired entries stop matching right away, without a cache rebuild
+	entries, err := ef.FetchAuthorizedEntries(ctx, agentID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*types.Entry{noExpiry, expiresNow, expiresLater}, entries)
+
+	clk.Add(time.Minute + time.Second)
+	entries, err = ef.FetchAuthorizedEntries(ctx, agentID)
+	require.NoError(t, err)
+	assert.Equal(t, []*types.Entry{noExpiry}, entries)
+}
+
 func TestRunRebuildCacheTask(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	watchErr := make(chan error, 1)
@@ -14,6 +14,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/plugin/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
@@ -22,66 +23,93 @@ import (
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
-func Middleware(log logrus.FieldLogger, metrics telemetry.Metrics, ds datastore.DataStore, clk clock.Clock, rlConf RateLimitConfig) middleware.Middleware {
+func Middleware(log logrus.FieldLogger, metrics telemetry.Metrics, ds datastore.DataStore, clk clock.Clock, rlConf RateLimitConfig, adminScopes api.AdminScopes) middleware.Middleware {
 	return middleware.Chain(
 		middleware.WithLogger(log),
 		middleware.WithMetrics(metrics),
-		middleware.WithAuthorization(Authorization(log, ds, clk)),
+		middleware.WithTracing(),
+		middleware.WithAuthorization(Authorization(log, ds, clk, adminScopes)),
 		middleware.WithRateLimits(RateLimits(rlConf)),
 	)
 }
 
-func Authorization(log logrus.FieldLogger, ds datastore.DataStore, clk clock.Clock) map[string]middleware.Authorizer {
+func Authorization(log logrus.FieldLogger, ds datastore.DataStore, clk clock.Clock, adminScopes api.AdminScopes) map[string]middleware.Authorizer {
 	agentAuthorizer := AgentAuthorizer(log, ds, clk)
-	entryFetcher := EntryFetcher(ds)
+	entryFetcher := EntryFetcher(ds, clk)
 
 	any := middleware.AuthorizeAny()
 	local := middleware.AuthorizeLocal()
 	agent := middleware.AuthorizeAgent(agentAuthorizer)
 	downstream := middleware.AuthorizeDownstream(entryFetcher)
-	admin := middleware.AuthorizeAdmin(entryFetcher)
+
+	// Admin workloads restricted to admin scopes can only call the methods
+	// allowed to one of their scopes
+	admin := middleware.AuthorizeScopedAdmin(entryFetcher, adminScopes)
+	readAdmin := middleware.AuthorizeScopedAdmin(entryFetcher, adminScopes, api.ReadOnlyAdminScope)
+	readEntryAdmin := middleware.AuthorizeScopedAdmin(entryFetcher, adminScopes, api.ReadOnlyAdminScope, api.EntryManagementAdminScope)
+	entryAdmin := middleware.AuthorizeScopedAdmin(entryFetcher, adminScopes, api.EntryManagementAdminScope)
+	readBundleAdmin := middleware.AuthorizeScopedAdmin(entryFetcher, adminScopes, api.ReadOnlyAdminScope, api.BundleManagementAdminScope)
+	bundleAdmin := middleware.AuthorizeScopedAdmin(entryFetcher, adminScopes, api.BundleManagementAdminScope)
 
 	localOrAdmin := middleware.AuthorizeAnyOf(local, admin)
-	localOrAdminOrAgent := middleware.AuthorizeAnyOf(local, admin, agent)
+	localOrAdminOrDownstream := middleware.AuthorizeAnyOf(local, admin, downstream)
+	localOrReadAdmin := middleware.AuthorizeAnyOf(local, readAdmin)
+	localOrReadEntryAdmin := middleware.AuthorizeAnyOf(local, readEntryAdmin)
+	localOrEntryAdmin := middleware.AuthorizeAnyOf(local, entryAdmin)
+	localOrReadBundleAdmin := middleware.AuthorizeAnyOf(local, readBundleAdmin)
+	localOrReadBundleAdminOrAgent := middleware.AuthorizeAnyOf(local, readBundleAdmin, agent)
+	localOrBundleAdmin := middleware.AuthorizeAnyOf(local, bundleAdmin)
+
+	// Agents reattesting their node authenticate with their current SVID
+	agentOrAny := middleware.AuthorizeAnyOf(agent, any)
 
 	return map[string]middleware.Authorizer{
-		"/spire.api.server.svid.v1.SVID/MintX509SVID":                   localOrAdmin,
-		"/spire.api.server.svid.v1.SVID/MintJWTSVID":                    localOrAdmin,
+		"/spire.api.server.svid.v1.SVID/MintX509SVID":                   localOrAdminOrDownstream,
+		"/spire.api.server.svid.v1.SVID/MintJWTSVID":                    localOrAdminOrDownstream,
 		"/spire.api.server.svid.v1.SVID/BatchNewX509SVID":               agent,
 		"/spire.api.server.svid.v1.SVID/NewJWTSVID":                     agent,
 		"/spire.api.server.svid.v1.SVID/NewDownstreamX509CA":            downstream,
 		"/spire.api.server.bundle.v1.Bundle/GetBundle":                  any,
-		"/spire.api.server.bundle.v1.Bundle/AppendBundle":               localOrAdmin,
+		"/spire.api.server.bundle.v1.Bundle/AppendBundle":               localOrBundleAdmin,
 		"/spire.api.server.bundle.v1.Bundle/PublishJWTAuthority":        downstream,
-		"/spire.api.server.bundle.v1.Bundle/CountBundles":               localOrAdmin,
-		"/spire.api.server.bundle.v1.Bundle/ListFederatedBundles":       localOrAdmin,
-		"/spire.api.server.bundle.v1.Bundle/GetFederatedBundle":         localOrAdminOrAgent,
-		"/spire.api.server.bundle.v1.Bundle/BatchCreateFederatedBundle": localOrAdmin,
-		"/spire.api.server.bundle.v1.Bundle/BatchUpdateFederatedBundle": localOrAdmin,
-		"/spire.api.server.bundle.v1.Bundle/BatchSetFederatedBundle":    localOrAdmin,
-		"/spire.api.server.bundle.v1.Bundle/BatchDeleteFederatedBundle": localOrAdmin,
+		"/spire.api.server.bundle.v1.Bundle/CountBundles":               localOrReadBundleAdmin,
+		"/spire.api.server.bundle.v1.Bundle/ListFederatedBundles":       localOrReadBundleAdmin,
+		"/spire.api.server.bundle.v1.Bundle/GetFederatedBundle":         localOrReadBundleAdminOrAgent,
+		"/spire.api.server.bundle.v1.Bundle/BatchCreateFederatedBundle": localOrBundleAdmin,
+		"/spire.api.server.bundle.v1.Bundle/BatchUpdateFederatedBundle": localOrBundleAdmin,
+		"/spire.api.server.bundle.v1.Bundle/BatchSetFederatedBundle":    localOrBundleAdmin,
+		"/spire.api.server.bundle.v1.Bundle/BatchDeleteFederatedBundle": localOrBundleAdmin,
 		"/spire.api.server.debug.v1.Debug/GetInfo":                      local,
-		"/spire.api.server.entry.v1.Entry/CountEntries":                 localOrAdmin,
-		"/spire.api.server.entry.v1.Entry/ListEntries":                  localOrAdmin,
-		"/spire.api.server.entry.v1.Entry/GetEntry":                     localOrAdmin,
-		"/spire.api.server.entry.v1.Entry/BatchCreateEntry":             localOrAdmin,
-		"/spire.api.server.entry.v1.Entry/BatchUpdateEntry":             localOrAdmin,
-		"/spire.api.server.entry.v1.Entry/BatchDeleteEntry":             localOrAdmin,
+		"/spire.api.server.entry.v1.Entry/CountEntries":                 localOrReadEntryAdmin,
+		"/spire.api.server.entry.v1.Entry/ListEntries":                  localOrReadEntryAdmin,
+		"/spire.api.server.entry.v1.Entry/GetEntry":                     localOrReadEntryAdmin,
+		"/spire.api.server.entry.v1.Entry/BatchCreateEntry":             localOrEntryAdmin,
+		"/spire.api.server.entry.v1.Entry/BatchUpdateEntry":             localOrEntryAdmin,
+		"/spire.api.server.entry.v1.Entry/BatchDeleteEntry":             localOrEntryAdmin,
 		"/spire.api.server.entry.v1.Entry/GetAuthorizedEntries":         agent,
-		"/spire.api.server.agent.v1.Agent/CountAgents":                  localOrAdmin,
-		"/spire.api.server.agent.v1.Agent/ListAgents":                   localOrAdmin,
-		"/spire.api.server.agent.v1.Agent/GetAgent":                     localOrAdmin,
+		"/spire.api.server.agent.v1.Agent/CountAgents":                  localOrReadAdmin,
+		"/spire.api.server.agent.v1.Agent/ListAgents":                   localOrReadAdmin,
+		"/spire.api.server.agent.v1.Agent/GetAgent":                     localOrReadAdmin,
 		"/spire.api.server.agent.v1.Agent/DeleteAgent":                  localOrAdmin,
 		"/spire.api.server.agent.v1.Agent/BanAgent":                     localOrAdmin,
-		"/spire.api.server.agent.v1.Agent/AttestAgent":                  any,
+		"/spire.api.server.agent.v1.Agent/AttestAgent":                  agentOrAny,
 		"/spire.api.server.agent.v1.Agent/RenewAgent":                   agent,
 		"/spire.api.server.agent.v1.Agent/CreateJoinToken":              localOrAdmin,
 		"/grpc.health.v1.Health/Check":                                  local,
 		"/grpc.health.v1.Health/Watch":                                  local,
+
+		"/spire.private.server.deletedentry.DeletedEntry/ListDeletedEntries": localOrReadEntryAdmin,
+		"/spire.private.server.deletedentry.DeletedEntry/RestoreEntry":       localOrEntryAdmin,
+
+		"/spire.private.server.entryfederation.EntryFederation/UpdateFederatesWith": localOrEntryAdmin,
+
+		"/spire.private.server.castatus.CAStatus/GetX509CAStatus": localOrReadAdmin,
 	}
 }
 
-func EntryFetcher(ds datastore.DataStore) middleware.EntryFetcher {
+// EntryFetcher returns the entries for the given SPIFFE ID. Expired entries
+// are skipped, so they stop authorizing callers before they are pruned.
+func EntryFetcher(ds datastore.DataStore, clk clock.Clock) middleware.EntryFetcher {
 	return middleware.EntryFetcherFunc(func(ctx context.Context, id spiffeid.ID) ([]*types.Entry, error) {
 		resp, err := ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
 			BySpiffeId: &wrapperspb.StringValue{
@@ -91,7 +119,15 @@ func EntryFetcher(ds datastore.DataStore) middleware.EntryFetcher {
 		if err != nil {
 			return nil, err
 		}
-		return api.RegistrationEntriesToProto(resp.Entries)
+
+		now := clk.Now().Unix()
+		entries := make([]*common.RegistrationEntry, 0, len(resp.Entries))
+		for _, entry := range resp.Entries {
+			if entry.EntryExpiry == 0 || entry.EntryExpiry >= now {
+				entries = append(entries, entry)
+			}
+		}
+		return api.RegistrationEntriesToProto(entries)
 	})
 }
 
@@ -213,6 +249,13 @@ func RateLimits(config RateLimitConfig) map[string]api.RateLimiter {
 		"/spire.api.server.agent.v1.Agent/CreateJoinToken":              noLimit,
 		"/grpc.health.v1.Health/Check":                                  noLimit,
 		"/grpc.health.v1.Health/Watch":                                  noLimit,
+
+		"/spire.private.server.deletedentry.DeletedEntry/ListDeletedEntries": noLimit,
+		"/spire.private.server.deletedentry.DeletedEntry/RestoreEntry":       noLimit,
+
+		"/spire.private.server.entryfederation.EntryFederation/UpdateFederatesWith": noLimit,
+
+		"/spire.private.server.castatus.CAStatus/GetX509CAStatus": noLimit,
 	}
 }
 
@@ -13,6 +13,7 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/cache/entrycache"
 	"github.com/spiffe/spire/pkg/server/plugin/datastore"
 	"github.com/spiffe/spire/proto/spire/common"
@@ -388,3 +389,143 @@ func createAuthorizedEntryTestData(t testing.TB, ds datastore.DataStore) *testEn
 		workloadEntries:  workloadEntries,
 	}
 }
+
+func TestEntryFetcherSkipsExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	ds := fakedatastore.New(t)
+	clk := clock.NewMock(t)
+
+	createEntry := func(expiresAt int64) *common.RegistrationEntry {
+		entry, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			ParentId:    agentID.String(),
+			SpiffeId:    adminID.String(),
+			Selectors:   []*common.Selector{{Type: "unix", Value: fmt.Sprintf("uid:%d", expiresAt)}},
+			Admin:       true,
+			EntryExpiry: expiresAt,
+		})
+		require.NoError(t, err)
+		return entry
+	}
+	createEntry(clk.Now().Add(-time.Second).Unix())
+	expiresLater := createEntry(clk.Now().Add(time.Minute).Unix())
+
+	fetcher := EntryFetcher(ds, clk)
+
+	// Expired entries stop authorizing callers before they are pruned
+	expected, err := api.RegistrationEntriesToProto([]*common.RegistrationEntry{expiresLater})
+	require.NoError(t, err)
+	entries, err := fetcher.FetchEntries(ctx, adminID)
+	require.NoError(t, err)
+	spiretest.AssertProtoListEqual(t, expected, entries)
+
+	clk.Add(time.Minute + time.Second)
+	entries, err = fetcher.FetchEntries(ctx, adminID)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestAuthorizationAdminScopes(t *testing.T) {
+	readerID := testTD.NewID("/reader")
+	entryManagerID := testTD.NewID("/entry-manager")
+	bundleManagerID := testTD.NewID("/bundle-manager")
+
+	ds := fakedatastore.New(t)
+	for _, id := range []spiffeid.ID{adminID, readerID, entryManagerID, bundleManagerID} {
+		_, err := ds.CreateRegistrationEntry(context.Background(), &common.RegistrationEntry{
+			ParentId:  agentID.String(),
+			SpiffeId:  id.String(),
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			Admin:     true,
+		})
+		require.NoError(t, err)
+	}
+
+	log, _ := test.NewNullLogger()
+	authorizers := Authorization(log, ds, clock.NewMock(t), api.AdminScopes{
+		readerID:        {api.ReadOnlyAdminScope},
+		entryManagerID:  {api.EntryManagementAdminScope},
+		bundleManagerID: {api.BundleManagementAdminScope},
+	})
+
+	readMethods := []string{
+		"/spire.api.server.bundle.v1.Bundle/CountBundles",
+		"/spire.api.server.bundle.v1.Bundle/ListFederatedBundles",
+		"/spire.api.server.bundle.v1.Bundle/GetFederatedBundle",
+		"/spire.api.server.entry.v1.Entry/CountEntries",
+		"/spire.api.server.entry.v1.Entry/ListEntries",
+		"/spire.api.server.entry.v1.Entry/GetEntry",
+		"/spire.api.server.agent.v1.Agent/CountAgents",
+		"/spire.api.server.agent.v1.Agent/ListAgents",
+		"/spire.api.server.agent.v1.Agent/GetAgent",
+		"/spire.private.server.deletedentry.DeletedEntry/ListDeletedEntries",
+		"/spire.private.server.castatus.CAStatus/GetX509CAStatus",
+	}
+	entryMethods := []string{
+		"/spire.api.server.entry.v1.Entry/CountEntries",
+		"/spire.api.server.entry.v1.Entry/ListEntries",
+		"/spire.api.server.entry.v1.Entry/GetEntry",
+		"/spire.api.server.entry.v1.Entry/BatchCreateEntry",
+		"/spire.api.server.entry.v1.Entry/BatchUpdateEntry",
+		"/spire.api.server.entry.v1.Entry/BatchDeleteEntry",
+		"/spire.private.server.deletedentry.DeletedEntry/ListDeletedEntries",
+		"/spire.private.server.deletedentry.DeletedEntry/RestoreEntry",
+		"/spire.private.server.entryfederation.EntryFederation/UpdateFederatesWith",
+	}
+	bundleMethods := []string{
+		"/spire.api.server.bundle.v1.Bundle/AppendBundle",
+		"/spire.api.server.bundle.v1.Bundle/CountBundles",
+		"/spire.api.server.bundle.v1.Bundle/ListFederatedBundles",
+		"/spire.api.server.bundle.v1.Bundle/GetFederatedBundle",
+		"/spire.api.server.bundle.v1.Bundle/BatchCreateFederatedBundle",
+		"/spire.api.server.bundle.v1.Bundle/BatchUpdateFederatedBundle",
+		"/spire.api.server.bundle.v1.Bundle/BatchSetFederatedBundle",
+		"/spire.api.server.bundle.v1.Bundle/BatchDeleteFederatedBundle",
+	}
+	adminOnlyMethods := []string{
+		"/spire.api.server.svid.v1.SVID/MintX509SVID",
+		"/spire.api.server.svid.v1.SVID/MintJWTSVID",
+		"/spire.api.server.agent.v1.Agent/DeleteAgent",
+		"/spire.api.server.agent.v1.Agent/BanAgent",
+		"/spire.api.server.agent.v1.Agent/CreateJoinToken",
+	}
+
+	allAdminMethods := make(map[string]bool)
+	for _, methods := range [][]string{readMethods, entryMethods, bundleMethods, adminOnlyMethods} {
+		for _, method := range methods {
+			allAdminMethods[method] = true
+		}
+	}
+
+	for _, tt := range []struct {
+		name    string
+		id      spiffeid.ID
+		allowed []string
+	}{
+		{name: "unrestricted admin", id: adminID, allowed: append(append(append(append([]string{}, readMethods...), entryMethods...), bundleMethods...), adminOnlyMethods...)},
+		{name: "read-only admin", id: readerID, allowed: readMethods},
+		{name: "entry-management admin", id: entryManagerID, allowed: entryMethods},
+		{name: "bundle-management admin", id: bundleManagerID, allowed: bundleMethods},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			allowed := make(map[string]bool)
+			for _, method := range tt.allowed {
+				allowed[method] = true
+			}
+
+			for method := range allAdminMethods {
+				authorizer, ok := authorizers[method]
+				require.True(t, ok, "no authorizer for %s", method)
+
+				ctx := rpccontext.WithLogger(context.Background(), log)
+				ctx = rpccontext.WithCallerID(ctx, tt.id)
+				_, err := authorizer.AuthorizeCaller(ctx)
+				if allowed[method] {
+					assert.NoError(t, err, "%s should be allowed", method)
+				} else {
+					assert.Equal(t, codes.PermissionDenied, status.Code(err), "%s should be denied", method)
+				}
+			}
+		})
+	}
+}
@@ -36,6 +36,22 @@ var isDNSLabel = regexp.MustCompile(`^[a-zA-Z0-9]([-]*[a-zA-Z0-9])+$`).MatchStri
 
 const defaultListEntriesPageSize = 50
 
+// updateEntryMask updates every field of an entry, including its metadata,
+// which the datastore leaves alone on updates without a mask.
+var updateEntryMask = &common.RegistrationEntryMask{
+	Selectors:     true,
+	ParentId:      true,
+	SpiffeId:      true,
+	Ttl:           true,
+	FederatesWith: true,
+	EntryId:       true,
+	Admin:         true,
+	Downstream:    true,
+	EntryExpiry:   true,
+	DnsNames:      true,
+	Metadata:      true,
+}
+
 // Handler service is used to register SPIFFE IDs, and the attestation logic that should
 // be performed on a workload before those IDs can be issued.
 type Handler struct {
@@ -46,6 +62,22 @@ type Handler struct {
 	Catalog     catalog.Catalog
 	TrustDomain spiffeid.TrustDomain
 	ServerCA    ca.ServerCA
+
+	// SPIFFEIDCollisionPolicy determines how entries that collide with
+	// existing entries are handled on create and update
+	SPIFFEIDCollisionPolicy api.SPIFFEIDCollisionPolicy
+
+	// DuplicateSelectorPolicy determines how entries with duplicate
+	// selectors are handled on create and update
+	DuplicateSelectorPolicy api.DuplicateSelectorPolicy
+
+	// DeletedEntryGracePeriod, if greater than zero, is how long deleted
+	// entries are kept, so they can be restored, before they are purged
+	DeletedEntryGracePeriod time.Duration
+
+	// AdminScopes restricts admin workloads to some of the server APIs.
+	// Restricted admin workloads are not authorized to call this API.
+	AdminScopes api.AdminScopes
 }
 
 // CreateEntry creates an entry in the Registration table,
@@ -110,7 +142,11 @@ func (h *Handler) DeleteEntry(ctx context.Context, request *registration.Registr
 	log := h.Log.WithField(telemetry.Method, telemetry.DeleteRegistrationEntry)
 
 	ds := h.getDataStore()
-	registrationEntry, err := ds.DeleteRegistrationEntry(ctx, request.Id)
+	deleteRegistrationEntry := ds.DeleteRegistrationEntry
+	if h.DeletedEntryGracePeriod > 0 {
+		deleteRegistrationEntry = ds.SoftDeleteRegistrationEntry
+	}
+	registrationEntry, err := deleteRegistrationEntry(ctx, request.Id)
 	if err != nil {
 		log.WithError(err).Error("Error deleting registration entry")
 		return &common.RegistrationEntry{}, status.Error(codes.Internal, err.Error())
@@ -176,8 +212,14 @@ func (h *Handler) UpdateEntry(ctx context.Context, request *registration.UpdateE
 	}
 
 	ds := h.getDataStore()
+	if err := h.checkSPIFFEIDCollisions(ctx, log, ds, request.Entry); err != nil {
+		log.WithError(err).Error("Failed to update registration entry")
+		return nil, err
+	}
+
 	resp, err := ds.UpdateRegistrationEntry(ctx, &datastore.UpdateRegistrationEntryRequest{
 		Entry: request.Entry,
+		Mask:  updateEntryMask,
 	})
 	if err != nil {
 		log.WithError(err).Error("Failed to update registration entry")
@@ -323,6 +365,7 @@ func (h *Handler) ListAllEntriesWithPages(ctx context.Context, request *registra
 			Token:    token,
 			PageSize: pageSize,
 		},
+		ByMetadata: request.ByMetadata,
 	})
 	if err != nil {
 		log.WithError(err).Error("Error trying to fetch entries")
@@ -793,6 +836,10 @@ func (h *Handler) createRegistrationEntry(ctx context.Context, requestedEntry *c
 		return existingEntry, true, nil
 	}
 
+	if err := h.checkSPIFFEIDCollisions(ctx, h.Log, ds, requestedEntry); err != nil {
+		return nil, false, err
+	}
+
 	registrationEntry, err := ds.CreateRegistrationEntry(ctx, requestedEntry)
 	if err != nil {
 		return nil, false, status.Errorf(codes.Internal, "error trying to create entry: %v", err)
@@ -800,6 +847,21 @@ func (h *Handler) createRegistrationEntry(ctx context.Context, requestedEntry *c
 
 	return registrationEntry, false, nil
 }
+
+// checkSPIFFEIDCollisions applies the SPIFFE ID collision policy to the entry
+// and returns a gRPC status error if it is rejected. Errors are not logged.
+func (h *Handler) checkSPIFFEIDCollisions(ctx context.Context, log logrus.FieldLogger, ds datastore.DataStore, entry *common.RegistrationEntry) error {
+	err := api.CheckSPIFFEIDCollisions(ctx, log, ds, h.SPIFFEIDCollisionPolicy, entry)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, api.ErrSPIFFEIDCollision):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "failed to check for SPIFFE ID collisions: %v", err)
+	}
+}
+
 func (h *Handler) prepareRegistrationEntry(entry *common.RegistrationEntry, forUpdate bool) (*common.RegistrationEntry, error) {
 	original := entry
 	entry = cloneRegistrationEntry(entry)
@@ -840,6 +902,10 @@ func (h *Handler) prepareRegistrationEntry(entry *common.RegistrationEntry, forU
 			return nil, err
 		}
 	}
+	entry.Selectors, err = api.ApplyDuplicateSelectorPolicy(h.DuplicateSelectorPolicy, entry.Selectors)
+	if err != nil {
+		return nil, err
+	}
 
 	return entry, nil
 }
@@ -850,7 +916,7 @@ func (h *Handler) AuthorizeCall(ctx context.Context, fullMethod string) (_ conte
 	defer counter.Done(&err)
 	log := h.Log.WithField(telemetry.Method, fullMethod)
 
-	callerID, err := authorizeCaller(ctx, h.getDataStore())
+	callerID, err := authorizeCaller(ctx, h.getDataStore(), h.AdminScopes)
 	if err != nil {
 		log.WithError(err).Error("Failed to authorize caller")
 		return nil, err
@@ -891,7 +957,7 @@ func getSpiffeIDFromCert(cert *x509.Certificate) (string, error) {
 	return spiffeID.String(), nil
 }
 
-func authorizeCaller(ctx context.Context, ds datastore.DataStore) (spiffeID string, err error) {
+func authorizeCaller(ctx context.Context, ds datastore.DataStore, adminScopes api.AdminScopes) (spiffeID string, err error) {
 	ctxPeer, ok := peer.FromContext(ctx)
 	if !ok {
 		return "", status.Error(codes.PermissionDenied, "no peer information for caller")
@@ -935,6 +1001,11 @@ func authorizeCaller(ctx context.Context, ds datastore.DataStore) (spiffeID stri
 
 	for _, entry := range resp.Entries {
 		if entry.Admin {
+			// This API is all or nothing, so admin workloads restricted to
+			// admin scopes are not authorized
+			if id, err := spiffeid.FromString(spiffeID); err == nil && adminScopes.IsRestricted(id) {
+				return "", status.Errorf(codes.PermissionDenied, "SPIFFE ID %q is restricted to admin scopes", spiffeID)
+			}
 			return spiffeID, nil
 		}
 	}
@@ -19,6 +19,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/plugin/datastore"
 	"github.com/spiffe/spire/proto/spire/api/registration"
 	"github.com/spiffe/spire/proto/spire/common"
@@ -75,6 +76,7 @@ type HandlerSuite struct {
 
 	ds       *fakedatastore.DataStore
 	serverCA *fakeserverca.CA
+	impl     *Handler
 	handler  registration.RegistrationClient
 }
 
@@ -110,6 +112,7 @@ func (s *HandlerSuite) SetupTest() {
 
 	go func() { _ = server.Serve(listener) }()
 	s.server = server
+	s.impl = handler
 	s.handler = registration.NewRegistrationClient(conn)
 }
 
@@ -427,6 +430,60 @@ func (s *HandlerSuite) TestCreateEntry() {
 	}
 }
 
+func (s *HandlerSuite) TestCreateEntrySPIFFEIDCollision() {
+	existing := s.createRegistrationEntry(&common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/parent",
+		SpiffeId:  "spiffe://example.org/existing",
+		Selectors: []*common.Selector{{Type: "B", Value: "b"}},
+	})
+	colliding := &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/parent",
+		SpiffeId:  "spiffe://example.org/colliding",
+		Selectors: []*common.Selector{{Type: "B", Value: "b"}},
+	}
+
+	s.impl.SPIFFEIDCollisionPolicy = api.RejectSPIFFEIDCollisions
+	_, err := s.handler.CreateEntry(context.Background(), colliding)
+	s.requireGRPCStatusCode(err, codes.FailedPrecondition)
+	s.Require().Contains(err.Error(), existing.EntryId)
+
+	s.impl.SPIFFEIDCollisionPolicy = api.AllowSPIFFEIDCollisions
+	resp, err := s.handler.CreateEntry(context.Background(), colliding)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(resp.Id)
+}
+
+func (s *HandlerSuite) TestCreateEntryDuplicateSelectors() {
+	entry := &common.RegistrationEntry{
+		ParentId: "spiffe://example.org/parent",
+		SpiffeId: "spiffe://example.org/workload",
+		Selectors: []*common.Selector{
+			{Type: "B", Value: "b"},
+			{Type: "A", Value: "a"},
+			{Type: "B", Value: "b"},
+		},
+	}
+
+	s.impl.DuplicateSelectorPolicy = api.RejectDuplicateSelectors
+	_, err := s.handler.CreateEntry(context.Background(), entry)
+	s.requireGRPCStatusCode(err, codes.InvalidArgument)
+	s.Require().Contains(err.Error(), `duplicate selector "B:b"`)
+
+	s.impl.DuplicateSelectorPolicy = api.DedupeDuplicateSelectors
+	resp, err := s.handler.CreateEntry(context.Background(), entry)
+	s.Require().NoError(err)
+
+	created, err := s.handler.FetchEntry(context.Background(), resp)
+	s.Require().NoError(err)
+	s.Require().Len(created.Selectors, 2)
+
+	// Duplicates are also collapsed on update
+	created.Selectors = append(created.Selectors, &common.Selector{Type: "A", Value: "a"})
+	updated, err := s.handler.UpdateEntry(context.Background(), &registration.UpdateEntryRequest{Entry: created})
+	s.Require().NoError(err)
+	s.Require().Len(updated.Selectors, 2)
+}
+
 func (s *HandlerSuite) TestCreateEntryIfNotExists() {
 	testCases := []struct {
 		Name        string
@@ -516,6 +573,7 @@ func (s *HandlerSuite) TestUpdateEntry() {
 			PrepareEntry: func(e *common.RegistrationEntry) {
 				e.Selectors = []*common.Selector{{Type: "B", Value: "b"}}
 				e.DnsNames = []string{"wxyz.2-a"}
+				e.Metadata = map[string]string{"owner": "team-a"}
 			},
 		},
 	}
@@ -581,6 +639,30 @@ func (s *HandlerSuite) TestDeleteEntry() {
 	}
 }
 
+func (s *HandlerSuite) TestDeleteEntryWithGracePeriod() {
+	entry := s.createRegistrationEntry(&common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/foo",
+		SpiffeId:  "spiffe://example.org/bar",
+		Selectors: []*common.Selector{{Type: "A", Value: "a"}},
+	})
+
+	s.impl.DeletedEntryGracePeriod = time.Hour
+	resp, err := s.handler.DeleteEntry(context.Background(), &registration.RegistrationEntryID{
+		Id: entry.EntryId,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(entry.EntryId, resp.EntryId)
+
+	// The entry no longer matches, but can be restored
+	fetched, err := s.ds.FetchRegistrationEntry(context.Background(), entry.EntryId)
+	s.Require().NoError(err)
+	s.Require().Nil(fetched)
+
+	restored, err := s.ds.RestoreRegistrationEntry(context.Background(), entry.EntryId)
+	s.Require().NoError(err)
+	s.RequireProtoEqual(entry, restored)
+}
+
 func (s *HandlerSuite) TestFetchEntry() {
 	entry := s.createRegistrationEntry(&common.RegistrationEntry{
 		ParentId:  "spiffe://example.org/foo",
@@ -660,6 +742,7 @@ func (s *HandlerSuite) TestListByParentId() {
 		ParentId:  "spiffe://example.org/foo",
 		SpiffeId:  "spiffe://example.org/baz",
 		Selectors: []*common.Selector{{Type: "A", Value: "a"}},
+		Metadata:  map[string]string{"owner": "team-a"},
 	})
 	entry3 := s.createRegistrationEntry(&common.RegistrationEntry{
 		ParentId:  "spiffe://example.org/buz",
@@ -709,6 +792,7 @@ func (s *HandlerSuite) TestListBySelector() {
 		ParentId:  "spiffe://example.org/foo",
 		SpiffeId:  "spiffe://example.org/baz",
 		Selectors: []*common.Selector{{Type: "A", Value: "a"}},
+		Metadata:  map[string]string{"owner": "team-a"},
 	})
 	entry3 := s.createRegistrationEntry(&common.RegistrationEntry{
 		ParentId:  "spiffe://example.org/buz",
@@ -845,6 +929,7 @@ func (s *HandlerSuite) TestListAllEntriesWithPages() {
 		ParentId:  "spiffe://example.org/foo",
 		SpiffeId:  "spiffe://example.org/baz",
 		Selectors: []*common.Selector{{Type: "A", Value: "a"}},
+		Metadata:  map[string]string{"owner": "team-a"},
 	})
 	entry3 := s.createRegistrationEntry(&common.RegistrationEntry{
 		ParentId:  "spiffe://example.org/buz",
@@ -892,6 +977,13 @@ func (s *HandlerSuite) TestListAllEntriesWithPages() {
 			},
 			expectedList: []*common.RegistrationEntry{entry3, entry2, entry1},
 		},
+		{
+			name: "filtered by metadata",
+			request: &registration.ListAllEntriesRequest{
+				ByMetadata: map[string]string{"owner": "team-a"},
+			},
+			expectedList: []*common.RegistrationEntry{entry2},
+		},
 	}
 	for _, testCase := range tests {
 		testCase := testCase
@@ -1321,6 +1413,9 @@ func (s *HandlerSuite) TestAuthorizeCall() {
 		Log:     log,
 		Catalog: catalog,
 		Metrics: telemetry.Blackhole{},
+		AdminScopes: api.AdminScopes{
+			spiffeid.Must("example.org", "scoped-admin"): {api.EntryManagementAdminScope},
+		},
 	}
 
 	makeTLSPeer := func(spiffeID string) *peer.Peer {
@@ -1345,6 +1440,12 @@ func (s *HandlerSuite) TestAuthorizeCall() {
 		Selectors: []*common.Selector{{Type: "A", Value: "a"}},
 		Admin:     true,
 	})
+	s.createRegistrationEntry(&common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/parent",
+		SpiffeId:  "spiffe://example.org/scoped-admin",
+		Selectors: []*common.Selector{{Type: "A", Value: "a"}},
+		Admin:     true,
+	})
 
 	testCases := []struct {
 		Peer     *peer.Peer
@@ -1395,6 +1496,10 @@ func (s *HandlerSuite) TestAuthorizeCall() {
 			Peer:     makeTLSPeer("spiffe://example.org/admin"),
 			CallerID: "spiffe://example.org/admin",
 		},
+		{
+			Peer: makeTLSPeer("spiffe://example.org/scoped-admin"),
+			Err:  `SPIFFE ID "spiffe://example.org/scoped-admin" is restricted to admin scopes`,
+		},
 	}
 
 	for _, testCase := range testCases {
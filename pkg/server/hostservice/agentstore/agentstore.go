@@ -5,6 +5,7 @@ import (
 	"errors"
 	"sync"
 
+	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/server/plugin/datastore"
 	agentstorev0 "github.com/spiffe/spire/proto/spire/hostservice/server/agentstore/v0"
 	"google.golang.org/grpc/codes"
@@ -52,7 +53,15 @@ func (s *AgentStore) GetAgentInfo(ctx context.Context, req *agentstorev0.GetAgen
 		return nil, err
 	}
 
-	attestedNode, err := deps.DataStore.FetchAttestedNode(ctx, req.AgentId)
+	// The agent ID is stored in its canonical form, so it must be looked up
+	// the same way. Otherwise a node could attest again with a differently
+	// spelled ID, bypassing attestors that only allow a node to attest once.
+	agentID, err := idutil.CanonicalizeSpiffeID(req.AgentId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "malformed agent ID: %v", err)
+	}
+
+	attestedNode, err := deps.DataStore.FetchAttestedNode(ctx, agentID)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +71,7 @@ func (s *AgentStore) GetAgentInfo(ctx context.Context, req *agentstorev0.GetAgen
 
 	return &agentstorev0.GetAgentInfoResponse{
 		Info: &agentstorev0.AgentInfo{
-			AgentId: req.AgentId,
+			AgentId: agentID,
 		},
 	}, nil
 }
@@ -25,12 +25,13 @@ func TestAgentStore(t *testing.T) {
 	}
 
 	testCases := []struct {
-		name    string
-		deps    *Deps
-		agentID string
-		code    codes.Code
-		depsErr string
-		getErr  string
+		name          string
+		deps          *Deps
+		agentID       string
+		expectAgentID string
+		code          codes.Code
+		depsErr       string
+		getErr        string
 	}{
 		{
 			name:   "precondition failure when no deps set",
@@ -54,6 +55,19 @@ func TestAgentStore(t *testing.T) {
 			agentID: "spiffe://domain.test/spire/agent/test/foo",
 			deps:    deps,
 		},
+		{
+			name:          "success with a differently spelled agent ID",
+			agentID:       "spiffe://DOMAIN.test/spire/agent/test//f%6Fo/",
+			deps:          deps,
+			expectAgentID: "spiffe://domain.test/spire/agent/test/foo",
+		},
+		{
+			name:    "malformed agent ID",
+			agentID: "http://domain.test/spire/agent/test/foo",
+			deps:    deps,
+			code:    codes.InvalidArgument,
+			getErr:  "malformed agent ID",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -81,7 +95,11 @@ func TestAgentStore(t *testing.T) {
 			}
 			require.NoError(err)
 			require.NotNil(t, resp)
-			assert.Equal(resp.Info.AgentId, testCase.agentID)
+			expectAgentID := testCase.expectAgentID
+			if expectAgentID == "" {
+				expectAgentID = testCase.agentID
+			}
+			assert.Equal(expectAgentID, resp.Info.AgentId)
 		})
 	}
 }
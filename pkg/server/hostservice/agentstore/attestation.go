@@ -5,11 +5,24 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/spiffe/spire/pkg/common/idutil"
 	agentstorev0 "github.com/spiffe/spire/proto/spire/hostservice/server/agentstore/v0"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
+// reattestingAgentKey is the metadata key used by the server to tell node
+// attestor plugins which agent is attesting its node again.
+const reattestingAgentKey = "spire-reattesting-agent-id"
+
+// WithReattestingAgent returns a context that tells node attestor plugins
+// that the agent with the given ID, authenticated with its current SVID, is
+// attesting its node again.
+func WithReattestingAgent(ctx context.Context, agentID string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, reattestingAgentKey, agentID)
+}
+
 func EnsureNotAttested(ctx context.Context, store agentstorev0.AgentStoreClient, agentID string) error {
 	attested, err := IsAttested(ctx, store, agentID)
 	switch {
@@ -22,7 +35,15 @@ func EnsureNotAttested(ctx context.Context, store agentstorev0.AgentStoreClient,
 	}
 }
 
+// IsAttested returns whether the agent has already attested. An agent that
+// is attesting its node again, as told by the server through
+// WithReattestingAgent, is not reported as attested, so it can refresh its
+// node selectors with node attestors that only allow a node to attest once.
 func IsAttested(ctx context.Context, store agentstorev0.AgentStoreClient, agentID string) (bool, error) {
+	if isReattestingAgent(ctx, agentID) {
+		return false, nil
+	}
+
 	_, err := store.GetAgentInfo(ctx, &agentstorev0.GetAgentInfoRequest{
 		AgentId: agentID,
 	})
@@ -35,3 +56,20 @@ func IsAttested(ctx context.Context, store agentstorev0.AgentStoreClient, agentI
 		return false, fmt.Errorf("unable to get agent info: %v", err)
 	}
 }
+
+func isReattestingAgent(ctx context.Context, agentID string) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	reattestingAgentIDs := md.Get(reattestingAgentKey)
+	if len(reattestingAgentIDs) != 1 {
+		return false
+	}
+
+	canonicalID, err := idutil.CanonicalizeSpiffeID(agentID)
+	if err != nil {
+		return false
+	}
+	return canonicalID == reattestingAgentIDs[0]
+}
@@ -5,10 +5,14 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/spiffe/spire/proto/spire/common"
 	agentstorev0 "github.com/spiffe/spire/proto/spire/hostservice/server/agentstore/v0"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -26,6 +30,33 @@ func TestEnsureNotAttested(t *testing.T) {
 	assert.EqualError(err, "unable to get agent info: ohno")
 }
 
+func TestEnsureNotAttestedWithDifferentlySpelledAgentID(t *testing.T) {
+	ds := fakedatastore.New(t)
+	_, err := ds.CreateAttestedNode(context.Background(), &common.AttestedNode{
+		SpiffeId: "spiffe://domain.test/spire/agent/test/attested",
+	})
+	require.NoError(t, err)
+
+	s := New()
+	require.NoError(t, s.SetDeps(Deps{DataStore: ds}))
+	store := agentStoreClient{s: s}
+
+	// A node that has already attested cannot attest again by changing the
+	// case of the trust domain or the form of the path of its agent ID
+	for _, agentID := range []string{
+		"spiffe://domain.test/spire/agent/test/attested",
+		"spiffe://DOMAIN.TEST/spire/agent/test/attested",
+		"spiffe://domain.test/spire/agent/test/attested/",
+		"spiffe://domain.test/spire/agent//test/%61ttested",
+	} {
+		err := EnsureNotAttested(context.Background(), store, agentID)
+		assert.EqualError(t, err, "agent has already attested", agentID)
+	}
+
+	err = EnsureNotAttested(context.Background(), store, "spiffe://domain.test/spire/agent/test/notattested")
+	assert.NoError(t, err)
+}
+
 func TestIsAttested(t *testing.T) {
 	assert := assert.New(t)
 	store := fakeAgentStore{}
@@ -43,6 +74,38 @@ func TestIsAttested(t *testing.T) {
 	assert.False(attested)
 }
 
+func TestIsAttestedWhenReattesting(t *testing.T) {
+	store := fakeAgentStore{}
+
+	// The server tells the plugin which agent is attesting again
+	ctx := WithReattestingAgent(context.Background(), "spiffe://domain.test/spire/agent/test/attested")
+	md, _ := metadata.FromOutgoingContext(ctx)
+	ctx = metadata.NewIncomingContext(context.Background(), md)
+
+	for _, agentID := range []string{
+		"spiffe://domain.test/spire/agent/test/attested",
+		"spiffe://DOMAIN.TEST/spire/agent/test/attested",
+	} {
+		attested, err := IsAttested(ctx, store, agentID)
+		assert.NoError(t, err)
+		assert.False(t, attested, agentID)
+	}
+
+	// Other agents are still checked against the store
+	attested, err := IsAttested(ctx, store, "spiffe://domain.test/spire/agent/test/bad")
+	assert.EqualError(t, err, "unable to get agent info: ohno")
+	assert.False(t, attested)
+}
+
+// agentStoreClient calls the host service directly
+type agentStoreClient struct {
+	s *AgentStore
+}
+
+func (c agentStoreClient) GetAgentInfo(ctx context.Context, in *agentstorev0.GetAgentInfoRequest, dialOpts ...grpc.CallOption) (*agentstorev0.GetAgentInfoResponse, error) {
+	return c.s.GetAgentInfo(ctx, in)
+}
+
 type fakeAgentStore struct{}
 
 func (fakeAgentStore) GetAgentInfo(ctx context.Context, in *agentstorev0.GetAgentInfoRequest, dialOpts ...grpc.CallOption) (*agentstorev0.GetAgentInfoResponse, error) {
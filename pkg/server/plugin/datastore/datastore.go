@@ -29,8 +29,15 @@ type DataStore interface {
 	FetchRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error)
 	ListRegistrationEntries(context.Context, *ListRegistrationEntriesRequest) (*ListRegistrationEntriesResponse, error)
 	PruneRegistrationEntries(context.Context, *PruneRegistrationEntriesRequest) (*PruneRegistrationEntriesResponse, error)
+	UpdateEntriesFederatesWith(context.Context, *UpdateEntriesFederatesWithRequest) (*UpdateEntriesFederatesWithResponse, error)
 	UpdateRegistrationEntry(context.Context, *UpdateRegistrationEntryRequest) (*UpdateRegistrationEntryResponse, error)
 
+	// Deleted entries
+	ListDeletedRegistrationEntries(context.Context) ([]*DeletedRegistrationEntry, error)
+	PruneDeletedRegistrationEntries(ctx context.Context, deletedBefore time.Time) error
+	RestoreRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error)
+	SoftDeleteRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error)
+
 	// Nodes
 	CountAttestedNodes(context.Context) (int32, error)
 	CreateAttestedNode(context.Context, *common.AttestedNode) (*common.AttestedNode, error)
@@ -40,6 +47,7 @@ type DataStore interface {
 	UpdateAttestedNode(context.Context, *UpdateAttestedNodeRequest) (*UpdateAttestedNodeResponse, error)
 
 	// Node selectors
+	BatchSetNodeSelectors(context.Context, *BatchSetNodeSelectorsRequest) (*BatchSetNodeSelectorsResponse, error)
 	GetNodeSelectors(context.Context, *GetNodeSelectorsRequest) (*GetNodeSelectorsResponse, error)
 	ListNodeSelectors(context.Context, *ListNodeSelectorsRequest) (*ListNodeSelectorsResponse, error)
 	SetNodeSelectors(context.Context, *SetNodeSelectorsRequest) (*SetNodeSelectorsResponse, error)
@@ -91,6 +99,23 @@ type AppendBundleResponse struct {
 	Bundle *common.Bundle
 }
 
+// BatchSetNodeSelectorsRequest sets the selectors of many nodes at once. The
+// selectors of every node are replaced in a single transaction, so either all
+// of them are updated or none are.
+type BatchSetNodeSelectorsRequest struct {
+	Selectors []*NodeSelectors
+}
+
+type BatchSetNodeSelectorsResponse struct {
+}
+
+// DeletedRegistrationEntry is a soft-deleted registration entry, which can be
+// restored until it is pruned.
+type DeletedRegistrationEntry struct {
+	Entry     *common.RegistrationEntry
+	DeletedAt time.Time
+}
+
 type ByFederatesWith struct {
 	TrustDomains []string
 	Match        MatchBehavior
@@ -159,6 +184,8 @@ type ListRegistrationEntriesRequest struct {
 	// When enabled, read-only connection will be used to connect to database read instances. Some staleness of data will be observed.
 	TolerateStale   bool
 	ByFederatesWith *ByFederatesWith
+	// Only the entries with all of these metadata key/value pairs match
+	ByMetadata map[string]string
 }
 
 type ListRegistrationEntriesResponse struct {
@@ -232,6 +259,24 @@ type UpdateBundleResponse struct {
 	Bundle *common.Bundle
 }
 
+// UpdateEntriesFederatesWithRequest adds a federated trust domain to, or
+// removes it from, every registration entry matching the selectors. The
+// entries are updated in a single transaction, so either all of them are
+// updated or none are.
+type UpdateEntriesFederatesWithRequest struct {
+	BySelectors   *BySelectors
+	TrustDomainID string
+	Remove        bool
+}
+
+type UpdateEntriesFederatesWithResponse struct {
+	// Entries are the matching entries that were updated. Entries that
+	// already were in the requested state are not updated.
+	Entries []*common.RegistrationEntry
+	// Matched is the number of entries matching the selectors
+	Matched int32
+}
+
 type UpdateRegistrationEntryRequest struct {
 	Entry *common.RegistrationEntry
 	Mask  *common.RegistrationEntryMask
@@ -0,0 +1,265 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/server/plugin/datastore"
+)
+
+var (
+	// reIndexName matches the names of the indexes created by the plugin
+	reIndexName = regexp.MustCompile(`\b(?:idx|uix)_\w+`)
+
+	// reSQLiteFullScan matches the SQLite plan steps reading a table in full
+	reSQLiteFullScan = regexp.MustCompile(`^SCAN (?:TABLE )?(\w+)$`)
+
+	// rePostgreSQLFullScan matches the PostgreSQL plan steps reading a table
+	// in full
+	rePostgreSQLFullScan = regexp.MustCompile(`Seq Scan on (\w+)`)
+
+	// rePostgreSQLBindVar matches the bind variables of PostgreSQL queries
+	rePostgreSQLBindVar = regexp.MustCompile(`\$(\d+)`)
+
+	// registeredEntryTables are the tables read when listing registration
+	// entries
+	registeredEntryTables = map[string]bool{
+		"registered_entries":             true,
+		"selectors":                      true,
+		"dns_names":                      true,
+		"entry_metadata":                 true,
+		"federated_registration_entries": true,
+		"bundles":                        true,
+	}
+)
+
+// EntryListingPlan is the plan the database chooses for the query listing the
+// registration entries that match a filter.
+type EntryListingPlan struct {
+	// Query is the listing query, with the values of the filter inlined.
+	Query string
+
+	// Plan is the output of EXPLAIN for the query, one line per step.
+	Plan []string
+
+	// Indexes are the indexes used by the plan, sorted by name.
+	Indexes []string
+
+	// FullScans are the registration entry tables read in full by the plan,
+	// sorted by name.
+	FullScans []string
+}
+
+// ExplainListRegistrationEntries reports the plan the database described by
+// the plugin configuration chooses to list the registration entries that
+// match the request, to verify the listing is served by the indexes. Nothing
+// is written to the database.
+func ExplainListRegistrationEntries(hclConfiguration string, req *datastore.ListRegistrationEntriesRequest, log logrus.FieldLogger) (*EntryListingPlan, error) {
+	if req.Pagination != nil && req.Pagination.PageSize == 0 {
+		return nil, sqlError.New("cannot paginate with pagesize = 0")
+	}
+	if req.BySelectors != nil && len(req.BySelectors.Selectors) == 0 {
+		return nil, sqlError.New("cannot list by empty selector set")
+	}
+
+	cfg, err := parseConfiguration(hclConfiguration)
+	if err != nil {
+		return nil, err
+	}
+
+	dialect, err := newDialect(cfg.DatabaseType, log)
+	if err != nil {
+		return nil, err
+	}
+
+	db, _, supportsCTE, err := dialect.connect(cfg, false)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	query, args, err := buildListRegistrationEntriesQuery(cfg.DatabaseType, supportsCTE, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The values are inlined since not every database can prepare EXPLAIN
+	// statements
+	query, err = inlineQueryArgs(cfg.DatabaseType, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	explain := "EXPLAIN "
+	if cfg.DatabaseType == SQLite {
+		explain = "EXPLAIN QUERY PLAN "
+	}
+	rows, err := db.DB().Query(explain + query)
+	if err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	defer rows.Close()
+
+	plan, err := scanPlan(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EntryListingPlan{
+		Query:     query,
+		Plan:      plan,
+		Indexes:   planIndexes(plan),
+		FullScans: planFullScans(cfg.DatabaseType, plan),
+	}, nil
+}
+
+// scanPlan returns the rows of an EXPLAIN statement as text. Single column
+// rows are returned as is. SQLite rows are reduced to their detail column,
+// and the MySQL rows to the "name=value" pairs of their non-null columns.
+func scanPlan(rows *sql.Rows) ([]string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	detail := -1
+	for i, column := range columns {
+		if column == "detail" {
+			detail = i
+		}
+	}
+
+	var plan []string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, sqlError.Wrap(err)
+		}
+
+		var step string
+		switch {
+		case len(columns) == 1:
+			step = values[0].String
+		case detail >= 0:
+			step = values[detail].String
+		default:
+			var fields []string
+			for i, column := range columns {
+				if values[i].Valid {
+					fields = append(fields, fmt.Sprintf("%s=%s", column, values[i].String))
+				}
+			}
+			step = strings.Join(fields, " ")
+		}
+		plan = append(plan, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	return plan, nil
+}
+
+func planIndexes(plan []string) []string {
+	set := make(map[string]bool)
+	for _, step := range plan {
+		for _, index := range reIndexName.FindAllString(step, -1) {
+			set[index] = true
+		}
+	}
+	return sortedKeys(set)
+}
+
+func planFullScans(dbType string, plan []string) []string {
+	set := make(map[string]bool)
+	for _, step := range plan {
+		var table string
+		switch dbType {
+		case SQLite:
+			if m := reSQLiteFullScan.FindStringSubmatch(strings.TrimSpace(step)); m != nil {
+				table = m[1]
+			}
+		case PostgreSQL:
+			if m := rePostgreSQLFullScan.FindStringSubmatch(step); m != nil {
+				table = m[1]
+			}
+		case MySQL:
+			if strings.Contains(" "+step+" ", " type=ALL ") {
+				for _, field := range strings.Fields(step) {
+					if strings.HasPrefix(field, "table=") {
+						table = strings.TrimPrefix(field, "table=")
+					}
+				}
+			}
+		}
+		if registeredEntryTables[table] {
+			set[table] = true
+		}
+	}
+	return sortedKeys(set)
+}
+
+// inlineQueryArgs replaces the bind variables of the query with the literal
+// values of the arguments.
+func inlineQueryArgs(dbType, query string, args []interface{}) (string, error) {
+	literals := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case string:
+			literal := strings.ReplaceAll(arg, "'", "''")
+			if dbType == MySQL {
+				literal = strings.ReplaceAll(literal, `\`, `\\`)
+			}
+			literals = append(literals, "'"+literal+"'")
+		case int:
+			literals = append(literals, strconv.Itoa(arg))
+		case uint64:
+			literals = append(literals, strconv.FormatUint(arg, 10))
+		default:
+			return "", sqlError.New("unsupported query argument type %T", arg)
+		}
+	}
+
+	if dbType == PostgreSQL {
+		var err error
+		query = rePostgreSQLBindVar.ReplaceAllStringFunc(query, func(bindVar string) string {
+			n, _ := strconv.Atoi(bindVar[1:])
+			if n < 1 || n > len(literals) {
+				err = sqlError.New("no argument for bind variable %s", bindVar)
+				return bindVar
+			}
+			return literals[n-1]
+		})
+		return query, err
+	}
+
+	parts := strings.Split(query, "?")
+	if len(parts)-1 != len(literals) {
+		return "", sqlError.New("query has %d bind variables for %d arguments", len(parts)-1, len(literals))
+	}
+	inlined := new(strings.Builder)
+	for i, part := range parts {
+		if i > 0 {
+			inlined.WriteString(literals[i-1])
+		}
+		inlined.WriteString(part)
+	}
+	return inlined.String(), nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
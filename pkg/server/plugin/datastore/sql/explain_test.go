@@ -0,0 +1,220 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/server/plugin/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	seededEntries = 1000
+	seededParents = 10
+)
+
+func TestExplainListRegistrationEntries(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	config := seedDatastore(t, seededEntries)
+
+	bySelector := &datastore.ListRegistrationEntriesRequest{
+		BySelectors: &datastore.BySelectors{
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:7"}},
+			Match:     datastore.Subset,
+		},
+	}
+	byParentID := &datastore.ListRegistrationEntriesRequest{
+		ByParentId: wrapperspb.String("spiffe://example.org/parent/3"),
+		Pagination: &datastore.Pagination{PageSize: 50, Token: "100"},
+	}
+
+	plan, err := ExplainListRegistrationEntries(config, bySelector, log)
+	require.NoError(t, err)
+	assert.Contains(t, plan.Query, "WHERE type = 'unix' AND value = 'uid:7'")
+	assert.Contains(t, plan.Indexes, "idx_selectors_type_value_entry")
+	assert.Empty(t, plan.FullScans)
+	assertPlanStep(t, plan, "SEARCH selectors USING COVERING INDEX idx_selectors_type_value_entry")
+
+	plan, err = ExplainListRegistrationEntries(config, byParentID, log)
+	require.NoError(t, err)
+	assert.Contains(t, plan.Query, "WHERE parent_id = 'spiffe://example.org/parent/3' AND id > 100")
+	assert.Empty(t, plan.FullScans)
+	assertPlanStep(t, plan, "SEARCH registered_entries USING COVERING INDEX idx_registered_entries_parent_id")
+
+	// Listing every entry reads them all
+	plan, err = ExplainListRegistrationEntries(config, &datastore.ListRegistrationEntriesRequest{}, log)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"registered_entries"}, plan.FullScans)
+
+	// Without the listing index, the selector lookups have to visit the
+	// selector rows to get their entry IDs
+	db := openSeededDB(t, config)
+	require.NoError(t, db.Table("selectors").RemoveIndex("idx_selectors_type_value_entry").Error)
+	require.NoError(t, db.Close())
+
+	plan, err = ExplainListRegistrationEntries(config, bySelector, log)
+	require.NoError(t, err)
+	assert.NotContains(t, plan.Indexes, "idx_selectors_type_value_entry")
+	assertPlanStep(t, plan, "SEARCH selectors USING INDEX idx_selectors_type_value")
+}
+
+func TestExplainListRegistrationEntriesErrors(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	dir := t.TempDir()
+	config := fmt.Sprintf(`
+		database_type = "sqlite3"
+		connection_string = %q
+	`, filepath.Join(dir, "datastore.sqlite3"))
+
+	_, err := ExplainListRegistrationEntries(config, &datastore.ListRegistrationEntriesRequest{
+		Pagination: &datastore.Pagination{},
+	}, log)
+	require.EqualError(t, err, "datastore-sql: cannot paginate with pagesize = 0")
+
+	_, err = ExplainListRegistrationEntries(config, &datastore.ListRegistrationEntriesRequest{
+		BySelectors: &datastore.BySelectors{},
+	}, log)
+	require.EqualError(t, err, "datastore-sql: cannot list by empty selector set")
+
+	_, err = ExplainListRegistrationEntries(`database_type = "oracle"
+		connection_string = "foo"`, &datastore.ListRegistrationEntriesRequest{}, log)
+	require.EqualError(t, err, "datastore-sql: unsupported database_type: oracle")
+
+	// The database has no tables
+	_, err = ExplainListRegistrationEntries(config, &datastore.ListRegistrationEntriesRequest{}, log)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no such table")
+}
+
+func TestInlineQueryArgs(t *testing.T) {
+	args := []interface{}{"it's", `C:\?`, uint64(7), 2}
+
+	query, err := inlineQueryArgs(SQLite, "a = ? AND b = ? AND c > ? AND d = ?", args)
+	require.NoError(t, err)
+	assert.Equal(t, `a = 'it''s' AND b = 'C:\?' AND c > 7 AND d = 2`, query)
+
+	query, err = inlineQueryArgs(MySQL, "a = ? AND b = ? AND c > ? AND d = ?", args)
+	require.NoError(t, err)
+	assert.Equal(t, `a = 'it''s' AND b = 'C:\\?' AND c > 7 AND d = 2`, query)
+
+	query, err = inlineQueryArgs(PostgreSQL, "a = $1 AND b = $2 AND c > $3 AND d = $4 AND e = $1", args)
+	require.NoError(t, err)
+	assert.Equal(t, `a = 'it''s' AND b = 'C:\?' AND c > 7 AND d = 2 AND e = 'it''s'`, query)
+
+	_, err = inlineQueryArgs(SQLite, "a = ? AND b = ?", []interface{}{"a"})
+	require.EqualError(t, err, "datastore-sql: query has 2 bind variables for 1 arguments")
+
+	_, err = inlineQueryArgs(PostgreSQL, "a = $1 AND b = $2", []interface{}{"a"})
+	require.EqualError(t, err, "datastore-sql: no argument for bind variable $2")
+
+	_, err = inlineQueryArgs(SQLite, "a = ?", []interface{}{1.5})
+	require.EqualError(t, err, "datastore-sql: unsupported query argument type float64")
+}
+
+func BenchmarkListRegistrationEntries(b *testing.B) {
+	log, _ := test.NewNullLogger()
+	ds := New(log)
+	require.NoError(b, ds.Configure(seedDatastore(b, seededEntries)))
+	defer ds.closeDB()
+
+	for _, bm := range []struct {
+		name string
+		req  *datastore.ListRegistrationEntriesRequest
+	}{
+		{
+			name: "by selector",
+			req: &datastore.ListRegistrationEntriesRequest{
+				BySelectors: &datastore.BySelectors{
+					Selectors: []*common.Selector{{Type: "unix", Value: "uid:7"}, {Type: "unix", Value: "path:/bin/7"}},
+					Match:     datastore.Subset,
+				},
+			},
+		},
+		{
+			name: "by parent ID",
+			req: &datastore.ListRegistrationEntriesRequest{
+				ByParentId: wrapperspb.String("spiffe://example.org/parent/3"),
+			},
+		},
+		{
+			name: "by parent ID paged",
+			req: &datastore.ListRegistrationEntriesRequest{
+				ByParentId: wrapperspb.String("spiffe://example.org/parent/3"),
+				Pagination: &datastore.Pagination{PageSize: 10},
+			},
+		},
+	} {
+		bm := bm
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				resp, err := ds.ListRegistrationEntries(context.Background(), bm.req)
+				require.NoError(b, err)
+				require.NotEmpty(b, resp.Entries)
+			}
+		})
+	}
+}
+
+// seedDatastore creates a SQLite datastore with the given number of
+// registration entries, spread over seededParents parents, and returns its
+// configuration. Entry i has the selectors unix:uid:<i mod 100> and
+// unix:path:/bin/<i>.
+func seedDatastore(tb testing.TB, entries int) string {
+	config := fmt.Sprintf(`
+		database_type = "sqlite3"
+		connection_string = %q
+	`, filepath.Join(tb.TempDir(), "datastore.sqlite3"))
+
+	// Configuring the plugin creates the schema
+	log, _ := test.NewNullLogger()
+	ds := New(log)
+	require.NoError(tb, ds.Configure(config))
+	ds.closeDB()
+
+	db := openSeededDB(tb, config)
+	defer db.Close()
+
+	tx := db.Begin()
+	require.NoError(tb, tx.Error)
+	for i := 0; i < entries; i++ {
+		entry := &RegisteredEntry{
+			EntryID:  fmt.Sprintf("entry-%d", i),
+			SpiffeID: fmt.Sprintf("spiffe://example.org/workload/%d", i),
+			ParentID: fmt.Sprintf("spiffe://example.org/parent/%d", i%seededParents),
+			Selectors: []Selector{
+				{Type: "unix", Value: fmt.Sprintf("uid:%d", i%100)},
+				{Type: "unix", Value: fmt.Sprintf("path:/bin/%d", i)},
+			},
+		}
+		require.NoError(tb, tx.Create(entry).Error)
+	}
+	require.NoError(tb, tx.Commit().Error)
+	return config
+}
+
+func openSeededDB(tb testing.TB, config string) *gorm.DB {
+	cfg, err := parseConfiguration(config)
+	require.NoError(tb, err)
+	db, err := openSQLite3(cfg.ConnectionString)
+	require.NoError(tb, err)
+	return db
+}
+
+func assertPlanStep(t *testing.T, plan *EntryListingPlan, prefix string) {
+	for _, step := range plan.Plan {
+		// Older SQLite versions name the tables as "TABLE <name>"
+		step = strings.Replace(strings.TrimSpace(step), " TABLE ", " ", 1)
+		if strings.HasPrefix(step, prefix) {
+			return
+		}
+	}
+	assert.Failf(t, "plan step not found", "no step starts with %q in plan:\n%s", prefix, strings.Join(plan.Plan, "\n"))
+}
@@ -19,15 +19,50 @@ import (
 
 const (
 	// the latest schema version of the database in the code
-	latestSchemaVersion = 16
+	latestSchemaVersion = 20
 )
 
 var (
 	// the current code version
 	codeVersion = semver.MustParse(version.Version())
+
+	// When a new version is added an entry must be included here that knows
+	// how to bring the previous version up. The migrations are run
+	// sequentially, each in its own transaction, to move from one version to
+	// the next. The entry at index N migrates the schema from version N to
+	// version N+1.
+	migrations = []schemaMigration{
+		{description: "Drop soft-deleted records", migrate: migrateToV1},
+		{description: "Create the federated_registration_entries join table", migrate: migrateToV2},
+		{description: "Normalize the SPIFFE IDs at rest", migrate: migrateToV3},
+		{description: "Move CA certificates into the bundle data and drop the ca_certs table", migrate: migrateToV4},
+		{description: "Add the admin column to registered_entries", migrate: migrateToV5},
+		{description: "Add the downstream column to registered_entries", migrate: migrateToV6},
+		{description: "Add the expiry column to registered_entries", migrate: migrateToV7},
+		{description: "Create the dns_names table", migrate: migrateToV8},
+		{description: "Index the SPIFFE and parent IDs of registered_entries", migrate: migrateToV9},
+		{description: "Index the expiry of registered_entries", migrate: migrateToV10},
+		{description: "Index the registered entry ID of federated_registration_entries", migrate: migrateToV11},
+		{description: "Add the code_version column to migrations", migrate: migrateToV12},
+		{description: "Add the new serial number and expiration columns to attested_node_entries", migrate: migrateToV13},
+		{description: "Add the revision_number column to registered_entries", migrate: migrateToV14},
+		{description: "Index the expiration of attested_node_entries", migrate: migrateToV15},
+		{description: "Add the store_svid column to registered_entries", migrate: migrateToV16},
+		{description: "Nothing to do; the registered entry hint was dropped", migrate: migrateToV17},
+		{description: "Create the entry_metadata table", migrate: migrateToV18},
+		{description: "Create the deleted_registered_entries table", migrate: migrateToV19},
+		{description: "Index the selector and parent ID lookups of registered entry listings", migrate: migrateToV20},
+	}
 )
 
-func migrateDB(db *gorm.DB, dbType string, disableMigration bool, log logrus.FieldLogger) (err error) {
+type schemaMigration struct {
+	description string
+	migrate     func(tx *gorm.DB) error
+}
+
+// migrateDB brings the database schema up to the latest version. A non-zero
+// statementTimeout bounds the statements run by each migration transaction.
+func migrateDB(db *gorm.DB, dbType string, disableMigration bool, statementTimeout time.Duration, log logrus.FieldLogger) (err error) {
 	// The version comparison logic in this package supports only 0.x and 1.x versioning semantics.
 	// It will need to be updated prior to releasing 2.x. Ensure that we're still building a pre-2.0
 	// version before continuing, and fail if we're not.
@@ -42,7 +77,7 @@ func migrateDB(db *gorm.DB, dbType string, disableMigration bool, log logrus.Fie
 	}
 
 	if isNew {
-		return initDB(db, dbType, log)
+		return initDB(db, dbType, statementTimeout, log)
 	}
 
 	// ensure migrations table exists so we can check versioning in all cases
@@ -114,6 +149,10 @@ func migrateDB(db *gorm.DB, dbType string, disableMigration bool, log logrus.Fie
 		if err := tx.Error; err != nil {
 			return sqlError.Wrap(err)
 		}
+		if err := setStatementTimeout(tx, dbType, statementTimeout); err != nil {
+			tx.Rollback()
+			return err
+		}
 		schemaVersion, err = migrateVersion(tx, schemaVersion, log)
 		if err != nil {
 			tx.Rollback()
@@ -174,13 +213,18 @@ func isCompatibleCodeVersion(thisCodeVersion, dbCodeVersion semver.Version) bool
 	return true
 }
 
-func initDB(db *gorm.DB, dbType string, log logrus.FieldLogger) (err error) {
+func initDB(db *gorm.DB, dbType string, statementTimeout time.Duration, log logrus.FieldLogger) (err error) {
 	log.Info("Initializing new database")
 	tx := db.Begin()
 	if err := tx.Error; err != nil {
 		return sqlError.Wrap(err)
 	}
 
+	if err := setStatementTimeout(tx, dbType, statementTimeout); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	tables := []interface{}{
 		&Bundle{},
 		&AttestedNode{},
@@ -190,6 +234,8 @@ func initDB(db *gorm.DB, dbType string, log logrus.FieldLogger) (err error) {
 		&Selector{},
 		&Migration{},
 		&DNSName{},
+		&EntryMetadata{},
+		&DeletedRegisteredEntry{},
 	}
 
 	if err := tableOptionsForDialect(tx, dbType).AutoMigrate(tables...).Error; err != nil {
@@ -209,6 +255,10 @@ func initDB(db *gorm.DB, dbType string, log logrus.FieldLogger) (err error) {
 		return err
 	}
 
+	if err := addRegisteredEntryListingIndexes(tx); err != nil {
+		return err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return sqlError.Wrap(err)
 	}
@@ -226,37 +276,47 @@ func tableOptionsForDialect(tx *gorm.DB, dbType string) *gorm.DB {
 	return tx
 }
 
-func migrateVersion(tx *gorm.DB, currVersion int, log logrus.FieldLogger) (versionOut int, err error) {
-	log.WithField(telemetry.VersionInfo, currVersion).Info("Migrating version")
+// setStatementTimeout bounds how long the statements run in the transaction
+// can take, using the closest setting each database offers:
+//  - PostgreSQL aborts any statement running longer than the timeout.
+//  - MySQL has no timeout for DDL statements, so the timeout bounds how long
+//    a statement waits for table and row locks instead (rounded up to whole
+//    seconds).
+//  - SQLite bounds how long a statement waits for a locked database.
+// The MySQL and SQLite settings apply to the connection, which is why it is
+// only used on connections dedicated to migrating the schema.
+func setStatementTimeout(tx *gorm.DB, dbType string, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
 
-	// When a new version is added an entry must be included here that knows
-	// how to bring the previous version up. The migrations are run
-	// sequentially, each in its own transaction, to move from one version to
-	// the next.
-	migrations := []func(tx *gorm.DB) error{
-		migrateToV1,
-		migrateToV2,
-		migrateToV3,
-		migrateToV4,
-		migrateToV5,
-		migrateToV6,
-		migrateToV7,
-		migrateToV8,
-		migrateToV9,
-		migrateToV10,
-		migrateToV11,
-		migrateToV12,
-		migrateToV13,
-		migrateToV14,
-		migrateToV15,
-		migrateToV16,
+	var stmt string
+	switch dbType {
+	case PostgreSQL:
+		stmt = fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())
+	case MySQL:
+		seconds := int64(math.Ceil(timeout.Seconds()))
+		stmt = fmt.Sprintf("SET SESSION lock_wait_timeout = %d, innodb_lock_wait_timeout = %d", seconds, seconds)
+	case SQLite:
+		stmt = fmt.Sprintf("PRAGMA busy_timeout = %d", timeout.Milliseconds())
+	default:
+		return sqlError.New("unsupported database_type: %v", dbType)
+	}
+
+	if err := tx.Exec(stmt).Error; err != nil {
+		return sqlError.Wrap(err)
 	}
+	return nil
+}
+
+func migrateVersion(tx *gorm.DB, currVersion int, log logrus.FieldLogger) (versionOut int, err error) {
+	log.WithField(telemetry.VersionInfo, currVersion).Info("Migrating version")
 
 	if currVersion >= len(migrations) {
 		return currVersion, sqlError.New("no migration support for version %d", currVersion)
 	}
 
-	if err := migrations[currVersion](tx); err != nil {
+	if err := migrations[currVersion].migrate(tx); err != nil {
 		return currVersion, err
 	}
 
@@ -492,12 +552,37 @@ func migrateToV15(tx *gorm.DB) error {
 }
 
 func migrateToV16(tx *gorm.DB) error {
-	if err := tx.AutoMigrate(&RegisteredEntry{}).Error; err != nil {
+	if err := tx.AutoMigrate(&V16RegisteredEntry{}).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
+// migrateToV17 used to add a hint column to registered_entries. The hint was
+// dropped, but the version is kept so the later migrations keep their
+// numbers. Databases migrated while it existed keep the unused column.
+func migrateToV17(tx *gorm.DB) error {
+	return nil
+}
+
+func migrateToV18(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&EntryMetadata{}).Error; err != nil {
 		return sqlError.Wrap(err)
 	}
 	return nil
 }
 
+func migrateToV19(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&DeletedRegisteredEntry{}).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
+func migrateToV20(tx *gorm.DB) error {
+	return addRegisteredEntryListingIndexes(tx)
+}
+
 func addFederatedRegistrationEntriesRegisteredEntryIDIndex(tx *gorm.DB) error {
 	// GORM creates the federated_registration_entries implicitly with a primary
 	// key tuple (bundle_id, registered_entry_id). Unfortunately, MySQL5 does
@@ -524,6 +609,22 @@ func addAttestedNodeEntriesExpiresAtIndex(tx *gorm.DB) error {
 	return nil
 }
 
+func addRegisteredEntryListingIndexes(tx *gorm.DB) error {
+	// Registration entry listings look up the IDs of the entries with a
+	// selector, or a parent ID, paging through them in ID order. Including the
+	// entry ID in the indexes lets PostgreSQL and SQLite answer the lookups
+	// from the indexes alone, and in order, instead of visiting every
+	// matching row. The column order does not fit gorm tags, since the ID is
+	// part of the embedded Model, so the indexes are created manually.
+	if err := tx.Table("selectors").AddIndex("idx_selectors_type_value_entry", "type", "value", "registered_entry_id").Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	if err := tx.Table("registered_entries").AddIndex("idx_registered_entries_parent_id_id", "parent_id", "id").Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
 // V3Bundle holds a version 3 trust bundle
 type V3Bundle struct {
 	Model
@@ -728,6 +829,37 @@ func (V14RegisteredEntry) TableName() string {
 	return "registered_entries"
 }
 
+// V16RegisteredEntry holds a registered entity entry
+type V16RegisteredEntry struct {
+	Model
+
+	EntryID  string `gorm:"unique_index"`
+	SpiffeID string `gorm:"index"`
+	ParentID string `gorm:"index"`
+	// TTL of identities derived from this entry
+	TTL           int32
+	Selectors     []Selector
+	FederatesWith []Bundle `gorm:"many2many:federated_registration_entries;"`
+	Admin         bool
+	Downstream    bool
+	// (optional) expiry of this entry
+	Expiry int64 `gorm:"index"`
+	// (optional) DNS entries
+	DNSList []DNSName
+
+	// RevisionNumber is a counter that is incremented when the entry is
+	// updated.
+	RevisionNumber int64
+
+	// StoreSvid determines if the issued SVID is exportable to a store
+	StoreSvid bool
+}
+
+// TableName gets table name for v16 registered entry
+func (V16RegisteredEntry) TableName() string {
+	return "registered_entries"
+}
+
 type V8Selector struct {
 	Model
 
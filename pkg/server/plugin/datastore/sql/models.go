@@ -92,6 +92,9 @@ type RegisteredEntry struct {
 
 	// StoreSvid determines if the issued SVID is exportable to a store
 	StoreSvid bool
+
+	// (optional) Operational metadata, not used to match workloads
+	Metadata []EntryMetadata
 }
 
 // JoinToken holds a join token
@@ -123,6 +126,34 @@ func (DNSName) TableName() string {
 	return "dns_names"
 }
 
+// EntryMetadata holds a metadata key/value pair of a registration entry
+type EntryMetadata struct {
+	Model
+
+	RegisteredEntryID uint   `gorm:"unique_index:idx_entry_metadata_entry"`
+	Name              string `gorm:"unique_index:idx_entry_metadata_entry;index:idx_entry_metadata_name_value"`
+	Value             string `gorm:"index:idx_entry_metadata_name_value"`
+}
+
+// TableName gets table name for registration entry metadata
+func (EntryMetadata) TableName() string {
+	return "entry_metadata"
+}
+
+// DeletedRegisteredEntry holds a soft-deleted registration entry, serialized,
+// until it is restored or pruned. The time it was deleted is CreatedAt.
+type DeletedRegisteredEntry struct {
+	Model
+
+	EntryID string `gorm:"unique_index"`
+	Data    []byte `gorm:"size:16777215"` // make MySQL to use MEDIUMBLOB (max 24MB) - doesn't affect PostgreSQL/SQLite
+}
+
+// TableName gets table name for soft-deleted registration entries
+func (DeletedRegisteredEntry) TableName() string {
+	return "deleted_registered_entries"
+}
+
 // Migration holds database schema version number, and
 // the SPIRE Code version number
 type Migration struct {
@@ -0,0 +1,141 @@
+package sql
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// SchemaStatus describes the schema of a database relative to the schema
+// supported by this code.
+type SchemaStatus struct {
+	// Initialized is false if the database has no SPIRE tables yet. Such a
+	// database is created at the latest schema version with no migrations.
+	Initialized bool
+
+	// Version is the schema version of the database.
+	Version int
+
+	// CodeVersion is the version of the SPIRE Server that last updated the
+	// schema, if recorded.
+	CodeVersion string
+
+	// LatestVersion is the schema version supported by this code.
+	LatestVersion int
+
+	// Pending are the migrations needed to bring the database up to the
+	// latest schema version, in the order in which they run.
+	Pending []PendingMigration
+}
+
+// PendingMigration is a migration that has not been applied to a database.
+type PendingMigration struct {
+	// Version is the schema version the migration brings the database to.
+	Version int
+
+	// Description is a short summary of the changes made by the migration.
+	Description string
+}
+
+// InspectSchema reports the schema status of the database described by the
+// plugin configuration. Nothing is written to the database.
+func InspectSchema(hclConfiguration string, log logrus.FieldLogger) (*SchemaStatus, error) {
+	db, _, err := openSchemaDB(hclConfiguration, log)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return schemaStatus(db)
+}
+
+// MigrateSchema applies the pending migrations to the database described by
+// the plugin configuration, even if automatic migration is disabled in it,
+// and reports the resulting schema status. A non-zero statementTimeout bounds
+// the statements run by each migration (see the documentation for the
+// details of how each database type enforces it).
+func MigrateSchema(hclConfiguration string, statementTimeout time.Duration, log logrus.FieldLogger) (*SchemaStatus, error) {
+	db, dbType, err := openSchemaDB(hclConfiguration, log)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if err := migrateDB(db, dbType, false, statementTimeout, log); err != nil {
+		return nil, err
+	}
+	return schemaStatus(db)
+}
+
+// openSchemaDB opens a dedicated connection to the database, without running
+// any migration.
+func openSchemaDB(hclConfiguration string, log logrus.FieldLogger) (*gorm.DB, string, error) {
+	cfg, err := parseConfiguration(hclConfiguration)
+	if err != nil {
+		return nil, "", err
+	}
+
+	dialect, err := newDialect(cfg.DatabaseType, log)
+	if err != nil {
+		return nil, "", err
+	}
+
+	db, _, _, err := dialect.connect(cfg, false)
+	if err != nil {
+		return nil, "", err
+	}
+	db.SetLogger(gormLogger{
+		log: log.WithField(telemetry.SubsystemName, "gorm"),
+	})
+
+	// Session settings, like the statement timeout, must apply to every
+	// statement of the migrations
+	db.DB().SetMaxOpenConns(1)
+	return db, cfg.DatabaseType, nil
+}
+
+func schemaStatus(db *gorm.DB) (*SchemaStatus, error) {
+	status := &SchemaStatus{
+		LatestVersion: latestSchemaVersion,
+	}
+
+	status.Initialized = db.HasTable(&Bundle{})
+	if err := db.Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	if !status.Initialized {
+		return status, nil
+	}
+
+	// Databases created before the migrations table was introduced are at
+	// version 0
+	if db.HasTable(&Migration{}) {
+		migration := new(Migration)
+		switch err := db.First(migration).Error; {
+		case gorm.IsRecordNotFoundError(err):
+		case err != nil:
+			return nil, sqlError.Wrap(err)
+		default:
+			status.Version = migration.Version
+			status.CodeVersion = migration.CodeVersion
+		}
+	}
+
+	status.Pending = pendingMigrations(status.Version)
+	return status, nil
+}
+
+// pendingMigrations returns the migrations that bring a database at the given
+// schema version up to the latest version.
+func pendingMigrations(schemaVersion int) []PendingMigration {
+	var pending []PendingMigration
+	for version := schemaVersion; version < len(migrations); version++ {
+		pending = append(pending, PendingMigration{
+			Version:     version + 1,
+			Description: migrations[version].description,
+		})
+	}
+	return pending
+}
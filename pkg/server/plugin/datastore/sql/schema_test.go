@@ -0,0 +1,110 @@
+package sql
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectSchema(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	require.Len(t, migrations, latestSchemaVersion, "every schema version needs a migration")
+
+	// A few of the migration dumps were taken before the version was bumped
+	// in the migrations table, so they record the version they migrate from
+	recordedVersions := map[int]int{3: 2, 13: 12, 15: 14}
+
+	for dump := 0; dump < latestSchemaVersion; dump++ {
+		i, ok := recordedVersions[dump]
+		if !ok {
+			i = dump
+		}
+		t.Run(fmt.Sprintf("v%d", dump), func(t *testing.T) {
+			config := schemaTestConfig(t, migrationDump(dump))
+
+			status, err := InspectSchema(config, log)
+			require.NoError(t, err)
+			assert.True(t, status.Initialized)
+			assert.Equal(t, i, status.Version)
+			assert.Equal(t, latestSchemaVersion, status.LatestVersion)
+
+			require.Len(t, status.Pending, latestSchemaVersion-i)
+			for j, pending := range status.Pending {
+				assert.Equal(t, i+j+1, pending.Version)
+				assert.Equal(t, migrations[i+j].description, pending.Description)
+			}
+
+			// Inspecting the schema does not migrate it
+			status, err = InspectSchema(config, log)
+			require.NoError(t, err)
+			assert.Equal(t, i, status.Version)
+		})
+	}
+}
+
+func TestInspectSchemaNewDatabase(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	config := schemaTestConfig(t, "")
+
+	status, err := InspectSchema(config, log)
+	require.NoError(t, err)
+	assert.Equal(t, &SchemaStatus{LatestVersion: latestSchemaVersion}, status)
+}
+
+func TestInspectSchemaInvalidConfig(t *testing.T) {
+	log, _ := test.NewNullLogger()
+
+	_, err := InspectSchema(`database_type = "oracle"
+connection_string = "foo"`, log)
+	require.EqualError(t, err, "datastore-sql: unsupported database_type: oracle")
+}
+
+func TestMigrateSchema(t *testing.T) {
+	log, _ := test.NewNullLogger()
+
+	for _, tt := range []struct {
+		name string
+		dump string
+	}{
+		{name: "new database"},
+		{name: "oldest schema", dump: migrationDump(0)},
+		{name: "previous schema", dump: migrationDump(latestSchemaVersion - 1)},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			config := schemaTestConfig(t, tt.dump)
+
+			status, err := MigrateSchema(config, time.Minute, log)
+			require.NoError(t, err)
+			assert.True(t, status.Initialized)
+			assert.Equal(t, latestSchemaVersion, status.Version)
+			assert.Equal(t, codeVersion.String(), status.CodeVersion)
+			assert.Empty(t, status.Pending)
+		})
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	assert.Len(t, pendingMigrations(0), latestSchemaVersion)
+	assert.Equal(t, []PendingMigration{
+		{Version: latestSchemaVersion, Description: migrations[latestSchemaVersion-1].description},
+	}, pendingMigrations(latestSchemaVersion-1))
+	assert.Empty(t, pendingMigrations(latestSchemaVersion))
+	assert.Empty(t, pendingMigrations(latestSchemaVersion+1))
+}
+
+func schemaTestConfig(t *testing.T, dump string) string {
+	dbPath := filepath.Join(t.TempDir(), "datastore.sqlite3")
+	if dump != "" {
+		require.NoError(t, dumpDB(dbPath, dump))
+	}
+	return fmt.Sprintf(`
+		database_type = "sqlite3"
+		connection_string = "file://%s"
+	`, dbPath)
+}
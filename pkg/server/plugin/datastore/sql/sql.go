@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -43,6 +44,22 @@ const (
 	SQLite = "sqlite3"
 )
 
+const (
+	// Bounds of the metadata of a registration entry. Values are stored in
+	// a varchar(255) column.
+	maxEntryMetadataCount       = 16
+	maxEntryMetadataKeyLength   = 63
+	maxEntryMetadataValueLength = 255
+)
+
+const (
+	// Bounds of the statements issued by BatchSetNodeSelectors, so that
+	// large batches stay within the bind variable limits of the databases
+	// (e.g. 999 for older SQLite versions).
+	nodeSelectorsQueryBatchSize  = 500
+	nodeSelectorsInsertBatchSize = 100
+)
+
 // Configuration for the datastore.
 // Pointer values are used to distinguish between "unset" and "zero" values.
 type configuration struct {
@@ -287,6 +304,29 @@ func (ds *Plugin) SetNodeSelectors(ctx context.Context, req *datastore.SetNodeSe
 	return resp, nil
 }
 
+// BatchSetNodeSelectors sets the selectors of many nodes (agents) by SPIFFE
+// ID in a single transaction, deleting their old selectors first
+func (ds *Plugin) BatchSetNodeSelectors(ctx context.Context, req *datastore.BatchSetNodeSelectorsRequest) (resp *datastore.BatchSetNodeSelectorsResponse, err error) {
+	seen := make(map[string]struct{}, len(req.Selectors))
+	for _, selectors := range req.Selectors {
+		if selectors == nil {
+			return nil, errors.New("invalid request: missing selectors")
+		}
+		if _, ok := seen[selectors.SpiffeId]; ok {
+			return nil, fmt.Errorf("invalid request: duplicate selectors for %q", selectors.SpiffeId)
+		}
+		seen[selectors.SpiffeId] = struct{}{}
+	}
+
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = batchSetNodeSelectors(tx, req)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // GetNodeSelectors gets node (agent) selectors by SPIFFE ID
 func (ds *Plugin) GetNodeSelectors(ctx context.Context,
 	req *datastore.GetNodeSelectorsRequest) (resp *datastore.GetNodeSelectorsResponse, err error) {
@@ -361,6 +401,19 @@ func (ds *Plugin) UpdateRegistrationEntry(ctx context.Context,
 	return resp, nil
 }
 
+// UpdateEntriesFederatesWith adds a federated trust domain to, or removes it
+// from, the registrations matching the selectors, in a single transaction
+func (ds *Plugin) UpdateEntriesFederatesWith(ctx context.Context,
+	req *datastore.UpdateEntriesFederatesWithRequest) (resp *datastore.UpdateEntriesFederatesWithResponse, err error) {
+	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = updateEntriesFederatesWith(tx, req)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // DeleteRegistrationEntry deletes the given registration
 func (ds *Plugin) DeleteRegistrationEntry(ctx context.Context,
 	entryID string) (registrationEntry *common.RegistrationEntry, err error) {
@@ -373,6 +426,52 @@ func (ds *Plugin) DeleteRegistrationEntry(ctx context.Context,
 	return registrationEntry, nil
 }
 
+// SoftDeleteRegistrationEntry deletes the given registration, keeping a copy
+// that can be restored until it is pruned
+func (ds *Plugin) SoftDeleteRegistrationEntry(ctx context.Context,
+	entryID string) (registrationEntry *common.RegistrationEntry, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		registrationEntry, err = softDeleteRegistrationEntry(tx, entryID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return registrationEntry, nil
+}
+
+// RestoreRegistrationEntry restores a soft-deleted registration, with the
+// same entry ID
+func (ds *Plugin) RestoreRegistrationEntry(ctx context.Context,
+	entryID string) (registrationEntry *common.RegistrationEntry, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		registrationEntry, err = restoreRegistrationEntry(tx, entryID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return registrationEntry, nil
+}
+
+// ListDeletedRegistrationEntries lists the soft-deleted registrations that
+// have not been pruned yet
+func (ds *Plugin) ListDeletedRegistrationEntries(ctx context.Context) (entries []*datastore.DeletedRegistrationEntry, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		entries, err = listDeletedRegistrationEntries(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// PruneDeletedRegistrationEntries permanently deletes the soft-deleted
+// registrations deleted before the given time
+func (ds *Plugin) PruneDeletedRegistrationEntries(ctx context.Context, deletedBefore time.Time) (err error) {
+	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		return pruneDeletedRegistrationEntries(tx, deletedBefore)
+	})
+}
+
 // PruneRegistrationEntries takes a registration entry message, and deletes all entries which have expired
 // before the date in the message
 func (ds *Plugin) PruneRegistrationEntries(ctx context.Context, req *datastore.PruneRegistrationEntriesRequest) (resp *datastore.PruneRegistrationEntriesResponse, err error) {
@@ -438,12 +537,8 @@ func (ds *Plugin) PruneJoinTokens(ctx context.Context, expiry time.Time) (err er
 
 // Configure parses HCL config payload into config struct, and opens new DB based on the result
 func (ds *Plugin) Configure(hclConfiguration string) error {
-	config := &configuration{}
-	if err := hcl.Decode(config, hclConfiguration); err != nil {
-		return err
-	}
-
-	if err := config.Validate(); err != nil {
+	config, err := parseConfiguration(hclConfiguration)
+	if err != nil {
 		return err
 	}
 
@@ -465,6 +560,25 @@ func (ds *Plugin) Configure(hclConfiguration string) error {
 	return nil
 }
 
+// ValidateConfiguration validates the plugin configuration without opening
+// any database connection
+func ValidateConfiguration(hclConfiguration string) error {
+	_, err := parseConfiguration(hclConfiguration)
+	return err
+}
+
+func parseConfiguration(hclConfiguration string) (*configuration, error) {
+	config := &configuration{}
+	if err := hcl.Decode(config, hclConfiguration); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
 func (ds *Plugin) openConnection(config *configuration, isReadOnly bool) error {
 	connectionString := getConnectionString(config, isReadOnly)
 	sqlDb := ds.db
@@ -615,18 +729,10 @@ func (ds *Plugin) gormToGRPCStatus(err error) error {
 }
 
 func (ds *Plugin) openDB(cfg *configuration, isReadOnly bool) (*gorm.DB, string, bool, dialect, error) {
-	var dialect dialect
-
 	ds.log.WithField(telemetry.DatabaseType, cfg.DatabaseType).Info("Opening SQL database")
-	switch cfg.DatabaseType {
-	case SQLite:
-		dialect = sqliteDB{log: ds.log}
-	case PostgreSQL:
-		dialect = postgresDB{}
-	case MySQL:
-		dialect = mysqlDB{}
-	default:
-		return nil, "", false, nil, sqlError.New("unsupported database_type: %v", cfg.DatabaseType)
+	dialect, err := newDialect(cfg.DatabaseType, ds.log)
+	if err != nil {
+		return nil, "", false, nil, err
 	}
 
 	db, version, supportsCTE, err := dialect.connect(cfg, isReadOnly)
@@ -652,7 +758,7 @@ func (ds *Plugin) openDB(cfg *configuration, isReadOnly bool) (*gorm.DB, string,
 	}
 
 	if !isReadOnly {
-		if err := migrateDB(db, cfg.DatabaseType, cfg.DisableMigration, ds.log); err != nil {
+		if err := migrateDB(db, cfg.DatabaseType, cfg.DisableMigration, 0, ds.log); err != nil {
 			db.Close()
 			return nil, "", false, nil, err
 		}
@@ -661,6 +767,19 @@ func (ds *Plugin) openDB(cfg *configuration, isReadOnly bool) (*gorm.DB, string,
 	return db, version, supportsCTE, dialect, nil
 }
 
+func newDialect(databaseType string, log logrus.FieldLogger) (dialect, error) {
+	switch databaseType {
+	case SQLite:
+		return sqliteDB{log: log}, nil
+	case PostgreSQL:
+		return postgresDB{}, nil
+	case MySQL:
+		return mysqlDB{}, nil
+	default:
+		return nil, sqlError.New("unsupported database_type: %v", databaseType)
+	}
+}
+
 type gormLogger struct {
 	log logrus.FieldLogger
 }
@@ -1569,6 +1688,75 @@ func setNodeSelectors(tx *gorm.DB, req *datastore.SetNodeSelectorsRequest) (*dat
 	return &datastore.SetNodeSelectorsResponse{}, nil
 }
 
+func batchSetNodeSelectors(tx *gorm.DB, req *datastore.BatchSetNodeSelectorsRequest) (*datastore.BatchSetNodeSelectorsResponse, error) {
+	spiffeIDs := make([]string, 0, len(req.Selectors))
+	var models []NodeSelector
+	now := time.Now()
+	for _, selectors := range req.Selectors {
+		spiffeIDs = append(spiffeIDs, selectors.SpiffeId)
+		for _, selector := range selectors.Selectors {
+			models = append(models, NodeSelector{
+				Model:    Model{CreatedAt: now, UpdatedAt: now},
+				SpiffeID: selectors.SpiffeId,
+				Type:     selector.Type,
+				Value:    selector.Value,
+			})
+		}
+	}
+
+	// The old selectors are deleted by ID, for the same reason explained in
+	// setNodeSelectors.
+	var ids []int64
+	for start := 0; start < len(spiffeIDs); start += nodeSelectorsQueryBatchSize {
+		end := minInt(start+nodeSelectorsQueryBatchSize, len(spiffeIDs))
+		var batchIDs []int64
+		if err := tx.Model(&NodeSelector{}).Where("spiffe_id IN (?)", spiffeIDs[start:end]).Pluck("id", &batchIDs).Error; err != nil {
+			return nil, sqlError.Wrap(err)
+		}
+		ids = append(ids, batchIDs...)
+	}
+	for start := 0; start < len(ids); start += nodeSelectorsQueryBatchSize {
+		end := minInt(start+nodeSelectorsQueryBatchSize, len(ids))
+		if err := tx.Where("id IN (?)", ids[start:end]).Delete(&NodeSelector{}).Error; err != nil {
+			return nil, sqlError.Wrap(err)
+		}
+	}
+
+	for start := 0; start < len(models); start += nodeSelectorsInsertBatchSize {
+		end := minInt(start+nodeSelectorsInsertBatchSize, len(models))
+		if err := insertNodeSelectors(tx, models[start:end]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &datastore.BatchSetNodeSelectorsResponse{}, nil
+}
+
+// insertNodeSelectors inserts the node selectors with a single statement
+func insertNodeSelectors(tx *gorm.DB, models []NodeSelector) error {
+	var query strings.Builder
+	query.WriteString("INSERT INTO node_resolver_map_entries (created_at, updated_at, spiffe_id, type, value) VALUES ")
+	args := make([]interface{}, 0, len(models)*5)
+	for i, model := range models {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(?, ?, ?, ?, ?)")
+		args = append(args, model.CreatedAt, model.UpdatedAt, model.SpiffeID, model.Type, model.Value)
+	}
+	if err := tx.Exec(query.String(), args...).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func getNodeSelectors(ctx context.Context, db *sqlDB, req *datastore.GetNodeSelectorsRequest) (*datastore.GetNodeSelectorsResponse, error) {
 	query := maybeRebind(db.databaseType, "SELECT type, value FROM node_resolver_map_entries WHERE spiffe_id=? ORDER BY id")
 	rows, err := db.QueryContext(ctx, query, req.SpiffeId)
@@ -1675,7 +1863,7 @@ func createRegistrationEntry(tx *gorm.DB, entry *common.RegistrationEntry) (*com
 		return nil, err
 	}
 
-	newRegisteredEntry := RegisteredEntry{
+	return insertRegistrationEntry(tx, RegisteredEntry{
 		EntryID:    entryID,
 		SpiffeID:   entry.SpiffeId,
 		ParentID:   entry.ParentId,
@@ -1683,8 +1871,12 @@ func createRegistrationEntry(tx *gorm.DB, entry *common.RegistrationEntry) (*com
 		Admin:      entry.Admin,
 		Downstream: entry.Downstream,
 		Expiry:     entry.EntryExpiry,
-	}
+	}, entry)
+}
 
+// insertRegistrationEntry inserts the model along with the selectors, DNS
+// names, federated trust domains and metadata of the entry
+func insertRegistrationEntry(tx *gorm.DB, newRegisteredEntry RegisteredEntry, entry *common.RegistrationEntry) (*common.RegistrationEntry, error) {
 	if err := tx.Create(&newRegisteredEntry).Error; err != nil {
 		return nil, sqlError.Wrap(err)
 	}
@@ -1720,6 +1912,13 @@ func createRegistrationEntry(tx *gorm.DB, entry *common.RegistrationEntry) (*com
 		}
 	}
 
+	for _, metadata := range makeEntryMetadata(entry.Metadata) {
+		metadata.RegisteredEntryID = newRegisteredEntry.ID
+		if err := tx.Create(&metadata).Error; err != nil {
+			return nil, sqlError.Wrap(err)
+		}
+	}
+
 	registrationEntry, err := modelToEntry(tx, newRegisteredEntry)
 	if err != nil {
 		return nil, err
@@ -1802,7 +2001,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -1810,7 +2012,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -1823,7 +2025,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -1831,11 +2033,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY selector_id, dns_name_id
 ;`
 	return query, []interface{}{entryID}, nil
@@ -1861,7 +2071,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -1869,7 +2082,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -1882,7 +2095,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -1890,11 +2103,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY selector_id, dns_name_id
 ;`
 	return query, []interface{}{entryID}, nil
@@ -1917,17 +2138,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.entry_id = ?
 ORDER BY selector_id, dns_name_id
 ;`
@@ -1954,7 +2180,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -1962,7 +2191,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -1975,7 +2204,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -1983,11 +2212,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY selector_id, dns_name_id
 ;`
 	return query, []interface{}{entryID}, nil
@@ -2181,7 +2418,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 `)
@@ -2192,7 +2432,7 @@ FROM
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -2207,7 +2447,7 @@ ON
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 `)
@@ -2218,7 +2458,7 @@ FROM
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 `)
@@ -2226,6 +2466,17 @@ FROM
 		builder.WriteString("WHERE registered_entry_id IN (SELECT id FROM listing)\n")
 	}
 	builder.WriteString(`
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+`)
+	if filtered {
+		builder.WriteString("WHERE registered_entry_id IN (SELECT id FROM listing)\n")
+	}
+	builder.WriteString(`
 ORDER BY e_id, selector_id, dns_name_id
 ;`)
 
@@ -2259,7 +2510,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 `)
@@ -2270,7 +2524,7 @@ FROM
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -2285,7 +2539,7 @@ ON
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 `)
@@ -2296,7 +2550,7 @@ FROM
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 `)
@@ -2304,6 +2558,17 @@ FROM
 		builder.WriteString("WHERE registered_entry_id IN (SELECT id FROM listing)\n")
 	}
 	builder.WriteString(`
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+`)
+	if filtered {
+		builder.WriteString("WHERE registered_entry_id IN (SELECT id FROM listing)\n")
+	}
+	builder.WriteString(`
 ORDER BY e_id, selector_id, dns_name_id
 ;`)
 
@@ -2341,17 +2606,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 `)
 
 	filtered, args, err := appendListRegistrationEntriesFilterQuery("WHERE E.id IN (\n", builder, MySQL, req)
@@ -2395,7 +2665,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 `)
@@ -2406,7 +2679,7 @@ FROM
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -2421,7 +2694,7 @@ ON
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 `)
@@ -2432,7 +2705,7 @@ FROM
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 `)
@@ -2440,6 +2713,17 @@ FROM
 		builder.WriteString("WHERE registered_entry_id IN (SELECT id FROM listing)\n")
 	}
 	builder.WriteString(`
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+`)
+	if filtered {
+		builder.WriteString("WHERE registered_entry_id IN (SELECT id FROM listing)\n")
+	}
+	builder.WriteString(`
 ORDER BY e_id, selector_id, dns_name_id
 ;`)
 
@@ -2620,6 +2904,22 @@ func appendListRegistrationEntriesFilterQuery(filterExp string, builder *strings
 		}
 	}
 
+	if len(req.ByMetadata) > 0 {
+		// entries must have all the given key/value pairs, so each pair is
+		// a child of the root intersection
+		keys := make([]string, 0, len(req.ByMetadata))
+		for key := range req.ByMetadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			root.children = append(root.children, idFilterNode{
+				query: []string{"SELECT registered_entry_id AS id FROM entry_metadata WHERE name = ? AND value = ?"},
+			})
+			args = append(args, key, req.ByMetadata[key])
+		}
+	}
+
 	if req.ByFederatesWith != nil && len(req.ByFederatesWith.TrustDomains) > 0 {
 		// Take the trust domains from the request without duplicates
 		tdSet := make(map[string]struct{})
@@ -2834,6 +3134,9 @@ type entryRow struct {
 	DNSNameID      sql.NullInt64
 	DNSName        sql.NullString
 	RevisionNumber sql.NullInt64
+	MetadataID     sql.NullInt64
+	MetadataName   sql.NullString
+	MetadataValue  sql.NullString
 }
 
 func scanEntryRow(rs *sql.Rows, r *entryRow) error {
@@ -2853,6 +3156,9 @@ func scanEntryRow(rs *sql.Rows, r *entryRow) error {
 		&r.DNSNameID,
 		&r.DNSName,
 		&r.RevisionNumber,
+		&r.MetadataID,
+		&r.MetadataName,
+		&r.MetadataValue,
 	))
 }
 
@@ -2899,6 +3205,16 @@ func fillEntryFromRow(entry *common.RegistrationEntry, r *entryRow) error {
 	if r.TrustDomain.Valid {
 		entry.FederatesWith = append(entry.FederatesWith, r.TrustDomain.String)
 	}
+
+	if r.MetadataName.Valid {
+		if !r.MetadataValue.Valid {
+			return sqlError.New("expected non-nil metadata.value value for entry id %s", entry.EntryId)
+		}
+		if entry.Metadata == nil {
+			entry.Metadata = make(map[string]string)
+		}
+		entry.Metadata[r.MetadataName.String] = r.MetadataValue.String
+	}
 	return nil
 }
 
@@ -2965,6 +3281,16 @@ func updateRegistrationEntry(tx *gorm.DB,
 		entry.DNSList = dnsList
 	}
 
+	// Metadata is only replaced when explicitly requested, since callers that
+	// update without a mask have no way to carry it.
+	if req.Mask != nil && req.Mask.Metadata {
+		// Delete existing metadata - we will write new ones
+		if err := tx.Exec("DELETE FROM entry_metadata WHERE registered_entry_id = ?", entry.ID).Error; err != nil {
+			return nil, sqlError.Wrap(err)
+		}
+		entry.Metadata = makeEntryMetadata(req.Entry.Metadata)
+	}
+
 	if req.Mask == nil || req.Mask.SpiffeId {
 		entry.SpiffeID = req.Entry.SpiffeId
 	}
@@ -3013,6 +3339,115 @@ func updateRegistrationEntry(tx *gorm.DB,
 	}, nil
 }
 
+func updateEntriesFederatesWith(tx *gorm.DB,
+	req *datastore.UpdateEntriesFederatesWithRequest) (*datastore.UpdateEntriesFederatesWithResponse, error) {
+	if req.BySelectors == nil || len(req.BySelectors.Selectors) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "cannot update entries by empty selector set")
+	}
+
+	bundles, err := makeFederatesWith(tx, []string{req.TrustDomainID})
+	if err != nil {
+		return nil, err
+	}
+	bundle := bundles[0]
+
+	entries, err := findEntriesBySelectors(tx, req.BySelectors)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &datastore.UpdateEntriesFederatesWithResponse{
+		Matched: int32(len(entries)),
+	}
+	for _, entry := range entries {
+		var federatesWith []*Bundle
+		if err := tx.Model(&entry).Association("FederatesWith").Find(&federatesWith).Error; err != nil {
+			return nil, sqlError.Wrap(err)
+		}
+		federated := false
+		for _, b := range federatesWith {
+			if b.ID == bundle.ID {
+				federated = true
+				break
+			}
+		}
+
+		association := tx.Model(&entry).Association("FederatesWith")
+		switch {
+		case !req.Remove && !federated:
+			association = association.Append(bundle)
+		case req.Remove && federated:
+			association = association.Delete(bundle)
+		default:
+			// Already in the requested state
+			continue
+		}
+		if err := association.Error; err != nil {
+			return nil, sqlError.Wrap(err)
+		}
+
+		// Revision number is increased by 1 on every update
+		if err := tx.Model(&entry).Update("revision_number", entry.RevisionNumber+1).Error; err != nil {
+			return nil, sqlError.Wrap(err)
+		}
+
+		updated, err := modelToEntry(tx, entry)
+		if err != nil {
+			return nil, err
+		}
+		resp.Entries = append(resp.Entries, updated)
+	}
+
+	return resp, nil
+}
+
+// findEntriesBySelectors returns the entries matching the selectors, i.e.
+// those with exactly the selectors or, for subset matching, whose selectors
+// are all among them, ordered by ID.
+func findEntriesBySelectors(tx *gorm.DB, bySelectors *datastore.BySelectors) ([]RegisteredEntry, error) {
+	// The candidates are the entries with at least one of the selectors
+	conditions := make([]string, 0, len(bySelectors.Selectors))
+	args := make([]interface{}, 0, len(bySelectors.Selectors)*2)
+	for _, s := range bySelectors.Selectors {
+		conditions = append(conditions, "(type = ? AND value = ?)")
+		args = append(args, s.Type, s.Value)
+	}
+	var ids []uint
+	if err := tx.Model(&Selector{}).Where(strings.Join(conditions, " OR "), args...).Pluck("DISTINCT registered_entry_id", &ids).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var candidates []RegisteredEntry
+	if err := tx.Where("id IN (?)", ids).Order("id").Find(&candidates).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	var entries []RegisteredEntry
+	for _, candidate := range candidates {
+		selectors, err := fetchEntrySelectors(tx, candidate)
+		if err != nil {
+			return nil, err
+		}
+
+		var matches bool
+		switch bySelectors.Match {
+		case datastore.Exact:
+			matches = sameSelectorSet(bySelectors.Selectors, selectors)
+		case datastore.Subset:
+			matches = isSelectorSubset(selectors, bySelectors.Selectors)
+		default:
+			return nil, sqlError.New("unhandled match behavior %q", bySelectors.Match)
+		}
+		if matches {
+			entries = append(entries, candidate)
+		}
+	}
+	return entries, nil
+}
+
 func deleteRegistrationEntry(tx *gorm.DB, entryID string) (*common.RegistrationEntry, error) {
 	entry := RegisteredEntry{}
 	if err := tx.Find(&entry, "entry_id = ?", entryID).Error; err != nil {
@@ -3046,9 +3481,132 @@ func deleteRegistrationEntrySupport(tx *gorm.DB, entry RegisteredEntry) error {
 		return sqlError.Wrap(err)
 	}
 
+	// Delete existing metadata
+	if err := tx.Exec("DELETE FROM entry_metadata WHERE registered_entry_id = ?", entry.ID).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+
 	return nil
 }
 
+func softDeleteRegistrationEntry(tx *gorm.DB, entryID string) (*common.RegistrationEntry, error) {
+	registrationEntry, err := deleteRegistrationEntry(tx, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := proto.Marshal(registrationEntry)
+	if err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	if err := tx.Create(&DeletedRegisteredEntry{
+		EntryID: registrationEntry.EntryId,
+		Data:    data,
+	}).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	return registrationEntry, nil
+}
+
+func restoreRegistrationEntry(tx *gorm.DB, entryID string) (*common.RegistrationEntry, error) {
+	model := DeletedRegisteredEntry{}
+	if err := tx.Find(&model, "entry_id = ?", entryID).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	entry, err := modelToDeletedEntry(model)
+	if err != nil {
+		return nil, err
+	}
+
+	// An identical entry may have been created since this one was deleted.
+	// Restoring it as well would leave duplicates behind.
+	similar, err := hasSimilarRegistrationEntry(tx, entry)
+	if err != nil {
+		return nil, err
+	}
+	if similar {
+		return nil, status.Errorf(codes.AlreadyExists, "similar entry already exists for deleted entry %q", entryID)
+	}
+
+	registrationEntry, err := insertRegistrationEntry(tx, RegisteredEntry{
+		EntryID:        entry.EntryId,
+		SpiffeID:       entry.SpiffeId,
+		ParentID:       entry.ParentId,
+		TTL:            entry.Ttl,
+		Admin:          entry.Admin,
+		Downstream:     entry.Downstream,
+		Expiry:         entry.EntryExpiry,
+		RevisionNumber: entry.RevisionNumber,
+	}, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Delete(&model).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	return registrationEntry, nil
+}
+
+// hasSimilarRegistrationEntry returns true if a registration entry with the
+// same SPIFFE ID, parent ID and selectors as the given entry exists
+func hasSimilarRegistrationEntry(tx *gorm.DB, entry *common.RegistrationEntry) (bool, error) {
+	var candidates []RegisteredEntry
+	if err := tx.Where("spiffe_id = ? AND parent_id = ?", entry.SpiffeId, entry.ParentId).Find(&candidates).Error; err != nil {
+		return false, sqlError.Wrap(err)
+	}
+
+	for _, candidate := range candidates {
+		selectors, err := fetchEntrySelectors(tx, candidate)
+		if err != nil {
+			return false, err
+		}
+		if sameSelectorSet(entry.Selectors, selectors) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func listDeletedRegistrationEntries(tx *gorm.DB) ([]*datastore.DeletedRegistrationEntry, error) {
+	var models []DeletedRegisteredEntry
+	if err := tx.Order("created_at, id").Find(&models).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	entries := make([]*datastore.DeletedRegistrationEntry, 0, len(models))
+	for _, model := range models {
+		entry, err := modelToDeletedEntry(model)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &datastore.DeletedRegistrationEntry{
+			Entry:     entry,
+			DeletedAt: model.CreatedAt,
+		})
+	}
+	return entries, nil
+}
+
+func pruneDeletedRegistrationEntries(tx *gorm.DB, deletedBefore time.Time) error {
+	if err := tx.Where("created_at < ?", deletedBefore).Delete(&DeletedRegisteredEntry{}).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
+func modelToDeletedEntry(model DeletedRegisteredEntry) (*common.RegistrationEntry, error) {
+	entry := new(common.RegistrationEntry)
+	if err := proto.Unmarshal(model.Data, entry); err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	return entry, nil
+}
+
 func pruneRegistrationEntries(tx *gorm.DB, req *datastore.PruneRegistrationEntriesRequest) (*datastore.PruneRegistrationEntriesResponse, error) {
 	var registrationEntries []RegisteredEntry
 	if err := tx.Where("expiry != 0").Where("expiry < ?", req.ExpiresBefore).Find(&registrationEntries).Error; err != nil {
@@ -3138,7 +3696,7 @@ func validateRegistrationEntry(entry *common.RegistrationEntry) error {
 		return sqlError.New("invalid registration entry: TTL is not set")
 	}
 
-	return nil
+	return validateEntryMetadata(entry.Metadata)
 }
 
 func validateRegistrationEntryForUpdate(entry *common.RegistrationEntry, mask *common.RegistrationEntryMask) error {
@@ -3161,9 +3719,109 @@ func validateRegistrationEntryForUpdate(entry *common.RegistrationEntry, mask *c
 		return sqlError.New("invalid registration entry: TTL is not set")
 	}
 
+	if mask != nil && mask.Metadata {
+		return validateEntryMetadata(entry.Metadata)
+	}
+
 	return nil
 }
 
+// validateEntryMetadata bounds the number and the size of the metadata of
+// a registration entry.
+func validateEntryMetadata(metadata map[string]string) error {
+	if len(metadata) > maxEntryMetadataCount {
+		return sqlError.New("invalid registration entry: metadata cannot have more than %d keys", maxEntryMetadataCount)
+	}
+	for key, value := range metadata {
+		switch {
+		case key == "":
+			return sqlError.New("invalid registration entry: metadata key cannot be empty")
+		case len(key) > maxEntryMetadataKeyLength:
+			return sqlError.New("invalid registration entry: metadata key %q is longer than %d bytes", key, maxEntryMetadataKeyLength)
+		case len(value) > maxEntryMetadataValueLength:
+			return sqlError.New("invalid registration entry: value of metadata key %q is longer than %d bytes", key, maxEntryMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+// makeEntryMetadata returns the models for the metadata of a registration
+// entry, sorted by key so they are always stored in the same order.
+func makeEntryMetadata(metadata map[string]string) []EntryMetadata {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	models := make([]EntryMetadata, 0, len(keys))
+	for _, key := range keys {
+		models = append(models, EntryMetadata{
+			Name:  key,
+			Value: metadata[key],
+		})
+	}
+	return models
+}
+
+func fetchEntrySelectors(tx *gorm.DB, model RegisteredEntry) ([]*common.Selector, error) {
+	var fetchedSelectors []*Selector
+	if err := tx.Model(&model).Related(&fetchedSelectors).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	selectors := make([]*common.Selector, 0, len(fetchedSelectors))
+	for _, selector := range fetchedSelectors {
+		selectors = append(selectors, &common.Selector{
+			Type:  selector.Type,
+			Value: selector.Value,
+		})
+	}
+	return selectors, nil
+}
+
+// isSelectorSubset returns true if all the selectors of a are in b.
+func isSelectorSubset(a, b []*common.Selector) bool {
+	type selectorKey struct {
+		Type  string
+		Value string
+	}
+
+	set := make(map[selectorKey]struct{}, len(b))
+	for _, s := range b {
+		set[selectorKey{Type: s.Type, Value: s.Value}] = struct{}{}
+	}
+
+	for _, s := range a {
+		if _, ok := set[selectorKey{Type: s.Type, Value: s.Value}]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func sameSelectorSet(a, b []*common.Selector) bool {
+	type selectorKey struct {
+		Type  string
+		Value string
+	}
+
+	set := make(map[selectorKey]struct{}, len(a))
+	for _, s := range a {
+		set[selectorKey{Type: s.Type, Value: s.Value}] = struct{}{}
+	}
+
+	other := make(map[selectorKey]struct{}, len(b))
+	for _, s := range b {
+		key := selectorKey{Type: s.Type, Value: s.Value}
+		if _, ok := set[key]; !ok {
+			return false
+		}
+		other[key] = struct{}{}
+	}
+	return len(set) == len(other)
+}
+
 // bundleToModel converts the given Protobuf bundle message to a database model. It
 // performs validation, and fully parses certificates to form CACert embedded models.
 func bundleToModel(pb *common.Bundle) (*Bundle, error) {
@@ -3187,17 +3845,9 @@ func bundleToModel(pb *common.Bundle) (*Bundle, error) {
 }
 
 func modelToEntry(tx *gorm.DB, model RegisteredEntry) (*common.RegistrationEntry, error) {
-	var fetchedSelectors []*Selector
-	if err := tx.Model(&model).Related(&fetchedSelectors).Error; err != nil {
-		return nil, sqlError.Wrap(err)
-	}
-
-	selectors := make([]*common.Selector, 0, len(fetchedSelectors))
-	for _, selector := range fetchedSelectors {
-		selectors = append(selectors, &common.Selector{
-			Type:  selector.Type,
-			Value: selector.Value,
-		})
+	selectors, err := fetchEntrySelectors(tx, model)
+	if err != nil {
+		return nil, err
 	}
 
 	var fetchedDNSs []*DNSName
@@ -3218,6 +3868,19 @@ func modelToEntry(tx *gorm.DB, model RegisteredEntry) (*common.RegistrationEntry
 		return nil, sqlError.Wrap(err)
 	}
 
+	var fetchedMetadata []*EntryMetadata
+	if err := tx.Model(&model).Related(&fetchedMetadata).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	var metadata map[string]string
+	if len(fetchedMetadata) > 0 {
+		metadata = make(map[string]string, len(fetchedMetadata))
+		for _, m := range fetchedMetadata {
+			metadata[m.Name] = m.Value
+		}
+	}
+
 	var federatesWith []string
 	for _, bundle := range fetchedBundles {
 		federatesWith = append(federatesWith, bundle.TrustDomain)
@@ -3235,6 +3898,7 @@ func modelToEntry(tx *gorm.DB, model RegisteredEntry) (*common.RegistrationEntry
 		EntryExpiry:    model.Expiry,
 		DnsNames:       dnsList,
 		RevisionNumber: model.RevisionNumber,
+		Metadata:       metadata,
 	}, nil
 }
 
@@ -1515,6 +1515,81 @@ func (s *PluginSuite) TestSetNodeSelectorsUnderLoad() {
 	}
 }
 
+func (s *PluginSuite) TestBatchSetNodeSelectors() {
+	s.setNodeSelectors("spiffe://example.org/node0", []*common.Selector{{Type: "OLD", Value: "0"}})
+	s.setNodeSelectors("spiffe://example.org/untouched", []*common.Selector{{Type: "OLD", Value: "1"}})
+
+	// Use enough nodes and selectors for the queries and inserts to be
+	// split into several statements.
+	req := &datastore.BatchSetNodeSelectorsRequest{}
+	expected := map[string][]*common.Selector{
+		"spiffe://example.org/untouched": {{Type: "OLD", Value: "1"}},
+	}
+	for i := 0; i < nodeSelectorsQueryBatchSize+1; i++ {
+		spiffeID := fmt.Sprintf("spiffe://example.org/node%d", i)
+		selectors := []*common.Selector{
+			{Type: "A", Value: strconv.Itoa(i)},
+			{Type: "B", Value: strconv.Itoa(i)},
+		}
+		req.Selectors = append(req.Selectors, &datastore.NodeSelectors{
+			SpiffeId:  spiffeID,
+			Selectors: selectors,
+		})
+		expected[spiffeID] = selectors
+	}
+	// Nodes without selectors are left without any
+	req.Selectors = append(req.Selectors, &datastore.NodeSelectors{
+		SpiffeId: "spiffe://example.org/empty",
+	})
+
+	resp, err := s.ds.BatchSetNodeSelectors(ctx, req)
+	s.Require().NoError(err)
+	s.Require().Equal(&datastore.BatchSetNodeSelectorsResponse{}, resp)
+
+	assertSelectorsEqual(s.T(), expected, s.listNodeSelectors(&datastore.ListNodeSelectorsRequest{}).Selectors)
+}
+
+func (s *PluginSuite) TestBatchSetNodeSelectorsIsAtomic() {
+	s.setNodeSelectors("spiffe://example.org/node1", []*common.Selector{{Type: "OLD", Value: "1"}})
+	s.setNodeSelectors("spiffe://example.org/node2", []*common.Selector{{Type: "OLD", Value: "2"}})
+
+	// The duplicated selector of the second node violates the unique index
+	// after the selectors of the first node have been replaced.
+	_, err := s.ds.BatchSetNodeSelectors(ctx, &datastore.BatchSetNodeSelectorsRequest{
+		Selectors: []*datastore.NodeSelectors{
+			{
+				SpiffeId:  "spiffe://example.org/node1",
+				Selectors: []*common.Selector{{Type: "NEW", Value: "1"}},
+			},
+			{
+				SpiffeId:  "spiffe://example.org/node2",
+				Selectors: []*common.Selector{{Type: "NEW", Value: "2"}, {Type: "NEW", Value: "2"}},
+			},
+		},
+	})
+	s.Require().Error(err)
+
+	assertSelectorsEqual(s.T(), map[string][]*common.Selector{
+		"spiffe://example.org/node1": {{Type: "OLD", Value: "1"}},
+		"spiffe://example.org/node2": {{Type: "OLD", Value: "2"}},
+	}, s.listNodeSelectors(&datastore.ListNodeSelectorsRequest{}).Selectors)
+}
+
+func (s *PluginSuite) TestBatchSetNodeSelectorsValidatesRequest() {
+	_, err := s.ds.BatchSetNodeSelectors(ctx, &datastore.BatchSetNodeSelectorsRequest{
+		Selectors: []*datastore.NodeSelectors{nil},
+	})
+	s.Require().EqualError(err, "invalid request: missing selectors")
+
+	_, err = s.ds.BatchSetNodeSelectors(ctx, &datastore.BatchSetNodeSelectorsRequest{
+		Selectors: []*datastore.NodeSelectors{
+			{SpiffeId: "spiffe://example.org/node1"},
+			{SpiffeId: "spiffe://example.org/node1"},
+		},
+	})
+	s.Require().EqualError(err, `invalid request: duplicate selectors for "spiffe://example.org/node1"`)
+}
+
 func (s *PluginSuite) TestCreateRegistrationEntry() {
 	var validRegistrationEntries []*common.RegistrationEntry
 	s.getTestDataFromJSONFile(filepath.Join("testdata", "valid_registration_entries.json"), &validRegistrationEntries)
@@ -1614,6 +1689,34 @@ func (s *PluginSuite) TestPruneRegistrationEntries() {
 	s.Nil(fetchedRegistrationEntry)
 }
 
+func (s *PluginSuite) TestPruneRegistrationEntriesKeepsEntriesWithoutExpiry() {
+	now := time.Now().Unix()
+	neverExpires := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "Type1", Value: "Value1"}},
+		SpiffeId:  "spiffe://example.org/never-expires",
+		ParentId:  "spiffe://example.org/parent",
+	})
+	expired := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors:   []*common.Selector{{Type: "Type1", Value: "Value1"}},
+		SpiffeId:    "spiffe://example.org/expired",
+		ParentId:    "spiffe://example.org/parent",
+		EntryExpiry: now - 10,
+	})
+
+	_, err := s.ds.PruneRegistrationEntries(ctx, &datastore.PruneRegistrationEntriesRequest{
+		ExpiresBefore: now,
+	})
+	s.Require().NoError(err)
+
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, neverExpires.EntryId)
+	s.Require().NoError(err)
+	s.Equal(neverExpires, fetched)
+
+	fetched, err = s.ds.FetchRegistrationEntry(ctx, expired.EntryId)
+	s.Require().NoError(err)
+	s.Nil(fetched)
+}
+
 func (s *PluginSuite) TestFetchInexistentRegistrationEntry() {
 	fetchedRegistrationEntry, err := s.ds.FetchRegistrationEntry(ctx, "INEXISTENT")
 	s.Require().NoError(err)
@@ -2021,6 +2124,161 @@ func (s *PluginSuite) TestUpdateRegistrationEntry() {
 	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
 }
 
+func (s *PluginSuite) TestRegistrationEntryMetadata() {
+	entry := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "Type1", Value: "Value1"}},
+		SpiffeId:  "spiffe://example.org/db",
+		ParentId:  "spiffe://example.org/agent",
+		Metadata:  map[string]string{"owner": "team-a", "ticket": "OPS-1"},
+	})
+	s.Equal(map[string]string{"owner": "team-a", "ticket": "OPS-1"}, entry.Metadata)
+
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.RequireProtoEqual(entry, fetched)
+
+	other := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "Type1", Value: "Value1"}},
+		SpiffeId:  "spiffe://example.org/web",
+		ParentId:  "spiffe://example.org/agent",
+		Metadata:  map[string]string{"owner": "team-a"},
+	})
+	bare := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "Type1", Value: "Value1"}},
+		SpiffeId:  "spiffe://example.org/cache",
+		ParentId:  "spiffe://example.org/agent",
+	})
+	s.Nil(bare.Metadata)
+
+	for _, tt := range []struct {
+		name          string
+		byMetadata    map[string]string
+		byParentID    string
+		expectEntries []*common.RegistrationEntry
+	}{
+		{
+			name:          "one pair",
+			byMetadata:    map[string]string{"owner": "team-a"},
+			expectEntries: []*common.RegistrationEntry{entry, other},
+		},
+		{
+			name:          "all pairs must match",
+			byMetadata:    map[string]string{"owner": "team-a", "ticket": "OPS-1"},
+			expectEntries: []*common.RegistrationEntry{entry},
+		},
+		{
+			name:       "value does not match",
+			byMetadata: map[string]string{"owner": "team-b"},
+		},
+		{
+			name:          "combined with other filters",
+			byMetadata:    map[string]string{"owner": "team-a"},
+			byParentID:    "spiffe://example.org/agent",
+			expectEntries: []*common.RegistrationEntry{entry, other},
+		},
+		{
+			name:       "combined with other filters that do not match",
+			byMetadata: map[string]string{"owner": "team-a"},
+			byParentID: "spiffe://example.org/other",
+		},
+	} {
+		tt := tt
+		for _, withPagination := range []bool{true, false} {
+			withPagination := withPagination
+			s.Run(fmt.Sprintf("%s with pagination %t", tt.name, withPagination), func() {
+				req := &datastore.ListRegistrationEntriesRequest{
+					ByMetadata: tt.byMetadata,
+				}
+				if tt.byParentID != "" {
+					req.ByParentId = &wrapperspb.StringValue{Value: tt.byParentID}
+				}
+				if withPagination {
+					req.Pagination = &datastore.Pagination{PageSize: 10}
+				}
+
+				resp, err := s.ds.ListRegistrationEntries(ctx, req)
+				s.Require().NoError(err)
+				s.RequireProtoListEqual(tt.expectEntries, resp.Entries)
+			})
+		}
+	}
+
+	// Updating the metadata replaces it
+	resp, err := s.ds.UpdateRegistrationEntry(ctx, &datastore.UpdateRegistrationEntryRequest{
+		Entry: &common.RegistrationEntry{EntryId: other.EntryId, Metadata: map[string]string{"owner": "team-b"}},
+		Mask:  &common.RegistrationEntryMask{Metadata: true},
+	})
+	s.Require().NoError(err)
+	s.Equal(map[string]string{"owner": "team-b"}, resp.Entry.Metadata)
+
+	// Deleting an entry deletes its metadata
+	_, err = s.ds.DeleteRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	var count int
+	s.Require().NoError(s.ds.db.Model(&EntryMetadata{}).Where("registered_entry_id NOT IN (SELECT id FROM registered_entries)").Count(&count).Error)
+	s.Equal(0, count)
+}
+
+func (s *PluginSuite) TestRegistrationEntryMetadataLimits() {
+	tooManyKeys := make(map[string]string)
+	for i := 0; i <= maxEntryMetadataCount; i++ {
+		tooManyKeys[fmt.Sprintf("key%d", i)] = "value"
+	}
+
+	for _, tt := range []struct {
+		name      string
+		metadata  map[string]string
+		expectErr string
+	}{
+		{
+			name:      "too many keys",
+			metadata:  tooManyKeys,
+			expectErr: "datastore-sql: invalid registration entry: metadata cannot have more than 16 keys",
+		},
+		{
+			name:      "empty key",
+			metadata:  map[string]string{"": "value"},
+			expectErr: "datastore-sql: invalid registration entry: metadata key cannot be empty",
+		},
+		{
+			name:      "key too long",
+			metadata:  map[string]string{strings.Repeat("k", maxEntryMetadataKeyLength+1): "value"},
+			expectErr: fmt.Sprintf("datastore-sql: invalid registration entry: metadata key %q is longer than 63 bytes", strings.Repeat("k", maxEntryMetadataKeyLength+1)),
+		},
+		{
+			name:      "value too long",
+			metadata:  map[string]string{"owner": strings.Repeat("v", maxEntryMetadataValueLength+1)},
+			expectErr: `datastore-sql: invalid registration entry: value of metadata key "owner" is longer than 255 bytes`,
+		},
+	} {
+		tt := tt
+		s.Run(tt.name, func() {
+			_, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+				Selectors: []*common.Selector{{Type: "Type1", Value: "Value1"}},
+				SpiffeId:  "spiffe://example.org/db",
+				ParentId:  "spiffe://example.org/agent",
+				Metadata:  tt.metadata,
+			})
+			s.RequireGRPCStatus(err, codes.Unknown, tt.expectErr)
+		})
+	}
+
+	// The largest metadata allowed is stored as is
+	metadata := make(map[string]string)
+	for i := 0; i < maxEntryMetadataCount; i++ {
+		metadata[fmt.Sprintf("%s%02d", strings.Repeat("k", maxEntryMetadataKeyLength-2), i)] = strings.Repeat("v", maxEntryMetadataValueLength)
+	}
+	entry := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "Type1", Value: "Value1"}},
+		SpiffeId:  "spiffe://example.org/db",
+		ParentId:  "spiffe://example.org/agent",
+		Metadata:  metadata,
+	})
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.Equal(metadata, fetched.Metadata)
+}
+
 func (s *PluginSuite) TestUpdateRegistrationEntryWithMask() {
 	// There are 9 fields in a registration entry. Of these, 3 have some validation in the SQL
 	// layer. In this test, we update each of the 9 fields and make sure update works, and also check
@@ -2038,6 +2296,7 @@ func (s *PluginSuite) TestUpdateRegistrationEntryWithMask() {
 		EntryExpiry:   1000,
 		DnsNames:      []string{"dns1"},
 		Downstream:    false,
+		Metadata:      map[string]string{"owner": "team-a"},
 	}
 	newEntry := &common.RegistrationEntry{
 		ParentId:      "spiffe://example.org/oldParentId",
@@ -2049,6 +2308,7 @@ func (s *PluginSuite) TestUpdateRegistrationEntryWithMask() {
 		EntryExpiry:   1000,
 		DnsNames:      []string{"dns2"},
 		Downstream:    false,
+		Metadata:      map[string]string{"owner": "team-b", "ticket": "OPS-1"},
 	}
 	badEntry := &common.RegistrationEntry{
 		ParentId:      "not a good parent id",
@@ -2175,6 +2435,30 @@ func (s *PluginSuite) TestUpdateRegistrationEntryWithMask() {
 			mask:   &common.RegistrationEntryMask{Downstream: false},
 			update: func(e *common.RegistrationEntry) { e.Downstream = newEntry.Downstream },
 			result: func(e *common.RegistrationEntry) {}},
+		// METADATA FIELD -- this field is validated so we check with good and bad data
+		{name: "Update Metadata, Good Data, Mask True",
+			mask:   &common.RegistrationEntryMask{Metadata: true},
+			update: func(e *common.RegistrationEntry) { e.Metadata = newEntry.Metadata },
+			result: func(e *common.RegistrationEntry) { e.Metadata = newEntry.Metadata }},
+		{name: "Update Metadata, Good Data, Mask False",
+			mask:   &common.RegistrationEntryMask{Metadata: false},
+			update: func(e *common.RegistrationEntry) { e.Metadata = newEntry.Metadata },
+			result: func(e *common.RegistrationEntry) {}},
+		{name: "Update Metadata, Bad Data, Mask True",
+			mask:   &common.RegistrationEntryMask{Metadata: true},
+			update: func(e *common.RegistrationEntry) { e.Metadata = map[string]string{"": "empty"} },
+			err:    errors.New("invalid registration entry: metadata key cannot be empty")},
+		{name: "Update Metadata, Bad Data, Mask False",
+			mask:   &common.RegistrationEntryMask{Metadata: false},
+			update: func(e *common.RegistrationEntry) { e.Metadata = map[string]string{"": "empty"} },
+			result: func(e *common.RegistrationEntry) {}},
+		{name: "Update Metadata, Good Data, Nil Mask",
+			mask: nil,
+			update: func(e *common.RegistrationEntry) {
+				proto.Merge(e, oldEntry)
+				e.Metadata = newEntry.Metadata
+			},
+			result: func(e *common.RegistrationEntry) {}},
 		// This should update all fields
 		{name: "Test With Nil Mask",
 			mask:   nil,
@@ -2265,6 +2549,181 @@ func (s *PluginSuite) TestDeleteRegistrationEntry() {
 	s.Require().Nil(deletedEntry)
 }
 
+func (s *PluginSuite) TestSoftDeleteRegistrationEntry() {
+	// soft-delete non-existing
+	_, err := s.ds.SoftDeleteRegistrationEntry(ctx, "badid")
+	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+
+	entry := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{
+			{Type: "Type1", Value: "Value1"},
+		},
+		SpiffeId:    "spiffe://example.org/foo",
+		ParentId:    "spiffe://example.org/bar",
+		Ttl:         1,
+		DnsNames:    []string{"foo.example.org"},
+		Metadata:    map[string]string{"team": "blue"},
+		Admin:       true,
+		EntryExpiry: 1234,
+	})
+
+	deletedEntry, err := s.ds.SoftDeleteRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.RequireProtoEqual(entry, deletedEntry)
+
+	// The entry no longer matches
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.Require().Nil(fetched)
+	resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		BySelectors: &datastore.BySelectors{
+			Selectors: entry.Selectors,
+			Match:     datastore.Subset,
+		},
+	})
+	s.Require().NoError(err)
+	s.Require().Empty(resp.Entries)
+
+	// But it is kept as a deleted entry
+	deleted, err := s.ds.ListDeletedRegistrationEntries(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(deleted, 1)
+	s.RequireProtoEqual(entry, deleted[0].Entry)
+	s.Require().False(deleted[0].DeletedAt.IsZero())
+
+	// Soft-delete again must fail with Not Found
+	_, err = s.ds.SoftDeleteRegistrationEntry(ctx, entry.EntryId)
+	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+}
+
+func (s *PluginSuite) TestRestoreRegistrationEntry() {
+	// restore non-existing
+	_, err := s.ds.RestoreRegistrationEntry(ctx, "badid")
+	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+
+	s.createBundle("spiffe://otherdomain.org")
+	entry := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{
+			{Type: "Type1", Value: "Value1"},
+			{Type: "Type2", Value: "Value2"},
+		},
+		SpiffeId:      "spiffe://example.org/foo",
+		ParentId:      "spiffe://example.org/bar",
+		Ttl:           1,
+		DnsNames:      []string{"foo.example.org"},
+		FederatesWith: []string{"spiffe://otherdomain.org"},
+		Metadata:      map[string]string{"team": "blue"},
+	})
+
+	_, err = s.ds.SoftDeleteRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+
+	// Restore within the grace period brings back the same entry
+	restored, err := s.ds.RestoreRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.RequireProtoEqual(entry, restored)
+
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.RequireProtoEqual(entry, fetched)
+
+	deleted, err := s.ds.ListDeletedRegistrationEntries(ctx)
+	s.Require().NoError(err)
+	s.Require().Empty(deleted)
+
+	// Restore again must fail with Not Found
+	_, err = s.ds.RestoreRegistrationEntry(ctx, entry.EntryId)
+	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+}
+
+func (s *PluginSuite) TestRestoreRegistrationEntrySimilarEntryExists() {
+	entry := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{
+			{Type: "Type1", Value: "Value1"},
+			{Type: "Type2", Value: "Value2"},
+		},
+		SpiffeId: "spiffe://example.org/foo",
+		ParentId: "spiffe://example.org/bar",
+		Ttl:      1,
+	})
+
+	_, err := s.ds.SoftDeleteRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+
+	// An entry with different selectors does not prevent the restore
+	s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{
+			{Type: "Type1", Value: "Value1"},
+		},
+		SpiffeId: "spiffe://example.org/foo",
+		ParentId: "spiffe://example.org/bar",
+		Ttl:      1,
+	})
+
+	// Recreate the same entry, with the selectors in a different order
+	recreated := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{
+			{Type: "Type2", Value: "Value2"},
+			{Type: "Type1", Value: "Value1"},
+		},
+		SpiffeId: "spiffe://example.org/foo",
+		ParentId: "spiffe://example.org/bar",
+		Ttl:      2,
+	})
+
+	_, err = s.ds.RestoreRegistrationEntry(ctx, entry.EntryId)
+	s.RequireGRPCStatus(err, codes.AlreadyExists, fmt.Sprintf("similar entry already exists for deleted entry %q", entry.EntryId))
+
+	// The deleted entry is kept so it can be restored once the similar
+	// entry is gone
+	deleted, err := s.ds.ListDeletedRegistrationEntries(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(deleted, 1)
+
+	_, err = s.ds.DeleteRegistrationEntry(ctx, recreated.EntryId)
+	s.Require().NoError(err)
+
+	restored, err := s.ds.RestoreRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.RequireProtoEqual(entry, restored)
+}
+
+func (s *PluginSuite) TestPruneDeletedRegistrationEntries() {
+	entry := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{
+			{Type: "Type1", Value: "Value1"},
+		},
+		SpiffeId: "spiffe://example.org/foo",
+		ParentId: "spiffe://example.org/bar",
+		Ttl:      1,
+	})
+
+	_, err := s.ds.SoftDeleteRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	deleted, err := s.ds.ListDeletedRegistrationEntries(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(deleted, 1)
+	deletedAt := deleted[0].DeletedAt
+
+	// Prune entries deleted before the entry was deleted: nothing pruned
+	err = s.ds.PruneDeletedRegistrationEntries(ctx, deletedAt.Add(-time.Second))
+	s.Require().NoError(err)
+	deleted, err = s.ds.ListDeletedRegistrationEntries(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(deleted, 1)
+
+	// Prune entries deleted after the grace period: the entry is purged and
+	// can no longer be restored
+	err = s.ds.PruneDeletedRegistrationEntries(ctx, deletedAt.Add(time.Second))
+	s.Require().NoError(err)
+	deleted, err = s.ds.ListDeletedRegistrationEntries(ctx)
+	s.Require().NoError(err)
+	s.Require().Empty(deleted)
+
+	_, err = s.ds.RestoreRegistrationEntry(ctx, entry.EntryId)
+	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+}
+
 func (s *PluginSuite) TestListParentIDEntries() {
 	allEntries := make([]*common.RegistrationEntry, 0)
 	s.getTestDataFromJSONFile(filepath.Join("testdata", "entries.json"), &allEntries)
@@ -2434,6 +2893,106 @@ func (s *PluginSuite) TestRegistrationEntriesFederatesWithSuccess() {
 	s.RequireProtoEqual(expected, actual)
 }
 
+func (s *PluginSuite) TestUpdateEntriesFederatesWith() {
+	s.createBundle("spiffe://dom1.org")
+	s.createBundle("spiffe://dom2.org")
+
+	entry1 := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "a", Value: "1"}},
+		SpiffeId:  "spiffe://example.org/entry1",
+		ParentId:  "spiffe://example.org/agent",
+	})
+	entry2 := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors:     []*common.Selector{{Type: "a", Value: "1"}, {Type: "b", Value: "2"}},
+		SpiffeId:      "spiffe://example.org/entry2",
+		ParentId:      "spiffe://example.org/agent",
+		FederatesWith: []string{"spiffe://dom1.org"},
+	})
+	entry3 := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "c", Value: "3"}},
+		SpiffeId:  "spiffe://example.org/entry3",
+		ParentId:  "spiffe://example.org/agent",
+	})
+
+	updateFederatesWith := func(trustDomainID string, remove bool, match datastore.MatchBehavior, selectors ...*common.Selector) *datastore.UpdateEntriesFederatesWithResponse {
+		resp, err := s.ds.UpdateEntriesFederatesWith(ctx, &datastore.UpdateEntriesFederatesWithRequest{
+			BySelectors: &datastore.BySelectors{
+				Selectors: selectors,
+				Match:     match,
+			},
+			TrustDomainID: trustDomainID,
+			Remove:        remove,
+		})
+		s.Require().NoError(err)
+		return resp
+	}
+	a1 := &common.Selector{Type: "a", Value: "1"}
+	b2 := &common.Selector{Type: "b", Value: "2"}
+
+	// Only the entry with exactly the selectors is updated
+	resp := updateFederatesWith("spiffe://dom2.org", false, datastore.Exact, a1, b2)
+	s.Require().Equal(int32(1), resp.Matched)
+	entry2.FederatesWith = []string{"spiffe://dom1.org", "spiffe://dom2.org"}
+	entry2.RevisionNumber++
+	s.RequireProtoListEqual([]*common.RegistrationEntry{entry2}, resp.Entries)
+
+	// The entries whose selectors are a subset of the selectors are
+	// updated, unless they already federate with the trust domain
+	resp = updateFederatesWith("spiffe://dom2.org", false, datastore.Subset, a1, b2)
+	s.Require().Equal(int32(2), resp.Matched)
+	entry1.FederatesWith = []string{"spiffe://dom2.org"}
+	entry1.RevisionNumber++
+	s.RequireProtoListEqual([]*common.RegistrationEntry{entry1}, resp.Entries)
+
+	s.RequireProtoEqual(entry1, s.fetchRegistrationEntry(entry1.EntryId))
+	s.RequireProtoEqual(entry2, s.fetchRegistrationEntry(entry2.EntryId))
+	s.RequireProtoEqual(entry3, s.fetchRegistrationEntry(entry3.EntryId))
+
+	// The trust domain is removed from the matching entries only
+	resp = updateFederatesWith("spiffe://dom1.org", true, datastore.Subset, a1, b2)
+	s.Require().Equal(int32(2), resp.Matched)
+	entry2.FederatesWith = []string{"spiffe://dom2.org"}
+	entry2.RevisionNumber++
+	s.RequireProtoListEqual([]*common.RegistrationEntry{entry2}, resp.Entries)
+
+	resp = updateFederatesWith("spiffe://dom2.org", true, datastore.Exact, a1)
+	s.Require().Equal(int32(1), resp.Matched)
+	entry1.FederatesWith = nil
+	entry1.RevisionNumber++
+	s.RequireProtoListEqual([]*common.RegistrationEntry{entry1}, resp.Entries)
+
+	s.RequireProtoEqual(entry1, s.fetchRegistrationEntry(entry1.EntryId))
+	s.RequireProtoEqual(entry2, s.fetchRegistrationEntry(entry2.EntryId))
+	s.RequireProtoEqual(entry3, s.fetchRegistrationEntry(entry3.EntryId))
+
+	// No entry matches
+	resp = updateFederatesWith("spiffe://dom2.org", false, datastore.Exact, &common.Selector{Type: "d", Value: "4"})
+	s.Require().Zero(resp.Matched)
+	s.Require().Empty(resp.Entries)
+}
+
+func (s *PluginSuite) TestUpdateEntriesFederatesWithErrors() {
+	s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "a", Value: "1"}},
+		SpiffeId:  "spiffe://example.org/entry1",
+		ParentId:  "spiffe://example.org/agent",
+	})
+
+	_, err := s.ds.UpdateEntriesFederatesWith(ctx, &datastore.UpdateEntriesFederatesWithRequest{
+		BySelectors: &datastore.BySelectors{
+			Selectors: []*common.Selector{{Type: "a", Value: "1"}},
+		},
+		TrustDomainID: "spiffe://otherdomain.org",
+	})
+	s.RequireErrorContains(err, `unable to find federated bundle "spiffe://otherdomain.org"`)
+
+	_, err = s.ds.UpdateEntriesFederatesWith(ctx, &datastore.UpdateEntriesFederatesWithRequest{
+		BySelectors:   &datastore.BySelectors{},
+		TrustDomainID: "spiffe://otherdomain.org",
+	})
+	s.RequireGRPCStatus(err, codes.InvalidArgument, "cannot update entries by empty selector set")
+}
+
 func (s *PluginSuite) TestDeleteBundleRestrictedByRegistrationEntries() {
 	// create the bundle and associated entry
 	s.createBundle("spiffe://otherdomain.org")
@@ -2790,6 +3349,16 @@ func (s *PluginSuite) TestMigration() {
 			s.Require().True(db.Dialect().HasIndex("attested_node_entries", "idx_attested_node_entries_expires_at"))
 		case 15:
 			s.Require().True(s.ds.db.Dialect().HasColumn("registered_entries", "store_svid"))
+		case 16:
+			// The registered entry hint was dropped, so no column is added
+			s.Require().False(s.ds.db.Dialect().HasColumn("registered_entries", "hint"))
+		case 17:
+			s.Require().True(s.ds.db.Dialect().HasTable("entry_metadata"))
+		case 18:
+			s.Require().True(s.ds.db.Dialect().HasTable("deleted_registered_entries"))
+		case 19:
+			s.Require().True(s.ds.db.Dialect().HasIndex("selectors", "idx_selectors_type_value_entry"))
+			s.Require().True(s.ds.db.Dialect().HasIndex("registered_entries", "idx_registered_entries_parent_id_id"))
 		default:
 			s.T().Fatalf("no migration test added for version %d", i)
 		}
@@ -2968,26 +3537,90 @@ func (s *PluginSuite) TestConfigure() {
 				rowsList = append(rowsList, rows)
 			}
 
-			// close all open queries, which results in idle connections
-			for _, rows := range rowsList {
-				require.NoError(t, rows.Close())
-			}
-			require.Equal(t, tt.expectIdle, db.Stats().Idle)
-		})
-	}
-}
+			// close all open queries, which results in idle connections
+			for _, rows := range rowsList {
+				require.NoError(t, rows.Close())
+			}
+			require.Equal(t, tt.expectIdle, db.Stats().Idle)
+		})
+	}
+}
+
+func TestListRegistrationEntriesQuery(t *testing.T) {
+	testCases := []struct {
+		dialect     string
+		paged       string
+		by          []string
+		supportsCTE bool
+		query       string
+	}{
+		{
+			dialect: "sqlite3",
+			query: `
+SELECT
+	id as e_id,
+	entry_id,
+	spiffe_id,
+	parent_id,
+	ttl AS reg_ttl,
+	admin,
+	downstream,
+	expiry,
+	NULL AS selector_id,
+	NULL AS selector_type,
+	NULL AS selector_value,
+	NULL AS trust_domain,
+	NULL AS dns_name_id,
+	NULL AS dns_name,
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
+FROM
+	registered_entries
+
+UNION
+
+SELECT
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
+FROM
+	bundles B
+INNER JOIN
+	federated_registration_entries F
+ON
+	B.id = F.bundle_id
+
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
+FROM
+	dns_names
+
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
+FROM
+	selectors
+
+UNION
 
-func TestListRegistrationEntriesQuery(t *testing.T) {
-	testCases := []struct {
-		dialect     string
-		paged       string
-		by          []string
-		supportsCTE bool
-		query       string
-	}{
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+
+ORDER BY e_id, selector_id, dns_name_id
+;`,
+		},
 		{
 			dialect: "sqlite3",
+			by:      []string{"parent-id"},
 			query: `
+WITH listing AS (
+	SELECT id FROM registered_entries WHERE parent_id = ?
+)
 SELECT
 	id as e_id,
 	entry_id,
@@ -3003,44 +3636,60 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
+WHERE id IN (SELECT id FROM listing)
 
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
 	federated_registration_entries F
 ON
 	B.id = F.bundle_id
+WHERE
+	F.registered_entry_id IN (SELECT id FROM listing)
 
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
+WHERE registered_entry_id IN (SELECT id FROM listing)
 
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
 
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
 		{
 			dialect: "sqlite3",
-			by:      []string{"parent-id"},
+			by:      []string{"metadata"},
 			query: `
 WITH listing AS (
-	SELECT id FROM registered_entries WHERE parent_id = ?
+	SELECT registered_entry_id AS id FROM entry_metadata WHERE name = ? AND value = ?
 )
 SELECT
 	id as e_id,
@@ -3057,7 +3706,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3065,7 +3717,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3078,7 +3730,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3086,11 +3738,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3116,7 +3776,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3124,7 +3787,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3137,7 +3800,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3145,11 +3808,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3175,7 +3846,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3183,7 +3857,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3196,7 +3870,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3204,11 +3878,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3234,7 +3916,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3242,7 +3927,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3255,7 +3940,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3263,11 +3948,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3297,7 +3990,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3305,7 +4001,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3318,7 +4014,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3326,11 +4022,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3356,7 +4060,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3364,7 +4071,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3377,7 +4084,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3385,11 +4092,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3419,7 +4134,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3427,7 +4145,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3440,7 +4158,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3448,11 +4166,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3482,7 +4208,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3490,7 +4219,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3503,7 +4232,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3511,11 +4240,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3549,7 +4286,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3557,7 +4297,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3570,7 +4310,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3578,11 +4318,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3612,7 +4360,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3620,7 +4371,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3633,7 +4384,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3641,11 +4392,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3677,7 +4436,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3685,7 +4447,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3698,7 +4460,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3706,11 +4468,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3736,7 +4506,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3744,7 +4517,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3757,7 +4530,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3765,11 +4538,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3795,7 +4576,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3803,7 +4587,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3816,7 +4600,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3824,11 +4608,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3855,7 +4647,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3863,7 +4658,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3876,7 +4671,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3884,11 +4679,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3919,7 +4722,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -3927,7 +4733,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3940,7 +4746,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -3948,11 +4754,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -3974,14 +4788,17 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3992,17 +4809,24 @@ ON
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4028,7 +4852,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4036,7 +4863,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4049,7 +4876,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4057,11 +4884,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4087,7 +4922,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4095,7 +4933,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4108,7 +4946,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4116,11 +4954,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4146,7 +4992,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4154,7 +5003,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4167,7 +5016,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4175,11 +5024,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4205,7 +5062,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4213,7 +5073,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4226,7 +5086,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4234,11 +5094,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4268,7 +5136,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4276,7 +5147,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4289,7 +5160,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4297,11 +5168,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4327,7 +5206,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4335,7 +5217,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4348,7 +5230,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4356,11 +5238,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4390,7 +5280,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4398,7 +5291,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4411,7 +5304,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4419,11 +5312,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4453,7 +5354,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4461,7 +5365,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4474,7 +5378,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4482,11 +5386,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4520,7 +5432,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4528,7 +5443,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4541,7 +5456,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4549,11 +5464,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4583,7 +5506,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4591,7 +5517,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4604,7 +5530,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4612,11 +5538,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4648,7 +5582,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4656,7 +5593,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4669,7 +5606,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4677,11 +5614,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4707,7 +5652,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4715,7 +5663,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4728,7 +5676,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4736,11 +5684,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4766,7 +5722,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4774,7 +5733,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4787,7 +5746,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4795,11 +5754,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4826,7 +5793,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4834,7 +5804,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4847,7 +5817,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4855,11 +5825,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4890,7 +5868,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -4898,7 +5879,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -4911,7 +5892,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -4919,11 +5900,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -4945,17 +5934,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
@@ -4979,17 +5973,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT id FROM registered_entries WHERE parent_id = ?
 )
@@ -5015,17 +6014,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT id FROM registered_entries WHERE spiffe_id = ?
 )
@@ -5051,17 +6055,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT id FROM registered_entries WHERE parent_id = ? AND spiffe_id = ?
 )
@@ -5087,17 +6096,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT registered_entry_id AS id FROM selectors WHERE type = ? AND value = ?
 )
@@ -5123,17 +6137,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT id FROM (
 		SELECT registered_entry_id AS id FROM selectors WHERE type = ? AND value = ?
@@ -5163,17 +6182,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT registered_entry_id AS id FROM selectors WHERE type = ? AND value = ?
 )
@@ -5199,17 +6223,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT DISTINCT id FROM (
 		(SELECT registered_entry_id AS id FROM selectors WHERE type = ? AND value = ?) c_0
@@ -5240,17 +6269,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT DISTINCT id FROM (
 		(SELECT id FROM registered_entries WHERE parent_id = ?) c_0
@@ -5281,17 +6315,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT DISTINCT id FROM (
 		(SELECT id FROM registered_entries WHERE parent_id = ?) c_0
@@ -5326,17 +6365,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT DISTINCT id FROM (
 		(SELECT id FROM registered_entries WHERE parent_id = ?) c_0
@@ -5367,17 +6411,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT DISTINCT id FROM (
 		(SELECT id FROM registered_entries WHERE parent_id = ?) c_0
@@ -5411,17 +6460,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT id FROM (
 		SELECT id FROM registered_entries ORDER BY id ASC LIMIT 1
@@ -5449,17 +6503,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT id FROM (
 		SELECT id FROM registered_entries WHERE id > ? ORDER BY id ASC LIMIT 1
@@ -5488,17 +6547,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT id FROM (
 		SELECT id FROM registered_entries WHERE spiffe_id = ? AND id > ? ORDER BY id ASC LIMIT 1
@@ -5527,17 +6591,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT id FROM (
 		SELECT DISTINCT id FROM (
@@ -5570,14 +6639,17 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -5588,17 +6660,24 @@ ON
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -5625,7 +6704,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -5633,7 +6715,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -5646,7 +6728,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -5654,9 +6736,17 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
+FROM
+	selectors
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
 FROM
-	selectors
+	entry_metadata
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
 ORDER BY e_id, selector_id, dns_name_id
@@ -5685,7 +6775,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -5693,7 +6786,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -5706,7 +6799,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -5714,11 +6807,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -5745,7 +6846,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -5753,7 +6857,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -5766,7 +6870,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -5774,11 +6878,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -5805,7 +6917,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -5813,7 +6928,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -5826,7 +6941,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -5834,11 +6949,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -5869,7 +6992,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -5877,7 +7003,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -5890,7 +7016,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -5898,11 +7024,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -5929,7 +7063,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -5937,7 +7074,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -5950,7 +7087,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -5958,11 +7095,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -5994,7 +7139,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6002,7 +7150,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6015,7 +7163,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6023,11 +7171,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6059,7 +7215,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6067,7 +7226,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6080,7 +7239,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6088,11 +7247,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6128,7 +7295,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6136,7 +7306,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6149,7 +7319,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6157,11 +7327,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6193,7 +7371,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6201,7 +7382,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6214,7 +7395,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6222,11 +7403,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6261,7 +7450,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6269,7 +7461,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6282,7 +7474,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6290,11 +7482,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6323,7 +7523,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6331,7 +7534,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6344,7 +7547,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6352,11 +7555,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6385,7 +7596,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6393,7 +7607,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6406,7 +7620,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6414,11 +7628,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6448,7 +7670,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6456,7 +7681,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6469,7 +7694,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6477,11 +7702,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6516,7 +7749,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6524,7 +7760,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6537,7 +7773,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6545,11 +7781,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6582,7 +7826,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6590,7 +7837,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6603,7 +7850,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6611,11 +7858,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6648,7 +7903,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6656,7 +7914,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6669,7 +7927,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6677,11 +7935,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6714,7 +7980,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6722,7 +7991,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6735,7 +8004,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6743,11 +8012,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6780,7 +8057,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6788,7 +8068,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6801,7 +8081,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6809,11 +8089,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6850,7 +8138,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6858,7 +8149,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6871,7 +8162,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6879,11 +8170,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6920,7 +8219,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6928,7 +8230,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -6941,7 +8243,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -6949,11 +8251,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -6990,7 +8300,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -6998,7 +8311,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -7011,7 +8324,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -7019,11 +8332,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -7060,7 +8381,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -7068,7 +8392,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -7081,7 +8405,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -7089,11 +8413,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -7131,7 +8463,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -7139,7 +8474,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -7152,7 +8487,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -7160,11 +8495,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -7197,7 +8540,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -7205,7 +8551,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -7218,7 +8564,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -7226,11 +8572,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -7263,7 +8617,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -7271,7 +8628,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -7284,7 +8641,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -7292,11 +8649,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -7329,7 +8694,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -7337,7 +8705,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -7350,7 +8718,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -7358,11 +8726,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -7395,7 +8771,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -7403,7 +8782,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -7416,7 +8795,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -7424,11 +8803,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -7465,7 +8852,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -7473,7 +8863,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -7486,7 +8876,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -7494,11 +8884,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -7535,7 +8933,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -7543,7 +8944,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -7556,7 +8957,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -7564,11 +8965,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -7605,7 +9014,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -7613,7 +9025,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -7626,7 +9038,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -7634,11 +9046,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -7675,7 +9095,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -7683,7 +9106,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -7696,7 +9119,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -7704,11 +9127,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -7746,7 +9177,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL ::integer AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -7754,7 +9188,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -7767,7 +9201,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -7775,11 +9209,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -7802,17 +9244,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT E.id
 	FROM registered_entries E
@@ -7845,17 +9292,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT E.id
 	FROM registered_entries E
@@ -7888,17 +9340,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT E.id
 	FROM registered_entries E
@@ -7931,17 +9388,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT E.id
 	FROM registered_entries E
@@ -7974,17 +9436,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT DISTINCT id FROM (
 		(SELECT id FROM registered_entries WHERE parent_id = ?) c_0
@@ -8022,17 +9489,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT DISTINCT id FROM (
 		(SELECT id FROM registered_entries WHERE parent_id = ?) c_0
@@ -8070,17 +9542,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT DISTINCT id FROM (
 		(SELECT id FROM registered_entries WHERE parent_id = ?) c_0
@@ -8118,17 +9595,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT DISTINCT id FROM (
 		(SELECT id FROM registered_entries WHERE parent_id = ?) c_0
@@ -8167,17 +9649,22 @@ SELECT
 	B.trust_domain,
 	D.id AS dns_name_id,
 	D.value AS dns_name,
-	E.revision_number
+	E.revision_number,
+	M.id AS metadata_id,
+	M.name AS metadata_name,
+	M.value AS metadata_value
 FROM
 	registered_entries E
 LEFT JOIN
-	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3) AS joinItems ON TRUE
+	(SELECT 1 AS joinItem UNION SELECT 2 UNION SELECT 3 UNION SELECT 4) AS joinItems ON TRUE
 LEFT JOIN
 	selectors S ON joinItem=1 AND E.id=S.registered_entry_id
 LEFT JOIN
 	dns_names D ON joinItem=2 AND E.id=D.registered_entry_id
 LEFT JOIN
 	(federated_registration_entries F INNER JOIN bundles B ON F.bundle_id=B.id) ON joinItem=3 AND E.id=F.registered_entry_id
+LEFT JOIN
+	entry_metadata M ON joinItem=4 AND E.id=M.registered_entry_id
 WHERE E.id IN (
 	SELECT id FROM (
 		SELECT DISTINCT id FROM (
@@ -8228,7 +9715,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -8236,7 +9726,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -8249,7 +9739,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -8257,11 +9747,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -8295,7 +9793,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -8303,7 +9804,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -8316,7 +9817,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -8324,11 +9825,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -8362,7 +9871,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -8370,7 +9882,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -8383,7 +9895,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -8391,11 +9903,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -8429,7 +9949,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -8437,7 +9960,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -8450,7 +9973,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -8458,11 +9981,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -8501,7 +10032,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -8509,7 +10043,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -8522,7 +10056,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -8530,11 +10064,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -8573,7 +10115,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -8581,7 +10126,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -8594,7 +10139,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -8602,11 +10147,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -8645,7 +10198,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -8653,7 +10209,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -8666,7 +10222,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -8674,11 +10230,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -8717,7 +10281,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -8725,7 +10292,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -8738,7 +10305,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -8746,11 +10313,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -8792,7 +10367,10 @@ SELECT
 	NULL AS trust_domain,
 	NULL AS dns_name_id,
 	NULL AS dns_name,
-	revision_number
+	revision_number,
+	NULL AS metadata_id,
+	NULL AS metadata_name,
+	NULL AS metadata_value
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -8800,7 +10378,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -8813,7 +10391,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -8821,11 +10399,19 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
 
+UNION
+
+SELECT
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, name, value
+FROM
+	entry_metadata
+WHERE registered_entry_id IN (SELECT id FROM listing)
+
 ORDER BY e_id, selector_id, dns_name_id
 ;`,
 		},
@@ -8872,6 +10458,8 @@ ORDER BY e_id, selector_id, dns_name_id
 					req.BySpiffeId = &wrapperspb.StringValue{
 						Value: "spiffe://id",
 					}
+				case "metadata":
+					req.ByMetadata = map[string]string{"owner": "team"}
 				case "selector-subset-one":
 					req.BySelectors = &datastore.BySelectors{
 						Selectors: []*common.Selector{{Type: "a", Value: "1"}},
@@ -0,0 +1,177 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+const (
+	// defaultCircuitBreakerCooldown is how long the circuit of a region stays
+	// open before a probe is let through
+	defaultCircuitBreakerCooldown = 30 * time.Second
+)
+
+var (
+	errCircuitOpen = errors.New("circuit breaker is open for the region")
+
+	circuitOpenedKey       = []string{"aws_iid", "circuit_breaker", "opened"}
+	circuitShortCircuitKey = []string{"aws_iid", "circuit_breaker", "short_circuit"}
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type regionCircuit struct {
+	state               circuitState
+	consecutiveFailures int
+	openedAt            time.Time
+	// probing is set while the probe of a half-open circuit is in flight
+	probing bool
+}
+
+// circuitBreaker tracks the AWS failures of each region. After threshold
+// consecutive failures, the circuit of the region opens and the calls to it
+// fail fast for the cooldown. The circuit then half-opens, letting a single
+// probe through: its success closes the circuit, its failure opens it again.
+// A nil circuitBreaker lets every call through.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	circuits  map[string]*regionCircuit
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		circuits:  make(map[string]*regionCircuit),
+	}
+}
+
+// allow returns true if a call to the region can be made.
+func (b *circuitBreaker) allow(region string, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	circuit, ok := b.circuits[region]
+	if !ok {
+		return true
+	}
+	switch circuit.state {
+	case circuitOpen:
+		if now.Sub(circuit.openedAt) < b.cooldown {
+			return false
+		}
+		circuit.state = circuitHalfOpen
+		circuit.probing = true
+		return true
+	case circuitHalfOpen:
+		if circuit.probing {
+			return false
+		}
+		circuit.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// recordSuccess closes the circuit of the region.
+func (b *circuitBreaker) recordSuccess(region string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.circuits, region)
+}
+
+// recordFailure records a failed call to the region. It returns true if the
+// failure opened the circuit.
+func (b *circuitBreaker) recordFailure(region string, now time.Time) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	circuit, ok := b.circuits[region]
+	if !ok {
+		circuit = new(regionCircuit)
+		b.circuits[region] = circuit
+	}
+	circuit.consecutiveFailures++
+	circuit.probing = false
+	if circuit.state == circuitHalfOpen || circuit.consecutiveFailures >= b.threshold {
+		circuit.state = circuitOpen
+		circuit.openedAt = now
+		return true
+	}
+	return false
+}
+
+// recordAbandoned releases the probe of a half-open circuit whose call was
+// abandoned by the caller, without telling whether the region recovered.
+func (b *circuitBreaker) recordAbandoned(region string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if circuit, ok := b.circuits[region]; ok {
+		circuit.probing = false
+	}
+}
+
+// describeInstancesInRegion describes the given instance with the client of
+// the region, unless the circuit of the region is open.
+func (p *IIDAttestorPlugin) describeInstancesInRegion(ctx context.Context, c *IIDAttestorConfig, call describeCall, client EC2Client, region, instanceID string) (*ec2.DescribeInstancesOutput, error) {
+	if !c.breaker.allow(region, p.hooks.clock.Now()) {
+		p.metrics.IncrCounterWithLabels(circuitShortCircuitKey, 1, []telemetry.Label{{Name: "region", Value: region}})
+		return nil, fmt.Errorf("%w %q", errCircuitOpen, region)
+	}
+
+	instancesDesc, err := describeInstancesWithTimeout(ctx, call, client, instanceID, c.MaxResults)
+	switch {
+	case err == nil:
+		c.breaker.recordSuccess(region)
+	case ctx.Err() != nil:
+		c.breaker.recordAbandoned(region)
+	case isRegionUnavailable(err) || isTimeout(err):
+		if c.breaker.recordFailure(region, p.hooks.clock.Now()) {
+			p.log.Warn("Opened the circuit breaker of the region after consecutive AWS failures", "region", region, "cooldown", c.circuitBreakerCooldown, "error", err)
+			p.metrics.IncrCounterWithLabels(circuitOpenedKey, 1, []telemetry.Label{{Name: "region", Value: region}})
+		}
+	default:
+		// AWS responded, so the region is available
+		c.breaker.recordSuccess(region)
+	}
+	return instancesDesc, err
+}
+
+// isTimeout returns true if the error returned by an AWS API call is due to
+// the call timing out.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && awsErr.Code() == request.CanceledErrorCode
+}
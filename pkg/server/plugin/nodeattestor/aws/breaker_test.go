@@ -0,0 +1,221 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/common/hostservice/metricsservice"
+	caws "github.com/spiffe/spire/pkg/common/plugin/aws"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/proto/spire/common/plugin"
+	metricsv0 "github.com/spiffe/spire/proto/spire/hostservice/common/metrics/v0"
+	agentstorev0 "github.com/spiffe/spire/proto/spire/hostservice/server/agentstore/v0"
+	nodeattestorv0 "github.com/spiffe/spire/proto/spire/plugin/server/nodeattestor/v0"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
+	mock_aws "github.com/spiffe/spire/test/mock/server/aws"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *IIDAttestorSuite) TestCircuitBreaker() {
+	unavailableErr := awserr.New("RequestError", "send request failed", errors.New("dial tcp: i/o timeout"))
+	// the metrics sink sanitizes the label values
+	regionLabels := []telemetry.Label{{Name: "region", Value: "test_region"}}
+	openedMetric := fakemetrics.MetricItem{Type: fakemetrics.IncrCounterWithLabelsType, Key: circuitOpenedKey, Val: 1, Labels: regionLabels}
+	shortCircuitMetric := fakemetrics.MetricItem{Type: fakemetrics.IncrCounterWithLabelsType, Key: circuitShortCircuitKey, Val: 1, Labels: regionLabels}
+
+	mockCtl := gomock.NewController(s.T())
+	defer mockCtl.Finish()
+	client := mock_aws.NewMockClient(mockCtl)
+	clk := clock.NewMock(s.T())
+	metrics := fakemetrics.New()
+
+	p := New()
+	p.hooks.getenv = func(key string) string {
+		return s.env[key]
+	}
+	p.hooks.clock = clk
+	p.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+		return client, nil
+	})
+
+	log, _ := test.NewNullLogger()
+	v0 := new(nodeattestor.V0)
+	plugintest.Load(s.T(), builtin(p), v0,
+		plugintest.HostServices(
+			agentstorev0.AgentStoreServiceServer(s.agentStore),
+			metricsv0.MetricsServiceServiceServer(metricsservice.New(metricsservice.Config{Metrics: metrics})),
+		),
+		plugintest.Log(log),
+	)
+
+	_, err := v0.NodeAttestorClient.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+skip_block_device = true
+disable_instance_profile_selectors = true
+circuit_breaker_threshold = 2
+circuit_breaker_cooldown = "1m"
+`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.Require().NoError(err)
+	p.config.awsCaCertPublicKey = &s.rsaKey.PublicKey
+
+	attest := func() (*nodeattestorv0.AttestResponse, error) {
+		stream, err := v0.NodeAttestorClient.Attest(context.Background())
+		s.Require().NoError(err)
+		defer func() {
+			s.Require().NoError(stream.CloseSend())
+		}()
+		s.Require().NoError(stream.Send(&nodeattestorv0.AttestRequest{
+			AttestationData: &common.AttestationData{
+				Type: caws.PluginName,
+				Data: s.iidAttestationDataToBytes(*s.buildDefaultIIDAttestationData()),
+			},
+		}))
+		return stream.Recv()
+	}
+
+	// the consecutive failures trip the breaker...
+	setAttestExpectations(client, nil, unavailableErr)
+	setAttestExpectations(client, nil, unavailableErr)
+	for i := 0; i < 2; i++ {
+		_, err = attest()
+		s.RequireErrorContains(err, "querying AWS via describe-instances: RequestError: send request failed")
+	}
+	s.Require().Equal([]fakemetrics.MetricItem{openedMetric}, metrics.AllMetrics())
+
+	// ...so the region is not called until the cooldown elapses
+	_, err = attest()
+	s.RequireErrorContains(err, `querying AWS via describe-instances: circuit breaker is open for the region "test-region"`)
+	s.Require().Equal([]fakemetrics.MetricItem{openedMetric, shortCircuitMetric}, metrics.AllMetrics())
+
+	// a failed probe opens the circuit again
+	clk.Add(time.Minute)
+	setAttestExpectations(client, nil, unavailableErr)
+	_, err = attest()
+	s.RequireErrorContains(err, "querying AWS via describe-instances: RequestError: send request failed")
+	_, err = attest()
+	s.RequireErrorContains(err, `circuit breaker is open for the region "test-region"`)
+	s.Require().Equal([]fakemetrics.MetricItem{openedMetric, shortCircuitMetric, openedMetric, shortCircuitMetric}, metrics.AllMetrics())
+
+	// a successful probe closes it
+	clk.Add(time.Minute)
+	setAttestExpectations(client, getDefaultDescribeInstancesOutput(), nil)
+	setAttestExpectations(client, getDefaultDescribeInstancesOutput(), nil)
+	for i := 0; i < 2; i++ {
+		resp, err := attest()
+		s.Require().NoError(err)
+		s.Require().Equal("spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance", resp.AgentId)
+	}
+	s.Require().Len(metrics.AllMetrics(), 4)
+}
+
+func (s *IIDAttestorSuite) TestCircuitBreakerFallsBack() {
+	unavailableErr := awserr.New("RequestError", "send request failed", errors.New("dial tcp: i/o timeout"))
+
+	mockCtl := gomock.NewController(s.T())
+	defer mockCtl.Finish()
+	clients := map[string]*mock_aws.MockClient{
+		testRegion:  mock_aws.NewMockClient(mockCtl),
+		"us-east-1": mock_aws.NewMockClient(mockCtl),
+	}
+	s.plugin.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+		return clients[region], nil
+	})
+
+	_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+skip_block_device = true
+disable_instance_profile_selectors = true
+fallback_regions = ["us-east-1"]
+circuit_breaker_threshold = 1
+`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.Require().NoError(err)
+	s.plugin.config.awsCaCertPublicKey = &s.rsaKey.PublicKey
+
+	// once the circuit of the region is open, the instance is described
+	// straight away in the fallback region
+	setAttestExpectations(clients[testRegion], nil, unavailableErr)
+	setAttestExpectations(clients["us-east-1"], getDefaultDescribeInstancesOutput(), nil)
+	setAttestExpectations(clients["us-east-1"], getDefaultDescribeInstancesOutput(), nil)
+	for i := 0; i < 2; i++ {
+		_, err := s.attest(&nodeattestorv0.AttestRequest{
+			AttestationData: &common.AttestationData{
+				Type: caws.PluginName,
+				Data: s.iidAttestationDataToBytes(*s.buildDefaultIIDAttestationData()),
+			},
+		})
+		s.Require().NoError(err)
+	}
+}
+
+func (s *IIDAttestorSuite) TestCircuitBreakerConfig() {
+	for _, tt := range []struct {
+		desc      string
+		config    string
+		expectErr string
+	}{
+		{
+			desc:      "negative threshold",
+			config:    "circuit_breaker_threshold = -1",
+			expectErr: "aws-iid: circuit_breaker_threshold cannot be negative",
+		},
+		{
+			desc:      "invalid cooldown",
+			config:    "circuit_breaker_threshold = 3\ncircuit_breaker_cooldown = \"soon\"",
+			expectErr: `aws-iid: invalid circuit_breaker_cooldown "soon"`,
+		},
+		{
+			desc:      "non-positive cooldown",
+			config:    "circuit_breaker_threshold = 3\ncircuit_breaker_cooldown = \"0s\"",
+			expectErr: "aws-iid: circuit_breaker_cooldown must be positive",
+		},
+	} {
+		tt := tt
+		s.T().Run(tt.desc, func(t *testing.T) {
+			_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+				Configuration: tt.config,
+				GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+			})
+			s.RequireErrorContains(err, tt.expectErr)
+		})
+	}
+
+	// the breaker is disabled by default
+	s.configure()
+	s.Require().Nil(s.plugin.config.breaker)
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(1, time.Minute)
+
+	require.True(t, b.recordFailure("us-west-2", now))
+	require.False(t, b.allow("us-west-2", now))
+	// other regions are unaffected
+	require.True(t, b.allow("us-east-1", now))
+
+	// a single probe is let through once the cooldown elapses
+	now = now.Add(time.Minute)
+	require.True(t, b.allow("us-west-2", now))
+	require.False(t, b.allow("us-west-2", now))
+
+	// an abandoned probe lets another one through
+	b.recordAbandoned("us-west-2")
+	require.True(t, b.allow("us-west-2", now))
+
+	b.recordSuccess("us-west-2")
+	require.True(t, b.allow("us-west-2", now))
+	require.True(t, b.allow("us-west-2", now))
+}
@@ -1,12 +1,18 @@
 package aws
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/sts"
 )
 
 var (
@@ -16,26 +22,59 @@ var (
 // IAMClient interface describing used aws iamclient functions, useful for mocking
 type IAMClient interface {
 	GetInstanceProfileWithContext(aws.Context, *iam.GetInstanceProfileInput, ...request.Option) (*iam.GetInstanceProfileOutput, error)
+	ListRoleTagsWithContext(aws.Context, *iam.ListRoleTagsInput, ...request.Option) (*iam.ListRoleTagsOutput, error)
 }
 
 // EC2Client interface describing used aws ec2client functions, useful for mocking
 type EC2Client interface {
 	DescribeInstancesWithContext(ctx aws.Context, input *ec2.DescribeInstancesInput, opts ...request.Option) (*ec2.DescribeInstancesOutput, error)
+	DescribeSpotInstanceRequestsWithContext(ctx aws.Context, input *ec2.DescribeSpotInstanceRequestsInput, opts ...request.Option) (*ec2.DescribeSpotInstanceRequestsOutput, error)
+	DescribeInstanceAttributeWithContext(ctx aws.Context, input *ec2.DescribeInstanceAttributeInput, opts ...request.Option) (*ec2.DescribeInstanceAttributeOutput, error)
+	DescribeInstanceStatusWithContext(ctx aws.Context, input *ec2.DescribeInstanceStatusInput, opts ...request.Option) (*ec2.DescribeInstanceStatusOutput, error)
+	DescribeTagsWithContext(ctx aws.Context, input *ec2.DescribeTagsInput, opts ...request.Option) (*ec2.DescribeTagsOutput, error)
+}
+
+// STSClient interface describing used aws stsclient functions, useful for mocking
+type STSClient interface {
+	GetCallerIdentityWithContext(aws.Context, *sts.GetCallerIdentityInput, ...request.Option) (*sts.GetCallerIdentityOutput, error)
+}
+
+// SSMClient interface describing used aws ssmclient functions, useful for mocking
+type SSMClient interface {
+	DescribeInstanceInformationWithContext(ctx aws.Context, input *ssm.DescribeInstanceInformationInput, opts ...request.Option) (*ssm.DescribeInstanceInformationOutput, error)
+	GetInventoryWithContext(ctx aws.Context, input *ssm.GetInventoryInput, opts ...request.Option) (*ssm.GetInventoryOutput, error)
 }
 
 type Client interface {
 	EC2Client
 	IAMClient
+	STSClient
+	SSMClient
+}
+
+// RegionCredential describes how to authenticate to AWS in a region. The
+// zero value uses the configured (or default) credentials.
+type RegionCredential struct {
+	// AssumeRoleARN, if set, is the ARN of the role to assume
+	AssumeRoleARN string `hcl:"assume_role_arn"`
+	// STSEndpoint, if set, is the STS endpoint used to assume the role and
+	// validate the credentials, e.g. a regional endpoint
+	STSEndpoint string `hcl:"sts_endpoint"`
+}
+
+func (rc RegionCredential) cacheKey(region string) string {
+	return region + "|" + rc.AssumeRoleARN + "|" + rc.STSEndpoint
 }
 
 type clientsCache struct {
-	mu        sync.RWMutex
-	config    *SessionConfig
-	clients   map[string]Client
-	newClient newClientCallback
+	mu                sync.RWMutex
+	config            *SessionConfig
+	regionCredentials map[string][]RegionCredential
+	clients           map[string]Client
+	newClient         newClientCallback
 }
 
-type newClientCallback func(config *SessionConfig, region string) (Client, error)
+type newClientCallback func(config *SessionConfig, region string, cred RegionCredential) (Client, error)
 
 func newClientsCache(newClient newClientCallback) *clientsCache {
 	return &clientsCache{
@@ -44,17 +83,40 @@ func newClientsCache(newClient newClientCallback) *clientsCache {
 	}
 }
 
-func (cc *clientsCache) configure(config SessionConfig) {
+func (cc *clientsCache) configure(config SessionConfig, regionCredentials map[string][]RegionCredential) {
 	cc.mu.Lock()
 	cc.clients = make(map[string]Client)
 	cc.config = &config
+	cc.regionCredentials = regionCredentials
 	cc.mu.Unlock()
 }
 
+// getClient returns a client for the given region. If a credential chain is
+// configured for the region, the client uses the first credential in the
+// chain that passes validation.
 func (cc *clientsCache) getClient(region string) (Client, error) {
+	cc.mu.RLock()
+	chain := cc.regionCredentials[region]
+	cc.mu.RUnlock()
+
+	if len(chain) == 0 {
+		return cc.getClientWithCredential(region, RegionCredential{})
+	}
+	return cc.getChainClient(region, chain)
+}
+
+// getClientWithRole returns a client for the given region that, if
+// assumeRoleARN is set, authenticates by assuming that role.
+func (cc *clientsCache) getClientWithRole(region, assumeRoleARN string) (Client, error) {
+	return cc.getClientWithCredential(region, RegionCredential{AssumeRoleARN: assumeRoleARN})
+}
+
+func (cc *clientsCache) getClientWithCredential(region string, cred RegionCredential) (Client, error) {
+	key := cred.cacheKey(region)
+
 	// do an initial check to see if p client for this region already exists
 	cc.mu.RLock()
-	client, ok := cc.clients[region]
+	client, ok := cc.clients[key]
 	cc.mu.RUnlock()
 	if ok {
 		return client, nil
@@ -67,7 +129,7 @@ func (cc *clientsCache) getClient(region string) (Client, error) {
 	// more than one thread could be racing to create p client (since we had
 	// to drop the read lock to take the write lock), so double check somebody
 	// hasn't beat us to it.
-	client, ok = cc.clients[region]
+	client, ok = cc.clients[key]
 	if ok {
 		return client, nil
 	}
@@ -76,26 +138,94 @@ func (cc *clientsCache) getClient(region string) (Client, error) {
 		return nil, iidError.New("not configured")
 	}
 
-	client, err := cc.newClient(cc.config, region)
+	client, err := cc.newClient(cc.config, region, cred)
 	if err != nil {
 		return nil, err
 	}
 
-	cc.clients[region] = client
+	cc.clients[key] = client
 	return client, nil
 }
 
-func newClient(config *SessionConfig, region string) (Client, error) {
-	sess, err := newAWSSession(config.AccessKeyID, config.SecretAccessKey, region)
+// getChainClient tries each credential in the chain until one passes
+// validation. The working client is cached for the region so the chain is
+// only walked once.
+func (cc *clientsCache) getChainClient(region string, chain []RegionCredential) (Client, error) {
+	key := region + "|chain"
+
+	cc.mu.RLock()
+	client, ok := cc.clients[key]
+	config := cc.config
+	cc.mu.RUnlock()
+	if ok {
+		return client, nil
+	}
+
+	if config == nil {
+		return nil, iidError.New("not configured")
+	}
+
+	// The lock is not held while validating since it involves a round trip
+	// to AWS. Racing callers might validate concurrently, which is harmless.
+	var errs []string
+	for i, cred := range chain {
+		client, err := cc.newClient(config, region, cred)
+		if err == nil {
+			err = validateClient(client)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("credential %d: %v", i, err))
+			continue
+		}
+
+		cc.mu.Lock()
+		defer cc.mu.Unlock()
+		if existing, ok := cc.clients[key]; ok {
+			return existing, nil
+		}
+		cc.clients[key] = client
+		return client, nil
+	}
+
+	return nil, iidError.New("no working credentials for region %q: %s", region, strings.Join(errs, "; "))
+}
+
+// validateClient makes sure the client credentials work by asking STS for
+// the caller identity, which requires no permissions.
+func validateClient(client Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), _awsTimeout)
+	defer cancel()
+
+	_, err := client.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	return err
+}
+
+func newClient(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+	sess, err := newAWSSession(config, region)
 	if err != nil {
 		return nil, iidError.Wrap(err)
 	}
 
+	var stsConfigs []*aws.Config
+	if cred.STSEndpoint != "" {
+		stsConfigs = append(stsConfigs, &aws.Config{Endpoint: aws.String(cred.STSEndpoint)})
+	}
+
+	if cred.AssumeRoleARN != "" {
+		sess = sess.Copy(&aws.Config{
+			Credentials: stscreds.NewCredentialsWithClient(sts.New(sess, stsConfigs...), cred.AssumeRoleARN),
+		})
+	}
+
 	return struct {
 		*iam.IAM
 		*ec2.EC2
+		*sts.STS
+		*ssm.SSM
 	}{
 		IAM: iam.New(sess),
 		EC2: ec2.New(sess),
+		STS: sts.New(sess, stsConfigs...),
+		SSM: ssm.New(sess),
 	}, nil
 }
@@ -0,0 +1,152 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/spiffe/spire/pkg/common/health"
+)
+
+const (
+	// defaultRegion is the region used for AWS calls that are not tied to
+	// a particular instance, like the credentials health check, unless the
+	// region is derived from the instance metadata.
+	defaultRegion = "us-east-1"
+
+	// healthCheckFreshness is how long a successful AWS call is trusted as
+	// proof that the credentials are valid before they are validated again.
+	healthCheckFreshness = 5 * time.Minute
+
+	// unhealthyThreshold is the number of consecutive credential validation
+	// failures required before the plugin reports itself not ready.
+	unhealthyThreshold = 3
+)
+
+// credentialsHealth tracks the result of AWS calls in order to detect
+// persistently failing credentials.
+type credentialsHealth struct {
+	mu                  sync.Mutex
+	lastSuccess         time.Time
+	consecutiveFailures int
+	lastErr             error
+}
+
+func (h *credentialsHealth) recordSuccess(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastSuccess = now
+	h.consecutiveFailures = 0
+	h.lastErr = nil
+}
+
+func (h *credentialsHealth) recordFailure(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.consecutiveFailures++
+	h.lastErr = err
+}
+
+func (h *credentialsHealth) succeededSince(t time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.consecutiveFailures == 0 && h.lastSuccess.After(t)
+}
+
+func (h *credentialsHealth) state() (healthy bool, lastErr error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.consecutiveFailures < unhealthyThreshold, h.lastErr
+}
+
+type credentialsHealthDetails struct {
+	ValidateCredentialsErr string `json:"validate_credentials_err,omitempty"`
+}
+
+// CheckHealth validates the configured AWS credentials with a lightweight
+// sts:GetCallerIdentity call, unless an AWS call recently succeeded. The
+// plugin is reported not ready only after persistent failures. It is always
+// reported live, since an AWS outage is not fixed by restarting the server.
+func (p *IIDAttestorPlugin) CheckHealth() health.State {
+	if _, err := p.getConfig(); err != nil {
+		return health.State{
+			Live:         true,
+			ReadyDetails: credentialsHealthDetails{ValidateCredentialsErr: err.Error()},
+		}
+	}
+
+	now := p.hooks.clock.Now()
+	if !p.health.succeededSince(now.Add(-healthCheckFreshness)) {
+		if err := p.validateCredentials(); err != nil {
+			p.log.Warn("Failed to validate AWS credentials", "error", err)
+			p.health.recordFailure(err)
+		} else {
+			p.health.recordSuccess(now)
+		}
+	}
+
+	healthy, lastErr := p.health.state()
+	details := credentialsHealthDetails{}
+	if lastErr != nil {
+		details.ValidateCredentialsErr = lastErr.Error()
+	}
+	return health.State{
+		Live:         true,
+		Ready:        healthy,
+		ReadyDetails: details,
+	}
+}
+
+// resolveDefaultRegion returns the region used for AWS calls that are not
+// tied to a particular instance. With fromIMDS, it is the region of the
+// instance the server runs on, falling back to defaultRegion if the instance
+// metadata is unavailable (e.g. when not running on EC2).
+func (p *IIDAttestorPlugin) resolveDefaultRegion(fromIMDS bool) string {
+	if !fromIMDS {
+		return defaultRegion
+	}
+
+	region, err := p.hooks.fetchIMDSRegion()
+	if err == nil && region == "" {
+		err = errors.New("region is empty")
+	}
+	if err != nil {
+		p.log.Warn("Failed to get the region from the instance metadata; using the default region", "region", defaultRegion, "error", err)
+		return defaultRegion
+	}
+	p.log.Debug("Using the region from the instance metadata as the default region", "region", region)
+	return region
+}
+
+// fetchIMDSRegion returns the region of the instance from the instance
+// metadata service. The metadata client times out quickly when the service
+// is not reachable.
+func fetchIMDSRegion() (string, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return "", err
+	}
+	return ec2metadata.New(sess).Region()
+}
+
+func (p *IIDAttestorPlugin) validateCredentials() error {
+	config, err := p.getConfig()
+	if err != nil {
+		return err
+	}
+
+	client, err := p.clients.getClient(config.defaultRegion)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), _awsTimeout)
+	defer cancel()
+
+	_, err = client.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	return err
+}
@@ -7,8 +7,10 @@ import (
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"math"
 	"os"
@@ -18,19 +20,27 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/hcl"
+	"github.com/spiffe/spire-plugin-sdk/pluginsdk"
 	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/hostservice/metricsservice"
 	caws "github.com/spiffe/spire/pkg/common/plugin/aws"
+	"github.com/spiffe/spire/pkg/common/selector"
+	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/util"
 	nodeattestorbase "github.com/spiffe/spire/pkg/server/plugin/nodeattestor/base"
 	"github.com/spiffe/spire/proto/spire/common"
 	spi "github.com/spiffe/spire/proto/spire/common/plugin"
+	metricsv0 "github.com/spiffe/spire/proto/spire/hostservice/common/metrics/v0"
 	nodeattestorv0 "github.com/spiffe/spire/proto/spire/plugin/server/nodeattestor/v0"
 )
 
@@ -50,12 +60,60 @@ var (
 
 const (
 	maxSecondsBetweenDeviceAttachments int64 = 60
+	// minMaxResults and maxMaxResults are the bounds accepted by AWS for the
+	// DescribeInstances MaxResults parameter
+	minMaxResults int64 = 5
+	maxMaxResults int64 = 1000
 	// accessKeyIDVarName env var name for AWS access key ID
 	accessKeyIDVarName = "AWS_ACCESS_KEY_ID"
 	// secretAccessKeyVarName env car name for AWS secret access key
 	secretAccessKeyVarName = "AWS_SECRET_ACCESS_KEY" //nolint: gosec // false positive
+	// spotStatus* are the status codes of the spot instance requests of spot
+	// instances that have been issued an interruption notice
+	spotStatusMarkedForTermination = "marked-for-termination"
+	spotStatusMarkedForStop        = "marked-for-stop"
+	spotStatusMarkedForHibernation = "marked-for-hibernation"
+	// defaultLaunchTimeGranularity is the granularity the launch time of
+	// the launchtime selector is truncated to by default
+	defaultLaunchTimeGranularity = time.Hour
+	// tagSelectorCategory is the category of the tag selectors
+	tagSelectorCategory = "tag"
+	// redacted replaces the secrets in the instance debug logs
+	redacted = "<redacted>"
+	// defaultInstanceNotFoundRetryInterval is the first backoff between the
+	// DescribeInstances calls that do not find the instance yet
+	defaultInstanceNotFoundRetryInterval = 500 * time.Millisecond
 )
 
+// reSecretTagKey matches the keys of the instance tags whose values are
+// redacted from the instance debug logs
+var reSecretTagKey = regexp.MustCompile(`(?i)secret|password|passwd|token|credential|private|api[-_]?key`)
+
+// selectorCategories are the categories accepted in selector_categories,
+// i.e. the prefixes of the values of the selectors this plugin generates
+var selectorCategories = []string{
+	tagSelectorCategory,
+	"sg",
+	"hostname",
+	"publichostname",
+	"vpc",
+	"owneraccount",
+	"eni",
+	"cpucount",
+	"iamrole",
+	"iamprofile",
+	"roletag",
+	"sessiontag",
+	"interruption",
+	"spotrequest",
+	"imds",
+	"capacityreservation",
+	"launchtime",
+	"userdatahash",
+	"public",
+	"ssm",
+}
+
 const awsCaCertPEM = `-----BEGIN CERTIFICATE-----
 MIIDIjCCAougAwIBAgIJAKnL4UEDMN/FMA0GCSqGSIb3DQEBBQUAMGoxCzAJBgNV
 BAYTAlVTMRMwEQYDVQQIEwpXYXNoaW5ndG9uMRAwDgYDVQQHEwdTZWF0dGxlMRgw
@@ -81,6 +139,13 @@ func BuiltIn() catalog.BuiltIn {
 	return builtin(New())
 }
 
+// BuiltInWithPlugin creates a built-in plugin backed by the given plugin
+// instance, so that the caller can keep a reference to it (e.g. to register
+// it for health checking).
+func BuiltInWithPlugin(p *IIDAttestorPlugin) catalog.BuiltIn {
+	return builtin(p)
+}
+
 func builtin(p *IIDAttestorPlugin) catalog.BuiltIn {
 	return catalog.MakeBuiltIn(caws.PluginName,
 		nodeattestorv0.NodeAttestorPluginServer(p),
@@ -94,10 +159,18 @@ type IIDAttestorPlugin struct {
 	config  *IIDAttestorConfig
 	mtx     sync.RWMutex
 	clients *clientsCache
+	health  credentialsHealth
+	metrics telemetry.Metrics
 
 	hooks struct {
 		// in test, this can be overridden to mock OS env
 		getenv func(string) string
+		clock  clock.Clock
+		// fetchIMDSRegion returns the region of the instance the server runs
+		// on, from the instance metadata service
+		fetchIMDSRegion func() (string, error)
+		// stsEndpoint is where the nonce responses are sent to be verified
+		stsEndpoint string
 	}
 	log hclog.Logger
 }
@@ -109,9 +182,123 @@ type IIDAttestorConfig struct {
 	DisableInstanceProfileSelectors bool     `hcl:"disable_instance_profile_selectors"`
 	LocalValidAcctIDs               []string `hcl:"account_ids_for_local_validation"`
 	AgentPathTemplate               string   `hcl:"agent_path_template"`
-	pathTemplate                    *template.Template
-	trustDomain                     string
-	awsCaCertPublicKey              *rsa.PublicKey
+	MaxResults                      int64    `hcl:"max_results"`
+	// AccountRoleMap maps AWS account IDs to the ARN of the role to assume
+	// when describing instance profiles owned by that account
+	AccountRoleMap map[string]string `hcl:"account_role_map"`
+	// StrictPermissions fails the attestation when the server is not
+	// authorized to describe the instance profile, instead of skipping the
+	// instance profile selectors
+	StrictPermissions bool `hcl:"strict_permissions"`
+	// PublicHostnameSelector enables the publichostname selector, which
+	// exposes the public DNS name of the instance
+	PublicHostnameSelector bool `hcl:"enable_public_hostname_selector"`
+	// SessionTagSelectors enables the sessiontag selectors, resolved from
+	// the tags of the roles in the instance profile
+	SessionTagSelectors bool `hcl:"enable_session_tag_selectors"`
+	// IncludeRoleTags enables the roletag selectors, resolved from the tags
+	// of the roles in the instance profile
+	IncludeRoleTags bool `hcl:"include_role_tags"`
+	// RejectMultipleInstances fails attestation, instead of logging a
+	// warning, when DescribeInstances returns more than one instance for the
+	// instance ID of the attesting node
+	RejectMultipleInstances bool `hcl:"reject_multiple_instances"`
+	// SpotInterruptionSelector enables the interruption selector, resolved
+	// from the spot instance request of spot instances
+	SpotInterruptionSelector bool `hcl:"enable_spot_interruption_selector"`
+	// SpotRequestSelector enables the spotrequest selector, with the ID of
+	// the spot instance request of spot instances
+	SpotRequestSelector bool `hcl:"enable_spot_request_selector"`
+	// MetadataOptionsSelectors enables the imds selectors, resolved from the
+	// instance metadata service options of the instance
+	MetadataOptionsSelectors bool `hcl:"enable_metadata_options_selectors"`
+	// CapacityReservationSelector enables the capacityreservation selector,
+	// resolved from the capacity reservation the instance runs in
+	CapacityReservationSelector bool `hcl:"enable_capacity_reservation_selector"`
+	// UserDataHashSelector enables the userdatahash selector, resolved from
+	// the user data of the instance
+	UserDataHashSelector bool `hcl:"enable_user_data_hash_selector"`
+	// LaunchTimeSelector enables the launchtime selector, with the launch
+	// time of the instance truncated to LaunchTimeGranularity
+	LaunchTimeSelector    bool   `hcl:"enable_launch_time_selector"`
+	LaunchTimeGranularity string `hcl:"launch_time_granularity"`
+	// PublicIPSelector enables the public selector, telling whether the
+	// instance has a public IPv4 address
+	PublicIPSelector bool `hcl:"enable_public_ip_selector"`
+	// SSMSelectors enables the ssm selectors, resolved from the SSM instance
+	// information and inventory of instances managed by SSM
+	SSMSelectors bool `hcl:"enable_ssm_selectors"`
+	// RegionCredentials maps AWS regions to an ordered chain of credentials.
+	// The first credential that passes validation is used for the region.
+	RegionCredentials map[string][]RegionCredential `hcl:"region_credentials"`
+	// PrewarmRegions are the regions whose clients are created, and their
+	// credentials validated, when the plugin is configured
+	PrewarmRegions []string `hcl:"prewarm_regions"`
+	// FallbackRegions are the regions, tried in order, where the instance is
+	// described when the region of the instance is unavailable
+	FallbackRegions []string `hcl:"fallback_regions"`
+	// RegionFromIMDS derives the default region, used for the AWS calls
+	// that are not tied to an instance, from the instance metadata of the
+	// server instead of using us-east-1
+	RegionFromIMDS bool `hcl:"region_from_imds"`
+	// SelectorCategories, if set, limits the selectors to those of the given
+	// categories, e.g. "tag" or "sg". If only tag selectors are requested,
+	// the tags are fetched with DescribeTags instead of describing the whole
+	// instance, whenever the instance description is not otherwise needed.
+	SelectorCategories []string `hcl:"selector_categories"`
+	// DebugLogInstances logs each described instance at debug level, with
+	// the values of the tags that look like secrets redacted. It is meant
+	// for development only.
+	DebugLogInstances bool `hcl:"debug_log_instances"`
+	// CircuitBreakerThreshold, if set, is the number of consecutive AWS
+	// failures in a region after which the instances of the region are not
+	// described for CircuitBreakerCooldown, failing fast instead
+	CircuitBreakerThreshold int    `hcl:"circuit_breaker_threshold"`
+	CircuitBreakerCooldown  string `hcl:"circuit_breaker_cooldown"`
+	// InstanceNotFoundRetryDeadline, if set, is how long DescribeInstances
+	// is retried while it does not return the instance yet, as happens right
+	// after the instance launches. The backoff between the calls starts at
+	// InstanceNotFoundRetryInterval and doubles after every call.
+	InstanceNotFoundRetryDeadline string `hcl:"instance_not_found_retry_deadline"`
+	InstanceNotFoundRetryInterval string `hcl:"instance_not_found_retry_interval"`
+	// RetryBudget, if set, is the number of retries shared by all of the
+	// AWS calls made to attest an agent, so that the retries of each call
+	// are bounded in aggregate
+	RetryBudget int `hcl:"retry_budget"`
+	// RequireNonce challenges the agent with a nonce it has to sign with the
+	// credentials of the instance profile role of the instance, so captured
+	// identity documents cannot be replayed to attest other agents
+	RequireNonce bool `hcl:"require_nonce"`
+	// TagTransforms maps instance tag keys to how their selectors are
+	// derived from the tag values, instead of the raw tag selectors
+	TagTransforms map[string]*TagTransform `hcl:"tag_transforms"`
+	// SuppressUnmappedTags drops the tag selectors of the tags without a
+	// transform in TagTransforms
+	SuppressUnmappedTags bool `hcl:"suppress_unmapped_tags"`
+	// LowercaseTagKeys lowercases the keys of the instance tags in the tag
+	// selectors, so they match regardless of the case of the tag keys. The
+	// tag values, and the tags of the agent path template, are untouched.
+	LowercaseTagKeys      bool `hcl:"lowercase_tag_keys"`
+	selectorCategories    map[string]bool
+	defaultRegion         string
+	launchTimeGranularity time.Duration
+	pathTemplate          *template.Template
+	trustDomain           string
+	awsCaCertPublicKey    *rsa.PublicKey
+
+	// circuitBreakerCooldown and breaker are only set when the circuit
+	// breaker is enabled
+	circuitBreakerCooldown time.Duration
+	breaker                *circuitBreaker
+
+	// notFoundRetryDeadline and notFoundRetryInterval are only set when
+	// the instances not found are retried
+	notFoundRetryDeadline time.Duration
+	notFoundRetryInterval time.Duration
+
+	// tagTransforms are the TagTransforms keyed by the tag key they apply
+	// to, which is lowercased with LowercaseTagKeys
+	tagTransforms map[string]*TagTransform
 }
 
 // New creates a new IIDAttestorPlugin.
@@ -119,9 +306,27 @@ func New() *IIDAttestorPlugin {
 	p := &IIDAttestorPlugin{}
 	p.clients = newClientsCache(defaultNewClientCallback)
 	p.hooks.getenv = os.Getenv
+	p.hooks.clock = clock.New()
+	p.hooks.fetchIMDSRegion = fetchIMDSRegion
+	p.hooks.stsEndpoint = caws.STSEndpoint
+	p.metrics = telemetry.Blackhole{}
 	return p
 }
 
+// BrokerHostServices brokers the AgentStore host service, required to tell
+// whether an agent has already attested, and the optional Metrics host
+// service.
+func (p *IIDAttestorPlugin) BrokerHostServices(broker pluginsdk.ServiceBroker) error {
+	if err := p.Base.BrokerHostServices(broker); err != nil {
+		return err
+	}
+	var metrics metricsv0.MetricsServiceServiceClient
+	if broker.BrokerClient(&metrics) {
+		p.metrics = metricsservice.WrapPluginMetrics(metrics, p.log)
+	}
+	return nil
+}
+
 // Attest implements the server side logic for the aws iid node attestation plugin.
 func (p *IIDAttestorPlugin) Attest(stream nodeattestorv0.NodeAttestor_AttestServer) error {
 	c, err := p.getConfig()
@@ -134,6 +339,11 @@ func (p *IIDAttestorPlugin) Attest(stream nodeattestorv0.NodeAttestor_AttestServ
 		return err
 	}
 
+	ctx := stream.Context()
+	if c.RetryBudget > 0 {
+		ctx = withRetryBudget(ctx, c.RetryBudget)
+	}
+
 	genAttestData := req.GetAttestationData()
 	if genAttestData == nil {
 		return iidError.New("request missing attestation data")
@@ -148,6 +358,12 @@ func (p *IIDAttestorPlugin) Attest(stream nodeattestorv0.NodeAttestor_AttestServ
 		return err
 	}
 
+	if c.RequireNonce {
+		if err := p.verifyNonce(stream, validDoc); err != nil {
+			return err
+		}
+	}
+
 	inTrustAcctList := false
 	for _, id := range c.LocalValidAcctIDs {
 		if validDoc.AccountID == id {
@@ -156,20 +372,24 @@ func (p *IIDAttestorPlugin) Attest(stream nodeattestorv0.NodeAttestor_AttestServ
 		}
 	}
 
-	awsClient, err := p.clients.getClient(validDoc.Region)
-	if err != nil {
-		return iidError.New("failed to get client: %w", err)
-	}
+	shouldCheckBlockDevice := !inTrustAcctList && !c.SkipBlockDevice
 
-	ctx, cancel := context.WithTimeout(stream.Context(), _awsTimeout)
-	defer cancel()
+	// The tags are enough to build the agent ID and the selectors when only
+	// tag selectors are requested, unless the block device of the instance
+	// has to be checked
+	describe := describeInstancesCall
+	if c.onlyTagSelectors() && !shouldCheckBlockDevice {
+		describe = describeTagsCall
+	}
 
-	instancesDesc, err := awsClient.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
-		InstanceIds: []*string{aws.String(validDoc.InstanceID)},
-		Filters:     instanceFilters,
-	})
+	instancesDesc, region, awsClient, err := p.describeInstancesWithRetry(ctx, c, describe, validDoc.Region, validDoc.InstanceID)
 	if err != nil {
-		return caws.AttestationStepError("querying AWS via describe-instances", err)
+		return err
+	}
+	p.health.recordSuccess(p.hooks.clock.Now())
+
+	if err := p.checkInstanceCount(c, instancesDesc, validDoc.InstanceID); err != nil {
+		return err
 	}
 
 	// Ideally we wouldn't do this work at all if the agent has already attested
@@ -179,7 +399,6 @@ func (p *IIDAttestorPlugin) Attest(stream nodeattestorv0.NodeAttestor_AttestServ
 	// This overhead will only effect agents attempting to re-attest which
 	// should be a very small portion of the overall server workload. This
 	// is a potential DoS vector.
-	shouldCheckBlockDevice := !inTrustAcctList && !c.SkipBlockDevice
 	var instance *ec2.Instance
 	var tags = make(instanceTags)
 	if strings.Contains(c.AgentPathTemplate, ".Tags") || shouldCheckBlockDevice {
@@ -204,7 +423,7 @@ func (p *IIDAttestorPlugin) Attest(stream nodeattestorv0.NodeAttestor_AttestServ
 		return iidError.New("failed to create spiffe ID: %w", err)
 	}
 
-	attested, err := p.IsAttested(stream.Context(), agentID.String())
+	attested, err := p.IsAttested(ctx, agentID.String())
 	switch {
 	case err != nil:
 		return err
@@ -212,7 +431,7 @@ func (p *IIDAttestorPlugin) Attest(stream nodeattestorv0.NodeAttestor_AttestServ
 		return iidError.New("IID has already been used to attest an agent")
 	}
 
-	selectors, err := p.resolveSelectors(stream.Context(), instancesDesc, awsClient)
+	selectors, err := p.resolveSelectors(ctx, instancesDesc, region, awsClient)
 	if err != nil {
 		return err
 	}
@@ -255,6 +474,112 @@ func (p *IIDAttestorPlugin) Configure(ctx context.Context, req *spi.ConfigureReq
 	}
 	config.awsCaCertPublicKey = awsCaCertPublicKey
 
+	if config.MaxResults != 0 && (config.MaxResults < minMaxResults || config.MaxResults > maxMaxResults) {
+		return nil, iidError.New("max_results must be between %d and %d", minMaxResults, maxMaxResults)
+	}
+
+	config.launchTimeGranularity = defaultLaunchTimeGranularity
+	if config.LaunchTimeGranularity != "" {
+		granularity, err := time.ParseDuration(config.LaunchTimeGranularity)
+		if err != nil {
+			return nil, iidError.New("invalid launch_time_granularity %q: %w", config.LaunchTimeGranularity, err)
+		}
+		if granularity <= 0 {
+			return nil, iidError.New("launch_time_granularity must be positive")
+		}
+		config.launchTimeGranularity = granularity
+	}
+
+	for accountID, roleARN := range config.AccountRoleMap {
+		if _, err := arn.Parse(roleARN); err != nil {
+			return nil, iidError.New("invalid role ARN %q for account %q in account_role_map: %w", roleARN, accountID, err)
+		}
+	}
+
+	for region, chain := range config.RegionCredentials {
+		for _, cred := range chain {
+			if cred.AssumeRoleARN == "" {
+				continue
+			}
+			if _, err := arn.Parse(cred.AssumeRoleARN); err != nil {
+				return nil, iidError.New("invalid role ARN %q for region %q in region_credentials: %w", cred.AssumeRoleARN, region, err)
+			}
+		}
+	}
+
+	for _, region := range config.PrewarmRegions {
+		if region == "" {
+			return nil, iidError.New("prewarm_regions cannot contain an empty region")
+		}
+	}
+
+	for _, region := range config.FallbackRegions {
+		if region == "" {
+			return nil, iidError.New("fallback_regions cannot contain an empty region")
+		}
+	}
+
+	if config.CircuitBreakerThreshold < 0 {
+		return nil, iidError.New("circuit_breaker_threshold cannot be negative")
+	}
+	if config.CircuitBreakerThreshold > 0 {
+		config.circuitBreakerCooldown = defaultCircuitBreakerCooldown
+		if config.CircuitBreakerCooldown != "" {
+			cooldown, err := time.ParseDuration(config.CircuitBreakerCooldown)
+			if err != nil {
+				return nil, iidError.New("invalid circuit_breaker_cooldown %q: %w", config.CircuitBreakerCooldown, err)
+			}
+			if cooldown <= 0 {
+				return nil, iidError.New("circuit_breaker_cooldown must be positive")
+			}
+			config.circuitBreakerCooldown = cooldown
+		}
+		config.breaker = newCircuitBreaker(config.CircuitBreakerThreshold, config.circuitBreakerCooldown)
+	}
+
+	if config.RetryBudget < 0 {
+		return nil, iidError.New("retry_budget cannot be negative")
+	}
+
+	if config.InstanceNotFoundRetryDeadline != "" {
+		deadline, err := time.ParseDuration(config.InstanceNotFoundRetryDeadline)
+		if err != nil {
+			return nil, iidError.New("invalid instance_not_found_retry_deadline %q: %w", config.InstanceNotFoundRetryDeadline, err)
+		}
+		if deadline < 0 {
+			return nil, iidError.New("instance_not_found_retry_deadline cannot be negative")
+		}
+		config.notFoundRetryDeadline = deadline
+	}
+	if config.notFoundRetryDeadline > 0 {
+		config.notFoundRetryInterval = defaultInstanceNotFoundRetryInterval
+		if config.InstanceNotFoundRetryInterval != "" {
+			interval, err := time.ParseDuration(config.InstanceNotFoundRetryInterval)
+			if err != nil {
+				return nil, iidError.New("invalid instance_not_found_retry_interval %q: %w", config.InstanceNotFoundRetryInterval, err)
+			}
+			if interval <= 0 {
+				return nil, iidError.New("instance_not_found_retry_interval must be positive")
+			}
+			config.notFoundRetryInterval = interval
+		}
+	}
+
+	config.tagTransforms, err = parseTagTransforms(config.TagTransforms, config.LowercaseTagKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.SelectorCategories) > 0 {
+		config.selectorCategories = make(map[string]bool, len(config.SelectorCategories))
+		for _, category := range config.SelectorCategories {
+			if !isSelectorCategory(category) {
+				return nil, iidError.New("unknown selector category %q in selector_categories", category)
+			}
+			config.selectorCategories[category] = true
+		}
+	}
+
 	if err := config.Validate(p.hooks.getenv(accessKeyIDVarName), p.hooks.getenv(secretAccessKeyVarName)); err != nil {
 		return nil, err
 	}
@@ -269,22 +594,44 @@ func (p *IIDAttestorPlugin) Configure(ctx context.Context, req *spi.ConfigureReq
 
 	config.pathTemplate = defaultAgentPathTemplate
 	if len(config.AgentPathTemplate) > 0 {
-		tmpl, err := template.New("agent-path").Parse(config.AgentPathTemplate)
+		// Fail on missing tags instead of rendering "<no value>"
+		tmpl, err := template.New("agent-path").Option("missingkey=error").Parse(config.AgentPathTemplate)
 		if err != nil {
 			return nil, iidError.New("failed to parse agent svid template: %q", config.AgentPathTemplate)
 		}
 		config.pathTemplate = tmpl
 	}
 
-	p.mtx.Lock()
-	defer p.mtx.Unlock()
+	config.defaultRegion = p.resolveDefaultRegion(config.RegionFromIMDS)
 
+	p.mtx.Lock()
 	p.config = config
-	p.clients.configure(config.SessionConfig)
+	p.clients.configure(config.SessionConfig, config.RegionCredentials)
+	p.mtx.Unlock()
+
+	p.prewarmClients(config.PrewarmRegions, config.RegionCredentials)
 
 	return &spi.ConfigureResponse{}, nil
 }
 
+// prewarmClients creates the clients for the given regions ahead of the
+// first attestation in them and validates their credentials. Failures are
+// only logged; the attestation surfaces them if they persist.
+func (p *IIDAttestorPlugin) prewarmClients(regions []string, regionCredentials map[string][]RegionCredential) {
+	for _, region := range regions {
+		client, err := p.clients.getClient(region)
+		// Clients picked from a credential chain have been validated already
+		if err == nil && len(regionCredentials[region]) == 0 {
+			err = validateClient(client)
+		}
+		if err != nil {
+			p.log.Warn("Failed to prewarm AWS client", "region", region, "error", err)
+			continue
+		}
+		p.log.Debug("Prewarmed AWS client", "region", region)
+	}
+}
+
 // GetPluginInfo returns the version and related metadata of the installed plugin.
 func (*IIDAttestorPlugin) GetPluginInfo(context.Context, *spi.GetPluginInfoRequest) (*spi.GetPluginInfoResponse, error) {
 	return &spi.GetPluginInfoResponse{}, nil
@@ -335,6 +682,33 @@ func (p *IIDAttestorPlugin) checkBlockDevice(instance *ec2.Instance) error {
 	return nil
 }
 
+// wantsSelectorCategory returns true if the selectors of the given category
+// are generated, i.e. selector_categories is unset or includes it.
+func (c *IIDAttestorConfig) wantsSelectorCategory(category string) bool {
+	return c.selectorCategories == nil || c.selectorCategories[category]
+}
+
+// onlyTagSelectors returns true if selector_categories limits the selectors
+// to the tag selectors.
+func (c *IIDAttestorConfig) onlyTagSelectors() bool {
+	return len(c.selectorCategories) == 1 && c.selectorCategories[tagSelectorCategory]
+}
+
+// wantsInstanceProfileSelectors returns true if any of the selectors
+// resolved from the instance profile are generated.
+func (c *IIDAttestorConfig) wantsInstanceProfileSelectors() bool {
+	return c.wantsSelectorCategory("iamrole") || c.wantsSelectorCategory("iamprofile") || c.wantsSelectorCategory("roletag") || c.wantsSelectorCategory("sessiontag")
+}
+
+func isSelectorCategory(category string) bool {
+	for _, c := range selectorCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *IIDAttestorPlugin) getConfig() (*IIDAttestorConfig, error) {
 	p.mtx.RLock()
 	defer p.mtx.RUnlock()
@@ -346,6 +720,231 @@ func (p *IIDAttestorPlugin) getConfig() (*IIDAttestorConfig, error) {
 	return p.config, nil
 }
 
+// describeCall is an AWS call that describes an instance, named after the
+// AWS CLI command for attestation errors.
+type describeCall struct {
+	name     string
+	describe func(ctx context.Context, client EC2Client, instanceID string, maxResults int64) (*ec2.DescribeInstancesOutput, error)
+}
+
+var (
+	describeInstancesCall = describeCall{name: "describe-instances", describe: describeInstances}
+	describeTagsCall      = describeCall{name: "describe-tags", describe: describeInstanceTags}
+)
+
+// describeInstancesWithRetry describes the given instance, retrying with
+// exponential backoff while DescribeInstances does not find it, until the
+// configured deadline or until the retry budget of the context is exhausted.
+// DescribeInstances is eventually consistent, so an instance that just
+// launched may not be found yet when it first attests. The tags returned by
+// DescribeTags do not tell whether the instance exists, so the describe-tags
+// call is never retried.
+func (p *IIDAttestorPlugin) describeInstancesWithRetry(ctx context.Context, c *IIDAttestorConfig, call describeCall, region, instanceID string) (*ec2.DescribeInstancesOutput, string, Client, error) {
+	deadline := p.hooks.clock.Now().Add(c.notFoundRetryDeadline)
+	backoff := c.notFoundRetryInterval
+	for {
+		instancesDesc, describedRegion, client, err := p.describeInstancesWithFallback(ctx, c, call, region, instanceID)
+		if c.notFoundRetryDeadline == 0 || call.name != describeInstancesCall.name || !isInstanceNotFound(instancesDesc, err) {
+			return instancesDesc, describedRegion, client, err
+		}
+
+		wait := deadline.Sub(p.hooks.clock.Now())
+		if wait <= 0 || !takeRetry(ctx) {
+			return instancesDesc, describedRegion, client, err
+		}
+		if backoff < wait {
+			wait = backoff
+		}
+		p.log.Debug("Instance not found yet; retrying", "instance_id", instanceID, "backoff", wait)
+
+		select {
+		case <-p.hooks.clock.After(wait):
+		case <-ctx.Done():
+			return nil, "", nil, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// isInstanceNotFound returns true if DescribeInstances did not find the
+// instance, either failing with InvalidInstanceID.NotFound or, when the
+// instance is looked up through a filter, returning no instances.
+func isInstanceNotFound(instancesDesc *ec2.DescribeInstancesOutput, err error) bool {
+	if err != nil {
+		var awsErr awserr.Error
+		return errors.As(err, &awsErr) && awsErr.Code() == "InvalidInstanceID.NotFound"
+	}
+	for _, reservation := range instancesDesc.Reservations {
+		if len(reservation.Instances) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// describeInstancesWithFallback describes the given instance in its region.
+// If the region is unavailable, the instance is described in each of the
+// fallback regions in order, until one of them succeeds. It returns the
+// region, and its client, that described the instance.
+func (p *IIDAttestorPlugin) describeInstancesWithFallback(ctx context.Context, c *IIDAttestorConfig, call describeCall, region, instanceID string) (*ec2.DescribeInstancesOutput, string, Client, error) {
+	client, err := p.clients.getClient(region)
+	if err != nil {
+		return nil, "", nil, iidError.New("failed to get client: %w", err)
+	}
+
+	instancesDesc, err := p.describeInstancesInRegion(ctx, c, call, client, region, instanceID)
+	if err == nil {
+		return instancesDesc, region, client, nil
+	}
+
+	if isRegionUnavailable(err) || errors.Is(err, errCircuitOpen) {
+		for _, fallbackRegion := range c.FallbackRegions {
+			p.log.Warn("Region is unavailable; describing the instance in a fallback region", "region", region, "fallback_region", fallbackRegion, "error", err)
+
+			fallbackClient, fallbackErr := p.clients.getClient(fallbackRegion)
+			if fallbackErr != nil {
+				p.log.Warn("Failed to get client for the fallback region", "fallback_region", fallbackRegion, "error", fallbackErr)
+				continue
+			}
+			instancesDesc, fallbackErr := p.describeInstancesInRegion(ctx, c, call, fallbackClient, fallbackRegion, instanceID)
+			if fallbackErr == nil {
+				return instancesDesc, fallbackRegion, fallbackClient, nil
+			}
+			if !isRegionUnavailable(fallbackErr) && !errors.Is(fallbackErr, errCircuitOpen) {
+				return nil, "", nil, caws.AttestationStepError("querying AWS via "+call.name, fallbackErr)
+			}
+		}
+	}
+
+	return nil, "", nil, caws.AttestationStepError("querying AWS via "+call.name, err)
+}
+
+func describeInstancesWithTimeout(ctx context.Context, call describeCall, client EC2Client, instanceID string, maxResults int64) (*ec2.DescribeInstancesOutput, error) {
+	ctx, cancel := context.WithTimeout(ctx, _awsTimeout)
+	defer cancel()
+	return call.describe(ctx, client, instanceID, maxResults)
+}
+
+// describeInstances describes the given instance, following NextToken until
+// all the reservations have been collected. When maxResults is set, the
+// instance is looked up through an instance-id filter, since AWS does not
+// allow MaxResults to be combined with explicit instance IDs.
+func describeInstances(ctx context.Context, client EC2Client, instanceID string, maxResults int64) (*ec2.DescribeInstancesOutput, error) {
+	input := &ec2.DescribeInstancesInput{
+		InstanceIds: []*string{aws.String(instanceID)},
+		Filters:     instanceFilters,
+	}
+	if maxResults > 0 {
+		input = &ec2.DescribeInstancesInput{
+			Filters: append([]*ec2.Filter{
+				{
+					Name:   aws.String("instance-id"),
+					Values: []*string{aws.String(instanceID)},
+				},
+			}, instanceFilters...),
+			MaxResults: aws.Int64(maxResults),
+		}
+	}
+
+	result := new(ec2.DescribeInstancesOutput)
+	for {
+		output, err := client.DescribeInstancesWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		result.Reservations = append(result.Reservations, output.Reservations...)
+
+		if aws.StringValue(output.NextToken) == "" {
+			return result, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// describeInstanceTags describes the tags of the given instance, following
+// NextToken until all the tags have been collected. The tags are returned as
+// the description of an instance that only has its ID and tags set, so it
+// can stand in for the output of DescribeInstances. DescribeTags does not
+// filter on the state of the instance, so the state is checked first with
+// DescribeInstanceStatus, and an instance that is not pending or running is
+// left out of the description, as DescribeInstances does.
+func describeInstanceTags(ctx context.Context, client EC2Client, instanceID string, maxResults int64) (*ec2.DescribeInstancesOutput, error) {
+	statusOutput, err := client.DescribeInstanceStatusWithContext(ctx, &ec2.DescribeInstanceStatusInput{
+		InstanceIds:         []*string{aws.String(instanceID)},
+		Filters:             instanceFilters,
+		IncludeAllInstances: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(statusOutput.InstanceStatuses) == 0 {
+		return &ec2.DescribeInstancesOutput{}, nil
+	}
+
+	input := &ec2.DescribeTagsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("resource-id"),
+				Values: []*string{aws.String(instanceID)},
+			},
+			{
+				Name:   aws.String("resource-type"),
+				Values: []*string{aws.String(ec2.ResourceTypeInstance)},
+			},
+		},
+	}
+	if maxResults > 0 {
+		input.MaxResults = aws.Int64(maxResults)
+	}
+
+	instance := &ec2.Instance{
+		InstanceId: aws.String(instanceID),
+	}
+	for {
+		output, err := client.DescribeTagsWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range output.Tags {
+			if tag != nil {
+				instance.Tags = append(instance.Tags, &ec2.Tag{Key: tag.Key, Value: tag.Value})
+			}
+		}
+
+		if aws.StringValue(output.NextToken) == "" {
+			return &ec2.DescribeInstancesOutput{
+				Reservations: []*ec2.Reservation{
+					{Instances: []*ec2.Instance{instance}},
+				},
+			}, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// checkInstanceCount checks that the instances described for a single
+// instance ID are, at most, that one instance. An instance listed more than
+// once, e.g. across result pages, is only counted once. More than one
+// instance means that the API or the instance filters misbehaved, in which
+// case a warning is logged or, if configured, attestation fails.
+func (p *IIDAttestorPlugin) checkInstanceCount(c *IIDAttestorConfig, instancesDesc *ec2.DescribeInstancesOutput, instanceID string) error {
+	instanceIDs := make(map[string]struct{})
+	for _, reservation := range instancesDesc.Reservations {
+		for _, instance := range reservation.Instances {
+			instanceIDs[aws.StringValue(instance.InstanceId)] = struct{}{}
+		}
+	}
+	if len(instanceIDs) <= 1 {
+		return nil
+	}
+
+	if c.RejectMultipleInstances {
+		return caws.AttestationStepError("querying AWS via describe-instances", iidError.New("returned %d instances for instance ID %q", len(instanceIDs), instanceID))
+	}
+	p.log.Warn("Describe instances returned more than one instance for the instance ID; selectors are resolved from all of them", "instance_id", instanceID, "count", len(instanceIDs))
+	return nil
+}
+
 func (p *IIDAttestorPlugin) getEC2Instance(instancesDesc *ec2.DescribeInstancesOutput) (*ec2.Instance, error) {
 	if len(instancesDesc.Reservations) < 1 {
 		return nil, caws.AttestationStepError("querying AWS via describe-instances", iidError.New("returned no reservations"))
@@ -393,11 +992,19 @@ func unmarshalAndValidateIdentityDocument(data []byte, pubKey *rsa.PublicKey) (e
 	return doc, nil
 }
 
-func (p *IIDAttestorPlugin) resolveSelectors(parent context.Context, instancesDesc *ec2.DescribeInstancesOutput, client Client) (*common.Selectors, error) {
-	selectorSet := map[string]bool{}
+func (p *IIDAttestorPlugin) resolveSelectors(parent context.Context, instancesDesc *ec2.DescribeInstancesOutput, region string, client Client) (*common.Selectors, error) {
+	selectorSet := map[string]*common.Selector{}
 	addSelectors := func(values []string) {
 		for _, value := range values {
-			selectorSet[value] = true
+			selectorSet[value] = &common.Selector{
+				Type:  caws.PluginName,
+				Value: value,
+			}
+		}
+	}
+	addTypedSelector := func(s *common.Selector) {
+		if s != nil {
+			selectorSet[s.Value] = s
 		}
 	}
 
@@ -407,45 +1014,230 @@ func (p *IIDAttestorPlugin) resolveSelectors(parent context.Context, instancesDe
 	}
 
 	for _, reservation := range instancesDesc.Reservations {
+		addSelectors(resolveOwnerAccount(reservation))
 		for _, instance := range reservation.Instances {
-			addSelectors(resolveTags(instance.Tags))
+			if c.DebugLogInstances {
+				p.logInstance(instance)
+			}
+			tagValues, err := resolveTransformedTags(instance.Tags, c.LowercaseTagKeys, c.tagTransforms, c.SuppressUnmappedTags)
+			if err != nil {
+				return nil, err
+			}
+			addSelectors(tagValues)
 			addSelectors(resolveSecurityGroups(instance.SecurityGroups))
-			if !c.DisableInstanceProfileSelectors && instance.IamInstanceProfile != nil && instance.IamInstanceProfile.Arn != nil {
+			addSelectors(resolveHostnames(instance, c.PublicHostnameSelector))
+			addSelectors(resolveNetwork(instance))
+			addSelectors(resolveNetworkInterfaces(instance.NetworkInterfaces))
+			addTypedSelector(resolveCPUCount(instance))
+			if c.MetadataOptionsSelectors {
+				addSelectors(resolveMetadataOptions(instance))
+			}
+			if c.CapacityReservationSelector {
+				addSelectors(resolveCapacityReservation(instance))
+			}
+			if c.SpotRequestSelector {
+				addSelectors(resolveSpotRequest(instance))
+			}
+			if c.LaunchTimeSelector {
+				addSelectors(resolveLaunchTime(instance, c.launchTimeGranularity))
+			}
+			if c.PublicIPSelector {
+				addSelectors(resolvePublicIP(instance))
+			}
+			if c.SpotInterruptionSelector && c.wantsSelectorCategory("interruption") {
+				values, err := p.resolveSpotInterruption(parent, c, client, instance)
+				if err != nil {
+					return nil, err
+				}
+				addSelectors(values)
+			}
+			if c.UserDataHashSelector && c.wantsSelectorCategory("userdatahash") {
+				values, err := p.resolveUserDataHash(parent, c, client, instance)
+				if err != nil {
+					return nil, err
+				}
+				addSelectors(values)
+			}
+			if c.SSMSelectors && c.wantsSelectorCategory("ssm") {
+				values, err := p.resolveSSM(parent, c, client, instance)
+				if err != nil {
+					return nil, err
+				}
+				addSelectors(values)
+			}
+			if !c.DisableInstanceProfileSelectors && c.wantsInstanceProfileSelectors() && instance.IamInstanceProfile != nil && instance.IamInstanceProfile.Arn != nil {
 				instanceProfileName, err := instanceProfileNameFromArn(*instance.IamInstanceProfile.Arn)
 				if err != nil {
 					return nil, err
 				}
+				iamClient, err := p.iamClientForProfile(c, *instance.IamInstanceProfile.Arn, region, client)
+				if err != nil {
+					return nil, err
+				}
 				ctx, cancel := context.WithTimeout(parent, _awsTimeout)
 				defer cancel()
-				output, err := client.GetInstanceProfileWithContext(ctx, &iam.GetInstanceProfileInput{
+				output, err := iamClient.GetInstanceProfileWithContext(ctx, &iam.GetInstanceProfileInput{
 					InstanceProfileName: aws.String(instanceProfileName),
 				})
-				if err != nil {
+				switch {
+				case err == nil:
+				case !c.StrictPermissions && isAccessDenied(err):
+					p.log.Warn("Not authorized to get the instance profile; skipping instance profile selectors", "instance_profile", instanceProfileName, "error", err)
+					continue
+				default:
 					return nil, iidError.Wrap(err)
 				}
-				addSelectors(resolveInstanceProfile(output.InstanceProfile))
+
+				values, err := p.resolveInstanceProfile(parent, c, iamClient, output.InstanceProfile)
+				if err != nil {
+					return nil, err
+				}
+				addSelectors(values)
 			}
 		}
 	}
 
 	// build and sort selectors
 	selectors := new(common.Selectors)
-	for value := range selectorSet {
-		selectors.Entries = append(selectors.Entries, &common.Selector{
-			Type:  caws.PluginName,
-			Value: value,
-		})
+	for _, s := range selectorSet {
+		if !c.wantsSelectorCategory(c.selectorCategory(s.Value)) {
+			continue
+		}
+		selectors.Entries = append(selectors.Entries, s)
 	}
 	util.SortSelectors(selectors.Entries)
 
 	return selectors, nil
 }
 
-func resolveTags(tags []*ec2.Tag) []string {
+// selectorCategory returns the category of a selector value, i.e. the part
+// before the first colon.
+func selectorCategory(value string) string {
+	if i := strings.IndexByte(value, ':'); i >= 0 {
+		return value[:i]
+	}
+	return value
+}
+
+// selectorCategory returns the category of a selector value. The selectors
+// of the tag transforms are in the tag category.
+func (c *IIDAttestorConfig) selectorCategory(value string) string {
+	category := selectorCategory(value)
+	for _, transform := range c.tagTransforms {
+		if transform.Selector == category {
+			return tagSelectorCategory
+		}
+	}
+	return category
+}
+
+// listRoleTags lists the tags of the given role, following Marker until all
+// the tags have been collected.
+func listRoleTags(parent context.Context, client IAMClient, roleName string) ([]*iam.Tag, error) {
+	ctx, cancel := context.WithTimeout(parent, _awsTimeout)
+	defer cancel()
+
+	input := &iam.ListRoleTagsInput{
+		RoleName: aws.String(roleName),
+	}
+	var tags []*iam.Tag
+	for {
+		output, err := client.ListRoleTagsWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, output.Tags...)
+
+		if !aws.BoolValue(output.IsTruncated) {
+			return tags, nil
+		}
+		input.Marker = output.Marker
+	}
+}
+
+// isAccessDenied returns true if the error returned by an AWS API call is
+// due to the caller lacking the permissions for it.
+func isAccessDenied(err error) bool {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+	switch awsErr.Code() {
+	case "AccessDenied", "AccessDeniedException", "UnauthorizedOperation":
+		return true
+	default:
+		return false
+	}
+}
+
+// isRegionUnavailable returns true if the error returned by an AWS API call
+// is due to the regional endpoint being unreachable or unavailable.
+func isRegionUnavailable(err error) bool {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+	switch awsErr.Code() {
+	case request.ErrCodeRequestError, request.ErrCodeResponseTimeout, "ServiceUnavailable", "Unavailable", "InternalError", "InternalFailure":
+		return true
+	default:
+		return false
+	}
+}
+
+// iamClientForProfile returns the client used to describe the given instance
+// profile. If the account owning the profile has a role configured in
+// account_role_map, a client assuming that role is returned.
+func (p *IIDAttestorPlugin) iamClientForProfile(c *IIDAttestorConfig, profileArn, region string, client Client) (IAMClient, error) {
+	a, err := arn.Parse(profileArn)
+	if err != nil {
+		return nil, iidError.Wrap(err)
+	}
+
+	roleARN, ok := c.AccountRoleMap[a.AccountID]
+	if !ok {
+		return client, nil
+	}
+
+	roleClient, err := p.clients.getClientWithRole(region, roleARN)
+	if err != nil {
+		return nil, iidError.New("failed to get client for account %q: %w", a.AccountID, err)
+	}
+	return roleClient, nil
+}
+
+// logInstance logs the described instance at debug level, with the values
+// of the tags that look like secrets redacted.
+func (p *IIDAttestorPlugin) logInstance(instance *ec2.Instance) {
+	if instance == nil {
+		return
+	}
+	redactedInstance := *instance
+	redactedInstance.Tags = make([]*ec2.Tag, 0, len(instance.Tags))
+	for _, tag := range instance.Tags {
+		if tag != nil && reSecretTagKey.MatchString(aws.StringValue(tag.Key)) {
+			tag = &ec2.Tag{Key: tag.Key, Value: aws.String(redacted)}
+		}
+		redactedInstance.Tags = append(redactedInstance.Tags, tag)
+	}
+
+	instanceJSON, err := json.Marshal(&redactedInstance)
+	if err != nil {
+		p.log.Debug("Unable to marshal the described instance", "instance_id", aws.StringValue(instance.InstanceId), "error", err)
+		return
+	}
+	p.log.Debug("Described instance", "instance_id", aws.StringValue(instance.InstanceId), "instance", string(instanceJSON))
+}
+
+func resolveTags(tags []*ec2.Tag, lowercaseKeys bool) []string {
 	values := make([]string, 0, len(tags))
 	for _, tag := range tags {
 		if tag != nil {
-			values = append(values, fmt.Sprintf("tag:%s:%s", aws.StringValue(tag.Key), aws.StringValue(tag.Value)))
+			key := aws.StringValue(tag.Key)
+			if lowercaseKeys {
+				key = strings.ToLower(key)
+			}
+			values = append(values, fmt.Sprintf("tag:%s:%s", key, aws.StringValue(tag.Value)))
 		}
 	}
 	return values
@@ -464,17 +1256,241 @@ func resolveSecurityGroups(sgs []*ec2.GroupIdentifier) []string {
 	return values
 }
 
-func resolveInstanceProfile(instanceProfile *iam.InstanceProfile) []string {
-	if instanceProfile == nil {
+func resolveHostnames(instance *ec2.Instance, includePublic bool) []string {
+	var values []string
+	if hostname := aws.StringValue(instance.PrivateDnsName); hostname != "" {
+		values = append(values, fmt.Sprintf("hostname:%s", hostname))
+	}
+	if hostname := aws.StringValue(instance.PublicDnsName); includePublic && hostname != "" {
+		values = append(values, fmt.Sprintf("publichostname:%s", hostname))
+	}
+	return values
+}
+
+// resolveNetworkInterfaces returns the eni selectors, with the ID and the
+// description of each network interface attached to the instance. Interfaces
+// without a description only get the ID selector.
+func resolveNetworkInterfaces(enis []*ec2.InstanceNetworkInterface) []string {
+	values := make([]string, 0, len(enis)*2)
+	for _, eni := range enis {
+		if eni == nil {
+			continue
+		}
+		if id := aws.StringValue(eni.NetworkInterfaceId); id != "" {
+			values = append(values, fmt.Sprintf("eni:id:%s", id))
+		}
+		if description := aws.StringValue(eni.Description); description != "" {
+			values = append(values, fmt.Sprintf("eni:description:%s", description))
+		}
+	}
+	return values
+}
+
+// resolveNetwork returns the vpc selector, with the ID of the VPC the
+// instance runs in, if known (e.g. EC2-Classic instances are not in a VPC).
+func resolveNetwork(instance *ec2.Instance) []string {
+	if vpcID := aws.StringValue(instance.VpcId); vpcID != "" {
+		return []string{fmt.Sprintf("vpc:%s", vpcID)}
+	}
+	return nil
+}
+
+// resolveOwnerAccount returns the owneraccount selector with the account
+// owning the reservation of the instance, if known. The instances described
+// through their tags have no reservation owner.
+func resolveOwnerAccount(reservation *ec2.Reservation) []string {
+	if ownerID := aws.StringValue(reservation.OwnerId); ownerID != "" {
+		return []string{fmt.Sprintf("owneraccount:%s", ownerID)}
+	}
+	return nil
+}
+
+// resolveCPUCount returns the cpucount selector, with the number of vCPUs of
+// the instance as its typed value, or nil if the CPU options are unknown.
+func resolveCPUCount(instance *ec2.Instance) *common.Selector {
+	if instance.CpuOptions == nil || instance.CpuOptions.CoreCount == nil {
+		return nil
+	}
+	threadsPerCore := aws.Int64Value(instance.CpuOptions.ThreadsPerCore)
+	if threadsPerCore == 0 {
+		threadsPerCore = 1
+	}
+	return selector.NewInt(caws.PluginName, "cpucount", aws.Int64Value(instance.CpuOptions.CoreCount)*threadsPerCore)
+}
+
+// resolveMetadataOptions returns the imds selectors, with the HTTP tokens
+// state (i.e. whether IMDSv2 is required) and the PUT response hop limit of
+// the instance metadata service, if known.
+func resolveMetadataOptions(instance *ec2.Instance) []string {
+	if instance.MetadataOptions == nil {
+		return nil
+	}
+	var values []string
+	if httpTokens := aws.StringValue(instance.MetadataOptions.HttpTokens); httpTokens != "" {
+		values = append(values, fmt.Sprintf("imds:http_tokens:%s", httpTokens))
+	}
+	if hopLimit := instance.MetadataOptions.HttpPutResponseHopLimit; hopLimit != nil {
+		values = append(values, fmt.Sprintf("imds:hop_limit:%d", aws.Int64Value(hopLimit)))
+	}
+	return values
+}
+
+// resolveCapacityReservation returns the capacityreservation selector, with
+// the ID of the capacity reservation the instance runs in, if any.
+func resolveCapacityReservation(instance *ec2.Instance) []string {
+	if id := aws.StringValue(instance.CapacityReservationId); id != "" {
+		return []string{fmt.Sprintf("capacityreservation:%s", id)}
+	}
+	return nil
+}
+
+// resolveSpotRequest returns the spotrequest selector, with the ID of the
+// spot instance request the instance was launched from, if any. On-demand
+// instances have no spot instance request.
+func resolveSpotRequest(instance *ec2.Instance) []string {
+	if instance == nil {
+		return nil
+	}
+	if id := aws.StringValue(instance.SpotInstanceRequestId); id != "" {
+		return []string{fmt.Sprintf("spotrequest:%s", id)}
+	}
+	return nil
+}
+
+// resolvePublicIP returns the public selector, which is "public:true" if
+// the instance has a public IPv4 address and "public:false" otherwise.
+func resolvePublicIP(instance *ec2.Instance) []string {
+	if instance == nil {
 		return nil
 	}
-	values := make([]string, 0, len(instanceProfile.Roles))
+	return []string{fmt.Sprintf("public:%t", aws.StringValue(instance.PublicIpAddress) != "")}
+}
+
+// resolveLaunchTime returns the launchtime selector, with the launch time of
+// the instance in UTC truncated to the given granularity, so that instances
+// launched close together share the selector. There is no selector if the
+// launch time is unknown.
+func resolveLaunchTime(instance *ec2.Instance, granularity time.Duration) []string {
+	if instance.LaunchTime == nil {
+		return nil
+	}
+	launchTime := instance.LaunchTime.UTC().Truncate(granularity)
+	return []string{fmt.Sprintf("launchtime:%s", launchTime.Format(time.RFC3339))}
+}
+
+// resolveSpotInterruption returns the interruption selector of a spot
+// instance that has been issued an interruption notice, i.e. whose spot
+// instance request is marked for termination, stop or hibernation.
+func (p *IIDAttestorPlugin) resolveSpotInterruption(parent context.Context, c *IIDAttestorConfig, client EC2Client, instance *ec2.Instance) ([]string, error) {
+	if aws.StringValue(instance.InstanceLifecycle) != ec2.InstanceLifecycleTypeSpot || instance.SpotInstanceRequestId == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(parent, _awsTimeout)
+	defer cancel()
+	output, err := client.DescribeSpotInstanceRequestsWithContext(ctx, &ec2.DescribeSpotInstanceRequestsInput{
+		SpotInstanceRequestIds: []*string{instance.SpotInstanceRequestId},
+	})
+	switch {
+	case err == nil:
+	case !c.StrictPermissions && isAccessDenied(err):
+		p.log.Warn("Not authorized to describe the spot instance request; skipping interruption selector", "spot_instance_request_id", aws.StringValue(instance.SpotInstanceRequestId), "error", err)
+		return nil, nil
+	default:
+		return nil, iidError.Wrap(err)
+	}
+
+	for _, request := range output.SpotInstanceRequests {
+		if request.Status == nil {
+			continue
+		}
+		switch aws.StringValue(request.Status.Code) {
+		case spotStatusMarkedForTermination, spotStatusMarkedForStop, spotStatusMarkedForHibernation:
+			return []string{"interruption:pending"}, nil
+		}
+	}
+	return nil, nil
+}
+
+// resolveUserDataHash returns the userdatahash selector, with the hex encoded
+// SHA-256 hash of the user data the instance was launched with, if any.
+func (p *IIDAttestorPlugin) resolveUserDataHash(parent context.Context, c *IIDAttestorConfig, client EC2Client, instance *ec2.Instance) ([]string, error) {
+	ctx, cancel := context.WithTimeout(parent, _awsTimeout)
+	defer cancel()
+	output, err := client.DescribeInstanceAttributeWithContext(ctx, &ec2.DescribeInstanceAttributeInput{
+		Attribute:  aws.String(ec2.InstanceAttributeNameUserData),
+		InstanceId: instance.InstanceId,
+	})
+	switch {
+	case err == nil:
+	case !c.StrictPermissions && isAccessDenied(err):
+		p.log.Warn("Not authorized to describe the user data of the instance; skipping user data hash selector", "instance_id", aws.StringValue(instance.InstanceId), "error", err)
+		return nil, nil
+	default:
+		return nil, iidError.Wrap(err)
+	}
+
+	if output.UserData == nil || aws.StringValue(output.UserData.Value) == "" {
+		return nil, nil
+	}
+	// The user data is returned base64 encoded
+	userData, err := base64.StdEncoding.DecodeString(aws.StringValue(output.UserData.Value))
+	if err != nil {
+		return nil, iidError.New("failed to decode the user data of instance %q: %w", aws.StringValue(instance.InstanceId), err)
+	}
+	sum := sha256.Sum256(userData)
+	return []string{fmt.Sprintf("userdatahash:%s", hex.EncodeToString(sum[:]))}, nil
+}
+
+// resolveInstanceProfile returns the iamprofile path selector of the instance
+// profile, the iamrole selectors for the roles in the instance profile and,
+// if enabled, the roletag and sessiontag selectors
+// resolved from the tags of those roles. EC2 does not pass session tags when
+// it assumes the role of an instance profile, and the tags of another
+// principal's session cannot be retrieved from STS, so the session tags are
+// those inherited from the role, i.e. the role tags.
+func (p *IIDAttestorPlugin) resolveInstanceProfile(parent context.Context, c *IIDAttestorConfig, client IAMClient, instanceProfile *iam.InstanceProfile) ([]string, error) {
+	if instanceProfile == nil {
+		return nil, nil
+	}
+
+	values := make([]string, 0, len(instanceProfile.Roles)+1)
+	if instanceProfile.Path != nil {
+		values = append(values, fmt.Sprintf("iamprofile:path:%s", aws.StringValue(instanceProfile.Path)))
+	}
 	for _, role := range instanceProfile.Roles {
-		if role != nil && role.Arn != nil {
+		if role == nil {
+			continue
+		}
+		if role.Arn != nil {
 			values = append(values, fmt.Sprintf("iamrole:%s", aws.StringValue(role.Arn)))
 		}
+
+		if (!c.IncludeRoleTags && !c.SessionTagSelectors) || role.RoleName == nil {
+			continue
+		}
+		tags, err := listRoleTags(parent, client, *role.RoleName)
+		switch {
+		case err == nil:
+		case !c.StrictPermissions && isAccessDenied(err):
+			p.log.Warn("Not authorized to list the role tags; skipping role tag selectors", "role", *role.RoleName, "error", err)
+			continue
+		default:
+			return nil, iidError.Wrap(err)
+		}
+		for _, tag := range tags {
+			if tag == nil {
+				continue
+			}
+			if c.IncludeRoleTags {
+				values = append(values, fmt.Sprintf("roletag:%s:%s", aws.StringValue(tag.Key), aws.StringValue(tag.Value)))
+			}
+			if c.SessionTagSelectors {
+				values = append(values, fmt.Sprintf("sessiontag:%s:%s", aws.StringValue(tag.Key), aws.StringValue(tag.Value)))
+			}
+		}
 	}
-	return values
+	return values, nil
 }
 
 var reInstanceProfileARNResource = regexp.MustCompile(`instance-profile[/:](.+)`)
@@ -489,5 +1505,10 @@ func instanceProfileNameFromArn(profileArn string) (string, error) {
 		return "", iidError.New("arn is not for an instance profile")
 	}
 
-	return m[1], nil
+	// The resource includes the path of the instance profile, if it has one
+	name := m[1]
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name, nil
 }
@@ -11,24 +11,34 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/spire/pkg/common/pemutil"
 	caws "github.com/spiffe/spire/pkg/common/plugin/aws"
+	"github.com/spiffe/spire/pkg/server/hostservice/agentstore"
 	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/proto/spire/common/plugin"
 	agentstorev0 "github.com/spiffe/spire/proto/spire/hostservice/server/agentstore/v0"
 	nodeattestorv0 "github.com/spiffe/spire/proto/spire/plugin/server/nodeattestor/v0"
+	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakeagentstore"
 	mock_aws "github.com/spiffe/spire/test/mock/server/aws"
 	"github.com/spiffe/spire/test/plugintest"
 	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 )
 
@@ -58,6 +68,13 @@ var (
 	zeroDeviceIndex    = int64(0)
 	nonzeroDeviceIndex = int64(1)
 	instanceStoreType  = ec2.DeviceTypeInstanceStore
+
+	testSpotInstanceRequest = "sir-test"
+	testCapacityReservation = "cr-0123456789abcdef0"
+	testVPC                 = "vpc-0123456789abcdef0"
+	testUserData            = "#!/bin/bash\necho hello\n"
+	// sha256 of testUserData
+	testUserDataHash = "f590776b449af73e55cb368f45ce28400a19d0c68cdb34485fdfc0602b6c2437"
 )
 
 func TestIIDAttestorPlugin(t *testing.T) {
@@ -74,6 +91,7 @@ type IIDAttestorSuite struct {
 	rsaKey     *rsa.PrivateKey
 	env        map[string]string
 	agentStore *fakeagentstore.AgentStore
+	logHook    *test.Hook
 }
 
 func (s *IIDAttestorSuite) SetupTest() {
@@ -90,9 +108,13 @@ func (s *IIDAttestorSuite) SetupTest() {
 	}
 	s.plugin = p
 
+	log, logHook := test.NewNullLogger()
+	s.logHook = logHook
+
 	v0 := new(nodeattestor.V0)
 	plugintest.Load(s.T(), builtin(s.plugin), v0,
 		plugintest.HostServices(agentstorev0.AgentStoreServiceServer(s.agentStore)),
+		plugintest.Log(log),
 	)
 	s.p = v0.NodeAttestorClient
 }
@@ -164,7 +186,7 @@ func (s *IIDAttestorSuite) TestErrorOnAlreadyAttested() {
 
 	client := mock_aws.NewMockClient(mockCtl)
 
-	mockGetEC2Client := func(config *SessionConfig, region string) (Client, error) {
+	mockGetEC2Client := func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
 		return client, nil
 	}
 	s.plugin.clients = newClientsCache(mockGetEC2Client)
@@ -196,6 +218,56 @@ func (s *IIDAttestorSuite) TestErrorOnAlreadyAttested() {
 	s.RequireErrorContains(err, "IID has already been used to attest an agent")
 }
 
+func (s *IIDAttestorSuite) TestReattestation() {
+	mockCtl := gomock.NewController(s.T())
+	defer mockCtl.Finish()
+
+	client := mock_aws.NewMockClient(mockCtl)
+
+	mockGetEC2Client := func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+		return client, nil
+	}
+	s.plugin.clients = newClientsCache(mockGetEC2Client)
+
+	setAttestExpectations(client, getDefaultDescribeInstancesOutput(), nil)
+	setAttestExpectations(client, getDefaultDescribeInstancesOutput(), nil)
+
+	s.configure()
+
+	// using our own keypair (since we don't have AWS private key)
+	originalAWSPublicKey := s.plugin.config.awsCaCertPublicKey
+	defer func() {
+		s.plugin.config.awsCaCertPublicKey = originalAWSPublicKey
+	}()
+	s.plugin.config.awsCaCertPublicKey = &s.rsaKey.PublicKey
+
+	data := &common.AttestationData{
+		Type: caws.PluginName,
+		Data: s.iidAttestationDataToBytes(*s.buildDefaultIIDAttestationData()),
+	}
+
+	agentID := "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance"
+	s.agentStore.SetAgentInfo(&agentstorev0.AgentInfo{
+		AgentId: agentID,
+	})
+
+	// The agent that attested the instance can attest it again, so its
+	// selectors are refreshed, e.g. after the instance tags change
+	ctx := agentstore.WithReattestingAgent(context.Background(), agentID)
+	resp, err := s.attestWithContext(ctx, &nodeattestorv0.AttestRequest{
+		AttestationData: data,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(agentID, resp.AgentId)
+
+	// Other agents still cannot reuse the instance identity document
+	ctx = agentstore.WithReattestingAgent(context.Background(), "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/other-instance")
+	_, err = s.attestWithContext(ctx, &nodeattestorv0.AttestRequest{
+		AttestationData: data,
+	})
+	s.RequireErrorContains(err, "IID has already been used to attest an agent")
+}
+
 func (s *IIDAttestorSuite) TestErrorOnBadSignature() {
 	s.configure()
 
@@ -240,6 +312,24 @@ func (s *IIDAttestorSuite) TestClientAndIDReturns() {
 		skipBlockDev                    bool
 		skipEC2Block                    bool
 		disableInstanceProfileSelectors bool
+		maxResults                      int64
+		strictPermissions               bool
+		publicHostnameSelector          bool
+		sessionTagSelectors             bool
+		includeRoleTags                 bool
+		spotInterruptionSelector        bool
+		spotRequestSelector             bool
+		metadataOptionsSelectors        bool
+		capacityReservationSelector     bool
+		userDataHashSelector            bool
+		launchTimeSelector              bool
+		launchTimeGranularity           string
+		publicIPSelector                bool
+		ssmSelectors                    bool
+		rejectMultipleInstances         bool
+		selectorCategories              []string
+		lowercaseTagKeys                bool
+		expectLogs                      []spiretest.LogEntry
 	}{
 		{
 			desc: "error on call",
@@ -404,7 +494,7 @@ func (s *IIDAttestorSuite) TestClientAndIDReturns() {
 			expectID: "spiffe://example.org/spire/agent/aws_iid/zone1/host1",
 		},
 		{
-			desc: "missing tags do not panic",
+			desc: "missing tags are rejected",
 			mockExpect: func(mock *mock_aws.MockClient) {
 				output := getDefaultDescribeInstancesOutput()
 				output.Reservations[0].Instances[0].RootDeviceType = &instanceStoreType
@@ -413,7 +503,41 @@ func (s *IIDAttestorSuite) TestClientAndIDReturns() {
 				setResolveSelectorsExpectations(mock, nil)
 			},
 			replacementTemplate: "{{ .PluginName}}/zone1/{{ .Tags.Hostname }}",
-			expectID:            "spiffe://example.org/spire/agent/aws_iid/zone1/%3Cno%20value%3E",
+			expectErr:           `failed to create spiffe ID: template: agent-path:1:31: executing "agent-path" at <.Tags.Hostname>: map has no entry for key "Hostname"`,
+		},
+		{
+			desc: "tags resulting in an invalid path are rejected",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].Tags = []*ec2.Tag{
+					{
+						Key:   aws.String("Environment"),
+						Value: aws.String("prod east"),
+					},
+				}
+				output.Reservations[0].Instances[0].RootDeviceType = &instanceStoreType
+				output.Reservations[0].Instances[0].NetworkInterfaces[0].Attachment.DeviceIndex = &zeroDeviceIndex
+				setAttestExpectations(mock, output, nil)
+			},
+			replacementTemplate: "{{ .PluginName}}/{{ .Tags.Environment }}/{{ .InstanceID }}",
+			expectErr:           `failed to create spiffe ID: agent path "aws_iid/prod east/test-instance" contains invalid characters in segment "prod east"`,
+		},
+		{
+			desc: "success, agent ID templated from tags",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].Tags = []*ec2.Tag{
+					{
+						Key:   aws.String("Environment"),
+						Value: aws.String("production"),
+					},
+				}
+				output.Reservations[0].Instances[0].RootDeviceType = &instanceStoreType
+				output.Reservations[0].Instances[0].NetworkInterfaces[0].Attachment.DeviceIndex = &zeroDeviceIndex
+				setAttestExpectations(mock, output, nil)
+			},
+			replacementTemplate: "{{ .PluginName}}/{{ .Tags.Environment }}/{{ .InstanceID }}",
+			expectID:            "spiffe://example.org/spire/agent/aws_iid/production/test-instance",
 		},
 		{
 			desc:                            "success, ignore instance profile selectors",
@@ -456,186 +580,2195 @@ func (s *IIDAttestorSuite) TestClientAndIDReturns() {
 			},
 			expectID: "spiffe://example.org/spire/agent/aws_iid/zone1/host1",
 		},
-	}
-
-	for _, tt := range tests {
-		tt := tt
-		s.T().Run(tt.desc, func(t *testing.T) {
-			mockCtl := gomock.NewController(s.T())
-			defer mockCtl.Finish()
-
-			client := mock_aws.NewMockClient(mockCtl)
-
-			mockGetEC2Client := func(config *SessionConfig, region string) (Client, error) {
-				return client, nil
-			}
-			s.plugin.clients = newClientsCache(mockGetEC2Client)
-
-			if tt.mockExpect != nil {
-				tt.mockExpect(client)
-			}
-
-			var configStr string
-			if tt.replacementTemplate != "" {
-				configStr = fmt.Sprintf(`agent_path_template = "%s"`, tt.replacementTemplate)
-			}
-			if len(tt.allowList) > 0 {
-				configStr += "\naccount_ids_for_local_validation = [\n"
-				for _, id := range tt.allowList {
-					configStr = `  ` + configStr + `"` + id + `",`
+		{
+			desc: "success, instance profile selectors skipped when access is denied",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].Tags = []*ec2.Tag{
+					{
+						Key:   aws.String("Hostname"),
+						Value: aws.String("host1"),
+					},
 				}
-				configStr += "\n]"
-			}
-			if tt.skipBlockDev {
-				configStr += "\nskip_block_device = true"
-			}
-			if tt.skipEC2Block {
-				configStr += "\nskip_ec2_attest_calling = true"
-			}
-
-			if tt.disableInstanceProfileSelectors {
-				configStr += "\ndisable_instance_profile_selectors = true"
-			}
-
-			_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
-				Configuration: configStr,
-				GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
-			})
-			s.Require().NoError(err)
-
-			data := &common.AttestationData{
-				Type: caws.PluginName,
-				Data: s.iidAttestationDataToBytes(*s.buildDefaultIIDAttestationData()),
-			}
-
-			// using our own keypair (since we don't have AWS private key)
-			originalAWSPublicKey := s.plugin.config.awsCaCertPublicKey
-			defer func() {
-				s.plugin.config.awsCaCertPublicKey = originalAWSPublicKey
-			}()
-			s.plugin.config.awsCaCertPublicKey = &s.rsaKey.PublicKey
-
-			resp, err := s.attest(&nodeattestorv0.AttestRequest{
-				AttestationData: data,
-			})
-
-			if tt.expectErr != "" {
-				s.Nil(resp)
-				s.RequireErrorContains(err, tt.expectErr)
-				return
-			}
-
-			s.Equal(tt.expectID, resp.AgentId)
-			if tt.expectSelectors != nil {
-				s.Len(resp.Selectors, len(tt.expectSelectors))
-				for i, sel := range resp.Selectors {
-					s.Equal(tt.expectSelectors[i], sel)
+				output.Reservations[0].Instances[0].SecurityGroups = []*ec2.GroupIdentifier{
+					{
+						GroupId:   aws.String("TestGroup"),
+						GroupName: aws.String("Test Group Name"),
+					},
 				}
-			}
-		})
-	}
-}
-
-func (s *IIDAttestorSuite) TestErrorOnBadSVIDTemplate() {
-	_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
-		Configuration: `
-agent_path_template = "{{ .InstanceID "
-`,
-		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
-	})
-	s.RequireErrorContains(err, "failed to parse agent svid template")
-}
-
-func (s *IIDAttestorSuite) TestConfigure() {
-	require := s.Require()
-
-	// malformed
-	resp, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
-		Configuration: `trust_domain`,
-		GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
-	})
-	s.RequireErrorContains(err, "expected start of object")
-	require.Nil(resp)
-
-	// missing global configuration
-	resp, err = s.p.Configure(context.Background(), &plugin.ConfigureRequest{
-		Configuration: ``})
-	s.RequireErrorContains(err, "global configuration is required")
-	require.Nil(resp)
-
-	// missing trust domain
-	resp, err = s.p.Configure(context.Background(), &plugin.ConfigureRequest{
-		Configuration: ``,
-		GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{}})
-	s.RequireErrorContains(err, "trust_domain is required")
-	require.Nil(resp)
-
-	// fails with access id but no secret
-	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
-		Configuration: `
-		access_key_id = "ACCESSKEYID"
-		`,
-		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
-	s.Require().EqualError(err, "aws-iid: configuration missing secret access key, but has access key id")
-	s.Require().Nil(resp)
-
-	// fails with secret but no access id
-	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
-		Configuration: `
-		secret_access_key = "SECRETACCESSKEY"
-		`,
-		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
-	s.Require().EqualError(err, "aws-iid: configuration missing access key id, but has secret access key")
-	s.Require().Nil(resp)
-
-	// success with envvars
-	s.env[accessKeyIDVarName] = "ACCESSKEYID"
-	s.env[secretAccessKeyVarName] = "SECRETACCESSKEY"
-	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
-		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
-	})
-	s.Require().NoError(err)
-	s.Require().Equal(resp, &plugin.ConfigureResponse{})
-	delete(s.env, accessKeyIDVarName)
-	delete(s.env, secretAccessKeyVarName)
-
-	// success, no AWS keys
-	resp, err = s.p.Configure(context.Background(), &plugin.ConfigureRequest{
-		Configuration: ``,
-		GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
-	require.NoError(err)
-	require.NotNil(resp)
-	s.RequireProtoEqual(resp, &plugin.ConfigureResponse{})
-}
-
-func (s *IIDAttestorSuite) TestGetPluginInfo() {
-	require := s.Require()
-	resp, err := s.p.GetPluginInfo(context.Background(), &plugin.GetPluginInfoRequest{})
-	require.NoError(err)
-	require.NotNil(resp)
-	s.RequireProtoEqual(resp, &plugin.GetPluginInfoResponse{})
-}
-
-func (s *IIDAttestorSuite) TestInstanceProfileArnParsing() {
-	// not an ARN
-	_, err := instanceProfileNameFromArn("not-an-arn")
-	s.Require().EqualError(err, "aws-iid: arn: invalid prefix")
-
-	// not an instance profile ARN
-	_, err = instanceProfileNameFromArn("arn:aws:elasticbeanstalk:us-east-1:123456789012:environment/My App/MyEnvironment")
-	s.Require().EqualError(err, "aws-iid: arn is not for an instance profile")
-
-	name, err := instanceProfileNameFromArn(testInstanceProfileArn)
-	s.Require().NoError(err)
-	s.Require().Equal(testInstanceProfileName, name)
-}
-
-func (s *IIDAttestorSuite) configure() {
-	_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
-		Configuration: `skip_block_device=true`,
-		GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
-	})
-	s.Require().NoError(err)
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/" + testProfile),
+				}
+				output.Reservations[0].Instances[0].RootDeviceType = &instanceStoreType
+				output.Reservations[0].Instances[0].NetworkInterfaces[0].Attachment.DeviceIndex = &zeroDeviceIndex
+				setAttestExpectations(mock, output, nil)
+				setResolveSelectorsError(mock, awserr.New("AccessDenied", "not authorized to perform iam:GetInstanceProfile", nil))
+			},
+			replacementTemplate: "{{ .PluginName}}/zone1/{{ .Tags.Hostname }}",
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "sg:id:TestGroup"},
+				{Type: caws.PluginName, Value: "sg:name:Test Group Name"},
+				{Type: caws.PluginName, Value: "tag:Hostname:host1"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/zone1/host1",
+		},
+		{
+			desc:              "error when access to the instance profile is denied with strict permissions",
+			strictPermissions: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/" + testProfile),
+				}
+				output.Reservations[0].Instances[0].RootDeviceType = &instanceStoreType
+				output.Reservations[0].Instances[0].NetworkInterfaces[0].Attachment.DeviceIndex = &zeroDeviceIndex
+				setAttestExpectations(mock, output, nil)
+				setResolveSelectorsError(mock, awserr.New("AccessDenied", "not authorized to perform iam:GetInstanceProfile", nil))
+			},
+			expectErr: "AccessDenied",
+		},
+		{
+			desc: "error on instance profile failure other than access denied",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/" + testProfile),
+				}
+				output.Reservations[0].Instances[0].RootDeviceType = &instanceStoreType
+				output.Reservations[0].Instances[0].NetworkInterfaces[0].Attachment.DeviceIndex = &zeroDeviceIndex
+				setAttestExpectations(mock, output, nil)
+				setResolveSelectorsError(mock, awserr.New("Throttling", "rate exceeded", nil))
+			},
+			expectErr: "Throttling",
+		},
+		{
+			desc: "success, instance profile path selector",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/service-roles/" + testProfile),
+				}
+				setAttestExpectations(mock, output, nil)
+				setResolveSelectorsExpectations(mock, &iam.GetInstanceProfileOutput{
+					InstanceProfile: &iam.InstanceProfile{
+						Path: aws.String("/service-roles/"),
+						Roles: []*iam.Role{
+							{Arn: aws.String("role1")},
+							nil,
+						},
+					},
+				})
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "iamprofile:path:/service-roles/"},
+				{Type: caws.PluginName, Value: "iamrole:role1"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:               "success, instance profile path selector only",
+			selectorCategories: []string{"iamprofile"},
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/" + testProfile),
+				}
+				setAttestExpectations(mock, output, nil)
+				setResolveSelectorsExpectations(mock, &iam.GetInstanceProfileOutput{
+					InstanceProfile: &iam.InstanceProfile{
+						Path:  aws.String("/"),
+						Roles: []*iam.Role{{Arn: aws.String("role1")}},
+					},
+				})
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "iamprofile:path:/"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, hostname selector",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].PrivateDnsName = aws.String("ip-10-0-0-1.ec2.internal")
+				output.Reservations[0].Instances[0].PublicDnsName = aws.String("ec2-1-2-3-4.compute-1.amazonaws.com")
+				setAttestExpectations(mock, output, nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "hostname:ip-10-0-0-1.ec2.internal"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                   "success, hostname and public hostname selectors",
+			publicHostnameSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].PrivateDnsName = aws.String("ip-10-0-0-1.ec2.internal")
+				output.Reservations[0].Instances[0].PublicDnsName = aws.String("ec2-1-2-3-4.compute-1.amazonaws.com")
+				setAttestExpectations(mock, output, nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "hostname:ip-10-0-0-1.ec2.internal"},
+				{Type: caws.PluginName, Value: "publichostname:ec2-1-2-3-4.compute-1.amazonaws.com"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, typed cpucount selector",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].CpuOptions = &ec2.CpuOptions{
+					CoreCount:      aws.Int64(2),
+					ThreadsPerCore: aws.Int64(2),
+				}
+				setAttestExpectations(mock, output, nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "cpucount:4", TypedValue: &common.Selector_IntValue{IntValue: 4}},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                   "success, no hostname selectors for empty DNS names",
+			publicHostnameSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].PrivateDnsName = aws.String("")
+				setAttestExpectations(mock, output, nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                "success, session tag selectors",
+			sessionTagSelectors: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/" + testProfile),
+				}
+				setAttestExpectations(mock, output, nil)
+				setResolveSelectorsExpectations(mock, &iam.GetInstanceProfileOutput{
+					InstanceProfile: &iam.InstanceProfile{
+						Roles: []*iam.Role{
+							{Arn: aws.String("role1"), RoleName: aws.String("role1")},
+							{Arn: aws.String("role2"), RoleName: aws.String("role2")},
+						},
+					},
+				})
+				mock.EXPECT().ListRoleTagsWithContext(gomock.Any(), &iam.ListRoleTagsInput{
+					RoleName: aws.String("role1"),
+				}).Return(&iam.ListRoleTagsOutput{
+					Tags: []*iam.Tag{
+						{Key: aws.String("team"), Value: aws.String("blog")},
+					},
+					IsTruncated: aws.Bool(true),
+					Marker:      aws.String("page-2"),
+				}, nil)
+				mock.EXPECT().ListRoleTagsWithContext(gomock.Any(), &iam.ListRoleTagsInput{
+					RoleName: aws.String("role1"),
+					Marker:   aws.String("page-2"),
+				}).Return(&iam.ListRoleTagsOutput{
+					Tags: []*iam.Tag{
+						{Key: aws.String("env"), Value: aws.String("prod")},
+					},
+				}, nil)
+				mock.EXPECT().ListRoleTagsWithContext(gomock.Any(), &iam.ListRoleTagsInput{
+					RoleName: aws.String("role2"),
+				}).Return(&iam.ListRoleTagsOutput{}, nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "iamrole:role1"},
+				{Type: caws.PluginName, Value: "iamrole:role2"},
+				{Type: caws.PluginName, Value: "sessiontag:env:prod"},
+				{Type: caws.PluginName, Value: "sessiontag:team:blog"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                "success, session tag selectors skipped when access is denied",
+			sessionTagSelectors: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/" + testProfile),
+				}
+				setAttestExpectations(mock, output, nil)
+				setResolveSelectorsExpectations(mock, &iam.GetInstanceProfileOutput{
+					InstanceProfile: &iam.InstanceProfile{
+						Roles: []*iam.Role{
+							{Arn: aws.String("role1"), RoleName: aws.String("role1")},
+						},
+					},
+				})
+				mock.EXPECT().ListRoleTagsWithContext(gomock.Any(), gomock.Any()).Return(nil, awserr.New("AccessDenied", "not authorized to perform iam:ListRoleTags", nil))
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "iamrole:role1"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                "error when access to the role tags is denied with strict permissions",
+			sessionTagSelectors: true,
+			strictPermissions:   true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/" + testProfile),
+				}
+				setAttestExpectations(mock, output, nil)
+				setResolveSelectorsExpectations(mock, &iam.GetInstanceProfileOutput{
+					InstanceProfile: &iam.InstanceProfile{
+						Roles: []*iam.Role{
+							{Arn: aws.String("role1"), RoleName: aws.String("role1")},
+						},
+					},
+				})
+				mock.EXPECT().ListRoleTagsWithContext(gomock.Any(), gomock.Any()).Return(nil, awserr.New("AccessDenied", "not authorized to perform iam:ListRoleTags", nil))
+			},
+			skipBlockDev: true,
+			expectErr:    "AccessDenied",
+		},
+		{
+			desc:            "success, role tag selectors",
+			includeRoleTags: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/" + testProfile),
+				}
+				setAttestExpectations(mock, output, nil)
+				setResolveSelectorsExpectations(mock, &iam.GetInstanceProfileOutput{
+					InstanceProfile: &iam.InstanceProfile{
+						Roles: []*iam.Role{
+							{Arn: aws.String("role1"), RoleName: aws.String("role1")},
+							{Arn: aws.String("role2"), RoleName: aws.String("role2")},
+						},
+					},
+				})
+				mock.EXPECT().ListRoleTagsWithContext(gomock.Any(), &iam.ListRoleTagsInput{
+					RoleName: aws.String("role1"),
+				}).Return(&iam.ListRoleTagsOutput{
+					Tags: []*iam.Tag{
+						{Key: aws.String("team"), Value: aws.String("blog")},
+						{Key: aws.String("env"), Value: aws.String("prod")},
+					},
+				}, nil)
+				// role2 has no tags
+				mock.EXPECT().ListRoleTagsWithContext(gomock.Any(), &iam.ListRoleTagsInput{
+					RoleName: aws.String("role2"),
+				}).Return(&iam.ListRoleTagsOutput{}, nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "iamrole:role1"},
+				{Type: caws.PluginName, Value: "iamrole:role2"},
+				{Type: caws.PluginName, Value: "roletag:env:prod"},
+				{Type: caws.PluginName, Value: "roletag:team:blog"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                "success, role and session tag selectors list the role tags once",
+			includeRoleTags:     true,
+			sessionTagSelectors: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/" + testProfile),
+				}
+				setAttestExpectations(mock, output, nil)
+				setResolveSelectorsExpectations(mock, &iam.GetInstanceProfileOutput{
+					InstanceProfile: &iam.InstanceProfile{
+						Roles: []*iam.Role{
+							{Arn: aws.String("role1"), RoleName: aws.String("role1")},
+						},
+					},
+				})
+				mock.EXPECT().ListRoleTagsWithContext(gomock.Any(), &iam.ListRoleTagsInput{
+					RoleName: aws.String("role1"),
+				}).Return(&iam.ListRoleTagsOutput{
+					Tags: []*iam.Tag{
+						{Key: aws.String("team"), Value: aws.String("blog")},
+					},
+				}, nil).Times(1)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "iamrole:role1"},
+				{Type: caws.PluginName, Value: "roletag:team:blog"},
+				{Type: caws.PluginName, Value: "sessiontag:team:blog"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:            "success, role tag selectors skipped when access is denied",
+			includeRoleTags: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/" + testProfile),
+				}
+				setAttestExpectations(mock, output, nil)
+				setResolveSelectorsExpectations(mock, &iam.GetInstanceProfileOutput{
+					InstanceProfile: &iam.InstanceProfile{
+						Roles: []*iam.Role{
+							{Arn: aws.String("role1"), RoleName: aws.String("role1")},
+						},
+					},
+				})
+				mock.EXPECT().ListRoleTagsWithContext(gomock.Any(), gomock.Any()).Return(nil, awserr.New("AccessDenied", "not authorized to perform iam:ListRoleTags", nil))
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "iamrole:role1"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:            "error when listing the role tags fails",
+			includeRoleTags: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/" + testProfile),
+				}
+				setAttestExpectations(mock, output, nil)
+				setResolveSelectorsExpectations(mock, &iam.GetInstanceProfileOutput{
+					InstanceProfile: &iam.InstanceProfile{
+						Roles: []*iam.Role{
+							{Arn: aws.String("role1"), RoleName: aws.String("role1")},
+						},
+					},
+				})
+				mock.EXPECT().ListRoleTagsWithContext(gomock.Any(), gomock.Any()).Return(nil, awserr.New("Throttling", "rate exceeded", nil))
+			},
+			skipBlockDev: true,
+			expectErr:    "Throttling",
+		},
+		{
+			desc:                     "success, interruption selector for a spot instance with an interruption notice",
+			spotInterruptionSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getSpotDescribeInstancesOutput(), nil)
+				setSpotInstanceRequestExpectations(mock, getSpotInstanceRequestOutput("marked-for-termination"), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "interruption:pending"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                     "success, interruption selector for a spot instance marked for hibernation",
+			spotInterruptionSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getSpotDescribeInstancesOutput(), nil)
+				setSpotInstanceRequestExpectations(mock, getSpotInstanceRequestOutput("marked-for-hibernation"), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "interruption:pending"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                     "success, no interruption selector for a spot instance without an interruption notice",
+			spotInterruptionSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getSpotDescribeInstancesOutput(), nil)
+				setSpotInstanceRequestExpectations(mock, getSpotInstanceRequestOutput("fulfilled"), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                     "success, spot instance request not described for an on-demand instance",
+			spotInterruptionSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getDefaultDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, spot instance request not described when the interruption selector is disabled",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getSpotDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                     "success, interruption selector skipped when not authorized to describe the spot instance request",
+			spotInterruptionSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getSpotDescribeInstancesOutput(), nil)
+				setSpotInstanceRequestExpectations(mock, nil, awserr.New("UnauthorizedOperation", "not authorized to perform ec2:DescribeSpotInstanceRequests", nil))
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                     "error when describing the spot instance request fails for a reason other than permissions",
+			spotInterruptionSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getSpotDescribeInstancesOutput(), nil)
+				setSpotInstanceRequestExpectations(mock, nil, awserr.New("RequestLimitExceeded", "request limit exceeded", nil))
+			},
+			skipBlockDev: true,
+			expectErr:    "RequestLimitExceeded",
+		},
+		{
+			desc:                     "error when describing the spot instance request fails with strict permissions",
+			spotInterruptionSelector: true,
+			strictPermissions:        true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getSpotDescribeInstancesOutput(), nil)
+				setSpotInstanceRequestExpectations(mock, nil, awserr.New("UnauthorizedOperation", "not authorized to perform ec2:DescribeSpotInstanceRequests", nil))
+			},
+			skipBlockDev: true,
+			expectErr:    "UnauthorizedOperation",
+		},
+		{
+			desc:                "success, spot request selector for a spot instance",
+			spotRequestSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getSpotDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "spotrequest:" + testSpotInstanceRequest},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                "success, no spot request selector for an on-demand instance",
+			spotRequestSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getDefaultDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, no spot request selector when it is disabled",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getSpotDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                     "success, imds selectors for an instance requiring IMDSv2",
+			metadataOptionsSelectors: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getMetadataOptionsDescribeInstancesOutput(ec2.HttpTokensStateRequired, 1), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "imds:hop_limit:1"},
+				{Type: caws.PluginName, Value: "imds:http_tokens:required"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                     "success, imds selectors for an instance with optional tokens",
+			metadataOptionsSelectors: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getMetadataOptionsDescribeInstancesOutput(ec2.HttpTokensStateOptional, 2), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "imds:hop_limit:2"},
+				{Type: caws.PluginName, Value: "imds:http_tokens:optional"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                     "success, no imds selectors when the metadata options are unknown",
+			metadataOptionsSelectors: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getDefaultDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, no imds selectors when they are disabled",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getMetadataOptionsDescribeInstancesOutput(ec2.HttpTokensStateRequired, 1), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                        "success, capacity reservation selector",
+			capacityReservationSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getCapacityReservationDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "capacityreservation:" + testCapacityReservation},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                        "success, no capacity reservation selector for an instance without a reservation",
+			capacityReservationSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getDefaultDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, no capacity reservation selector when it is disabled",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getCapacityReservationDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:             "success, public selector for an instance with a public IP",
+			publicIPSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getPublicIPDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "public:true"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:             "success, public selector for a private-only instance",
+			publicIPSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getDefaultDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "public:false"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:             "success, public selector for an instance with an empty public IP",
+			publicIPSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].PublicIpAddress = aws.String("")
+				setAttestExpectations(mock, output, nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "public:false"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, no public selector when it is disabled",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getPublicIPDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:               "success, launch time selector truncated to the hour by default",
+			launchTimeSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getLaunchTimeDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "launchtime:2021-03-04T05:00:00Z"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                  "success, launch time selector truncated to the day",
+			launchTimeSelector:    true,
+			launchTimeGranularity: "24h",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getLaunchTimeDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "launchtime:2021-03-04T00:00:00Z"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                  "success, launch time selector truncated to the minute",
+			launchTimeSelector:    true,
+			launchTimeGranularity: "1m",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getLaunchTimeDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "launchtime:2021-03-04T05:06:00Z"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:               "success, no launch time selector for an instance without a launch time",
+			launchTimeSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getDefaultDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, no launch time selector when it is disabled",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getLaunchTimeDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                 "success, user data hash selector",
+			userDataHashSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+				setUserDataExpectations(mock, aws.String(base64.StdEncoding.EncodeToString([]byte(testUserData))), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "userdatahash:" + testUserDataHash},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                 "success, no user data hash selector for an instance without user data",
+			userDataHashSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+				setUserDataExpectations(mock, nil, nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                 "success, user data hash selector skipped when not authorized to describe the user data",
+			userDataHashSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+				setUserDataExpectations(mock, nil, awserr.New("UnauthorizedOperation", "not authorized to perform ec2:DescribeInstanceAttribute", nil))
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                 "error when describing the user data fails for a reason other than permissions",
+			userDataHashSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+				setUserDataExpectations(mock, nil, awserr.New("RequestLimitExceeded", "request limit exceeded", nil))
+			},
+			skipBlockDev: true,
+			expectErr:    "RequestLimitExceeded",
+		},
+		{
+			desc:                 "error when describing the user data fails with strict permissions",
+			userDataHashSelector: true,
+			strictPermissions:    true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+				setUserDataExpectations(mock, nil, awserr.New("UnauthorizedOperation", "not authorized to perform ec2:DescribeInstanceAttribute", nil))
+			},
+			skipBlockDev: true,
+			expectErr:    "UnauthorizedOperation",
+		},
+		{
+			desc:                 "error when the user data is malformed",
+			userDataHashSelector: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+				setUserDataExpectations(mock, aws.String("not base64!"), nil)
+			},
+			skipBlockDev: true,
+			expectErr:    `failed to decode the user data of instance "test-instance"`,
+		},
+		{
+			desc: "success, no user data hash selector when it is disabled",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:         "success, ssm selectors",
+			ssmSelectors: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+				setSSMInstanceInformationExpectations(mock, []*ssm.InstanceInformation{
+					{
+						InstanceId:   aws.String(testInstance),
+						PlatformType: aws.String(ssm.PlatformTypeLinux),
+						PlatformName: aws.String("Amazon Linux"),
+					},
+				}, nil)
+				setSSMInventoryExpectations(mock, "AWS:PatchSummary", []map[string]*string{
+					{"PatchGroup": aws.String("production")},
+				}, nil)
+				setSSMInventoryExpectations(mock, "AWS:Application", []map[string]*string{
+					{"Name": aws.String("nginx")},
+					{"Name": aws.String("openssl")},
+				}, nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "ssm:application:nginx"},
+				{Type: caws.PluginName, Value: "ssm:application:openssl"},
+				{Type: caws.PluginName, Value: "ssm:patchgroup:production"},
+				{Type: caws.PluginName, Value: "ssm:platform:Linux"},
+				{Type: caws.PluginName, Value: "ssm:platformname:Amazon Linux"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:         "success, ssm selectors from all the pages of the inventory",
+			ssmSelectors: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+				setSSMInstanceInformationExpectations(mock, []*ssm.InstanceInformation{
+					{InstanceId: aws.String(testInstance)},
+				}, nil)
+				setSSMInventoryExpectations(mock, "AWS:PatchSummary", nil, nil)
+				input := getSSMInventoryInput("AWS:Application")
+				mock.EXPECT().GetInventoryWithContext(gomock.Any(), input).Return(&ssm.GetInventoryOutput{
+					Entities:  []*ssm.InventoryResultEntity{getSSMInventoryEntity("AWS:Application", []map[string]*string{{"Name": aws.String("nginx")}})},
+					NextToken: aws.String("next"),
+				}, nil)
+				nextInput := getSSMInventoryInput("AWS:Application")
+				nextInput.NextToken = aws.String("next")
+				mock.EXPECT().GetInventoryWithContext(gomock.Any(), nextInput).Return(&ssm.GetInventoryOutput{
+					Entities: []*ssm.InventoryResultEntity{getSSMInventoryEntity("AWS:Application", []map[string]*string{{"Name": aws.String("openssl")}})},
+				}, nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "ssm:application:nginx"},
+				{Type: caws.PluginName, Value: "ssm:application:openssl"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:         "success, no ssm selectors for an instance not managed by ssm",
+			ssmSelectors: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+				setSSMInstanceInformationExpectations(mock, nil, nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:         "success, ssm selectors skipped when getting the inventory fails",
+			ssmSelectors: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+				setSSMInstanceInformationExpectations(mock, []*ssm.InstanceInformation{
+					{InstanceId: aws.String(testInstance), PlatformType: aws.String(ssm.PlatformTypeLinux)},
+				}, nil)
+				setSSMInventoryExpectations(mock, "AWS:PatchSummary", nil, awserr.New("AccessDeniedException", "not authorized to perform ssm:GetInventory", nil))
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:              "error when describing the instance information fails with strict permissions",
+			ssmSelectors:      true,
+			strictPermissions: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+				setSSMInstanceInformationExpectations(mock, nil, awserr.New("AccessDeniedException", "not authorized to perform ssm:DescribeInstanceInformation", nil))
+			},
+			skipBlockDev: true,
+			expectErr:    "AccessDeniedException",
+		},
+		{
+			desc:               "success, no ssm selectors when the category is filtered out",
+			ssmSelectors:       true,
+			selectorCategories: []string{"sg"},
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, no ssm selectors when they are disabled",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getInstanceIDDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev:    true,
+			expectSelectors: []*common.Selector{},
+			expectID:        "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, warning when describe-instances returns more than one instance",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getMultipleInstancesDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "tag:Hostname:host1"},
+				{Type: caws.PluginName, Value: "tag:Hostname:host2"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Describe instances returned more than one instance for the instance ID; selectors are resolved from all of them",
+					Data: logrus.Fields{
+						"instance_id": testInstance,
+						"count":       "2",
+					},
+				},
+			},
+		},
+		{
+			desc: "success, vpc selector",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getVPCDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "vpc:" + testVPC},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, eni selectors are deduped across interfaces",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getNetworkInterfacesDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "eni:description:service=billing"},
+				{Type: caws.PluginName, Value: "eni:id:eni-0123456789abcdef0"},
+				{Type: caws.PluginName, Value: "eni:id:eni-0123456789abcdef1"},
+				{Type: caws.PluginName, Value: "eni:id:eni-0123456789abcdef2"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, vpc selector is deduped across instances",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getMultipleInstancesDescribeInstancesOutput()
+				for _, reservation := range output.Reservations {
+					reservation.Instances[0].VpcId = aws.String(testVPC)
+				}
+				setAttestExpectations(mock, output, nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "tag:Hostname:host1"},
+				{Type: caws.PluginName, Value: "tag:Hostname:host2"},
+				{Type: caws.PluginName, Value: "vpc:" + testVPC},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Describe instances returned more than one instance for the instance ID; selectors are resolved from all of them",
+					Data: logrus.Fields{
+						"instance_id": testInstance,
+						"count":       "2",
+					},
+				},
+			},
+		},
+		{
+			desc: "success, owner account selector distinct from the account of the agent ID",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].OwnerId = aws.String("owner-account")
+				output.Reservations[0].Instances[0].VpcId = aws.String(testVPC)
+				setAttestExpectations(mock, output, nil)
+			},
+			skipBlockDev: true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "owneraccount:owner-account"},
+				{Type: caws.PluginName, Value: "vpc:" + testVPC},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc:                    "error when describe-instances returns more than one instance and they are rejected",
+			rejectMultipleInstances: true,
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setAttestExpectations(mock, getMultipleInstancesDescribeInstancesOutput(), nil)
+			},
+			skipBlockDev: true,
+			expectErr:    `querying AWS via describe-instances: aws-iid: returned 2 instances for instance ID "test-instance"`,
+		},
+		{
+			desc: "success, paginated describe-instances",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				input := &ec2.DescribeInstancesInput{
+					Filters: append([]*ec2.Filter{
+						{
+							Name:   aws.String("instance-id"),
+							Values: []*string{&testInstance},
+						},
+					}, instanceFilters...),
+					MaxResults: aws.Int64(5),
+				}
+				firstPage := getDefaultDescribeInstancesOutput()
+				firstPage.Reservations[0].Instances[0].Tags = []*ec2.Tag{
+					{Key: aws.String("Hostname"), Value: aws.String("host1")},
+				}
+				firstPage.NextToken = aws.String("page-2")
+				mock.EXPECT().DescribeInstancesWithContext(gomock.Any(), input).Return(firstPage, nil)
+
+				nextInput := *input
+				nextInput.NextToken = aws.String("page-2")
+				secondPage := getDefaultDescribeInstancesOutput()
+				secondPage.Reservations[0].Instances[0].SecurityGroups = []*ec2.GroupIdentifier{
+					{GroupName: aws.String("Test Group Name"), GroupId: aws.String("TestGroup")},
+				}
+				mock.EXPECT().DescribeInstancesWithContext(gomock.Any(), &nextInput).Return(secondPage, nil)
+			},
+			skipBlockDev: true,
+			maxResults:   5,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "sg:id:TestGroup"},
+				{Type: caws.PluginName, Value: "sg:name:Test Group Name"},
+				{Type: caws.PluginName, Value: "tag:Hostname:host1"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, only tag selectors are fetched with describe-tags",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setDescribeInstanceStatusExpectations(mock, ec2.InstanceStateNameRunning, nil)
+				setDescribeTagsExpectations(mock, &ec2.DescribeTagsOutput{
+					Tags: []*ec2.TagDescription{
+						{Key: aws.String("Hostname"), Value: aws.String("host1")},
+						{Key: aws.String("Team"), Value: aws.String("blog")},
+					},
+				}, nil)
+			},
+			skipBlockDev:        true,
+			selectorCategories:  []string{"tag"},
+			replacementTemplate: "{{ .PluginName }}/{{ .Tags.Hostname }}",
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "tag:Hostname:host1"},
+				{Type: caws.PluginName, Value: "tag:Team:blog"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/host1",
+		},
+		{
+			desc: "success, only tag selectors are fetched with describe-tags across pages",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setDescribeInstanceStatusExpectations(mock, ec2.InstanceStateNamePending, nil)
+				input := describeTagsInput()
+				input.MaxResults = aws.Int64(5)
+				mock.EXPECT().DescribeTagsWithContext(gomock.Any(), input).Return(&ec2.DescribeTagsOutput{
+					Tags: []*ec2.TagDescription{
+						{Key: aws.String("Hostname"), Value: aws.String("host1")},
+					},
+					NextToken: aws.String("page-2"),
+				}, nil)
+
+				nextInput := describeTagsInput()
+				nextInput.MaxResults = aws.Int64(5)
+				nextInput.NextToken = aws.String("page-2")
+				mock.EXPECT().DescribeTagsWithContext(gomock.Any(), nextInput).Return(&ec2.DescribeTagsOutput{
+					Tags: []*ec2.TagDescription{
+						{Key: aws.String("Team"), Value: aws.String("blog")},
+					},
+				}, nil)
+			},
+			skipBlockDev:       true,
+			maxResults:         5,
+			selectorCategories: []string{"tag"},
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "tag:Hostname:host1"},
+				{Type: caws.PluginName, Value: "tag:Team:blog"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "error on describe-tags call",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setDescribeInstanceStatusExpectations(mock, ec2.InstanceStateNameRunning, nil)
+				setDescribeTagsExpectations(mock, nil, errors.New("client error"))
+			},
+			skipBlockDev:       true,
+			selectorCategories: []string{"tag"},
+			expectErr:          "querying AWS via describe-tags: client error",
+		},
+		{
+			desc: "error on describe-instance-status call before describe-tags",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setDescribeInstanceStatusExpectations(mock, "", errors.New("client error"))
+			},
+			skipBlockDev:       true,
+			selectorCategories: []string{"tag"},
+			expectErr:          "querying AWS via describe-tags: client error",
+		},
+		{
+			desc: "terminated instance, tags are not fetched with describe-tags",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				// The instance is filtered out for not being pending or
+				// running, as DescribeInstances does
+				setDescribeInstanceStatusExpectations(mock, "", nil)
+			},
+			skipBlockDev:        true,
+			selectorCategories:  []string{"tag"},
+			replacementTemplate: "{{ .PluginName }}/{{ .Tags.Hostname }}",
+			expectErr:           "querying AWS via describe-instances: aws-iid: returned no reservations",
+		},
+		{
+			desc: "terminated instance, no tag selectors from describe-tags",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setDescribeInstanceStatusExpectations(mock, "", nil)
+			},
+			skipBlockDev:       true,
+			selectorCategories: []string{"tag"},
+			expectID:           "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, only tag selectors but block device check describes the instance",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].Tags = []*ec2.Tag{
+					{Key: aws.String("Hostname"), Value: aws.String("host1")},
+				}
+				output.Reservations[0].Instances[0].SecurityGroups = []*ec2.GroupIdentifier{
+					{GroupName: aws.String("Test Group Name"), GroupId: aws.String("TestGroup")},
+				}
+				output.Reservations[0].Instances[0].RootDeviceType = &instanceStoreType
+				output.Reservations[0].Instances[0].NetworkInterfaces[0].Attachment.DeviceIndex = &zeroDeviceIndex
+				setAttestExpectations(mock, output, nil)
+			},
+			selectorCategories: []string{"tag"},
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "tag:Hostname:host1"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, selectors limited to the selector categories",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].Tags = []*ec2.Tag{
+					{Key: aws.String("Hostname"), Value: aws.String("host1")},
+				}
+				output.Reservations[0].Instances[0].SecurityGroups = []*ec2.GroupIdentifier{
+					{GroupName: aws.String("Test Group Name"), GroupId: aws.String("TestGroup")},
+				}
+				output.Reservations[0].Instances[0].PrivateDnsName = aws.String("ip-10-0-0-1.ec2.internal")
+				// The instance profile is not described since no instance
+				// profile selectors are requested
+				output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+					Arn: aws.String("arn:aws::::instance-profile/" + testProfile),
+				}
+				setAttestExpectations(mock, output, nil)
+			},
+			skipBlockDev:       true,
+			selectorCategories: []string{"tag", "sg"},
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "sg:id:TestGroup"},
+				{Type: caws.PluginName, Value: "sg:name:Test Group Name"},
+				{Type: caws.PluginName, Value: "tag:Hostname:host1"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+		{
+			desc: "success, tag keys are lowercased",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				output := getDefaultDescribeInstancesOutput()
+				output.Reservations[0].Instances[0].Tags = []*ec2.Tag{
+					{Key: aws.String("Hostname"), Value: aws.String("Host1")},
+					{Key: aws.String("ENVIRONMENT"), Value: aws.String("Prod")},
+					{Key: aws.String("team"), Value: aws.String("blog")},
+				}
+				setAttestExpectations(mock, output, nil)
+			},
+			skipBlockDev:     true,
+			lowercaseTagKeys: true,
+			// The tags of the agent path template keep their case
+			replacementTemplate: "{{ .PluginName }}/{{ .Tags.Hostname }}",
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "tag:environment:Prod"},
+				{Type: caws.PluginName, Value: "tag:hostname:Host1"},
+				{Type: caws.PluginName, Value: "tag:team:blog"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/Host1",
+		},
+		{
+			desc: "success, tag keys fetched with describe-tags are lowercased",
+			mockExpect: func(mock *mock_aws.MockClient) {
+				setDescribeInstanceStatusExpectations(mock, ec2.InstanceStateNameRunning, nil)
+				setDescribeTagsExpectations(mock, &ec2.DescribeTagsOutput{
+					Tags: []*ec2.TagDescription{
+						{Key: aws.String("Environment"), Value: aws.String("Prod")},
+					},
+				}, nil)
+			},
+			skipBlockDev:       true,
+			selectorCategories: []string{"tag"},
+			lowercaseTagKeys:   true,
+			expectSelectors: []*common.Selector{
+				{Type: caws.PluginName, Value: "tag:environment:Prod"},
+			},
+			expectID: "spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		s.T().Run(tt.desc, func(t *testing.T) {
+			mockCtl := gomock.NewController(s.T())
+			defer mockCtl.Finish()
+			s.logHook.Reset()
+
+			client := mock_aws.NewMockClient(mockCtl)
+
+			mockGetEC2Client := func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+				return client, nil
+			}
+			s.plugin.clients = newClientsCache(mockGetEC2Client)
+
+			if tt.mockExpect != nil {
+				tt.mockExpect(client)
+			}
+
+			var configStr string
+			if tt.replacementTemplate != "" {
+				configStr = fmt.Sprintf(`agent_path_template = "%s"`, tt.replacementTemplate)
+			}
+			if len(tt.allowList) > 0 {
+				configStr += "\naccount_ids_for_local_validation = [\n"
+				for _, id := range tt.allowList {
+					configStr = `  ` + configStr + `"` + id + `",`
+				}
+				configStr += "\n]"
+			}
+			if tt.skipBlockDev {
+				configStr += "\nskip_block_device = true"
+			}
+			if tt.skipEC2Block {
+				configStr += "\nskip_ec2_attest_calling = true"
+			}
+
+			if tt.disableInstanceProfileSelectors {
+				configStr += "\ndisable_instance_profile_selectors = true"
+			}
+			if tt.maxResults != 0 {
+				configStr += fmt.Sprintf("\nmax_results = %d", tt.maxResults)
+			}
+			if tt.strictPermissions {
+				configStr += "\nstrict_permissions = true"
+			}
+			if tt.publicHostnameSelector {
+				configStr += "\nenable_public_hostname_selector = true"
+			}
+			if tt.sessionTagSelectors {
+				configStr += "\nenable_session_tag_selectors = true"
+			}
+			if tt.includeRoleTags {
+				configStr += "\ninclude_role_tags = true"
+			}
+			if tt.spotInterruptionSelector {
+				configStr += "\nenable_spot_interruption_selector = true"
+			}
+			if tt.spotRequestSelector {
+				configStr += "\nenable_spot_request_selector = true"
+			}
+			if tt.metadataOptionsSelectors {
+				configStr += "\nenable_metadata_options_selectors = true"
+			}
+			if tt.capacityReservationSelector {
+				configStr += "\nenable_capacity_reservation_selector = true"
+			}
+			if tt.userDataHashSelector {
+				configStr += "\nenable_user_data_hash_selector = true"
+			}
+			if tt.launchTimeSelector {
+				configStr += "\nenable_launch_time_selector = true"
+			}
+			if tt.launchTimeGranularity != "" {
+				configStr += fmt.Sprintf("\nlaunch_time_granularity = %q", tt.launchTimeGranularity)
+			}
+			if tt.publicIPSelector {
+				configStr += "\nenable_public_ip_selector = true"
+			}
+			if tt.ssmSelectors {
+				configStr += "\nenable_ssm_selectors = true"
+			}
+			if tt.rejectMultipleInstances {
+				configStr += "\nreject_multiple_instances = true"
+			}
+			if len(tt.selectorCategories) > 0 {
+				configStr += fmt.Sprintf("\nselector_categories = [\"%s\"]", strings.Join(tt.selectorCategories, `", "`))
+			}
+			if tt.lowercaseTagKeys {
+				configStr += "\nlowercase_tag_keys = true"
+			}
+
+			_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+				Configuration: configStr,
+				GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+			})
+			s.Require().NoError(err)
+
+			data := &common.AttestationData{
+				Type: caws.PluginName,
+				Data: s.iidAttestationDataToBytes(*s.buildDefaultIIDAttestationData()),
+			}
+
+			// using our own keypair (since we don't have AWS private key)
+			originalAWSPublicKey := s.plugin.config.awsCaCertPublicKey
+			defer func() {
+				s.plugin.config.awsCaCertPublicKey = originalAWSPublicKey
+			}()
+			s.plugin.config.awsCaCertPublicKey = &s.rsaKey.PublicKey
+
+			resp, err := s.attest(&nodeattestorv0.AttestRequest{
+				AttestationData: data,
+			})
+
+			if tt.expectErr != "" {
+				s.Nil(resp)
+				s.RequireErrorContains(err, tt.expectErr)
+				return
+			}
+
+			s.Equal(tt.expectID, resp.AgentId)
+			if tt.expectSelectors != nil {
+				s.Len(resp.Selectors, len(tt.expectSelectors))
+				for i, sel := range resp.Selectors {
+					s.Equal(tt.expectSelectors[i], sel)
+				}
+			}
+			if tt.expectLogs != nil {
+				spiretest.AssertLogs(t, s.logHook.AllEntries(), tt.expectLogs)
+			}
+		})
+	}
+}
+
+func (s *IIDAttestorSuite) TestCrossAccountInstanceProfileSelectors() {
+	const sharedRoleARN = "arn:aws:iam::999999999999:role/spire-server"
+
+	mockCtl := gomock.NewController(s.T())
+	defer mockCtl.Finish()
+
+	// the default client describes the instance, while the instance profile,
+	// owned by another account, is described assuming the mapped role
+	client := mock_aws.NewMockClient(mockCtl)
+	roleClient := mock_aws.NewMockClient(mockCtl)
+	var assumedRoles []string
+	s.plugin.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+		assumedRoles = append(assumedRoles, cred.AssumeRoleARN)
+		if cred.AssumeRoleARN == sharedRoleARN {
+			return roleClient, nil
+		}
+		return client, nil
+	})
+
+	output := getDefaultDescribeInstancesOutput()
+	output.Reservations[0].Instances[0].IamInstanceProfile = &ec2.IamInstanceProfile{
+		Arn: aws.String("arn:aws:iam::111111111111:instance-profile/" + testProfile),
+	}
+	setAttestExpectations(client, output, nil)
+	setResolveSelectorsExpectations(roleClient, &iam.GetInstanceProfileOutput{
+		InstanceProfile: &iam.InstanceProfile{
+			Roles: []*iam.Role{
+				{Arn: aws.String("arn:aws:iam::111111111111:role/shared")},
+			},
+		},
+	})
+
+	_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+skip_block_device = true
+account_role_map = {
+	"111111111111" = "arn:aws:iam::999999999999:role/spire-server"
+}
+`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.Require().NoError(err)
+
+	// using our own keypair (since we don't have AWS private key)
+	originalAWSPublicKey := s.plugin.config.awsCaCertPublicKey
+	defer func() {
+		s.plugin.config.awsCaCertPublicKey = originalAWSPublicKey
+	}()
+	s.plugin.config.awsCaCertPublicKey = &s.rsaKey.PublicKey
+
+	resp, err := s.attest(&nodeattestorv0.AttestRequest{
+		AttestationData: &common.AttestationData{
+			Type: caws.PluginName,
+			Data: s.iidAttestationDataToBytes(*s.buildDefaultIIDAttestationData()),
+		},
+	})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"", sharedRoleARN}, assumedRoles)
+	s.RequireProtoListEqual([]*common.Selector{
+		{Type: caws.PluginName, Value: "iamrole:arn:aws:iam::111111111111:role/shared"},
+	}, resp.Selectors)
+}
+
+func (s *IIDAttestorSuite) TestRegionCredentialChain() {
+	mockCtl := gomock.NewController(s.T())
+	defer mockCtl.Finish()
+
+	// the default credentials do not work in the region, so the client
+	// assuming the role through the regional STS endpoint is used
+	defaultClient := mock_aws.NewMockClient(mockCtl)
+	regionalClient := mock_aws.NewMockClient(mockCtl)
+	var creds []RegionCredential
+	s.plugin.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+		s.Require().Equal(testRegion, region)
+		creds = append(creds, cred)
+		if cred.AssumeRoleARN != "" {
+			return regionalClient, nil
+		}
+		return defaultClient, nil
+	})
+
+	defaultClient.EXPECT().GetCallerIdentityWithContext(gomock.Any(), &sts.GetCallerIdentityInput{}).Return(nil, errors.New("InvalidClientTokenId: the security token included in the request is invalid"))
+	regionalClient.EXPECT().GetCallerIdentityWithContext(gomock.Any(), &sts.GetCallerIdentityInput{}).Return(&sts.GetCallerIdentityOutput{}, nil)
+	setAttestExpectations(regionalClient, getDefaultDescribeInstancesOutput(), nil)
+
+	_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+skip_block_device = true
+disable_instance_profile_selectors = true
+region_credentials = {
+	"test-region" = [
+		{},
+		{
+			assume_role_arn = "arn:aws:iam::999999999999:role/spire-server"
+			sts_endpoint = "https://sts.test-region.amazonaws.com"
+		},
+	]
+}
+`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.Require().NoError(err)
+
+	// using our own keypair (since we don't have AWS private key)
+	originalAWSPublicKey := s.plugin.config.awsCaCertPublicKey
+	defer func() {
+		s.plugin.config.awsCaCertPublicKey = originalAWSPublicKey
+	}()
+	s.plugin.config.awsCaCertPublicKey = &s.rsaKey.PublicKey
+
+	_, err = s.attest(&nodeattestorv0.AttestRequest{
+		AttestationData: &common.AttestationData{
+			Type: caws.PluginName,
+			Data: s.iidAttestationDataToBytes(*s.buildDefaultIIDAttestationData()),
+		},
+	})
+	s.Require().NoError(err)
+
+	regionalCred := RegionCredential{
+		AssumeRoleARN: "arn:aws:iam::999999999999:role/spire-server",
+		STSEndpoint:   "https://sts.test-region.amazonaws.com",
+	}
+	s.Require().Equal([]RegionCredential{{}, regionalCred}, creds)
+
+	// the working client is cached, so the chain is not walked again
+	client, err := s.plugin.clients.getClient(testRegion)
+	s.Require().NoError(err)
+	s.Require().Equal(regionalClient, client)
+	s.Require().Len(creds, 2)
+}
+
+func (s *IIDAttestorSuite) TestRegionCredentialChainExhausted() {
+	mockCtl := gomock.NewController(s.T())
+	defer mockCtl.Finish()
+
+	client := mock_aws.NewMockClient(mockCtl)
+	s.plugin.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+		if cred.AssumeRoleARN != "" {
+			return nil, errors.New("oh no")
+		}
+		return client, nil
+	})
+	client.EXPECT().GetCallerIdentityWithContext(gomock.Any(), &sts.GetCallerIdentityInput{}).Return(nil, errors.New("AccessDenied"))
+
+	_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+region_credentials = {
+	"test-region" = [
+		{},
+		{ assume_role_arn = "arn:aws:iam::999999999999:role/spire-server" },
+	]
+}
+`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.Require().NoError(err)
+
+	_, err = s.plugin.clients.getClient(testRegion)
+	s.Require().EqualError(err, `aws-iid: no working credentials for region "test-region": credential 0: AccessDenied; credential 1: oh no`)
+}
+
+func (s *IIDAttestorSuite) TestPrewarmRegions() {
+	mockCtl := gomock.NewController(s.T())
+	defer mockCtl.Finish()
+
+	clients := map[string]*mock_aws.MockClient{
+		"us-east-1": mock_aws.NewMockClient(mockCtl),
+		"us-west-2": mock_aws.NewMockClient(mockCtl),
+	}
+	var regions []string
+	s.plugin.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+		regions = append(regions, region)
+		return clients[region], nil
+	})
+
+	// A failed validation does not fail the configuration
+	clients["us-east-1"].EXPECT().GetCallerIdentityWithContext(gomock.Any(), &sts.GetCallerIdentityInput{}).Return(&sts.GetCallerIdentityOutput{}, nil)
+	clients["us-west-2"].EXPECT().GetCallerIdentityWithContext(gomock.Any(), &sts.GetCallerIdentityInput{}).Return(nil, errors.New("ExpiredToken: the security token included in the request is expired"))
+
+	_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `prewarm_regions = ["us-east-1", "us-west-2"]`,
+		GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"us-east-1", "us-west-2"}, regions)
+
+	// The prewarmed clients are used without creating them again
+	for region, expected := range clients {
+		client, err := s.plugin.clients.getClient(region)
+		s.Require().NoError(err)
+		s.Require().Equal(expected, client)
+	}
+	s.Require().Len(regions, 2)
+}
+
+func (s *IIDAttestorSuite) TestPrewarmRegionsWithCredentialChain() {
+	mockCtl := gomock.NewController(s.T())
+	defer mockCtl.Finish()
+
+	client := mock_aws.NewMockClient(mockCtl)
+	s.plugin.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+		return client, nil
+	})
+
+	// The credential chain validates the client, so it is not validated twice
+	client.EXPECT().GetCallerIdentityWithContext(gomock.Any(), &sts.GetCallerIdentityInput{}).Return(&sts.GetCallerIdentityOutput{}, nil)
+
+	_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+prewarm_regions = ["test-region"]
+region_credentials = {
+	"test-region" = [
+		{ assume_role_arn = "arn:aws:iam::999999999999:role/spire-server" },
+	]
+}
+`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.Require().NoError(err)
+
+	s.plugin.clients.mu.RLock()
+	defer s.plugin.clients.mu.RUnlock()
+	s.Require().Contains(s.plugin.clients.clients, "test-region|chain")
+}
+
+func (s *IIDAttestorSuite) TestFallbackRegions() {
+	unavailableErr := awserr.New("RequestError", "send request failed", errors.New("dial tcp: i/o timeout"))
+
+	for _, tt := range []struct {
+		desc            string
+		fallbackRegions string
+		setup           func(clients map[string]*mock_aws.MockClient)
+		expectRegions   []string
+		expectErr       string
+	}{
+		{
+			desc:            "primary region unavailable, first fallback succeeds",
+			fallbackRegions: `["us-east-1", "us-west-2"]`,
+			setup: func(clients map[string]*mock_aws.MockClient) {
+				setAttestExpectations(clients[testRegion], nil, unavailableErr)
+				setAttestExpectations(clients["us-east-1"], getDefaultDescribeInstancesOutput(), nil)
+			},
+			expectRegions: []string{testRegion, "us-east-1"},
+		},
+		{
+			desc:            "fallback regions are tried in order",
+			fallbackRegions: `["us-east-1", "us-west-2"]`,
+			setup: func(clients map[string]*mock_aws.MockClient) {
+				setAttestExpectations(clients[testRegion], nil, unavailableErr)
+				setAttestExpectations(clients["us-east-1"], nil, awserr.New("ServiceUnavailable", "service is unavailable", nil))
+				setAttestExpectations(clients["us-west-2"], getDefaultDescribeInstancesOutput(), nil)
+			},
+			expectRegions: []string{testRegion, "us-east-1", "us-west-2"},
+		},
+		{
+			desc:            "primary region error unrelated to availability",
+			fallbackRegions: `["us-east-1"]`,
+			setup: func(clients map[string]*mock_aws.MockClient) {
+				setAttestExpectations(clients[testRegion], nil, awserr.New("UnauthorizedOperation", "not authorized", nil))
+			},
+			expectRegions: []string{testRegion},
+			expectErr:     "querying AWS via describe-instances: UnauthorizedOperation: not authorized",
+		},
+		{
+			desc:            "all regions unavailable",
+			fallbackRegions: `["us-east-1"]`,
+			setup: func(clients map[string]*mock_aws.MockClient) {
+				setAttestExpectations(clients[testRegion], nil, unavailableErr)
+				setAttestExpectations(clients["us-east-1"], nil, unavailableErr)
+			},
+			expectRegions: []string{testRegion, "us-east-1"},
+			expectErr:     "querying AWS via describe-instances: RequestError: send request failed",
+		},
+		{
+			desc:            "no fallback regions",
+			fallbackRegions: `[]`,
+			setup: func(clients map[string]*mock_aws.MockClient) {
+				setAttestExpectations(clients[testRegion], nil, unavailableErr)
+			},
+			expectRegions: []string{testRegion},
+			expectErr:     "querying AWS via describe-instances: RequestError: send request failed",
+		},
+	} {
+		tt := tt
+		s.T().Run(tt.desc, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+
+			clients := map[string]*mock_aws.MockClient{
+				testRegion:  mock_aws.NewMockClient(mockCtl),
+				"us-east-1": mock_aws.NewMockClient(mockCtl),
+				"us-west-2": mock_aws.NewMockClient(mockCtl),
+			}
+			var regions []string
+			s.plugin.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+				regions = append(regions, region)
+				return clients[region], nil
+			})
+			tt.setup(clients)
+
+			_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+				Configuration: fmt.Sprintf(`
+skip_block_device = true
+disable_instance_profile_selectors = true
+fallback_regions = %s
+`, tt.fallbackRegions),
+				GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+			})
+			s.Require().NoError(err)
+
+			// using our own keypair (since we don't have AWS private key)
+			s.plugin.config.awsCaCertPublicKey = &s.rsaKey.PublicKey
+
+			resp, err := s.attest(&nodeattestorv0.AttestRequest{
+				AttestationData: &common.AttestationData{
+					Type: caws.PluginName,
+					Data: s.iidAttestationDataToBytes(*s.buildDefaultIIDAttestationData()),
+				},
+			})
+			s.Require().Equal(tt.expectRegions, regions)
+			if tt.expectErr != "" {
+				s.RequireErrorContains(err, tt.expectErr)
+				return
+			}
+			s.Require().NoError(err)
+			// The agent ID keeps the region of the instance
+			s.Require().Equal("spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance", resp.AgentId)
+		})
+	}
+}
+
+func (s *IIDAttestorSuite) TestInstanceNotFoundRetry() {
+	notFoundErr := awserr.New("InvalidInstanceID.NotFound", "The instance ID 'test-instance' does not exist", nil)
+
+	for _, tt := range []struct {
+		desc   string
+		config string
+		// outputs and errs are the results of the consecutive
+		// DescribeInstances calls
+		outputs []*ec2.DescribeInstancesOutput
+		errs    []error
+		// waits are how long the clock is advanced for each retry
+		waits     []time.Duration
+		expectErr string
+	}{
+		{
+			desc:    "instance not found on the first call",
+			config:  `instance_not_found_retry_deadline = "10s"`,
+			outputs: []*ec2.DescribeInstancesOutput{nil, getDefaultDescribeInstancesOutput()},
+			errs:    []error{notFoundErr, nil},
+			waits:   []time.Duration{defaultInstanceNotFoundRetryInterval},
+		},
+		{
+			desc:    "no instances returned on the first calls",
+			config:  "instance_not_found_retry_deadline = \"10s\"\ninstance_not_found_retry_interval = \"1s\"",
+			outputs: []*ec2.DescribeInstancesOutput{{}, {Reservations: []*ec2.Reservation{{}}}, getDefaultDescribeInstancesOutput()},
+			errs:    []error{nil, nil, nil},
+			waits:   []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			desc:      "instance not found before the deadline",
+			config:    "instance_not_found_retry_deadline = \"3s\"\ninstance_not_found_retry_interval = \"1s\"",
+			outputs:   []*ec2.DescribeInstancesOutput{nil, nil, nil},
+			errs:      []error{notFoundErr, notFoundErr, notFoundErr},
+			waits:     []time.Duration{time.Second, 2 * time.Second},
+			expectErr: "querying AWS via describe-instances: InvalidInstanceID.NotFound",
+		},
+		{
+			desc:      "instance not found before the retry budget is exhausted",
+			config:    "instance_not_found_retry_deadline = \"10s\"\ninstance_not_found_retry_interval = \"1s\"\nretry_budget = 1",
+			outputs:   []*ec2.DescribeInstancesOutput{nil, nil},
+			errs:      []error{notFoundErr, notFoundErr},
+			waits:     []time.Duration{time.Second},
+			expectErr: "querying AWS via describe-instances: InvalidInstanceID.NotFound",
+		},
+		{
+			desc:      "retry disabled",
+			outputs:   []*ec2.DescribeInstancesOutput{nil},
+			errs:      []error{notFoundErr},
+			expectErr: "querying AWS via describe-instances: InvalidInstanceID.NotFound",
+		},
+		{
+			desc:      "other errors are not retried",
+			config:    `instance_not_found_retry_deadline = "10s"`,
+			outputs:   []*ec2.DescribeInstancesOutput{nil},
+			errs:      []error{awserr.New("UnauthorizedOperation", "not authorized", nil)},
+			expectErr: "querying AWS via describe-instances: UnauthorizedOperation: not authorized",
+		},
+	} {
+		tt := tt
+		s.T().Run(tt.desc, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+			client := mock_aws.NewMockClient(mockCtl)
+			s.plugin.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+				return client, nil
+			})
+			for i := range tt.outputs {
+				setAttestExpectations(client, tt.outputs[i], tt.errs[i])
+			}
+			clk := clock.NewMock(t)
+			s.plugin.hooks.clock = clk
+
+			_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+				Configuration: "skip_block_device = true\ndisable_instance_profile_selectors = true\n" + tt.config,
+				GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+			})
+			s.Require().NoError(err)
+			s.plugin.config.awsCaCertPublicKey = &s.rsaKey.PublicKey
+
+			go func() {
+				for _, wait := range tt.waits {
+					clk.WaitForAfter(time.Minute, "timed out waiting for the retry backoff")
+					clk.Add(wait)
+				}
+			}()
+
+			resp, err := s.attest(&nodeattestorv0.AttestRequest{
+				AttestationData: &common.AttestationData{
+					Type: caws.PluginName,
+					Data: s.iidAttestationDataToBytes(*s.buildDefaultIIDAttestationData()),
+				},
+			})
+			if tt.expectErr != "" {
+				s.RequireErrorContains(err, tt.expectErr)
+				return
+			}
+			s.Require().NoError(err)
+			s.Require().Equal("spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance", resp.AgentId)
+		})
+	}
+}
+
+func (s *IIDAttestorSuite) TestInstanceNotFoundRetryConfig() {
+	for _, tt := range []struct {
+		desc      string
+		config    string
+		expectErr string
+	}{
+		{
+			desc:      "invalid deadline",
+			config:    `instance_not_found_retry_deadline = "soon"`,
+			expectErr: `aws-iid: invalid instance_not_found_retry_deadline "soon"`,
+		},
+		{
+			desc:      "negative deadline",
+			config:    `instance_not_found_retry_deadline = "-1s"`,
+			expectErr: "aws-iid: instance_not_found_retry_deadline cannot be negative",
+		},
+		{
+			desc:      "invalid interval",
+			config:    "instance_not_found_retry_deadline = \"10s\"\ninstance_not_found_retry_interval = \"often\"",
+			expectErr: `aws-iid: invalid instance_not_found_retry_interval "often"`,
+		},
+		{
+			desc:      "non-positive interval",
+			config:    "instance_not_found_retry_deadline = \"10s\"\ninstance_not_found_retry_interval = \"0s\"",
+			expectErr: "aws-iid: instance_not_found_retry_interval must be positive",
+		},
+		{
+			desc:      "negative retry budget",
+			config:    "retry_budget = -1",
+			expectErr: "aws-iid: retry_budget cannot be negative",
+		},
+	} {
+		tt := tt
+		s.T().Run(tt.desc, func(t *testing.T) {
+			_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+				Configuration: tt.config,
+				GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+			})
+			s.RequireErrorContains(err, tt.expectErr)
+		})
+	}
+}
+
+func (s *IIDAttestorSuite) TestErrorOnBadSVIDTemplate() {
+	_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+agent_path_template = "{{ .InstanceID "
+`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.RequireErrorContains(err, "failed to parse agent svid template")
+}
+
+func (s *IIDAttestorSuite) TestConfigure() {
+	require := s.Require()
+
+	// malformed
+	resp, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `trust_domain`,
+		GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.RequireErrorContains(err, "expected start of object")
+	require.Nil(resp)
+
+	// missing global configuration
+	resp, err = s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: ``})
+	s.RequireErrorContains(err, "global configuration is required")
+	require.Nil(resp)
+
+	// missing trust domain
+	resp, err = s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: ``,
+		GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{}})
+	s.RequireErrorContains(err, "trust_domain is required")
+	require.Nil(resp)
+
+	// fails with access id but no secret
+	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+		access_key_id = "ACCESSKEYID"
+		`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
+	s.Require().EqualError(err, "aws-iid: configuration missing secret access key, but has access key id")
+	s.Require().Nil(resp)
+
+	// fails with secret but no access id
+	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+		secret_access_key = "SECRETACCESSKEY"
+		`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
+	s.Require().EqualError(err, "aws-iid: configuration missing access key id, but has secret access key")
+	s.Require().Nil(resp)
+
+	// fails with an invalid role ARN in account_role_map
+	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+		account_role_map = {
+			"111111111111" = "not-an-arn"
+		}
+		`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
+	s.Require().EqualError(err, `aws-iid: invalid role ARN "not-an-arn" for account "111111111111" in account_role_map: arn: invalid prefix`)
+	s.Require().Nil(resp)
+
+	// fails with an invalid role ARN in region_credentials
+	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+		region_credentials = {
+			"us-east-1" = [{ assume_role_arn = "not-an-arn" }]
+		}
+		`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
+	s.Require().EqualError(err, `aws-iid: invalid role ARN "not-an-arn" for region "us-east-1" in region_credentials: arn: invalid prefix`)
+	s.Require().Nil(resp)
+
+	// fails with a local_address that is not an IP address
+	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+		local_address = "eth0"
+		`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
+	s.Require().EqualError(err, `aws-iid: invalid local_address "eth0": must be an IP address`)
+	s.Require().Nil(resp)
+
+	// fails with an invalid launch_time_granularity
+	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+		launch_time_granularity = "hour"
+		`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
+	s.Require().EqualError(err, `aws-iid: invalid launch_time_granularity "hour": time: invalid duration "hour"`)
+	s.Require().Nil(resp)
+
+	// fails with a non-positive launch_time_granularity
+	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+		launch_time_granularity = "0s"
+		`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
+	s.Require().EqualError(err, "aws-iid: launch_time_granularity must be positive")
+	s.Require().Nil(resp)
+
+	// fails with max_results out of bounds
+	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+		max_results = 1
+		`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
+	s.Require().EqualError(err, "aws-iid: max_results must be between 5 and 1000")
+	s.Require().Nil(resp)
+
+	// fails with an empty prewarm region
+	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+		prewarm_regions = ["us-east-1", ""]
+		`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
+	s.Require().EqualError(err, "aws-iid: prewarm_regions cannot contain an empty region")
+	s.Require().Nil(resp)
+
+	// fails with an empty fallback region
+	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+		fallback_regions = ["us-east-1", ""]
+		`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
+	s.Require().EqualError(err, "aws-iid: fallback_regions cannot contain an empty region")
+	s.Require().Nil(resp)
+
+	// fails with an unknown selector category
+	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+		selector_categories = ["tag", "ami"]
+		`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
+	s.Require().EqualError(err, `aws-iid: unknown selector category "ami" in selector_categories`)
+	s.Require().Nil(resp)
+
+	// success with envvars
+	s.env[accessKeyIDVarName] = "ACCESSKEYID"
+	s.env[secretAccessKeyVarName] = "SECRETACCESSKEY"
+	resp, err = s.plugin.Configure(context.Background(), &plugin.ConfigureRequest{
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(resp, &plugin.ConfigureResponse{})
+	delete(s.env, accessKeyIDVarName)
+	delete(s.env, secretAccessKeyVarName)
+
+	// success, no AWS keys
+	resp, err = s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: ``,
+		GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"}})
+	require.NoError(err)
+	require.NotNil(resp)
+	s.RequireProtoEqual(resp, &plugin.ConfigureResponse{})
+}
+
+func (s *IIDAttestorSuite) TestGetPluginInfo() {
+	require := s.Require()
+	resp, err := s.p.GetPluginInfo(context.Background(), &plugin.GetPluginInfoRequest{})
+	require.NoError(err)
+	require.NotNil(resp)
+	s.RequireProtoEqual(resp, &plugin.GetPluginInfoResponse{})
+}
+
+func (s *IIDAttestorSuite) TestCheckHealth() {
+	mockCtl := gomock.NewController(s.T())
+	defer mockCtl.Finish()
+
+	client := mock_aws.NewMockClient(mockCtl)
+	s.plugin.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+		s.Require().Equal(defaultRegion, region)
+		return client, nil
+	})
+	clk := clock.NewMock(s.T())
+	s.plugin.hooks.clock = clk
+
+	// not configured
+	state := s.plugin.CheckHealth()
+	s.Require().True(state.Live)
+	s.Require().False(state.Ready)
+
+	s.configure()
+
+	// healthy while credentials validate
+	client.EXPECT().GetCallerIdentityWithContext(gomock.Any(), &sts.GetCallerIdentityInput{}).Return(&sts.GetCallerIdentityOutput{}, nil)
+	state = s.plugin.CheckHealth()
+	s.Require().True(state.Live)
+	s.Require().True(state.Ready)
+
+	// a recent success is trusted without calling AWS again
+	state = s.plugin.CheckHealth()
+	s.Require().True(state.Ready)
+
+	// transient failures do not flip the health status...
+	clk.Add(healthCheckFreshness + time.Second)
+	client.EXPECT().GetCallerIdentityWithContext(gomock.Any(), gomock.Any()).Times(unhealthyThreshold).Return(nil, errors.New("ExpiredToken: the security token included in the request is expired"))
+	for i := 0; i < unhealthyThreshold-1; i++ {
+		state = s.plugin.CheckHealth()
+		s.Require().True(state.Live)
+		s.Require().True(state.Ready)
+	}
+
+	// ...but persistent ones do, without failing liveness
+	state = s.plugin.CheckHealth()
+	s.Require().True(state.Live)
+	s.Require().False(state.Ready)
+	s.Require().Equal(credentialsHealthDetails{
+		ValidateCredentialsErr: "ExpiredToken: the security token included in the request is expired",
+	}, state.ReadyDetails)
+
+	// recovers once the credentials validate again
+	client.EXPECT().GetCallerIdentityWithContext(gomock.Any(), gomock.Any()).Return(&sts.GetCallerIdentityOutput{}, nil)
+	state = s.plugin.CheckHealth()
+	s.Require().True(state.Live)
+	s.Require().True(state.Ready)
+}
+
+func (s *IIDAttestorSuite) TestConfigureRegionFromIMDS() {
+	configure := func(config string) {
+		_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+			Configuration: config,
+			GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+		})
+		s.Require().NoError(err)
+	}
+	requireDefaultRegion := func(expected string) {
+		config, err := s.plugin.getConfig()
+		s.Require().NoError(err)
+		s.Require().Equal(expected, config.defaultRegion)
+	}
+
+	// the instance metadata is only consulted when enabled
+	s.plugin.hooks.fetchIMDSRegion = func() (string, error) {
+		s.Fail("unexpected instance metadata call")
+		return "", nil
+	}
+	configure("")
+	requireDefaultRegion(defaultRegion)
+
+	// the region is derived from the instance metadata...
+	mockCtl := gomock.NewController(s.T())
+	defer mockCtl.Finish()
+	client := mock_aws.NewMockClient(mockCtl)
+	s.plugin.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+		s.Require().Equal("eu-west-2", region)
+		return client, nil
+	})
+	s.plugin.hooks.fetchIMDSRegion = func() (string, error) {
+		return "eu-west-2", nil
+	}
+	configure("region_from_imds = true")
+	requireDefaultRegion("eu-west-2")
+
+	// ...and used for the calls not tied to an instance
+	client.EXPECT().GetCallerIdentityWithContext(gomock.Any(), &sts.GetCallerIdentityInput{}).Return(&sts.GetCallerIdentityOutput{}, nil)
+	s.Require().True(s.plugin.CheckHealth().Ready)
+
+	// falls back to the default region when the metadata is unavailable
+	s.logHook.Reset()
+	s.plugin.hooks.fetchIMDSRegion = func() (string, error) {
+		return "", errors.New("EC2MetadataRequestError: failed to get EC2 instance region")
+	}
+	configure("region_from_imds = true")
+	requireDefaultRegion(defaultRegion)
+	spiretest.AssertLastLogs(s.T(), s.logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.WarnLevel,
+			Message: "Failed to get the region from the instance metadata; using the default region",
+			Data: logrus.Fields{
+				"region":        defaultRegion,
+				logrus.ErrorKey: "EC2MetadataRequestError: failed to get EC2 instance region",
+			},
+		},
+	})
+
+	// or returns an empty region
+	s.plugin.hooks.fetchIMDSRegion = func() (string, error) {
+		return "", nil
+	}
+	configure("region_from_imds = true")
+	requireDefaultRegion(defaultRegion)
+}
+
+func (s *IIDAttestorSuite) TestDebugLogInstances() {
+	output := getDefaultDescribeInstancesOutput()
+	instance := output.Reservations[0].Instances[0]
+	instance.InstanceId = aws.String(testInstance)
+	instance.Tags = []*ec2.Tag{
+		{Key: aws.String("Hostname"), Value: aws.String("host1")},
+		{Key: aws.String("DB_PASSWORD"), Value: aws.String("hunter2")},
+	}
+
+	redactedInstance := *instance
+	redactedInstance.Tags = []*ec2.Tag{
+		{Key: aws.String("Hostname"), Value: aws.String("host1")},
+		{Key: aws.String("DB_PASSWORD"), Value: aws.String("<redacted>")},
+	}
+	instanceJSON, err := json.Marshal(&redactedInstance)
+	s.Require().NoError(err)
+
+	for _, tt := range []struct {
+		desc       string
+		config     string
+		expectLogs []spiretest.LogEntry
+	}{
+		{
+			desc:   "disabled",
+			config: "skip_block_device = true",
+		},
+		{
+			desc:   "enabled",
+			config: "skip_block_device = true\ndebug_log_instances = true",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.DebugLevel,
+					Message: "Described instance",
+					Data: logrus.Fields{
+						"instance_id": testInstance,
+						"instance":    string(instanceJSON),
+					},
+				},
+			},
+		},
+	} {
+		tt := tt
+		s.T().Run(tt.desc, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+			client := mock_aws.NewMockClient(mockCtl)
+			setAttestExpectations(client, output, nil)
+
+			p := New()
+			p.hooks.getenv = func(key string) string {
+				return s.env[key]
+			}
+			p.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+				return client, nil
+			})
+
+			log, logHook := test.NewNullLogger()
+			log.SetLevel(logrus.DebugLevel)
+			v0 := new(nodeattestor.V0)
+			plugintest.Load(t, builtin(p), v0,
+				plugintest.HostServices(agentstorev0.AgentStoreServiceServer(s.agentStore)),
+				plugintest.Log(log),
+			)
+
+			_, err := v0.NodeAttestorClient.Configure(context.Background(), &plugin.ConfigureRequest{
+				Configuration: tt.config,
+				GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+			})
+			require.NoError(t, err)
+			p.config.awsCaCertPublicKey = &s.rsaKey.PublicKey
+
+			stream, err := v0.NodeAttestorClient.Attest(context.Background())
+			require.NoError(t, err)
+			require.NoError(t, stream.Send(&nodeattestorv0.AttestRequest{
+				AttestationData: &common.AttestationData{
+					Type: caws.PluginName,
+					Data: s.iidAttestationDataToBytes(*s.buildDefaultIIDAttestationData()),
+				},
+			}))
+			_, err = stream.Recv()
+			require.NoError(t, err)
+			require.NoError(t, stream.CloseSend())
+
+			var described []*logrus.Entry
+			for _, entry := range logHook.AllEntries() {
+				if entry.Message == "Described instance" {
+					described = append(described, entry)
+				}
+			}
+			spiretest.AssertLogs(t, described, tt.expectLogs)
+		})
+	}
+}
+
+func (s *IIDAttestorSuite) TestInstanceProfileArnParsing() {
+	// not an ARN
+	_, err := instanceProfileNameFromArn("not-an-arn")
+	s.Require().EqualError(err, "aws-iid: arn: invalid prefix")
+
+	// not an instance profile ARN
+	_, err = instanceProfileNameFromArn("arn:aws:elasticbeanstalk:us-east-1:123456789012:environment/My App/MyEnvironment")
+	s.Require().EqualError(err, "aws-iid: arn is not for an instance profile")
+
+	name, err := instanceProfileNameFromArn(testInstanceProfileArn)
+	s.Require().NoError(err)
+	s.Require().Equal(testInstanceProfileName, name)
+
+	// instance profile ARN with a path
+	name, err = instanceProfileNameFromArn("arn:aws:iam::123412341234:instance-profile/service-roles/nodes.test.k8s.local")
+	s.Require().NoError(err)
+	s.Require().Equal(testInstanceProfileName, name)
+}
+
+func (s *IIDAttestorSuite) configure() {
+	_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `skip_block_device=true`,
+		GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.Require().NoError(err)
 }
 
 // get a DescribeInstancesOutput with essential structs created, but no values
@@ -658,8 +2791,126 @@ func getDefaultDescribeInstancesOutput() *ec2.DescribeInstancesOutput {
 	}
 }
 
+// get a DescribeInstancesOutput that, unexpectedly, has two instances for
+// the test instance ID
+func getMultipleInstancesDescribeInstancesOutput() *ec2.DescribeInstancesOutput {
+	output := getDefaultDescribeInstancesOutput()
+	output.Reservations[0].Instances[0].InstanceId = aws.String(testInstance)
+	output.Reservations[0].Instances[0].Tags = []*ec2.Tag{
+		{Key: aws.String("Hostname"), Value: aws.String("host1")},
+	}
+	other := getDefaultDescribeInstancesOutput().Reservations[0]
+	other.Instances[0].InstanceId = aws.String("other-instance")
+	other.Instances[0].Tags = []*ec2.Tag{
+		{Key: aws.String("Hostname"), Value: aws.String("host2")},
+	}
+	output.Reservations = append(output.Reservations, other)
+	return output
+}
+
+// get a DescribeInstancesOutput for a spot instance launched from the test
+// spot instance request
+func getSpotDescribeInstancesOutput() *ec2.DescribeInstancesOutput {
+	output := getDefaultDescribeInstancesOutput()
+	output.Reservations[0].Instances[0].InstanceLifecycle = aws.String(ec2.InstanceLifecycleTypeSpot)
+	output.Reservations[0].Instances[0].SpotInstanceRequestId = aws.String(testSpotInstanceRequest)
+	return output
+}
+
+// get a DescribeInstancesOutput for an instance with the given instance
+// metadata service options
+func getMetadataOptionsDescribeInstancesOutput(httpTokens string, hopLimit int64) *ec2.DescribeInstancesOutput {
+	output := getDefaultDescribeInstancesOutput()
+	output.Reservations[0].Instances[0].MetadataOptions = &ec2.InstanceMetadataOptionsResponse{
+		HttpTokens:              aws.String(httpTokens),
+		HttpPutResponseHopLimit: aws.Int64(hopLimit),
+	}
+	return output
+}
+
+// get a DescribeInstancesOutput for an instance running in the test capacity
+// reservation
+func getCapacityReservationDescribeInstancesOutput() *ec2.DescribeInstancesOutput {
+	output := getDefaultDescribeInstancesOutput()
+	output.Reservations[0].Instances[0].CapacityReservationId = aws.String(testCapacityReservation)
+	return output
+}
+
+// get a DescribeInstancesOutput for an instance with a public IP address
+func getPublicIPDescribeInstancesOutput() *ec2.DescribeInstancesOutput {
+	output := getDefaultDescribeInstancesOutput()
+	output.Reservations[0].Instances[0].PublicIpAddress = aws.String("1.2.3.4")
+	return output
+}
+
+// get a DescribeInstancesOutput for an instance launched at a fixed time that
+// is not in UTC
+func getLaunchTimeDescribeInstancesOutput() *ec2.DescribeInstancesOutput {
+	output := getDefaultDescribeInstancesOutput()
+	launchTime := time.Date(2021, 3, 4, 6, 6, 7, 0, time.FixedZone("UTC+1", 60*60))
+	output.Reservations[0].Instances[0].LaunchTime = aws.Time(launchTime)
+	return output
+}
+
+// get a DescribeInstancesOutput for an instance running in the test VPC
+func getVPCDescribeInstancesOutput() *ec2.DescribeInstancesOutput {
+	output := getDefaultDescribeInstancesOutput()
+	output.Reservations[0].Instances[0].VpcId = aws.String(testVPC)
+	return output
+}
+
+// get a DescribeInstancesOutput for an instance with several network
+// interfaces, two of them with the same description and one without any
+func getNetworkInterfacesDescribeInstancesOutput() *ec2.DescribeInstancesOutput {
+	output := getDefaultDescribeInstancesOutput()
+	output.Reservations[0].Instances[0].NetworkInterfaces = []*ec2.InstanceNetworkInterface{
+		{
+			Attachment:         &ec2.InstanceNetworkInterfaceAttachment{DeviceIndex: aws.Int64(0)},
+			NetworkInterfaceId: aws.String("eni-0123456789abcdef0"),
+			Description:        aws.String("service=billing"),
+		},
+		{
+			Attachment:         &ec2.InstanceNetworkInterfaceAttachment{DeviceIndex: aws.Int64(1)},
+			NetworkInterfaceId: aws.String("eni-0123456789abcdef1"),
+			Description:        aws.String("service=billing"),
+		},
+		{
+			Attachment:         &ec2.InstanceNetworkInterfaceAttachment{DeviceIndex: aws.Int64(2)},
+			NetworkInterfaceId: aws.String("eni-0123456789abcdef2"),
+		},
+	}
+	return output
+}
+
+// get a DescribeInstancesOutput for the test instance, with its instance ID
+func getInstanceIDDescribeInstancesOutput() *ec2.DescribeInstancesOutput {
+	output := getDefaultDescribeInstancesOutput()
+	output.Reservations[0].Instances[0].InstanceId = aws.String(testInstance)
+	return output
+}
+
+// get a DescribeSpotInstanceRequestsOutput for the test spot instance request
+// with the given status code
+func getSpotInstanceRequestOutput(statusCode string) *ec2.DescribeSpotInstanceRequestsOutput {
+	return &ec2.DescribeSpotInstanceRequestsOutput{
+		SpotInstanceRequests: []*ec2.SpotInstanceRequest{
+			{
+				SpotInstanceRequestId: aws.String(testSpotInstanceRequest),
+				InstanceId:            aws.String(testInstance),
+				Status: &ec2.SpotInstanceStatus{
+					Code: aws.String(statusCode),
+				},
+			},
+		},
+	}
+}
+
 func (s *IIDAttestorSuite) attest(req *nodeattestorv0.AttestRequest) (*nodeattestorv0.AttestResponse, error) {
-	stream, err := s.p.Attest(context.Background())
+	return s.attestWithContext(context.Background(), req)
+}
+
+func (s *IIDAttestorSuite) attestWithContext(ctx context.Context, req *nodeattestorv0.AttestRequest) (*nodeattestorv0.AttestResponse, error) {
+	stream, err := s.p.Attest(ctx)
 	s.Require().NoError(err)
 	defer func() {
 		s.Require().NoError(stream.CloseSend())
@@ -708,8 +2959,118 @@ func setAttestExpectations(mock *mock_aws.MockClient, dio *ec2.DescribeInstances
 	}).Return(dio, err)
 }
 
+func describeTagsInput() *ec2.DescribeTagsInput {
+	return &ec2.DescribeTagsInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("resource-id"), Values: []*string{aws.String(testInstance)}},
+			{Name: aws.String("resource-type"), Values: []*string{aws.String(ec2.ResourceTypeInstance)}},
+		},
+	}
+}
+
+// setDescribeInstanceStatusExpectations expects the state of the instance to
+// be checked, returning the given state or, if empty, no instance, as when
+// the instance is not pending or running.
+func setDescribeInstanceStatusExpectations(mock *mock_aws.MockClient, state string, err error) {
+	output := &ec2.DescribeInstanceStatusOutput{}
+	if state != "" {
+		output.InstanceStatuses = []*ec2.InstanceStatus{
+			{
+				InstanceId:    aws.String(testInstance),
+				InstanceState: &ec2.InstanceState{Name: aws.String(state)},
+			},
+		}
+	}
+	if err != nil {
+		output = nil
+	}
+	mock.EXPECT().DescribeInstanceStatusWithContext(gomock.Any(), &ec2.DescribeInstanceStatusInput{
+		InstanceIds:         []*string{aws.String(testInstance)},
+		Filters:             instanceFilters,
+		IncludeAllInstances: aws.Bool(true),
+	}).Return(output, err)
+}
+
+func setDescribeTagsExpectations(mock *mock_aws.MockClient, dto *ec2.DescribeTagsOutput, err error) {
+	mock.EXPECT().DescribeTagsWithContext(gomock.Any(), describeTagsInput()).Return(dto, err)
+}
+
+func setSpotInstanceRequestExpectations(mock *mock_aws.MockClient, dsiro *ec2.DescribeSpotInstanceRequestsOutput, err error) {
+	mock.EXPECT().DescribeSpotInstanceRequestsWithContext(gomock.Any(), &ec2.DescribeSpotInstanceRequestsInput{
+		SpotInstanceRequestIds: []*string{aws.String(testSpotInstanceRequest)},
+	}).Return(dsiro, err)
+}
+
+func setUserDataExpectations(mock *mock_aws.MockClient, userData *string, err error) {
+	var output *ec2.DescribeInstanceAttributeOutput
+	if err == nil {
+		output = &ec2.DescribeInstanceAttributeOutput{
+			InstanceId: aws.String(testInstance),
+		}
+		if userData != nil {
+			output.UserData = &ec2.AttributeValue{Value: userData}
+		}
+	}
+	mock.EXPECT().DescribeInstanceAttributeWithContext(gomock.Any(), &ec2.DescribeInstanceAttributeInput{
+		Attribute:  aws.String(ec2.InstanceAttributeNameUserData),
+		InstanceId: aws.String(testInstance),
+	}).Return(output, err)
+}
+
+func setSSMInstanceInformationExpectations(mock *mock_aws.MockClient, infos []*ssm.InstanceInformation, err error) {
+	var output *ssm.DescribeInstanceInformationOutput
+	if err == nil {
+		output = &ssm.DescribeInstanceInformationOutput{InstanceInformationList: infos}
+	}
+	mock.EXPECT().DescribeInstanceInformationWithContext(gomock.Any(), &ssm.DescribeInstanceInformationInput{
+		Filters: []*ssm.InstanceInformationStringFilter{
+			{Key: aws.String("InstanceIds"), Values: []*string{aws.String(testInstance)}},
+		},
+	}).Return(output, err)
+}
+
+func setSSMInventoryExpectations(mock *mock_aws.MockClient, typeName string, content []map[string]*string, err error) {
+	var output *ssm.GetInventoryOutput
+	if err == nil {
+		output = &ssm.GetInventoryOutput{
+			Entities: []*ssm.InventoryResultEntity{getSSMInventoryEntity(typeName, content)},
+		}
+	}
+	mock.EXPECT().GetInventoryWithContext(gomock.Any(), getSSMInventoryInput(typeName)).Return(output, err)
+}
+
+func getSSMInventoryInput(typeName string) *ssm.GetInventoryInput {
+	return &ssm.GetInventoryInput{
+		Filters: []*ssm.InventoryFilter{
+			{
+				Key:    aws.String("AWS:InstanceInformation.InstanceId"),
+				Type:   aws.String(ssm.InventoryQueryOperatorTypeEqual),
+				Values: []*string{aws.String(testInstance)},
+			},
+		},
+		ResultAttributes: []*ssm.ResultAttribute{
+			{TypeName: aws.String(typeName)},
+		},
+	}
+}
+
+func getSSMInventoryEntity(typeName string, content []map[string]*string) *ssm.InventoryResultEntity {
+	return &ssm.InventoryResultEntity{
+		Id: aws.String(testInstance),
+		Data: map[string]*ssm.InventoryResultItem{
+			typeName: {TypeName: aws.String(typeName), Content: content},
+		},
+	}
+}
+
 func setResolveSelectorsExpectations(mock *mock_aws.MockClient, gipo *iam.GetInstanceProfileOutput) {
 	mock.EXPECT().GetInstanceProfileWithContext(gomock.Any(), &iam.GetInstanceProfileInput{
 		InstanceProfileName: aws.String(testProfile),
 	}).AnyTimes().Return(gipo, nil)
 }
+
+func setResolveSelectorsError(mock *mock_aws.MockClient, err error) {
+	mock.EXPECT().GetInstanceProfileWithContext(gomock.Any(), &iam.GetInstanceProfileInput{
+		InstanceProfileName: aws.String(testProfile),
+	}).Return(nil, err)
+}
@@ -0,0 +1,149 @@
+package aws
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	caws "github.com/spiffe/spire/pkg/common/plugin/aws"
+	nodeattestorv0 "github.com/spiffe/spire/proto/spire/plugin/server/nodeattestor/v0"
+)
+
+const (
+	nonceSize = 32
+	// maxSTSResponseSize bounds the size of the STS responses read
+	maxSTSResponseSize = 64 * 1024
+)
+
+// getCallerIdentityResponse is the part of the sts:GetCallerIdentity
+// response needed to identify the role session of the instance
+type getCallerIdentityResponse struct {
+	Arn     string `xml:"GetCallerIdentityResult>Arn"`
+	Account string `xml:"GetCallerIdentityResult>Account"`
+}
+
+// verifyNonce challenges the agent with a fresh nonce, which it signs into
+// an sts:GetCallerIdentity request with the credentials of the instance
+// profile role. The request is sent to STS, and the attestation fails
+// unless it was signed by the role session EC2 created for the instance
+// of the identity document. Since the nonce is never reused, neither the
+// identity document nor the signed request can be replayed.
+func (p *IIDAttestorPlugin) verifyNonce(stream nodeattestorv0.NodeAttestor_AttestServer, doc ec2metadata.EC2InstanceIdentityDocument) error {
+	nonce, err := generateNonce()
+	if err != nil {
+		return iidError.New("unable to generate nonce: %w", err)
+	}
+
+	challenge, err := json.Marshal(caws.NonceChallenge{Nonce: nonce})
+	if err != nil {
+		return iidError.New("unable to marshal nonce challenge: %w", err)
+	}
+
+	if err := stream.Send(&nodeattestorv0.AttestResponse{
+		Challenge: challenge,
+	}); err != nil {
+		return err
+	}
+
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	response := new(caws.NonceResponse)
+	if err := json.Unmarshal(req.Response, response); err != nil {
+		return iidError.New("unable to unmarshal nonce response: %w", err)
+	}
+
+	switch response.Headers.Get(caws.NonceHeader) {
+	case "":
+		return iidError.New("nonce response is missing the nonce")
+	case nonce:
+	default:
+		return iidError.New("nonce response does not match the nonce issued")
+	}
+	if !isHeaderSigned(response.Headers, caws.NonceHeader) {
+		return iidError.New("nonce response does not sign the nonce")
+	}
+
+	identity, err := p.getCallerIdentity(stream.Context(), response.Headers, nonce)
+	if err != nil {
+		return iidError.New("unable to verify nonce response: %w", err)
+	}
+
+	sessionARN, err := arn.Parse(identity.Arn)
+	if err != nil {
+		return iidError.New("unable to parse caller identity ARN %q: %w", identity.Arn, err)
+	}
+	resource := strings.Split(sessionARN.Resource, "/")
+	if sessionARN.Service != "sts" || identity.Account != doc.AccountID || sessionARN.AccountID != doc.AccountID ||
+		len(resource) != 3 || resource[0] != "assumed-role" || resource[2] != doc.InstanceID {
+		return iidError.New("nonce was not signed by the instance profile role of instance %q: signed by %q", doc.InstanceID, identity.Arn)
+	}
+	return nil
+}
+
+func (p *IIDAttestorPlugin) getCallerIdentity(parent context.Context, headers http.Header, nonce string) (*getCallerIdentityResponse, error) {
+	ctx, cancel := context.WithTimeout(parent, _awsTimeout)
+	defer cancel()
+
+	req, err := caws.NewGetCallerIdentityRequest(ctx, p.hooks.stsEndpoint, nonce)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"Authorization", "X-Amz-Date", "X-Amz-Security-Token", "X-Amz-Content-Sha256"} {
+		if values := headers.Values(name); len(values) > 0 {
+			req.Header[name] = values
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sts:GetCallerIdentity failed with status code %d", resp.StatusCode)
+	}
+
+	identity := new(getCallerIdentityResponse)
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, maxSTSResponseSize)).Decode(identity); err != nil {
+		return nil, fmt.Errorf("unable to decode sts:GetCallerIdentity response: %w", err)
+	}
+	return identity, nil
+}
+
+// isHeaderSigned returns true if the SigV4 authorization header of the
+// request signs the given header.
+func isHeaderSigned(headers http.Header, name string) bool {
+	authorization := headers.Get("Authorization")
+	for _, field := range strings.Split(authorization, ",") {
+		field = strings.TrimSpace(field)
+		if !strings.HasPrefix(field, "SignedHeaders=") {
+			continue
+		}
+		for _, signed := range strings.Split(strings.TrimPrefix(field, "SignedHeaders="), ";") {
+			if signed == strings.ToLower(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func generateNonce() (string, error) {
+	b := make([]byte, nonceSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
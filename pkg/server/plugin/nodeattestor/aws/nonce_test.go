@@ -0,0 +1,222 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	caws "github.com/spiffe/spire/pkg/common/plugin/aws"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/proto/spire/common/plugin"
+	nodeattestorv0 "github.com/spiffe/spire/proto/spire/plugin/server/nodeattestor/v0"
+	mock_aws "github.com/spiffe/spire/test/mock/server/aws"
+)
+
+const (
+	testAuthorization = "AWS4-HMAC-SHA256 Credential=ASIAEXAMPLE/20210301/us-east-1/sts/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-spire-aws-iid-nonce, Signature=0123456789abcdef"
+	testSessionARN    = "arn:aws:sts::test-account:assumed-role/test-role/test-instance"
+)
+
+func (s *IIDAttestorSuite) TestNonce() {
+	signedResponse := func(nonce string) *caws.NonceResponse {
+		return &caws.NonceResponse{
+			Headers: http.Header{
+				"Authorization":        []string{testAuthorization},
+				"X-Amz-Date":           []string{"20210301T100000Z"},
+				"X-Amz-Security-Token": []string{"token"},
+				caws.NonceHeader:       []string{nonce},
+			},
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		// respond returns the response to the challenge of the given nonce
+		respond func(nonce string) *caws.NonceResponse
+		// replay answers the challenge with the response of a previous
+		// attestation
+		replay     bool
+		stsStatus  int
+		sessionARN string
+		expectErr  string
+	}{
+		{
+			name:    "valid nonce",
+			respond: signedResponse,
+		},
+		{
+			name: "missing nonce",
+			respond: func(nonce string) *caws.NonceResponse {
+				response := signedResponse(nonce)
+				response.Headers.Del(caws.NonceHeader)
+				return response
+			},
+			expectErr: "aws-iid: nonce response is missing the nonce",
+		},
+		{
+			name:      "replayed nonce",
+			respond:   signedResponse,
+			replay:    true,
+			expectErr: "aws-iid: nonce response does not match the nonce issued",
+		},
+		{
+			name: "nonce not signed",
+			respond: func(nonce string) *caws.NonceResponse {
+				response := signedResponse(nonce)
+				response.Headers.Set("Authorization", "AWS4-HMAC-SHA256 Credential=ASIAEXAMPLE/20210301/us-east-1/sts/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=0123456789abcdef")
+				return response
+			},
+			expectErr: "aws-iid: nonce response does not sign the nonce",
+		},
+		{
+			name:      "signature rejected by STS",
+			respond:   signedResponse,
+			stsStatus: http.StatusForbidden,
+			expectErr: "aws-iid: unable to verify nonce response: sts:GetCallerIdentity failed with status code 403",
+		},
+		{
+			name:       "signed by another instance",
+			respond:    signedResponse,
+			sessionARN: "arn:aws:sts::test-account:assumed-role/test-role/other-instance",
+			expectErr:  `aws-iid: nonce was not signed by the instance profile role of instance "test-instance": signed by "arn:aws:sts::test-account:assumed-role/test-role/other-instance"`,
+		},
+		{
+			name:       "signed by a user",
+			respond:    signedResponse,
+			sessionARN: "arn:aws:iam::test-account:user/test-instance",
+			expectErr:  `aws-iid: nonce was not signed by the instance profile role of instance "test-instance": signed by "arn:aws:iam::test-account:user/test-instance"`,
+		},
+	} {
+		tt := tt
+		s.T().Run(tt.name, func(t *testing.T) {
+			sessionARN := testSessionARN
+			if tt.sessionARN != "" {
+				sessionARN = tt.sessionARN
+			}
+			sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil || string(body) != caws.GetCallerIdentityBody ||
+					r.Header.Get("Authorization") != testAuthorization || r.Header.Get(caws.NonceHeader) == "" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				if tt.stsStatus != 0 {
+					w.WriteHeader(tt.stsStatus)
+					return
+				}
+				_, _ = fmt.Fprintf(w, `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <GetCallerIdentityResult>
+    <Arn>%s</Arn>
+    <UserId>AROAEXAMPLE:test-instance</UserId>
+    <Account>test-account</Account>
+  </GetCallerIdentityResult>
+</GetCallerIdentityResponse>`, sessionARN)
+			}))
+			defer sts.Close()
+
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+			client := mock_aws.NewMockClient(mockCtl)
+			s.plugin.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+				return client, nil
+			})
+			s.plugin.hooks.stsEndpoint = sts.URL
+			if tt.expectErr == "" || tt.replay {
+				// The instance is only described once the nonce is verified
+				setAttestExpectations(client, getDefaultDescribeInstancesOutput(), nil)
+			}
+
+			_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+				Configuration: "require_nonce = true\nskip_block_device = true",
+				GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+			})
+			s.Require().NoError(err)
+			s.plugin.config.awsCaCertPublicKey = &s.rsaKey.PublicKey
+
+			var captured *caws.NonceResponse
+			if tt.replay {
+				_, err := s.attestWithNonce(func(nonce string) *caws.NonceResponse {
+					captured = tt.respond(nonce)
+					return captured
+				})
+				s.Require().NoError(err)
+			}
+
+			resp, err := s.attestWithNonce(func(nonce string) *caws.NonceResponse {
+				if captured != nil {
+					return captured
+				}
+				return tt.respond(nonce)
+			})
+			if tt.expectErr != "" {
+				s.RequireErrorContains(err, tt.expectErr)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal("spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance", resp.AgentId)
+		})
+	}
+}
+
+func (s *IIDAttestorSuite) TestNonceNotRequired() {
+	mockCtl := gomock.NewController(s.T())
+	defer mockCtl.Finish()
+	client := mock_aws.NewMockClient(mockCtl)
+	s.plugin.clients = newClientsCache(func(config *SessionConfig, region string, cred RegionCredential) (Client, error) {
+		return client, nil
+	})
+	setAttestExpectations(client, getDefaultDescribeInstancesOutput(), nil)
+
+	_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: "skip_block_device = true",
+		GlobalConfig:  &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.Require().NoError(err)
+	s.plugin.config.awsCaCertPublicKey = &s.rsaKey.PublicKey
+
+	// The attestation completes without a challenge
+	resp, err := s.attest(&nodeattestorv0.AttestRequest{
+		AttestationData: &common.AttestationData{
+			Type: caws.PluginName,
+			Data: s.iidAttestationDataToBytes(*s.buildDefaultIIDAttestationData()),
+		},
+	})
+	s.Require().NoError(err)
+	s.Nil(resp.Challenge)
+	s.Equal("spiffe://example.org/spire/agent/aws_iid/test-account/test-region/test-instance", resp.AgentId)
+}
+
+// attestWithNonce attests with the default identity document, answering the
+// nonce challenge of the server with the given response.
+func (s *IIDAttestorSuite) attestWithNonce(respond func(nonce string) *caws.NonceResponse) (*nodeattestorv0.AttestResponse, error) {
+	stream, err := s.p.Attest(context.Background())
+	s.Require().NoError(err)
+	defer func() {
+		s.Require().NoError(stream.CloseSend())
+	}()
+
+	s.Require().NoError(stream.Send(&nodeattestorv0.AttestRequest{
+		AttestationData: &common.AttestationData{
+			Type: caws.PluginName,
+			Data: s.iidAttestationDataToBytes(*s.buildDefaultIIDAttestationData()),
+		},
+	}))
+
+	resp, err := stream.Recv()
+	s.Require().NoError(err)
+	challenge := new(caws.NonceChallenge)
+	s.Require().NoError(json.Unmarshal(resp.Challenge, challenge))
+	s.Require().NotEmpty(challenge.Nonce)
+
+	response, err := json.Marshal(respond(challenge.Nonce))
+	s.Require().NoError(err)
+	s.Require().NoError(stream.Send(&nodeattestorv0.AttestRequest{
+		Response: response,
+	}))
+	return stream.Recv()
+}
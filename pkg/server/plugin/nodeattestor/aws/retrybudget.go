@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// retryBudget bounds the retries of all of the AWS calls made to attest an
+// agent. Each call is retried by the AWS SDK on its own, so a handful of
+// failing calls can retry for longer than the attestation deadline. Every
+// retry, including those of the instances not found yet, takes a token from
+// the budget, and calls are no longer retried once it is exhausted.
+type retryBudget struct {
+	mtx       sync.Mutex
+	remaining int
+}
+
+type retryBudgetKey struct{}
+
+// withRetryBudget returns a context whose AWS calls share a budget of the
+// given number of retries.
+func withRetryBudget(ctx context.Context, retries int) context.Context {
+	return context.WithValue(ctx, retryBudgetKey{}, &retryBudget{remaining: retries})
+}
+
+// takeRetry takes a retry from the retry budget of the context. It returns
+// false if the budget is exhausted. Contexts without a budget always allow
+// retries.
+func takeRetry(ctx context.Context) bool {
+	budget, ok := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	if !ok {
+		return true
+	}
+
+	budget.mtx.Lock()
+	defer budget.mtx.Unlock()
+	if budget.remaining <= 0 {
+		return false
+	}
+	budget.remaining--
+	return true
+}
+
+// retryBudgetHandler stops the AWS SDK from retrying a failed call when the
+// retry budget of the call context is exhausted. It runs before the core
+// AfterRetry handler, which keeps the retry state set here.
+var retryBudgetHandler = request.NamedHandler{
+	Name: "spire.RetryBudgetHandler",
+	Fn: func(r *request.Request) {
+		if r.Retryable == nil {
+			r.Retryable = aws.Bool(r.ShouldRetry(r))
+		}
+		if r.WillRetry() && !takeRetry(r.Context()) {
+			r.Retryable = aws.Bool(false)
+		}
+	},
+}
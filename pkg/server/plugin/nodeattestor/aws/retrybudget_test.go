@@ -0,0 +1,90 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBudget(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		budget         int
+		expectRequests int32
+	}{
+		{
+			// 3 calls, each made once and retried 3 times
+			name:           "no budget",
+			expectRequests: 12,
+		},
+		{
+			// 3 calls, each made once, and 4 retries between them
+			name:           "budget shared by the calls",
+			budget:         4,
+			expectRequests: 7,
+		},
+		{
+			name:           "budget larger than the retries",
+			budget:         20,
+			expectRequests: 12,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			config := &SessionConfig{
+				AccessKeyID:     "ACCESS_KEY_ID",
+				SecretAccessKey: "SECRET_ACCESS_KEY",
+			}
+			require.NoError(t, config.Validate("", ""))
+			sess, err := newAWSSession(config, "us-west-2")
+			require.NoError(t, err)
+			awsConfig := &aws.Config{
+				Endpoint:   aws.String(server.URL),
+				MaxRetries: aws.Int(3),
+				SleepDelay: func(time.Duration) {},
+			}
+			ec2Client := ec2.New(sess, awsConfig)
+			iamClient := iam.New(sess, awsConfig)
+
+			ctx := context.Background()
+			if tt.budget > 0 {
+				ctx = withRetryBudget(ctx, tt.budget)
+			}
+
+			_, err = ec2Client.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{})
+			assert.Error(t, err)
+			_, err = ec2Client.DescribeSpotInstanceRequestsWithContext(ctx, &ec2.DescribeSpotInstanceRequestsInput{})
+			assert.Error(t, err)
+			_, err = iamClient.GetInstanceProfileWithContext(ctx, &iam.GetInstanceProfileInput{InstanceProfileName: aws.String("profile")})
+			assert.Error(t, err)
+
+			assert.Equal(t, tt.expectRequests, atomic.LoadInt32(&requests))
+		})
+	}
+}
+
+func TestTakeRetry(t *testing.T) {
+	// Contexts without a budget are not bounded
+	assert.True(t, takeRetry(context.Background()))
+
+	ctx := withRetryBudget(context.Background(), 2)
+	assert.True(t, takeRetry(ctx))
+	assert.True(t, takeRetry(ctx))
+	assert.False(t, takeRetry(ctx))
+}
@@ -0,0 +1,238 @@
+package aws
+
+// SelectorSchema describes a kind of selector generated by the plugin, so
+// that policy authors and tooling can tell which selectors registration
+// entries can use without reading the plugin documentation. All of the
+// selectors have the aws_iid type.
+type SelectorSchema struct {
+	// Name is the human readable name of the selector
+	Name string `json:"name"`
+
+	// Category is the category of the selector, i.e. the prefix of its
+	// value, as accepted in selector_categories
+	Category string `json:"category"`
+
+	// Format is the format of the selector value. The parts between angle
+	// brackets are placeholders, e.g. "tag:<key>:<value>".
+	Format string `json:"format"`
+
+	// Example is an example of selector value
+	Example string `json:"example"`
+
+	// Description describes what the selector value is
+	Description string `json:"description"`
+
+	// EnabledBy is the configuration option that has to be true for the
+	// selector to be generated, if any
+	EnabledBy string `json:"enabled_by,omitempty"`
+}
+
+// selectorSchemas are the schemas of the selectors generated by the plugin,
+// in the order of the documentation. The selectors named by tag_transforms
+// are configured, and have no schema.
+var selectorSchemas = []SelectorSchema{
+	{
+		Name:        "Instance Tag",
+		Category:    tagSelectorCategory,
+		Format:      "tag:<key>:<value>",
+		Example:     "tag:name:blog",
+		Description: "The key and value of an instance tag",
+	},
+	{
+		Name:        "Security Group ID",
+		Category:    "sg",
+		Format:      "sg:id:<security group id>",
+		Example:     "sg:id:sg-01234567",
+		Description: "The ID of a security group the instance belongs to",
+	},
+	{
+		Name:        "Security Group Name",
+		Category:    "sg",
+		Format:      "sg:name:<security group name>",
+		Example:     "sg:name:blog",
+		Description: "The name of a security group the instance belongs to",
+	},
+	{
+		Name:        "Hostname",
+		Category:    "hostname",
+		Format:      "hostname:<private dns name>",
+		Example:     "hostname:ip-10-0-0-1.ec2.internal",
+		Description: "The private DNS name of the instance",
+	},
+	{
+		Name:        "Public Hostname",
+		Category:    "publichostname",
+		Format:      "publichostname:<public dns name>",
+		Example:     "publichostname:ec2-1-2-3-4.compute-1.amazonaws.com",
+		Description: "The public DNS name of the instance",
+		EnabledBy:   "enable_public_hostname_selector",
+	},
+	{
+		Name:        "VPC",
+		Category:    "vpc",
+		Format:      "vpc:<vpc id>",
+		Example:     "vpc:vpc-0123456789abcdef0",
+		Description: "The ID of the VPC the instance runs in",
+	},
+	{
+		Name:        "Owner Account",
+		Category:    "owneraccount",
+		Format:      "owneraccount:<account id>",
+		Example:     "owneraccount:123456789012",
+		Description: "The ID of the account owning the reservation of the instance",
+	},
+	{
+		Name:        "ENI ID",
+		Category:    "eni",
+		Format:      "eni:id:<network interface id>",
+		Example:     "eni:id:eni-0123456789abcdef0",
+		Description: "The ID of a network interface attached to the instance",
+	},
+	{
+		Name:        "ENI Description",
+		Category:    "eni",
+		Format:      "eni:description:<network interface description>",
+		Example:     "eni:description:service=blog",
+		Description: "The description of a network interface attached to the instance",
+	},
+	{
+		Name:        "CPU Count",
+		Category:    "cpucount",
+		Format:      "cpucount:<vcpus>",
+		Example:     "cpucount:4",
+		Description: "The number of vCPUs of the instance",
+	},
+	{
+		Name:        "IAM role",
+		Category:    "iamrole",
+		Format:      "iamrole:<role arn>",
+		Example:     "iamrole:arn:aws:iam::123456789012:role/Blog",
+		Description: "An IAM role within the instance profile of the instance",
+	},
+	{
+		Name:        "Instance Profile Path",
+		Category:    "iamprofile",
+		Format:      "iamprofile:path:<instance profile path>",
+		Example:     "iamprofile:path:/service-roles/",
+		Description: "The path of the instance profile of the instance",
+	},
+	{
+		Name:        "Role Tag",
+		Category:    "roletag",
+		Format:      "roletag:<key>:<value>",
+		Example:     "roletag:team:blog",
+		Description: "The key and value of a tag of an IAM role within the instance profile",
+		EnabledBy:   "include_role_tags",
+	},
+	{
+		Name:        "Session Tag",
+		Category:    "sessiontag",
+		Format:      "sessiontag:<key>:<value>",
+		Example:     "sessiontag:team:blog",
+		Description: "The key and value of a tag of the role sessions of the instance",
+		EnabledBy:   "enable_session_tag_selectors",
+	},
+	{
+		Name:        "Spot Interruption",
+		Category:    "interruption",
+		Format:      "interruption:pending",
+		Example:     "interruption:pending",
+		Description: "The instance is a spot instance that has been issued an interruption notice",
+		EnabledBy:   "enable_spot_interruption_selector",
+	},
+	{
+		Name:        "Spot Request",
+		Category:    "spotrequest",
+		Format:      "spotrequest:<spot instance request id>",
+		Example:     "spotrequest:sir-0123abcd",
+		Description: "The ID of the spot instance request the instance was launched from",
+		EnabledBy:   "enable_spot_request_selector",
+	},
+	{
+		Name:        "IMDS HTTP Tokens",
+		Category:    "imds",
+		Format:      "imds:http_tokens:<required|optional>",
+		Example:     "imds:http_tokens:required",
+		Description: "Whether the instance metadata service requires session tokens",
+		EnabledBy:   "enable_metadata_options_selectors",
+	},
+	{
+		Name:        "IMDS Hop Limit",
+		Category:    "imds",
+		Format:      "imds:hop_limit:<hop limit>",
+		Example:     "imds:hop_limit:1",
+		Description: "The PUT response hop limit of the instance metadata service",
+		EnabledBy:   "enable_metadata_options_selectors",
+	},
+	{
+		Name:        "Capacity Reservation",
+		Category:    "capacityreservation",
+		Format:      "capacityreservation:<capacity reservation id>",
+		Example:     "capacityreservation:cr-0123456789abcdef0",
+		Description: "The ID of the capacity reservation the instance runs in",
+		EnabledBy:   "enable_capacity_reservation_selector",
+	},
+	{
+		Name:        "Launch Time",
+		Category:    "launchtime",
+		Format:      "launchtime:<RFC 3339 time>",
+		Example:     "launchtime:2021-03-04T05:00:00Z",
+		Description: "The launch time of the instance in UTC, truncated to launch_time_granularity",
+		EnabledBy:   "enable_launch_time_selector",
+	},
+	{
+		Name:        "Public IP",
+		Category:    "public",
+		Format:      "public:<true|false>",
+		Example:     "public:true",
+		Description: "Whether the instance has a public IPv4 address",
+		EnabledBy:   "enable_public_ip_selector",
+	},
+	{
+		Name:        "SSM Platform",
+		Category:    "ssm",
+		Format:      "ssm:platform:<platform type>",
+		Example:     "ssm:platform:Linux",
+		Description: "The platform type of the instance reported by the SSM agent",
+		EnabledBy:   "enable_ssm_selectors",
+	},
+	{
+		Name:        "SSM Platform Name",
+		Category:    "ssm",
+		Format:      "ssm:platformname:<platform name>",
+		Example:     "ssm:platformname:Amazon Linux",
+		Description: "The name of the operating system of the instance reported by the SSM agent",
+		EnabledBy:   "enable_ssm_selectors",
+	},
+	{
+		Name:        "SSM Patch Group",
+		Category:    "ssm",
+		Format:      "ssm:patchgroup:<patch group>",
+		Example:     "ssm:patchgroup:production",
+		Description: "The patch group of the instance in its SSM inventory",
+		EnabledBy:   "enable_ssm_selectors",
+	},
+	{
+		Name:        "SSM Application",
+		Category:    "ssm",
+		Format:      "ssm:application:<application name>",
+		Example:     "ssm:application:nginx",
+		Description: "The name of an application installed on the instance in its SSM inventory",
+		EnabledBy:   "enable_ssm_selectors",
+	},
+	{
+		Name:        "User Data Hash",
+		Category:    "userdatahash",
+		Format:      "userdatahash:<hex sha-256 hash>",
+		Example:     "userdatahash:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		Description: "The hex encoded SHA-256 hash of the user data the instance was launched with",
+		EnabledBy:   "enable_user_data_hash_selector",
+	},
+}
+
+// SelectorSchemas returns the schemas of the selectors the plugin generates.
+func SelectorSchemas() []SelectorSchema {
+	schemas := make([]SelectorSchema, len(selectorSchemas))
+	copy(schemas, selectorSchemas)
+	return schemas
+}
@@ -0,0 +1,115 @@
+package aws
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rePlaceholder = regexp.MustCompile(`<[^>]+>`)
+
+func TestSelectorSchemasCoverSelectorCategories(t *testing.T) {
+	schemaCategories := make(map[string]bool)
+	for _, schema := range SelectorSchemas() {
+		assert.True(t, isSelectorCategory(schema.Category), "schema %q has unknown category %q", schema.Name, schema.Category)
+		assert.True(t, strings.HasPrefix(schema.Format, schema.Category+":"), "format of schema %q does not start with its category", schema.Name)
+		assert.NotEmpty(t, schema.Description, "schema %q has no description", schema.Name)
+		schemaCategories[schema.Category] = true
+	}
+
+	for _, category := range selectorCategories {
+		assert.True(t, schemaCategories[category], "category %q has no schema", category)
+	}
+}
+
+func TestSelectorSchemaExamplesMatchFormats(t *testing.T) {
+	for _, schema := range SelectorSchemas() {
+		assert.Regexp(t, formatRegexp(t, schema.Format), schema.Example, "example of schema %q does not match its format", schema.Name)
+	}
+}
+
+func TestResolvedSelectorsMatchSchemas(t *testing.T) {
+	instance := &ec2.Instance{
+		Tags: []*ec2.Tag{
+			{Key: aws.String("Name"), Value: aws.String("blog")},
+		},
+		SecurityGroups: []*ec2.GroupIdentifier{
+			{GroupId: aws.String("sg-01234567"), GroupName: aws.String("blog")},
+		},
+		PrivateDnsName: aws.String("ip-10-0-0-1.ec2.internal"),
+		PublicDnsName:  aws.String("ec2-1-2-3-4.compute-1.amazonaws.com"),
+		NetworkInterfaces: []*ec2.InstanceNetworkInterface{
+			{NetworkInterfaceId: aws.String("eni-0123456789abcdef0"), Description: aws.String("service=blog")},
+		},
+		VpcId: aws.String("vpc-0123456789abcdef0"),
+		MetadataOptions: &ec2.InstanceMetadataOptionsResponse{
+			HttpTokens:              aws.String("required"),
+			HttpPutResponseHopLimit: aws.Int64(1),
+		},
+		CapacityReservationId: aws.String("cr-0123456789abcdef0"),
+		SpotInstanceRequestId: aws.String("sir-0123abcd"),
+		PublicIpAddress:       aws.String("1.2.3.4"),
+		LaunchTime:            aws.Time(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)),
+		CpuOptions:            &ec2.CpuOptions{CoreCount: aws.Int64(2), ThreadsPerCore: aws.Int64(2)},
+	}
+	reservation := &ec2.Reservation{OwnerId: aws.String("123456789012")}
+
+	var values []string
+	values = append(values, resolveTags(instance.Tags, false)...)
+	values = append(values, resolveSecurityGroups(instance.SecurityGroups)...)
+	values = append(values, resolveHostnames(instance, true)...)
+	values = append(values, resolveNetworkInterfaces(instance.NetworkInterfaces)...)
+	values = append(values, resolveNetwork(instance)...)
+	values = append(values, resolveOwnerAccount(reservation)...)
+	values = append(values, resolveCPUCount(instance).Value)
+	values = append(values, resolveMetadataOptions(instance)...)
+	values = append(values, resolveCapacityReservation(instance)...)
+	values = append(values, resolveSpotRequest(instance)...)
+	values = append(values, resolvePublicIP(instance)...)
+	values = append(values, resolveLaunchTime(instance, time.Hour)...)
+	require.Len(t, values, 16)
+
+	for _, value := range values {
+		assert.True(t, matchesSelectorSchema(t, value), "selector %q does not match a schema", value)
+	}
+}
+
+func matchesSelectorSchema(t *testing.T, value string) bool {
+	for _, schema := range SelectorSchemas() {
+		if formatRegexp(t, schema.Format).MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatRegexp returns a regular expression matching the values of a format,
+// where the placeholders match any non-empty text, or one of the
+// alternatives they list.
+func formatRegexp(t *testing.T, format string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("^")
+	last := 0
+	for _, loc := range rePlaceholder.FindAllStringIndex(format, -1) {
+		expr.WriteString(regexp.QuoteMeta(format[last:loc[0]]))
+		placeholder := format[loc[0]+1 : loc[1]-1]
+		if strings.Contains(placeholder, "|") {
+			expr.WriteString("(" + placeholder + ")")
+		} else {
+			expr.WriteString(".+")
+		}
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(format[last:]))
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	require.NoError(t, err)
+	return re
+}
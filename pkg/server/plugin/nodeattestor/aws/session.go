@@ -1,15 +1,63 @@
 package aws
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	caws "github.com/spiffe/spire/pkg/common/plugin/aws"
+	"github.com/spiffe/spire/pkg/common/version"
 )
 
+const vpcEndpointTypeInterface = "interface"
+
+// reDNSLabel matches a DNS label
+var reDNSLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
 // SessionConfig is a common config for AWS session config.
 type SessionConfig struct {
 	AccessKeyID     string `hcl:"access_key_id"`
 	SecretAccessKey string `hcl:"secret_access_key"`
+	LocalAddress    string `hcl:"local_address"`
+	// VPCEndpointDNSSuffix, if set, is the DNS suffix of the VPC endpoints
+	// the EC2 and IAM clients connect to instead of the public endpoints
+	VPCEndpointDNSSuffix string `hcl:"vpc_endpoint_dns_suffix"`
+	// VPCEndpointType is the type of the VPC endpoints. Only interface
+	// endpoints are supported.
+	VPCEndpointType string `hcl:"vpc_endpoint_type"`
+	// HTTPConnectTimeout, if set, bounds the time to establish the TCP
+	// connections to the AWS APIs
+	HTTPConnectTimeout string `hcl:"http_connect_timeout"`
+	// HTTPReadTimeout, if set, bounds the time to wait for the response
+	// headers once a request has been written
+	HTTPReadTimeout string `hcl:"http_read_timeout"`
+	// HTTPTimeout, if set, bounds the time of each HTTP request to the AWS
+	// APIs, including connecting and reading the response body
+	HTTPTimeout string `hcl:"http_timeout"`
+	// HTTPProxyURL, if set, is the URL of the HTTP proxy the AWS API calls
+	// go through. Proxy credentials can be passed in the URL user info.
+	HTTPProxyURL string `hcl:"http_proxy_url"`
+	// UserAgent, if set, replaces the product identifying SPIRE in the
+	// User-Agent of the AWS API calls, which is by default the SPIRE
+	// version and the plugin name. The AWS SDK product is always kept.
+	UserAgent string `hcl:"user_agent"`
+
+	httpConnectTimeout time.Duration
+	httpReadTimeout    time.Duration
+	httpTimeout        time.Duration
+	httpProxyURL       *url.URL
 }
 
 func (cfg *SessionConfig) Validate(defaultAccessKeyID, defaultSecretAccessKey string) error {
@@ -27,17 +75,182 @@ func (cfg *SessionConfig) Validate(defaultAccessKeyID, defaultSecretAccessKey st
 	case cfg.AccessKeyID == "" && cfg.SecretAccessKey != "":
 		return iidError.New("configuration missing access key id, but has secret access key")
 	}
+
+	if cfg.LocalAddress != "" && net.ParseIP(cfg.LocalAddress) == nil {
+		return iidError.New("invalid local_address %q: must be an IP address", cfg.LocalAddress)
+	}
+
+	var err error
+	if cfg.httpConnectTimeout, err = parseHTTPTimeout("http_connect_timeout", cfg.HTTPConnectTimeout); err != nil {
+		return err
+	}
+	if cfg.httpReadTimeout, err = parseHTTPTimeout("http_read_timeout", cfg.HTTPReadTimeout); err != nil {
+		return err
+	}
+	if cfg.httpTimeout, err = parseHTTPTimeout("http_timeout", cfg.HTTPTimeout); err != nil {
+		return err
+	}
+	if cfg.httpProxyURL, err = parseHTTPProxyURL(cfg.HTTPProxyURL); err != nil {
+		return err
+	}
+	if strings.ContainsAny(cfg.UserAgent, "\r\n") {
+		return iidError.New("invalid user_agent %q: must not contain line breaks", cfg.UserAgent)
+	}
+
+	switch {
+	case cfg.VPCEndpointDNSSuffix == "" && cfg.VPCEndpointType != "":
+		return iidError.New("vpc_endpoint_type requires vpc_endpoint_dns_suffix")
+	case cfg.VPCEndpointDNSSuffix == "":
+	case cfg.VPCEndpointType != "" && cfg.VPCEndpointType != vpcEndpointTypeInterface:
+		return iidError.New("unsupported vpc_endpoint_type %q: only %q endpoints are supported", cfg.VPCEndpointType, vpcEndpointTypeInterface)
+	default:
+		if err := validateDNSSuffix(cfg.VPCEndpointDNSSuffix); err != nil {
+			return iidError.New("invalid vpc_endpoint_dns_suffix %q: %v", cfg.VPCEndpointDNSSuffix, err)
+		}
+	}
+	return nil
+}
+
+// parseHTTPTimeout parses the value of the named timeout, which must be a
+// positive duration if set. Zero is returned if it is not set.
+func parseHTTPTimeout(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, iidError.New("invalid %s %q: %v", name, value, err)
+	}
+	if timeout <= 0 {
+		return 0, iidError.New("invalid %s %q: must be a positive duration", name, value)
+	}
+	return timeout, nil
+}
+
+// parseHTTPProxyURL parses the proxy URL, which must be an http or https URL
+// with a host if set. Nil is returned if it is not set.
+func parseHTTPProxyURL(value string) (*url.URL, error) {
+	if value == "" {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		// the URL may carry the proxy credentials so it is left out
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, iidError.New("invalid http_proxy_url: %v", err)
+	}
+	switch {
+	case proxyURL.Scheme != "http" && proxyURL.Scheme != "https":
+		return nil, iidError.New("invalid http_proxy_url %q: scheme must be http or https", proxyURL.Redacted())
+	case proxyURL.Hostname() == "":
+		return nil, iidError.New("invalid http_proxy_url %q: missing host", proxyURL.Redacted())
+	}
+	return proxyURL, nil
+}
+
+// validateDNSSuffix validates that the suffix is a DNS name made of at least
+// two labels, e.g. "vpce.example.internal"
+func validateDNSSuffix(suffix string) error {
+	if len(suffix) > 253 {
+		return errors.New("must not be longer than 253 characters")
+	}
+	labels := strings.Split(suffix, ".")
+	if len(labels) < 2 {
+		return errors.New("must have at least two labels")
+	}
+	for _, label := range labels {
+		if !reDNSLabel.MatchString(label) {
+			return fmt.Errorf("label %q must be 1 to 63 lowercase letters, digits or hyphens, and cannot start or end with a hyphen", label)
+		}
+	}
 	return nil
 }
 
 // newAWSSession create an AWS Session from the config and given region
-func newAWSSession(accessKeyID, secretAccessKey, region string) (*session.Session, error) {
-	var awsConf *aws.Config
-	if secretAccessKey != "" && accessKeyID != "" {
-		creds := credentials.NewStaticCredentials(accessKeyID, secretAccessKey, "")
-		awsConf = &aws.Config{Credentials: creds, Region: &region}
-	} else {
-		awsConf = &aws.Config{Region: &region}
-	}
-	return session.NewSession(awsConf)
+func newAWSSession(config *SessionConfig, region string) (*session.Session, error) {
+	awsConf := &aws.Config{Region: &region}
+	if config.SecretAccessKey != "" && config.AccessKeyID != "" {
+		awsConf.Credentials = credentials.NewStaticCredentials(config.AccessKeyID, config.SecretAccessKey, "")
+	}
+	if config.LocalAddress != "" || config.hasHTTPTimeouts() || config.httpProxyURL != nil {
+		awsConf.HTTPClient = newHTTPClient(config)
+	}
+	if config.VPCEndpointDNSSuffix != "" {
+		awsConf.EndpointResolver = newVPCEndpointResolver(config.VPCEndpointDNSSuffix)
+	}
+	sess, err := session.NewSession(awsConf)
+	if err != nil {
+		return nil, err
+	}
+	sess.Handlers.Build.PushBack(request.MakeAddToUserAgentFreeFormHandler(userAgent(config)))
+	sess.Handlers.AfterRetry.PushFrontNamed(retryBudgetHandler)
+	return sess, nil
+}
+
+// userAgent returns the product appended to the User-Agent of the AWS API
+// calls, so they can be told apart in the AWS request logs (e.g. CloudTrail)
+func userAgent(config *SessionConfig) string {
+	if config.UserAgent != "" {
+		return config.UserAgent
+	}
+	return fmt.Sprintf("SPIRE/%s (%s)", version.Version(), caws.PluginName)
+}
+
+// newVPCEndpointResolver returns an endpoint resolver that directs the EC2
+// and IAM calls to the VPC endpoints at
+// "<service>.<region>.<dnsSuffix>", so the traffic does not leave the VPC.
+// Other services use the public endpoints.
+func newVPCEndpointResolver(dnsSuffix string) endpoints.Resolver {
+	return endpoints.ResolverFunc(func(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		// Resolve the public endpoint first to keep its signing details
+		resolved, err := endpoints.DefaultResolver().EndpointFor(service, region, opts...)
+		if err != nil {
+			return resolved, err
+		}
+
+		switch service {
+		case ec2.EndpointsID, iam.EndpointsID:
+			resolved.URL = fmt.Sprintf("https://%s.%s.%s", service, region, dnsSuffix)
+		}
+		return resolved, nil
+	})
+}
+
+func (cfg *SessionConfig) hasHTTPTimeouts() bool {
+	return cfg.httpConnectTimeout > 0 || cfg.httpReadTimeout > 0 || cfg.httpTimeout > 0
+}
+
+// newHTTPClient returns an HTTP client whose connections originate from the
+// configured local address, if any, that applies the configured timeouts and
+// that goes through the configured proxy instead of the one in the
+// environment, if any. Other than that, it behaves like the default client.
+func newHTTPClient(config *SessionConfig) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if config.LocalAddress != "" {
+		dialer.LocalAddr = &net.TCPAddr{IP: net.ParseIP(config.LocalAddress)}
+	}
+	if config.httpConnectTimeout > 0 {
+		dialer.Timeout = config.httpConnectTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	if config.httpReadTimeout > 0 {
+		transport.ResponseHeaderTimeout = config.httpReadTimeout
+	}
+	if config.httpProxyURL != nil {
+		// the credentials in the URL user info are sent to the proxy in
+		// the Proxy-Authorization header
+		transport.Proxy = http.ProxyURL(config.httpProxyURL)
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   config.httpTimeout,
+	}
 }
@@ -2,7 +2,11 @@ package aws
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"net/url"
+	"regexp"
+	"strings"
 	"text/template"
 
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
@@ -10,7 +14,13 @@ import (
 	"github.com/spiffe/spire/pkg/common/plugin/aws"
 )
 
-var defaultAgentPathTemplate = template.Must(template.New("agent-svid").Parse("{{ .PluginName}}/{{ .AccountID }}/{{ .Region }}/{{ .InstanceID }}"))
+var (
+	defaultAgentPathTemplate = template.Must(template.New("agent-svid").Parse("{{ .PluginName}}/{{ .AccountID }}/{{ .Region }}/{{ .InstanceID }}"))
+
+	// reAgentPathSegment matches the characters allowed in the segments of a
+	// SPIFFE ID path
+	reAgentPathSegment = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+)
 
 type agentPathTemplateData struct {
 	InstanceID  string
@@ -36,5 +46,32 @@ func makeSpiffeID(trustDomain string, agentPathTemplate *template.Template, doc
 		return nil, err
 	}
 
+	if err := validateAgentPath(agentPath.String()); err != nil {
+		return nil, err
+	}
+
 	return idutil.AgentURI(trustDomain, agentPath.String()), nil
 }
+
+// validateAgentPath validates that the templated agent path results in a
+// legal SPIFFE ID path, i.e. that it is made of non-empty segments that only
+// contain letters, digits, dots, dashes and underscores and are not "." or
+// "..". Since instance tags are set by the instance owners, this keeps them
+// from producing IDs that other components would fail to parse.
+func validateAgentPath(agentPath string) error {
+	trimmed := strings.TrimPrefix(agentPath, "/")
+	if trimmed == "" {
+		return errors.New("agent path is empty")
+	}
+	for _, segment := range strings.Split(trimmed, "/") {
+		switch {
+		case segment == "":
+			return fmt.Errorf("agent path %q contains an empty segment", agentPath)
+		case segment == "." || segment == "..":
+			return fmt.Errorf("agent path %q contains a relative segment", agentPath)
+		case !reAgentPathSegment.MatchString(segment):
+			return fmt.Errorf("agent path %q contains invalid characters in segment %q", agentPath, segment)
+		}
+	}
+	return nil
+}
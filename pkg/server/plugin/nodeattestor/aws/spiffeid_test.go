@@ -18,6 +18,7 @@ func TestMakeSpiffeID(t *testing.T) {
 		doc               ec2metadata.EC2InstanceIdentityDocument
 		tags              instanceTags
 		want              string
+		wantErr           string
 	}{
 		{
 			name:              "default",
@@ -40,11 +41,64 @@ func TestMakeSpiffeID(t *testing.T) {
 			},
 			want: "spiffe://example.org/spire/agent/c/d",
 		},
+		{
+			name:              "tag with slash adds segments",
+			trustDomain:       "example.org",
+			agentPathTemplate: templateWithTags,
+			tags: instanceTags{
+				"a": "c/e",
+				"b": "d",
+			},
+			want: "spiffe://example.org/spire/agent/c/e/d",
+		},
+		{
+			name:              "empty tag",
+			trustDomain:       "example.org",
+			agentPathTemplate: templateWithTags,
+			tags: instanceTags{
+				"a": "c",
+				"b": "",
+			},
+			wantErr: `agent path "c/" contains an empty segment`,
+		},
+		{
+			name:              "relative segment",
+			trustDomain:       "example.org",
+			agentPathTemplate: templateWithTags,
+			tags: instanceTags{
+				"a": "..",
+				"b": "d",
+			},
+			wantErr: `agent path "../d" contains a relative segment`,
+		},
+		{
+			name:              "invalid characters",
+			trustDomain:       "example.org",
+			agentPathTemplate: templateWithTags,
+			tags: instanceTags{
+				"a": "c",
+				"b": "d?e=f",
+			},
+			wantErr: `agent path "c/d?e=f" contains invalid characters in segment "d?e=f"`,
+		},
+		{
+			name:              "empty path",
+			trustDomain:       "example.org",
+			agentPathTemplate: template.Must(template.New("agent-svid").Parse("{{ .Tags.a }}")),
+			tags: instanceTags{
+				"a": "",
+			},
+			wantErr: "agent path is empty",
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			got, err := makeSpiffeID(tt.trustDomain, tt.agentPathTemplate, tt.doc, tt.tags)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
 			require.NoError(t, err)
 			require.Equal(t, got.String(), tt.want)
 		})
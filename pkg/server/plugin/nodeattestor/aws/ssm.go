@@ -0,0 +1,130 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+const (
+	// ssm*Type are the SSM inventory types the ssm selectors are resolved
+	// from
+	ssmApplicationType  = "AWS:Application"
+	ssmPatchSummaryType = "AWS:PatchSummary"
+)
+
+// resolveSSM returns the ssm selectors of an instance managed by SSM, with
+// the platform of the instance from its SSM instance information, and the
+// patch group and installed applications from its SSM inventory. Instances
+// not managed by SSM have no ssm selectors.
+func (p *IIDAttestorPlugin) resolveSSM(parent context.Context, c *IIDAttestorConfig, client SSMClient, instance *ec2.Instance) ([]string, error) {
+	instanceID := aws.StringValue(instance.InstanceId)
+	values, err := resolveSSMSelectors(parent, client, instanceID)
+	switch {
+	case err == nil:
+		return values, nil
+	case !c.StrictPermissions:
+		// The SSM selectors are best-effort
+		p.log.Warn("Unable to get the SSM inventory of the instance; skipping ssm selectors", "instance_id", instanceID, "error", err)
+		return nil, nil
+	default:
+		return nil, iidError.Wrap(err)
+	}
+}
+
+func resolveSSMSelectors(parent context.Context, client SSMClient, instanceID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(parent, _awsTimeout)
+	defer cancel()
+
+	output, err := client.DescribeInstanceInformationWithContext(ctx, &ssm.DescribeInstanceInformationInput{
+		Filters: []*ssm.InstanceInformationStringFilter{
+			{
+				Key:    aws.String("InstanceIds"),
+				Values: []*string{aws.String(instanceID)},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	var managed bool
+	for _, info := range output.InstanceInformationList {
+		if info == nil || aws.StringValue(info.InstanceId) != instanceID {
+			continue
+		}
+		managed = true
+		if platform := aws.StringValue(info.PlatformType); platform != "" {
+			values = append(values, fmt.Sprintf("ssm:platform:%s", platform))
+		}
+		if name := aws.StringValue(info.PlatformName); name != "" {
+			values = append(values, fmt.Sprintf("ssm:platformname:%s", name))
+		}
+	}
+	if !managed {
+		return nil, nil
+	}
+
+	patchSummaries, err := getSSMInventory(ctx, client, instanceID, ssmPatchSummaryType)
+	if err != nil {
+		return nil, err
+	}
+	for _, patchSummary := range patchSummaries {
+		if patchGroup := aws.StringValue(patchSummary["PatchGroup"]); patchGroup != "" {
+			values = append(values, fmt.Sprintf("ssm:patchgroup:%s", patchGroup))
+		}
+	}
+
+	applications, err := getSSMInventory(ctx, client, instanceID, ssmApplicationType)
+	if err != nil {
+		return nil, err
+	}
+	for _, application := range applications {
+		if name := aws.StringValue(application["Name"]); name != "" {
+			values = append(values, fmt.Sprintf("ssm:application:%s", name))
+		}
+	}
+
+	return values, nil
+}
+
+// getSSMInventory returns the items of the given type in the SSM inventory
+// of the instance, going through all the pages of GetInventory.
+func getSSMInventory(ctx context.Context, client SSMClient, instanceID, typeName string) ([]map[string]*string, error) {
+	input := &ssm.GetInventoryInput{
+		Filters: []*ssm.InventoryFilter{
+			{
+				Key:    aws.String("AWS:InstanceInformation.InstanceId"),
+				Type:   aws.String(ssm.InventoryQueryOperatorTypeEqual),
+				Values: []*string{aws.String(instanceID)},
+			},
+		},
+		ResultAttributes: []*ssm.ResultAttribute{
+			{TypeName: aws.String(typeName)},
+		},
+	}
+
+	var items []map[string]*string
+	for {
+		output, err := client.GetInventoryWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, entity := range output.Entities {
+			if entity == nil || aws.StringValue(entity.Id) != instanceID {
+				continue
+			}
+			if data := entity.Data[typeName]; data != nil {
+				items = append(items, data.Content...)
+			}
+		}
+		if aws.StringValue(output.NextToken) == "" {
+			return items, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
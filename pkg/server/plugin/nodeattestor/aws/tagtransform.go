@@ -0,0 +1,137 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// TagTransform describes how the selectors of an instance tag are derived
+// from its value, instead of the tag selector with the raw value.
+type TagTransform struct {
+	// Selector is the name the selectors are generated with, i.e. the
+	// selectors are "<selector>:<value>"
+	Selector string `hcl:"selector"`
+	// Values, if set, is a lookup table from the tag values to the selector
+	// values
+	Values map[string]string `hcl:"values"`
+	// Default, if set, is the selector value of the tag values missing from
+	// Values. Otherwise those tag values generate no selector.
+	Default string `hcl:"default"`
+	// Template, if set, renders the selector value from the tag Key and
+	// Value, the latter after the lookup in Values
+	Template string `hcl:"template"`
+
+	template *template.Template
+}
+
+type tagTransformData struct {
+	Key   string
+	Value string
+}
+
+// parseTagTransforms validates the tag transforms and returns them keyed by
+// the tag key they apply to, lowercased if lowercaseKeys is set.
+func parseTagTransforms(transforms map[string]*TagTransform, lowercaseKeys bool) (map[string]*TagTransform, error) {
+	parsed := make(map[string]*TagTransform, len(transforms))
+	for key, transform := range transforms {
+		switch {
+		case transform == nil || transform.Selector == "":
+			return nil, iidError.New("selector is required for tag %q in tag_transforms", key)
+		case strings.Contains(transform.Selector, ":"):
+			return nil, iidError.New("selector %q for tag %q in tag_transforms cannot contain a colon", transform.Selector, key)
+		case isSelectorCategory(transform.Selector):
+			return nil, iidError.New("selector %q for tag %q in tag_transforms is a selector category of the plugin", transform.Selector, key)
+		}
+
+		if transform.Template != "" {
+			tmpl, err := template.New(key).Option("missingkey=error").Parse(transform.Template)
+			if err != nil {
+				return nil, iidError.New("failed to parse template for tag %q in tag_transforms: %w", key, err)
+			}
+			transform.template = tmpl
+		}
+
+		if lowercaseKeys {
+			key = strings.ToLower(key)
+		}
+		if _, ok := parsed[key]; ok {
+			return nil, iidError.New("tag %q is transformed more than once in tag_transforms", key)
+		}
+		parsed[key] = transform
+	}
+	return parsed, nil
+}
+
+// resolveTransformedTags returns the selector values of the given tags. The
+// tags with a transform generate the selectors of the transform, and the
+// others the tag selectors, unless suppressUnmapped is set.
+func resolveTransformedTags(tags []*ec2.Tag, lowercaseKeys bool, transforms map[string]*TagTransform, suppressUnmapped bool) ([]string, error) {
+	if len(transforms) == 0 {
+		if suppressUnmapped {
+			return nil, nil
+		}
+		return resolveTags(tags, lowercaseKeys), nil
+	}
+
+	var unmapped []*ec2.Tag
+	var values []string
+	for _, tag := range tags {
+		if tag == nil {
+			continue
+		}
+		key := aws.StringValue(tag.Key)
+		if lowercaseKeys {
+			key = strings.ToLower(key)
+		}
+		transform, ok := transforms[key]
+		if !ok {
+			unmapped = append(unmapped, tag)
+			continue
+		}
+
+		value, ok, err := transform.apply(aws.StringValue(tag.Key), aws.StringValue(tag.Value))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			values = append(values, fmt.Sprintf("%s:%s", transform.Selector, value))
+		}
+	}
+
+	if !suppressUnmapped {
+		values = append(values, resolveTags(unmapped, lowercaseKeys)...)
+	}
+	return values, nil
+}
+
+// apply returns the selector value of the given tag, if any.
+func (t *TagTransform) apply(key, value string) (string, bool, error) {
+	if t.Values != nil {
+		mapped, ok := t.Values[value]
+		switch {
+		case ok:
+			value = mapped
+		case t.Default != "":
+			value = t.Default
+		default:
+			return "", false, nil
+		}
+	}
+
+	if t.template != nil {
+		var b strings.Builder
+		if err := t.template.Execute(&b, tagTransformData{Key: key, Value: value}); err != nil {
+			return "", false, iidError.New("failed to render the selector of tag %q: %w", key, err)
+		}
+		value = b.String()
+	}
+
+	if value == "" {
+		return "", false, nil
+	}
+	return value, true, nil
+}
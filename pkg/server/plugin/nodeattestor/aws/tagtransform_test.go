@@ -0,0 +1,218 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/spiffe/spire/proto/spire/common/plugin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTransformedTags(t *testing.T) {
+	tags := []*ec2.Tag{
+		{Key: aws.String("CostCenter"), Value: aws.String("cc-1234")},
+		{Key: aws.String("Environment"), Value: aws.String("prod")},
+		{Key: aws.String("Hostname"), Value: aws.String("host1")},
+	}
+
+	for _, tt := range []struct {
+		desc             string
+		transforms       map[string]*TagTransform
+		lowercaseKeys    bool
+		suppressUnmapped bool
+		tags             []*ec2.Tag
+		expectValues     []string
+		expectErr        string
+	}{
+		{
+			desc:         "no transforms",
+			tags:         tags,
+			expectValues: []string{"tag:CostCenter:cc-1234", "tag:Environment:prod", "tag:Hostname:host1"},
+		},
+		{
+			desc:             "no transforms, unmapped tags suppressed",
+			suppressUnmapped: true,
+			tags:             tags,
+		},
+		{
+			desc: "remapping",
+			transforms: map[string]*TagTransform{
+				"Environment": {Selector: "env"},
+			},
+			tags:         tags,
+			expectValues: []string{"env:prod", "tag:CostCenter:cc-1234", "tag:Hostname:host1"},
+		},
+		{
+			desc: "table lookup",
+			transforms: map[string]*TagTransform{
+				"CostCenter": {Selector: "team", Values: map[string]string{"cc-1234": "payments"}},
+			},
+			tags:         tags,
+			expectValues: []string{"team:payments", "tag:Environment:prod", "tag:Hostname:host1"},
+		},
+		{
+			desc: "table lookup miss",
+			transforms: map[string]*TagTransform{
+				"CostCenter": {Selector: "team", Values: map[string]string{"cc-5678": "billing"}},
+			},
+			tags:         tags,
+			expectValues: []string{"tag:Environment:prod", "tag:Hostname:host1"},
+		},
+		{
+			desc: "table lookup miss with default",
+			transforms: map[string]*TagTransform{
+				"CostCenter": {Selector: "team", Values: map[string]string{"cc-5678": "billing"}, Default: "unknown"},
+			},
+			tags:         tags,
+			expectValues: []string{"team:unknown", "tag:Environment:prod", "tag:Hostname:host1"},
+		},
+		{
+			desc: "template",
+			transforms: map[string]*TagTransform{
+				"CostCenter": {Selector: "team", Values: map[string]string{"cc-1234": "payments"}, Template: "{{ .Key }}-{{ .Value }}"},
+			},
+			tags:         tags,
+			expectValues: []string{"team:CostCenter-payments", "tag:Environment:prod", "tag:Hostname:host1"},
+		},
+		{
+			desc: "template rendering an empty value",
+			transforms: map[string]*TagTransform{
+				"CostCenter": {Selector: "team", Template: "{{ if eq .Value \"none\" }}{{ .Value }}{{ end }}"},
+			},
+			tags:         tags,
+			expectValues: []string{"tag:Environment:prod", "tag:Hostname:host1"},
+		},
+		{
+			desc: "unmapped tags suppressed",
+			transforms: map[string]*TagTransform{
+				"Environment": {Selector: "env"},
+			},
+			suppressUnmapped: true,
+			tags:             tags,
+			expectValues:     []string{"env:prod"},
+		},
+		{
+			desc: "lowercased keys",
+			transforms: map[string]*TagTransform{
+				"ENVIRONMENT": {Selector: "env"},
+			},
+			lowercaseKeys: true,
+			tags:          tags,
+			expectValues:  []string{"env:prod", "tag:costcenter:cc-1234", "tag:hostname:host1"},
+		},
+		{
+			desc: "keys are case sensitive",
+			transforms: map[string]*TagTransform{
+				"environment": {Selector: "env"},
+			},
+			tags:         tags,
+			expectValues: []string{"tag:CostCenter:cc-1234", "tag:Environment:prod", "tag:Hostname:host1"},
+		},
+		{
+			desc: "template failure",
+			transforms: map[string]*TagTransform{
+				"Environment": {Selector: "env", Template: "{{ .Value.Missing }}"},
+			},
+			tags:      tags,
+			expectErr: `aws-iid: failed to render the selector of tag "Environment"`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			transforms, err := parseTagTransforms(tt.transforms, tt.lowercaseKeys)
+			require.NoError(t, err)
+
+			values, err := resolveTransformedTags(tt.tags, tt.lowercaseKeys, transforms, tt.suppressUnmapped)
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectValues, values)
+		})
+	}
+}
+
+func TestParseTagTransforms(t *testing.T) {
+	for _, tt := range []struct {
+		desc          string
+		transforms    map[string]*TagTransform
+		lowercaseKeys bool
+		expectErr     string
+	}{
+		{
+			desc:       "missing selector",
+			transforms: map[string]*TagTransform{"CostCenter": {}},
+			expectErr:  `aws-iid: selector is required for tag "CostCenter" in tag_transforms`,
+		},
+		{
+			desc:       "selector with a colon",
+			transforms: map[string]*TagTransform{"CostCenter": {Selector: "team:name"}},
+			expectErr:  `aws-iid: selector "team:name" for tag "CostCenter" in tag_transforms cannot contain a colon`,
+		},
+		{
+			desc:       "selector category of the plugin",
+			transforms: map[string]*TagTransform{"CostCenter": {Selector: "sg"}},
+			expectErr:  `aws-iid: selector "sg" for tag "CostCenter" in tag_transforms is a selector category of the plugin`,
+		},
+		{
+			desc:       "invalid template",
+			transforms: map[string]*TagTransform{"CostCenter": {Selector: "team", Template: "{{ .Value "}},
+			expectErr:  `aws-iid: failed to parse template for tag "CostCenter" in tag_transforms`,
+		},
+		{
+			desc: "keys colliding once lowercased",
+			transforms: map[string]*TagTransform{
+				"Team": {Selector: "team"},
+				"TEAM": {Selector: "group"},
+			},
+			lowercaseKeys: true,
+			expectErr:     `aws-iid: tag "team" is transformed more than once in tag_transforms`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := parseTagTransforms(tt.transforms, tt.lowercaseKeys)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expectErr)
+		})
+	}
+}
+
+func (s *IIDAttestorSuite) TestConfigureTagTransforms() {
+	_, err := s.p.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: `
+tag_transforms = {
+	"CostCenter" = {
+		selector = "team"
+		values = {
+			"cc-1234" = "payments"
+		}
+		default = "unknown"
+	}
+	"Environment" = {
+		selector = "env"
+		template = "env-{{ .Value }}"
+	}
+}
+suppress_unmapped_tags = true
+`,
+		GlobalConfig: &plugin.ConfigureRequest_GlobalConfig{TrustDomain: "example.org"},
+	})
+	s.Require().NoError(err)
+
+	config := s.plugin.config
+	s.Require().True(config.SuppressUnmappedTags)
+	s.Require().Len(config.tagTransforms, 2)
+	s.Require().Equal("team", config.tagTransforms["CostCenter"].Selector)
+	s.Require().Equal(map[string]string{"cc-1234": "payments"}, config.tagTransforms["CostCenter"].Values)
+	s.Require().Equal("unknown", config.tagTransforms["CostCenter"].Default)
+	s.Require().NotNil(config.tagTransforms["Environment"].template)
+
+	// the transformed selectors are in the tag category
+	s.Require().Equal(tagSelectorCategory, config.selectorCategory("team:payments"))
+	s.Require().Equal("sg", config.selectorCategory("sg:id:TestGroup"))
+}
@@ -0,0 +1,240 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+type fakeEntry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// fakeDirectory is a minimal LDAP server that supports simple binds, StartTLS
+// and searches. Searches are answered with the entries registered for the
+// exact filter string.
+type fakeDirectory struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+
+	mu          sync.Mutex
+	entries     map[string][]fakeEntry
+	credentials map[string]string
+	conns       []net.Conn
+	connCount   int
+	bindCount   int
+	filters     []string
+}
+
+func newFakeDirectory(t *testing.T, tlsConfig *tls.Config, useTLS bool) *fakeDirectory {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	if useTLS {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	d := &fakeDirectory{
+		listener:    listener,
+		tlsConfig:   tlsConfig,
+		entries:     make(map[string][]fakeEntry),
+		credentials: make(map[string]string),
+	}
+	go d.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		d.CloseConnections()
+	})
+	return d
+}
+
+func (d *fakeDirectory) Addr() string {
+	return d.listener.Addr().String()
+}
+
+func (d *fakeDirectory) AddCredentials(dn, password string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.credentials[dn] = password
+}
+
+func (d *fakeDirectory) SetEntries(filter string, entries ...fakeEntry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries[filter] = entries
+}
+
+func (d *fakeDirectory) ConnCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.connCount
+}
+
+func (d *fakeDirectory) BindCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.bindCount
+}
+
+func (d *fakeDirectory) Filters() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.filters...)
+}
+
+// CloseConnections hangs up on the connected clients
+func (d *fakeDirectory) CloseConnections() {
+	d.mu.Lock()
+	conns := d.conns
+	d.conns = nil
+	d.mu.Unlock()
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+func (d *fakeDirectory) serve() {
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+		d.mu.Lock()
+		d.conns = append(d.conns, conn)
+		d.connCount++
+		d.mu.Unlock()
+		go d.handleConn(conn)
+	}
+}
+
+func (d *fakeDirectory) handleConn(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		if len(packet.Children) < 2 {
+			return
+		}
+		messageID, ok := packet.Children[0].Value.(int64)
+		if !ok {
+			return
+		}
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			d.handleBind(conn, messageID, op)
+		case ldap.ApplicationUnbindRequest:
+			return
+		case ldap.ApplicationSearchRequest:
+			d.handleSearch(conn, messageID, op)
+		case ldap.ApplicationExtendedRequest:
+			if op.Children[0].Data.String() != startTLSOID {
+				d.writeResult(conn, messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "unsupported extended operation")
+				continue
+			}
+			d.writeResult(conn, messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess, "")
+			tlsConn := tls.Server(conn, d.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+		default:
+			return
+		}
+	}
+}
+
+func (d *fakeDirectory) handleBind(conn net.Conn, messageID int64, op *ber.Packet) {
+	dn, _ := op.Children[1].Value.(string)
+	password := op.Children[2].Data.String()
+
+	d.mu.Lock()
+	d.bindCount++
+	expected, ok := d.credentials[dn]
+	d.mu.Unlock()
+
+	if !ok || expected != password {
+		d.writeResult(conn, messageID, ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "invalid credentials")
+		return
+	}
+	d.writeResult(conn, messageID, ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+}
+
+func (d *fakeDirectory) handleSearch(conn net.Conn, messageID int64, op *ber.Packet) {
+	filter, err := ldap.DecompileFilter(op.Children[6])
+	if err != nil {
+		d.writeResult(conn, messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError, err.Error())
+		return
+	}
+	var requested []string
+	for _, attr := range op.Children[7].Children {
+		requested = append(requested, attr.Value.(string))
+	}
+
+	d.mu.Lock()
+	d.filters = append(d.filters, filter)
+	entries := d.entries[filter]
+	d.mu.Unlock()
+
+	for _, entry := range entries {
+		envelope := newEnvelope(messageID)
+		result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+		result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "DN"))
+		attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+		for name, values := range entry.Attributes {
+			if !containsFold(requested, name) {
+				continue
+			}
+			attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+			attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+			for _, value := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+			}
+			attribute.AppendChild(set)
+			attributes.AppendChild(attribute)
+		}
+		result.AppendChild(attributes)
+		envelope.AppendChild(result)
+		if _, err := conn.Write(envelope.Bytes()); err != nil {
+			return
+		}
+	}
+	d.writeResult(conn, messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, "")
+}
+
+func (d *fakeDirectory) writeResult(conn net.Conn, messageID int64, tag ber.Tag, resultCode uint16, message string) {
+	envelope := newEnvelope(messageID)
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(resultCode), "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+	envelope.AppendChild(result)
+	_, _ = conn.Write(envelope.Bytes())
+}
+
+func newEnvelope(messageID int64) *ber.Packet {
+	envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	return envelope
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
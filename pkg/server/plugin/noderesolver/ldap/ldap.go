@@ -0,0 +1,382 @@
+package ldap
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	"github.com/zeebo/errs"
+
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/idutil"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/proto/spire/common"
+	spi "github.com/spiffe/spire/proto/spire/common/plugin"
+	noderesolverv0 "github.com/spiffe/spire/proto/spire/plugin/server/noderesolver/v0"
+)
+
+const (
+	pluginName = "ldap"
+
+	defaultAgentPathPattern = `^/spire/agent/x509pop/cn/([^/]+)$`
+	defaultSearchFilter     = "(cn={{ .CommonName }})"
+	defaultMaxConnections   = 4
+	defaultTimeout          = 10 * time.Second
+)
+
+var ldapError = errs.Class("ldap")
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		noderesolverv0.NodeResolverPluginServer(p),
+	)
+}
+
+type Config struct {
+	URL                string   `hcl:"url"`
+	StartTLS           bool     `hcl:"start_tls"`
+	CACertPath         string   `hcl:"ca_cert_path"`
+	InsecureSkipVerify bool     `hcl:"insecure_skip_verify"`
+	BindDN             string   `hcl:"bind_dn"`
+	BindPassword       string   `hcl:"bind_password"`
+	BaseDN             string   `hcl:"base_dn"`
+	AgentPathPattern   string   `hcl:"agent_path_pattern"`
+	SearchFilter       string   `hcl:"search_filter"`
+	Attributes         []string `hcl:"attributes"`
+	MaxConnections     int      `hcl:"max_connections"`
+	Timeout            string   `hcl:"timeout"`
+}
+
+// searchFilterData is the data the search filter template is executed with
+type searchFilterData struct {
+	// CommonName is the common name captured from the agent ID path, escaped
+	// to be used in a filter
+	CommonName string
+}
+
+type pluginConfig struct {
+	baseDN           string
+	agentPathPattern *regexp.Regexp
+	searchFilter     *template.Template
+	attributes       []string
+	timeout          time.Duration
+}
+
+type Plugin struct {
+	noderesolverv0.UnsafeNodeResolverServer
+
+	log hclog.Logger
+
+	mu     sync.RWMutex
+	config *pluginConfig
+	pool   *connPool
+}
+
+func New() *Plugin {
+	return &Plugin{}
+}
+
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) Resolve(ctx context.Context, req *noderesolverv0.ResolveRequest) (*noderesolverv0.ResolveResponse, error) {
+	config, pool, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &noderesolverv0.ResolveResponse{
+		Map: make(map[string]*common.Selectors),
+	}
+	for _, spiffeID := range req.BaseSpiffeIdList {
+		selectors, err := p.resolveSpiffeID(ctx, config, pool, spiffeID)
+		if err != nil {
+			return nil, err
+		}
+		if selectors != nil {
+			resp.Map[spiffeID] = selectors
+		}
+	}
+
+	return resp, nil
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *spi.ConfigureRequest) (*spi.ConfigureResponse, error) {
+	hclConfig := new(Config)
+	if err := hcl.Decode(hclConfig, req.Configuration); err != nil {
+		return nil, ldapError.New("unable to decode configuration: %v", err)
+	}
+
+	if hclConfig.URL == "" {
+		return nil, ldapError.New("url is required")
+	}
+	u, err := url.Parse(hclConfig.URL)
+	if err != nil {
+		return nil, ldapError.New("invalid url: %v", err)
+	}
+	switch u.Scheme {
+	case "ldap":
+	case "ldaps":
+		if hclConfig.StartTLS {
+			return nil, ldapError.New("start_tls cannot be used with an ldaps url")
+		}
+	default:
+		return nil, ldapError.New("invalid url: scheme must be ldap or ldaps")
+	}
+	if hclConfig.BaseDN == "" {
+		return nil, ldapError.New("base_dn is required")
+	}
+	if len(hclConfig.Attributes) == 0 {
+		return nil, ldapError.New("at least one attribute is required")
+	}
+	if hclConfig.BindPassword != "" && hclConfig.BindDN == "" {
+		return nil, ldapError.New("bind_password requires bind_dn")
+	}
+
+	agentPathPattern := defaultAgentPathPattern
+	if hclConfig.AgentPathPattern != "" {
+		agentPathPattern = hclConfig.AgentPathPattern
+	}
+	agentPathRE, err := regexp.Compile(agentPathPattern)
+	if err != nil {
+		return nil, ldapError.New("invalid agent_path_pattern: %v", err)
+	}
+	if agentPathRE.NumSubexp() != 1 {
+		return nil, ldapError.New("invalid agent_path_pattern: must have exactly one capture group")
+	}
+
+	searchFilter := defaultSearchFilter
+	if hclConfig.SearchFilter != "" {
+		searchFilter = hclConfig.SearchFilter
+	}
+	searchFilterTmpl, err := template.New("search-filter").Parse(searchFilter)
+	if err != nil {
+		return nil, ldapError.New("invalid search_filter: %v", err)
+	}
+
+	timeout := defaultTimeout
+	if hclConfig.Timeout != "" {
+		timeout, err = time.ParseDuration(hclConfig.Timeout)
+		if err != nil {
+			return nil, ldapError.New("invalid timeout: %v", err)
+		}
+	}
+
+	maxConnections := defaultMaxConnections
+	if hclConfig.MaxConnections < 0 {
+		return nil, ldapError.New("max_connections cannot be negative")
+	}
+	if hclConfig.MaxConnections > 0 {
+		maxConnections = hclConfig.MaxConnections
+	}
+
+	tlsConfig, err := buildTLSConfig(u, hclConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := newConnPool(maxConnections, func() (*ldap.Conn, error) {
+		return dial(hclConfig, tlsConfig, timeout)
+	})
+
+	p.setConfig(&pluginConfig{
+		baseDN:           hclConfig.BaseDN,
+		agentPathPattern: agentPathRE,
+		searchFilter:     searchFilterTmpl,
+		attributes:       hclConfig.Attributes,
+		timeout:          timeout,
+	}, pool)
+
+	return &spi.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) GetPluginInfo(context.Context, *spi.GetPluginInfoRequest) (*spi.GetPluginInfoResponse, error) {
+	return &spi.GetPluginInfoResponse{}, nil
+}
+
+func (p *Plugin) getConfig() (*pluginConfig, *connPool, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.config == nil {
+		return nil, nil, ldapError.New("not configured")
+	}
+	return p.config, p.pool, nil
+}
+
+func (p *Plugin) setConfig(config *pluginConfig, pool *connPool) {
+	p.mu.Lock()
+	oldPool := p.pool
+	p.config = config
+	p.pool = pool
+	p.mu.Unlock()
+
+	// Connections of the previous configuration are closed as they are
+	// released
+	if oldPool != nil {
+		oldPool.Close()
+	}
+}
+
+func (p *Plugin) resolveSpiffeID(ctx context.Context, config *pluginConfig, pool *connPool, spiffeID string) (*common.Selectors, error) {
+	u, err := idutil.ParseSpiffeID(spiffeID, idutil.AllowAnyTrustDomainAgent())
+	if err != nil {
+		return nil, ldapError.Wrap(err)
+	}
+
+	m := config.agentPathPattern.FindStringSubmatch(u.Path)
+	if m == nil || m[1] == "" {
+		p.log.Warn("Unrecognized agent ID", telemetry.SPIFFEID, spiffeID)
+		return nil, nil
+	}
+	commonName := m[1]
+
+	var filter bytes.Buffer
+	if err := config.searchFilter.Execute(&filter, searchFilterData{
+		CommonName: ldap.EscapeFilter(commonName),
+	}); err != nil {
+		return nil, ldapError.New("unable to build search filter: %v", err)
+	}
+
+	result, err := search(ctx, pool, ldap.NewSearchRequest(
+		config.baseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0,
+		int(config.timeout/time.Second),
+		false,
+		filter.String(),
+		config.attributes,
+		nil,
+	))
+	if err != nil {
+		return nil, ldapError.New("unable to search directory for %q: %v", commonName, err)
+	}
+
+	switch len(result.Entries) {
+	case 0:
+		p.log.Warn("No directory entry found for agent", telemetry.SPIFFEID, spiffeID)
+		return nil, nil
+	case 1:
+	default:
+		return nil, ldapError.New("expected one directory entry for %q at most; found %d", commonName, len(result.Entries))
+	}
+
+	return selectorsFromEntry(result.Entries[0], config.attributes), nil
+}
+
+// search runs the search request on a pooled connection. The search is
+// retried once on a network error, since idle connections may have been
+// closed by the directory in the meantime.
+func search(ctx context.Context, pool *connPool, req *ldap.SearchRequest) (result *ldap.SearchResult, err error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		var conn *ldap.Conn
+		conn, err = pool.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result, err = conn.Search(req)
+		broken := err != nil && (conn.IsClosing() || ldap.IsErrorWithCode(err, ldap.ErrorNetwork))
+		pool.Put(conn, err)
+		if !broken {
+			break
+		}
+	}
+	return result, err
+}
+
+// selectorsFromEntry returns the sorted selectors for the values of the
+// given attributes of the entry. Attribute names are matched case
+// insensitively, as directories may return them in a different case than
+// they were requested in, and the selectors use the configured names.
+func selectorsFromEntry(entry *ldap.Entry, attributes []string) *common.Selectors {
+	selectorMap := make(map[string]bool)
+	for _, attribute := range attributes {
+		for _, value := range entry.GetEqualFoldAttributeValues(attribute) {
+			selectorMap[attribute+":"+value] = true
+		}
+	}
+
+	selectorValues := make([]string, 0, len(selectorMap))
+	for selectorValue := range selectorMap {
+		selectorValues = append(selectorValues, selectorValue)
+	}
+	sort.Strings(selectorValues)
+
+	selectors := &common.Selectors{}
+	for _, selectorValue := range selectorValues {
+		selectors.Entries = append(selectors.Entries, &common.Selector{
+			Type:  pluginName,
+			Value: selectorValue,
+		})
+	}
+	return selectors
+}
+
+func buildTLSConfig(u *url.URL, config *Config) (*tls.Config, error) {
+	if u.Scheme != "ldaps" && !config.StartTLS {
+		if config.CACertPath != "" || config.InsecureSkipVerify {
+			return nil, ldapError.New("TLS settings require an ldaps url or start_tls")
+		}
+		return nil, nil
+	}
+
+	host := u.Hostname()
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: config.InsecureSkipVerify, // nolint: gosec // opt-in for test environments
+		MinVersion:         tls.VersionTLS12,
+	}
+	if config.CACertPath != "" {
+		certs, err := pemutil.LoadCertificates(config.CACertPath)
+		if err != nil {
+			return nil, ldapError.New("unable to load CA certificates: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		for _, cert := range certs {
+			tlsConfig.RootCAs.AddCert(cert)
+		}
+	}
+	return tlsConfig, nil
+}
+
+func dial(config *Config, tlsConfig *tls.Config, timeout time.Duration) (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(timeout)
+
+	if config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, errs.New("StartTLS failed: %v", err)
+		}
+	}
+
+	if config.BindDN != "" {
+		if err := conn.Bind(config.BindDN, config.BindPassword); err != nil {
+			conn.Close()
+			return nil, errs.New("bind failed: %v", err)
+		}
+	}
+	return conn, nil
+}
@@ -0,0 +1,363 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/server/plugin/noderesolver"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/proto/spire/common/plugin"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	agentID      = "spiffe://example.org/spire/agent/x509pop/cn/web-01"
+	bindDN       = "cn=spire,ou=services,dc=example,dc=org"
+	bindPassword = "secret"
+)
+
+var webEntry = fakeEntry{
+	DN: "cn=web-01,ou=hosts,dc=example,dc=org",
+	Attributes: map[string][]string{
+		"Department": {"engineering"},
+		"owner":      {"alice", "bob"},
+		"location":   {"dc1"},
+	},
+}
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		config    string
+		expectErr string
+	}{
+		{
+			name:      "malformed configuration",
+			config:    "{{",
+			expectErr: "ldap: unable to decode configuration",
+		},
+		{
+			name:      "missing url",
+			config:    `base_dn = "dc=example,dc=org" attributes = ["owner"]`,
+			expectErr: "ldap: url is required",
+		},
+		{
+			name:      "unsupported url scheme",
+			config:    `url = "http://localhost" base_dn = "dc=example,dc=org" attributes = ["owner"]`,
+			expectErr: "ldap: invalid url: scheme must be ldap or ldaps",
+		},
+		{
+			name:      "start_tls with ldaps",
+			config:    `url = "ldaps://localhost" start_tls = true base_dn = "dc=example,dc=org" attributes = ["owner"]`,
+			expectErr: "ldap: start_tls cannot be used with an ldaps url",
+		},
+		{
+			name:      "TLS settings without TLS",
+			config:    `url = "ldap://localhost" insecure_skip_verify = true base_dn = "dc=example,dc=org" attributes = ["owner"]`,
+			expectErr: "ldap: TLS settings require an ldaps url or start_tls",
+		},
+		{
+			name:      "missing base_dn",
+			config:    `url = "ldap://localhost" attributes = ["owner"]`,
+			expectErr: "ldap: base_dn is required",
+		},
+		{
+			name:      "missing attributes",
+			config:    `url = "ldap://localhost" base_dn = "dc=example,dc=org"`,
+			expectErr: "ldap: at least one attribute is required",
+		},
+		{
+			name:      "bind_password without bind_dn",
+			config:    `url = "ldap://localhost" base_dn = "dc=example,dc=org" attributes = ["owner"] bind_password = "secret"`,
+			expectErr: "ldap: bind_password requires bind_dn",
+		},
+		{
+			name:      "invalid agent_path_pattern",
+			config:    `url = "ldap://localhost" base_dn = "dc=example,dc=org" attributes = ["owner"] agent_path_pattern = "("`,
+			expectErr: "ldap: invalid agent_path_pattern",
+		},
+		{
+			name:      "agent_path_pattern without a capture group",
+			config:    `url = "ldap://localhost" base_dn = "dc=example,dc=org" attributes = ["owner"] agent_path_pattern = "^/spire/agent/.*$"`,
+			expectErr: "ldap: invalid agent_path_pattern: must have exactly one capture group",
+		},
+		{
+			name:      "invalid search_filter",
+			config:    `url = "ldap://localhost" base_dn = "dc=example,dc=org" attributes = ["owner"] search_filter = "(cn={{ .CommonName )"`,
+			expectErr: "ldap: invalid search_filter",
+		},
+		{
+			name:      "invalid timeout",
+			config:    `url = "ldap://localhost" base_dn = "dc=example,dc=org" attributes = ["owner"] timeout = "soon"`,
+			expectErr: "ldap: invalid timeout",
+		},
+		{
+			name:      "negative max_connections",
+			config:    `url = "ldap://localhost" base_dn = "dc=example,dc=org" attributes = ["owner"] max_connections = -1`,
+			expectErr: "ldap: max_connections cannot be negative",
+		},
+		{
+			name:      "missing CA certificates",
+			config:    `url = "ldaps://localhost" base_dn = "dc=example,dc=org" attributes = ["owner"] ca_cert_path = "/does/not/exist.pem"`,
+			expectErr: "ldap: unable to load CA certificates",
+		},
+		{
+			name:   "success",
+			config: `url = "ldap://localhost" base_dn = "dc=example,dc=org" attributes = ["owner"]`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := loadResolver(t)
+			_, err := resolver.Configure(context.Background(), &plugin.ConfigureRequest{
+				Configuration: tt.config,
+			})
+			if tt.expectErr != "" {
+				spiretest.RequireGRPCStatusContains(t, err, codes.Unknown, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestResolveNotConfigured(t *testing.T) {
+	resolver := loadResolver(t)
+	selectors, err := resolver.Resolve(context.Background(), agentID)
+	spiretest.RequireGRPCStatusContains(t, err, codes.Unknown, "noderesolver(ldap): not configured")
+	require.Empty(t, selectors)
+}
+
+func TestResolve(t *testing.T) {
+	directory := newFakeDirectory(t, nil, false)
+	directory.AddCredentials(bindDN, bindPassword)
+	directory.SetEntries("(cn=web-01)", webEntry)
+	directory.SetEntries("(cn=dup)", webEntry, webEntry)
+
+	resolver := loadResolver(t)
+	configureResolver(t, resolver, directory, "ldap", fmt.Sprintf(`
+		bind_dn = %q
+		bind_password = %q
+	`, bindDN, bindPassword))
+
+	t.Run("attributes are mapped to selectors", func(t *testing.T) {
+		selectors, err := resolver.Resolve(context.Background(), agentID)
+		require.NoError(t, err)
+		spiretest.RequireProtoListEqual(t, []*common.Selector{
+			{Type: "ldap", Value: "department:engineering"},
+			{Type: "ldap", Value: "owner:alice"},
+			{Type: "ldap", Value: "owner:bob"},
+		}, selectors)
+	})
+
+	t.Run("agent ID not matching the pattern is ignored", func(t *testing.T) {
+		selectors, err := resolver.Resolve(context.Background(), "spiffe://example.org/spire/agent/x509pop/0123456789abcdef")
+		require.NoError(t, err)
+		require.Empty(t, selectors)
+	})
+
+	t.Run("non-agent ID", func(t *testing.T) {
+		selectors, err := resolver.Resolve(context.Background(), "spiffe://example.org/workload")
+		spiretest.RequireGRPCStatusContains(t, err, codes.Unknown, `noderesolver(ldap): "spiffe://example.org/workload" is not a valid agent SPIFFE ID`)
+		require.Empty(t, selectors)
+	})
+
+	t.Run("no directory entry", func(t *testing.T) {
+		selectors, err := resolver.Resolve(context.Background(), "spiffe://example.org/spire/agent/x509pop/cn/unknown")
+		require.NoError(t, err)
+		require.Empty(t, selectors)
+	})
+
+	t.Run("multiple directory entries", func(t *testing.T) {
+		selectors, err := resolver.Resolve(context.Background(), "spiffe://example.org/spire/agent/x509pop/cn/dup")
+		spiretest.RequireGRPCStatusContains(t, err, codes.Unknown, `noderesolver(ldap): expected one directory entry for "dup" at most; found 2`)
+		require.Empty(t, selectors)
+	})
+
+	t.Run("common name is escaped in the filter", func(t *testing.T) {
+		_, err := resolver.Resolve(context.Background(), "spiffe://example.org/spire/agent/x509pop/cn/web*)(cn=*")
+		require.NoError(t, err)
+		filters := directory.Filters()
+		require.Equal(t, `(cn=web\2a\29\28cn=\2a)`, filters[len(filters)-1])
+	})
+
+	// All of the searches above were done over the same connection
+	require.Equal(t, 1, directory.ConnCount())
+	require.Equal(t, 1, directory.BindCount())
+}
+
+func TestResolveWithCustomPatternAndFilter(t *testing.T) {
+	directory := newFakeDirectory(t, nil, false)
+	directory.SetEntries("(&(objectClass=device)(serialNumber=web-01))", webEntry)
+
+	resolver := loadResolver(t)
+	configureResolver(t, resolver, directory, "ldap", `
+		agent_path_pattern = "^/spire/agent/x509pop/serial/([^/]+)$"
+		search_filter = "(&(objectClass=device)(serialNumber={{ .CommonName }}))"
+	`)
+
+	selectors, err := resolver.Resolve(context.Background(), "spiffe://example.org/spire/agent/x509pop/serial/web-01")
+	require.NoError(t, err)
+	spiretest.RequireProtoListEqual(t, []*common.Selector{
+		{Type: "ldap", Value: "department:engineering"},
+		{Type: "ldap", Value: "owner:alice"},
+		{Type: "ldap", Value: "owner:bob"},
+	}, selectors)
+
+	// Without bind_dn the search is anonymous
+	require.Equal(t, 0, directory.BindCount())
+}
+
+func TestResolveWithInvalidCredentials(t *testing.T) {
+	directory := newFakeDirectory(t, nil, false)
+	directory.AddCredentials(bindDN, bindPassword)
+
+	resolver := loadResolver(t)
+	configureResolver(t, resolver, directory, "ldap", fmt.Sprintf(`
+		bind_dn = %q
+		bind_password = "wrong"
+	`, bindDN))
+
+	selectors, err := resolver.Resolve(context.Background(), agentID)
+	spiretest.RequireGRPCStatusContains(t, err, codes.Unknown, `noderesolver(ldap): unable to search directory for "web-01": bind failed`)
+	require.Empty(t, selectors)
+}
+
+func TestResolveWithLDAPS(t *testing.T) {
+	caPath, tlsConfig := createTLSConfig(t)
+	directory := newFakeDirectory(t, tlsConfig, true)
+	directory.SetEntries("(cn=web-01)", webEntry)
+
+	resolver := loadResolver(t)
+	configureResolver(t, resolver, directory, "ldaps", fmt.Sprintf(`ca_cert_path = %q`, caPath))
+
+	selectors, err := resolver.Resolve(context.Background(), agentID)
+	require.NoError(t, err)
+	require.Len(t, selectors, 3)
+}
+
+func TestResolveWithLDAPSUntrustedServer(t *testing.T) {
+	_, tlsConfig := createTLSConfig(t)
+	otherCAPath, _ := createTLSConfig(t)
+	directory := newFakeDirectory(t, tlsConfig, true)
+	directory.SetEntries("(cn=web-01)", webEntry)
+
+	resolver := loadResolver(t)
+	configureResolver(t, resolver, directory, "ldaps", fmt.Sprintf(`ca_cert_path = %q`, otherCAPath))
+
+	selectors, err := resolver.Resolve(context.Background(), agentID)
+	spiretest.RequireGRPCStatusContains(t, err, codes.Unknown, "certificate signed by unknown authority")
+	require.Empty(t, selectors)
+}
+
+func TestResolveWithStartTLS(t *testing.T) {
+	caPath, tlsConfig := createTLSConfig(t)
+	directory := newFakeDirectory(t, tlsConfig, false)
+	directory.AddCredentials(bindDN, bindPassword)
+	directory.SetEntries("(cn=web-01)", webEntry)
+
+	resolver := loadResolver(t)
+	configureResolver(t, resolver, directory, "ldap", fmt.Sprintf(`
+		start_tls = true
+		ca_cert_path = %q
+		bind_dn = %q
+		bind_password = %q
+	`, caPath, bindDN, bindPassword))
+
+	selectors, err := resolver.Resolve(context.Background(), agentID)
+	require.NoError(t, err)
+	require.Len(t, selectors, 3)
+	require.Equal(t, 1, directory.BindCount())
+}
+
+func TestResolveReconnectsAfterConnectionLoss(t *testing.T) {
+	directory := newFakeDirectory(t, nil, false)
+	directory.SetEntries("(cn=web-01)", webEntry)
+
+	resolver := loadResolver(t)
+	configureResolver(t, resolver, directory, "ldap", "")
+
+	_, err := resolver.Resolve(context.Background(), agentID)
+	require.NoError(t, err)
+
+	// The pooled connection is dropped by the directory
+	directory.CloseConnections()
+
+	selectors, err := resolver.Resolve(context.Background(), agentID)
+	require.NoError(t, err)
+	require.Len(t, selectors, 3)
+	require.Equal(t, 2, directory.ConnCount())
+}
+
+func TestResolveLimitsConnections(t *testing.T) {
+	directory := newFakeDirectory(t, nil, false)
+	directory.SetEntries("(cn=web-01)", webEntry)
+
+	resolver := loadResolver(t)
+	configureResolver(t, resolver, directory, "ldap", "max_connections = 2")
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := resolver.Resolve(context.Background(), agentID)
+			errCh <- err
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		require.NoError(t, err)
+	}
+
+	require.LessOrEqual(t, directory.ConnCount(), 2)
+}
+
+func loadResolver(t *testing.T) noderesolver.V0 {
+	var resolver noderesolver.V0
+	plugintest.Load(t, BuiltIn(), &resolver)
+	return resolver
+}
+
+func configureResolver(t *testing.T, resolver noderesolver.V0, directory *fakeDirectory, scheme, extraConfig string) {
+	_, err := resolver.Configure(context.Background(), &plugin.ConfigureRequest{
+		Configuration: fmt.Sprintf(`
+			url = "%s://%s"
+			base_dn = "ou=hosts,dc=example,dc=org"
+			attributes = ["department", "owner"]
+			%s
+		`, scheme, directory.Addr(), extraConfig),
+	})
+	require.NoError(t, err)
+}
+
+// createTLSConfig returns the path to a CA certificate and the TLS
+// configuration of a server, on the loopback address, signed by that CA.
+func createTLSConfig(t *testing.T) (string, *tls.Config) {
+	caCert, caKey := testca.CreateCACertificate(t, nil, nil)
+	serverCert, serverKey := testca.CreateX509Certificate(t, caCert, caKey,
+		testca.WithIPAddresses(net.ParseIP("127.0.0.1")))
+
+	caPath := filepath.Join(spiretest.TempDir(t), "ca.pem")
+	require.NoError(t, pemutil.SaveCertificate(caPath, caCert, 0600))
+
+	return caPath, &tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{serverCert.Raw},
+			PrivateKey:  serverKey,
+		}},
+		MinVersion: tls.VersionTLS12,
+	}
+}
@@ -0,0 +1,109 @@
+package ldap
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// connPool is a pool of bound connections to the directory. At most max
+// connections are open at a time; callers wait for one to be released once
+// the limit is reached. Released connections are kept for reuse unless the
+// operation done with them failed with a network error.
+type connPool struct {
+	dial func() (*ldap.Conn, error)
+
+	// slots holds a token for each connection that can still be opened
+	slots chan struct{}
+
+	mu     sync.Mutex
+	idle   []*ldap.Conn
+	closed bool
+}
+
+func newConnPool(max int, dial func() (*ldap.Conn, error)) *connPool {
+	slots := make(chan struct{}, max)
+	for i := 0; i < max; i++ {
+		slots <- struct{}{}
+	}
+	return &connPool{
+		dial:  dial,
+		slots: slots,
+	}
+}
+
+// Get returns an idle connection, or dials a new one. The connection must be
+// released with Put.
+func (p *connPool) Get(ctx context.Context) (*ldap.Conn, error) {
+	select {
+	case <-p.slots:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	for {
+		conn := p.popIdle()
+		if conn == nil {
+			break
+		}
+		// The connection is closing if the server hung up while it was idle
+		if !conn.IsClosing() {
+			return conn, nil
+		}
+		conn.Close()
+	}
+
+	conn, err := p.dial()
+	if err != nil {
+		p.slots <- struct{}{}
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Put releases a connection obtained with Get. The error is the result of
+// the operation done with the connection.
+func (p *connPool) Put(conn *ldap.Conn, err error) {
+	defer func() {
+		p.slots <- struct{}{}
+	}()
+
+	if ldap.IsErrorWithCode(err, ldap.ErrorNetwork) || conn.IsClosing() {
+		conn.Close()
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		conn.Close()
+		return
+	}
+	p.idle = append(p.idle, conn)
+}
+
+// Close closes the idle connections. Connections in use are closed once they
+// are released.
+func (p *connPool) Close() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	for _, conn := range idle {
+		conn.Close()
+	}
+}
+
+func (p *connPool) popIdle() *ldap.Conn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.idle) == 0 {
+		return nil
+	}
+	conn := p.idle[len(p.idle)-1]
+	p.idle = p.idle[:len(p.idle)-1]
+	return conn
+}
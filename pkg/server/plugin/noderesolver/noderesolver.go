@@ -12,3 +12,34 @@ type NodeResolver interface {
 
 	Resolve(ctx context.Context, agentID string) ([]*common.Selector, error)
 }
+
+// BatchNodeResolver is implemented by node resolvers that can resolve the
+// selectors for several agents at once.
+type BatchNodeResolver interface {
+	NodeResolver
+
+	// ResolveBatch returns the selectors for each of the given agent IDs.
+	// Agents without selectors may be omitted from the returned map.
+	ResolveBatch(ctx context.Context, agentIDs []string) (map[string][]*common.Selector, error)
+}
+
+// ResolveBatch resolves the selectors for the given agent IDs. If the node
+// resolver does not implement BatchNodeResolver, the agent IDs are resolved
+// one at a time.
+func ResolveBatch(ctx context.Context, nodeResolver NodeResolver, agentIDs []string) (map[string][]*common.Selector, error) {
+	if batchNodeResolver, ok := nodeResolver.(BatchNodeResolver); ok {
+		return batchNodeResolver.ResolveBatch(ctx, agentIDs)
+	}
+
+	selectors := make(map[string][]*common.Selector, len(agentIDs))
+	for _, agentID := range agentIDs {
+		agentSelectors, err := nodeResolver.Resolve(ctx, agentID)
+		if err != nil {
+			return nil, err
+		}
+		if len(agentSelectors) > 0 {
+			selectors[agentID] = agentSelectors
+		}
+	}
+	return selectors, nil
+}
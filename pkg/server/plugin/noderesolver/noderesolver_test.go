@@ -0,0 +1,101 @@
+package noderesolver_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/server/plugin/noderesolver"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakenoderesolver"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ noderesolver.BatchNodeResolver = (*noderesolver.V0)(nil)
+	_ noderesolver.NodeResolver      = (*singleNodeResolver)(nil)
+)
+
+func TestResolveBatchWithBatchNodeResolver(t *testing.T) {
+	nr := fakenoderesolver.New(t, "fake", map[string][]string{
+		"spiffe://example.org/spire/agent/a": {"a1", "a2"},
+		"spiffe://example.org/spire/agent/b": {"b1"},
+	})
+	_, ok := nr.(noderesolver.BatchNodeResolver)
+	require.True(t, ok, "fake node resolver does not implement BatchNodeResolver")
+
+	selectors, err := noderesolver.ResolveBatch(context.Background(), nr, []string{
+		"spiffe://example.org/spire/agent/a",
+		"spiffe://example.org/spire/agent/b",
+		"spiffe://example.org/spire/agent/c",
+	})
+	require.NoError(t, err)
+	assertSelectors(t, map[string][]*common.Selector{
+		"spiffe://example.org/spire/agent/a": {{Type: "fake", Value: "a1"}, {Type: "fake", Value: "a2"}},
+		"spiffe://example.org/spire/agent/b": {{Type: "fake", Value: "b1"}},
+	}, selectors)
+}
+
+func TestResolveBatchFallsBackToResolve(t *testing.T) {
+	nr := &singleNodeResolver{
+		selectors: map[string][]*common.Selector{
+			"spiffe://example.org/spire/agent/a": {{Type: "single", Value: "a1"}},
+			"spiffe://example.org/spire/agent/b": {{Type: "single", Value: "b1"}},
+		},
+	}
+
+	selectors, err := noderesolver.ResolveBatch(context.Background(), nr, []string{
+		"spiffe://example.org/spire/agent/a",
+		"spiffe://example.org/spire/agent/b",
+		"spiffe://example.org/spire/agent/c",
+	})
+	require.NoError(t, err)
+	assertSelectors(t, map[string][]*common.Selector{
+		"spiffe://example.org/spire/agent/a": {{Type: "single", Value: "a1"}},
+		"spiffe://example.org/spire/agent/b": {{Type: "single", Value: "b1"}},
+	}, selectors)
+	assert.Equal(t, []string{
+		"spiffe://example.org/spire/agent/a",
+		"spiffe://example.org/spire/agent/b",
+		"spiffe://example.org/spire/agent/c",
+	}, nr.resolved)
+}
+
+func TestResolveBatchFallbackFailure(t *testing.T) {
+	nr := &singleNodeResolver{
+		err: errors.New("ohno"),
+	}
+
+	selectors, err := noderesolver.ResolveBatch(context.Background(), nr, []string{"spiffe://example.org/spire/agent/a"})
+	assert.EqualError(t, err, "ohno")
+	assert.Nil(t, selectors)
+}
+
+func assertSelectors(t *testing.T, expected, actual map[string][]*common.Selector) {
+	require.Len(t, actual, len(expected))
+	for agentID, expectedSelectors := range expected {
+		spiretest.AssertProtoListEqual(t, expectedSelectors, actual[agentID])
+	}
+}
+
+// singleNodeResolver only implements NodeResolver, which forces ResolveBatch
+// to resolve agents one at a time.
+type singleNodeResolver struct {
+	selectors map[string][]*common.Selector
+	err       error
+	resolved  []string
+}
+
+func (r *singleNodeResolver) Name() string { return "single" }
+
+func (r *singleNodeResolver) Type() string { return "NodeResolver" }
+
+func (r *singleNodeResolver) Resolve(ctx context.Context, agentID string) ([]*common.Selector, error) {
+	r.resolved = append(r.resolved, agentID)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.selectors[agentID], nil
+}
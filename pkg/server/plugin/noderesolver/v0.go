@@ -26,3 +26,21 @@ func (v0 *V0) Resolve(ctx context.Context, agentID string) ([]*common.Selector,
 	}
 	return selectors.Entries, nil
 }
+
+func (v0 *V0) ResolveBatch(ctx context.Context, agentIDs []string) (map[string][]*common.Selector, error) {
+	resp, err := v0.NodeResolverPluginClient.Resolve(ctx, &noderesolverv0.ResolveRequest{
+		BaseSpiffeIdList: agentIDs,
+	})
+	if err != nil {
+		return nil, v0.WrapErr(err)
+	}
+	// Only keep the selectors of the requested agents, in case the plugin
+	// returns more than it was asked for
+	selectors := make(map[string][]*common.Selector, len(agentIDs))
+	for _, agentID := range agentIDs {
+		if entries := resp.Map[agentID].GetEntries(); len(entries) > 0 {
+			selectors[agentID] = entries
+		}
+	}
+	return selectors, nil
+}
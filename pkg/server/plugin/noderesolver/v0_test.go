@@ -54,6 +54,25 @@ func TestV0(t *testing.T) {
 	})
 }
 
+func TestV0ResolveBatch(t *testing.T) {
+	server := noderesolverv0.NodeResolverPluginServer(&v0BatchPlugin{})
+	v0 := new(noderesolver.V0)
+	plugintest.Load(t, catalog.MakeBuiltIn("test", server), v0)
+
+	t.Run("success", func(t *testing.T) {
+		actualSelectors, err := v0.ResolveBatch(context.Background(), []string{"with-selectors", "without-selectors", "nil-selectors-in-map", "missing"})
+		assert.NoError(t, err)
+		assert.Len(t, actualSelectors, 1)
+		spiretest.AssertProtoListEqual(t, expectedSelectors, actualSelectors["with-selectors"])
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		actualSelectors, err := v0.ResolveBatch(context.Background(), []string{"with-selectors", "bad"})
+		spiretest.AssertGRPCStatus(t, err, codes.FailedPrecondition, "noderesolver(test): ohno")
+		assert.Nil(t, actualSelectors)
+	})
+}
+
 func loadV0Plugin(t *testing.T) noderesolver.NodeResolver {
 	server := noderesolverv0.NodeResolverPluginServer(&v0Plugin{})
 
@@ -93,3 +112,29 @@ func (plugin *v0Plugin) Resolve(ctx context.Context, req *noderesolverv0.Resolve
 	}
 	return resp, nil
 }
+
+type v0BatchPlugin struct {
+	noderesolverv0.UnimplementedNodeResolverServer
+}
+
+func (plugin *v0BatchPlugin) Resolve(ctx context.Context, req *noderesolverv0.ResolveRequest) (*noderesolverv0.ResolveResponse, error) {
+	resp := &noderesolverv0.ResolveResponse{
+		Map: map[string]*common.Selectors{
+			// not requested, so it should be dropped
+			"extra": {Entries: expectedSelectors},
+		},
+	}
+	for _, agentID := range req.BaseSpiffeIdList {
+		switch agentID {
+		case "with-selectors":
+			resp.Map[agentID] = &common.Selectors{Entries: expectedSelectors}
+		case "without-selectors":
+			resp.Map[agentID] = &common.Selectors{}
+		case "nil-selectors-in-map":
+			resp.Map[agentID] = nil
+		case "bad":
+			return nil, status.Error(codes.FailedPrecondition, "ohno")
+		}
+	}
+	return resp, nil
+}
@@ -3,8 +3,11 @@ package disk
 import (
 	"context"
 	"crypto/x509"
+	"encoding/asn1"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -34,11 +37,13 @@ func builtin(p *Plugin) catalog.BuiltIn {
 }
 
 type Configuration struct {
-	trustDomain spiffeid.TrustDomain
+	trustDomain       spiffeid.TrustDomain
+	policyIdentifiers []asn1.ObjectIdentifier
 
-	CertFilePath   string `hcl:"cert_file_path" json:"cert_file_path"`
-	KeyFilePath    string `hcl:"key_file_path" json:"key_file_path"`
-	BundleFilePath string `hcl:"bundle_file_path" json:"bundle_file_path"`
+	CertFilePath        string   `hcl:"cert_file_path" json:"cert_file_path"`
+	KeyFilePath         string   `hcl:"key_file_path" json:"key_file_path"`
+	BundleFilePath      string   `hcl:"bundle_file_path" json:"bundle_file_path"`
+	CertificatePolicies []string `hcl:"certificate_policies" json:"certificate_policies,omitempty"`
 }
 
 type Plugin struct {
@@ -92,6 +97,14 @@ func (p *Plugin) Configure(ctx context.Context, req *spi.ConfigureRequest) (*spi
 	}
 	config.trustDomain = trustDomain
 
+	for _, policy := range config.CertificatePolicies {
+		oid, err := parseOID(policy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate policy %q: %v", policy, err)
+		}
+		config.policyIdentifiers = append(config.policyIdentifiers, oid)
+	}
+
 	upstreamCA, certs, err := p.loadUpstreamCAAndCerts(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load upstream CA: %v", err)
@@ -226,11 +239,45 @@ func (p *Plugin) loadUpstreamCAAndCerts(config *Configuration) (*x509svid.Upstre
 		x509util.NewMemoryKeypair(caCert, key),
 		config.trustDomain,
 		x509svid.UpstreamCAOptions{
-			Clock: p.clock,
+			Clock:             p.clock,
+			PolicyIdentifiers: config.policyIdentifiers,
 		},
 	), caCerts, nil
 }
 
+// parseOID parses an object identifier in dotted decimal notation, e.g.
+// "2.23.140.1.2.1". The OID must be encodable in a certificate, i.e. have at
+// least two arcs, the first being 0, 1 or 2 and the second below 40 unless
+// the first is 2.
+func parseOID(s string) (asn1.ObjectIdentifier, error) {
+	arcs := strings.Split(s, ".")
+	if len(arcs) < 2 {
+		return nil, errors.New("must have at least two arcs")
+	}
+
+	oid := make(asn1.ObjectIdentifier, 0, len(arcs))
+	for _, arc := range arcs {
+		// Reject signs and leading zeros, which are not valid in the dotted
+		// decimal notation
+		if arc == "" || strings.TrimLeft(arc, "0123456789") != "" || (len(arc) > 1 && arc[0] == '0') {
+			return nil, fmt.Errorf("arc %q is not a non-negative integer", arc)
+		}
+		n, err := strconv.ParseInt(arc, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("arc %q is out of range", arc)
+		}
+		oid = append(oid, int(n))
+	}
+
+	switch {
+	case oid[0] > 2:
+		return nil, errors.New("first arc must be 0, 1 or 2")
+	case oid[0] < 2 && oid[1] >= 40:
+		return nil, errors.New("second arc must be below 40 when the first arc is 0 or 1")
+	}
+	return oid, nil
+}
+
 func makeError(code codes.Code, format string, args ...interface{}) error {
 	return status.Errorf(code, "upstreamauthority-disk: "+format, args...)
 }
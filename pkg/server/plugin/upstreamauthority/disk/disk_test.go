@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
+	"encoding/asn1"
 	"encoding/json"
 	"io"
 	"testing"
@@ -242,6 +243,54 @@ func (s *DiskSuite) TestMintX509CAUsesPreferredTTLIfSet() {
 	s.testCSRTTL(0, x509svid.DefaultUpstreamCATTL)
 }
 
+func (s *DiskSuite) TestMintX509CAWithCertificatePolicies() {
+	config, err := json.Marshal(Configuration{
+		KeyFilePath:         "_test_data/keys/EC/private_key.pem",
+		CertFilePath:        "_test_data/keys/EC/cert.pem",
+		CertificatePolicies: []string{"2.23.140.1.2.1", "1.3.6.1.4.1.99999.1"},
+	})
+	s.Require().NoError(err)
+	_, err = s.p.Configure(ctx, &spi.ConfigureRequest{
+		Configuration: string(config),
+		GlobalConfig:  &spi.ConfigureRequest_GlobalConfig{TrustDomain: "localhost"},
+	})
+	s.Require().NoError(err)
+
+	csr, _, err := util.NewCSRTemplate("spiffe://localhost")
+	s.Require().NoError(err)
+	resp, err := s.mintX509CA(&upstreamauthorityv0.MintX509CARequest{Csr: csr})
+	s.Require().NoError(err)
+
+	certs, err := x509util.RawCertsToCertificates(resp.X509CaChain)
+	s.Require().NoError(err)
+	s.Require().Len(certs, 1)
+	s.Require().Equal([]asn1.ObjectIdentifier{
+		{2, 23, 140, 1, 2, 1},
+		{1, 3, 6, 1, 4, 1, 99999, 1},
+	}, certs[0].PolicyIdentifiers)
+
+	// The policies are carried in the certificate policies extension
+	var found bool
+	for _, ext := range certs[0].Extensions {
+		if ext.Id.Equal(asn1.ObjectIdentifier{2, 5, 29, 32}) {
+			found = true
+		}
+	}
+	s.Require().True(found, "certificate policies extension is missing")
+}
+
+func (s *DiskSuite) TestMintX509CAWithoutCertificatePolicies() {
+	csr, _, err := util.NewCSRTemplate("spiffe://localhost")
+	s.Require().NoError(err)
+	resp, err := s.mintX509CA(&upstreamauthorityv0.MintX509CARequest{Csr: csr})
+	s.Require().NoError(err)
+
+	certs, err := x509util.RawCertsToCertificates(resp.X509CaChain)
+	s.Require().NoError(err)
+	s.Require().Len(certs, 1)
+	s.Require().Empty(certs[0].PolicyIdentifiers)
+}
+
 func (s *DiskSuite) testCSRTTL(preferredTTL int32, expectedTTL time.Duration) {
 	validSpiffeID := "spiffe://localhost"
 	csr, _, err := util.NewCSRTemplate(validSpiffeID)
@@ -353,6 +402,48 @@ func TestInvalidConfigs(t *testing.T) {
 			inputConfig:       `this is :[ invalid ^^^ hcl`,
 			expectErrContains: "illegal char",
 		},
+		{
+			msg:               "certificate policy with one arc",
+			trustDomain:       "trust.domain",
+			inputConfig:       `certificate_policies = ["2"]`,
+			expectErrContains: `invalid certificate policy "2": must have at least two arcs`,
+		},
+		{
+			msg:               "certificate policy with an empty arc",
+			trustDomain:       "trust.domain",
+			inputConfig:       `certificate_policies = ["2..1"]`,
+			expectErrContains: `invalid certificate policy "2..1": arc "" is not a non-negative integer`,
+		},
+		{
+			msg:               "certificate policy with a non-numeric arc",
+			trustDomain:       "trust.domain",
+			inputConfig:       `certificate_policies = ["2.23.ca.1"]`,
+			expectErrContains: `invalid certificate policy "2.23.ca.1": arc "ca" is not a non-negative integer`,
+		},
+		{
+			msg:               "certificate policy with a leading zero",
+			trustDomain:       "trust.domain",
+			inputConfig:       `certificate_policies = ["2.023.140"]`,
+			expectErrContains: `invalid certificate policy "2.023.140": arc "023" is not a non-negative integer`,
+		},
+		{
+			msg:               "certificate policy with an arc out of range",
+			trustDomain:       "trust.domain",
+			inputConfig:       `certificate_policies = ["2.99999999999"]`,
+			expectErrContains: `invalid certificate policy "2.99999999999": arc "99999999999" is out of range`,
+		},
+		{
+			msg:               "certificate policy with an invalid first arc",
+			trustDomain:       "trust.domain",
+			inputConfig:       `certificate_policies = ["3.1"]`,
+			expectErrContains: `invalid certificate policy "3.1": first arc must be 0, 1 or 2`,
+		},
+		{
+			msg:               "certificate policy with an invalid second arc",
+			trustDomain:       "trust.domain",
+			inputConfig:       `certificate_policies = ["1.40"]`,
+			expectErrContains: `invalid certificate policy "1.40": second arc must be below 40 when the first arc is 0 or 1`,
+		},
 		{
 			msg:               "no trust domain",
 			expectErrContains: "trust_domain is required",
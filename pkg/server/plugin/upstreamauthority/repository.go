@@ -1,21 +1,38 @@
 package upstreamauthority
 
 type Repository struct {
-	UpstreamAuthority UpstreamAuthority
+	UpstreamAuthorities []UpstreamAuthority
 }
 
+// GetUpstreamAuthority returns the first upstream authority, if any.
 func (repo *Repository) GetUpstreamAuthority() (UpstreamAuthority, bool) {
-	return repo.UpstreamAuthority, repo.UpstreamAuthority != nil
+	if len(repo.UpstreamAuthorities) == 0 {
+		return nil, false
+	}
+	return repo.UpstreamAuthorities[0], true
 }
 
+func (repo *Repository) GetUpstreamAuthorities() []UpstreamAuthority {
+	return repo.UpstreamAuthorities
+}
+
+// SetUpstreamAuthority replaces the upstream authorities with the given one.
+// Passing nil clears the upstream authorities.
 func (repo *Repository) SetUpstreamAuthority(upstreamAuthority UpstreamAuthority) {
-	repo.UpstreamAuthority = upstreamAuthority
+	repo.UpstreamAuthorities = nil
+	if upstreamAuthority != nil {
+		repo.UpstreamAuthorities = []UpstreamAuthority{upstreamAuthority}
+	}
+}
+
+func (repo *Repository) AddUpstreamAuthority(upstreamAuthority UpstreamAuthority) {
+	repo.UpstreamAuthorities = append(repo.UpstreamAuthorities, upstreamAuthority)
 }
 
 func (repo *Repository) ClearUpstreamAuthority() {
-	repo.UpstreamAuthority = nil
+	repo.UpstreamAuthorities = nil
 }
 
 func (repo *Repository) Clear() {
-	repo.UpstreamAuthority = nil
+	repo.UpstreamAuthorities = nil
 }
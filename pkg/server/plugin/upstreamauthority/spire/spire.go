@@ -25,6 +25,10 @@ const (
 	pluginName       = "spire"
 	upstreamPollFreq = 5 * time.Second
 	internalPollFreq = time.Second
+
+	// defaultWorkloadAPISocket is the default socket path of the SPIRE agent
+	// running alongside the server
+	defaultWorkloadAPISocket = "/tmp/spire-agent/public/api.sock"
 )
 
 var clk clock.Clock = clock.New()
@@ -78,6 +82,18 @@ func (m *Plugin) Configure(ctx context.Context, req *plugin.ConfigureRequest) (*
 		return nil, errors.New("global configuration is required")
 	}
 
+	if config.ServerAddr == "" {
+		return nil, errors.New("server_address is required")
+	}
+
+	if config.ServerPort == "" {
+		return nil, errors.New("server_port is required")
+	}
+
+	if config.WorkloadAPISocket == "" {
+		config.WorkloadAPISocket = defaultWorkloadAPISocket
+	}
+
 	if req.GlobalConfig.TrustDomain == "" {
 		return nil, errors.New("trust_domain is required")
 	}
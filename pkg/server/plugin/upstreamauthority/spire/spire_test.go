@@ -279,6 +279,22 @@ func TestSpirePlugin_Configure(t *testing.T) {
 			},
 			err: "global configuration is required",
 		},
+		{
+			name: "no server address",
+			req: &spi.ConfigureRequest{
+				Configuration: `server_port = "8081"`,
+				GlobalConfig:  &spi.ConfigureRequest_GlobalConfig{TrustDomain: trustDomain.String()},
+			},
+			err: "server_address is required",
+		},
+		{
+			name: "no server port",
+			req: &spi.ConfigureRequest{
+				Configuration: `server_address = "upstream-spire-server"`,
+				GlobalConfig:  &spi.ConfigureRequest_GlobalConfig{TrustDomain: trustDomain.String()},
+			},
+			err: "server_port is required",
+		},
 		{
 			name: "no trust domain",
 			req: &spi.ConfigureRequest{
@@ -313,8 +329,23 @@ func TestSpirePlugin_Configure(t *testing.T) {
 	}
 }
 
+func TestSpirePlugin_ConfigureDefaultWorkloadAPISocket(t *testing.T) {
+	m := New()
+	_, err := m.Configure(ctx, &spi.ConfigureRequest{
+		Configuration: `
+			server_address = "upstream-spire-server"
+			server_port = "8081"
+		`,
+		GlobalConfig: &spi.ConfigureRequest_GlobalConfig{TrustDomain: trustDomain.String()},
+	})
+	require.NoError(t, err)
+	require.Equal(t, defaultWorkloadAPISocket, m.config.WorkloadAPISocket)
+	require.Equal(t, "unix://"+defaultWorkloadAPISocket, m.serverClient.workloadAPISocket)
+	require.Equal(t, "upstream-spire-server:8081", m.serverClient.serverAddr)
+}
+
 func TestSpirePlugin_GetPluginInfo(t *testing.T) {
-	m, _ := newWithDefault(t, "", "")
+	m, _ := newWithDefault(t, "localhost:8081", "")
 
 	res, err := m.GetPluginInfo(ctx, &spi.GetPluginInfoRequest{})
 	require.NoError(t, err)
@@ -363,8 +394,8 @@ func TestSpirePlugin_MintX509CA(t *testing.T) {
 			getCSR: func() ([]byte, crypto.PublicKey) {
 				return csr, pubKey
 			},
-			customServerAddr: "localhost",
-			expectedErr:      `rpc error: code = Unavailable desc = connection error: desc = "transport: Error while dialing dial tcp :0`,
+			customServerAddr: "127.0.0.1:1",
+			expectedErr:      `rpc error: code = Unavailable desc = connection error: desc = "transport: Error while dialing dial tcp 127.0.0.1:1`,
 		},
 		{
 			name: "invalid scheme",
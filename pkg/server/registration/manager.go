@@ -23,6 +23,14 @@ type ManagerConfig struct {
 	Metrics telemetry.Metrics
 
 	Clock clock.Clock
+
+	// DeletedEntryGracePeriod is how long deleted entries are kept, so they
+	// can be restored, before they are purged
+	DeletedEntryGracePeriod time.Duration
+
+	// PruneInterval is how often expired and purgeable deleted entries are
+	// pruned. Defaults to five minutes.
+	PruneInterval time.Duration
 }
 
 // Manager is the manager of registrations
@@ -37,10 +45,13 @@ func NewManager(c ManagerConfig) *Manager {
 	if c.Clock == nil {
 		c.Clock = clock.New()
 	}
+	if c.PruneInterval <= 0 {
+		c.PruneInterval = _pruningCandence
+	}
 
 	return &Manager{
 		c:       c,
-		log:     c.Log.WithField(telemetry.RetryInterval, _pruningCandence),
+		log:     c.Log.WithField(telemetry.RetryInterval, c.PruneInterval),
 		metrics: c.Metrics,
 	}
 }
@@ -51,7 +62,7 @@ func (m *Manager) Run(ctx context.Context) error {
 }
 
 func (m *Manager) pruneEvery(ctx context.Context) error {
-	ticker := m.c.Clock.Ticker(_pruningCandence)
+	ticker := m.c.Clock.Ticker(m.c.PruneInterval)
 	defer ticker.Stop()
 
 	for {
@@ -71,8 +82,12 @@ func (m *Manager) prune(ctx context.Context) (err error) {
 	counter := telemetry_server.StartRegistrationManagerPruneEntryCall(m.c.Metrics)
 	defer counter.Done(&err)
 
-	_, err = m.c.DataStore.PruneRegistrationEntries(ctx, &datastore.PruneRegistrationEntriesRequest{
-		ExpiresBefore: m.c.Clock.Now().Unix(),
-	})
-	return err
+	now := m.c.Clock.Now()
+	if _, err := m.c.DataStore.PruneRegistrationEntries(ctx, &datastore.PruneRegistrationEntriesRequest{
+		ExpiresBefore: now.Unix(),
+	}); err != nil {
+		return err
+	}
+
+	return m.c.DataStore.PruneDeletedRegistrationEntries(ctx, now.Add(-m.c.DeletedEntryGracePeriod))
 }
@@ -15,6 +15,8 @@ import (
 	"github.com/spiffe/spire/test/spiretest"
 )
 
+const deletedEntryGracePeriod = time.Hour
+
 func TestManager(t *testing.T) {
 	spiretest.Run(t, new(ManagerSuite))
 }
@@ -126,12 +128,68 @@ func (s *ManagerSuite) TestPruning() {
 	s.Empty(listResp.Entries)
 }
 
+func (s *ManagerSuite) TestPruningDeletedEntries() {
+	done := s.setupAndRunManager()
+	defer done()
+
+	entry, err := s.ds.CreateRegistrationEntry(context.Background(), &common.RegistrationEntry{
+		ParentId:  "spiffe://test.test/testA",
+		SpiffeId:  "spiffe://test.test/testA/test1",
+		Selectors: []*common.Selector{{Type: "type", Value: "value"}},
+	})
+	s.Require().NoError(err)
+	_, err = s.ds.SoftDeleteRegistrationEntry(context.Background(), entry.EntryId)
+	s.Require().NoError(err)
+
+	// still within the grace period
+	s.NoError(s.m.prune(context.Background()))
+	deleted, err := s.ds.ListDeletedRegistrationEntries(context.Background())
+	s.NoError(err)
+	s.Len(deleted, 1)
+
+	// purged once the grace period elapses
+	s.clock.Add(deletedEntryGracePeriod + time.Minute)
+	s.NoError(s.m.prune(context.Background()))
+	deleted, err = s.ds.ListDeletedRegistrationEntries(context.Background())
+	s.NoError(err)
+	s.Empty(deleted)
+}
+
+func (s *ManagerSuite) TestPruningOnConfiguredInterval() {
+	done := s.setupAndRunManagerWithPruneInterval(time.Minute)
+	defer done()
+
+	entry, err := s.ds.CreateRegistrationEntry(context.Background(), &common.RegistrationEntry{
+		ParentId:    "spiffe://test.test/testA",
+		SpiffeId:    "spiffe://test.test/testA/test1",
+		Selectors:   []*common.Selector{{Type: "type", Value: "value"}},
+		EntryExpiry: s.clock.Now().Add(-time.Second).Unix(),
+	})
+	s.Require().NoError(err)
+
+	// the expired entry is deleted on the first tick of the configured
+	// interval, well before the default interval
+	s.clock.WaitForTicker(time.Minute, "waiting for the pruning ticker")
+	s.clock.Add(time.Minute)
+	s.Require().Eventually(func() bool {
+		fetched, err := s.ds.FetchRegistrationEntry(context.Background(), entry.EntryId)
+		return err == nil && fetched == nil
+	}, time.Minute, 10*time.Millisecond, "expired entry was not pruned")
+}
+
 func (s *ManagerSuite) setupAndRunManager() func() {
+	return s.setupAndRunManagerWithPruneInterval(0)
+}
+
+func (s *ManagerSuite) setupAndRunManagerWithPruneInterval(pruneInterval time.Duration) func() {
 	s.m = NewManager(ManagerConfig{
 		Clock:     s.clock,
 		DataStore: s.ds,
 		Log:       s.log,
 		Metrics:   s.metrics,
+
+		DeletedEntryGracePeriod: deletedEntryGracePeriod,
+		PruneInterval:           pruneInterval,
 	})
 
 	ctx, cancel := context.WithCancel(context.Background())
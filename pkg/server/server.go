@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
@@ -18,6 +19,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/hostservice/metricsservice"
 	"github.com/spiffe/spire/pkg/common/profiling"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/tracing"
 	"github.com/spiffe/spire/pkg/common/uptime"
 	"github.com/spiffe/spire/pkg/common/util"
 	bundle_client "github.com/spiffe/spire/pkg/server/bundle/client"
@@ -89,6 +91,19 @@ func (s *Server) run(ctx context.Context) (err error) {
 	telemetry.EmitVersion(metrics)
 	uptime.ReportMetrics(ctx, metrics)
 
+	if s.config.Tracing != nil {
+		shutdownTracing, err := tracing.Setup(ctx, *s.config.Tracing)
+		if err != nil {
+			return fmt.Errorf("failed to set up tracing: %w", err)
+		}
+		defer func() {
+			if err := shutdownTracing(context.Background()); err != nil {
+				s.config.Log.WithError(err).Warn("Failed to shut down tracing")
+			}
+		}()
+		s.config.Log.WithField(telemetry.Address, s.config.Tracing.OTLPEndpoint).Info("Exporting traces")
+	}
+
 	// Create the identity provider host service. It will not be functional
 	// until the call to SetDeps() below. There is some tricky initialization
 	// stuff going on since the identity provider host service requires plugins
@@ -102,14 +117,14 @@ func (s *Server) run(ctx context.Context) (err error) {
 	// until the call to SetDeps() below.
 	agentStore := agentstore.New()
 
-	cat, err := s.loadCatalog(ctx, metrics, identityProvider, agentStore, metricsService)
+	healthChecker := health.NewChecker(s.config.HealthChecks, s.config.Log)
+
+	cat, err := s.loadCatalog(ctx, metrics, healthChecker, identityProvider, agentStore, metricsService)
 	if err != nil {
 		return err
 	}
 	defer cat.Close()
 
-	healthChecker := health.NewChecker(s.config.HealthChecks, s.config.Log)
-
 	err = s.validateTrustDomain(ctx, cat.GetDataStore())
 	if err != nil {
 		return err
@@ -156,7 +171,7 @@ func (s *Server) run(ctx context.Context) (err error) {
 		return fmt.Errorf("failed setting AgentStore deps: %v", err)
 	}
 
-	bundleManager := s.newBundleManager(cat, metrics)
+	bundleManager := s.newBundleManager(cat, metrics, svidRotator)
 
 	registrationManager := s.newRegistrationManager(cat, metrics)
 
@@ -236,11 +251,12 @@ func (s *Server) setupProfiling(ctx context.Context) (stop func()) {
 	}
 }
 
-func (s *Server) loadCatalog(ctx context.Context, metrics telemetry.Metrics, identityProvider identityproviderv0.IdentityProviderServer, agentStore agentstorev0.AgentStoreServer,
+func (s *Server) loadCatalog(ctx context.Context, metrics telemetry.Metrics, healthChecker health.Checker, identityProvider identityproviderv0.IdentityProviderServer, agentStore agentstorev0.AgentStoreServer,
 	metricsService metricsv0.MetricsServiceServer) (*catalog.Repository, error) {
 	return catalog.Load(ctx, catalog.Config{
 		Log:              s.config.Log.WithField(telemetry.SubsystemName, telemetry.Catalog),
 		Metrics:          metrics,
+		HealthChecker:    healthChecker,
 		TrustDomain:      s.config.TrustDomain,
 		PluginConfig:     s.config.PluginConfigs,
 		IdentityProvider: identityProvider,
@@ -251,13 +267,18 @@ func (s *Server) loadCatalog(ctx context.Context, metrics telemetry.Metrics, ide
 
 func (s *Server) newCA(metrics telemetry.Metrics, healthChecker health.Checker) *ca.CA {
 	return ca.NewCA(ca.Config{
-		Log:           s.config.Log.WithField(telemetry.SubsystemName, telemetry.CA),
-		Metrics:       metrics,
-		X509SVIDTTL:   s.config.SVIDTTL,
-		JWTIssuer:     s.config.JWTIssuer,
-		TrustDomain:   s.config.TrustDomain,
-		CASubject:     s.config.CASubject,
-		HealthChecker: healthChecker,
+		Log:                   s.config.Log.WithField(telemetry.SubsystemName, telemetry.CA),
+		Metrics:               metrics,
+		X509SVIDTTL:           s.config.SVIDTTL,
+		JWTSVIDTTL:            s.config.JWTSVIDTTL,
+		JWTIssuer:             s.config.JWTIssuer,
+		TrustDomain:           s.config.TrustDomain,
+		CASubject:             s.config.CASubject,
+		HealthChecker:         healthChecker,
+		SecondaryTrustDomain:  s.config.SecondaryTrustDomain,
+		SerialNumberGenerator: s.config.SerialNumberGenerator,
+		FIPSMode:              s.config.FIPSMode,
+		JWTKeyTrustDomains:    s.config.JWTKeyTrustDomains,
 	})
 }
 
@@ -274,6 +295,15 @@ func (s *Server) newCAManager(ctx context.Context, cat catalog.Catalog, metrics
 		X509CAKeyType: s.config.CAKeyType,
 		JWTKeyType:    s.config.JWTKeyType,
 		HealthChecker: healthChecker,
+
+		JWTKeyIDThumbprint:     s.config.JWTKeyIDThumbprint,
+		JWTKeyNoticePeriod:     s.config.JWTKeyNoticePeriod,
+		UpstreamAuthorityOrder: s.config.UpstreamAuthorityOrder,
+		NotifierTimeout:        s.config.NotifierTimeout,
+		FIPSMode:               s.config.FIPSMode,
+		JWTKeyTrustDomains:     s.config.JWTKeyTrustDomains,
+
+		UpstreamAuthorityValidation: s.config.UpstreamAuthorityValidation,
 	})
 	if err := caManager.Initialize(ctx); err != nil {
 		return nil, err
@@ -286,6 +316,9 @@ func (s *Server) newRegistrationManager(cat catalog.Catalog, metrics telemetry.M
 		DataStore: cat.GetDataStore(),
 		Log:       s.config.Log.WithField(telemetry.SubsystemName, telemetry.RegistrationManager),
 		Metrics:   metrics,
+
+		DeletedEntryGracePeriod: s.config.DeletedEntryGracePeriod,
+		PruneInterval:           s.config.EntryPruneInterval,
 	})
 	return registrationManager
 }
@@ -303,21 +336,39 @@ func (s *Server) newSVIDRotator(ctx context.Context, serverCA ca.ServerCA, metri
 	return svidRotator, nil
 }
 
-func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog, svidObserver svid.Observer, serverCA ca.ServerCA, metrics telemetry.Metrics, caManager *ca.Manager) (endpoints.Server, error) {
+func (s *Server) newEndpointsServer(ctx context.Context, cat catalog.Catalog, svidObserver svid.Observer, serverCA ca.ServerCA, metrics telemetry.Metrics, caManager *ca.Manager) (endpoints.Server, error) {
 	config := endpoints.Config{
 		TCPAddr:             s.config.BindAddress,
 		UDSAddr:             s.config.BindUDSAddress,
 		SVIDObserver:        svidObserver,
 		TrustDomain:         s.config.TrustDomain,
-		Catalog:             catalog,
+		Catalog:             cat,
 		ServerCA:            serverCA,
 		Log:                 s.config.Log.WithField(telemetry.SubsystemName, telemetry.Endpoints),
+		AuditLog:            s.config.AuditLog,
 		Metrics:             metrics,
 		Manager:             caManager,
 		RateLimit:           s.config.RateLimit,
+		TLSPolicy:           s.config.TLSPolicy,
 		Uptime:              uptime.Uptime,
 		Clock:               clock.New(),
 		CacheReloadInterval: s.config.CacheReloadInterval,
+		JWTKeyTrustDomains:  s.config.JWTKeyTrustDomains,
+
+		MinNodeSelectors:            s.config.MinNodeSelectors,
+		RejectBelowMinNodeSelectors: s.config.RejectBelowMinNodeSelectors,
+		MaxNodeSelectors:            s.config.MaxNodeSelectors,
+		MaxAttestationPayloadSize:   s.config.MaxAttestationPayloadSize,
+		MaxConcurrentAttestations:   s.config.MaxConcurrentAttestations,
+		NodeSelectorMergePolicy:     s.config.NodeSelectorMergePolicy,
+		SPIFFEIDCollisionPolicy:     s.config.SPIFFEIDCollisionPolicy,
+		DuplicateSelectorPolicy:     s.config.DuplicateSelectorPolicy,
+		DeletedEntryGracePeriod:     s.config.DeletedEntryGracePeriod,
+		KnownSelectorTypes:          catalog.KnownSelectorTypes(s.config.PluginConfigs),
+		UnknownSelectorTypePolicy:   s.config.UnknownSelectorTypePolicy,
+		SVIDIssuanceHook:            s.config.SVIDIssuanceHook,
+		AdminScopes:                 s.config.AdminScopes,
+		GRPCMessageSizeLimits:       s.config.GRPCMessageSizeLimits,
 	}
 	if s.config.Federation.BundleEndpoint != nil {
 		config.BundleEndpoint.Address = s.config.Federation.BundleEndpoint.Address
@@ -326,12 +377,22 @@ func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog
 	return endpoints.New(ctx, config)
 }
 
-func (s *Server) newBundleManager(cat catalog.Catalog, metrics telemetry.Metrics) *bundle_client.Manager {
+func (s *Server) newBundleManager(cat catalog.Catalog, metrics telemetry.Metrics, svidObserver svid.Observer) *bundle_client.Manager {
 	return bundle_client.NewManager(bundle_client.ManagerConfig{
 		Log:          s.config.Log.WithField(telemetry.SubsystemName, "bundle_client"),
 		Metrics:      metrics,
 		DataStore:    cat.GetDataStore(),
 		TrustDomains: s.config.Federation.FederatesWith,
+		ServerSVID: func() (*tls.Certificate, error) {
+			state := svidObserver.State()
+			cert := &tls.Certificate{
+				PrivateKey: state.Key,
+			}
+			for _, c := range state.SVID {
+				cert.Certificate = append(cert.Certificate, c.Raw)
+			}
+			return cert, nil
+		},
 	})
 }
 
@@ -0,0 +1,107 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package attestation
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// AttestationClient is the client API for Attestation service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AttestationClient interface {
+	// Reattest performs node attestation again, so the server attests the
+	// node and resolves its selectors again, and the agent gets a new SVID.
+	// It returns once the reattestation has finished.
+	Reattest(ctx context.Context, in *ReattestRequest, opts ...grpc.CallOption) (*ReattestResponse, error)
+}
+
+type attestationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAttestationClient(cc grpc.ClientConnInterface) AttestationClient {
+	return &attestationClient{cc}
+}
+
+func (c *attestationClient) Reattest(ctx context.Context, in *ReattestRequest, opts ...grpc.CallOption) (*ReattestResponse, error) {
+	out := new(ReattestResponse)
+	err := c.cc.Invoke(ctx, "/spire.private.agent.attestation.Attestation/Reattest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AttestationServer is the server API for Attestation service.
+// All implementations must embed UnimplementedAttestationServer
+// for forward compatibility
+type AttestationServer interface {
+	// Reattest performs node attestation again, so the server attests the
+	// node and resolves its selectors again, and the agent gets a new SVID.
+	// It returns once the reattestation has finished.
+	Reattest(context.Context, *ReattestRequest) (*ReattestResponse, error)
+	mustEmbedUnimplementedAttestationServer()
+}
+
+// UnimplementedAttestationServer must be embedded to have forward compatible implementations.
+type UnimplementedAttestationServer struct {
+}
+
+func (UnimplementedAttestationServer) Reattest(context.Context, *ReattestRequest) (*ReattestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reattest not implemented")
+}
+func (UnimplementedAttestationServer) mustEmbedUnimplementedAttestationServer() {}
+
+// UnsafeAttestationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AttestationServer will
+// result in compilation errors.
+type UnsafeAttestationServer interface {
+	mustEmbedUnimplementedAttestationServer()
+}
+
+func RegisterAttestationServer(s grpc.ServiceRegistrar, srv AttestationServer) {
+	s.RegisterService(&Attestation_ServiceDesc, srv)
+}
+
+func _Attestation_Reattest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReattestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttestationServer).Reattest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.private.agent.attestation.Attestation/Reattest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttestationServer).Reattest(ctx, req.(*ReattestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Attestation_ServiceDesc is the grpc.ServiceDesc for Attestation service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Attestation_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.private.agent.attestation.Attestation",
+	HandlerType: (*AttestationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Reattest",
+			Handler:    _Attestation_Reattest_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "private/agent/attestation/attestation.proto",
+}
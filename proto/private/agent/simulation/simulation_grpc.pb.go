@@ -0,0 +1,109 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package simulation
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// SimulationClient is the client API for Simulation service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SimulationClient interface {
+	// SimulateAttestation matches the given selectors against the
+	// registration entries cached by the agent, the same way the Workload API
+	// does for an attested workload. Nothing is issued and no private key is
+	// returned.
+	SimulateAttestation(ctx context.Context, in *SimulateAttestationRequest, opts ...grpc.CallOption) (*SimulateAttestationResponse, error)
+}
+
+type simulationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSimulationClient(cc grpc.ClientConnInterface) SimulationClient {
+	return &simulationClient{cc}
+}
+
+func (c *simulationClient) SimulateAttestation(ctx context.Context, in *SimulateAttestationRequest, opts ...grpc.CallOption) (*SimulateAttestationResponse, error) {
+	out := new(SimulateAttestationResponse)
+	err := c.cc.Invoke(ctx, "/spire.private.agent.simulation.Simulation/SimulateAttestation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SimulationServer is the server API for Simulation service.
+// All implementations must embed UnimplementedSimulationServer
+// for forward compatibility
+type SimulationServer interface {
+	// SimulateAttestation matches the given selectors against the
+	// registration entries cached by the agent, the same way the Workload API
+	// does for an attested workload. Nothing is issued and no private key is
+	// returned.
+	SimulateAttestation(context.Context, *SimulateAttestationRequest) (*SimulateAttestationResponse, error)
+	mustEmbedUnimplementedSimulationServer()
+}
+
+// UnimplementedSimulationServer must be embedded to have forward compatible implementations.
+type UnimplementedSimulationServer struct {
+}
+
+func (UnimplementedSimulationServer) SimulateAttestation(context.Context, *SimulateAttestationRequest) (*SimulateAttestationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SimulateAttestation not implemented")
+}
+func (UnimplementedSimulationServer) mustEmbedUnimplementedSimulationServer() {}
+
+// UnsafeSimulationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SimulationServer will
+// result in compilation errors.
+type UnsafeSimulationServer interface {
+	mustEmbedUnimplementedSimulationServer()
+}
+
+func RegisterSimulationServer(s grpc.ServiceRegistrar, srv SimulationServer) {
+	s.RegisterService(&Simulation_ServiceDesc, srv)
+}
+
+func _Simulation_SimulateAttestation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimulateAttestationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulationServer).SimulateAttestation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.private.agent.simulation.Simulation/SimulateAttestation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulationServer).SimulateAttestation(ctx, req.(*SimulateAttestationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Simulation_ServiceDesc is the grpc.ServiceDesc for Simulation service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Simulation_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.private.agent.simulation.Simulation",
+	HandlerType: (*SimulationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SimulateAttestation",
+			Handler:    _Simulation_SimulateAttestation_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "private/agent/simulation/simulation.proto",
+}
@@ -0,0 +1,367 @@
+// The CAStatus API lets operators see which X.509 CAs the server is signing
+// with, which one is prepared to replace it, and when the rotation happens,
+// e.g. to coordinate the distribution of the trust bundle downstream.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.26.0
+// 	protoc        v3.14.0
+// source: private/server/castatus/castatus.proto
+
+package castatus
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetX509CAStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetX509CAStatusRequest) Reset() {
+	*x = GetX509CAStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_private_server_castatus_castatus_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetX509CAStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetX509CAStatusRequest) ProtoMessage() {}
+
+func (x *GetX509CAStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_castatus_castatus_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetX509CAStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetX509CAStatusRequest) Descriptor() ([]byte, []int) {
+	return file_private_server_castatus_castatus_proto_rawDescGZIP(), []int{0}
+}
+
+type GetX509CAStatusResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The X.509 CA the server signs with. Unset if there is none yet.
+	Active *X509CA `protobuf:"bytes,1,opt,name=active,proto3" json:"active,omitempty"`
+	// The X.509 CA prepared to replace the active one. Unset if it has not
+	// been prepared yet.
+	Next *X509CA `protobuf:"bytes,2,opt,name=next,proto3" json:"next,omitempty"`
+	// When the next X.509 CA is prepared and added to the trust bundle
+	// (seconds since Unix epoch). Zero if it has been prepared already or
+	// there is no active X.509 CA.
+	PrepareNextAt int64 `protobuf:"varint,3,opt,name=prepare_next_at,json=prepareNextAt,proto3" json:"prepare_next_at,omitempty"`
+	// When the next X.509 CA replaces the active one (seconds since Unix
+	// epoch). Zero if there is no active X.509 CA.
+	RotateAt int64 `protobuf:"varint,4,opt,name=rotate_at,json=rotateAt,proto3" json:"rotate_at,omitempty"`
+}
+
+func (x *GetX509CAStatusResponse) Reset() {
+	*x = GetX509CAStatusResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_private_server_castatus_castatus_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetX509CAStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetX509CAStatusResponse) ProtoMessage() {}
+
+func (x *GetX509CAStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_castatus_castatus_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetX509CAStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetX509CAStatusResponse) Descriptor() ([]byte, []int) {
+	return file_private_server_castatus_castatus_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetX509CAStatusResponse) GetActive() *X509CA {
+	if x != nil {
+		return x.Active
+	}
+	return nil
+}
+
+func (x *GetX509CAStatusResponse) GetNext() *X509CA {
+	if x != nil {
+		return x.Next
+	}
+	return nil
+}
+
+func (x *GetX509CAStatusResponse) GetPrepareNextAt() int64 {
+	if x != nil {
+		return x.PrepareNextAt
+	}
+	return 0
+}
+
+func (x *GetX509CAStatusResponse) GetRotateAt() int64 {
+	if x != nil {
+		return x.RotateAt
+	}
+	return 0
+}
+
+type X509CA struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The ID of the slot the X.509 CA is kept in, i.e. "A" or "B".
+	SlotId string `protobuf:"bytes,1,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	// The ASN.1 DER encoded CA certificate, followed by the intermediates,
+	// if any, that chain it back to the upstream authority.
+	CaCertChain [][]byte `protobuf:"bytes,2,rep,name=ca_cert_chain,json=caCertChain,proto3" json:"ca_cert_chain,omitempty"`
+	// When the X.509 CA was prepared (seconds since Unix epoch).
+	IssuedAt int64 `protobuf:"varint,3,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	// The validity window of the CA certificate (seconds since Unix epoch).
+	NotBefore int64 `protobuf:"varint,4,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	NotAfter  int64 `protobuf:"varint,5,opt,name=not_after,json=notAfter,proto3" json:"not_after,omitempty"`
+}
+
+func (x *X509CA) Reset() {
+	*x = X509CA{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_private_server_castatus_castatus_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *X509CA) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*X509CA) ProtoMessage() {}
+
+func (x *X509CA) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_castatus_castatus_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use X509CA.ProtoReflect.Descriptor instead.
+func (*X509CA) Descriptor() ([]byte, []int) {
+	return file_private_server_castatus_castatus_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *X509CA) GetSlotId() string {
+	if x != nil {
+		return x.SlotId
+	}
+	return ""
+}
+
+func (x *X509CA) GetCaCertChain() [][]byte {
+	if x != nil {
+		return x.CaCertChain
+	}
+	return nil
+}
+
+func (x *X509CA) GetIssuedAt() int64 {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return 0
+}
+
+func (x *X509CA) GetNotBefore() int64 {
+	if x != nil {
+		return x.NotBefore
+	}
+	return 0
+}
+
+func (x *X509CA) GetNotAfter() int64 {
+	if x != nil {
+		return x.NotAfter
+	}
+	return 0
+}
+
+var File_private_server_castatus_castatus_proto protoreflect.FileDescriptor
+
+var file_private_server_castatus_castatus_proto_rawDesc = []byte{
+	0x0a, 0x26, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x2f, 0x63, 0x61, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2f, 0x63, 0x61, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x1d, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e,
+	0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x63,
+	0x61, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x18, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x58, 0x35,
+	0x30, 0x39, 0x43, 0x41, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x22, 0xd8, 0x01, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x58, 0x35, 0x30, 0x39, 0x43, 0x41, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a,
+	0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e,
+	0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x2e, 0x63, 0x61, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x58, 0x35,
+	0x30, 0x39, 0x43, 0x41, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x39, 0x0a, 0x04,
+	0x6e, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73, 0x70, 0x69,
+	0x72, 0x65, 0x2e, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x2e, 0x63, 0x61, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x58, 0x35, 0x30, 0x39, 0x43,
+	0x41, 0x52, 0x04, 0x6e, 0x65, 0x78, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x70, 0x72, 0x65, 0x70, 0x61,
+	0x72, 0x65, 0x5f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0d, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x4e, 0x65, 0x78, 0x74, 0x41, 0x74, 0x12,
+	0x1b, 0x0a, 0x09, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x08, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x41, 0x74, 0x22, 0x9e, 0x01, 0x0a,
+	0x06, 0x58, 0x35, 0x30, 0x39, 0x43, 0x41, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x6c, 0x6f, 0x74, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6c, 0x6f, 0x74, 0x49, 0x64,
+	0x12, 0x22, 0x0a, 0x0d, 0x63, 0x61, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x5f, 0x63, 0x68, 0x61, 0x69,
+	0x6e, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0b, 0x63, 0x61, 0x43, 0x65, 0x72, 0x74, 0x43,
+	0x68, 0x61, 0x69, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x73, 0x73, 0x75, 0x65, 0x64, 0x5f, 0x61,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x69, 0x73, 0x73, 0x75, 0x65, 0x64, 0x41,
+	0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x6f, 0x74, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6e, 0x6f, 0x74, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65,
+	0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x6f, 0x74, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x08, 0x6e, 0x6f, 0x74, 0x41, 0x66, 0x74, 0x65, 0x72, 0x32, 0x8d, 0x01,
+	0x0a, 0x08, 0x43, 0x41, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x80, 0x01, 0x0a, 0x0f, 0x47,
+	0x65, 0x74, 0x58, 0x35, 0x30, 0x39, 0x43, 0x41, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x35,
+	0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x2e, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x63, 0x61, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x47,
+	0x65, 0x74, 0x58, 0x35, 0x30, 0x39, 0x43, 0x41, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x36, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x70, 0x72,
+	0x69, 0x76, 0x61, 0x74, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x63, 0x61, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x47, 0x65, 0x74, 0x58, 0x35, 0x30, 0x39, 0x43, 0x41, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x37, 0x5a,
+	0x35, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x70, 0x69, 0x66,
+	0x66, 0x65, 0x2f, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70,
+	0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x63, 0x61,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_private_server_castatus_castatus_proto_rawDescOnce sync.Once
+	file_private_server_castatus_castatus_proto_rawDescData = file_private_server_castatus_castatus_proto_rawDesc
+)
+
+func file_private_server_castatus_castatus_proto_rawDescGZIP() []byte {
+	file_private_server_castatus_castatus_proto_rawDescOnce.Do(func() {
+		file_private_server_castatus_castatus_proto_rawDescData = protoimpl.X.CompressGZIP(file_private_server_castatus_castatus_proto_rawDescData)
+	})
+	return file_private_server_castatus_castatus_proto_rawDescData
+}
+
+var file_private_server_castatus_castatus_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_private_server_castatus_castatus_proto_goTypes = []interface{}{
+	(*GetX509CAStatusRequest)(nil),  // 0: spire.private.server.castatus.GetX509CAStatusRequest
+	(*GetX509CAStatusResponse)(nil), // 1: spire.private.server.castatus.GetX509CAStatusResponse
+	(*X509CA)(nil),                  // 2: spire.private.server.castatus.X509CA
+}
+var file_private_server_castatus_castatus_proto_depIdxs = []int32{
+	2, // 0: spire.private.server.castatus.GetX509CAStatusResponse.active:type_name -> spire.private.server.castatus.X509CA
+	2, // 1: spire.private.server.castatus.GetX509CAStatusResponse.next:type_name -> spire.private.server.castatus.X509CA
+	0, // 2: spire.private.server.castatus.CAStatus.GetX509CAStatus:input_type -> spire.private.server.castatus.GetX509CAStatusRequest
+	1, // 3: spire.private.server.castatus.CAStatus.GetX509CAStatus:output_type -> spire.private.server.castatus.GetX509CAStatusResponse
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_private_server_castatus_castatus_proto_init() }
+func file_private_server_castatus_castatus_proto_init() {
+	if File_private_server_castatus_castatus_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_private_server_castatus_castatus_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetX509CAStatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_private_server_castatus_castatus_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetX509CAStatusResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_private_server_castatus_castatus_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*X509CA); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_private_server_castatus_castatus_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_private_server_castatus_castatus_proto_goTypes,
+		DependencyIndexes: file_private_server_castatus_castatus_proto_depIdxs,
+		MessageInfos:      file_private_server_castatus_castatus_proto_msgTypes,
+	}.Build()
+	File_private_server_castatus_castatus_proto = out.File
+	file_private_server_castatus_castatus_proto_rawDesc = nil
+	file_private_server_castatus_castatus_proto_goTypes = nil
+	file_private_server_castatus_castatus_proto_depIdxs = nil
+}
@@ -0,0 +1,105 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package castatus
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// CAStatusClient is the client API for CAStatus service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CAStatusClient interface {
+	// GetX509CAStatus returns the active and next X.509 CAs of the server,
+	// and when the next one is prepared and activated.
+	GetX509CAStatus(ctx context.Context, in *GetX509CAStatusRequest, opts ...grpc.CallOption) (*GetX509CAStatusResponse, error)
+}
+
+type cAStatusClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCAStatusClient(cc grpc.ClientConnInterface) CAStatusClient {
+	return &cAStatusClient{cc}
+}
+
+func (c *cAStatusClient) GetX509CAStatus(ctx context.Context, in *GetX509CAStatusRequest, opts ...grpc.CallOption) (*GetX509CAStatusResponse, error) {
+	out := new(GetX509CAStatusResponse)
+	err := c.cc.Invoke(ctx, "/spire.private.server.castatus.CAStatus/GetX509CAStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CAStatusServer is the server API for CAStatus service.
+// All implementations must embed UnimplementedCAStatusServer
+// for forward compatibility
+type CAStatusServer interface {
+	// GetX509CAStatus returns the active and next X.509 CAs of the server,
+	// and when the next one is prepared and activated.
+	GetX509CAStatus(context.Context, *GetX509CAStatusRequest) (*GetX509CAStatusResponse, error)
+	mustEmbedUnimplementedCAStatusServer()
+}
+
+// UnimplementedCAStatusServer must be embedded to have forward compatible implementations.
+type UnimplementedCAStatusServer struct {
+}
+
+func (UnimplementedCAStatusServer) GetX509CAStatus(context.Context, *GetX509CAStatusRequest) (*GetX509CAStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetX509CAStatus not implemented")
+}
+func (UnimplementedCAStatusServer) mustEmbedUnimplementedCAStatusServer() {}
+
+// UnsafeCAStatusServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CAStatusServer will
+// result in compilation errors.
+type UnsafeCAStatusServer interface {
+	mustEmbedUnimplementedCAStatusServer()
+}
+
+func RegisterCAStatusServer(s grpc.ServiceRegistrar, srv CAStatusServer) {
+	s.RegisterService(&CAStatus_ServiceDesc, srv)
+}
+
+func _CAStatus_GetX509CAStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetX509CAStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CAStatusServer).GetX509CAStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.private.server.castatus.CAStatus/GetX509CAStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CAStatusServer).GetX509CAStatus(ctx, req.(*GetX509CAStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CAStatus_ServiceDesc is the grpc.ServiceDesc for CAStatus service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CAStatus_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.private.server.castatus.CAStatus",
+	HandlerType: (*CAStatusServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetX509CAStatus",
+			Handler:    _CAStatus_GetX509CAStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "private/server/castatus/castatus.proto",
+}
@@ -0,0 +1,145 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package deletedentry
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// DeletedEntryClient is the client API for DeletedEntry service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DeletedEntryClient interface {
+	// ListDeletedEntries lists the deleted entries that have not been purged
+	// yet, oldest first.
+	ListDeletedEntries(ctx context.Context, in *ListDeletedEntriesRequest, opts ...grpc.CallOption) (*ListDeletedEntriesResponse, error)
+	// RestoreEntry restores a deleted entry, with the same entry ID, if it
+	// has not been purged yet.
+	RestoreEntry(ctx context.Context, in *RestoreEntryRequest, opts ...grpc.CallOption) (*RestoreEntryResponse, error)
+}
+
+type deletedEntryClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDeletedEntryClient(cc grpc.ClientConnInterface) DeletedEntryClient {
+	return &deletedEntryClient{cc}
+}
+
+func (c *deletedEntryClient) ListDeletedEntries(ctx context.Context, in *ListDeletedEntriesRequest, opts ...grpc.CallOption) (*ListDeletedEntriesResponse, error) {
+	out := new(ListDeletedEntriesResponse)
+	err := c.cc.Invoke(ctx, "/spire.private.server.deletedentry.DeletedEntry/ListDeletedEntries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deletedEntryClient) RestoreEntry(ctx context.Context, in *RestoreEntryRequest, opts ...grpc.CallOption) (*RestoreEntryResponse, error) {
+	out := new(RestoreEntryResponse)
+	err := c.cc.Invoke(ctx, "/spire.private.server.deletedentry.DeletedEntry/RestoreEntry", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeletedEntryServer is the server API for DeletedEntry service.
+// All implementations must embed UnimplementedDeletedEntryServer
+// for forward compatibility
+type DeletedEntryServer interface {
+	// ListDeletedEntries lists the deleted entries that have not been purged
+	// yet, oldest first.
+	ListDeletedEntries(context.Context, *ListDeletedEntriesRequest) (*ListDeletedEntriesResponse, error)
+	// RestoreEntry restores a deleted entry, with the same entry ID, if it
+	// has not been purged yet.
+	RestoreEntry(context.Context, *RestoreEntryRequest) (*RestoreEntryResponse, error)
+	mustEmbedUnimplementedDeletedEntryServer()
+}
+
+// UnimplementedDeletedEntryServer must be embedded to have forward compatible implementations.
+type UnimplementedDeletedEntryServer struct {
+}
+
+func (UnimplementedDeletedEntryServer) ListDeletedEntries(context.Context, *ListDeletedEntriesRequest) (*ListDeletedEntriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeletedEntries not implemented")
+}
+func (UnimplementedDeletedEntryServer) RestoreEntry(context.Context, *RestoreEntryRequest) (*RestoreEntryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreEntry not implemented")
+}
+func (UnimplementedDeletedEntryServer) mustEmbedUnimplementedDeletedEntryServer() {}
+
+// UnsafeDeletedEntryServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DeletedEntryServer will
+// result in compilation errors.
+type UnsafeDeletedEntryServer interface {
+	mustEmbedUnimplementedDeletedEntryServer()
+}
+
+func RegisterDeletedEntryServer(s grpc.ServiceRegistrar, srv DeletedEntryServer) {
+	s.RegisterService(&DeletedEntry_ServiceDesc, srv)
+}
+
+func _DeletedEntry_ListDeletedEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeletedEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeletedEntryServer).ListDeletedEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.private.server.deletedentry.DeletedEntry/ListDeletedEntries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeletedEntryServer).ListDeletedEntries(ctx, req.(*ListDeletedEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeletedEntry_RestoreEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeletedEntryServer).RestoreEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.private.server.deletedentry.DeletedEntry/RestoreEntry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeletedEntryServer).RestoreEntry(ctx, req.(*RestoreEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DeletedEntry_ServiceDesc is the grpc.ServiceDesc for DeletedEntry service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DeletedEntry_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.private.server.deletedentry.DeletedEntry",
+	HandlerType: (*DeletedEntryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDeletedEntries",
+			Handler:    _DeletedEntry_ListDeletedEntries_Handler,
+		},
+		{
+			MethodName: "RestoreEntry",
+			Handler:    _DeletedEntry_RestoreEntry_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "private/server/deletedentry/deletedentry.proto",
+}
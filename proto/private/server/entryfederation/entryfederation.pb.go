@@ -0,0 +1,351 @@
+// The EntryFederation API lets operators add a federated trust domain to, or
+// remove it from, many registration entries at once, e.g. all the entries of
+// a namespace, instead of updating the entries one by one.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.26.0
+// 	protoc        v3.14.0
+// source: private/server/entryfederation/entryfederation.proto
+
+package entryfederation
+
+import (
+	common "github.com/spiffe/spire/proto/spire/common"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type UpdateFederatesWithRequest_Operation int32
+
+const (
+	// Add the trust domain to the federated trust domains of the entries.
+	UpdateFederatesWithRequest_ADD UpdateFederatesWithRequest_Operation = 0
+	// Remove the trust domain from the federated trust domains of the
+	// entries.
+	UpdateFederatesWithRequest_REMOVE UpdateFederatesWithRequest_Operation = 1
+)
+
+// Enum value maps for UpdateFederatesWithRequest_Operation.
+var (
+	UpdateFederatesWithRequest_Operation_name = map[int32]string{
+		0: "ADD",
+		1: "REMOVE",
+	}
+	UpdateFederatesWithRequest_Operation_value = map[string]int32{
+		"ADD":    0,
+		"REMOVE": 1,
+	}
+)
+
+func (x UpdateFederatesWithRequest_Operation) Enum() *UpdateFederatesWithRequest_Operation {
+	p := new(UpdateFederatesWithRequest_Operation)
+	*p = x
+	return p
+}
+
+func (x UpdateFederatesWithRequest_Operation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UpdateFederatesWithRequest_Operation) Descriptor() protoreflect.EnumDescriptor {
+	return file_private_server_entryfederation_entryfederation_proto_enumTypes[0].Descriptor()
+}
+
+func (UpdateFederatesWithRequest_Operation) Type() protoreflect.EnumType {
+	return &file_private_server_entryfederation_entryfederation_proto_enumTypes[0]
+}
+
+func (x UpdateFederatesWithRequest_Operation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UpdateFederatesWithRequest_Operation.Descriptor instead.
+func (UpdateFederatesWithRequest_Operation) EnumDescriptor() ([]byte, []int) {
+	return file_private_server_entryfederation_entryfederation_proto_rawDescGZIP(), []int{0, 0}
+}
+
+type UpdateFederatesWithRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The selectors of the entries to update.
+	Selectors []*common.Selector `protobuf:"bytes,1,rep,name=selectors,proto3" json:"selectors,omitempty"`
+	// Whether entries whose selectors are a subset of the selectors are
+	// updated too. Otherwise, only the entries with exactly the selectors
+	// are updated.
+	MatchSubset bool `protobuf:"varint,2,opt,name=match_subset,json=matchSubset,proto3" json:"match_subset,omitempty"`
+	// The trust domain, e.g. "spiffe://example.org" or "example.org".
+	TrustDomain string `protobuf:"bytes,3,opt,name=trust_domain,json=trustDomain,proto3" json:"trust_domain,omitempty"`
+	// Whether the trust domain is added or removed.
+	Operation UpdateFederatesWithRequest_Operation `protobuf:"varint,4,opt,name=operation,proto3,enum=spire.private.server.entryfederation.UpdateFederatesWithRequest_Operation" json:"operation,omitempty"`
+}
+
+func (x *UpdateFederatesWithRequest) Reset() {
+	*x = UpdateFederatesWithRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_private_server_entryfederation_entryfederation_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateFederatesWithRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateFederatesWithRequest) ProtoMessage() {}
+
+func (x *UpdateFederatesWithRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_entryfederation_entryfederation_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateFederatesWithRequest.ProtoReflect.Descriptor instead.
+func (*UpdateFederatesWithRequest) Descriptor() ([]byte, []int) {
+	return file_private_server_entryfederation_entryfederation_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UpdateFederatesWithRequest) GetSelectors() []*common.Selector {
+	if x != nil {
+		return x.Selectors
+	}
+	return nil
+}
+
+func (x *UpdateFederatesWithRequest) GetMatchSubset() bool {
+	if x != nil {
+		return x.MatchSubset
+	}
+	return false
+}
+
+func (x *UpdateFederatesWithRequest) GetTrustDomain() string {
+	if x != nil {
+		return x.TrustDomain
+	}
+	return ""
+}
+
+func (x *UpdateFederatesWithRequest) GetOperation() UpdateFederatesWithRequest_Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return UpdateFederatesWithRequest_ADD
+}
+
+type UpdateFederatesWithResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The entries matching the selectors that were updated. Entries already
+	// federating (or not federating) with the trust domain are left as is
+	// and are not returned.
+	Entries []*common.RegistrationEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	// The number of entries matching the selectors, updated or not.
+	Matched int32 `protobuf:"varint,2,opt,name=matched,proto3" json:"matched,omitempty"`
+}
+
+func (x *UpdateFederatesWithResponse) Reset() {
+	*x = UpdateFederatesWithResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_private_server_entryfederation_entryfederation_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateFederatesWithResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateFederatesWithResponse) ProtoMessage() {}
+
+func (x *UpdateFederatesWithResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_entryfederation_entryfederation_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateFederatesWithResponse.ProtoReflect.Descriptor instead.
+func (*UpdateFederatesWithResponse) Descriptor() ([]byte, []int) {
+	return file_private_server_entryfederation_entryfederation_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UpdateFederatesWithResponse) GetEntries() []*common.RegistrationEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *UpdateFederatesWithResponse) GetMatched() int32 {
+	if x != nil {
+		return x.Matched
+	}
+	return 0
+}
+
+var File_private_server_entryfederation_entryfederation_proto protoreflect.FileDescriptor
+
+var file_private_server_entryfederation_entryfederation_proto_rawDesc = []byte{
+	0x0a, 0x34, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x2f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x66, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x66, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x24, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x70, 0x72,
+	0x69, 0x76, 0x61, 0x74, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x65, 0x6e, 0x74,
+	0x72, 0x79, 0x66, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x19, 0x73, 0x70,
+	0x69, 0x72, 0x65, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f,
+	0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa4, 0x02, 0x0a, 0x1a, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x46, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x65, 0x73, 0x57, 0x69, 0x74, 0x68, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x34, 0x0a, 0x09, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x73, 0x70, 0x69, 0x72,
+	0x65, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x52, 0x09, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x21, 0x0a, 0x0c,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x73, 0x75, 0x62, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0b, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x53, 0x75, 0x62, 0x73, 0x65, 0x74, 0x12,
+	0x21, 0x0a, 0x0c, 0x74, 0x72, 0x75, 0x73, 0x74, 0x5f, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x74, 0x72, 0x75, 0x73, 0x74, 0x44, 0x6f, 0x6d, 0x61,
+	0x69, 0x6e, 0x12, 0x68, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x4a, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x70, 0x72,
+	0x69, 0x76, 0x61, 0x74, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x65, 0x6e, 0x74,
+	0x72, 0x79, 0x66, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x46, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x65, 0x73, 0x57, 0x69, 0x74, 0x68,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x20, 0x0a, 0x09,
+	0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x44, 0x44,
+	0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x45, 0x4d, 0x4f, 0x56, 0x45, 0x10, 0x01, 0x22, 0x72,
+	0x0a, 0x1b, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x46, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x65,
+	0x73, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a,
+	0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f,
+	0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x52, 0x65,
+	0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x61, 0x74, 0x63,
+	0x68, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68,
+	0x65, 0x64, 0x32, 0xae, 0x01, 0x0a, 0x0f, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x46, 0x65, 0x64, 0x65,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x9a, 0x01, 0x0a, 0x13, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x46, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x65, 0x73, 0x57, 0x69, 0x74, 0x68, 0x12, 0x40,
+	0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x2e, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x66, 0x65, 0x64, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x46, 0x65, 0x64, 0x65,
+	0x72, 0x61, 0x74, 0x65, 0x73, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x41, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65,
+	0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x66, 0x65, 0x64,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x46, 0x65,
+	0x64, 0x65, 0x72, 0x61, 0x74, 0x65, 0x73, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x42, 0x3e, 0x5a, 0x3c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x2f, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x2f, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x2f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x66, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_private_server_entryfederation_entryfederation_proto_rawDescOnce sync.Once
+	file_private_server_entryfederation_entryfederation_proto_rawDescData = file_private_server_entryfederation_entryfederation_proto_rawDesc
+)
+
+func file_private_server_entryfederation_entryfederation_proto_rawDescGZIP() []byte {
+	file_private_server_entryfederation_entryfederation_proto_rawDescOnce.Do(func() {
+		file_private_server_entryfederation_entryfederation_proto_rawDescData = protoimpl.X.CompressGZIP(file_private_server_entryfederation_entryfederation_proto_rawDescData)
+	})
+	return file_private_server_entryfederation_entryfederation_proto_rawDescData
+}
+
+var file_private_server_entryfederation_entryfederation_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_private_server_entryfederation_entryfederation_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_private_server_entryfederation_entryfederation_proto_goTypes = []interface{}{
+	(UpdateFederatesWithRequest_Operation)(0), // 0: spire.private.server.entryfederation.UpdateFederatesWithRequest.Operation
+	(*UpdateFederatesWithRequest)(nil),        // 1: spire.private.server.entryfederation.UpdateFederatesWithRequest
+	(*UpdateFederatesWithResponse)(nil),       // 2: spire.private.server.entryfederation.UpdateFederatesWithResponse
+	(*common.Selector)(nil),                   // 3: spire.common.Selector
+	(*common.RegistrationEntry)(nil),          // 4: spire.common.RegistrationEntry
+}
+var file_private_server_entryfederation_entryfederation_proto_depIdxs = []int32{
+	3, // 0: spire.private.server.entryfederation.UpdateFederatesWithRequest.selectors:type_name -> spire.common.Selector
+	0, // 1: spire.private.server.entryfederation.UpdateFederatesWithRequest.operation:type_name -> spire.private.server.entryfederation.UpdateFederatesWithRequest.Operation
+	4, // 2: spire.private.server.entryfederation.UpdateFederatesWithResponse.entries:type_name -> spire.common.RegistrationEntry
+	1, // 3: spire.private.server.entryfederation.EntryFederation.UpdateFederatesWith:input_type -> spire.private.server.entryfederation.UpdateFederatesWithRequest
+	2, // 4: spire.private.server.entryfederation.EntryFederation.UpdateFederatesWith:output_type -> spire.private.server.entryfederation.UpdateFederatesWithResponse
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_private_server_entryfederation_entryfederation_proto_init() }
+func file_private_server_entryfederation_entryfederation_proto_init() {
+	if File_private_server_entryfederation_entryfederation_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_private_server_entryfederation_entryfederation_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateFederatesWithRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_private_server_entryfederation_entryfederation_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateFederatesWithResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_private_server_entryfederation_entryfederation_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_private_server_entryfederation_entryfederation_proto_goTypes,
+		DependencyIndexes: file_private_server_entryfederation_entryfederation_proto_depIdxs,
+		EnumInfos:         file_private_server_entryfederation_entryfederation_proto_enumTypes,
+		MessageInfos:      file_private_server_entryfederation_entryfederation_proto_msgTypes,
+	}.Build()
+	File_private_server_entryfederation_entryfederation_proto = out.File
+	file_private_server_entryfederation_entryfederation_proto_rawDesc = nil
+	file_private_server_entryfederation_entryfederation_proto_goTypes = nil
+	file_private_server_entryfederation_entryfederation_proto_depIdxs = nil
+}
@@ -0,0 +1,111 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package entryfederation
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// EntryFederationClient is the client API for EntryFederation service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EntryFederationClient interface {
+	// UpdateFederatesWith adds the trust domain to, or removes it from, the
+	// federated trust domains of the entries matching the selectors. The
+	// entries are updated in a single transaction, so either all of them
+	// are updated or none are. The trust domain must have a bundle, i.e. be
+	// federated with.
+	UpdateFederatesWith(ctx context.Context, in *UpdateFederatesWithRequest, opts ...grpc.CallOption) (*UpdateFederatesWithResponse, error)
+}
+
+type entryFederationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEntryFederationClient(cc grpc.ClientConnInterface) EntryFederationClient {
+	return &entryFederationClient{cc}
+}
+
+func (c *entryFederationClient) UpdateFederatesWith(ctx context.Context, in *UpdateFederatesWithRequest, opts ...grpc.CallOption) (*UpdateFederatesWithResponse, error) {
+	out := new(UpdateFederatesWithResponse)
+	err := c.cc.Invoke(ctx, "/spire.private.server.entryfederation.EntryFederation/UpdateFederatesWith", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EntryFederationServer is the server API for EntryFederation service.
+// All implementations must embed UnimplementedEntryFederationServer
+// for forward compatibility
+type EntryFederationServer interface {
+	// UpdateFederatesWith adds the trust domain to, or removes it from, the
+	// federated trust domains of the entries matching the selectors. The
+	// entries are updated in a single transaction, so either all of them
+	// are updated or none are. The trust domain must have a bundle, i.e. be
+	// federated with.
+	UpdateFederatesWith(context.Context, *UpdateFederatesWithRequest) (*UpdateFederatesWithResponse, error)
+	mustEmbedUnimplementedEntryFederationServer()
+}
+
+// UnimplementedEntryFederationServer must be embedded to have forward compatible implementations.
+type UnimplementedEntryFederationServer struct {
+}
+
+func (UnimplementedEntryFederationServer) UpdateFederatesWith(context.Context, *UpdateFederatesWithRequest) (*UpdateFederatesWithResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateFederatesWith not implemented")
+}
+func (UnimplementedEntryFederationServer) mustEmbedUnimplementedEntryFederationServer() {}
+
+// UnsafeEntryFederationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EntryFederationServer will
+// result in compilation errors.
+type UnsafeEntryFederationServer interface {
+	mustEmbedUnimplementedEntryFederationServer()
+}
+
+func RegisterEntryFederationServer(s grpc.ServiceRegistrar, srv EntryFederationServer) {
+	s.RegisterService(&EntryFederation_ServiceDesc, srv)
+}
+
+func _EntryFederation_UpdateFederatesWith_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateFederatesWithRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EntryFederationServer).UpdateFederatesWith(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/spire.private.server.entryfederation.EntryFederation/UpdateFederatesWith",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EntryFederationServer).UpdateFederatesWith(ctx, req.(*UpdateFederatesWithRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EntryFederation_ServiceDesc is the grpc.ServiceDesc for EntryFederation service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EntryFederation_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.private.server.entryfederation.EntryFederation",
+	HandlerType: (*EntryFederationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UpdateFederatesWith",
+			Handler:    _EntryFederation_UpdateFederatesWith_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "private/server/entryfederation/entryfederation.proto",
+}
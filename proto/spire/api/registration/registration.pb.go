@@ -396,6 +396,8 @@ type ListAllEntriesRequest struct {
 	unknownFields protoimpl.UnknownFields
 
 	Pagination *Pagination `protobuf:"bytes,1,opt,name=pagination,proto3" json:"pagination,omitempty"`
+	// Only the entries with all of these metadata key/value pairs are listed
+	ByMetadata map[string]string `protobuf:"bytes,2,rep,name=by_metadata,json=byMetadata,proto3" json:"by_metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
 func (x *ListAllEntriesRequest) Reset() {
@@ -437,6 +439,13 @@ func (x *ListAllEntriesRequest) GetPagination() *Pagination {
 	return nil
 }
 
+func (x *ListAllEntriesRequest) GetByMetadata() map[string]string {
+	if x != nil {
+		return x.ByMetadata
+	}
+	return nil
+}
+
 // It is used to list all registration entries with pagination
 type ListAllEntriesResponse struct {
 	state         protoimpl.MessageState
@@ -1380,13 +1389,23 @@ var file_spire_api_registration_registration_proto_rawDesc = []byte{
 	0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67,
 	0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61,
-	0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x5b, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c,
-	0x6c, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x42, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e,
-	0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x50, 0x61, 0x67,
-	0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x22, 0x97, 0x01, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x6c, 0x45,
+	0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0xfa, 0x01, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x41,
+	0x6c, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x42, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x50, 0x61,
+	0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x5e, 0x0a, 0x0b, 0x62, 0x79, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3d, 0x2e, 0x73, 0x70, 0x69, 0x72,
+	0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x42, 0x79, 0x4d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x62, 0x79, 0x4d, 0x65, 0x74, 0x61,
+	0x64, 0x61, 0x74, 0x61, 0x1a, 0x3d, 0x0a, 0x0f, 0x42, 0x79, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0x97, 0x01, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x6c, 0x45,
 	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39,
 	0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
 	0x1f, 0x2e, 0x73, 0x70, 0x69, 0x72, 0x65, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x52,
@@ -1627,7 +1646,7 @@ func file_spire_api_registration_registration_proto_rawDescGZIP() []byte {
 }
 
 var file_spire_api_registration_registration_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_spire_api_registration_registration_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_spire_api_registration_registration_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
 var file_spire_api_registration_registration_proto_goTypes = []interface{}{
 	(DeleteFederatedBundleRequest_Mode)(0), // 0: spire.api.registration.DeleteFederatedBundleRequest.Mode
 	(*RegistrationEntryID)(nil),            // 1: spire.api.registration.RegistrationEntryID
@@ -1654,78 +1673,80 @@ var file_spire_api_registration_registration_proto_goTypes = []interface{}{
 	(*NodeSelectors)(nil),                  // 22: spire.api.registration.NodeSelectors
 	(*GetNodeSelectorsRequest)(nil),        // 23: spire.api.registration.GetNodeSelectorsRequest
 	(*GetNodeSelectorsResponse)(nil),       // 24: spire.api.registration.GetNodeSelectorsResponse
-	(*common.RegistrationEntry)(nil),       // 25: spire.common.RegistrationEntry
-	(*common.Bundle)(nil),                  // 26: spire.common.Bundle
-	(*common.AttestedNode)(nil),            // 27: spire.common.AttestedNode
-	(*common.Selector)(nil),                // 28: spire.common.Selector
-	(*common.Empty)(nil),                   // 29: spire.common.Empty
-	(*common.Selectors)(nil),               // 30: spire.common.Selectors
-	(*common.RegistrationEntries)(nil),     // 31: spire.common.RegistrationEntries
+	nil,                                    // 25: spire.api.registration.ListAllEntriesRequest.ByMetadataEntry
+	(*common.RegistrationEntry)(nil),       // 26: spire.common.RegistrationEntry
+	(*common.Bundle)(nil),                  // 27: spire.common.Bundle
+	(*common.AttestedNode)(nil),            // 28: spire.common.AttestedNode
+	(*common.Selector)(nil),                // 29: spire.common.Selector
+	(*common.Empty)(nil),                   // 30: spire.common.Empty
+	(*common.Selectors)(nil),               // 31: spire.common.Selectors
+	(*common.RegistrationEntries)(nil),     // 32: spire.common.RegistrationEntries
 }
 var file_spire_api_registration_registration_proto_depIdxs = []int32{
-	25, // 0: spire.api.registration.CreateEntryIfNotExistsResponse.entry:type_name -> spire.common.RegistrationEntry
-	25, // 1: spire.api.registration.UpdateEntryRequest.entry:type_name -> spire.common.RegistrationEntry
+	26, // 0: spire.api.registration.CreateEntryIfNotExistsResponse.entry:type_name -> spire.common.RegistrationEntry
+	26, // 1: spire.api.registration.UpdateEntryRequest.entry:type_name -> spire.common.RegistrationEntry
 	6,  // 2: spire.api.registration.ListAllEntriesRequest.pagination:type_name -> spire.api.registration.Pagination
-	25, // 3: spire.api.registration.ListAllEntriesResponse.entries:type_name -> spire.common.RegistrationEntry
-	6,  // 4: spire.api.registration.ListAllEntriesResponse.pagination:type_name -> spire.api.registration.Pagination
-	26, // 5: spire.api.registration.FederatedBundle.bundle:type_name -> spire.common.Bundle
-	0,  // 6: spire.api.registration.DeleteFederatedBundleRequest.mode:type_name -> spire.api.registration.DeleteFederatedBundleRequest.Mode
-	26, // 7: spire.api.registration.Bundle.bundle:type_name -> spire.common.Bundle
-	27, // 8: spire.api.registration.ListAgentsResponse.nodes:type_name -> spire.common.AttestedNode
-	27, // 9: spire.api.registration.EvictAgentResponse.node:type_name -> spire.common.AttestedNode
-	28, // 10: spire.api.registration.NodeSelectors.selectors:type_name -> spire.common.Selector
-	22, // 11: spire.api.registration.GetNodeSelectorsResponse.selectors:type_name -> spire.api.registration.NodeSelectors
-	25, // 12: spire.api.registration.Registration.CreateEntry:input_type -> spire.common.RegistrationEntry
-	25, // 13: spire.api.registration.Registration.CreateEntryIfNotExists:input_type -> spire.common.RegistrationEntry
-	1,  // 14: spire.api.registration.Registration.DeleteEntry:input_type -> spire.api.registration.RegistrationEntryID
-	1,  // 15: spire.api.registration.Registration.FetchEntry:input_type -> spire.api.registration.RegistrationEntryID
-	29, // 16: spire.api.registration.Registration.FetchEntries:input_type -> spire.common.Empty
-	5,  // 17: spire.api.registration.Registration.UpdateEntry:input_type -> spire.api.registration.UpdateEntryRequest
-	2,  // 18: spire.api.registration.Registration.ListByParentID:input_type -> spire.api.registration.ParentID
-	28, // 19: spire.api.registration.Registration.ListBySelector:input_type -> spire.common.Selector
-	30, // 20: spire.api.registration.Registration.ListBySelectors:input_type -> spire.common.Selectors
-	3,  // 21: spire.api.registration.Registration.ListBySpiffeID:input_type -> spire.api.registration.SpiffeID
-	7,  // 22: spire.api.registration.Registration.ListAllEntriesWithPages:input_type -> spire.api.registration.ListAllEntriesRequest
-	9,  // 23: spire.api.registration.Registration.CreateFederatedBundle:input_type -> spire.api.registration.FederatedBundle
-	10, // 24: spire.api.registration.Registration.FetchFederatedBundle:input_type -> spire.api.registration.FederatedBundleID
-	29, // 25: spire.api.registration.Registration.ListFederatedBundles:input_type -> spire.common.Empty
-	9,  // 26: spire.api.registration.Registration.UpdateFederatedBundle:input_type -> spire.api.registration.FederatedBundle
-	11, // 27: spire.api.registration.Registration.DeleteFederatedBundle:input_type -> spire.api.registration.DeleteFederatedBundleRequest
-	12, // 28: spire.api.registration.Registration.CreateJoinToken:input_type -> spire.api.registration.JoinToken
-	29, // 29: spire.api.registration.Registration.FetchBundle:input_type -> spire.common.Empty
-	16, // 30: spire.api.registration.Registration.EvictAgent:input_type -> spire.api.registration.EvictAgentRequest
-	14, // 31: spire.api.registration.Registration.ListAgents:input_type -> spire.api.registration.ListAgentsRequest
-	18, // 32: spire.api.registration.Registration.MintX509SVID:input_type -> spire.api.registration.MintX509SVIDRequest
-	20, // 33: spire.api.registration.Registration.MintJWTSVID:input_type -> spire.api.registration.MintJWTSVIDRequest
-	23, // 34: spire.api.registration.Registration.GetNodeSelectors:input_type -> spire.api.registration.GetNodeSelectorsRequest
-	1,  // 35: spire.api.registration.Registration.CreateEntry:output_type -> spire.api.registration.RegistrationEntryID
-	4,  // 36: spire.api.registration.Registration.CreateEntryIfNotExists:output_type -> spire.api.registration.CreateEntryIfNotExistsResponse
-	25, // 37: spire.api.registration.Registration.DeleteEntry:output_type -> spire.common.RegistrationEntry
-	25, // 38: spire.api.registration.Registration.FetchEntry:output_type -> spire.common.RegistrationEntry
-	31, // 39: spire.api.registration.Registration.FetchEntries:output_type -> spire.common.RegistrationEntries
-	25, // 40: spire.api.registration.Registration.UpdateEntry:output_type -> spire.common.RegistrationEntry
-	31, // 41: spire.api.registration.Registration.ListByParentID:output_type -> spire.common.RegistrationEntries
-	31, // 42: spire.api.registration.Registration.ListBySelector:output_type -> spire.common.RegistrationEntries
-	31, // 43: spire.api.registration.Registration.ListBySelectors:output_type -> spire.common.RegistrationEntries
-	31, // 44: spire.api.registration.Registration.ListBySpiffeID:output_type -> spire.common.RegistrationEntries
-	8,  // 45: spire.api.registration.Registration.ListAllEntriesWithPages:output_type -> spire.api.registration.ListAllEntriesResponse
-	29, // 46: spire.api.registration.Registration.CreateFederatedBundle:output_type -> spire.common.Empty
-	9,  // 47: spire.api.registration.Registration.FetchFederatedBundle:output_type -> spire.api.registration.FederatedBundle
-	9,  // 48: spire.api.registration.Registration.ListFederatedBundles:output_type -> spire.api.registration.FederatedBundle
-	29, // 49: spire.api.registration.Registration.UpdateFederatedBundle:output_type -> spire.common.Empty
-	29, // 50: spire.api.registration.Registration.DeleteFederatedBundle:output_type -> spire.common.Empty
-	12, // 51: spire.api.registration.Registration.CreateJoinToken:output_type -> spire.api.registration.JoinToken
-	13, // 52: spire.api.registration.Registration.FetchBundle:output_type -> spire.api.registration.Bundle
-	17, // 53: spire.api.registration.Registration.EvictAgent:output_type -> spire.api.registration.EvictAgentResponse
-	15, // 54: spire.api.registration.Registration.ListAgents:output_type -> spire.api.registration.ListAgentsResponse
-	19, // 55: spire.api.registration.Registration.MintX509SVID:output_type -> spire.api.registration.MintX509SVIDResponse
-	21, // 56: spire.api.registration.Registration.MintJWTSVID:output_type -> spire.api.registration.MintJWTSVIDResponse
-	24, // 57: spire.api.registration.Registration.GetNodeSelectors:output_type -> spire.api.registration.GetNodeSelectorsResponse
-	35, // [35:58] is the sub-list for method output_type
-	12, // [12:35] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	25, // 3: spire.api.registration.ListAllEntriesRequest.by_metadata:type_name -> spire.api.registration.ListAllEntriesRequest.ByMetadataEntry
+	26, // 4: spire.api.registration.ListAllEntriesResponse.entries:type_name -> spire.common.RegistrationEntry
+	6,  // 5: spire.api.registration.ListAllEntriesResponse.pagination:type_name -> spire.api.registration.Pagination
+	27, // 6: spire.api.registration.FederatedBundle.bundle:type_name -> spire.common.Bundle
+	0,  // 7: spire.api.registration.DeleteFederatedBundleRequest.mode:type_name -> spire.api.registration.DeleteFederatedBundleRequest.Mode
+	27, // 8: spire.api.registration.Bundle.bundle:type_name -> spire.common.Bundle
+	28, // 9: spire.api.registration.ListAgentsResponse.nodes:type_name -> spire.common.AttestedNode
+	28, // 10: spire.api.registration.EvictAgentResponse.node:type_name -> spire.common.AttestedNode
+	29, // 11: spire.api.registration.NodeSelectors.selectors:type_name -> spire.common.Selector
+	22, // 12: spire.api.registration.GetNodeSelectorsResponse.selectors:type_name -> spire.api.registration.NodeSelectors
+	26, // 13: spire.api.registration.Registration.CreateEntry:input_type -> spire.common.RegistrationEntry
+	26, // 14: spire.api.registration.Registration.CreateEntryIfNotExists:input_type -> spire.common.RegistrationEntry
+	1,  // 15: spire.api.registration.Registration.DeleteEntry:input_type -> spire.api.registration.RegistrationEntryID
+	1,  // 16: spire.api.registration.Registration.FetchEntry:input_type -> spire.api.registration.RegistrationEntryID
+	30, // 17: spire.api.registration.Registration.FetchEntries:input_type -> spire.common.Empty
+	5,  // 18: spire.api.registration.Registration.UpdateEntry:input_type -> spire.api.registration.UpdateEntryRequest
+	2,  // 19: spire.api.registration.Registration.ListByParentID:input_type -> spire.api.registration.ParentID
+	29, // 20: spire.api.registration.Registration.ListBySelector:input_type -> spire.common.Selector
+	31, // 21: spire.api.registration.Registration.ListBySelectors:input_type -> spire.common.Selectors
+	3,  // 22: spire.api.registration.Registration.ListBySpiffeID:input_type -> spire.api.registration.SpiffeID
+	7,  // 23: spire.api.registration.Registration.ListAllEntriesWithPages:input_type -> spire.api.registration.ListAllEntriesRequest
+	9,  // 24: spire.api.registration.Registration.CreateFederatedBundle:input_type -> spire.api.registration.FederatedBundle
+	10, // 25: spire.api.registration.Registration.FetchFederatedBundle:input_type -> spire.api.registration.FederatedBundleID
+	30, // 26: spire.api.registration.Registration.ListFederatedBundles:input_type -> spire.common.Empty
+	9,  // 27: spire.api.registration.Registration.UpdateFederatedBundle:input_type -> spire.api.registration.FederatedBundle
+	11, // 28: spire.api.registration.Registration.DeleteFederatedBundle:input_type -> spire.api.registration.DeleteFederatedBundleRequest
+	12, // 29: spire.api.registration.Registration.CreateJoinToken:input_type -> spire.api.registration.JoinToken
+	30, // 30: spire.api.registration.Registration.FetchBundle:input_type -> spire.common.Empty
+	16, // 31: spire.api.registration.Registration.EvictAgent:input_type -> spire.api.registration.EvictAgentRequest
+	14, // 32: spire.api.registration.Registration.ListAgents:input_type -> spire.api.registration.ListAgentsRequest
+	18, // 33: spire.api.registration.Registration.MintX509SVID:input_type -> spire.api.registration.MintX509SVIDRequest
+	20, // 34: spire.api.registration.Registration.MintJWTSVID:input_type -> spire.api.registration.MintJWTSVIDRequest
+	23, // 35: spire.api.registration.Registration.GetNodeSelectors:input_type -> spire.api.registration.GetNodeSelectorsRequest
+	1,  // 36: spire.api.registration.Registration.CreateEntry:output_type -> spire.api.registration.RegistrationEntryID
+	4,  // 37: spire.api.registration.Registration.CreateEntryIfNotExists:output_type -> spire.api.registration.CreateEntryIfNotExistsResponse
+	26, // 38: spire.api.registration.Registration.DeleteEntry:output_type -> spire.common.RegistrationEntry
+	26, // 39: spire.api.registration.Registration.FetchEntry:output_type -> spire.common.RegistrationEntry
+	32, // 40: spire.api.registration.Registration.FetchEntries:output_type -> spire.common.RegistrationEntries
+	26, // 41: spire.api.registration.Registration.UpdateEntry:output_type -> spire.common.RegistrationEntry
+	32, // 42: spire.api.registration.Registration.ListByParentID:output_type -> spire.common.RegistrationEntries
+	32, // 43: spire.api.registration.Registration.ListBySelector:output_type -> spire.common.RegistrationEntries
+	32, // 44: spire.api.registration.Registration.ListBySelectors:output_type -> spire.common.RegistrationEntries
+	32, // 45: spire.api.registration.Registration.ListBySpiffeID:output_type -> spire.common.RegistrationEntries
+	8,  // 46: spire.api.registration.Registration.ListAllEntriesWithPages:output_type -> spire.api.registration.ListAllEntriesResponse
+	30, // 47: spire.api.registration.Registration.CreateFederatedBundle:output_type -> spire.common.Empty
+	9,  // 48: spire.api.registration.Registration.FetchFederatedBundle:output_type -> spire.api.registration.FederatedBundle
+	9,  // 49: spire.api.registration.Registration.ListFederatedBundles:output_type -> spire.api.registration.FederatedBundle
+	30, // 50: spire.api.registration.Registration.UpdateFederatedBundle:output_type -> spire.common.Empty
+	30, // 51: spire.api.registration.Registration.DeleteFederatedBundle:output_type -> spire.common.Empty
+	12, // 52: spire.api.registration.Registration.CreateJoinToken:output_type -> spire.api.registration.JoinToken
+	13, // 53: spire.api.registration.Registration.FetchBundle:output_type -> spire.api.registration.Bundle
+	17, // 54: spire.api.registration.Registration.EvictAgent:output_type -> spire.api.registration.EvictAgentResponse
+	15, // 55: spire.api.registration.Registration.ListAgents:output_type -> spire.api.registration.ListAgentsResponse
+	19, // 56: spire.api.registration.Registration.MintX509SVID:output_type -> spire.api.registration.MintX509SVIDResponse
+	21, // 57: spire.api.registration.Registration.MintJWTSVID:output_type -> spire.api.registration.MintJWTSVIDResponse
+	24, // 58: spire.api.registration.Registration.GetNodeSelectors:output_type -> spire.api.registration.GetNodeSelectorsResponse
+	36, // [36:59] is the sub-list for method output_type
+	13, // [13:36] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_spire_api_registration_registration_proto_init() }
@@ -2029,7 +2050,7 @@ func file_spire_api_registration_registration_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_spire_api_registration_registration_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   24,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
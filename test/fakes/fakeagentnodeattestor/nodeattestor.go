@@ -20,6 +20,11 @@ type Config struct {
 	// Responses is list of echo responses. The response to each challenge is
 	// expected to match the challenge value.
 	Responses []string
+
+	// Type and Payload, if set, override the type and payload of the
+	// attestation data returned by the plugin.
+	Type    string
+	Payload []byte
 }
 
 func New(t *testing.T, config Config) nodeattestor.NodeAttestor {
@@ -82,10 +87,18 @@ func (p *nodeAttestor) makeResponse(challengeResponse []byte) *nodeattestorv0.Fe
 		}
 	}
 
+	attestationData := &common.AttestationData{
+		Type: "test",
+		Data: []byte("TEST"),
+	}
+	if p.config.Type != "" {
+		attestationData.Type = p.config.Type
+	}
+	if p.config.Payload != nil {
+		attestationData.Data = p.config.Payload
+	}
+
 	return &nodeattestorv0.FetchAttestationDataResponse{
-		AttestationData: &common.AttestationData{
-			Type: "test",
-			Data: []byte("TEST"),
-		},
+		AttestationData: attestationData,
 	}
 }
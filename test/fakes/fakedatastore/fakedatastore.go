@@ -157,6 +157,13 @@ func (s *DataStore) DeleteAttestedNode(ctx context.Context, spiffeID string) (*c
 	return s.ds.DeleteAttestedNode(ctx, spiffeID)
 }
 
+func (s *DataStore) BatchSetNodeSelectors(ctx context.Context, req *datastore.BatchSetNodeSelectorsRequest) (*datastore.BatchSetNodeSelectorsResponse, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.BatchSetNodeSelectors(ctx, req)
+}
+
 func (s *DataStore) SetNodeSelectors(ctx context.Context, req *datastore.SetNodeSelectorsRequest) (*datastore.SetNodeSelectorsResponse, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -216,6 +223,13 @@ func (s *DataStore) ListRegistrationEntries(ctx context.Context, req *datastore.
 	return resp, err
 }
 
+func (s *DataStore) UpdateEntriesFederatesWith(ctx context.Context, req *datastore.UpdateEntriesFederatesWithRequest) (*datastore.UpdateEntriesFederatesWithResponse, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.UpdateEntriesFederatesWith(ctx, req)
+}
+
 func (s *DataStore) UpdateRegistrationEntry(ctx context.Context, req *datastore.UpdateRegistrationEntryRequest) (*datastore.UpdateRegistrationEntryResponse, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -237,6 +251,34 @@ func (s *DataStore) PruneRegistrationEntries(ctx context.Context, req *datastore
 	return s.ds.PruneRegistrationEntries(ctx, req)
 }
 
+func (s *DataStore) SoftDeleteRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.SoftDeleteRegistrationEntry(ctx, entryID)
+}
+
+func (s *DataStore) RestoreRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.RestoreRegistrationEntry(ctx, entryID)
+}
+
+func (s *DataStore) ListDeletedRegistrationEntries(ctx context.Context) ([]*datastore.DeletedRegistrationEntry, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListDeletedRegistrationEntries(ctx)
+}
+
+func (s *DataStore) PruneDeletedRegistrationEntries(ctx context.Context, deletedBefore time.Time) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.PruneDeletedRegistrationEntries(ctx, deletedBefore)
+}
+
 func (s *DataStore) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) error {
 	if err := s.getNextError(); err != nil {
 		return err
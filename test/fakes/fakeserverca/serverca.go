@@ -14,6 +14,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakehealthchecker"
+	"github.com/spiffe/spire/test/testkey"
 	"github.com/stretchr/testify/require"
 )
 
@@ -32,6 +33,10 @@ type Options struct {
 	Clock       clock.Clock
 	X509SVIDTTL time.Duration
 	JWTSVIDTTL  time.Duration
+
+	// JWTKeyTrustDomains are additional trust domains the CA signs
+	// JWT-SVIDs for, each with its own randomly generated key.
+	JWTKeyTrustDomains []spiffeid.TrustDomain
 }
 
 type CA struct {
@@ -75,6 +80,8 @@ func New(t *testing.T, trustDomain spiffeid.TrustDomain, options *Options) *CA {
 		JWTSVIDTTL:    options.JWTSVIDTTL,
 		Clock:         options.Clock,
 		HealthChecker: healthChecker,
+
+		JWTKeyTrustDomains: options.JWTKeyTrustDomains,
 	})
 	serverCA.SetX509CA(x509CA)
 	serverCA.SetJWTKey(&ca.JWTKey{
@@ -82,6 +89,13 @@ func New(t *testing.T, trustDomain spiffeid.TrustDomain, options *Options) *CA {
 		Kid:      "KID",
 		NotAfter: notAfter,
 	})
+	for _, td := range options.JWTKeyTrustDomains {
+		serverCA.SetTrustDomainJWTKey(td, &ca.JWTKey{
+			Signer:   testkey.NewEC256(t),
+			Kid:      "KID-" + td.String(),
+			NotAfter: notAfter,
+		})
+	}
 
 	return &CA{
 		CA:      serverCA,
@@ -0,0 +1,37 @@
+package faketracing
+
+import (
+	"testing"
+
+	"github.com/spiffe/spire/pkg/common/tracing"
+	"go.opentelemetry.io/otel/sdk/export/trace/tracetest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporter records spans in memory.
+type Exporter struct {
+	*tracetest.InMemoryExporter
+}
+
+// New installs a tracer provider that synchronously exports spans to an
+// in-memory exporter. The no-op tracer provider is restored when the test
+// finishes.
+func New(t *testing.T) *Exporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tracing.SetTracerProvider(tracing.NewTracerProvider("test", sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() {
+		tracing.SetTracerProvider(trace.NewNoopTracerProvider())
+	})
+	return &Exporter{InMemoryExporter: exporter}
+}
+
+// SpanNames returns the names of the exported spans, in the order they
+// ended.
+func (e *Exporter) SpanNames() []string {
+	var names []string
+	for _, span := range e.GetSpans() {
+		names = append(names, span.Name)
+	}
+	return names
+}
@@ -263,6 +263,12 @@ func WithLifetime(notBefore, notAfter time.Time) CertificateOption {
 	})
 }
 
+func WithDNSNames(dnsNames ...string) CertificateOption {
+	return certificateOption(func(c *x509.Certificate) {
+		c.DNSNames = dnsNames
+	})
+}
+
 func WithIPAddresses(ips ...net.IP) CertificateOption {
 	return certificateOption(func(c *x509.Certificate) {
 		c.IPAddresses = ips